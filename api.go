@@ -55,6 +55,11 @@ const (
 
 	// RangePatternStr specifies the format expected for range matches.
 	RangeMatchPatternStr = `^\s*\[\s*([-+]?\d+),\s*([-+]?\d+)\s*\)\s*$`
+
+	// MethodPatternStr specifies what can be used as an HTTP method: GET,
+	// PUT, POST, DELETE, PATCH, HEAD, OPTIONS, or any other token as defined
+	// by RFC 7230.
+	MethodPatternStr = "^[!#$%&'*+.^_`|~0-9A-Za-z-]+$"
 )
 
 var (
@@ -76,6 +81,9 @@ var (
 
 	// RangeMatchPattern is the pattern that a range match From.Value should have.
 	RangeMatchPattern = regexp.MustCompile(RangeMatchPatternStr)
+
+	// MethodPattern is the pattern that an HTTP method must match.
+	MethodPattern = regexp.MustCompile(MethodPatternStr)
 )
 
 // ErrorCase represents an error in an API object. It contains both the
@@ -17,6 +17,7 @@ limitations under the License.
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -42,8 +43,28 @@ type httpAccessTokenV1 struct {
 //   dest - service handling our HTTP requests; cf. NewService
 func NewAccessTokenV1(
 	dest apihttp.Endpoint,
+) (service.AccessToken, error) {
+	hc, err := NewAccessTokenContextV1(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.FromAccessTokenContext(hc), nil
+}
+
+// NewAccessTokenContextV1 constructs a new HTTP backed AccessToken API
+// implementation whose methods apply the context.Context they are passed to
+// each request.
+//
+// Parameters:
+//   dest - service handling our HTTP requests; cf. NewService
+func NewAccessTokenContextV1(
+	dest apihttp.Endpoint,
 ) (*httpAccessTokenV1, error) {
-	return &httpAccessTokenV1{dest, apihttp.NewRequestHandler(dest.Client())}, nil
+	return &httpAccessTokenV1{
+		dest,
+		apihttp.NewRetryingRequestHandler(dest.Client(), dest.RetryPolicy()),
+	}, nil
 }
 
 // creates a accessToken-scoped version of the specified path
@@ -93,7 +114,10 @@ func (hc *httpAccessTokenV1) delete(path string, params apihttp.Params) (*http.R
 	return hc.request(http.MethodDelete, path, params, "")
 }
 
-func (hc *httpAccessTokenV1) Index(filters ...service.AccessTokenFilter) (api.AccessTokens, error) {
+func (hc *httpAccessTokenV1) Index(
+	ctx context.Context,
+	filters ...service.AccessTokenFilter,
+) (api.AccessTokens, error) {
 	params := apihttp.Params{}
 
 	if filters != nil && len(filters) != 0 {
@@ -109,27 +133,30 @@ func (hc *httpAccessTokenV1) Index(filters ...service.AccessTokenFilter) (api.Ac
 	}
 
 	response := make(api.AccessTokens, 0, 10)
-	reqFn := func() (*http.Request, error) { return hc.get("", params) }
+	reqFn := func() (*http.Request, error) { return hc.get("", readParams(ctx, params)) }
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return nil, err
 	}
 
 	return response, nil
 }
 
-func (hc *httpAccessTokenV1) Get(key api.AccessTokenKey) (api.AccessToken, error) {
+func (hc *httpAccessTokenV1) Get(
+	ctx context.Context,
+	key api.AccessTokenKey,
+) (api.AccessToken, error) {
 	if key == "" {
 		return api.AccessToken{}, httperr.New400(
 			"AccessTokenKey is a required parameter", httperr.ObjectKeyRequiredErrorCode)
 	}
 
 	reqFn := func() (*http.Request, error) {
-		return hc.get("/"+url.QueryEscape(string(key)), nil)
+		return hc.get("/"+url.QueryEscape(string(key)), readParams(ctx, nil))
 	}
 
 	response := api.AccessToken{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.AccessToken{}, err
 	}
 
@@ -141,11 +168,17 @@ func mkEncodeAccessTokenError(accessToken api.AccessToken) *httperr.Error {
 	return httperr.New400(msg, httperr.UnknownEncodingCode)
 }
 
-func (hc *httpAccessTokenV1) CreateAccessToken(desc string) (api.AccessToken, error) {
-	return hc.Create(api.AccessToken{Description: desc})
+func (hc *httpAccessTokenV1) CreateAccessToken(
+	ctx context.Context,
+	desc string,
+) (api.AccessToken, error) {
+	return hc.Create(ctx, api.AccessToken{Description: desc})
 }
 
-func (hc *httpAccessTokenV1) Create(newAccessToken api.AccessToken) (api.AccessToken, error) {
+func (hc *httpAccessTokenV1) Create(
+	ctx context.Context,
+	newAccessToken api.AccessToken,
+) (api.AccessToken, error) {
 	encoded := ""
 
 	if b, err := json.Marshal(newAccessToken); err == nil {
@@ -154,9 +187,15 @@ func (hc *httpAccessTokenV1) Create(newAccessToken api.AccessToken) (api.AccessT
 		return api.AccessToken{}, mkEncodeAccessTokenError(newAccessToken)
 	}
 
-	reqFn := func() (*http.Request, error) { return hc.post("", nil, encoded) }
+	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.post("", params, encoded)
+	}
 	response := api.AccessToken{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.AccessToken{}, err
 	}
 
@@ -164,17 +203,22 @@ func (hc *httpAccessTokenV1) Create(newAccessToken api.AccessToken) (api.AccessT
 }
 
 func (hc *httpAccessTokenV1) Delete(
+	ctx context.Context,
 	accessTokenKey api.AccessTokenKey,
 	checksum api.Checksum,
 ) error {
 	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, apihttp.Params{queryargs.Checksum: checksum.Checksum})
+		if err != nil {
+			return nil, err
+		}
 		return hc.delete(
 			"/"+url.QueryEscape(string(accessTokenKey)),
-			apihttp.Params{queryargs.Checksum: checksum.Checksum},
+			params,
 		)
 	}
 
-	if err := hc.requestHandler.Do(reqFn, nil); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, nil); err != nil {
 		return err
 	}
 
@@ -0,0 +1,95 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	apihttp "github.com/turbinelabs/api/http"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/service"
+)
+
+type httpBatchV1 struct {
+	dest apihttp.Endpoint
+
+	requestHandler apihttp.RequestHandler
+}
+
+// Construct a new HTTP backed Batch API implementation.
+//
+// Parameters:
+//	dest - service handling our HTTP requests; cf. NewService
+func NewBatchV1(dest apihttp.Endpoint) (service.Batch, error) {
+	hc, err := NewBatchContextV1(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.FromBatchContext(hc), nil
+}
+
+// NewBatchContextV1 constructs a new HTTP backed Batch API
+// implementation whose methods apply the context.Context they are passed to
+// each request.
+//
+// Parameters:
+//	dest - service handling our HTTP requests; cf. NewService
+func NewBatchContextV1(dest apihttp.Endpoint) (*httpBatchV1, error) {
+	return &httpBatchV1{
+		dest,
+		apihttp.NewRetryingRequestHandler(dest.Client(), dest.RetryPolicy()),
+	}, nil
+}
+
+func (hb *httpBatchV1) Apply(
+	ctx context.Context,
+	ops []service.BatchOperation,
+) ([]service.BatchResult, error) {
+	b, err := json.Marshal(ops)
+	if err != nil {
+		return nil, httperr.New400(
+			fmt.Sprintf("could not encode provided batch operations: %v", err),
+			httperr.UnknownEncodingCode,
+		)
+	}
+	encoded := string(b)
+
+	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hb.dest.NewRequest(
+			http.MethodPost,
+			"/v1.0/batch",
+			params,
+			strings.NewReader(encoded),
+		)
+	}
+
+	var response []service.BatchResult
+	if err := hb.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
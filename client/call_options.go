@@ -0,0 +1,118 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/turbinelabs/api"
+	apihttp "github.com/turbinelabs/api/http"
+	"github.com/turbinelabs/api/queryargs"
+)
+
+type callOptionsKey struct{}
+
+// callOptions are the per-call options carried by a context.Context.
+type callOptions struct {
+	comment        string
+	actor          api.UserKey
+	includeDeleted bool
+}
+
+func getCallOptions(ctx context.Context) callOptions {
+	if ctx == nil {
+		return callOptions{}
+	}
+	opts, _ := ctx.Value(callOptionsKey{}).(callOptions)
+	return opts
+}
+
+func withCallOptions(ctx context.Context, f func(*callOptions)) context.Context {
+	opts := getCallOptions(ctx)
+	f(&opts)
+	return context.WithValue(ctx, callOptionsKey{}, opts)
+}
+
+// WithComment returns a context which, when passed to the Create, Modify, or
+// Delete methods of a service.AllContext or service.AdminContext produced by
+// this package, attaches the given comment to the change. The comment is
+// recorded by the remote Turbine Labs API, whose History API returns it as
+// ChangeMeta.Comment. The server and service/memory packages of this
+// repository do not record comments.
+func WithComment(ctx context.Context, comment string) context.Context {
+	return withCallOptions(ctx, func(o *callOptions) { o.comment = comment })
+}
+
+// WithActor returns a context which records the given actor for the Create,
+// Modify, or Delete methods of a service.AllContext or service.AdminContext
+// produced by this package. The Turbine Labs API does not accept actor
+// annotations: it records the owner of the API key used to make the request
+// as ChangeMeta.ActorKey. Rather than silently dropping the actor, mutations
+// made with such a context fail without sending a request.
+func WithActor(ctx context.Context, actor api.UserKey) context.Context {
+	return withCallOptions(ctx, func(o *callOptions) { o.actor = actor })
+}
+
+// WithDeleted returns a context which, when passed to the Get or Index
+// methods of a service.AllContext or service.AdminContext produced by this
+// package, requests that deleted objects be included in the result.
+func WithDeleted(ctx context.Context) context.Context {
+	return withCallOptions(ctx, func(o *callOptions) { o.includeDeleted = true })
+}
+
+// readParams returns params with the read options carried by ctx added.
+// The given Params are not modified.
+func readParams(ctx context.Context, params apihttp.Params) apihttp.Params {
+	opts := getCallOptions(ctx)
+	if !opts.includeDeleted {
+		return params
+	}
+
+	result := copyParams(params)
+	result[queryargs.IncludeDeleted] = "true"
+	return result
+}
+
+// mutationParams returns params with the mutation options carried by ctx
+// added. The given Params are not modified. An error is returned if ctx
+// carries an option the API cannot honor.
+func mutationParams(ctx context.Context, params apihttp.Params) (apihttp.Params, error) {
+	opts := getCallOptions(ctx)
+	if opts.actor != "" {
+		return nil, fmt.Errorf(
+			"cannot record actor %q: the API records the owner of the API key as the actor",
+			opts.actor,
+		)
+	}
+
+	if opts.comment == "" {
+		return params, nil
+	}
+
+	result := copyParams(params)
+	result[queryargs.ChangeComment] = opts.comment
+	return result, nil
+}
+
+func copyParams(params apihttp.Params) apihttp.Params {
+	result := make(apihttp.Params, len(params)+1)
+	for k, v := range params {
+		result[k] = v
+	}
+	return result
+}
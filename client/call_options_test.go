@@ -0,0 +1,141 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/turbinelabs/api"
+	apihttp "github.com/turbinelabs/api/http"
+	"github.com/turbinelabs/api/queryargs"
+	"github.com/turbinelabs/test/assert"
+)
+
+func TestReadParams(t *testing.T) {
+	ctx := context.Background()
+	params := apihttp.Params{"a": "b"}
+
+	assert.Nil(t, readParams(ctx, nil))
+	assert.DeepEqual(t, readParams(ctx, params), params)
+	assert.DeepEqual(t, readParams(WithComment(ctx, "c"), params), params)
+
+	got := readParams(WithDeleted(ctx), params)
+	assert.DeepEqual(t, got, apihttp.Params{"a": "b", queryargs.IncludeDeleted: "true"})
+	assert.DeepEqual(t, params, apihttp.Params{"a": "b"})
+}
+
+func TestMutationParams(t *testing.T) {
+	ctx := context.Background()
+	params := apihttp.Params{queryargs.Checksum: "cs"}
+
+	got, err := mutationParams(ctx, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, got)
+
+	got, err = mutationParams(WithDeleted(ctx), params)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, params)
+
+	ctx = WithComment(WithComment(ctx, "first"), "because")
+	got, err = mutationParams(ctx, params)
+	assert.Nil(t, err)
+	assert.DeepEqual(
+		t,
+		got,
+		apihttp.Params{
+			queryargs.Checksum:      "cs",
+			queryargs.ChangeComment: "because",
+		},
+	)
+	assert.DeepEqual(t, params, apihttp.Params{queryargs.Checksum: "cs"})
+}
+
+func TestMutationParamsRejectsActor(t *testing.T) {
+	ctx := WithActor(WithComment(context.Background(), "because"), "bob")
+
+	got, err := mutationParams(ctx, apihttp.Params{queryargs.Checksum: "cs"})
+	assert.Nil(t, got)
+	assert.ErrorContains(t, err, `cannot record actor "bob"`)
+}
+
+func TestCallOptionsActorNotSent(t *testing.T) {
+	var got []apihttp.RichRequest
+	verifier := verifyingHandler{
+		fn:       func(rr apihttp.RichRequest) { got = append(got, rr) },
+		status:   http.StatusOK,
+		response: api.Cluster{ClusterKey: "ck"},
+	}
+	server := httptest.NewServer(verifier)
+	defer server.Close()
+
+	svc, err := NewAllContext(newTestEndpointFromServer(server), clientTestAPIKey, clientTestApp)
+	assert.Nil(t, err)
+
+	ctx := WithActor(context.Background(), "bob")
+	_, err = svc.Cluster().Modify(ctx, api.Cluster{ClusterKey: "ck"})
+	assert.ErrorContains(t, err, "owner of the API key")
+	err = svc.Cluster().Delete(ctx, "ck", api.Checksum{Checksum: "cs"})
+	assert.ErrorContains(t, err, "owner of the API key")
+	assert.Equal(t, len(got), 0)
+
+	_, err = svc.Cluster().Get(ctx, "ck")
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 1)
+}
+
+func TestCallOptionsSent(t *testing.T) {
+	var got []apihttp.RichRequest
+	verifier := verifyingHandler{
+		fn:       func(rr apihttp.RichRequest) { got = append(got, rr) },
+		status:   http.StatusOK,
+		response: api.Cluster{ClusterKey: "ck"},
+	}
+	server := httptest.NewServer(verifier)
+	defer server.Close()
+
+	svc, err := NewAllContext(newTestEndpointFromServer(server), clientTestAPIKey, clientTestApp)
+	assert.Nil(t, err)
+
+	ctx := WithComment(context.Background(), "because")
+	_, err = svc.Cluster().Modify(ctx, api.Cluster{ClusterKey: "ck"})
+	assert.Nil(t, err)
+	_, err = svc.Cluster().AddInstance(ctx, "ck", api.Checksum{Checksum: "cs"}, api.Instance{})
+	assert.Nil(t, err)
+	_, err = svc.Cluster().Get(WithDeleted(context.Background()), "ck")
+	assert.Nil(t, err)
+	_, err = svc.Cluster().Get(context.Background(), "ck")
+	assert.Nil(t, err)
+
+	if !assert.Equal(t, len(got), 4) {
+		return
+	}
+
+	for _, rr := range got[:2] {
+		assert.Equal(t, rr.QueryArg(queryargs.ChangeComment), "because")
+		_, ok := rr.QueryArgOk(queryargs.IncludeDeleted)
+		assert.False(t, ok)
+	}
+	assert.Equal(t, got[1].QueryArg(queryargs.Checksum), "cs")
+
+	_, ok := got[2].QueryArgOk(queryargs.IncludeDeleted)
+	assert.True(t, ok)
+	_, ok = got[3].QueryArgOk(queryargs.IncludeDeleted)
+	assert.False(t, ok)
+}
@@ -64,43 +64,76 @@ func NewAll(
 	apiKey string,
 	clientApp App,
 ) (service.All, error) {
+	hs, err := newHTTPServiceV1(dest, apiKey, clientApp)
+	if err != nil {
+		return nil, err
+	}
+
+	return hs, nil
+}
+
+// NewAllContext creates a new service.AllContext backed by a Turbine api
+// server at dest. Cancellation and deadlines of the context.Context passed to
+// each method are applied to the underlying HTTP request.
+//
+// Parameters: See NewAll.
+func NewAllContext(
+	dest apihttp.Endpoint,
+	apiKey string,
+	clientApp App,
+) (service.AllContext, error) {
+	hs, err := newHTTPServiceV1(dest, apiKey, clientApp)
+	if err != nil {
+		return nil, err
+	}
+
+	return (*httpServiceContextV1)(hs), nil
+}
+
+func newHTTPServiceV1(
+	dest apihttp.Endpoint,
+	apiKey string,
+	clientApp App,
+) (*httpServiceV1, error) {
 	dest = configureEndpoint(dest, apiKey, clientApp)
-	c, err := NewClusterV1(dest)
+	c, err := NewClusterContextV1(dest)
 	if err != nil {
 		return nil, err
 	}
-	d, err := NewDomainV1(dest)
+	d, err := NewDomainContextV1(dest)
 	if err != nil {
 		return nil, err
 	}
-	r, err := NewRouteV1(dest)
+	r, err := NewRouteContextV1(dest)
 	if err != nil {
 		return nil, err
 	}
-	s, err := NewSharedRulesV1(dest)
+	s, err := NewSharedRulesContextV1(dest)
 	if err != nil {
 		return nil, err
 	}
-	p, err := NewProxyV1(dest)
+	p, err := NewProxyContextV1(dest)
 	if err != nil {
 		return nil, err
 	}
-	l, err := NewListenerV1(dest)
+	l, err := NewListenerContextV1(dest)
 	if err != nil {
 		return nil, err
 	}
-	z, err := NewZoneV1(dest)
+	z, err := NewZoneContextV1(dest)
 	if err != nil {
 		return nil, err
 	}
-	h, err := NewHistoryV1(dest)
+	h, err := NewHistoryContextV1(dest)
+	if err != nil {
+		return nil, err
+	}
+	b, err := NewBatchContextV1(dest)
 	if err != nil {
 		return nil, err
 	}
 
-	httpService := httpServiceV1{c, d, r, s, p, l, z, h}
-
-	return &httpService, nil
+	return &httpServiceV1{c, d, r, s, p, l, z, h, b}, nil
 }
 
 // Create a new Admin backed by a Turbine api server at dest. Communication
@@ -118,20 +151,52 @@ func NewAdmin(
 	apiKey string,
 	clientApp App,
 ) (service.Admin, error) {
+	as, err := newHTTPAdminV1(dest, apiKey, clientApp)
+	if err != nil {
+		return nil, err
+	}
+
+	return as, nil
+}
+
+// NewAdminContext creates a new service.AdminContext backed by a Turbine api
+// server at dest. See NewAllContext.
+//
+// Parameters: See NewAll.
+func NewAdminContext(
+	dest apihttp.Endpoint,
+	apiKey string,
+	clientApp App,
+) (service.AdminContext, error) {
+	as, err := newHTTPAdminV1(dest, apiKey, clientApp)
+	if err != nil {
+		return nil, err
+	}
+
+	return (*httpAdminContextV1)(as), nil
+}
+
+func newHTTPAdminV1(
+	dest apihttp.Endpoint,
+	apiKey string,
+	clientApp App,
+) (*httpAdminV1, error) {
 	dest = configureEndpoint(dest, apiKey, clientApp)
 
-	u, err := NewUserV1(dest)
+	u, err := NewUserContextV1(dest)
 	if err != nil {
 		return nil, err
 	}
-	at, err := NewAccessTokenV1(dest)
+	o, err := NewOrgContextV1(dest)
+	if err != nil {
+		return nil, err
+	}
+	at, err := NewAccessTokenContextV1(dest)
 	if err != nil {
 		return nil, err
 	}
 
-	httpAdmin := httpAdminV1{u, at}
-
-	return &httpAdmin, nil
+	return &httpAdminV1{u, o, at}, nil
 }
 
 func configureEndpoint(dest apihttp.Endpoint, apiKey string, clientApp App) apihttp.Endpoint {
@@ -164,60 +229,140 @@ type httpServiceV1 struct {
 	listenerV1    *httpListenerV1
 	zoneV1        *httpZoneV1
 	historyV1     *httpHistoryV1
+	batchV1       *httpBatchV1
 }
 
 // Returns an implementation of service.Cluster.
 func (hs *httpServiceV1) Cluster() service.Cluster {
-	return hs.clusterV1
+	return service.FromClusterContext(hs.clusterV1)
 }
 
 // Returns an implementation of service.Domain.
 func (hs *httpServiceV1) Domain() service.Domain {
-	return hs.domainV1
+	return service.FromDomainContext(hs.domainV1)
 }
 
 // Returns an implementation of service.SharedRules.
 func (hs *httpServiceV1) SharedRules() service.SharedRules {
-	return hs.sharedRulesV1
+	return service.FromSharedRulesContext(hs.sharedRulesV1)
 }
 
 // Returns an implementation of service.Route.
 func (hs *httpServiceV1) Route() service.Route {
-	return hs.routeV1
+	return service.FromRouteContext(hs.routeV1)
 }
 
 // Returns an implementation of service.Proxy.
 func (hs *httpServiceV1) Proxy() service.Proxy {
-	return hs.proxyV1
+	return service.FromProxyContext(hs.proxyV1)
 }
 
 // Returns an implementation of service.Listener
 func (hs *httpServiceV1) Listener() service.Listener {
-	return hs.listenerV1
+	return service.FromListenerContext(hs.listenerV1)
 }
 
 // Returns an implementation of service.Zone.
 func (hs *httpServiceV1) Zone() service.Zone {
-	return hs.zoneV1
+	return service.FromZoneContext(hs.zoneV1)
 }
 
 // Returns an implementation of service.History.
 func (hs *httpServiceV1) History() service.History {
-	return hs.historyV1
+	return service.FromHistoryContext(hs.historyV1)
+}
+
+// Returns an implementation of service.Batch.
+func (hs *httpServiceV1) Batch() service.Batch {
+	return service.FromBatchContext(hs.batchV1)
 }
 
 // v1 http-backed service that implements Admin via HTTP calls to some
 // backend. For the implementation of each sub interface see in gen_XYZ.go
 type httpAdminV1 struct {
 	userV1        *httpUserV1
+	orgV1         *httpOrgV1
 	accessTokenV1 *httpAccessTokenV1
 }
 
 // Returns an implementation of service.User.
 func (as *httpAdminV1) User() service.User {
-	return as.userV1
+	return service.FromUserContext(as.userV1)
+}
+
+// Returns an implementation of service.Org.
+func (as *httpAdminV1) Org() service.Org {
+	return service.FromOrgContext(as.orgV1)
 }
 
 func (as *httpAdminV1) AccessToken() service.AccessToken {
+	return service.FromAccessTokenContext(as.accessTokenV1)
+}
+
+// v1 http-backed service that implements service.AllContext. It shares its
+// representation with httpServiceV1.
+type httpServiceContextV1 httpServiceV1
+
+// Returns an implementation of service.ClusterContext.
+func (hs *httpServiceContextV1) Cluster() service.ClusterContext {
+	return hs.clusterV1
+}
+
+// Returns an implementation of service.DomainContext.
+func (hs *httpServiceContextV1) Domain() service.DomainContext {
+	return hs.domainV1
+}
+
+// Returns an implementation of service.SharedRulesContext.
+func (hs *httpServiceContextV1) SharedRules() service.SharedRulesContext {
+	return hs.sharedRulesV1
+}
+
+// Returns an implementation of service.RouteContext.
+func (hs *httpServiceContextV1) Route() service.RouteContext {
+	return hs.routeV1
+}
+
+// Returns an implementation of service.ProxyContext.
+func (hs *httpServiceContextV1) Proxy() service.ProxyContext {
+	return hs.proxyV1
+}
+
+// Returns an implementation of service.ListenerContext.
+func (hs *httpServiceContextV1) Listener() service.ListenerContext {
+	return hs.listenerV1
+}
+
+// Returns an implementation of service.ZoneContext.
+func (hs *httpServiceContextV1) Zone() service.ZoneContext {
+	return hs.zoneV1
+}
+
+// Returns an implementation of service.HistoryContext.
+func (hs *httpServiceContextV1) History() service.HistoryContext {
+	return hs.historyV1
+}
+
+// Returns an implementation of service.BatchContext.
+func (hs *httpServiceContextV1) Batch() service.BatchContext {
+	return hs.batchV1
+}
+
+// v1 http-backed service that implements service.AdminContext. It shares its
+// representation with httpAdminV1.
+type httpAdminContextV1 httpAdminV1
+
+// Returns an implementation of service.UserContext.
+func (as *httpAdminContextV1) User() service.UserContext {
+	return as.userV1
+}
+
+// Returns an implementation of service.OrgContext.
+func (as *httpAdminContextV1) Org() service.OrgContext {
+	return as.orgV1
+}
+
+// Returns an implementation of service.AccessTokenContext.
+func (as *httpAdminContextV1) AccessToken() service.AccessTokenContext {
 	return as.accessTokenV1
 }
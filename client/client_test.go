@@ -43,6 +43,7 @@ const (
 	sharedRulesCommonURL = "/v1.0/shared_rules"
 	zoneCommonURL        = "/v1.0/zone"
 	userCommonURL        = "/v1.0/admin/user"
+	orgCommonURL         = "/v1.0/admin/org"
 )
 
 var fixtures = fixture.New()
@@ -17,6 +17,7 @@ limitations under the License.
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -29,6 +30,7 @@ import (
 )
 
 func (hc *httpClusterV1) AddInstance(
+	ctx context.Context,
 	clusterKey api.ClusterKey,
 	checksum api.Checksum,
 	instance api.Instance,
@@ -42,14 +44,18 @@ func (hc *httpClusterV1) AddInstance(
 	}
 
 	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, apihttp.Params{queryargs.Checksum: checksum.Checksum})
+		if err != nil {
+			return nil, err
+		}
 		return hc.post(
 			fmt.Sprintf("/%s/instance", url.QueryEscape(string(clusterKey))),
-			apihttp.Params{queryargs.Checksum: checksum.Checksum},
+			params,
 			encoded)
 	}
 	response := api.Cluster{}
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Cluster{}, err
 	}
 
@@ -57,6 +63,7 @@ func (hc *httpClusterV1) AddInstance(
 }
 
 func (hc *httpClusterV1) RemoveInstance(
+	ctx context.Context,
 	clusterKey api.ClusterKey,
 	checksum api.Checksum,
 	instance api.Instance,
@@ -67,11 +74,18 @@ func (hc *httpClusterV1) RemoveInstance(
 	instPath := fmt.Sprintf("/%s/instance/%s:%s", ckey, host, port)
 
 	reqFn := func() (*http.Request, error) {
-		return hc.delete(instPath, apihttp.Params{queryargs.Checksum: checksum.Checksum})
+		params, err := mutationParams(ctx, apihttp.Params{queryargs.Checksum: checksum.Checksum})
+		if err != nil {
+			return nil, err
+		}
+		return hc.delete(
+			instPath,
+			params,
+		)
 	}
 	response := api.Cluster{}
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Cluster{}, err
 	}
 
@@ -0,0 +1,124 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/turbinelabs/api"
+	apihttp "github.com/turbinelabs/api/http"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/test/assert"
+)
+
+func TestNewAllContextPropagatesDeadline(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-done
+		}),
+	)
+	defer server.Close()
+	defer close(done)
+
+	svc, err := NewAllContext(newTestEndpointFromServer(server), clientTestAPIKey, clientTestApp)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = svc.Cluster().Index(ctx)
+	assert.ErrorContains(t, err, context.DeadlineExceeded.Error())
+
+	_, err = svc.History().Zone(ctx, "zk", time.Time{}, time.Time{})
+	assert.ErrorContains(t, err, context.DeadlineExceeded.Error())
+}
+
+func TestNewAllContextSharesEndpoint(t *testing.T) {
+	e := newTestEndpoint("example.com:80")
+
+	svc, err := NewAllContext(e, clientTestAPIKey, clientTestApp)
+	assert.Nil(t, err)
+
+	hs := (*httpServiceV1)(svc.(*httpServiceContextV1))
+	r, err := hs.clusterV1.dest.NewRequest("GET", "/index.html", apihttp.Params{}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, len(r.Header), 4)
+}
+
+func TestNewAdminContextCanceled(t *testing.T) {
+	verifier := verifyingHandler{
+		fn:       func(apihttp.RichRequest) { t.Error("unexpected request") },
+		status:   http.StatusOK,
+		response: api.User{},
+	}
+	server := httptest.NewServer(verifier)
+	defer server.Close()
+
+	admin, err := NewAdminContext(newTestEndpointFromServer(server), clientTestAPIKey, clientTestApp)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = admin.User().Get(ctx, "uk")
+	assert.ErrorContains(t, err, context.Canceled.Error())
+}
+
+func TestNewClusterV1(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, r.URL.Path, "/v1.0/cluster/ck")
+			apihttp.RichResponseWriter{ResponseWriter: w}.WriteEnvelope(
+				nil,
+				api.Cluster{ClusterKey: "ck"},
+			)
+		}),
+	)
+	defer server.Close()
+
+	var c service.Cluster
+	c, err := NewClusterV1(newTestEndpointFromServer(server))
+	assert.Nil(t, err)
+
+	cluster, err := c.Get("ck")
+	assert.Nil(t, err)
+	assert.Equal(t, cluster.ClusterKey, api.ClusterKey("ck"))
+}
+
+func TestNewClusterContextV1(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("unexpected request")
+		}),
+	)
+	defer server.Close()
+
+	var c service.ClusterContext
+	c, err := NewClusterContextV1(newTestEndpointFromServer(server))
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = c.Get(ctx, "ck")
+	assert.ErrorContains(t, err, context.Canceled.Error())
+}
@@ -27,4 +27,5 @@ package client
 // admin-rooted objects
 
 //go:generate codegen --output=gen_user.go object.template Key=github.com/turbinelabs/api.UserKey Object=github.com/turbinelabs/api.User ObjectArray=github.com/turbinelabs/api.Users Root=/admin
+//go:generate codegen --output=gen_org.go object.template Key=github.com/turbinelabs/api.OrgKey Object=github.com/turbinelabs/api.Org ObjectArray=github.com/turbinelabs/api.Orgs Root=/admin
 // access token not generated because it doesn't expose a general Modify endpoint
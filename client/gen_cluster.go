@@ -23,6 +23,7 @@ package client
 // Any changes will be lost if this file is regenerated.
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -48,8 +49,28 @@ type httpClusterV1 struct {
 //	dest - service handling our HTTP requests; cf. NewService
 func NewClusterV1(
 	dest apihttp.Endpoint,
+) (service.Cluster, error) {
+	hc, err := NewClusterContextV1(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.FromClusterContext(hc), nil
+}
+
+// NewClusterContextV1 constructs a new HTTP backed api.Cluster API
+// implementation whose methods apply the context.Context they are passed to
+// each request.
+//
+// Parameters:
+//	dest - service handling our HTTP requests; cf. NewService
+func NewClusterContextV1(
+	dest apihttp.Endpoint,
 ) (*httpClusterV1, error) {
-	return &httpClusterV1{dest, apihttp.NewRequestHandler(dest.Client())}, nil
+	return &httpClusterV1{
+		dest,
+		apihttp.NewRetryingRequestHandler(dest.Client(), dest.RetryPolicy()),
+	}, nil
 }
 
 // creates a cluster-scoped version of the specified path
@@ -99,7 +120,10 @@ func (hc *httpClusterV1) delete(path string, params apihttp.Params) (*http.Reque
 	return hc.request(http.MethodDelete, path, params, "")
 }
 
-func (hc *httpClusterV1) Index(filters ...service.ClusterFilter) (api.Clusters, error) {
+func (hc *httpClusterV1) Index(
+	ctx context.Context,
+	filters ...service.ClusterFilter,
+) (api.Clusters, error) {
 	params := apihttp.Params{}
 
 	if filters != nil && len(filters) != 0 {
@@ -115,27 +139,30 @@ func (hc *httpClusterV1) Index(filters ...service.ClusterFilter) (api.Clusters,
 	}
 
 	response := make(api.Clusters, 0, 10)
-	reqFn := func() (*http.Request, error) { return hc.get("", params) }
+	reqFn := func() (*http.Request, error) { return hc.get("", readParams(ctx, params)) }
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return nil, err
 	}
 
 	return response, nil
 }
 
-func (hc *httpClusterV1) Get(key api.ClusterKey) (api.Cluster, error) {
+func (hc *httpClusterV1) Get(
+	ctx context.Context,
+	key api.ClusterKey,
+) (api.Cluster, error) {
 	if key == "" {
 		return api.Cluster{}, httperr.New400(
 			"ClusterKey is a required parameter", httperr.ObjectKeyRequiredErrorCode)
 	}
 
 	reqFn := func() (*http.Request, error) {
-		return hc.get("/"+url.QueryEscape(string(key)), nil)
+		return hc.get("/"+url.QueryEscape(string(key)), readParams(ctx, nil))
 	}
 
 	response := api.Cluster{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Cluster{}, err
 	}
 
@@ -147,7 +174,10 @@ func mkEncodeClusterError(cluster api.Cluster) *httperr.Error {
 	return httperr.New400(msg, httperr.UnknownEncodingCode)
 }
 
-func (hc *httpClusterV1) Create(newCluster api.Cluster) (api.Cluster, error) {
+func (hc *httpClusterV1) Create(
+	ctx context.Context,
+	newCluster api.Cluster,
+) (api.Cluster, error) {
 	encoded := ""
 
 	if b, err := json.Marshal(newCluster); err == nil {
@@ -156,16 +186,25 @@ func (hc *httpClusterV1) Create(newCluster api.Cluster) (api.Cluster, error) {
 		return api.Cluster{}, mkEncodeClusterError(newCluster)
 	}
 
-	reqFn := func() (*http.Request, error) { return hc.post("", nil, encoded) }
+	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.post("", params, encoded)
+	}
 	response := api.Cluster{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Cluster{}, err
 	}
 
 	return response, nil
 }
 
-func (hc *httpClusterV1) Modify(cluster api.Cluster) (api.Cluster, error) {
+func (hc *httpClusterV1) Modify(
+	ctx context.Context,
+	cluster api.Cluster,
+) (api.Cluster, error) {
 	encoded := ""
 
 	if b, err := json.Marshal(cluster); err == nil {
@@ -176,10 +215,14 @@ func (hc *httpClusterV1) Modify(cluster api.Cluster) (api.Cluster, error) {
 
 	response := api.Cluster{}
 	reqFn := func() (*http.Request, error) {
-		return hc.put("/"+url.QueryEscape(string(cluster.ClusterKey)), nil, encoded)
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.put("/"+url.QueryEscape(string(cluster.ClusterKey)), params, encoded)
 	}
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Cluster{}, err
 	}
 
@@ -187,17 +230,22 @@ func (hc *httpClusterV1) Modify(cluster api.Cluster) (api.Cluster, error) {
 }
 
 func (hc *httpClusterV1) Delete(
+	ctx context.Context,
 	clusterKey api.ClusterKey,
 	checksum api.Checksum,
 ) error {
 	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, apihttp.Params{queryargs.Checksum: checksum.Checksum})
+		if err != nil {
+			return nil, err
+		}
 		return hc.delete(
 			"/"+url.QueryEscape(string(clusterKey)),
-			apihttp.Params{queryargs.Checksum: checksum.Checksum},
+			params,
 		)
 	}
 
-	if err := hc.requestHandler.Do(reqFn, nil); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, nil); err != nil {
 		return err
 	}
 
@@ -23,6 +23,7 @@ package client
 // Any changes will be lost if this file is regenerated.
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -48,8 +49,28 @@ type httpDomainV1 struct {
 //	dest - service handling our HTTP requests; cf. NewService
 func NewDomainV1(
 	dest apihttp.Endpoint,
+) (service.Domain, error) {
+	hc, err := NewDomainContextV1(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.FromDomainContext(hc), nil
+}
+
+// NewDomainContextV1 constructs a new HTTP backed api.Domain API
+// implementation whose methods apply the context.Context they are passed to
+// each request.
+//
+// Parameters:
+//	dest - service handling our HTTP requests; cf. NewService
+func NewDomainContextV1(
+	dest apihttp.Endpoint,
 ) (*httpDomainV1, error) {
-	return &httpDomainV1{dest, apihttp.NewRequestHandler(dest.Client())}, nil
+	return &httpDomainV1{
+		dest,
+		apihttp.NewRetryingRequestHandler(dest.Client(), dest.RetryPolicy()),
+	}, nil
 }
 
 // creates a domain-scoped version of the specified path
@@ -99,7 +120,10 @@ func (hc *httpDomainV1) delete(path string, params apihttp.Params) (*http.Reques
 	return hc.request(http.MethodDelete, path, params, "")
 }
 
-func (hc *httpDomainV1) Index(filters ...service.DomainFilter) (api.Domains, error) {
+func (hc *httpDomainV1) Index(
+	ctx context.Context,
+	filters ...service.DomainFilter,
+) (api.Domains, error) {
 	params := apihttp.Params{}
 
 	if filters != nil && len(filters) != 0 {
@@ -115,27 +139,30 @@ func (hc *httpDomainV1) Index(filters ...service.DomainFilter) (api.Domains, err
 	}
 
 	response := make(api.Domains, 0, 10)
-	reqFn := func() (*http.Request, error) { return hc.get("", params) }
+	reqFn := func() (*http.Request, error) { return hc.get("", readParams(ctx, params)) }
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return nil, err
 	}
 
 	return response, nil
 }
 
-func (hc *httpDomainV1) Get(key api.DomainKey) (api.Domain, error) {
+func (hc *httpDomainV1) Get(
+	ctx context.Context,
+	key api.DomainKey,
+) (api.Domain, error) {
 	if key == "" {
 		return api.Domain{}, httperr.New400(
 			"DomainKey is a required parameter", httperr.ObjectKeyRequiredErrorCode)
 	}
 
 	reqFn := func() (*http.Request, error) {
-		return hc.get("/"+url.QueryEscape(string(key)), nil)
+		return hc.get("/"+url.QueryEscape(string(key)), readParams(ctx, nil))
 	}
 
 	response := api.Domain{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Domain{}, err
 	}
 
@@ -147,7 +174,10 @@ func mkEncodeDomainError(domain api.Domain) *httperr.Error {
 	return httperr.New400(msg, httperr.UnknownEncodingCode)
 }
 
-func (hc *httpDomainV1) Create(newDomain api.Domain) (api.Domain, error) {
+func (hc *httpDomainV1) Create(
+	ctx context.Context,
+	newDomain api.Domain,
+) (api.Domain, error) {
 	encoded := ""
 
 	if b, err := json.Marshal(newDomain); err == nil {
@@ -156,16 +186,25 @@ func (hc *httpDomainV1) Create(newDomain api.Domain) (api.Domain, error) {
 		return api.Domain{}, mkEncodeDomainError(newDomain)
 	}
 
-	reqFn := func() (*http.Request, error) { return hc.post("", nil, encoded) }
+	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.post("", params, encoded)
+	}
 	response := api.Domain{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Domain{}, err
 	}
 
 	return response, nil
 }
 
-func (hc *httpDomainV1) Modify(domain api.Domain) (api.Domain, error) {
+func (hc *httpDomainV1) Modify(
+	ctx context.Context,
+	domain api.Domain,
+) (api.Domain, error) {
 	encoded := ""
 
 	if b, err := json.Marshal(domain); err == nil {
@@ -176,10 +215,14 @@ func (hc *httpDomainV1) Modify(domain api.Domain) (api.Domain, error) {
 
 	response := api.Domain{}
 	reqFn := func() (*http.Request, error) {
-		return hc.put("/"+url.QueryEscape(string(domain.DomainKey)), nil, encoded)
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.put("/"+url.QueryEscape(string(domain.DomainKey)), params, encoded)
 	}
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Domain{}, err
 	}
 
@@ -187,17 +230,22 @@ func (hc *httpDomainV1) Modify(domain api.Domain) (api.Domain, error) {
 }
 
 func (hc *httpDomainV1) Delete(
+	ctx context.Context,
 	domainKey api.DomainKey,
 	checksum api.Checksum,
 ) error {
 	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, apihttp.Params{queryargs.Checksum: checksum.Checksum})
+		if err != nil {
+			return nil, err
+		}
 		return hc.delete(
 			"/"+url.QueryEscape(string(domainKey)),
-			apihttp.Params{queryargs.Checksum: checksum.Checksum},
+			params,
 		)
 	}
 
-	if err := hc.requestHandler.Do(reqFn, nil); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, nil); err != nil {
 		return err
 	}
 
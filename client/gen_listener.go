@@ -23,6 +23,7 @@ package client
 // Any changes will be lost if this file is regenerated.
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -48,8 +49,28 @@ type httpListenerV1 struct {
 //	dest - service handling our HTTP requests; cf. NewService
 func NewListenerV1(
 	dest apihttp.Endpoint,
+) (service.Listener, error) {
+	hc, err := NewListenerContextV1(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.FromListenerContext(hc), nil
+}
+
+// NewListenerContextV1 constructs a new HTTP backed api.Listener API
+// implementation whose methods apply the context.Context they are passed to
+// each request.
+//
+// Parameters:
+//	dest - service handling our HTTP requests; cf. NewService
+func NewListenerContextV1(
+	dest apihttp.Endpoint,
 ) (*httpListenerV1, error) {
-	return &httpListenerV1{dest, apihttp.NewRequestHandler(dest.Client())}, nil
+	return &httpListenerV1{
+		dest,
+		apihttp.NewRetryingRequestHandler(dest.Client(), dest.RetryPolicy()),
+	}, nil
 }
 
 // creates a listener-scoped version of the specified path
@@ -99,7 +120,10 @@ func (hc *httpListenerV1) delete(path string, params apihttp.Params) (*http.Requ
 	return hc.request(http.MethodDelete, path, params, "")
 }
 
-func (hc *httpListenerV1) Index(filters ...service.ListenerFilter) (api.Listeners, error) {
+func (hc *httpListenerV1) Index(
+	ctx context.Context,
+	filters ...service.ListenerFilter,
+) (api.Listeners, error) {
 	params := apihttp.Params{}
 
 	if filters != nil && len(filters) != 0 {
@@ -115,27 +139,30 @@ func (hc *httpListenerV1) Index(filters ...service.ListenerFilter) (api.Listener
 	}
 
 	response := make(api.Listeners, 0, 10)
-	reqFn := func() (*http.Request, error) { return hc.get("", params) }
+	reqFn := func() (*http.Request, error) { return hc.get("", readParams(ctx, params)) }
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return nil, err
 	}
 
 	return response, nil
 }
 
-func (hc *httpListenerV1) Get(key api.ListenerKey) (api.Listener, error) {
+func (hc *httpListenerV1) Get(
+	ctx context.Context,
+	key api.ListenerKey,
+) (api.Listener, error) {
 	if key == "" {
 		return api.Listener{}, httperr.New400(
 			"ListenerKey is a required parameter", httperr.ObjectKeyRequiredErrorCode)
 	}
 
 	reqFn := func() (*http.Request, error) {
-		return hc.get("/"+url.QueryEscape(string(key)), nil)
+		return hc.get("/"+url.QueryEscape(string(key)), readParams(ctx, nil))
 	}
 
 	response := api.Listener{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Listener{}, err
 	}
 
@@ -147,7 +174,10 @@ func mkEncodeListenerError(listener api.Listener) *httperr.Error {
 	return httperr.New400(msg, httperr.UnknownEncodingCode)
 }
 
-func (hc *httpListenerV1) Create(newListener api.Listener) (api.Listener, error) {
+func (hc *httpListenerV1) Create(
+	ctx context.Context,
+	newListener api.Listener,
+) (api.Listener, error) {
 	encoded := ""
 
 	if b, err := json.Marshal(newListener); err == nil {
@@ -156,16 +186,25 @@ func (hc *httpListenerV1) Create(newListener api.Listener) (api.Listener, error)
 		return api.Listener{}, mkEncodeListenerError(newListener)
 	}
 
-	reqFn := func() (*http.Request, error) { return hc.post("", nil, encoded) }
+	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.post("", params, encoded)
+	}
 	response := api.Listener{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Listener{}, err
 	}
 
 	return response, nil
 }
 
-func (hc *httpListenerV1) Modify(listener api.Listener) (api.Listener, error) {
+func (hc *httpListenerV1) Modify(
+	ctx context.Context,
+	listener api.Listener,
+) (api.Listener, error) {
 	encoded := ""
 
 	if b, err := json.Marshal(listener); err == nil {
@@ -176,10 +215,14 @@ func (hc *httpListenerV1) Modify(listener api.Listener) (api.Listener, error) {
 
 	response := api.Listener{}
 	reqFn := func() (*http.Request, error) {
-		return hc.put("/"+url.QueryEscape(string(listener.ListenerKey)), nil, encoded)
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.put("/"+url.QueryEscape(string(listener.ListenerKey)), params, encoded)
 	}
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Listener{}, err
 	}
 
@@ -187,17 +230,22 @@ func (hc *httpListenerV1) Modify(listener api.Listener) (api.Listener, error) {
 }
 
 func (hc *httpListenerV1) Delete(
+	ctx context.Context,
 	listenerKey api.ListenerKey,
 	checksum api.Checksum,
 ) error {
 	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, apihttp.Params{queryargs.Checksum: checksum.Checksum})
+		if err != nil {
+			return nil, err
+		}
 		return hc.delete(
 			"/"+url.QueryEscape(string(listenerKey)),
-			apihttp.Params{queryargs.Checksum: checksum.Checksum},
+			params,
 		)
 	}
 
-	if err := hc.requestHandler.Do(reqFn, nil); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, nil); err != nil {
 		return err
 	}
 
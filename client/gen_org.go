@@ -0,0 +1,257 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+// This file was automatically generated by
+//   github.com/turbinelabs/api/client/gen.go
+// from
+//   object.template.
+// Any changes will be lost if this file is regenerated.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/turbinelabs/api"
+	apihttp "github.com/turbinelabs/api/http"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/queryargs"
+	"github.com/turbinelabs/api/service"
+)
+
+type httpOrgV1 struct {
+	dest apihttp.Endpoint
+
+	requestHandler apihttp.RequestHandler
+}
+
+// Construct a new HTTP backed api.Org API implementation.
+//
+// Parameters:
+//	dest - service handling our HTTP requests; cf. NewService
+func NewOrgV1(
+	dest apihttp.Endpoint,
+) (service.Org, error) {
+	hc, err := NewOrgContextV1(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.FromOrgContext(hc), nil
+}
+
+// NewOrgContextV1 constructs a new HTTP backed api.Org API
+// implementation whose methods apply the context.Context they are passed to
+// each request.
+//
+// Parameters:
+//	dest - service handling our HTTP requests; cf. NewService
+func NewOrgContextV1(
+	dest apihttp.Endpoint,
+) (*httpOrgV1, error) {
+	return &httpOrgV1{
+		dest,
+		apihttp.NewRetryingRequestHandler(dest.Client(), dest.RetryPolicy()),
+	}, nil
+}
+
+// creates a org-scoped version of the specified path
+func (hc *httpOrgV1) path(p string) string {
+	return "/v1.0/admin/org" + p
+}
+
+// Construct a request to the associated org Endpoint with a specified
+// method, path, query params, and body.
+func (hc *httpOrgV1) request(
+	method string,
+	path string,
+	params apihttp.Params,
+	body string,
+) (*http.Request, error) {
+	rdr := strings.NewReader(body)
+	req, err := hc.dest.NewRequest(string(method), hc.path(path), params, rdr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (hc *httpOrgV1) get(path string, params apihttp.Params) (*http.Request, error) {
+	return hc.request(http.MethodGet, path, params, "")
+}
+
+func (hc *httpOrgV1) post(
+	path string,
+	params apihttp.Params,
+	body string,
+) (*http.Request, error) {
+	return hc.request(http.MethodPost, path, params, body)
+}
+
+func (hc *httpOrgV1) put(
+	path string,
+	params apihttp.Params,
+	body string,
+) (*http.Request, error) {
+	return hc.request(http.MethodPut, path, params, body)
+}
+
+func (hc *httpOrgV1) delete(path string, params apihttp.Params) (*http.Request, error) {
+	return hc.request(http.MethodDelete, path, params, "")
+}
+
+func (hc *httpOrgV1) Index(
+	ctx context.Context,
+	filters ...service.OrgFilter,
+) (api.Orgs, error) {
+	params := apihttp.Params{}
+
+	if filters != nil && len(filters) != 0 {
+		filterBytes, err := json.Marshal(filters)
+		if err != nil {
+			return nil, httperr.New400(
+				fmt.Sprintf("unable to encode org filters: %v: %s", filters, err),
+				httperr.UnknownUnclassifiedCode,
+			)
+		}
+
+		params[queryargs.IndexFilters] = string(filterBytes)
+	}
+
+	response := make(api.Orgs, 0, 10)
+	reqFn := func() (*http.Request, error) { return hc.get("", readParams(ctx, params)) }
+
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (hc *httpOrgV1) Get(
+	ctx context.Context,
+	key api.OrgKey,
+) (api.Org, error) {
+	if key == "" {
+		return api.Org{}, httperr.New400(
+			"OrgKey is a required parameter", httperr.ObjectKeyRequiredErrorCode)
+	}
+
+	reqFn := func() (*http.Request, error) {
+		return hc.get("/"+url.QueryEscape(string(key)), readParams(ctx, nil))
+	}
+
+	response := api.Org{}
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
+		return api.Org{}, err
+	}
+
+	return response, nil
+}
+
+func mkEncodeOrgError(org api.Org) *httperr.Error {
+	msg := fmt.Sprintf("could not encode provided org: %+v", org)
+	return httperr.New400(msg, httperr.UnknownEncodingCode)
+}
+
+func (hc *httpOrgV1) Create(
+	ctx context.Context,
+	newOrg api.Org,
+) (api.Org, error) {
+	encoded := ""
+
+	if b, err := json.Marshal(newOrg); err == nil {
+		encoded = string(b)
+	} else {
+		return api.Org{}, mkEncodeOrgError(newOrg)
+	}
+
+	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.post("", params, encoded)
+	}
+	response := api.Org{}
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
+		return api.Org{}, err
+	}
+
+	return response, nil
+}
+
+func (hc *httpOrgV1) Modify(
+	ctx context.Context,
+	org api.Org,
+) (api.Org, error) {
+	encoded := ""
+
+	if b, err := json.Marshal(org); err == nil {
+		encoded = string(b)
+	} else {
+		return api.Org{}, mkEncodeOrgError(org)
+	}
+
+	response := api.Org{}
+	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.put("/"+url.QueryEscape(string(org.OrgKey)), params, encoded)
+	}
+
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
+		return api.Org{}, err
+	}
+
+	return response, nil
+}
+
+func (hc *httpOrgV1) Delete(
+	ctx context.Context,
+	orgKey api.OrgKey,
+	checksum api.Checksum,
+) error {
+	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, apihttp.Params{queryargs.Checksum: checksum.Checksum})
+		if err != nil {
+			return nil, err
+		}
+		return hc.delete(
+			"/"+url.QueryEscape(string(orgKey)),
+			params,
+		)
+	}
+
+	if err := hc.requestHandler.DoContext(ctx, reqFn, nil); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (hc *httpOrgV1) Purge(_ api.OrgKey, _ api.Checksum) error {
+	return httperr.New501("Purge not implemented", httperr.MiscErrorCode)
+}
@@ -23,6 +23,7 @@ package client
 // Any changes will be lost if this file is regenerated.
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -48,8 +49,28 @@ type httpProxyV1 struct {
 //	dest - service handling our HTTP requests; cf. NewService
 func NewProxyV1(
 	dest apihttp.Endpoint,
+) (service.Proxy, error) {
+	hc, err := NewProxyContextV1(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.FromProxyContext(hc), nil
+}
+
+// NewProxyContextV1 constructs a new HTTP backed api.Proxy API
+// implementation whose methods apply the context.Context they are passed to
+// each request.
+//
+// Parameters:
+//	dest - service handling our HTTP requests; cf. NewService
+func NewProxyContextV1(
+	dest apihttp.Endpoint,
 ) (*httpProxyV1, error) {
-	return &httpProxyV1{dest, apihttp.NewRequestHandler(dest.Client())}, nil
+	return &httpProxyV1{
+		dest,
+		apihttp.NewRetryingRequestHandler(dest.Client(), dest.RetryPolicy()),
+	}, nil
 }
 
 // creates a proxy-scoped version of the specified path
@@ -99,7 +120,10 @@ func (hc *httpProxyV1) delete(path string, params apihttp.Params) (*http.Request
 	return hc.request(http.MethodDelete, path, params, "")
 }
 
-func (hc *httpProxyV1) Index(filters ...service.ProxyFilter) (api.Proxies, error) {
+func (hc *httpProxyV1) Index(
+	ctx context.Context,
+	filters ...service.ProxyFilter,
+) (api.Proxies, error) {
 	params := apihttp.Params{}
 
 	if filters != nil && len(filters) != 0 {
@@ -115,27 +139,30 @@ func (hc *httpProxyV1) Index(filters ...service.ProxyFilter) (api.Proxies, error
 	}
 
 	response := make(api.Proxies, 0, 10)
-	reqFn := func() (*http.Request, error) { return hc.get("", params) }
+	reqFn := func() (*http.Request, error) { return hc.get("", readParams(ctx, params)) }
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return nil, err
 	}
 
 	return response, nil
 }
 
-func (hc *httpProxyV1) Get(key api.ProxyKey) (api.Proxy, error) {
+func (hc *httpProxyV1) Get(
+	ctx context.Context,
+	key api.ProxyKey,
+) (api.Proxy, error) {
 	if key == "" {
 		return api.Proxy{}, httperr.New400(
 			"ProxyKey is a required parameter", httperr.ObjectKeyRequiredErrorCode)
 	}
 
 	reqFn := func() (*http.Request, error) {
-		return hc.get("/"+url.QueryEscape(string(key)), nil)
+		return hc.get("/"+url.QueryEscape(string(key)), readParams(ctx, nil))
 	}
 
 	response := api.Proxy{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Proxy{}, err
 	}
 
@@ -147,7 +174,10 @@ func mkEncodeProxyError(proxy api.Proxy) *httperr.Error {
 	return httperr.New400(msg, httperr.UnknownEncodingCode)
 }
 
-func (hc *httpProxyV1) Create(newProxy api.Proxy) (api.Proxy, error) {
+func (hc *httpProxyV1) Create(
+	ctx context.Context,
+	newProxy api.Proxy,
+) (api.Proxy, error) {
 	encoded := ""
 
 	if b, err := json.Marshal(newProxy); err == nil {
@@ -156,16 +186,25 @@ func (hc *httpProxyV1) Create(newProxy api.Proxy) (api.Proxy, error) {
 		return api.Proxy{}, mkEncodeProxyError(newProxy)
 	}
 
-	reqFn := func() (*http.Request, error) { return hc.post("", nil, encoded) }
+	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.post("", params, encoded)
+	}
 	response := api.Proxy{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Proxy{}, err
 	}
 
 	return response, nil
 }
 
-func (hc *httpProxyV1) Modify(proxy api.Proxy) (api.Proxy, error) {
+func (hc *httpProxyV1) Modify(
+	ctx context.Context,
+	proxy api.Proxy,
+) (api.Proxy, error) {
 	encoded := ""
 
 	if b, err := json.Marshal(proxy); err == nil {
@@ -176,10 +215,14 @@ func (hc *httpProxyV1) Modify(proxy api.Proxy) (api.Proxy, error) {
 
 	response := api.Proxy{}
 	reqFn := func() (*http.Request, error) {
-		return hc.put("/"+url.QueryEscape(string(proxy.ProxyKey)), nil, encoded)
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.put("/"+url.QueryEscape(string(proxy.ProxyKey)), params, encoded)
 	}
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Proxy{}, err
 	}
 
@@ -187,17 +230,22 @@ func (hc *httpProxyV1) Modify(proxy api.Proxy) (api.Proxy, error) {
 }
 
 func (hc *httpProxyV1) Delete(
+	ctx context.Context,
 	proxyKey api.ProxyKey,
 	checksum api.Checksum,
 ) error {
 	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, apihttp.Params{queryargs.Checksum: checksum.Checksum})
+		if err != nil {
+			return nil, err
+		}
 		return hc.delete(
 			"/"+url.QueryEscape(string(proxyKey)),
-			apihttp.Params{queryargs.Checksum: checksum.Checksum},
+			params,
 		)
 	}
 
-	if err := hc.requestHandler.Do(reqFn, nil); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, nil); err != nil {
 		return err
 	}
 
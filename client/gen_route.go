@@ -23,6 +23,7 @@ package client
 // Any changes will be lost if this file is regenerated.
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -48,8 +49,28 @@ type httpRouteV1 struct {
 //	dest - service handling our HTTP requests; cf. NewService
 func NewRouteV1(
 	dest apihttp.Endpoint,
+) (service.Route, error) {
+	hc, err := NewRouteContextV1(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.FromRouteContext(hc), nil
+}
+
+// NewRouteContextV1 constructs a new HTTP backed api.Route API
+// implementation whose methods apply the context.Context they are passed to
+// each request.
+//
+// Parameters:
+//	dest - service handling our HTTP requests; cf. NewService
+func NewRouteContextV1(
+	dest apihttp.Endpoint,
 ) (*httpRouteV1, error) {
-	return &httpRouteV1{dest, apihttp.NewRequestHandler(dest.Client())}, nil
+	return &httpRouteV1{
+		dest,
+		apihttp.NewRetryingRequestHandler(dest.Client(), dest.RetryPolicy()),
+	}, nil
 }
 
 // creates a route-scoped version of the specified path
@@ -99,7 +120,10 @@ func (hc *httpRouteV1) delete(path string, params apihttp.Params) (*http.Request
 	return hc.request(http.MethodDelete, path, params, "")
 }
 
-func (hc *httpRouteV1) Index(filters ...service.RouteFilter) (api.Routes, error) {
+func (hc *httpRouteV1) Index(
+	ctx context.Context,
+	filters ...service.RouteFilter,
+) (api.Routes, error) {
 	params := apihttp.Params{}
 
 	if filters != nil && len(filters) != 0 {
@@ -115,27 +139,30 @@ func (hc *httpRouteV1) Index(filters ...service.RouteFilter) (api.Routes, error)
 	}
 
 	response := make(api.Routes, 0, 10)
-	reqFn := func() (*http.Request, error) { return hc.get("", params) }
+	reqFn := func() (*http.Request, error) { return hc.get("", readParams(ctx, params)) }
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return nil, err
 	}
 
 	return response, nil
 }
 
-func (hc *httpRouteV1) Get(key api.RouteKey) (api.Route, error) {
+func (hc *httpRouteV1) Get(
+	ctx context.Context,
+	key api.RouteKey,
+) (api.Route, error) {
 	if key == "" {
 		return api.Route{}, httperr.New400(
 			"RouteKey is a required parameter", httperr.ObjectKeyRequiredErrorCode)
 	}
 
 	reqFn := func() (*http.Request, error) {
-		return hc.get("/"+url.QueryEscape(string(key)), nil)
+		return hc.get("/"+url.QueryEscape(string(key)), readParams(ctx, nil))
 	}
 
 	response := api.Route{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Route{}, err
 	}
 
@@ -147,7 +174,10 @@ func mkEncodeRouteError(route api.Route) *httperr.Error {
 	return httperr.New400(msg, httperr.UnknownEncodingCode)
 }
 
-func (hc *httpRouteV1) Create(newRoute api.Route) (api.Route, error) {
+func (hc *httpRouteV1) Create(
+	ctx context.Context,
+	newRoute api.Route,
+) (api.Route, error) {
 	encoded := ""
 
 	if b, err := json.Marshal(newRoute); err == nil {
@@ -156,16 +186,25 @@ func (hc *httpRouteV1) Create(newRoute api.Route) (api.Route, error) {
 		return api.Route{}, mkEncodeRouteError(newRoute)
 	}
 
-	reqFn := func() (*http.Request, error) { return hc.post("", nil, encoded) }
+	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.post("", params, encoded)
+	}
 	response := api.Route{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Route{}, err
 	}
 
 	return response, nil
 }
 
-func (hc *httpRouteV1) Modify(route api.Route) (api.Route, error) {
+func (hc *httpRouteV1) Modify(
+	ctx context.Context,
+	route api.Route,
+) (api.Route, error) {
 	encoded := ""
 
 	if b, err := json.Marshal(route); err == nil {
@@ -176,10 +215,14 @@ func (hc *httpRouteV1) Modify(route api.Route) (api.Route, error) {
 
 	response := api.Route{}
 	reqFn := func() (*http.Request, error) {
-		return hc.put("/"+url.QueryEscape(string(route.RouteKey)), nil, encoded)
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.put("/"+url.QueryEscape(string(route.RouteKey)), params, encoded)
 	}
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Route{}, err
 	}
 
@@ -187,17 +230,22 @@ func (hc *httpRouteV1) Modify(route api.Route) (api.Route, error) {
 }
 
 func (hc *httpRouteV1) Delete(
+	ctx context.Context,
 	routeKey api.RouteKey,
 	checksum api.Checksum,
 ) error {
 	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, apihttp.Params{queryargs.Checksum: checksum.Checksum})
+		if err != nil {
+			return nil, err
+		}
 		return hc.delete(
 			"/"+url.QueryEscape(string(routeKey)),
-			apihttp.Params{queryargs.Checksum: checksum.Checksum},
+			params,
 		)
 	}
 
-	if err := hc.requestHandler.Do(reqFn, nil); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, nil); err != nil {
 		return err
 	}
 
@@ -23,6 +23,7 @@ package client
 // Any changes will be lost if this file is regenerated.
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -48,8 +49,28 @@ type httpSharedRulesV1 struct {
 //	dest - service handling our HTTP requests; cf. NewService
 func NewSharedRulesV1(
 	dest apihttp.Endpoint,
+) (service.SharedRules, error) {
+	hc, err := NewSharedRulesContextV1(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.FromSharedRulesContext(hc), nil
+}
+
+// NewSharedRulesContextV1 constructs a new HTTP backed api.SharedRules API
+// implementation whose methods apply the context.Context they are passed to
+// each request.
+//
+// Parameters:
+//	dest - service handling our HTTP requests; cf. NewService
+func NewSharedRulesContextV1(
+	dest apihttp.Endpoint,
 ) (*httpSharedRulesV1, error) {
-	return &httpSharedRulesV1{dest, apihttp.NewRequestHandler(dest.Client())}, nil
+	return &httpSharedRulesV1{
+		dest,
+		apihttp.NewRetryingRequestHandler(dest.Client(), dest.RetryPolicy()),
+	}, nil
 }
 
 // creates a sharedRules-scoped version of the specified path
@@ -99,7 +120,10 @@ func (hc *httpSharedRulesV1) delete(path string, params apihttp.Params) (*http.R
 	return hc.request(http.MethodDelete, path, params, "")
 }
 
-func (hc *httpSharedRulesV1) Index(filters ...service.SharedRulesFilter) (api.SharedRulesSlice, error) {
+func (hc *httpSharedRulesV1) Index(
+	ctx context.Context,
+	filters ...service.SharedRulesFilter,
+) (api.SharedRulesSlice, error) {
 	params := apihttp.Params{}
 
 	if filters != nil && len(filters) != 0 {
@@ -115,27 +139,30 @@ func (hc *httpSharedRulesV1) Index(filters ...service.SharedRulesFilter) (api.Sh
 	}
 
 	response := make(api.SharedRulesSlice, 0, 10)
-	reqFn := func() (*http.Request, error) { return hc.get("", params) }
+	reqFn := func() (*http.Request, error) { return hc.get("", readParams(ctx, params)) }
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return nil, err
 	}
 
 	return response, nil
 }
 
-func (hc *httpSharedRulesV1) Get(key api.SharedRulesKey) (api.SharedRules, error) {
+func (hc *httpSharedRulesV1) Get(
+	ctx context.Context,
+	key api.SharedRulesKey,
+) (api.SharedRules, error) {
 	if key == "" {
 		return api.SharedRules{}, httperr.New400(
 			"SharedRulesKey is a required parameter", httperr.ObjectKeyRequiredErrorCode)
 	}
 
 	reqFn := func() (*http.Request, error) {
-		return hc.get("/"+url.QueryEscape(string(key)), nil)
+		return hc.get("/"+url.QueryEscape(string(key)), readParams(ctx, nil))
 	}
 
 	response := api.SharedRules{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.SharedRules{}, err
 	}
 
@@ -147,7 +174,10 @@ func mkEncodeSharedRulesError(sharedRules api.SharedRules) *httperr.Error {
 	return httperr.New400(msg, httperr.UnknownEncodingCode)
 }
 
-func (hc *httpSharedRulesV1) Create(newSharedRules api.SharedRules) (api.SharedRules, error) {
+func (hc *httpSharedRulesV1) Create(
+	ctx context.Context,
+	newSharedRules api.SharedRules,
+) (api.SharedRules, error) {
 	encoded := ""
 
 	if b, err := json.Marshal(newSharedRules); err == nil {
@@ -156,16 +186,25 @@ func (hc *httpSharedRulesV1) Create(newSharedRules api.SharedRules) (api.SharedR
 		return api.SharedRules{}, mkEncodeSharedRulesError(newSharedRules)
 	}
 
-	reqFn := func() (*http.Request, error) { return hc.post("", nil, encoded) }
+	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.post("", params, encoded)
+	}
 	response := api.SharedRules{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.SharedRules{}, err
 	}
 
 	return response, nil
 }
 
-func (hc *httpSharedRulesV1) Modify(sharedRules api.SharedRules) (api.SharedRules, error) {
+func (hc *httpSharedRulesV1) Modify(
+	ctx context.Context,
+	sharedRules api.SharedRules,
+) (api.SharedRules, error) {
 	encoded := ""
 
 	if b, err := json.Marshal(sharedRules); err == nil {
@@ -176,10 +215,14 @@ func (hc *httpSharedRulesV1) Modify(sharedRules api.SharedRules) (api.SharedRule
 
 	response := api.SharedRules{}
 	reqFn := func() (*http.Request, error) {
-		return hc.put("/"+url.QueryEscape(string(sharedRules.SharedRulesKey)), nil, encoded)
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.put("/"+url.QueryEscape(string(sharedRules.SharedRulesKey)), params, encoded)
 	}
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.SharedRules{}, err
 	}
 
@@ -187,17 +230,22 @@ func (hc *httpSharedRulesV1) Modify(sharedRules api.SharedRules) (api.SharedRule
 }
 
 func (hc *httpSharedRulesV1) Delete(
+	ctx context.Context,
 	sharedRulesKey api.SharedRulesKey,
 	checksum api.Checksum,
 ) error {
 	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, apihttp.Params{queryargs.Checksum: checksum.Checksum})
+		if err != nil {
+			return nil, err
+		}
 		return hc.delete(
 			"/"+url.QueryEscape(string(sharedRulesKey)),
-			apihttp.Params{queryargs.Checksum: checksum.Checksum},
+			params,
 		)
 	}
 
-	if err := hc.requestHandler.Do(reqFn, nil); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, nil); err != nil {
 		return err
 	}
 
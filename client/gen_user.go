@@ -23,6 +23,7 @@ package client
 // Any changes will be lost if this file is regenerated.
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -48,8 +49,28 @@ type httpUserV1 struct {
 //	dest - service handling our HTTP requests; cf. NewService
 func NewUserV1(
 	dest apihttp.Endpoint,
+) (service.User, error) {
+	hc, err := NewUserContextV1(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.FromUserContext(hc), nil
+}
+
+// NewUserContextV1 constructs a new HTTP backed api.User API
+// implementation whose methods apply the context.Context they are passed to
+// each request.
+//
+// Parameters:
+//	dest - service handling our HTTP requests; cf. NewService
+func NewUserContextV1(
+	dest apihttp.Endpoint,
 ) (*httpUserV1, error) {
-	return &httpUserV1{dest, apihttp.NewRequestHandler(dest.Client())}, nil
+	return &httpUserV1{
+		dest,
+		apihttp.NewRetryingRequestHandler(dest.Client(), dest.RetryPolicy()),
+	}, nil
 }
 
 // creates a user-scoped version of the specified path
@@ -99,7 +120,10 @@ func (hc *httpUserV1) delete(path string, params apihttp.Params) (*http.Request,
 	return hc.request(http.MethodDelete, path, params, "")
 }
 
-func (hc *httpUserV1) Index(filters ...service.UserFilter) (api.Users, error) {
+func (hc *httpUserV1) Index(
+	ctx context.Context,
+	filters ...service.UserFilter,
+) (api.Users, error) {
 	params := apihttp.Params{}
 
 	if filters != nil && len(filters) != 0 {
@@ -115,27 +139,30 @@ func (hc *httpUserV1) Index(filters ...service.UserFilter) (api.Users, error) {
 	}
 
 	response := make(api.Users, 0, 10)
-	reqFn := func() (*http.Request, error) { return hc.get("", params) }
+	reqFn := func() (*http.Request, error) { return hc.get("", readParams(ctx, params)) }
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return nil, err
 	}
 
 	return response, nil
 }
 
-func (hc *httpUserV1) Get(key api.UserKey) (api.User, error) {
+func (hc *httpUserV1) Get(
+	ctx context.Context,
+	key api.UserKey,
+) (api.User, error) {
 	if key == "" {
 		return api.User{}, httperr.New400(
 			"UserKey is a required parameter", httperr.ObjectKeyRequiredErrorCode)
 	}
 
 	reqFn := func() (*http.Request, error) {
-		return hc.get("/"+url.QueryEscape(string(key)), nil)
+		return hc.get("/"+url.QueryEscape(string(key)), readParams(ctx, nil))
 	}
 
 	response := api.User{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.User{}, err
 	}
 
@@ -147,7 +174,10 @@ func mkEncodeUserError(user api.User) *httperr.Error {
 	return httperr.New400(msg, httperr.UnknownEncodingCode)
 }
 
-func (hc *httpUserV1) Create(newUser api.User) (api.User, error) {
+func (hc *httpUserV1) Create(
+	ctx context.Context,
+	newUser api.User,
+) (api.User, error) {
 	encoded := ""
 
 	if b, err := json.Marshal(newUser); err == nil {
@@ -156,16 +186,25 @@ func (hc *httpUserV1) Create(newUser api.User) (api.User, error) {
 		return api.User{}, mkEncodeUserError(newUser)
 	}
 
-	reqFn := func() (*http.Request, error) { return hc.post("", nil, encoded) }
+	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.post("", params, encoded)
+	}
 	response := api.User{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.User{}, err
 	}
 
 	return response, nil
 }
 
-func (hc *httpUserV1) Modify(user api.User) (api.User, error) {
+func (hc *httpUserV1) Modify(
+	ctx context.Context,
+	user api.User,
+) (api.User, error) {
 	encoded := ""
 
 	if b, err := json.Marshal(user); err == nil {
@@ -176,10 +215,14 @@ func (hc *httpUserV1) Modify(user api.User) (api.User, error) {
 
 	response := api.User{}
 	reqFn := func() (*http.Request, error) {
-		return hc.put("/"+url.QueryEscape(string(user.UserKey)), nil, encoded)
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.put("/"+url.QueryEscape(string(user.UserKey)), params, encoded)
 	}
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.User{}, err
 	}
 
@@ -187,17 +230,22 @@ func (hc *httpUserV1) Modify(user api.User) (api.User, error) {
 }
 
 func (hc *httpUserV1) Delete(
+	ctx context.Context,
 	userKey api.UserKey,
 	checksum api.Checksum,
 ) error {
 	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, apihttp.Params{queryargs.Checksum: checksum.Checksum})
+		if err != nil {
+			return nil, err
+		}
 		return hc.delete(
 			"/"+url.QueryEscape(string(userKey)),
-			apihttp.Params{queryargs.Checksum: checksum.Checksum},
+			params,
 		)
 	}
 
-	if err := hc.requestHandler.Do(reqFn, nil); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, nil); err != nil {
 		return err
 	}
 
@@ -23,6 +23,7 @@ package client
 // Any changes will be lost if this file is regenerated.
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -48,8 +49,28 @@ type httpZoneV1 struct {
 //	dest - service handling our HTTP requests; cf. NewService
 func NewZoneV1(
 	dest apihttp.Endpoint,
+) (service.Zone, error) {
+	hc, err := NewZoneContextV1(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.FromZoneContext(hc), nil
+}
+
+// NewZoneContextV1 constructs a new HTTP backed api.Zone API
+// implementation whose methods apply the context.Context they are passed to
+// each request.
+//
+// Parameters:
+//	dest - service handling our HTTP requests; cf. NewService
+func NewZoneContextV1(
+	dest apihttp.Endpoint,
 ) (*httpZoneV1, error) {
-	return &httpZoneV1{dest, apihttp.NewRequestHandler(dest.Client())}, nil
+	return &httpZoneV1{
+		dest,
+		apihttp.NewRetryingRequestHandler(dest.Client(), dest.RetryPolicy()),
+	}, nil
 }
 
 // creates a zone-scoped version of the specified path
@@ -99,7 +120,10 @@ func (hc *httpZoneV1) delete(path string, params apihttp.Params) (*http.Request,
 	return hc.request(http.MethodDelete, path, params, "")
 }
 
-func (hc *httpZoneV1) Index(filters ...service.ZoneFilter) (api.Zones, error) {
+func (hc *httpZoneV1) Index(
+	ctx context.Context,
+	filters ...service.ZoneFilter,
+) (api.Zones, error) {
 	params := apihttp.Params{}
 
 	if filters != nil && len(filters) != 0 {
@@ -115,27 +139,30 @@ func (hc *httpZoneV1) Index(filters ...service.ZoneFilter) (api.Zones, error) {
 	}
 
 	response := make(api.Zones, 0, 10)
-	reqFn := func() (*http.Request, error) { return hc.get("", params) }
+	reqFn := func() (*http.Request, error) { return hc.get("", readParams(ctx, params)) }
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return nil, err
 	}
 
 	return response, nil
 }
 
-func (hc *httpZoneV1) Get(key api.ZoneKey) (api.Zone, error) {
+func (hc *httpZoneV1) Get(
+	ctx context.Context,
+	key api.ZoneKey,
+) (api.Zone, error) {
 	if key == "" {
 		return api.Zone{}, httperr.New400(
 			"ZoneKey is a required parameter", httperr.ObjectKeyRequiredErrorCode)
 	}
 
 	reqFn := func() (*http.Request, error) {
-		return hc.get("/"+url.QueryEscape(string(key)), nil)
+		return hc.get("/"+url.QueryEscape(string(key)), readParams(ctx, nil))
 	}
 
 	response := api.Zone{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Zone{}, err
 	}
 
@@ -147,7 +174,10 @@ func mkEncodeZoneError(zone api.Zone) *httperr.Error {
 	return httperr.New400(msg, httperr.UnknownEncodingCode)
 }
 
-func (hc *httpZoneV1) Create(newZone api.Zone) (api.Zone, error) {
+func (hc *httpZoneV1) Create(
+	ctx context.Context,
+	newZone api.Zone,
+) (api.Zone, error) {
 	encoded := ""
 
 	if b, err := json.Marshal(newZone); err == nil {
@@ -156,16 +186,25 @@ func (hc *httpZoneV1) Create(newZone api.Zone) (api.Zone, error) {
 		return api.Zone{}, mkEncodeZoneError(newZone)
 	}
 
-	reqFn := func() (*http.Request, error) { return hc.post("", nil, encoded) }
+	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.post("", params, encoded)
+	}
 	response := api.Zone{}
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Zone{}, err
 	}
 
 	return response, nil
 }
 
-func (hc *httpZoneV1) Modify(zone api.Zone) (api.Zone, error) {
+func (hc *httpZoneV1) Modify(
+	ctx context.Context,
+	zone api.Zone,
+) (api.Zone, error) {
 	encoded := ""
 
 	if b, err := json.Marshal(zone); err == nil {
@@ -176,10 +215,14 @@ func (hc *httpZoneV1) Modify(zone api.Zone) (api.Zone, error) {
 
 	response := api.Zone{}
 	reqFn := func() (*http.Request, error) {
-		return hc.put("/"+url.QueryEscape(string(zone.ZoneKey)), nil, encoded)
+		params, err := mutationParams(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hc.put("/"+url.QueryEscape(string(zone.ZoneKey)), params, encoded)
 	}
 
-	if err := hc.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return api.Zone{}, err
 	}
 
@@ -187,17 +230,22 @@ func (hc *httpZoneV1) Modify(zone api.Zone) (api.Zone, error) {
 }
 
 func (hc *httpZoneV1) Delete(
+	ctx context.Context,
 	zoneKey api.ZoneKey,
 	checksum api.Checksum,
 ) error {
 	reqFn := func() (*http.Request, error) {
+		params, err := mutationParams(ctx, apihttp.Params{queryargs.Checksum: checksum.Checksum})
+		if err != nil {
+			return nil, err
+		}
 		return hc.delete(
 			"/"+url.QueryEscape(string(zoneKey)),
-			apihttp.Params{queryargs.Checksum: checksum.Checksum},
+			params,
 		)
 	}
 
-	if err := hc.requestHandler.Do(reqFn, nil); err != nil {
+	if err := hc.requestHandler.DoContext(ctx, reqFn, nil); err != nil {
 		return err
 	}
 
@@ -131,6 +131,13 @@ type userGetTest struct {
 	wantErr     error
 }
 
+type orgGetTest struct {
+	orgKey      api.OrgKey
+	responseObj interface{}
+	wantResp    api.Org
+	wantErr     error
+}
+
 type zoneGetTest struct {
 	zoneKey     api.ZoneKey
 	responseObj interface{}
@@ -246,6 +253,33 @@ func (tc userGetTest) run(t *testing.T) {
 	}.run(t)
 }
 
+func (tc orgGetTest) run(t *testing.T) {
+	callEquals := func(i1, i2 interface{}) bool {
+		c1, ok := i1.(api.Org)
+		assert.True(t, ok)
+
+		c2, ok := i2.(api.Org)
+		assert.True(t, ok)
+
+		return c1.Equals(c2)
+	}
+
+	svcCall := func(key string, server *httptest.Server) (interface{}, error) {
+		svc := getAdminInterface(server).Org()
+		return svc.Get(api.OrgKey(key))
+	}
+
+	getTestCase{
+		svcCall:       svcCall,
+		checkEquality: callEquals,
+		key:           string(tc.orgKey),
+		responseObj:   tc.responseObj,
+		wantResp:      tc.wantResp,
+		wantErr:       tc.wantErr,
+		assertURL:     mkAssertGetURL(orgCommonURL),
+	}.run(t)
+}
+
 func (tc zoneGetTest) run(t *testing.T) {
 	callEquals := func(i1, i2 interface{}) bool {
 		c1, ok := i1.(api.Zone)
@@ -341,6 +375,7 @@ func TestGetNoKey(t *testing.T) {
 	sharedRulesGetTest{wantErr: e("SharedRules")}.run(t)
 	proxyGetTest{wantErr: e("Proxy")}.run(t)
 	userGetTest{wantErr: e("User")}.run(t)
+	orgGetTest{wantErr: e("Org")}.run(t)
 	zoneGetTest{wantErr: e("Zone")}.run(t)
 }
 
@@ -403,6 +438,14 @@ func TestGetUserReturnsOrg(t *testing.T) {
 	}.run(t)
 }
 
+func TestGetOrg(t *testing.T) {
+	orgGetTest{
+		orgKey:      fixtures.OrgKey1,
+		responseObj: fixtures.Org1,
+		wantResp:    fixtures.Org1,
+	}.run(t)
+}
+
 func TestGetWrapsWeirdResponses(t *testing.T) {
 	wantErr := func(path string, id interface{}) *httperr.Error {
 		return httperr.New500(
@@ -17,6 +17,7 @@ limitations under the License.
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -27,6 +28,7 @@ import (
 	apihttp "github.com/turbinelabs/api/http"
 	httperr "github.com/turbinelabs/api/http/error"
 	"github.com/turbinelabs/api/queryargs"
+	"github.com/turbinelabs/api/service"
 	"github.com/turbinelabs/api/service/changelog"
 	tbntime "github.com/turbinelabs/nonstdlib/time"
 )
@@ -41,10 +43,25 @@ type httpHistoryV1 struct {
 //
 // Parameters:
 //	dest - service handling our HTTP requests; cf. NewService
-func NewHistoryV1(dest apihttp.Endpoint) (*httpHistoryV1, error) {
+func NewHistoryV1(dest apihttp.Endpoint) (service.History, error) {
+	hc, err := NewHistoryContextV1(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.FromHistoryContext(hc), nil
+}
+
+// NewHistoryContextV1 constructs a new HTTP backed History API
+// implementation whose methods apply the context.Context they are passed to
+// each request.
+//
+// Parameters:
+//	dest - service handling our HTTP requests; cf. NewService
+func NewHistoryContextV1(dest apihttp.Endpoint) (*httpHistoryV1, error) {
 	return &httpHistoryV1{
 		dest,
-		apihttp.NewRequestHandler(dest.Client()),
+		apihttp.NewRetryingRequestHandler(dest.Client(), dest.RetryPolicy()),
 	}, nil
 }
 
@@ -76,6 +93,7 @@ func (hh *httpHistoryV1) get(path string, params apihttp.Params) (*http.Request,
 }
 
 func (hh *httpHistoryV1) Index(
+	ctx context.Context,
 	filters changelog.FilterExpr,
 	start,
 	end time.Time,
@@ -100,7 +118,7 @@ func (hh *httpHistoryV1) Index(
 
 	var response []api.ChangeDescription
 	reqFn := func() (*http.Request, error) { return hh.get("/adhoc", params) }
-	if err := hh.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hh.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return nil, err
 	}
 
@@ -123,6 +141,7 @@ func mkTimeArgs(start, stop time.Time) apihttp.Params {
 }
 
 func doNamedChangelogQueryRequest(
+	ctx context.Context,
 	hh *httpHistoryV1,
 	url string,
 	start,
@@ -132,7 +151,7 @@ func doNamedChangelogQueryRequest(
 	var response []api.ChangeDescription
 
 	reqFn := func() (*http.Request, error) { return hh.get(url, params) }
-	if err := hh.requestHandler.Do(reqFn, &response); err != nil {
+	if err := hh.requestHandler.DoContext(ctx, reqFn, &response); err != nil {
 		return nil, err
 	}
 
@@ -140,46 +159,51 @@ func doNamedChangelogQueryRequest(
 }
 
 func (hh *httpHistoryV1) DomainGraph(
+	ctx context.Context,
 	domainKey api.DomainKey,
 	start,
 	stop time.Time,
 ) ([]api.ChangeDescription, error) {
 	url := fmt.Sprintf("/domain-graph/%s", string(domainKey))
-	return doNamedChangelogQueryRequest(hh, url, start, stop)
+	return doNamedChangelogQueryRequest(ctx, hh, url, start, stop)
 }
 
 func (hh *httpHistoryV1) RouteGraph(
+	ctx context.Context,
 	routeKey api.RouteKey,
 	start,
 	stop time.Time,
 ) ([]api.ChangeDescription, error) {
 	url := fmt.Sprintf("/route-graph/%s", string(routeKey))
-	return doNamedChangelogQueryRequest(hh, url, start, stop)
+	return doNamedChangelogQueryRequest(ctx, hh, url, start, stop)
 }
 
 func (hh *httpHistoryV1) SharedRulesGraph(
+	ctx context.Context,
 	clusterKey api.SharedRulesKey,
 	start,
 	stop time.Time,
 ) ([]api.ChangeDescription, error) {
 	url := fmt.Sprintf("/shared-rules-graph/%s", string(clusterKey))
-	return doNamedChangelogQueryRequest(hh, url, start, stop)
+	return doNamedChangelogQueryRequest(ctx, hh, url, start, stop)
 }
 
 func (hh *httpHistoryV1) ClusterGraph(
+	ctx context.Context,
 	clusterKey api.ClusterKey,
 	start,
 	stop time.Time,
 ) ([]api.ChangeDescription, error) {
 	url := fmt.Sprintf("/cluster-graph/%s", string(clusterKey))
-	return doNamedChangelogQueryRequest(hh, url, start, stop)
+	return doNamedChangelogQueryRequest(ctx, hh, url, start, stop)
 }
 
 func (hh *httpHistoryV1) Zone(
+	ctx context.Context,
 	zoneKey api.ZoneKey,
 	start,
 	stop time.Time,
 ) ([]api.ChangeDescription, error) {
 	url := fmt.Sprintf("/zone/%s", string(zoneKey))
-	return doNamedChangelogQueryRequest(hh, url, start, stop)
+	return doNamedChangelogQueryRequest(ctx, hh, url, start, stop)
 }
@@ -35,6 +35,7 @@ type ClusterConstraint struct {
 	Metadata      Metadata      `json:"metadata"`
 	Properties    Metadata      `json:"properties"`
 	ResponseData  ResponseData  `json:"response_data"`
+	RequestData   RequestData   `json:"request_data"`
 	Weight        uint32        `json:"weight"`
 }
 
@@ -54,6 +55,7 @@ func (cc ClusterConstraint) IsValid() *ValidationError {
 	errs.MergePrefixed(ConstraintMetadataValid(cc.Metadata), "")
 	errs.MergePrefixed(ConstraintPropertiesValid(cc.Properties), "")
 	errs.MergePrefixed(cc.ResponseData.IsValid(), "response_data")
+	errs.MergePrefixed(cc.RequestData.IsValid(), "request_data")
 
 	return errs.OrNil()
 }
@@ -182,7 +184,8 @@ func (cc ClusterConstraint) Equals(o ClusterConstraint) bool {
 	return cc.coreEquality(o) &&
 		cc.Metadata.Equals(o.Metadata) &&
 		cc.Properties.Equals(o.Properties) &&
-		cc.ResponseData.Equals(o.ResponseData)
+		cc.ResponseData.Equals(o.ResponseData) &&
+		cc.RequestData.Equals(o.RequestData)
 }
 
 // Check equality between two AllConstraints objects. The objects are equal
@@ -31,6 +31,12 @@ func getRD() ResponseData {
 	}
 }
 
+func getReqD() RequestData {
+	return RequestData{
+		[]RequestHeaderDatum{{"x-request-header", OverwriteHeaderAction, "value", true}},
+	}
+}
+
 func TestClusterConstraintsEqualsSuccess(t *testing.T) {
 	cc1 := ClusterConstraint{
 		"cckey1",
@@ -38,6 +44,7 @@ func TestClusterConstraintsEqualsSuccess(t *testing.T) {
 		Metadata{{"key", "value"}, {"key2", "value2"}},
 		Metadata{{"state", "released"}},
 		ResponseData{[]HeaderDatum{{ResponseDatum{"x-header", "value", true}}}, nil},
+		RequestData{},
 		1234,
 	}
 	cc2 := ClusterConstraint{
@@ -46,6 +53,7 @@ func TestClusterConstraintsEqualsSuccess(t *testing.T) {
 		Metadata{{"key-2", "value-2"}},
 		Metadata{{"stata", "testing"}},
 		ResponseData{[]HeaderDatum{{ResponseDatum{"x-header", "value", true}}}, nil},
+		RequestData{},
 		1234,
 	}
 
@@ -63,6 +71,7 @@ func TestClusterConstraintsEqualsDiffernetOrder(t *testing.T) {
 		Metadata{{"key", "value"}, {"key2", "value2"}},
 		Metadata{{"state", "released"}},
 		ResponseData{[]HeaderDatum{{ResponseDatum{"x-header", "value", true}}}, nil},
+		RequestData{},
 		1234,
 	}
 	cc2 := ClusterConstraint{
@@ -71,6 +80,7 @@ func TestClusterConstraintsEqualsDiffernetOrder(t *testing.T) {
 		Metadata{{"key-2", "value-2"}},
 		Metadata{{"stata", "testing"}},
 		ResponseData{[]HeaderDatum{{ResponseDatum{"x-header", "value", true}}}, nil},
+		RequestData{},
 		1234,
 	}
 
@@ -82,9 +92,9 @@ func TestClusterConstraintsEqualsDiffernetOrder(t *testing.T) {
 }
 
 func TestClusterConstraintsEqualsFailureMetadata(t *testing.T) {
-	cc1 := ClusterConstraint{"cckey1", "ckey1", Metadata{{"key", "value"}, {"key2", "value2"}}, nil, ResponseData{}, 1234}
-	cc2a := ClusterConstraint{"cckey2", "ckey2", Metadata{}, nil, ResponseData{}, 1234}
-	cc2b := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key-2", "value-2"}}, nil, ResponseData{}, 1234}
+	cc1 := ClusterConstraint{"cckey1", "ckey1", Metadata{{"key", "value"}, {"key2", "value2"}}, nil, ResponseData{}, RequestData{}, 1234}
+	cc2a := ClusterConstraint{"cckey2", "ckey2", Metadata{}, nil, ResponseData{}, RequestData{}, 1234}
+	cc2b := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key-2", "value-2"}}, nil, ResponseData{}, RequestData{}, 1234}
 
 	slice1 := ClusterConstraints{cc1, cc2a}
 	slice2 := ClusterConstraints{cc1, cc2b}
@@ -94,9 +104,9 @@ func TestClusterConstraintsEqualsFailureMetadata(t *testing.T) {
 }
 
 func TestClusterConstraintsEqualsFailureProperties(t *testing.T) {
-	cc1 := ClusterConstraint{"cckey1", "ckey1", nil, Metadata{{"state", "released"}}, ResponseData{}, 1234}
-	cc2a := ClusterConstraint{"cckey2", "ckey2", nil, Metadata{{"state", "released"}}, ResponseData{}, 1234}
-	cc2b := ClusterConstraint{"cckey2", "ckey2", nil, Metadata{{"state", "releasing"}}, ResponseData{}, 1234}
+	cc1 := ClusterConstraint{"cckey1", "ckey1", nil, Metadata{{"state", "released"}}, ResponseData{}, RequestData{}, 1234}
+	cc2a := ClusterConstraint{"cckey2", "ckey2", nil, Metadata{{"state", "released"}}, ResponseData{}, RequestData{}, 1234}
+	cc2b := ClusterConstraint{"cckey2", "ckey2", nil, Metadata{{"state", "releasing"}}, ResponseData{}, RequestData{}, 1234}
 
 	slice1 := ClusterConstraints{cc1, cc2a}
 	slice2 := ClusterConstraints{cc1, cc2b}
@@ -106,8 +116,8 @@ func TestClusterConstraintsEqualsFailureProperties(t *testing.T) {
 }
 
 func TestClusterConstraintsEqualsLengthMismatch(t *testing.T) {
-	cc1 := ClusterConstraint{"cckey1", "ckey1", Metadata{{"key", "value"}, {"key2", "value2"}}, nil, ResponseData{}, 1234}
-	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"key2", "value2"}}, nil, ResponseData{}, 1234}
+	cc1 := ClusterConstraint{"cckey1", "ckey1", Metadata{{"key", "value"}, {"key2", "value2"}}, nil, ResponseData{}, RequestData{}, 1234}
+	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"key2", "value2"}}, nil, ResponseData{}, RequestData{}, 1234}
 
 	slice1 := ClusterConstraints{cc1, cc2}
 	slice2 := ClusterConstraints{}
@@ -117,8 +127,8 @@ func TestClusterConstraintsEqualsLengthMismatch(t *testing.T) {
 }
 
 func getClusterConstraintPair() (ClusterConstraint, ClusterConstraint) {
-	cc1 := ClusterConstraint{"cckey1", "ckey1", Metadata{{"key", "value"}, {"key2", "value2"}}, nil, getRD(), 1234}
-	cc2 := ClusterConstraint{"cckey1", "ckey1", Metadata{{"key", "value"}, {"key2", "value2"}}, nil, getRD(), 1234}
+	cc1 := ClusterConstraint{"cckey1", "ckey1", Metadata{{"key", "value"}, {"key2", "value2"}}, nil, getRD(), getReqD(), 1234}
+	cc2 := ClusterConstraint{"cckey1", "ckey1", Metadata{{"key", "value"}, {"key2", "value2"}}, nil, getRD(), getReqD(), 1234}
 	return cc1, cc2
 }
 
@@ -138,6 +148,15 @@ func TestClusterConstraintEqualsResponseDataVaries(t *testing.T) {
 	assert.False(t, cc2.Equals(cc1))
 }
 
+func TestClusterConstraintEqualsRequestDataVaries(t *testing.T) {
+	cc1, cc2 := getClusterConstraintPair()
+
+	cc2.RequestData.Headers[0].Action = AddHeaderAction
+
+	assert.False(t, cc1.Equals(cc2))
+	assert.False(t, cc2.Equals(cc1))
+}
+
 func TestClusterConstraintEqualsConstraintKeyVaries(t *testing.T) {
 	cc1, cc2 := getClusterConstraintPair()
 	cc2.ConstraintKey = "cckey2"
@@ -172,7 +191,7 @@ func TestClusterConstraintEqualsMetadataVaries(t *testing.T) {
 
 // ClusterConstraint.IsValid
 func getValidClusterConstraint() ClusterConstraint {
-	return ClusterConstraint{"cckey1", "ckey1", Metadata{{"key", "value"}, {"k", "v"}}, nil, getRD(), 1234}
+	return ClusterConstraint{"cckey1", "ckey1", Metadata{{"key", "value"}, {"k", "v"}}, nil, getRD(), getReqD(), 1234}
 }
 
 func TestClusterConstraintIsValidResponseDataFailure(t *testing.T) {
@@ -185,6 +204,16 @@ func TestClusterConstraintIsValidResponseDataFailure(t *testing.T) {
 	}})
 }
 
+func TestClusterConstraintIsValidRequestDataFailure(t *testing.T) {
+	cc := getValidClusterConstraint()
+	cc.RequestData.Headers[0].Value = ""
+	n := cc.RequestData.Headers[0].Name
+
+	assert.DeepEqual(t, cc.IsValid(), &ValidationError{[]ErrorCase{
+		{"request_data.headers[" + n + "].value", "may not be empty"},
+	}})
+}
+
 func TestClusterConstraintIsValidSuccess(t *testing.T) {
 	cc := getValidClusterConstraint()
 
@@ -221,16 +250,16 @@ func TestClusterConstraintIsValidWeightFailure(t *testing.T) {
 
 // ClusterConstarintSlice.IsValid
 func TestClusterConstraintsIsValidSuccess(t *testing.T) {
-	cc1 := ClusterConstraint{"cckey1", "ckey1", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 234}
-	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 123}
+	cc1 := ClusterConstraint{"cckey1", "ckey1", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 234}
+	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 123}
 	ccs := ClusterConstraints{cc1, cc2}
 
 	assert.Nil(t, ccs.IsValid("test"))
 }
 
 func TestClusterConstraintsIsValidFailureOnDuplicateConstraintKeys(t *testing.T) {
-	cc1 := ClusterConstraint{"cckey1", "ckey", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 234}
-	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 123}
+	cc1 := ClusterConstraint{"cckey1", "ckey", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 234}
+	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 123}
 	cc2.ConstraintKey = "cckey1"
 	ccs := ClusterConstraints{cc1, cc2}
 
@@ -238,8 +267,8 @@ func TestClusterConstraintsIsValidFailureOnDuplicateConstraintKeys(t *testing.T)
 }
 
 func TestClusterConstraintsIsValidInvalidResponseData(t *testing.T) {
-	cc1 := ClusterConstraint{"cckey1", "ckey", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, getRD(), 234}
-	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, getRD(), 123}
+	cc1 := ClusterConstraint{"cckey1", "ckey", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, getRD(), getReqD(), 234}
+	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, getRD(), getReqD(), 123}
 	cc2.ResponseData.Headers[0].Value = ""
 	n := cc2.ResponseData.Headers[0].Name
 	ccs := ClusterConstraints{cc1, cc2}
@@ -250,8 +279,8 @@ func TestClusterConstraintsIsValidInvalidResponseData(t *testing.T) {
 }
 
 func TestClusterConstraintsIsValidInvalidContents(t *testing.T) {
-	cc1 := ClusterConstraint{"cckey1", "ckey", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 234}
-	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 0}
+	cc1 := ClusterConstraint{"cckey1", "ckey", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 234}
+	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 0}
 	ccs := ClusterConstraints{cc1, cc2}
 
 	assert.NonNil(t, ccs.IsValid("test"))
@@ -265,8 +294,8 @@ func TestClusterConstraintsIsValidEmpty(t *testing.T) {
 
 // ClusterConstraints.IsValid
 func TestClusterConstraintsIsValidSucces(t *testing.T) {
-	cc1 := ClusterConstraint{"cckey1", "ckey1", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 234}
-	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 123}
+	cc1 := ClusterConstraint{"cckey1", "ckey1", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 234}
+	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 123}
 	ccs := ClusterConstraints{cc1, cc2}
 
 	set := AllConstraints{ccs, ccs, ccs}
@@ -275,9 +304,9 @@ func TestClusterConstraintsIsValidSucces(t *testing.T) {
 }
 
 func TestClusterConstraintsIsValidFailsWithBadLight(t *testing.T) {
-	cc1 := ClusterConstraint{"cckey1", "ckey", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 234}
-	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 123}
-	ccbad := ClusterConstraint{"cc-bad", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 0}
+	cc1 := ClusterConstraint{"cckey1", "ckey", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 234}
+	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 123}
+	ccbad := ClusterConstraint{"cc-bad", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 0}
 
 	ccs := ClusterConstraints{cc1, cc2}
 	ccsBad := ClusterConstraints{cc1, cc2, ccbad}
@@ -289,9 +318,9 @@ func TestClusterConstraintsIsValidFailsWithBadLight(t *testing.T) {
 }
 
 func TestClusterConstraintsIsValidFailsWithBadDark(t *testing.T) {
-	cc1 := ClusterConstraint{"cckey1", "ckey", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 234}
-	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 123}
-	ccbad := ClusterConstraint{"cc-bad", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 0}
+	cc1 := ClusterConstraint{"cckey1", "ckey", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 234}
+	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 123}
+	ccbad := ClusterConstraint{"cc-bad", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 0}
 
 	ccs := ClusterConstraints{cc1, cc2}
 	ccsBad := ClusterConstraints{cc1, cc2, ccbad}
@@ -303,9 +332,9 @@ func TestClusterConstraintsIsValidFailsWithBadDark(t *testing.T) {
 }
 
 func TestClusterConstraintsIsValidFailsWithBadTap(t *testing.T) {
-	cc1 := ClusterConstraint{"cckey1", "ckey", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 234}
-	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 123}
-	ccbad := ClusterConstraint{"cc-bad", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 0}
+	cc1 := ClusterConstraint{"cckey1", "ckey", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 234}
+	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 123}
+	ccbad := ClusterConstraint{"cc-bad", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 0}
 
 	ccs := ClusterConstraints{cc1, cc2}
 	ccsBad := ClusterConstraints{cc1, cc2, ccbad}
@@ -317,8 +346,8 @@ func TestClusterConstraintsIsValidFailsWithBadTap(t *testing.T) {
 }
 
 func TestClusterConstraintsIsValidFailsWithZeroLight(t *testing.T) {
-	cc1 := ClusterConstraint{"cckey1", "ckey", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 234}
-	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, 123}
+	cc1 := ClusterConstraint{"cckey1", "ckey", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 234}
+	cc2 := ClusterConstraint{"cckey2", "ckey2", Metadata{{"key", "value"}, {"k", "v"}}, Metadata{}, ResponseData{}, RequestData{}, 123}
 	ccs := ClusterConstraints{cc1, cc2}
 
 	set := AllConstraints{ccs, ccs, ccs}
@@ -411,6 +440,7 @@ func getTestCC() ClusterConstraint {
 		md,
 		props,
 		ResponseData{},
+		RequestData{},
 		100,
 	}
 }
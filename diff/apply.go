@@ -0,0 +1,445 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/changetype"
+)
+
+// ConflictError is returned by Apply and Revert when a removal does not
+// match the current state of the object.
+type ConflictError struct {
+	// Path is the path of the ChangeEntry.
+	Path string
+
+	// Want is the value the ChangeEntry expected to remove.
+	Want string
+
+	// Got is the current value, or the empty string if there is none.
+	Got string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s: expected %q, found %q", e.Path, e.Want, e.Got)
+}
+
+// Apply applies a ChangeEntry to the object pointed to by v, which must be
+// of the type indicated by the entry's ObjectType. Entries recorded on the
+// root path (e.g. "cluster") carry the JSON encoding of the entire object.
+//
+// Removals are checked against the object's current value; a *ConflictError
+// is returned if they do not match. Additions replace the current value.
+// The order of elements in key-indexed containers is not recorded, so
+// elements are always added to the end of their container.
+//
+// The object is modified in place, including the elements of its slices,
+// which may be shared with copies of the object.
+func Apply(v interface{}, e api.ChangeEntry) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot apply changes to %T", v)
+	}
+
+	root, segs, err := parsePath(e.Path)
+	if err != nil {
+		return err
+	}
+	if root != e.ObjectType.Name {
+		return fmt.Errorf("%s: path does not match object type %q", e.Path, e.ObjectType.Name)
+	}
+
+	a := applier{path: e.Path, ct: e.ChangeType, value: e.Value}
+	if len(segs) == 0 {
+		return a.object(rv.Elem())
+	}
+	return a.apply(rv.Elem(), segs)
+}
+
+// Revert undoes a ChangeEntry on the object pointed to by v by applying its
+// inverse. Reverting a ChangeDescription's entries in reverse order undoes
+// the change.
+func Revert(v interface{}, e api.ChangeEntry) error {
+	switch e.ChangeType {
+	case changetype.Addition:
+		e.ChangeType = changetype.Removal
+	case changetype.Removal:
+		e.ChangeType = changetype.Addition
+	default:
+		return fmt.Errorf("%s: unknown change type %q", e.Path, e.ChangeType.Name)
+	}
+	return Apply(v, e)
+}
+
+// segment is a single step in a path: either a field name or a container
+// index.
+type segment struct {
+	name  string
+	index bool
+}
+
+// parsePath splits a path into its root and subsequent segments. Indices
+// may contain any character other than brackets.
+func parsePath(path string) (string, []segment, error) {
+	end := strings.IndexAny(path, ".[")
+	if end < 0 {
+		return path, nil, nil
+	}
+
+	root := path[:end]
+	segs := []segment{}
+	for rest := path[end:]; rest != ""; {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return "", nil, fmt.Errorf("%s: empty field name", path)
+			}
+			segs = append(segs, segment{name: rest[:end]})
+			rest = rest[end:]
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return "", nil, fmt.Errorf("%s: unterminated index", path)
+			}
+			segs = append(segs, segment{name: rest[1:end], index: true})
+			rest = rest[end+1:]
+
+		default:
+			return "", nil, fmt.Errorf("%s: malformed path", path)
+		}
+	}
+
+	return root, segs, nil
+}
+
+// applier applies a single change to the value at the end of a path.
+type applier struct {
+	path  string
+	ct    changetype.ChangeType
+	value string
+}
+
+func (a applier) conflict(got string) error {
+	return &ConflictError{Path: a.path, Want: a.value, Got: got}
+}
+
+// object applies a change recorded as the JSON encoding of an entire object.
+func (a applier) object(v reflect.Value) error {
+	decoded := reflect.New(v.Type())
+	if err := json.Unmarshal([]byte(a.value), decoded.Interface()); err != nil {
+		return fmt.Errorf("%s: %v", a.path, err)
+	}
+
+	switch a.ct {
+	case changetype.Addition:
+		v.Set(decoded.Elem())
+
+	case changetype.Removal:
+		d := &differ{}
+		d.value(a.path, v, decoded.Elem())
+		if len(d.entries) > 0 {
+			got, _ := json.Marshal(v.Interface())
+			return a.conflict(string(got))
+		}
+		v.Set(reflect.Zero(v.Type()))
+
+	default:
+		return fmt.Errorf("%s: unknown change type %q", a.path, a.ct.Name)
+	}
+
+	return nil
+}
+
+func (a applier) apply(v reflect.Value, segs []segment) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if a.ct == changetype.Removal {
+				return a.conflict("")
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if err := a.apply(v.Elem(), segs); err != nil {
+			return err
+		}
+		// pointers are nil unless they have a value
+		if a.ct == changetype.Removal && isZero(v.Elem()) {
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+
+	if len(segs) == 0 {
+		if v.Kind() == reflect.Slice {
+			return a.element(v)
+		}
+		return a.leaf(v)
+	}
+
+	seg := segs[0]
+	switch {
+	case !seg.index && v.Kind() == reflect.Struct && v.Type() != timeType:
+		f, ok := lookupField(v, seg.name)
+		if !ok {
+			return fmt.Errorf("%s: unknown field %q", a.path, seg.name)
+		}
+		return a.apply(f, segs[1:])
+
+	case seg.index && v.Kind() == reflect.Slice:
+		i, ok := lookupElement(v, seg.name)
+		if !ok {
+			return a.conflict("")
+		}
+		elem := v.Index(i)
+		// Metadata is recorded as key/value pairs
+		if len(segs) == 1 && elem.Type() == metadatumType && keyOf(elem) == seg.name {
+			return a.apply(elem.Field(1), nil)
+		}
+		return a.apply(elem, segs[1:])
+	}
+
+	return fmt.Errorf("%s: cannot traverse %s", a.path, v.Type())
+}
+
+// element adds or removes an element of a container. Slices of strings are
+// sets of values; other containers are indexed by key or position.
+func (a applier) element(v reflect.Value) error {
+	elem := v.Type().Elem()
+
+	if elem.Kind() == reflect.String {
+		for i := 0; i < v.Len(); i++ {
+			if v.Index(i).String() != a.value {
+				continue
+			}
+			if a.ct == changetype.Removal {
+				v.Set(reflect.AppendSlice(v.Slice(0, i), v.Slice(i+1, v.Len())))
+			}
+			return nil
+		}
+		if a.ct == changetype.Removal {
+			return a.conflict("")
+		}
+		e := reflect.New(elem).Elem()
+		e.SetString(a.value)
+		v.Set(reflect.Append(v, e))
+		return nil
+	}
+
+	switch a.ct {
+	case changetype.Addition:
+		e := reflect.New(elem).Elem()
+		if set := elementKeySetters[elem]; set != nil {
+			set(e, a.value)
+		}
+		v.Set(reflect.Append(v, e))
+		return nil
+
+	case changetype.Removal:
+		i, ok := lookupElement(v, a.value)
+		if !ok {
+			return a.conflict("")
+		}
+		v.Set(reflect.AppendSlice(v.Slice(0, i), v.Slice(i+1, v.Len())))
+		return nil
+	}
+
+	return fmt.Errorf("%s: unknown change type %q", a.path, a.ct.Name)
+}
+
+func (a applier) leaf(v reflect.Value) error {
+	switch a.ct {
+	case changetype.Addition:
+		return a.set(v)
+
+	case changetype.Removal:
+		if got, _ := format(v); got != a.value {
+			return a.conflict(got)
+		}
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	return fmt.Errorf("%s: unknown change type %q", a.path, a.ct.Name)
+}
+
+// set parses the change's value into v.
+func (a applier) set(v reflect.Value) error {
+	var err error
+	if v.Type() == timeType {
+		var t time.Time
+		t, err = time.Parse(time.RFC3339Nano, a.value)
+		if err == nil {
+			v.Set(reflect.ValueOf(t))
+		}
+	} else {
+		switch v.Kind() {
+		case reflect.String:
+			v.SetString(a.value)
+		case reflect.Bool:
+			var b bool
+			b, err = strconv.ParseBool(a.value)
+			v.SetBool(b)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			var i int64
+			i, err = strconv.ParseInt(a.value, 10, v.Type().Bits())
+			v.SetInt(i)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			var u uint64
+			u, err = strconv.ParseUint(a.value, 10, v.Type().Bits())
+			v.SetUint(u)
+		case reflect.Float32, reflect.Float64:
+			var f float64
+			f, err = strconv.ParseFloat(a.value, v.Type().Bits())
+			v.SetFloat(f)
+		default:
+			return fmt.Errorf("%s: cannot set %s", a.path, v.Type())
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %v", a.path, err)
+	}
+	return nil
+}
+
+// elementKeySetters initialize new elements of key-indexed containers from
+// their keys.
+var elementKeySetters = map[reflect.Type]func(reflect.Value, string){
+	reflect.TypeOf(api.Instance{}): func(v reflect.Value, k string) {
+		i := strings.LastIndexByte(k, ':')
+		if i < 0 {
+			return
+		}
+		if port, err := strconv.Atoi(k[i+1:]); err == nil {
+			v.Set(reflect.ValueOf(api.Instance{Host: k[:i], Port: port}))
+		}
+	},
+	reflect.TypeOf(api.Rule{}): func(v reflect.Value, k string) {
+		v.Set(reflect.ValueOf(api.Rule{RuleKey: api.RuleKey(k)}))
+	},
+	reflect.TypeOf(api.ClusterConstraint{}): func(v reflect.Value, k string) {
+		v.Set(reflect.ValueOf(api.ClusterConstraint{ConstraintKey: api.ConstraintKey(k)}))
+	},
+	reflect.TypeOf(api.Redirect{}): func(v reflect.Value, k string) {
+		v.Set(reflect.ValueOf(api.Redirect{Name: k}))
+	},
+	reflect.TypeOf(api.HeaderDatum{}): func(v reflect.Value, k string) {
+		v.Set(reflect.ValueOf(api.HeaderDatum{ResponseDatum: api.ResponseDatum{Name: k}}))
+	},
+	reflect.TypeOf(api.CookieDatum{}): func(v reflect.Value, k string) {
+		v.Set(reflect.ValueOf(api.CookieDatum{ResponseDatum: api.ResponseDatum{Name: k}}))
+	},
+	reflect.TypeOf(api.Listener{}): func(v reflect.Value, k string) {
+		v.Set(reflect.ValueOf(api.Listener{ListenerKey: api.ListenerKey(k)}))
+	},
+	metadatumType: func(v reflect.Value, k string) {
+		v.Set(reflect.ValueOf(api.Metadatum{Key: k}))
+	},
+}
+
+// keyOf returns the key of an element of a key-indexed container.
+func keyOf(v reflect.Value) string {
+	if keyFn := elementKeys[v.Type()]; keyFn != nil {
+		return keyFn(v)
+	}
+	return ""
+}
+
+// lookupElement finds the element of a container with the given key. As an
+// element's attributes are removed, its key may become incomplete; if no
+// key matches, the last element whose remaining key attributes agree with
+// the key is used. Otherwise, the key is treated as a position.
+func lookupElement(v reflect.Value, k string) (int, bool) {
+	elem := v.Type().Elem()
+	if keyFn := elementKeys[elem]; keyFn != nil {
+		for i := 0; i < v.Len(); i++ {
+			if keyFn(v.Index(i)) == k {
+				return i, true
+			}
+		}
+
+		if set := elementKeySetters[elem]; set != nil {
+			want := reflect.New(elem).Elem()
+			set(want, k)
+			for i := v.Len() - 1; i >= 0; i-- {
+				if partialMatch(v.Index(i), want) {
+					return i, true
+				}
+			}
+		}
+	}
+
+	i, err := strconv.Atoi(k)
+	if err != nil || i < 0 || i >= v.Len() {
+		return 0, false
+	}
+	return i, true
+}
+
+// partialMatch returns true if some of the attributes set in want are
+// missing from v, and the rest are equal.
+func partialMatch(v, want reflect.Value) bool {
+	missing := false
+	for i := 0; i < want.NumField(); i++ {
+		w := want.Field(i)
+		if isZero(w) {
+			continue
+		}
+		f := v.Field(i)
+		switch {
+		case isZero(f):
+			missing = true
+		case !reflect.DeepEqual(f.Interface(), w.Interface()):
+			return false
+		}
+	}
+	return missing
+}
+
+// lookupField finds the field of a struct with the given JSON name,
+// flattening embedded structs.
+func lookupField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type == checksumType {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if fv, ok := lookupField(v.Field(i), name); ok {
+				return fv, true
+			}
+			continue
+		}
+		if fieldName(f) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/changetype"
+	"github.com/turbinelabs/api/objecttype"
+	"github.com/turbinelabs/test/assert"
+)
+
+func modifiedCluster() api.Cluster {
+	c := testCluster()
+	c.Name = "api-v2"
+	c.Instances = api.Instances{
+		{
+			Host:     "10.0.0.1",
+			Port:     8080,
+			Metadata: api.Metadata{{Key: "stage", Value: "canary"}, {Key: "version", Value: "2"}},
+		},
+		{Host: "10.0.0.3", Port: 8080},
+	}
+	c.CircuitBreakers = &api.CircuitBreakers{MaxRetries: func(i int) *int { return &i }(3)}
+	c.HealthChecks = api.HealthChecks{{TimeoutMsec: 100, IntervalMsec: 1000}}
+	return c
+}
+
+// same returns true if a and b have the same attributes, treating zero
+// values as present.
+func same(a, b interface{}) bool {
+	d := &differ{}
+	d.value("", reflect.ValueOf(a), reflect.ValueOf(b))
+	return len(d.entries) == 0
+}
+
+func TestApplyAndRevertCluster(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		before, after func() api.Cluster
+	}{
+		{"modify", testCluster, modifiedCluster},
+		{"modify reversed", modifiedCluster, testCluster},
+		{"create", func() api.Cluster { return api.Cluster{} }, modifiedCluster},
+		{"delete", modifiedCluster, func() api.Cluster { return api.Cluster{} }},
+	} {
+		assert.Group(tc.name, t, func(g *assert.G) {
+			entries := Cluster(tc.before(), tc.after())
+
+			c := tc.before()
+			for _, e := range entries {
+				assert.Nil(g, Apply(&c, e))
+			}
+			assert.True(g, same(c, tc.after()))
+
+			c = tc.after()
+			for i := len(entries) - 1; i >= 0; i-- {
+				assert.Nil(g, Revert(&c, entries[i]))
+			}
+			assert.True(g, same(c, tc.before()))
+		})
+	}
+}
+
+func TestApplyAndRevertRoute(t *testing.T) {
+	before := func() api.Route {
+		return api.Route{
+			RouteKey: "rk",
+			ZoneKey:  "zk",
+			Path:     "/",
+			Rules: api.Rules{
+				{
+					RuleKey: "r1",
+					Methods: []string{"GET"},
+					Constraints: api.AllConstraints{
+						Light: api.ClusterConstraints{{ConstraintKey: "cc1", ClusterKey: "c1", Weight: 1}},
+					},
+				},
+				{RuleKey: "r2", Methods: []string{"PUT"}},
+			},
+		}
+	}
+	after := func() api.Route {
+		r := before()
+		r.Rules = api.Rules{
+			{
+				RuleKey: "r1",
+				Methods: []string{"POST"},
+				Matches: api.Matches{
+					{Kind: api.HeaderMatchKind, Behavior: api.ExactMatchBehavior, From: api.Metadatum{Key: "x"}},
+				},
+				Constraints: api.AllConstraints{
+					Light: api.ClusterConstraints{{ConstraintKey: "cc1", ClusterKey: "c1", Weight: 2}},
+				},
+			},
+		}
+		return r
+	}
+
+	entries := Route(before(), after())
+
+	r := before()
+	for _, e := range entries {
+		assert.Nil(t, Apply(&r, e))
+	}
+	assert.True(t, same(r, after()))
+
+	r = after()
+	for i := len(entries) - 1; i >= 0; i-- {
+		assert.Nil(t, Revert(&r, entries[i]))
+	}
+	assert.True(t, same(r, before()))
+}
+
+func TestApplyAndRevertPositionalMetadata(t *testing.T) {
+	before := api.SharedRules{SharedRulesKey: "srk", ZoneKey: "zk"}
+	after := func() api.SharedRules {
+		return api.SharedRules{
+			SharedRulesKey: "srk",
+			ZoneKey:        "zk",
+			Properties:     api.Metadata{{Key: "a[0]", Value: "1"}, {Key: "b[0]", Value: "2"}},
+		}
+	}
+
+	entries := SharedRules(before, after())
+
+	sr := before
+	for _, e := range entries {
+		assert.Nil(t, Apply(&sr, e))
+	}
+	assert.DeepEqual(t, sr.Properties, after().Properties)
+
+	sr = after()
+	for i := len(entries) - 1; i >= 0; i-- {
+		assert.Nil(t, Revert(&sr, entries[i]))
+	}
+	assert.Equal(t, len(sr.Properties), 0)
+}
+
+func TestApplyTime(t *testing.T) {
+	deleted := time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC)
+	before := api.User{UserKey: "uk", LoginEmail: "a@example.com"}
+	after := before
+	after.DeletedAt = &deleted
+
+	u := before
+	for _, e := range User(before, after) {
+		assert.Nil(t, Apply(&u, e))
+	}
+	assert.NonNil(t, u.DeletedAt)
+	assert.True(t, u.DeletedAt.Equal(deleted))
+}
+
+func TestApplyConflict(t *testing.T) {
+	c := testCluster()
+	err := Apply(&c, api.ChangeEntry{
+		ObjectType: objecttype.Cluster,
+		ChangeType: changetype.Removal,
+		Path:       "cluster.name",
+		Value:      "web",
+	})
+	assert.DeepEqual(t, err, &ConflictError{Path: "cluster.name", Want: "web", Got: "api"})
+	assert.ErrorContains(t, err, `cluster.name: expected "web", found "api"`)
+
+	err = Apply(&c, api.ChangeEntry{
+		ObjectType: objecttype.Cluster,
+		ChangeType: changetype.Removal,
+		Path:       "cluster.instances",
+		Value:      "10.0.0.9:80",
+	})
+	assert.DeepEqual(t, err, &ConflictError{Path: "cluster.instances", Want: "10.0.0.9:80"})
+
+	err = Apply(&c, api.ChangeEntry{
+		ObjectType: objecttype.Cluster,
+		ChangeType: changetype.Removal,
+		Path:       "cluster.circuit_breakers.max_retries",
+		Value:      "3",
+	})
+	assert.DeepEqual(t, err, &ConflictError{Path: "cluster.circuit_breakers.max_retries", Want: "3"})
+}
+
+func TestApplyObject(t *testing.T) {
+	c := testCluster()
+	b, err := json.Marshal(c)
+	assert.Nil(t, err)
+
+	removal := api.ChangeEntry{
+		ObjectType: objecttype.Cluster,
+		ChangeType: changetype.Removal,
+		Path:       "cluster",
+		Value:      string(b),
+	}
+
+	modified := c
+	modified.Name = "other"
+	err = Apply(&modified, removal)
+	assert.ErrorContains(t, err, `cluster: expected`)
+
+	assert.Nil(t, Apply(&c, removal))
+	assert.DeepEqual(t, c, api.Cluster{})
+
+	assert.Nil(t, Revert(&c, removal))
+	assert.DeepEqual(t, c, testCluster())
+}
+
+func TestApplyErrors(t *testing.T) {
+	c := testCluster()
+	entry := func(path string) api.ChangeEntry {
+		return api.ChangeEntry{
+			ObjectType: objecttype.Cluster,
+			ChangeType: changetype.Addition,
+			Path:       path,
+			Value:      "x",
+		}
+	}
+
+	assert.ErrorContains(t, Apply(c, entry("cluster.name")), "cannot apply changes to api.Cluster")
+	assert.ErrorContains(t, Apply(&c, entry("route.path")), "path does not match object type")
+	assert.ErrorContains(t, Apply(&c, entry("cluster.instances[0")), "unterminated index")
+	assert.ErrorContains(t, Apply(&c, entry("cluster..name")), "empty field name")
+	assert.ErrorContains(t, Apply(&c, entry("cluster.nope")), `unknown field "nope"`)
+	assert.ErrorContains(t, Apply(&c, entry("cluster.require_tls")), "invalid syntax")
+	assert.ErrorContains(t, Apply(&c, entry("cluster.name[0]")), "cannot traverse string")
+}
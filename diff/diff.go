@@ -0,0 +1,518 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diff computes the api.ChangeEntry values describing the
+// difference between two versions of an api object, as recorded by the
+// changelog.
+//
+// Attribute paths are dot-separated JSON field names rooted with the object
+// type, e.g. "route.shared_rules_key". Containers are key-indexed using
+// index operators: Instances by "host:port", Rules by RuleKey,
+// ClusterConstraints by ConstraintKey, Redirects, ResponseData and
+// RateLimits by name, Listeners by ListenerKey and Metadata by key, so a
+// change to an Instance's metadata is recorded on
+// "cluster.instances[10.0.0.1:8080].metadata[stage]".
+// Other containers, such as HealthChecks and Matches, are indexed by
+// position. Keys must match api.AllowedIndexPattern and be unique within
+// their container; otherwise the container is indexed by position.
+//
+// As elements are added or removed they are recorded on the non-indexed
+// path (e.g. "cluster.instances") with the element's key as the value.
+// Added elements are followed by their attributes; removed elements are
+// preceded by them. Slices of strings, such as aliases
+// and domain keys, are treated as sets of values recorded only on the
+// non-indexed path.
+//
+// A modified attribute is recorded as a removal of its previous value and
+// an addition of its new one. Nil pointers have no value. A zero-value
+// before object represents creation, so that all of the after object's
+// attributes are recorded as additions; likewise a zero-value after object
+// represents deletion.
+package diff
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/changetype"
+	"github.com/turbinelabs/api/objecttype"
+)
+
+// Cluster returns the changes between two versions of a Cluster.
+func Cluster(before, after api.Cluster) []api.ChangeEntry {
+	return diff(
+		objecttype.Cluster,
+		string(after.ClusterKey),
+		string(before.ClusterKey),
+		after.ZoneKey,
+		before.ZoneKey,
+		before,
+		after,
+	)
+}
+
+// Domain returns the changes between two versions of a Domain.
+func Domain(before, after api.Domain) []api.ChangeEntry {
+	return diff(
+		objecttype.Domain,
+		string(after.DomainKey),
+		string(before.DomainKey),
+		after.ZoneKey,
+		before.ZoneKey,
+		before,
+		after,
+	)
+}
+
+// Route returns the changes between two versions of a Route.
+func Route(before, after api.Route) []api.ChangeEntry {
+	return diff(
+		objecttype.Route,
+		string(after.RouteKey),
+		string(before.RouteKey),
+		after.ZoneKey,
+		before.ZoneKey,
+		before,
+		after,
+	)
+}
+
+// SharedRules returns the changes between two versions of a SharedRules.
+func SharedRules(before, after api.SharedRules) []api.ChangeEntry {
+	return diff(
+		objecttype.SharedRules,
+		string(after.SharedRulesKey),
+		string(before.SharedRulesKey),
+		after.ZoneKey,
+		before.ZoneKey,
+		before,
+		after,
+	)
+}
+
+// Listener returns the changes between two versions of a Listener.
+func Listener(before, after api.Listener) []api.ChangeEntry {
+	return diff(
+		objecttype.Listener,
+		string(after.ListenerKey),
+		string(before.ListenerKey),
+		after.ZoneKey,
+		before.ZoneKey,
+		before,
+		after,
+	)
+}
+
+// Proxy returns the changes between two versions of a Proxy.
+func Proxy(before, after api.Proxy) []api.ChangeEntry {
+	return diff(
+		objecttype.Proxy,
+		string(after.ProxyKey),
+		string(before.ProxyKey),
+		after.ZoneKey,
+		before.ZoneKey,
+		before,
+		after,
+	)
+}
+
+// Zone returns the changes between two versions of a Zone.
+func Zone(before, after api.Zone) []api.ChangeEntry {
+	return diff(
+		objecttype.Zone,
+		string(after.ZoneKey),
+		string(before.ZoneKey),
+		after.ZoneKey,
+		before.ZoneKey,
+		before,
+		after,
+	)
+}
+
+// Org returns the changes between two versions of an Org. Orgs are not
+// bound to a Zone.
+func Org(before, after api.Org) []api.ChangeEntry {
+	return diff(
+		objecttype.Org,
+		string(after.OrgKey),
+		string(before.OrgKey),
+		"",
+		"",
+		before,
+		after,
+	)
+}
+
+// AccessToken returns the changes between two versions of an AccessToken.
+// AccessTokens are not bound to a Zone.
+func AccessToken(before, after api.AccessToken) []api.ChangeEntry {
+	return diff(
+		objecttype.AccessToken,
+		string(after.AccessTokenKey),
+		string(before.AccessTokenKey),
+		"",
+		"",
+		before,
+		after,
+	)
+}
+
+// User returns the changes between two versions of a User. Users are not
+// bound to a Zone.
+func User(before, after api.User) []api.ChangeEntry {
+	return diff(
+		objecttype.User,
+		string(after.UserKey),
+		string(before.UserKey),
+		"",
+		"",
+		before,
+		after,
+	)
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	checksumType  = reflect.TypeOf(api.Checksum{})
+	metadatumType = reflect.TypeOf(api.Metadatum{})
+
+	// elementKeys determines the key of elements of key-indexed containers
+	elementKeys = map[reflect.Type]func(reflect.Value) string{
+		reflect.TypeOf(api.Instance{}): func(v reflect.Value) string {
+			i := v.Interface().(api.Instance)
+			return i.Host + ":" + strconv.Itoa(i.Port)
+		},
+		reflect.TypeOf(api.Rule{}): func(v reflect.Value) string {
+			return string(v.Interface().(api.Rule).RuleKey)
+		},
+		reflect.TypeOf(api.ClusterConstraint{}): func(v reflect.Value) string {
+			return string(v.Interface().(api.ClusterConstraint).ConstraintKey)
+		},
+		reflect.TypeOf(api.Redirect{}): func(v reflect.Value) string {
+			return v.Interface().(api.Redirect).Name
+		},
+		reflect.TypeOf(api.HeaderDatum{}): func(v reflect.Value) string {
+			return v.Interface().(api.HeaderDatum).Name
+		},
+		reflect.TypeOf(api.CookieDatum{}): func(v reflect.Value) string {
+			return v.Interface().(api.CookieDatum).Name
+		},
+		reflect.TypeOf(api.RateLimit{}): func(v reflect.Value) string {
+			return v.Interface().(api.RateLimit).Name
+		},
+		reflect.TypeOf(api.Listener{}): func(v reflect.Value) string {
+			return string(v.Interface().(api.Listener).ListenerKey)
+		},
+		metadatumType: func(v reflect.Value) string {
+			return v.Interface().(api.Metadatum).Key
+		},
+	}
+)
+
+func diff(
+	ot objecttype.ObjectType,
+	key, beforeKey string,
+	zk, beforeZK api.ZoneKey,
+	before, after interface{},
+) []api.ChangeEntry {
+	if key == "" {
+		key = beforeKey
+	}
+	if zk == "" {
+		zk = beforeZK
+	}
+
+	b := reflect.ValueOf(before)
+	a := reflect.ValueOf(after)
+	if isZero(b) {
+		b = reflect.Value{}
+	}
+	if isZero(a) {
+		a = reflect.Value{}
+	}
+
+	d := &differ{template: api.ChangeEntry{ObjectType: ot, ObjectKey: key, ZoneKey: zk}}
+	d.value(ot.Name, b, a)
+	return d.entries
+}
+
+// differ accumulates the changes between two values. Invalid reflect.Values
+// represent absent values.
+type differ struct {
+	template api.ChangeEntry
+	entries  []api.ChangeEntry
+}
+
+func (d *differ) add(ct changetype.ChangeType, path, value string) {
+	e := d.template
+	e.ChangeType = ct
+	e.Path = path
+	e.Value = value
+	d.entries = append(d.entries, e)
+}
+
+func (d *differ) value(path string, before, after reflect.Value) {
+	before, after = indirect(before), indirect(after)
+	if !before.IsValid() && !after.IsValid() {
+		return
+	}
+
+	var t reflect.Type
+	if after.IsValid() {
+		t = after.Type()
+	} else {
+		t = before.Type()
+	}
+
+	switch {
+	case t == timeType:
+		d.leaf(path, before, after)
+
+	case t.Kind() == reflect.Struct:
+		d.fields(path, t, before, after)
+
+	case t.Kind() == reflect.Slice:
+		d.slice(path, t.Elem(), before, after)
+
+	default:
+		d.leaf(path, before, after)
+	}
+}
+
+// fields compares the fields of two structs, flattening embedded structs
+// and ignoring checksums and fields not serialized to JSON.
+func (d *differ) fields(path string, t reflect.Type, before, after reflect.Value) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type == checksumType {
+			continue
+		}
+
+		b, a := field(before, i), field(after, i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			d.fields(path, f.Type, b, a)
+			continue
+		}
+
+		name := fieldName(f)
+		if name == "" {
+			continue
+		}
+		d.value(path+"."+name, b, a)
+	}
+}
+
+func (d *differ) slice(path string, elem reflect.Type, before, after reflect.Value) {
+	if elem.Kind() == reflect.String {
+		d.set(path, before, after)
+		return
+	}
+
+	keyFn := elementKeys[elem]
+	bKeys, bOK := keys(before, keyFn)
+	aKeys, aOK := keys(after, keyFn)
+	if keyFn == nil || !bOK || !aOK {
+		keyFn = nil
+		bKeys, _ = keys(before, nil)
+		aKeys, _ = keys(after, nil)
+	}
+
+	bIndex := map[string]int{}
+	for i, k := range bKeys {
+		bIndex[k] = i
+	}
+	aIndex := map[string]int{}
+	for i, k := range aKeys {
+		aIndex[k] = i
+	}
+
+	// Metadata is recorded as key/value pairs
+	element := func(k string, b, a reflect.Value) {
+		if keyFn != nil && elem == metadatumType {
+			d.value(path+"["+k+"]", field(b, 1), field(a, 1))
+		} else {
+			d.value(path+"["+k+"]", b, a)
+		}
+	}
+
+	// Removed elements are recorded last to first, each after its
+	// attributes, so that the changes can be applied (or reverted) in order.
+	for i := len(bKeys) - 1; i >= 0; i-- {
+		k := bKeys[i]
+		if _, ok := aIndex[k]; !ok {
+			element(k, before.Index(i), reflect.Value{})
+			d.add(changetype.Removal, path, k)
+		}
+	}
+
+	for i, k := range bKeys {
+		if j, ok := aIndex[k]; ok {
+			element(k, before.Index(i), after.Index(j))
+		}
+	}
+
+	for j, k := range aKeys {
+		if _, ok := bIndex[k]; !ok {
+			d.add(changetype.Addition, path, k)
+			element(k, reflect.Value{}, after.Index(j))
+		}
+	}
+}
+
+// set compares slices of strings as sets of values.
+func (d *differ) set(path string, before, after reflect.Value) {
+	bValues := map[string]bool{}
+	for i := 0; before.IsValid() && i < before.Len(); i++ {
+		bValues[before.Index(i).String()] = true
+	}
+	aValues := map[string]bool{}
+	for i := 0; after.IsValid() && i < after.Len(); i++ {
+		aValues[after.Index(i).String()] = true
+	}
+
+	for i := 0; before.IsValid() && i < before.Len(); i++ {
+		if v := before.Index(i).String(); !aValues[v] {
+			d.add(changetype.Removal, path, v)
+		}
+	}
+	for i := 0; after.IsValid() && i < after.Len(); i++ {
+		if v := after.Index(i).String(); !bValues[v] {
+			d.add(changetype.Addition, path, v)
+		}
+	}
+}
+
+func (d *differ) leaf(path string, before, after reflect.Value) {
+	b, bOK := format(before)
+	a, aOK := format(after)
+	if bOK == aOK && b == a {
+		return
+	}
+
+	if bOK {
+		d.add(changetype.Removal, path, b)
+	}
+	if aOK {
+		d.add(changetype.Addition, path, a)
+	}
+}
+
+// keys returns the keys of a slice's elements. If keyFn is nil, or the keys
+// it produces are not unique valid indices, positional keys are returned
+// along with false.
+func keys(v reflect.Value, keyFn func(reflect.Value) string) ([]string, bool) {
+	if !v.IsValid() {
+		return nil, true
+	}
+
+	result := make([]string, v.Len())
+	if keyFn != nil {
+		seen := map[string]bool{}
+		ok := true
+		for i := range result {
+			k := keyFn(v.Index(i))
+			if seen[k] || !api.AllowedIndexPattern.MatchString(k) {
+				ok = false
+				break
+			}
+			seen[k] = true
+			result[i] = k
+		}
+		if ok {
+			return result, true
+		}
+	}
+
+	for i := range result {
+		result[i] = strconv.Itoa(i)
+	}
+	return result, keyFn == nil
+}
+
+// format returns the string form of a scalar value, and false if the value
+// is absent.
+func format(v reflect.Value) (string, bool) {
+	if !v.IsValid() {
+		return "", false
+	}
+
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).UTC().Format(time.RFC3339Nano), true
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), true
+	}
+
+	return "", false
+}
+
+// indirect dereferences pointers, returning an invalid Value for nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// isZero returns true if v is invalid or holds the zero value of its type.
+func isZero(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+// field returns the i'th field of a struct, or an invalid Value if the
+// struct is absent.
+func field(v reflect.Value, i int) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	return v.Field(i)
+}
+
+// fieldName returns a field's JSON name, or the empty string if it is not
+// serialized.
+func fieldName(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name
+	}
+	return f.Name
+}
@@ -0,0 +1,358 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/changetype"
+	"github.com/turbinelabs/api/objecttype"
+	"github.com/turbinelabs/test/assert"
+)
+
+type change struct {
+	ct    changetype.ChangeType
+	path  string
+	value string
+}
+
+func add(path, value string) change    { return change{changetype.Addition, path, value} }
+func remove(path, value string) change { return change{changetype.Removal, path, value} }
+
+func changes(entries []api.ChangeEntry) []change {
+	result := []change{}
+	for _, e := range entries {
+		result = append(result, change{e.ChangeType, e.Path, e.Value})
+	}
+	return result
+}
+
+func testCluster() api.Cluster {
+	return api.Cluster{
+		ClusterKey: "ck",
+		ZoneKey:    "zk",
+		Name:       "api",
+		Instances: api.Instances{
+			{Host: "10.0.0.1", Port: 8080, Metadata: api.Metadata{{Key: "stage", Value: "prod"}}},
+			{Host: "10.0.0.2", Port: 8080},
+		},
+		Checksum: api.Checksum{Checksum: "cs-1"},
+	}
+}
+
+func TestClusterNoChanges(t *testing.T) {
+	c := testCluster()
+	after := testCluster()
+	after.Checksum = api.Checksum{Checksum: "cs-2"}
+
+	assert.Equal(t, len(Cluster(c, after)), 0)
+}
+
+func TestCluster(t *testing.T) {
+	before := testCluster()
+	after := testCluster()
+	after.Name = "api-v2"
+	after.RequireTLS = true
+	after.Instances[0].Metadata = api.Metadata{
+		{Key: "stage", Value: "canary"},
+		{Key: "version", Value: "2"},
+	}
+	after.Instances = append(after.Instances[:1], api.Instance{Host: "10.0.0.3", Port: 8080})
+	after.CircuitBreakers = &api.CircuitBreakers{MaxRetries: func(i int) *int { return &i }(3)}
+
+	entries := Cluster(before, after)
+	for _, e := range entries {
+		assert.Equal(t, e.ObjectType, objecttype.Cluster)
+		assert.Equal(t, e.ObjectKey, "ck")
+		assert.Equal(t, e.ZoneKey, api.ZoneKey("zk"))
+	}
+
+	assert.ArrayEqual(
+		t,
+		changes(entries),
+		[]change{
+			remove("cluster.name", "api"),
+			add("cluster.name", "api-v2"),
+			remove("cluster.require_tls", "false"),
+			add("cluster.require_tls", "true"),
+			remove("cluster.instances[10.0.0.2:8080].host", "10.0.0.2"),
+			remove("cluster.instances[10.0.0.2:8080].port", "8080"),
+			remove("cluster.instances", "10.0.0.2:8080"),
+			remove("cluster.instances[10.0.0.1:8080].metadata[stage]", "prod"),
+			add("cluster.instances[10.0.0.1:8080].metadata[stage]", "canary"),
+			add("cluster.instances[10.0.0.1:8080].metadata", "version"),
+			add("cluster.instances[10.0.0.1:8080].metadata[version]", "2"),
+			add("cluster.instances", "10.0.0.3:8080"),
+			add("cluster.instances[10.0.0.3:8080].host", "10.0.0.3"),
+			add("cluster.instances[10.0.0.3:8080].port", "8080"),
+			add("cluster.circuit_breakers.max_retries", "3"),
+		},
+	)
+}
+
+func TestClusterCreation(t *testing.T) {
+	entries := Cluster(api.Cluster{}, api.Cluster{ClusterKey: "ck", ZoneKey: "zk", Name: "c"})
+	assert.ArrayEqual(
+		t,
+		changes(entries),
+		[]change{
+			add("cluster.cluster_key", "ck"),
+			add("cluster.zone_key", "zk"),
+			add("cluster.name", "c"),
+			add("cluster.require_tls", "false"),
+		},
+	)
+}
+
+func TestClusterDeletion(t *testing.T) {
+	entries := Cluster(api.Cluster{ClusterKey: "ck", ZoneKey: "zk", Name: "c"}, api.Cluster{})
+	assert.ArrayEqual(
+		t,
+		changes(entries),
+		[]change{
+			remove("cluster.cluster_key", "ck"),
+			remove("cluster.zone_key", "zk"),
+			remove("cluster.name", "c"),
+			remove("cluster.require_tls", "false"),
+		},
+	)
+	for _, e := range entries {
+		assert.Equal(t, e.ObjectKey, "ck")
+		assert.Equal(t, e.ZoneKey, api.ZoneKey("zk"))
+	}
+}
+
+func TestRoute(t *testing.T) {
+	before := api.Route{
+		RouteKey: "rk",
+		ZoneKey:  "zk",
+		Path:     "/",
+		Rules: api.Rules{
+			{
+				RuleKey: "r1",
+				Methods: []string{"GET"},
+				Constraints: api.AllConstraints{
+					Light: api.ClusterConstraints{{ConstraintKey: "cc1", ClusterKey: "c1", Weight: 1}},
+				},
+			},
+		},
+	}
+
+	after := before
+	after.Rules = api.Rules{
+		{
+			RuleKey: "r1",
+			Methods: []string{"GET", "POST"},
+			Matches: api.Matches{
+				{
+					Kind:     api.HeaderMatchKind,
+					Behavior: api.ExactMatchBehavior,
+					From:     api.Metadatum{Key: "x"},
+				},
+			},
+			Constraints: api.AllConstraints{
+				Light: api.ClusterConstraints{{ConstraintKey: "cc1", ClusterKey: "c1", Weight: 2}},
+			},
+		},
+	}
+	after.CohortSeed = &api.CohortSeed{Type: api.CohortSeedHeader, Name: "x-user"}
+
+	assert.ArrayEqual(
+		t,
+		changes(Route(before, after)),
+		[]change{
+			add("route.rules[r1].methods", "POST"),
+			add("route.rules[r1].matches", "0"),
+			add("route.rules[r1].matches[0].kind", "header"),
+			add("route.rules[r1].matches[0].behavior", "exact"),
+			add("route.rules[r1].matches[0].from.key", "x"),
+			add("route.rules[r1].matches[0].from.value", ""),
+			add("route.rules[r1].matches[0].to.key", ""),
+			add("route.rules[r1].matches[0].to.value", ""),
+			remove("route.rules[r1].constraints.light[cc1].weight", "1"),
+			add("route.rules[r1].constraints.light[cc1].weight", "2"),
+			add("route.cohort_seed.type", "header"),
+			add("route.cohort_seed.name", "x-user"),
+			add("route.cohort_seed.use_zero_value_seed", "false"),
+		},
+	)
+}
+
+func TestDomain(t *testing.T) {
+	before := api.Domain{
+		DomainKey: "dk",
+		ZoneKey:   "zk",
+		Name:      "example.com",
+		Port:      80,
+		Aliases:   api.DomainAliases{"www.example.com", "*.example.com"},
+		Redirects: api.Redirects{
+			{Name: "r", From: "(.*)", To: "https://$host$1", RedirectType: api.PermanentRedirect},
+		},
+	}
+
+	after := before
+	after.Aliases = api.DomainAliases{"*.example.com", "example.*"}
+	after.Redirects = api.Redirects{
+		{Name: "r", From: "(.*)", To: "https://$host$1", RedirectType: api.TemporaryRedirect},
+	}
+
+	assert.ArrayEqual(
+		t,
+		changes(Domain(before, after)),
+		[]change{
+			remove("domain.redirects[r].redirect_type", "permanent"),
+			add("domain.redirects[r].redirect_type", "temporary"),
+			remove("domain.aliases", "www.example.com"),
+			add("domain.aliases", "example.*"),
+		},
+	)
+}
+
+func TestDomainRateLimitPolicy(t *testing.T) {
+	perClient := api.RateLimit{
+		Name:            "per-client",
+		Descriptors:     []api.RateLimitDescriptor{{Kind: api.RemoteAddressRateLimitDescriptor}},
+		RequestsPerUnit: 10,
+		Unit:            api.SecondRateLimitUnit,
+	}
+	perKey := api.RateLimit{
+		Name:            "per-key",
+		Descriptors:     []api.RateLimitDescriptor{{Kind: api.HeaderRateLimitDescriptor, Key: "x-api-key"}},
+		RequestsPerUnit: 100,
+		Unit:            api.MinuteRateLimitUnit,
+	}
+
+	before := api.Domain{
+		DomainKey:       "dk",
+		ZoneKey:         "zk",
+		Name:            "example.com",
+		Port:            80,
+		RateLimitPolicy: &api.RateLimitPolicy{Limits: []api.RateLimit{perClient, perKey}},
+	}
+
+	// limits are keyed by name, so inserting one does not move the others
+	perClient.RequestsPerUnit = 20
+	after := before
+	after.RateLimitPolicy = &api.RateLimitPolicy{
+		Limits: []api.RateLimit{
+			{Name: "global", RequestsPerUnit: 1000, Unit: api.SecondRateLimitUnit},
+			perClient,
+			perKey,
+		},
+	}
+
+	assert.ArrayEqual(
+		t,
+		changes(Domain(before, after)),
+		[]change{
+			remove("domain.rate_limit_policy.limits[per-client].requests_per_unit", "10"),
+			add("domain.rate_limit_policy.limits[per-client].requests_per_unit", "20"),
+			add("domain.rate_limit_policy.limits", "global"),
+			add("domain.rate_limit_policy.limits[global].name", "global"),
+			add("domain.rate_limit_policy.limits[global].requests_per_unit", "1000"),
+			add("domain.rate_limit_policy.limits[global].unit", "second"),
+		},
+	)
+}
+
+func TestSharedRulesPositionalFallback(t *testing.T) {
+	// keys that are not valid indices cause positional indexing
+	before := api.SharedRules{
+		SharedRulesKey: "srk",
+		ZoneKey:        "zk",
+		Properties:     api.Metadata{{Key: "a[0]", Value: "1"}},
+	}
+	after := before
+	after.Properties = api.Metadata{{Key: "a[0]", Value: "2"}}
+
+	assert.ArrayEqual(
+		t,
+		changes(SharedRules(before, after)),
+		[]change{
+			remove("shared_rules.properties[0].value", "1"),
+			add("shared_rules.properties[0].value", "2"),
+		},
+	)
+}
+
+func TestProxyAndListener(t *testing.T) {
+	before := api.Proxy{ProxyKey: "pk", ZoneKey: "zk", DomainKeys: []api.DomainKey{"dk1"}}
+	after := before
+	after.DomainKeys = []api.DomainKey{"dk1", "dk2"}
+	after.ListenerKeys = []api.ListenerKey{"lk"}
+
+	assert.ArrayEqual(
+		t,
+		changes(Proxy(before, after)),
+		[]change{add("proxy.domain_keys", "dk2"), add("proxy.listener_keys", "lk")},
+	)
+
+	l := api.Listener{ListenerKey: "lk", ZoneKey: "zk", Port: 80}
+	l2 := l
+	l2.Port = 8080
+	entries := Listener(l, l2)
+	assert.ArrayEqual(
+		t,
+		changes(entries),
+		[]change{remove("listener.port", "80"), add("listener.port", "8080")},
+	)
+	assert.Equal(t, entries[0].ObjectType, objecttype.Listener)
+}
+
+func TestZoneAndUser(t *testing.T) {
+	entries := Zone(api.Zone{ZoneKey: "zk", Name: "a"}, api.Zone{ZoneKey: "zk", Name: "b"})
+	assert.ArrayEqual(
+		t,
+		changes(entries),
+		[]change{remove("zone.name", "a"), add("zone.name", "b")},
+	)
+	assert.Equal(t, entries[0].ZoneKey, api.ZoneKey("zk"))
+
+	deleted := time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC)
+	before := api.User{UserKey: "uk", LoginEmail: "a@example.com", OrgKey: "ok"}
+	after := before
+	after.DeletedAt = &deleted
+
+	entries = User(before, after)
+	assert.ArrayEqual(
+		t,
+		changes(entries),
+		[]change{add("user.deleted_at", "2018-03-01T12:00:00Z")},
+	)
+	assert.Equal(t, entries[0].ObjectKey, "uk")
+	assert.Equal(t, entries[0].ZoneKey, api.ZoneKey(""))
+}
+
+func TestOrgAndAccessToken(t *testing.T) {
+	entries := Org(api.Org{}, api.Org{OrgKey: "ok", Name: "o"})
+	assert.ArrayEqual(
+		t,
+		changes(entries),
+		[]change{add("org.org_key", "ok"), add("org.name", "o"), add("org.contact_email", "")},
+	)
+	assert.Equal(t, entries[0].ObjectKey, "ok")
+
+	token := api.AccessToken{AccessTokenKey: "atk", Description: "ci"}
+	entries = AccessToken(token, api.AccessToken{})
+	assert.Equal(t, entries[0].ObjectKey, "atk")
+	for _, e := range entries {
+		assert.Equal(t, e.ObjectType, objecttype.AccessToken)
+		assert.Equal(t, e.ChangeType, changetype.Removal)
+	}
+}
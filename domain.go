@@ -49,17 +49,18 @@ var DomainAliasPattern = regexp.MustCompile(fmt.Sprintf(
 
 // A Domain represents the TLD or subdomain under which which a set of Routes is served.
 type Domain struct {
-	DomainKey   DomainKey     `json:"domain_key"` // overwritten for create
-	ZoneKey     ZoneKey       `json:"zone_key"`
-	Name        string        `json:"name"`
-	Port        int           `json:"port"`
-	SSLConfig   *SSLConfig    `json:"ssl_config,omitempty"`
-	Redirects   Redirects     `json:"redirects"`
-	GzipEnabled bool          `json:"gzip_enabled"`
-	CorsConfig  *CorsConfig   `json:"cors_config"`
-	Aliases     DomainAliases `json:"aliases"`
-	OrgKey      OrgKey        `json:"-"`
-	ForceHTTPS  bool          `json:"force_https"`
+	DomainKey       DomainKey        `json:"domain_key"` // overwritten for create
+	ZoneKey         ZoneKey          `json:"zone_key"`
+	Name            string           `json:"name"`
+	Port            int              `json:"port"`
+	SSLConfig       *SSLConfig       `json:"ssl_config,omitempty"`
+	Redirects       Redirects        `json:"redirects"`
+	GzipEnabled     bool             `json:"gzip_enabled"`
+	CorsConfig      *CorsConfig      `json:"cors_config"`
+	Aliases         DomainAliases    `json:"aliases"`
+	RateLimitPolicy *RateLimitPolicy `json:"rate_limit_policy"`
+	OrgKey          OrgKey           `json:"-"`
+	ForceHTTPS      bool             `json:"force_https"`
 	Checksum
 }
 
@@ -161,6 +162,10 @@ func (d Domain) IsValid() *ValidationError {
 		errs.MergePrefixed(d.SSLConfig.IsValid(), parent)
 	}
 
+	if d.RateLimitPolicy != nil {
+		errs.MergePrefixed(d.RateLimitPolicy.IsValid(), parent)
+	}
+
 	return errs.OrNil()
 }
 
@@ -184,6 +189,7 @@ func (d Domain) Equals(o Domain) bool {
 		d.GzipEnabled == o.GzipEnabled &&
 		d.Redirects.Equals(o.Redirects) &&
 		d.ForceHTTPS == o.ForceHTTPS &&
+		RateLimitPolicyEquals(d.RateLimitPolicy, o.RateLimitPolicy) &&
 		ccEq
 }
 
@@ -40,12 +40,14 @@ func getDomains() (Domain, Domain) {
 		true,
 		mkCC(),
 		DomainAliases{},
+		mkRLP(),
 		"okey",
 		true,
 		Checksum{"aoeusnth"},
 	}
 	d2 := d
 	d2.CorsConfig = mkCC()
+	d2.RateLimitPolicy = mkRLP()
 	return d, d2
 }
 
@@ -112,6 +114,31 @@ func TestDomainEqualsForceHTTPSChanged(t *testing.T) {
 	assert.False(t, d1.Equals(d2))
 }
 
+func TestDomainEqualsRateLimitPolicyNilNil(t *testing.T) {
+	d1, d2 := getDomains()
+	d1.RateLimitPolicy = nil
+	d2.RateLimitPolicy = nil
+
+	assert.True(t, d2.Equals(d1))
+	assert.True(t, d1.Equals(d2))
+}
+
+func TestDomainEqualsRateLimitPolicySomeNil(t *testing.T) {
+	d1, d2 := getDomains()
+	d2.RateLimitPolicy = nil
+
+	assert.False(t, d2.Equals(d1))
+	assert.False(t, d1.Equals(d2))
+}
+
+func TestDomainEqualsRateLimitPolicyChanges(t *testing.T) {
+	d1, d2 := getDomains()
+	d2.RateLimitPolicy.RejectStatus = 503
+
+	assert.False(t, d2.Equals(d1))
+	assert.False(t, d1.Equals(d2))
+}
+
 func TestDomainNotEqualsKeyVaries(t *testing.T) {
 	d1, d2 := getDomains()
 	d2.DomainKey = "dkey2"
@@ -163,6 +190,7 @@ func getDomain() Domain {
 		true,
 		cc,
 		DomainAliases{},
+		mkRLP(),
 		"okey",
 		true,
 		Checksum{},
@@ -237,6 +265,14 @@ func TestDomainIsValidFailsOnSSLConfig(t *testing.T) {
 	assert.NonNil(t, d1.IsValid())
 }
 
+func TestDomainIsValidFailsOnRateLimitPolicy(t *testing.T) {
+	d1 := getDomain()
+	d1.RateLimitPolicy.Limits = nil
+	assert.DeepEqual(t, d1.IsValid(), &ValidationError{[]ErrorCase{
+		{"domain.rate_limit_policy.limits", "must have at least one limit"},
+	}})
+}
+
 func TestDomainIsValidFailedDkey(t *testing.T) {
 	d1 := getDomain()
 	d1.DomainKey = ""
@@ -294,9 +330,9 @@ func TestDomainIsValidAlias(t *testing.T) {
 }
 
 func getThreeDomains() (Domain, Domain, Domain) {
-	d1 := Domain{"dkey-1", "zk", "name", 10, nil, nil, true, nil, DomainAliases{}, "okey", true, Checksum{}}
-	d2 := Domain{"dkey-2", "zk", "name", 20, nil, nil, true, nil, DomainAliases{}, "okey", true, Checksum{}}
-	d3 := Domain{"dkey-3", "zk", "name", 30, nil, nil, true, nil, DomainAliases{}, "okey", true, Checksum{}}
+	d1 := Domain{"dkey-1", "zk", "name", 10, nil, nil, true, nil, DomainAliases{}, nil, "okey", true, Checksum{}}
+	d2 := Domain{"dkey-2", "zk", "name", 20, nil, nil, true, nil, DomainAliases{}, nil, "okey", true, Checksum{}}
+	d3 := Domain{"dkey-3", "zk", "name", 30, nil, nil, true, nil, DomainAliases{}, nil, "okey", true, Checksum{}}
 
 	return d1, d2, d3
 }
@@ -329,27 +365,27 @@ func TestDomainsEqualsFailure(t *testing.T) {
 }
 
 func TestDomainsIsValidSuccess(t *testing.T) {
-	d1 := Domain{"dkey-1", "zk", "name", 10, nil, nil, true, nil, DomainAliases{}, "okey", true, Checksum{}}
-	d2 := Domain{"dkey-2", "zk", "name", 20, nil, nil, true, nil, DomainAliases{}, "okey", true, Checksum{}}
-	d3 := Domain{"dkey-3", "zk", "name", 30, nil, nil, true, nil, DomainAliases{}, "okey", true, Checksum{}}
+	d1 := Domain{"dkey-1", "zk", "name", 10, nil, nil, true, nil, DomainAliases{}, nil, "okey", true, Checksum{}}
+	d2 := Domain{"dkey-2", "zk", "name", 20, nil, nil, true, nil, DomainAliases{}, nil, "okey", true, Checksum{}}
+	d3 := Domain{"dkey-3", "zk", "name", 30, nil, nil, true, nil, DomainAliases{}, nil, "okey", true, Checksum{}}
 	ds := Domains{d3, d2, d1}
 
 	assert.Nil(t, ds.IsValid())
 }
 
 func TestDomainsIsValidFailureDupe(t *testing.T) {
-	d1 := Domain{"dkey-1", "zk", "name", 10, nil, nil, true, nil, DomainAliases{}, "okey", true, Checksum{}}
-	d2 := Domain{"dkey-2", "zk", "name", 20, nil, nil, true, nil, DomainAliases{}, "okey", true, Checksum{}}
-	d3 := Domain{"dkey-3", "zk", "name", 30, nil, nil, true, nil, DomainAliases{}, "okey", true, Checksum{}}
+	d1 := Domain{"dkey-1", "zk", "name", 10, nil, nil, true, nil, DomainAliases{}, nil, "okey", true, Checksum{}}
+	d2 := Domain{"dkey-2", "zk", "name", 20, nil, nil, true, nil, DomainAliases{}, nil, "okey", true, Checksum{}}
+	d3 := Domain{"dkey-3", "zk", "name", 30, nil, nil, true, nil, DomainAliases{}, nil, "okey", true, Checksum{}}
 	ds := Domains{d3, d2, d1, d3}
 
 	assert.NonNil(t, ds.IsValid())
 }
 
 func TestDomainsIsValidFailureBadDomain(t *testing.T) {
-	d1 := Domain{"dkey-1", "zk", "name", 10, nil, nil, true, nil, DomainAliases{}, "okey", true, Checksum{}}
-	d2 := Domain{"dkey-2", "", "name", 20, nil, nil, true, nil, DomainAliases{}, "okey", true, Checksum{}}
-	d3 := Domain{"dkey-3", "zk", "name", 30, nil, nil, true, nil, DomainAliases{}, "okey", true, Checksum{}}
+	d1 := Domain{"dkey-1", "zk", "name", 10, nil, nil, true, nil, DomainAliases{}, nil, "okey", true, Checksum{}}
+	d2 := Domain{"dkey-2", "", "name", 20, nil, nil, true, nil, DomainAliases{}, nil, "okey", true, Checksum{}}
+	d3 := Domain{"dkey-3", "zk", "name", 30, nil, nil, true, nil, DomainAliases{}, nil, "okey", true, Checksum{}}
 	ds := Domains{d3, d2, d1}
 
 	assert.NonNil(t, ds.IsValid())
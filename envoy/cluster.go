@@ -0,0 +1,176 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envoy
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/turbinelabs/api"
+)
+
+// cluster translates a Cluster. Its Instances become static endpoints,
+// resolved by DNS unless every Host is an IP address.
+func (g *generator) cluster(c api.Cluster) (Cluster, error) {
+	cluster := Cluster{
+		Name:           c.Name,
+		Type:           "STATIC",
+		ConnectTimeout: connectTimeout,
+		LoadAssignment: ClusterLoadAssignment{
+			ClusterName: c.Name,
+			Endpoints:   []LocalityLbEndpoints{},
+		},
+	}
+
+	if len(c.Instances) > 0 {
+		endpoints := make([]LbEndpoint, len(c.Instances))
+		for i, inst := range c.Instances {
+			if net.ParseIP(inst.Host) == nil {
+				cluster.Type = "STRICT_DNS"
+			}
+			endpoints[i] = LbEndpoint{
+				Endpoint: Endpoint{
+					Address: Address{
+						SocketAddress: SocketAddress{Address: inst.Host, PortValue: inst.Port},
+					},
+				},
+				Metadata: lbMetadata(inst.Metadata),
+			}
+		}
+		cluster.LoadAssignment.Endpoints = []LocalityLbEndpoints{{LbEndpoints: endpoints}}
+	}
+
+	if selectors := g.selectors[c.ClusterKey]; len(selectors) > 0 {
+		names := make([]string, 0, len(selectors))
+		for name := range selectors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		cluster.LbSubsetConfig = &LbSubsetConfig{FallbackPolicy: "ANY_ENDPOINT"}
+		for _, name := range names {
+			cluster.LbSubsetConfig.SubsetSelectors = append(
+				cluster.LbSubsetConfig.SubsetSelectors,
+				LbSubsetSelector{Keys: selectors[name]},
+			)
+		}
+	}
+
+	for i, hc := range c.HealthChecks {
+		healthCheck, err := healthCheck(hc)
+		if err != nil {
+			return Cluster{}, fmt.Errorf("cluster %q: health_checks[%d]: %v", c.Name, i, err)
+		}
+		cluster.HealthChecks = append(cluster.HealthChecks, healthCheck)
+	}
+
+	if od := c.OutlierDetection; od != nil {
+		cluster.OutlierDetection = &OutlierDetection{
+			Interval:                           optionalDuration(od.IntervalMsec),
+			BaseEjectionTime:                   optionalDuration(od.BaseEjectionTimeMsec),
+			MaxEjectionPercent:                 od.MaxEjectionPercent,
+			Consecutive5xx:                     od.Consecutive5xx,
+			EnforcingConsecutive5xx:            od.EnforcingConsecutive5xx,
+			EnforcingSuccessRate:               od.EnforcingSuccessRate,
+			SuccessRateMinimumHosts:            od.SuccessRateMinimumHosts,
+			SuccessRateRequestVolume:           od.SuccessRateRequestVolume,
+			SuccessRateStdevFactor:             od.SuccessRateStdevFactor,
+			ConsecutiveGatewayFailure:          od.ConsecutiveGatewayFailure,
+			EnforcingConsecutiveGatewayFailure: od.EnforcingConsecutiveGatewayFailure,
+		}
+	}
+
+	if cb := c.CircuitBreakers; cb != nil {
+		cluster.CircuitBreakers = &CircuitBreakers{
+			Thresholds: []Thresholds{
+				{
+					MaxConnections:     cb.MaxConnections,
+					MaxPendingRequests: cb.MaxPendingRequests,
+					MaxRequests:        cb.MaxRequests,
+					MaxRetries:         cb.MaxRetries,
+				},
+			},
+		}
+	}
+
+	if c.RequireTLS {
+		cluster.TLSContext = &UpstreamTLSContext{}
+	}
+
+	return cluster, nil
+}
+
+func healthCheck(hc api.HealthCheck) (HealthCheck, error) {
+	result := HealthCheck{
+		Timeout:               duration(hc.TimeoutMsec),
+		Interval:              duration(hc.IntervalMsec),
+		IntervalJitter:        optionalDuration(hc.IntervalJitterMsec),
+		UnhealthyThreshold:    hc.UnhealthyThreshold,
+		HealthyThreshold:      hc.HealthyThreshold,
+		ReuseConnection:       hc.ReuseConnection,
+		NoTrafficInterval:     optionalDuration(hc.NoTrafficIntervalMsec),
+		UnhealthyInterval:     optionalDuration(hc.UnhealthyIntervalMsec),
+		UnhealthyEdgeInterval: optionalDuration(hc.UnhealthyEdgeIntervalMsec),
+		HealthyEdgeInterval:   optionalDuration(hc.HealthyEdgeIntervalMsec),
+	}
+
+	if h := hc.HealthChecker.HTTPHealthCheck; h != nil {
+		result.HTTPHealthCheck = &HTTPHealthCheck{
+			Host:        h.Host,
+			Path:        h.Path,
+			ServiceName: h.ServiceName,
+		}
+		for _, md := range h.RequestHeadersToAdd {
+			result.HTTPHealthCheck.RequestHeadersToAdd = append(
+				result.HTTPHealthCheck.RequestHeadersToAdd,
+				HeaderValueOption{Header: HeaderValue{Key: md.Key, Value: md.Value}},
+			)
+		}
+	}
+
+	if t := hc.HealthChecker.TCPHealthCheck; t != nil {
+		result.TCPHealthCheck = &TCPHealthCheck{}
+		if t.Send != "" {
+			send, err := payload(t.Send)
+			if err != nil {
+				return HealthCheck{}, fmt.Errorf("send: %v", err)
+			}
+			result.TCPHealthCheck.Send = &send
+		}
+		for i, r := range t.Receive {
+			receive, err := payload(r)
+			if err != nil {
+				return HealthCheck{}, fmt.Errorf("receive[%d]: %v", i, err)
+			}
+			result.TCPHealthCheck.Receive = append(result.TCPHealthCheck.Receive, receive)
+		}
+	}
+
+	return result, nil
+}
+
+// payload converts base64 encoded bytes into a hex encoded Payload.
+func payload(s string) (Payload, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return Payload{}, err
+	}
+	return Payload{Text: hex.EncodeToString(b)}, nil
+}
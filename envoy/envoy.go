@@ -0,0 +1,279 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package envoy translates the objects served by a Proxy into Envoy v2
+// configuration resources. Generate produces Listeners, RouteConfigurations
+// and Clusters suitable for serving over aggregated discovery, and
+// Resources.Bootstrap produces an equivalent static bootstrap configuration:
+//
+// 	resources, err := envoy.Generate(
+// 		envoy.Objects{
+// 			Proxy:       proxy,
+// 			Listeners:   listeners,
+// 			Domains:     domains,
+// 			Routes:      routes,
+// 			SharedRules: sharedRules,
+// 			Clusters:    clusters,
+// 		},
+// 	)
+//
+// Domains are attached to the Proxy's Listeners with matching ports. As
+// described by Proxy, a default Listener on 0.0.0.0 is created for any
+// port without one. Each Domain becomes a virtual host answering to its name
+// and Aliases. Each Route becomes one Envoy route per Rule, followed by one
+// for its SharedRules' Default; the metadata of each ClusterConstraint,
+// together with any constraints derived from a Rule's Matches, selects a
+// subset of the Cluster's Instances.
+//
+// The RequestData and ResponseData of a SharedRules, Route and
+// ClusterConstraint, merged in that order, modify the requests and responses
+// of each Envoy route and its weighted clusters. A ResponseData value that
+// is not literal refers to the metadata of the Instance handling the
+// request; a RequestData value that is not literal may refer to any request
+// attribute except cookies and query parameters.
+//
+// Some behavior has no Envoy equivalent: a request whose Rule selects no
+// Instances falls back to any Instance of the Cluster rather than to the
+// next Rule, and CohortSeeds, FaultInjections, RateLimitPolicies, Matches
+// whose constraints are derived from request values, range Matches on
+// cookies or query parameters, and Redirects using capture groups other than
+// a trailing "$1" produce errors.
+package envoy
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/turbinelabs/api"
+)
+
+const (
+	defaultListenerIP = "0.0.0.0"
+	connectTimeout    = "1s"
+	lbFilter          = "envoy.lb"
+)
+
+var codecTypes = map[api.ListenerProtocol]string{
+	api.HttpListenerProtocol:     "HTTP1",
+	api.Http2ListenerProtocol:    "HTTP2",
+	api.HttpAutoListenerProtocol: "AUTO",
+}
+
+// Objects holds the objects translated by Generate. Listeners, Domains,
+// Routes, SharedRules and Clusters may include objects not referenced by
+// the Proxy; they are ignored, with the exception of Clusters, all of which
+// are translated.
+type Objects struct {
+	Proxy       api.Proxy
+	Listeners   api.Listeners
+	Domains     api.Domains
+	Routes      api.Routes
+	SharedRules api.SharedRulesSlice
+	Clusters    api.Clusters
+}
+
+// Resources are the Envoy resources generated for a Proxy. Each Listener
+// refers to the RouteConfiguration with the same name.
+type Resources struct {
+	Listeners           []Listener           `json:"listeners"`
+	RouteConfigurations []RouteConfiguration `json:"route_configurations"`
+	Clusters            []Cluster            `json:"clusters"`
+}
+
+// Bootstrap returns a static bootstrap configuration equivalent to the
+// Resources, with each Listener's RouteConfiguration inlined.
+func (r *Resources) Bootstrap() Bootstrap {
+	routeConfigs := map[string]RouteConfiguration{}
+	for _, rc := range r.RouteConfigurations {
+		routeConfigs[rc.Name] = rc
+	}
+
+	listeners := make([]Listener, len(r.Listeners))
+	for i, l := range r.Listeners {
+		chains := make([]FilterChain, len(l.FilterChains))
+		for j, fc := range l.FilterChains {
+			filters := make([]Filter, len(fc.Filters))
+			for k, f := range fc.Filters {
+				hcm := *f.Config
+				if hcm.RDS != nil {
+					rc := routeConfigs[hcm.RDS.RouteConfigName]
+					hcm.RouteConfig = &rc
+					hcm.RDS = nil
+				}
+				filters[k] = Filter{Name: f.Name, Config: &hcm}
+			}
+			fc.Filters = filters
+			chains[j] = fc
+		}
+		l.FilterChains = chains
+		listeners[i] = l
+	}
+
+	return Bootstrap{
+		StaticResources: StaticResources{
+			Listeners: listeners,
+			Clusters:  append([]Cluster{}, r.Clusters...),
+		},
+	}
+}
+
+// Generate translates the objects served by the Proxy into Envoy Resources.
+// An error is returned if the Proxy refers to missing objects or if its
+// configuration cannot be expressed in Envoy.
+func Generate(o Objects) (*Resources, error) {
+	g := newGenerator(o)
+
+	domains := make([]api.Domain, 0, len(o.Proxy.DomainKeys))
+	for _, dk := range o.Proxy.DomainKeys {
+		d, ok := g.domains[dk]
+		if !ok {
+			return nil, fmt.Errorf("domain %q does not exist", dk)
+		}
+		domains = append(domains, d)
+	}
+
+	listeners := make(api.Listeners, 0, len(o.Proxy.ListenerKeys))
+	ports := map[int]bool{}
+	for _, lk := range o.Proxy.ListenerKeys {
+		l, ok := g.listeners[lk]
+		if !ok {
+			return nil, fmt.Errorf("listener %q does not exist", lk)
+		}
+		listeners = append(listeners, l)
+		ports[l.Port] = true
+	}
+
+	// domains on ports without a listener get a default one
+	defaultPorts := []int{}
+	for _, d := range domains {
+		if !ports[d.Port] {
+			defaultPorts = append(defaultPorts, d.Port)
+			ports[d.Port] = true
+		}
+	}
+	sort.Ints(defaultPorts)
+	for _, port := range defaultPorts {
+		listeners = append(
+			listeners,
+			api.Listener{
+				Name:     defaultListenerIP + ":" + strconv.Itoa(port),
+				IP:       defaultListenerIP,
+				Port:     port,
+				Protocol: api.HttpAutoListenerProtocol,
+			},
+		)
+	}
+
+	resources := &Resources{
+		Listeners:           []Listener{},
+		RouteConfigurations: []RouteConfiguration{},
+		Clusters:            []Cluster{},
+	}
+
+	for _, l := range listeners {
+		attached := []api.Domain{}
+		for _, d := range domains {
+			if d.Port == l.Port {
+				attached = append(attached, d)
+			}
+		}
+
+		listener, err := g.listener(l, attached)
+		if err != nil {
+			return nil, err
+		}
+
+		rc := RouteConfiguration{Name: l.Name, VirtualHosts: []VirtualHost{}}
+		for _, d := range attached {
+			vh, err := g.virtualHost(d)
+			if err != nil {
+				return nil, err
+			}
+			rc.VirtualHosts = append(rc.VirtualHosts, vh)
+		}
+
+		resources.Listeners = append(resources.Listeners, listener)
+		resources.RouteConfigurations = append(resources.RouteConfigurations, rc)
+	}
+
+	// clusters are generated last, once all subset selectors are known
+	clusters := append(api.Clusters{}, o.Clusters...)
+	sort.Sort(api.ClusterByName(clusters))
+	for _, c := range clusters {
+		cluster, err := g.cluster(c)
+		if err != nil {
+			return nil, err
+		}
+		resources.Clusters = append(resources.Clusters, cluster)
+	}
+
+	return resources, nil
+}
+
+// generator holds the objects being translated, indexed by key, and the
+// subset selectors required of each Cluster by the routes generated so far.
+type generator struct {
+	listeners   map[api.ListenerKey]api.Listener
+	domains     map[api.DomainKey]api.Domain
+	routes      map[api.DomainKey]api.Routes
+	sharedRules map[api.SharedRulesKey]api.SharedRules
+	clusters    map[api.ClusterKey]api.Cluster
+	selectors   map[api.ClusterKey]map[string][]string
+}
+
+func newGenerator(o Objects) *generator {
+	g := &generator{
+		listeners:   map[api.ListenerKey]api.Listener{},
+		domains:     map[api.DomainKey]api.Domain{},
+		routes:      map[api.DomainKey]api.Routes{},
+		sharedRules: map[api.SharedRulesKey]api.SharedRules{},
+		clusters:    map[api.ClusterKey]api.Cluster{},
+		selectors:   map[api.ClusterKey]map[string][]string{},
+	}
+
+	for _, l := range o.Listeners {
+		g.listeners[l.ListenerKey] = l
+	}
+	for _, d := range o.Domains {
+		g.domains[d.DomainKey] = d
+	}
+	for _, r := range o.Routes {
+		g.routes[r.DomainKey] = append(g.routes[r.DomainKey], r)
+	}
+	for _, sr := range o.SharedRules {
+		g.sharedRules[sr.SharedRulesKey] = sr
+	}
+	for _, c := range o.Clusters {
+		g.clusters[c.ClusterKey] = c
+	}
+
+	return g
+}
+
+// duration formats milliseconds as a protobuf JSON Duration.
+func duration(msec int) string {
+	return strconv.FormatFloat(float64(msec)/1000, 'f', -1, 64) + "s"
+}
+
+// optionalDuration formats milliseconds as a protobuf JSON Duration, or
+// returns the empty string if msec is nil.
+func optionalDuration(msec *int) string {
+	if msec == nil {
+		return ""
+	}
+	return duration(*msec)
+}
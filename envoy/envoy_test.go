@@ -0,0 +1,604 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envoy
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/test/assert"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+func intPtr(i int) *int { return &i }
+
+func uintPtr(i uint) *uint { return &i }
+
+func testObjects() Objects {
+	reuse := true
+
+	return Objects{
+		Proxy: api.Proxy{
+			ProxyKey:     "pk",
+			Name:         "proxy",
+			DomainKeys:   []api.DomainKey{"main", "secure", "api"},
+			ListenerKeys: []api.ListenerKey{"api-listener"},
+		},
+		Listeners: api.Listeners{
+			{
+				ListenerKey: "api-listener",
+				Name:        "api-listener",
+				IP:          "0.0.0.0",
+				Port:        8080,
+				Protocol:    api.HttpAutoListenerProtocol,
+				TracingConfig: &api.TracingConfig{
+					Ingress:               true,
+					RequestHeadersForTags: []string{"x-request-id"},
+				},
+			},
+		},
+		Domains: api.Domains{
+			{
+				DomainKey:   "main",
+				Name:        "example.com",
+				Port:        80,
+				Aliases:     api.DomainAliases{"*.example.com", "example.*"},
+				GzipEnabled: true,
+				CorsConfig: &api.CorsConfig{
+					AllowedOrigins: []string{"*"},
+					AllowedMethods: []string{"GET", "POST"},
+					AllowedHeaders: []string{"x-app"},
+					MaxAge:         60,
+				},
+				Redirects: api.Redirects{
+					{
+						Name:         "docs",
+						From:         "/docs/(.*)",
+						To:           "https://docs.example.com/v2/$1",
+						RedirectType: api.PermanentRedirect,
+					},
+					{
+						Name:         "legacy",
+						From:         "/legacy.*",
+						To:           "http://$host/",
+						RedirectType: api.TemporaryRedirect,
+						HeaderConstraints: api.HeaderConstraints{
+							{Name: "X-Keep-Legacy", Value: "yes", Invert: true},
+						},
+					},
+				},
+			},
+			{
+				DomainKey:  "secure",
+				Name:       "secure.example.com",
+				Port:       443,
+				Aliases:    api.DomainAliases{"www.secure.example.com"},
+				ForceHTTPS: true,
+				SSLConfig: &api.SSLConfig{
+					Protocols: []api.SSLProtocol{api.TLS1_1, api.TLS1_2},
+					CertKeyPairs: []api.CertKeyPathPair{
+						{CertificatePath: "/etc/tls/secure.crt", KeyPath: "/etc/tls/secure.key"},
+					},
+				},
+			},
+			{DomainKey: "api", Name: "api.example.com", Port: 8080},
+			{DomainKey: "unused", Name: "unused.example.com", Port: 80},
+		},
+		Routes: api.Routes{
+			{RouteKey: "main-root", DomainKey: "main", Path: "/", SharedRulesKey: "web-rules"},
+			{
+				RouteKey:       "main-api",
+				DomainKey:      "main",
+				Path:           "/api",
+				SharedRulesKey: "api-rules",
+				RetryPolicy:    &api.RetryPolicy{NumRetries: 2, PerTryTimeoutMsec: 500, TimeoutMsec: 1500},
+				RequestData: api.RequestData{
+					Headers: []api.RequestHeaderDatum{
+						{Name: "X-Client-IP", Action: api.OverwriteHeaderAction, Value: "source_ip"},
+						{Name: "X-Original-Host", Action: api.AddHeaderAction, Value: "header:Host"},
+						{Name: "X-Debug", Action: api.OverwriteHeaderAction, Value: "1", ValueIsLiteral: true},
+					},
+				},
+				ResponseData: api.ResponseData{
+					Headers: []api.HeaderDatum{
+						{ResponseDatum: api.ResponseDatum{Name: "x-tier", Value: "api-100%", ValueIsLiteral: true}},
+					},
+					Cookies: []api.CookieDatum{
+						{
+							ResponseDatum: api.ResponseDatum{Name: "stage", Value: "stage"},
+							ExpiresInSec:  uintPtr(60),
+							Path:          "/api",
+							HttpOnly:      true,
+						},
+					},
+				},
+				Rules: api.Rules{
+					{
+						RuleKey: "canary",
+						Methods: []string{"GET", "HEAD"},
+						Matches: api.Matches{
+							{
+								Kind:     api.HeaderMatchKind,
+								Behavior: api.ExactMatchBehavior,
+								From:     api.Metadatum{Key: "X-Canary", Value: "true"},
+								To:       api.Metadatum{Key: "stage", Value: "canary"},
+							},
+							{
+								Kind:     api.CookieMatchKind,
+								Behavior: api.PrefixMatchBehavior,
+								From:     api.Metadatum{Key: "user", Value: "internal-"},
+							},
+						},
+						Constraints: api.AllConstraints{
+							Light: api.ClusterConstraints{{ConstraintKey: "c", ClusterKey: "api", Weight: 1}},
+							Tap:   api.ClusterConstraints{{ConstraintKey: "t", ClusterKey: "mirror", Weight: 1}},
+						},
+					},
+				},
+			},
+			{RouteKey: "secure-root", DomainKey: "secure", Path: "/", SharedRulesKey: "web-rules"},
+			{RouteKey: "api-root", DomainKey: "api", Path: "/", SharedRulesKey: "api-rules"},
+		},
+		SharedRules: api.SharedRulesSlice{
+			{
+				SharedRulesKey: "web-rules",
+				Default: api.AllConstraints{
+					Light: api.ClusterConstraints{{ConstraintKey: "w", ClusterKey: "web", Weight: 1}},
+				},
+			},
+			{
+				SharedRulesKey: "api-rules",
+				RetryPolicy:    &api.RetryPolicy{TimeoutMsec: 3000},
+				RequestData: api.RequestData{
+					Headers: []api.RequestHeaderDatum{
+						{Name: "X-Env", Action: api.OverwriteHeaderAction, Value: "prod", ValueIsLiteral: true},
+						{Name: "X-Debug", Action: api.RemoveHeaderAction},
+					},
+				},
+				ResponseData: api.ResponseData{
+					Headers: []api.HeaderDatum{
+						{ResponseDatum: api.ResponseDatum{Name: "X-Version", Value: "version"}},
+						{ResponseDatum: api.ResponseDatum{Name: "X-Tier", Value: "api", ValueIsLiteral: true}},
+					},
+				},
+				Default: api.AllConstraints{
+					Light: api.ClusterConstraints{
+						{
+							ConstraintKey: "p",
+							ClusterKey:    "api",
+							Metadata:      api.Metadata{{Key: "stage", Value: "prod"}},
+							Weight:        90,
+						},
+						{
+							ConstraintKey: "n",
+							ClusterKey:    "api",
+							Metadata: api.Metadata{
+								{Key: "version", Value: "2"},
+								{Key: "stage", Value: "prod"},
+							},
+							Weight: 10,
+							RequestData: api.RequestData{
+								Headers: []api.RequestHeaderDatum{
+									{Name: "x-env", Action: api.OverwriteHeaderAction, Value: "next", ValueIsLiteral: true},
+								},
+							},
+							ResponseData: api.ResponseData{
+								Headers: []api.HeaderDatum{
+									{ResponseDatum: api.ResponseDatum{Name: "X-Version", Value: "2", ValueIsLiteral: true}},
+								},
+							},
+						},
+					},
+					Dark: api.ClusterConstraints{{ConstraintKey: "d", ClusterKey: "mirror", Weight: 1}},
+				},
+				Rules: api.Rules{
+					{
+						RuleKey: "beta",
+						Matches: api.Matches{
+							{
+								Kind:     api.QueryMatchKind,
+								Behavior: api.RegexMatchBehavior,
+								From:     api.Metadatum{Key: "beta", Value: "1|true"},
+								To:       api.Metadatum{Key: "stage", Value: "beta"},
+							},
+							{
+								Kind:     api.HeaderMatchKind,
+								Behavior: api.RangeMatchBehavior,
+								From:     api.Metadatum{Key: "X-Shard", Value: "[0,10)"},
+							},
+						},
+						Constraints: api.AllConstraints{
+							Light: api.ClusterConstraints{
+								{
+									ConstraintKey: "b",
+									ClusterKey:    "api",
+									Metadata:      api.Metadata{{Key: "stage", Value: "prod"}},
+									Weight:        1,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Clusters: api.Clusters{
+			{
+				ClusterKey: "web",
+				Name:       "web",
+				RequireTLS: true,
+				Instances:  api.Instances{{Host: "web-1.internal", Port: 443}},
+			},
+			{
+				ClusterKey: "api",
+				Name:       "api",
+				Instances: api.Instances{
+					{
+						Host:     "10.0.0.1",
+						Port:     8080,
+						Metadata: api.Metadata{{Key: "stage", Value: "prod"}, {Key: "version", Value: "1"}},
+					},
+					{
+						Host:     "10.0.0.2",
+						Port:     8080,
+						Metadata: api.Metadata{{Key: "stage", Value: "canary"}, {Key: "version", Value: "2"}},
+					},
+				},
+				HealthChecks: api.HealthChecks{
+					{
+						TimeoutMsec:        1000,
+						IntervalMsec:       15000,
+						IntervalJitterMsec: intPtr(250),
+						UnhealthyThreshold: 3,
+						HealthyThreshold:   2,
+						ReuseConnection:    &reuse,
+						HealthChecker: api.HealthChecker{
+							HTTPHealthCheck: &api.HTTPHealthCheck{
+								Path:                "/health",
+								RequestHeadersToAdd: api.Metadata{{Key: "x-health", Value: "1"}},
+							},
+						},
+					},
+					{
+						TimeoutMsec:        500,
+						IntervalMsec:       5000,
+						UnhealthyThreshold: 1,
+						HealthyThreshold:   1,
+						HealthChecker: api.HealthChecker{
+							TCPHealthCheck: &api.TCPHealthCheck{
+								Send:    "cGluZw==",
+								Receive: []string{"cG9uZw=="},
+							},
+						},
+					},
+				},
+				OutlierDetection: &api.OutlierDetection{
+					IntervalMsec:   intPtr(10000),
+					Consecutive5xx: intPtr(5),
+				},
+				CircuitBreakers: &api.CircuitBreakers{
+					MaxConnections: intPtr(1024),
+					MaxRetries:     intPtr(3),
+				},
+			},
+			{
+				ClusterKey: "mirror",
+				Name:       "mirror",
+			},
+		},
+	}
+}
+
+func checkGolden(t *testing.T, name string, v interface{}) {
+	got, err := json.MarshalIndent(v, "", "  ")
+	assert.Nil(t, err)
+	got = append(got, '\n')
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		assert.Nil(t, ioutil.WriteFile(path, got, 0644))
+	}
+
+	want, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, string(got), string(want))
+}
+
+func TestGenerate(t *testing.T) {
+	resources, err := Generate(testObjects())
+	assert.Nil(t, err)
+	checkGolden(t, "resources.json", resources)
+}
+
+func TestBootstrap(t *testing.T) {
+	resources, err := Generate(testObjects())
+	assert.Nil(t, err)
+	checkGolden(t, "bootstrap.json", resources.Bootstrap())
+
+	// the resources are not modified
+	assert.NonNil(t, resources.Listeners[0].FilterChains[0].Filters[0].Config.RDS)
+	assert.Nil(t, resources.Listeners[0].FilterChains[0].Filters[0].Config.RouteConfig)
+}
+
+func TestGenerateEmptyProxy(t *testing.T) {
+	resources, err := Generate(Objects{Proxy: api.Proxy{ProxyKey: "pk"}})
+	assert.Nil(t, err)
+	assert.Equal(t, len(resources.Listeners), 0)
+	assert.Equal(t, len(resources.RouteConfigurations), 0)
+	assert.Equal(t, len(resources.Clusters), 0)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(*Objects)
+		err    string
+	}{
+		{
+			name:   "missing domain",
+			modify: func(o *Objects) { o.Proxy.DomainKeys = append(o.Proxy.DomainKeys, "nope") },
+			err:    `domain "nope" does not exist`,
+		},
+		{
+			name:   "missing listener",
+			modify: func(o *Objects) { o.Proxy.ListenerKeys = append(o.Proxy.ListenerKeys, "nope") },
+			err:    `listener "nope" does not exist`,
+		},
+		{
+			name:   "tcp listener",
+			modify: func(o *Objects) { o.Listeners[0].Protocol = api.TCPListenerProtocol },
+			err:    `listener "api-listener": protocol "tcp" is not supported`,
+		},
+		{
+			name:   "missing shared rules",
+			modify: func(o *Objects) { o.Routes[0].SharedRulesKey = "nope" },
+			err:    `route "main-root": shared rules "nope" does not exist`,
+		},
+		{
+			name: "missing cluster",
+			modify: func(o *Objects) {
+				o.SharedRules[0].Default.Light[0].ClusterKey = "nope"
+			},
+			err: `route "main-root": shared rules "web-rules" default: cluster "nope" does not exist`,
+		},
+		{
+			name: "derived constraint",
+			modify: func(o *Objects) {
+				o.SharedRules[1].Rules[0].Matches[0].To.Value = ""
+			},
+			err: `rule "beta": query match "beta": constraints derived from request values are not supported`,
+		},
+		{
+			name: "range cookie match",
+			modify: func(o *Objects) {
+				o.Routes[1].Rules[0].Matches[1].Behavior = api.RangeMatchBehavior
+			},
+			err: `match behavior "range" is not supported for cookies`,
+		},
+		{
+			name: "source ip match",
+			modify: func(o *Objects) {
+				o.Routes[1].Rules[0].Matches[1] = api.Match{
+					Kind:     api.SourceIPMatchKind,
+					Behavior: api.ExactMatchBehavior,
+					From:     api.Metadatum{Value: "10.0.0.0/8"},
+				}
+			},
+			err: `match kind "source_ip" is not supported`,
+		},
+		{
+			name: "route cohort seed",
+			modify: func(o *Objects) {
+				o.Routes[1].CohortSeed = &api.CohortSeed{Type: api.CohortSeedCookie, Name: "user"}
+			},
+			err: `route "main-api": cohort seeds are not supported`,
+		},
+		{
+			name: "shared rules cohort seed",
+			modify: func(o *Objects) {
+				o.SharedRules[0].CohortSeed = &api.CohortSeed{Type: api.CohortSeedHeader, Name: "x-user"}
+			},
+			err: `route "main-root": shared rules "web-rules": cohort seeds are not supported`,
+		},
+		{
+			name: "rule cohort seed",
+			modify: func(o *Objects) {
+				o.SharedRules[1].Rules[0].CohortSeed = &api.CohortSeed{Type: api.CohortSeedQuery, Name: "u"}
+			},
+			err: `rule "beta": cohort seeds are not supported`,
+		},
+		{
+			name: "request data cookie",
+			modify: func(o *Objects) {
+				o.Routes[1].RequestData.Headers[1].Value = "cookie:session"
+			},
+			err: `route "main-api": request header "X-Original-Host": cookie attributes cannot be expressed`,
+		},
+		{
+			name: "shared rules request data query",
+			modify: func(o *Objects) {
+				o.SharedRules[1].RequestData.Headers[0] = api.RequestHeaderDatum{
+					Name:   "X-Env",
+					Action: api.AddHeaderAction,
+					Value:  "query:env",
+				}
+			},
+			err: `shared rules "api-rules": request header "X-Env": query attributes cannot be expressed`,
+		},
+		{
+			name: "constraint request data query",
+			modify: func(o *Objects) {
+				o.SharedRules[1].Default.Light[1].RequestData.Headers[0].ValueIsLiteral = false
+				o.SharedRules[1].Default.Light[1].RequestData.Headers[0].Value = "query:env"
+			},
+			err: `constraint "n": request header "x-env": query attributes cannot be expressed`,
+		},
+		{
+			name: "rule fault injection",
+			modify: func(o *Objects) {
+				o.Routes[1].Rules[0].FaultInjection = &api.FaultInjection{
+					Abort: &api.FaultAbort{HTTPStatus: 503, Percent: 1},
+				}
+			},
+			err: `route "main-api": rule "canary": fault injection is not supported`,
+		},
+		{
+			name: "shared rules fault injection",
+			modify: func(o *Objects) {
+				o.SharedRules[0].FaultInjection = &api.FaultInjection{
+					Delay: &api.FaultDelay{DelayMsec: 500, Percent: 10},
+				}
+			},
+			err: `route "main-root": shared rules "web-rules" default: fault injection is not supported`,
+		},
+		{
+			name: "rule rate limit policy",
+			modify: func(o *Objects) {
+				o.Routes[1].Rules[0].RateLimitPolicy = &api.RateLimitPolicy{}
+			},
+			err: `route "main-api": rule "canary": rate limiting is not supported`,
+		},
+		{
+			name: "domain rate limit policy",
+			modify: func(o *Objects) {
+				o.Domains[2].RateLimitPolicy = &api.RateLimitPolicy{}
+			},
+			err: `route "api-root": rule "beta": rate limiting is not supported`,
+		},
+		{
+			name:   "redirect capture group",
+			modify: func(o *Objects) { o.Domains[0].Redirects[1].To = "http://$host/$1/x" },
+			err:    `domain "example.com": redirect "legacy": $1 cannot be expressed`,
+		},
+		{
+			name:   "redirect from not a prefix",
+			modify: func(o *Objects) { o.Domains[0].Redirects[0].From = "/d[a-z]cs/(.*)" },
+			err:    `redirect "docs": $1 requires from to be a literal prefix followed by (.*)`,
+		},
+		{
+			name: "ssl protocols",
+			modify: func(o *Objects) {
+				o.Domains[1].SSLConfig.Protocols = []api.SSLProtocol{api.SSL3}
+			},
+			err: `domain "secure.example.com": no supported TLS protocol in [SSLv3]`,
+		},
+		{
+			name: "health check payload",
+			modify: func(o *Objects) {
+				o.Clusters[1].HealthChecks[1].HealthChecker.TCPHealthCheck.Send = "!"
+			},
+			err: `cluster "api": health_checks[1]: send: illegal base64 data`,
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Group(
+			tc.name,
+			t,
+			func(g *assert.G) {
+				o := testObjects()
+				tc.modify(&o)
+				resources, err := Generate(o)
+				assert.Nil(g, resources)
+				assert.ErrorContains(g, err, tc.err)
+			},
+		)
+	}
+}
+
+func TestRuleMatchKeylessKinds(t *testing.T) {
+	r := api.Rule{
+		Methods: []string{"PATCH"},
+		Matches: api.Matches{
+			{
+				Kind:     api.PathMatchKind,
+				Behavior: api.SuffixMatchBehavior,
+				From:     api.Metadatum{Value: ".json"},
+				To:       api.Metadatum{Key: "format", Value: "json"},
+			},
+			{
+				Kind:     api.HostMatchKind,
+				Behavior: api.PrefixMatchBehavior,
+				From:     api.Metadatum{Value: "api."},
+			},
+			{
+				Kind:     api.SchemeMatchKind,
+				Behavior: api.ExactMatchBehavior,
+				From:     api.Metadatum{Value: "HTTPS"},
+			},
+		},
+	}
+
+	match, derived, err := ruleMatch("/", r)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, derived, api.Metadata{{Key: "format", Value: "json"}})
+	assert.DeepEqual(t, match.Headers, []HeaderMatcher{
+		{Name: ":method", ExactMatch: "PATCH"},
+		{Name: ":path", SafeRegexMatch: regexMatcher(`[^?]*\.json(\?.*)?`)},
+		{Name: ":authority", PrefixMatch: "api."},
+		{Name: "x-forwarded-proto", ExactMatch: "https"},
+	})
+}
+
+func TestLiteralPrefix(t *testing.T) {
+	testCases := []struct {
+		from   string
+		prefix string
+		ok     bool
+	}{
+		{"(.*)", "", true},
+		{"^/docs/(.*)$", "/docs/", true},
+		{`/v1\.0/(.*)`, "/v1.0/", true},
+		{"/v1.0/(.*)", "", false},
+		{"/docs/(.+)", "", false},
+		{"/d(o)cs/(.*)", "", false},
+	}
+
+	for _, tc := range testCases {
+		assert.Group(
+			tc.from,
+			t,
+			func(g *assert.G) {
+				prefix, ok := literalPrefix(tc.from)
+				assert.Equal(g, prefix, tc.prefix)
+				assert.Equal(g, ok, tc.ok)
+			},
+		)
+	}
+}
+
+func TestRedirectCatchAll(t *testing.T) {
+	route, err := redirect(
+		api.Redirect{
+			Name:         "force-https",
+			From:         "(.*)",
+			To:           "https://$host$1",
+			RedirectType: api.PermanentRedirect,
+		},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, *route.Match.Prefix, "/")
+	assert.DeepEqual(
+		t,
+		route.Redirect,
+		&RedirectAction{HTTPSRedirect: true, ResponseCode: "MOVED_PERMANENTLY"},
+	)
+}
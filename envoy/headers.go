@@ -0,0 +1,225 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envoy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/turbinelabs/api"
+)
+
+const setCookieHeader = "Set-Cookie"
+
+// requestVariables are the Envoy header variables for the keyless request
+// attributes a RequestHeaderDatum may refer to.
+var requestVariables = map[api.MatchKind]string{
+	api.PathMatchKind:     "%REQ(:path)%",
+	api.HostMatchKind:     "%REQ(:authority)%",
+	api.SchemeMatchKind:   "%REQ(x-forwarded-proto)%",
+	api.SourceIPMatchKind: "%DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT%",
+}
+
+// splitResponseData divides the ResponseData of a SharedRules and Route,
+// already merged in that order, between an Envoy route and its weighted
+// clusters. Envoy applies the headers of a weighted cluster before those of
+// its route, so a header or cookie overridden by any of the light
+// ClusterConstraints moves from the route to each weighted cluster, where it
+// is merged with that ClusterConstraint's ResponseData.
+func splitResponseData(
+	rd api.ResponseData,
+	light api.ClusterConstraints,
+) (api.ResponseData, []api.ResponseData) {
+	headers := map[string]bool{}
+	cookies := map[string]bool{}
+	for _, cc := range light {
+		for _, h := range cc.ResponseData.Headers {
+			headers[h.CanonicalName()] = true
+		}
+		for _, c := range cc.ResponseData.Cookies {
+			cookies[c.Name] = true
+		}
+	}
+
+	route := api.ResponseData{}
+	shared := api.ResponseData{}
+	for _, h := range rd.Headers {
+		if headers[h.CanonicalName()] {
+			shared.Headers = append(shared.Headers, h)
+		} else {
+			route.Headers = append(route.Headers, h)
+		}
+	}
+	for _, c := range rd.Cookies {
+		if cookies[c.Name] {
+			shared.Cookies = append(shared.Cookies, c)
+		} else {
+			route.Cookies = append(route.Cookies, c)
+		}
+	}
+
+	clusters := make([]api.ResponseData, len(light))
+	for i, cc := range light {
+		clusters[i] = shared.MergeFrom(cc.ResponseData)
+	}
+
+	return route, clusters
+}
+
+// responseHeaders translates ResponseData. Headers replace any value set
+// by the upstream; each cookie becomes an additional Set-Cookie header.
+func responseHeaders(rd api.ResponseData) []HeaderValueOption {
+	if rd.Len() == 0 {
+		return nil
+	}
+
+	result := make([]HeaderValueOption, 0, rd.Len())
+	for _, h := range rd.Headers {
+		result = append(
+			result,
+			HeaderValueOption{
+				Header: HeaderValue{Key: h.Name, Value: responseValue(h.ResponseDatum)},
+				Append: boolPtr(false),
+			},
+		)
+	}
+
+	for _, c := range rd.Cookies {
+		value := c.Name + "=" + responseValue(c.ResponseDatum)
+		if annotation := c.Annotation(); annotation != "" {
+			value += "; " + annotation
+		}
+		result = append(
+			result,
+			HeaderValueOption{
+				Header: HeaderValue{Key: setCookieHeader, Value: value},
+				Append: boolPtr(true),
+			},
+		)
+	}
+
+	return result
+}
+
+// responseValue returns an Envoy header value for a ResponseDatum. A value
+// that is not literal refers to the metadata of the Instance handling the
+// request, which is available to Envoy as upstream host metadata.
+func responseValue(d api.ResponseDatum) string {
+	if d.ValueIsLiteral {
+		return escapeHeaderValue(d.Value)
+	}
+	return fmt.Sprintf(`%%UPSTREAM_METADATA(["%s", %q])%%`, lbFilter, d.Value)
+}
+
+// escapeHeaderValue escapes the '%' characters Envoy uses to delimit
+// variables in custom header values.
+func escapeHeaderValue(s string) string {
+	return strings.Replace(s, "%", "%%", -1)
+}
+
+// splitRequestData divides the RequestData of a SharedRules and Route,
+// already merged in that order, between an Envoy route and its weighted
+// clusters, in the same way as splitResponseData.
+func splitRequestData(
+	rd api.RequestData,
+	light api.ClusterConstraints,
+) (api.RequestData, []api.RequestData) {
+	headers := map[string]bool{}
+	for _, cc := range light {
+		for _, h := range cc.RequestData.Headers {
+			headers[h.CanonicalName()] = true
+		}
+	}
+
+	route := api.RequestData{}
+	shared := api.RequestData{}
+	for _, h := range rd.Headers {
+		if headers[h.CanonicalName()] {
+			shared.Headers = append(shared.Headers, h)
+		} else {
+			route.Headers = append(route.Headers, h)
+		}
+	}
+
+	clusters := make([]api.RequestData, len(light))
+	for i, cc := range light {
+		clusters[i] = shared.MergeFrom(cc.RequestData)
+	}
+
+	return route, clusters
+}
+
+// requestHeaders translates RequestData into the headers to add to and
+// remove from a request. Overwritten headers replace any value already
+// present.
+func requestHeaders(rd api.RequestData) ([]HeaderValueOption, []string, error) {
+	var (
+		toAdd    []HeaderValueOption
+		toRemove []string
+	)
+
+	for _, h := range rd.Headers {
+		if h.Action == api.RemoveHeaderAction {
+			toRemove = append(toRemove, h.CanonicalName())
+			continue
+		}
+
+		value, err := requestValue(h)
+		if err != nil {
+			return nil, nil, fmt.Errorf("request header %q: %v", h.Name, err)
+		}
+		toAdd = append(
+			toAdd,
+			HeaderValueOption{
+				Header: HeaderValue{Key: h.Name, Value: value},
+				Append: boolPtr(h.Action == api.AddHeaderAction),
+			},
+		)
+	}
+
+	return toAdd, toRemove, nil
+}
+
+// requestValue returns an Envoy header value for a RequestHeaderDatum.
+// Envoy has no header variables for cookies or query parameters.
+func requestValue(h api.RequestHeaderDatum) (string, error) {
+	if h.ValueIsLiteral {
+		return escapeHeaderValue(h.Value), nil
+	}
+
+	kind, key, err := h.Attribute()
+	if err != nil {
+		return "", err
+	}
+
+	if kind == api.HeaderMatchKind {
+		name := strings.ToLower(key)
+		if name == "host" {
+			// Envoy stores the Host header as :authority
+			return requestVariables[api.HostMatchKind], nil
+		}
+		return "%REQ(" + name + ")%", nil
+	}
+	if v, ok := requestVariables[kind]; ok {
+		return v, nil
+	}
+	return "", fmt.Errorf("%s attributes cannot be expressed", kind)
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envoy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/turbinelabs/api"
+)
+
+var tlsVersions = []struct {
+	protocol api.SSLProtocol
+	version  string
+}{
+	{api.TLS1, "TLSv1_0"},
+	{api.TLS1_1, "TLSv1_1"},
+	{api.TLS1_2, "TLSv1_2"},
+}
+
+// listener translates a Listener serving the given Domains. Domains with an
+// SSLConfig are served by a filter chain matching their names; the
+// remaining Domains share a filter chain without TLS.
+func (g *generator) listener(l api.Listener, domains []api.Domain) (Listener, error) {
+	codecType, ok := codecTypes[l.Protocol]
+	if !ok {
+		return Listener{}, fmt.Errorf(
+			"listener %q: protocol %q is not supported",
+			l.Name,
+			l.Protocol,
+		)
+	}
+
+	hcm := &HTTPConnectionManager{
+		StatPrefix: l.Name,
+		CodecType:  codecType,
+		RDS:        &RDS{RouteConfigName: l.Name},
+		Tracing:    tracing(l.TracingConfig),
+	}
+
+	cors, gzip := false, false
+	for _, d := range domains {
+		cors = cors || d.CorsConfig != nil
+		gzip = gzip || d.GzipEnabled
+	}
+	if cors {
+		hcm.HTTPFilters = append(hcm.HTTPFilters, HTTPFilter{Name: "envoy.cors"})
+	}
+	if gzip {
+		hcm.HTTPFilters = append(hcm.HTTPFilters, HTTPFilter{Name: "envoy.gzip"})
+	}
+	hcm.HTTPFilters = append(hcm.HTTPFilters, HTTPFilter{Name: "envoy.router"})
+
+	filters := []Filter{{Name: "envoy.http_connection_manager", Config: hcm}}
+
+	chains := []FilterChain{}
+	plain := false
+	for _, d := range domains {
+		if d.SSLConfig == nil {
+			plain = true
+			continue
+		}
+
+		tls, err := downstreamTLSContext(*d.SSLConfig)
+		if err != nil {
+			return Listener{}, fmt.Errorf("domain %q: %v", d.Name, err)
+		}
+
+		chains = append(
+			chains,
+			FilterChain{
+				FilterChainMatch: &FilterChainMatch{ServerNames: serverNames(d)},
+				TLSContext:       tls,
+				Filters:          filters,
+			},
+		)
+	}
+	if plain || len(chains) == 0 {
+		chains = append(chains, FilterChain{Filters: filters})
+	}
+
+	return Listener{
+		Name: l.Name,
+		Address: Address{
+			SocketAddress: SocketAddress{Address: l.IP, PortValue: l.Port},
+		},
+		FilterChains: chains,
+	}, nil
+}
+
+func tracing(tc *api.TracingConfig) *Tracing {
+	if tc == nil {
+		return nil
+	}
+
+	t := &Tracing{
+		OperationName:         "EGRESS",
+		RequestHeadersForTags: tc.RequestHeadersForTags,
+	}
+	if tc.Ingress {
+		t.OperationName = "INGRESS"
+	}
+	return t
+}
+
+// serverNames returns the names for which a Domain's certificate is served.
+// Aliases with a trailing wildcard cannot be matched by SNI and are omitted.
+func serverNames(d api.Domain) []string {
+	names := []string{d.Name}
+	for _, a := range d.Aliases {
+		if !strings.HasSuffix(string(a), ".*") {
+			names = append(names, string(a))
+		}
+	}
+	return names
+}
+
+func downstreamTLSContext(c api.SSLConfig) (*DownstreamTLSContext, error) {
+	protocols := c.Protocols
+	if len(protocols) == 0 {
+		protocols = api.DefaultProtocols
+	}
+
+	enabled := map[api.SSLProtocol]bool{}
+	for _, p := range protocols {
+		enabled[p] = true
+	}
+
+	params := &TLSParameters{}
+	for _, v := range tlsVersions {
+		if !enabled[v.protocol] {
+			continue
+		}
+		if params.TLSMinimumProtocolVersion == "" {
+			params.TLSMinimumProtocolVersion = v.version
+		}
+		params.TLSMaximumProtocolVersion = v.version
+	}
+	if params.TLSMinimumProtocolVersion == "" {
+		return nil, fmt.Errorf("no supported TLS protocol in %v", protocols)
+	}
+
+	cipherFilter := strings.TrimSpace(c.CipherFilter)
+	if cipherFilter == "" {
+		cipherFilter = api.DefaultCipherFilter
+	}
+	params.CipherSuites = strings.Split(cipherFilter, ":")
+
+	certs := make([]TLSCertificate, len(c.CertKeyPairs))
+	for i, pair := range c.CertKeyPairs {
+		certs[i] = TLSCertificate{
+			CertificateChain: DataSource{Filename: pair.CertificatePath},
+			PrivateKey:       DataSource{Filename: pair.KeyPath},
+		}
+	}
+
+	return &DownstreamTLSContext{
+		CommonTLSContext: CommonTLSContext{TLSParams: params, TLSCertificates: certs},
+	}, nil
+}
@@ -0,0 +1,660 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envoy
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/turbinelabs/api"
+)
+
+const retryOn = "5xx,connect-failure,refused-stream"
+
+var (
+	responseCodes = map[api.RedirectType]string{
+		api.PermanentRedirect: "MOVED_PERMANENTLY",
+		api.TemporaryRedirect: "FOUND",
+	}
+
+	redirectVariable = regexp.MustCompile(`\$([0-9]+|host)`)
+)
+
+// virtualHost translates a Domain, its Redirects and its Routes.
+func (g *generator) virtualHost(d api.Domain) (VirtualHost, error) {
+	vh := VirtualHost{
+		Name:    d.Name + ":" + strconv.Itoa(d.Port),
+		Domains: hostNames(d),
+		Routes:  []Route{},
+		Cors:    corsPolicy(d.CorsConfig),
+	}
+	if d.ForceHTTPS {
+		vh.RequireTLS = "ALL"
+	}
+
+	for _, r := range d.Redirects {
+		route, err := redirect(r)
+		if err != nil {
+			return VirtualHost{}, fmt.Errorf("domain %q: %v", d.Name, err)
+		}
+		vh.Routes = append(vh.Routes, route)
+	}
+
+	// Envoy uses the first matching route, so longer paths come first
+	routes := append(api.Routes{}, g.routes[d.DomainKey]...)
+	sort.SliceStable(routes, func(i, j int) bool {
+		return len(routes[i].Path) > len(routes[j].Path)
+	})
+
+	for _, r := range routes {
+		rs, err := g.routeEntries(d, r)
+		if err != nil {
+			return VirtualHost{}, fmt.Errorf("route %q: %v", r.RouteKey, err)
+		}
+		vh.Routes = append(vh.Routes, rs...)
+	}
+
+	return vh, nil
+}
+
+// hostNames returns the Host header values answered by a Domain: its name
+// and Aliases, with and without its port.
+func hostNames(d api.Domain) []string {
+	port := ":" + strconv.Itoa(d.Port)
+	names := []string{d.Name, d.Name + port}
+	for _, a := range d.Aliases {
+		names = append(names, string(a), string(a)+port)
+	}
+	return names
+}
+
+func corsPolicy(c *api.CorsConfig) *CorsPolicy {
+	if c == nil {
+		return nil
+	}
+
+	p := &CorsPolicy{
+		AllowOrigin:      c.AllowedOrigins,
+		AllowMethods:     strings.Join(c.AllowedMethods, ","),
+		AllowHeaders:     strings.Join(c.AllowedHeaders, ","),
+		ExposeHeaders:    strings.Join(c.ExposedHeaders, ","),
+		AllowCredentials: c.AllowCredentials,
+	}
+	if c.MaxAge > 0 {
+		p.MaxAge = strconv.Itoa(c.MaxAge)
+	}
+	return p
+}
+
+// redirect translates a Redirect. A Redirect whose From is a literal prefix
+// followed by "(.*)" and whose To ends in "$1" becomes a prefix rewrite;
+// any other use of capture groups cannot be expressed.
+func redirect(r api.Redirect) (Route, error) {
+	to := r.To
+	match := RouteMatch{}
+	action := &RedirectAction{ResponseCode: responseCodes[r.RedirectType]}
+
+	rewrite := strings.HasSuffix(to, "$1")
+	if rewrite {
+		prefix, ok := literalPrefix(r.From)
+		if !ok {
+			return Route{}, fmt.Errorf(
+				"redirect %q: $1 requires from to be a literal prefix followed by (.*)",
+				r.Name,
+			)
+		}
+		to = strings.TrimSuffix(to, "$1")
+		match.Prefix = &prefix
+	} else {
+		match.SafeRegex = regexMatcher(r.From)
+	}
+
+	u, err := url.Parse(to)
+	if err != nil {
+		return Route{}, fmt.Errorf("redirect %q: %v", r.Name, err)
+	}
+
+	host := u.Host
+	if host == "$host" {
+		host = ""
+	}
+	if m := redirectVariable.FindString(host + u.Path + u.RawQuery); m != "" {
+		return Route{}, fmt.Errorf("redirect %q: %s cannot be expressed", r.Name, m)
+	}
+
+	switch u.Scheme {
+	case "":
+	case "https":
+		action.HTTPSRedirect = true
+	default:
+		action.SchemeRedirect = u.Scheme
+	}
+	action.HostRedirect = host
+
+	path := u.Path
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	if rewrite {
+		if *match.Prefix == "" {
+			// "(.*)" captures the leading slash
+			*match.Prefix = "/"
+			if path != "" {
+				path += "/"
+			}
+		}
+		action.PrefixRewrite = path
+	} else {
+		action.PathRedirect = path
+	}
+
+	for _, hc := range r.HeaderConstraints {
+		value := hc.Value
+		if !hc.CaseSensitive {
+			value = "(?i)" + value
+		}
+		match.Headers = append(
+			match.Headers,
+			HeaderMatcher{
+				Name:           strings.ToLower(hc.Name),
+				SafeRegexMatch: regexMatcher(value),
+				InvertMatch:    hc.Invert,
+			},
+		)
+	}
+
+	return Route{Match: match, Redirect: action}, nil
+}
+
+// literalPrefix returns the literal prefix of a regular expression of the
+// form "prefix(.*)", optionally anchored.
+func literalPrefix(from string) (string, bool) {
+	s := strings.TrimSuffix(strings.TrimPrefix(from, "^"), "$")
+	if !strings.HasSuffix(s, "(.*)") {
+		return "", false
+	}
+	s = strings.TrimSuffix(s, "(.*)")
+
+	// a capture group in the prefix would be referenced by $1
+	re, err := regexp.Compile(s)
+	if err != nil || re.NumSubexp() > 0 {
+		return "", false
+	}
+	prefix, complete := re.LiteralPrefix()
+	if !complete {
+		return "", false
+	}
+	return prefix, true
+}
+
+// routeData is the configuration shared by the Envoy routes generated for a
+// Route.
+type routeData struct {
+	retryPolicy *api.RetryPolicy
+	request     api.RequestData
+	response    api.ResponseData
+}
+
+// routeEntries translates a Route into an Envoy route for each of its Rules
+// and its SharedRules' Rules, in order, followed by one for the SharedRules'
+// Default.
+func (g *generator) routeEntries(d api.Domain, r api.Route) ([]Route, error) {
+	sr, ok := g.sharedRules[r.SharedRulesKey]
+	if !ok {
+		return nil, fmt.Errorf("shared rules %q does not exist", r.SharedRulesKey)
+	}
+
+	// Envoy cannot assign requests to cohorts
+	if sr.CohortSeed != nil {
+		return nil, fmt.Errorf("shared rules %q: cohort seeds are not supported", sr.SharedRulesKey)
+	}
+	if r.CohortSeed != nil {
+		return nil, fmt.Errorf("cohort seeds are not supported")
+	}
+
+	if _, _, err := requestHeaders(sr.RequestData); err != nil {
+		return nil, fmt.Errorf("shared rules %q: %v", sr.SharedRulesKey, err)
+	}
+	if _, _, err := requestHeaders(r.RequestData); err != nil {
+		return nil, err
+	}
+
+	data := routeData{
+		retryPolicy: r.RetryPolicy,
+		request:     sr.RequestData.MergeFrom(r.RequestData),
+		response:    sr.ResponseData.MergeFrom(r.ResponseData),
+	}
+	if data.retryPolicy == nil {
+		data.retryPolicy = sr.RetryPolicy
+	}
+
+	rules := append(append(api.Rules{}, r.Rules...), sr.Rules...)
+	result := make([]Route, 0, len(rules)+1)
+	for _, rule := range rules {
+		if rule.CohortSeed != nil {
+			return nil, fmt.Errorf("rule %q: cohort seeds are not supported", rule.RuleKey)
+		}
+
+		if api.FaultInjectionFor(sr, r, &rule) != nil {
+			return nil, fmt.Errorf("rule %q: fault injection is not supported", rule.RuleKey)
+		}
+		if api.RateLimitPolicyFor(d, r, &rule) != nil {
+			return nil, fmt.Errorf("rule %q: rate limiting is not supported", rule.RuleKey)
+		}
+
+		match, derived, err := ruleMatch(r.Path, rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %v", rule.RuleKey, err)
+		}
+
+		route, err := g.route(match, rule.Constraints, derived, data)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %v", rule.RuleKey, err)
+		}
+
+		result = append(result, route)
+	}
+
+	if api.FaultInjectionFor(sr, r, nil) != nil {
+		return nil, fmt.Errorf(
+			"shared rules %q default: fault injection is not supported",
+			sr.SharedRulesKey,
+		)
+	}
+	if api.RateLimitPolicyFor(d, r, nil) != nil {
+		return nil, fmt.Errorf(
+			"shared rules %q default: rate limiting is not supported",
+			sr.SharedRulesKey,
+		)
+	}
+
+	path := r.Path
+	route, err := g.route(RouteMatch{Prefix: &path}, sr.Default, nil, data)
+	if err != nil {
+		return nil, fmt.Errorf("shared rules %q default: %v", sr.SharedRulesKey, err)
+	}
+	result = append(result, route)
+
+	return result, nil
+}
+
+// ruleMatch translates a Rule's Methods and Matches, returning the metadata
+// constraints derived from the Matches.
+func ruleMatch(path string, r api.Rule) (RouteMatch, api.Metadata, error) {
+	match := RouteMatch{Prefix: &path}
+
+	switch len(r.Methods) {
+	case 0:
+	case 1:
+		match.Headers = append(match.Headers, HeaderMatcher{Name: ":method", ExactMatch: r.Methods[0]})
+	default:
+		quoted := make([]string, len(r.Methods))
+		for i, m := range r.Methods {
+			quoted[i] = regexp.QuoteMeta(m)
+		}
+		match.Headers = append(
+			match.Headers,
+			HeaderMatcher{Name: ":method", SafeRegexMatch: regexMatcher(strings.Join(quoted, "|"))},
+		)
+	}
+
+	var derived api.Metadata
+	for _, m := range r.Matches {
+		switch m.Kind {
+		case api.HeaderMatchKind:
+			hm, err := headerMatcher(m)
+			if err != nil {
+				return RouteMatch{}, nil, err
+			}
+			match.Headers = append(match.Headers, hm)
+
+		case api.CookieMatchKind:
+			hm, err := cookieMatcher(m)
+			if err != nil {
+				return RouteMatch{}, nil, err
+			}
+			match.Headers = append(match.Headers, hm)
+
+		case api.QueryMatchKind:
+			qm, err := queryParameterMatcher(m)
+			if err != nil {
+				return RouteMatch{}, nil, err
+			}
+			match.QueryParameters = append(match.QueryParameters, qm)
+
+		case api.PathMatchKind:
+			hm, err := pathMatcher(m)
+			if err != nil {
+				return RouteMatch{}, nil, err
+			}
+			match.Headers = append(match.Headers, hm)
+
+		case api.HostMatchKind:
+			hm, err := headerMatcher(m)
+			if err != nil {
+				return RouteMatch{}, nil, err
+			}
+			hm.Name = ":authority"
+			match.Headers = append(match.Headers, hm)
+
+		case api.SchemeMatchKind:
+			match.Headers = append(
+				match.Headers,
+				HeaderMatcher{Name: "x-forwarded-proto", ExactMatch: strings.ToLower(m.From.Value)},
+			)
+
+		default:
+			return RouteMatch{}, nil, fmt.Errorf("match kind %q is not supported", m.Kind)
+		}
+
+		if m.To.Key == "" {
+			continue
+		}
+
+		value := m.To.Value
+		if value == "" {
+			// only an exact match determines the request value in advance
+			if m.Behavior != api.ExactMatchBehavior || m.From.Value == "" {
+				return RouteMatch{}, nil, fmt.Errorf(
+					"%s match %q: constraints derived from request values are not supported",
+					m.Kind,
+					matchName(m),
+				)
+			}
+			value = m.From.Value
+		}
+		derived = append(derived, api.Metadatum{Key: m.To.Key, Value: value})
+	}
+
+	return match, derived, nil
+}
+
+func headerMatcher(m api.Match) (HeaderMatcher, error) {
+	hm := HeaderMatcher{Name: strings.ToLower(m.From.Key)}
+
+	switch m.Behavior {
+	case api.ExactMatchBehavior:
+		if m.From.Value == "" {
+			hm.PresentMatch = true
+		} else {
+			hm.ExactMatch = m.From.Value
+		}
+
+	case api.RegexMatchBehavior:
+		hm.SafeRegexMatch = regexMatcher(m.From.Value)
+
+	case api.PrefixMatchBehavior:
+		hm.PrefixMatch = m.From.Value
+
+	case api.SuffixMatchBehavior:
+		hm.SuffixMatch = m.From.Value
+
+	case api.RangeMatchBehavior:
+		start, end, err := api.ParseRangeBoundaries(m.From.Value)
+		if err != nil {
+			return HeaderMatcher{}, err
+		}
+		hm.RangeMatch = &Int64Range{Start: start, End: end}
+
+	default:
+		return HeaderMatcher{}, fmt.Errorf("match behavior %q is not supported", m.Behavior)
+	}
+
+	return hm, nil
+}
+
+// cookieMatcher translates a cookie Match into a regular expression matching
+// the Cookie header.
+func cookieMatcher(m api.Match) (HeaderMatcher, error) {
+	var value string
+
+	switch m.Behavior {
+	case api.ExactMatchBehavior:
+		if m.From.Value == "" {
+			value = "[^;]*"
+		} else {
+			value = regexp.QuoteMeta(m.From.Value)
+		}
+
+	case api.RegexMatchBehavior:
+		value = "(?:" + m.From.Value + ")"
+
+	case api.PrefixMatchBehavior:
+		value = regexp.QuoteMeta(m.From.Value) + "[^;]*"
+
+	case api.SuffixMatchBehavior:
+		value = "[^;]*" + regexp.QuoteMeta(m.From.Value)
+
+	default:
+		return HeaderMatcher{}, fmt.Errorf(
+			"match behavior %q is not supported for cookies",
+			m.Behavior,
+		)
+	}
+
+	return HeaderMatcher{
+		Name: "cookie",
+		SafeRegexMatch: regexMatcher(
+			`(.*;\s*)?` + regexp.QuoteMeta(m.From.Key) + "=" + value + "(;.*)?",
+		),
+	}, nil
+}
+
+func queryParameterMatcher(m api.Match) (QueryParameterMatcher, error) {
+	qm := QueryParameterMatcher{Name: m.From.Key}
+
+	switch m.Behavior {
+	case api.ExactMatchBehavior:
+		if m.From.Value == "" {
+			qm.PresentMatch = true
+		} else {
+			qm.StringMatch = &StringMatcher{Exact: m.From.Value}
+		}
+
+	case api.RegexMatchBehavior:
+		qm.StringMatch = &StringMatcher{SafeRegex: regexMatcher(m.From.Value)}
+
+	case api.PrefixMatchBehavior:
+		qm.StringMatch = &StringMatcher{Prefix: m.From.Value}
+
+	case api.SuffixMatchBehavior:
+		qm.StringMatch = &StringMatcher{Suffix: m.From.Value}
+
+	default:
+		return QueryParameterMatcher{}, fmt.Errorf(
+			"match behavior %q is not supported for query parameters",
+			m.Behavior,
+		)
+	}
+
+	return qm, nil
+}
+
+// pathMatcher translates a path Match into a regular expression matching
+// the :path header, which includes the query string.
+func pathMatcher(m api.Match) (HeaderMatcher, error) {
+	const query = `(\?.*)?`
+
+	var regex string
+	switch m.Behavior {
+	case api.ExactMatchBehavior:
+		regex = regexp.QuoteMeta(m.From.Value) + query
+
+	case api.RegexMatchBehavior:
+		regex = "(?:" + m.From.Value + ")" + query
+
+	case api.PrefixMatchBehavior:
+		regex = regexp.QuoteMeta(m.From.Value) + ".*"
+
+	case api.SuffixMatchBehavior:
+		regex = `[^?]*` + regexp.QuoteMeta(m.From.Value) + query
+
+	default:
+		return HeaderMatcher{}, fmt.Errorf(
+			"match behavior %q is not supported for paths",
+			m.Behavior,
+		)
+	}
+
+	return HeaderMatcher{Name: ":path", SafeRegexMatch: regexMatcher(regex)}, nil
+}
+
+// matchName returns the From.Key of a Match, or the From.Value of a Match
+// whose kind has no key.
+func matchName(m api.Match) string {
+	if m.From.Key == "" {
+		return m.From.Value
+	}
+	return m.From.Key
+}
+
+func regexMatcher(regex string) *RegexMatcher {
+	return &RegexMatcher{Regex: regex}
+}
+
+// route translates AllConstraints into an Envoy route with the given match.
+// Light ClusterConstraints become weighted clusters, selecting the subset of
+// Instances matching their metadata merged with the derived constraints;
+// Dark and Tap ClusterConstraints become request mirrors.
+func (g *generator) route(
+	match RouteMatch,
+	c api.AllConstraints,
+	derived api.Metadata,
+	data routeData,
+) (Route, error) {
+	if len(c.Light) == 0 {
+		return Route{}, fmt.Errorf("no light cluster constraints")
+	}
+
+	request, clusterRequests := splitRequestData(data.request, c.Light)
+	response, clusterResponses := splitResponseData(data.response, c.Light)
+
+	action := &RouteAction{}
+	for i, cc := range c.Light {
+		cluster, ok := g.clusters[cc.ClusterKey]
+		if !ok {
+			return Route{}, fmt.Errorf("cluster %q does not exist", cc.ClusterKey)
+		}
+
+		if _, _, err := requestHeaders(cc.RequestData); err != nil {
+			return Route{}, fmt.Errorf("constraint %q: %v", cc.ConstraintKey, err)
+		}
+		toAdd, toRemove, err := requestHeaders(clusterRequests[i])
+		if err != nil {
+			return Route{}, err
+		}
+
+		md := mergeMetadata(cc.Metadata, derived)
+		g.addSelector(cc.ClusterKey, md)
+
+		action.WeightedClusters.Clusters = append(
+			action.WeightedClusters.Clusters,
+			ClusterWeight{
+				Name:                   cluster.Name,
+				Weight:                 cc.Weight,
+				MetadataMatch:          lbMetadata(md),
+				RequestHeadersToAdd:    toAdd,
+				RequestHeadersToRemove: toRemove,
+				ResponseHeadersToAdd:   responseHeaders(clusterResponses[i]),
+			},
+		)
+		action.WeightedClusters.TotalWeight += cc.Weight
+	}
+
+	for _, cc := range append(append(api.ClusterConstraints{}, c.Dark...), c.Tap...) {
+		cluster, ok := g.clusters[cc.ClusterKey]
+		if !ok {
+			return Route{}, fmt.Errorf("cluster %q does not exist", cc.ClusterKey)
+		}
+		action.RequestMirrorPolicies = append(
+			action.RequestMirrorPolicies,
+			RequestMirrorPolicy{Cluster: cluster.Name},
+		)
+	}
+
+	if rp := data.retryPolicy; rp != nil {
+		if rp.TimeoutMsec > 0 {
+			action.Timeout = duration(rp.TimeoutMsec)
+		}
+		if rp.NumRetries > 0 {
+			action.RetryPolicy = &RetryPolicy{RetryOn: retryOn, NumRetries: rp.NumRetries}
+			if rp.PerTryTimeoutMsec > 0 {
+				action.RetryPolicy.PerTryTimeout = duration(rp.PerTryTimeoutMsec)
+			}
+		}
+	}
+
+	toAdd, toRemove, err := requestHeaders(request)
+	if err != nil {
+		return Route{}, err
+	}
+
+	return Route{
+		Match:                  match,
+		Route:                  action,
+		RequestHeadersToAdd:    toAdd,
+		RequestHeadersToRemove: toRemove,
+		ResponseHeadersToAdd:   responseHeaders(response),
+	}, nil
+}
+
+// addSelector records that a subset of the Cluster's Instances is selected
+// by the keys of the given metadata.
+func (g *generator) addSelector(ck api.ClusterKey, md api.Metadata) {
+	if len(md) == 0 {
+		return
+	}
+
+	keys := make([]string, len(md))
+	for i, m := range md {
+		keys[i] = m.Key
+	}
+	sort.Strings(keys)
+
+	if g.selectors[ck] == nil {
+		g.selectors[ck] = map[string][]string{}
+	}
+	g.selectors[ck][strings.Join(keys, ",")] = keys
+}
+
+// mergeMetadata returns the union of two Metadata, sorted by key. Values in
+// override take precedence.
+func mergeMetadata(md, override api.Metadata) api.Metadata {
+	if len(md) == 0 && len(override) == 0 {
+		return nil
+	}
+
+	values := md.Map()
+	for k, v := range override.Map() {
+		values[k] = v
+	}
+
+	merged := api.MetadataFromMap(values)
+	sort.Sort(api.MetadataByKey(merged))
+	return merged
+}
+
+func lbMetadata(md api.Metadata) *Metadata {
+	if len(md) == 0 {
+		return nil
+	}
+	return &Metadata{FilterMetadata: map[string]map[string]string{lbFilter: md.Map()}}
+}
@@ -0,0 +1,405 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envoy
+
+// The types in this file mirror the JSON representation of the subset of the
+// Envoy v2 API produced by Generate. Field names follow the proto3 JSON
+// mapping of the corresponding Envoy messages.
+
+// Listener is an envoy.api.v2.Listener.
+type Listener struct {
+	Name         string        `json:"name"`
+	Address      Address       `json:"address"`
+	FilterChains []FilterChain `json:"filter_chains"`
+}
+
+// Address is an envoy.api.v2.core.Address.
+type Address struct {
+	SocketAddress SocketAddress `json:"socket_address"`
+}
+
+// SocketAddress is an envoy.api.v2.core.SocketAddress.
+type SocketAddress struct {
+	Address   string `json:"address"`
+	PortValue int    `json:"port_value"`
+}
+
+// FilterChain is an envoy.api.v2.listener.FilterChain.
+type FilterChain struct {
+	FilterChainMatch *FilterChainMatch     `json:"filter_chain_match,omitempty"`
+	TLSContext       *DownstreamTLSContext `json:"tls_context,omitempty"`
+	Filters          []Filter              `json:"filters"`
+}
+
+// FilterChainMatch is an envoy.api.v2.listener.FilterChainMatch.
+type FilterChainMatch struct {
+	ServerNames []string `json:"server_names,omitempty"`
+}
+
+// Filter is an envoy.api.v2.listener.Filter. Only the HTTP connection manager
+// filter is produced.
+type Filter struct {
+	Name   string                 `json:"name"`
+	Config *HTTPConnectionManager `json:"config"`
+}
+
+// HTTPConnectionManager is the configuration of the envoy.http_connection_manager
+// network filter.
+type HTTPConnectionManager struct {
+	StatPrefix  string              `json:"stat_prefix"`
+	CodecType   string              `json:"codec_type"`
+	RDS         *RDS                `json:"rds,omitempty"`
+	RouteConfig *RouteConfiguration `json:"route_config,omitempty"`
+	HTTPFilters []HTTPFilter        `json:"http_filters"`
+	Tracing     *Tracing            `json:"tracing,omitempty"`
+}
+
+// RDS configures an HTTPConnectionManager to fetch its RouteConfiguration
+// dynamically.
+type RDS struct {
+	RouteConfigName string       `json:"route_config_name"`
+	ConfigSource    ConfigSource `json:"config_source"`
+}
+
+// ConfigSource is an envoy.api.v2.core.ConfigSource. Only aggregated
+// discovery is produced.
+type ConfigSource struct {
+	ADS struct{} `json:"ads"`
+}
+
+// HTTPFilter is an HTTP filter of an HTTPConnectionManager.
+type HTTPFilter struct {
+	Name string `json:"name"`
+}
+
+// Tracing is the tracing configuration of an HTTPConnectionManager.
+type Tracing struct {
+	OperationName         string   `json:"operation_name"`
+	RequestHeadersForTags []string `json:"request_headers_for_tags,omitempty"`
+}
+
+// DownstreamTLSContext is an envoy.api.v2.auth.DownstreamTlsContext.
+type DownstreamTLSContext struct {
+	CommonTLSContext CommonTLSContext `json:"common_tls_context"`
+}
+
+// UpstreamTLSContext is an envoy.api.v2.auth.UpstreamTlsContext.
+type UpstreamTLSContext struct {
+	CommonTLSContext *CommonTLSContext `json:"common_tls_context,omitempty"`
+}
+
+// CommonTLSContext is an envoy.api.v2.auth.CommonTlsContext.
+type CommonTLSContext struct {
+	TLSParams       *TLSParameters   `json:"tls_params,omitempty"`
+	TLSCertificates []TLSCertificate `json:"tls_certificates,omitempty"`
+}
+
+// TLSParameters is an envoy.api.v2.auth.TlsParameters.
+type TLSParameters struct {
+	TLSMinimumProtocolVersion string   `json:"tls_minimum_protocol_version,omitempty"`
+	TLSMaximumProtocolVersion string   `json:"tls_maximum_protocol_version,omitempty"`
+	CipherSuites              []string `json:"cipher_suites,omitempty"`
+}
+
+// TLSCertificate is an envoy.api.v2.auth.TlsCertificate.
+type TLSCertificate struct {
+	CertificateChain DataSource `json:"certificate_chain"`
+	PrivateKey       DataSource `json:"private_key"`
+}
+
+// DataSource is an envoy.api.v2.core.DataSource. Only file sources are
+// produced.
+type DataSource struct {
+	Filename string `json:"filename"`
+}
+
+// RouteConfiguration is an envoy.api.v2.RouteConfiguration.
+type RouteConfiguration struct {
+	Name         string        `json:"name"`
+	VirtualHosts []VirtualHost `json:"virtual_hosts"`
+}
+
+// VirtualHost is an envoy.api.v2.route.VirtualHost.
+type VirtualHost struct {
+	Name       string      `json:"name"`
+	Domains    []string    `json:"domains"`
+	Routes     []Route     `json:"routes"`
+	RequireTLS string      `json:"require_tls,omitempty"`
+	Cors       *CorsPolicy `json:"cors,omitempty"`
+}
+
+// CorsPolicy is an envoy.api.v2.route.CorsPolicy.
+type CorsPolicy struct {
+	AllowOrigin      []string `json:"allow_origin,omitempty"`
+	AllowMethods     string   `json:"allow_methods,omitempty"`
+	AllowHeaders     string   `json:"allow_headers,omitempty"`
+	ExposeHeaders    string   `json:"expose_headers,omitempty"`
+	MaxAge           string   `json:"max_age,omitempty"`
+	AllowCredentials bool     `json:"allow_credentials,omitempty"`
+}
+
+// Route is an envoy.api.v2.route.Route. Exactly one of Route and Redirect
+// is set.
+type Route struct {
+	Match                  RouteMatch          `json:"match"`
+	Route                  *RouteAction        `json:"route,omitempty"`
+	Redirect               *RedirectAction     `json:"redirect,omitempty"`
+	RequestHeadersToAdd    []HeaderValueOption `json:"request_headers_to_add,omitempty"`
+	RequestHeadersToRemove []string            `json:"request_headers_to_remove,omitempty"`
+	ResponseHeadersToAdd   []HeaderValueOption `json:"response_headers_to_add,omitempty"`
+}
+
+// RouteMatch is an envoy.api.v2.route.RouteMatch. Exactly one of Prefix and
+// SafeRegex is set.
+type RouteMatch struct {
+	Prefix          *string                 `json:"prefix,omitempty"`
+	SafeRegex       *RegexMatcher           `json:"safe_regex,omitempty"`
+	Headers         []HeaderMatcher         `json:"headers,omitempty"`
+	QueryParameters []QueryParameterMatcher `json:"query_parameters,omitempty"`
+}
+
+// RegexMatcher is an envoy.type.matcher.RegexMatcher using the RE2 engine.
+type RegexMatcher struct {
+	GoogleRE2 struct{} `json:"google_re2"`
+	Regex     string   `json:"regex"`
+}
+
+// HeaderMatcher is an envoy.api.v2.route.HeaderMatcher. Exactly one match
+// field is set.
+type HeaderMatcher struct {
+	Name           string        `json:"name"`
+	ExactMatch     string        `json:"exact_match,omitempty"`
+	SafeRegexMatch *RegexMatcher `json:"safe_regex_match,omitempty"`
+	RangeMatch     *Int64Range   `json:"range_match,omitempty"`
+	PresentMatch   bool          `json:"present_match,omitempty"`
+	PrefixMatch    string        `json:"prefix_match,omitempty"`
+	SuffixMatch    string        `json:"suffix_match,omitempty"`
+	InvertMatch    bool          `json:"invert_match,omitempty"`
+}
+
+// Int64Range is an envoy.type.Int64Range, inclusive of Start and exclusive
+// of End.
+type Int64Range struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// QueryParameterMatcher is an envoy.api.v2.route.QueryParameterMatcher.
+// Exactly one of StringMatch and PresentMatch is set.
+type QueryParameterMatcher struct {
+	Name         string         `json:"name"`
+	StringMatch  *StringMatcher `json:"string_match,omitempty"`
+	PresentMatch bool           `json:"present_match,omitempty"`
+}
+
+// StringMatcher is an envoy.type.matcher.StringMatcher. Exactly one field
+// is set.
+type StringMatcher struct {
+	Exact     string        `json:"exact,omitempty"`
+	Prefix    string        `json:"prefix,omitempty"`
+	Suffix    string        `json:"suffix,omitempty"`
+	SafeRegex *RegexMatcher `json:"safe_regex,omitempty"`
+}
+
+// RouteAction is an envoy.api.v2.route.RouteAction.
+type RouteAction struct {
+	WeightedClusters      WeightedCluster       `json:"weighted_clusters"`
+	RequestMirrorPolicies []RequestMirrorPolicy `json:"request_mirror_policies,omitempty"`
+	Timeout               string                `json:"timeout,omitempty"`
+	RetryPolicy           *RetryPolicy          `json:"retry_policy,omitempty"`
+}
+
+// WeightedCluster is an envoy.api.v2.route.WeightedCluster.
+type WeightedCluster struct {
+	Clusters    []ClusterWeight `json:"clusters"`
+	TotalWeight uint32          `json:"total_weight"`
+}
+
+// ClusterWeight is an envoy.api.v2.route.WeightedCluster.ClusterWeight. The
+// MetadataMatch selects a subset of the Cluster's endpoints.
+type ClusterWeight struct {
+	Name                   string              `json:"name"`
+	Weight                 uint32              `json:"weight"`
+	MetadataMatch          *Metadata           `json:"metadata_match,omitempty"`
+	RequestHeadersToAdd    []HeaderValueOption `json:"request_headers_to_add,omitempty"`
+	RequestHeadersToRemove []string            `json:"request_headers_to_remove,omitempty"`
+	ResponseHeadersToAdd   []HeaderValueOption `json:"response_headers_to_add,omitempty"`
+}
+
+// RequestMirrorPolicy is an envoy.api.v2.route.RouteAction.RequestMirrorPolicy.
+type RequestMirrorPolicy struct {
+	Cluster string `json:"cluster"`
+}
+
+// RetryPolicy is an envoy.api.v2.route.RetryPolicy.
+type RetryPolicy struct {
+	RetryOn       string `json:"retry_on"`
+	NumRetries    int    `json:"num_retries"`
+	PerTryTimeout string `json:"per_try_timeout,omitempty"`
+}
+
+// RedirectAction is an envoy.api.v2.route.RedirectAction.
+type RedirectAction struct {
+	HTTPSRedirect  bool   `json:"https_redirect,omitempty"`
+	SchemeRedirect string `json:"scheme_redirect,omitempty"`
+	HostRedirect   string `json:"host_redirect,omitempty"`
+	PathRedirect   string `json:"path_redirect,omitempty"`
+	PrefixRewrite  string `json:"prefix_rewrite,omitempty"`
+	ResponseCode   string `json:"response_code"`
+}
+
+// Metadata is an envoy.api.v2.core.Metadata. Subset load balancing uses the
+// "envoy.lb" filter.
+type Metadata struct {
+	FilterMetadata map[string]map[string]string `json:"filter_metadata"`
+}
+
+// Cluster is an envoy.api.v2.Cluster.
+type Cluster struct {
+	Name             string                `json:"name"`
+	Type             string                `json:"type"`
+	ConnectTimeout   string                `json:"connect_timeout"`
+	LoadAssignment   ClusterLoadAssignment `json:"load_assignment"`
+	LbSubsetConfig   *LbSubsetConfig       `json:"lb_subset_config,omitempty"`
+	HealthChecks     []HealthCheck         `json:"health_checks,omitempty"`
+	OutlierDetection *OutlierDetection     `json:"outlier_detection,omitempty"`
+	CircuitBreakers  *CircuitBreakers      `json:"circuit_breakers,omitempty"`
+	TLSContext       *UpstreamTLSContext   `json:"tls_context,omitempty"`
+}
+
+// ClusterLoadAssignment is an envoy.api.v2.ClusterLoadAssignment.
+type ClusterLoadAssignment struct {
+	ClusterName string                `json:"cluster_name"`
+	Endpoints   []LocalityLbEndpoints `json:"endpoints"`
+}
+
+// LocalityLbEndpoints is an envoy.api.v2.endpoint.LocalityLbEndpoints.
+type LocalityLbEndpoints struct {
+	LbEndpoints []LbEndpoint `json:"lb_endpoints"`
+}
+
+// LbEndpoint is an envoy.api.v2.endpoint.LbEndpoint.
+type LbEndpoint struct {
+	Endpoint Endpoint  `json:"endpoint"`
+	Metadata *Metadata `json:"metadata,omitempty"`
+}
+
+// Endpoint is an envoy.api.v2.endpoint.Endpoint.
+type Endpoint struct {
+	Address Address `json:"address"`
+}
+
+// LbSubsetConfig is an envoy.api.v2.Cluster.LbSubsetConfig.
+type LbSubsetConfig struct {
+	FallbackPolicy  string             `json:"fallback_policy"`
+	SubsetSelectors []LbSubsetSelector `json:"subset_selectors"`
+}
+
+// LbSubsetSelector is an envoy.api.v2.Cluster.LbSubsetConfig.LbSubsetSelector.
+type LbSubsetSelector struct {
+	Keys []string `json:"keys"`
+}
+
+// HealthCheck is an envoy.api.v2.core.HealthCheck.
+type HealthCheck struct {
+	Timeout               string           `json:"timeout"`
+	Interval              string           `json:"interval"`
+	IntervalJitter        string           `json:"interval_jitter,omitempty"`
+	UnhealthyThreshold    int              `json:"unhealthy_threshold"`
+	HealthyThreshold      int              `json:"healthy_threshold"`
+	ReuseConnection       *bool            `json:"reuse_connection,omitempty"`
+	NoTrafficInterval     string           `json:"no_traffic_interval,omitempty"`
+	UnhealthyInterval     string           `json:"unhealthy_interval,omitempty"`
+	UnhealthyEdgeInterval string           `json:"unhealthy_edge_interval,omitempty"`
+	HealthyEdgeInterval   string           `json:"healthy_edge_interval,omitempty"`
+	HTTPHealthCheck       *HTTPHealthCheck `json:"http_health_check,omitempty"`
+	TCPHealthCheck        *TCPHealthCheck  `json:"tcp_health_check,omitempty"`
+}
+
+// HTTPHealthCheck is an envoy.api.v2.core.HealthCheck.HttpHealthCheck.
+type HTTPHealthCheck struct {
+	Host                string              `json:"host,omitempty"`
+	Path                string              `json:"path"`
+	ServiceName         string              `json:"service_name,omitempty"`
+	RequestHeadersToAdd []HeaderValueOption `json:"request_headers_to_add,omitempty"`
+}
+
+// TCPHealthCheck is an envoy.api.v2.core.HealthCheck.TcpHealthCheck.
+type TCPHealthCheck struct {
+	Send    *Payload  `json:"send,omitempty"`
+	Receive []Payload `json:"receive,omitempty"`
+}
+
+// Payload is an envoy.api.v2.core.HealthCheck.Payload holding hex encoded
+// bytes.
+type Payload struct {
+	Text string `json:"text"`
+}
+
+// HeaderValueOption is an envoy.api.v2.core.HeaderValueOption. If Append is
+// nil, Envoy appends the value to any already present.
+type HeaderValueOption struct {
+	Header HeaderValue `json:"header"`
+	Append *bool       `json:"append,omitempty"`
+}
+
+// HeaderValue is an envoy.api.v2.core.HeaderValue.
+type HeaderValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// OutlierDetection is an envoy.api.v2.cluster.OutlierDetection.
+type OutlierDetection struct {
+	Interval                           string `json:"interval,omitempty"`
+	BaseEjectionTime                   string `json:"base_ejection_time,omitempty"`
+	MaxEjectionPercent                 *int   `json:"max_ejection_percent,omitempty"`
+	Consecutive5xx                     *int   `json:"consecutive_5xx,omitempty"`
+	EnforcingConsecutive5xx            *int   `json:"enforcing_consecutive_5xx,omitempty"`
+	EnforcingSuccessRate               *int   `json:"enforcing_success_rate,omitempty"`
+	SuccessRateMinimumHosts            *int   `json:"success_rate_minimum_hosts,omitempty"`
+	SuccessRateRequestVolume           *int   `json:"success_rate_request_volume,omitempty"`
+	SuccessRateStdevFactor             *int   `json:"success_rate_stdev_factor,omitempty"`
+	ConsecutiveGatewayFailure          *int   `json:"consecutive_gateway_failure,omitempty"`
+	EnforcingConsecutiveGatewayFailure *int   `json:"enforcing_consecutive_gateway_failure,omitempty"`
+}
+
+// CircuitBreakers is an envoy.api.v2.cluster.CircuitBreakers.
+type CircuitBreakers struct {
+	Thresholds []Thresholds `json:"thresholds"`
+}
+
+// Thresholds is an envoy.api.v2.cluster.CircuitBreakers.Thresholds for the
+// default routing priority.
+type Thresholds struct {
+	MaxConnections     *int `json:"max_connections,omitempty"`
+	MaxPendingRequests *int `json:"max_pending_requests,omitempty"`
+	MaxRequests        *int `json:"max_requests,omitempty"`
+	MaxRetries         *int `json:"max_retries,omitempty"`
+}
+
+// Bootstrap is an envoy.config.bootstrap.v2.Bootstrap holding only static
+// resources.
+type Bootstrap struct {
+	StaticResources StaticResources `json:"static_resources"`
+}
+
+// StaticResources is an envoy.config.bootstrap.v2.Bootstrap.StaticResources.
+type StaticResources struct {
+	Listeners []Listener `json:"listeners"`
+	Clusters  []Cluster  `json:"clusters"`
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+)
+
+/*
+	FaultInjection configures delays and aborts injected into requests by the
+	proxy, for resilience testing. A FaultInjection may delay a percentage of
+	requests, abort a percentage of requests with an HTTP status, or both. If
+	Match is set, only requests with a matching header are affected.
+
+	It is possible to set a fault injection on a SharedRules, Route, or Rule
+	object. As with CohortSeed, only one of these will apply to any given
+	request: one set on a matching Rule takes precedence over one set on the
+	Route, which takes precedence over one set on the SharedRules. See
+	FaultInjectionFor.
+
+	Example:
+
+		FaultInjection{
+			Delay: &FaultDelay{DelayMsec: 500, Percent: 10},
+			Abort: &FaultAbort{HTTPStatus: 503, Percent: 1},
+			Match: &Match{
+				Kind:     HeaderMatchKind,
+				Behavior: ExactMatchBehavior,
+				From:     Metadatum{Key: "X-Fault-Test", Value: "true"},
+			},
+		}
+
+	would delay 10% of requests with an "X-Fault-Test: true" header by 500
+	milliseconds, and abort 1% of them with a 503.
+*/
+type FaultInjection struct {
+	// Delay, if set, injects a fixed delay before a request is forwarded.
+	Delay *FaultDelay `json:"delay"`
+
+	// Abort, if set, aborts a request with an HTTP status instead of
+	// forwarding it.
+	Abort *FaultAbort `json:"abort"`
+
+	// Match, if set, limits faults to requests whose headers match. It must
+	// be a header Match with no To datum.
+	Match *Match `json:"match"`
+}
+
+// FaultDelay specifies a fixed delay injected into a percentage of requests.
+type FaultDelay struct {
+	// Time in milliseconds to delay a request.
+	DelayMsec int `json:"delay_msec"`
+	// Percentage of requests, greater than 0 and at most 100, to delay.
+	Percent float64 `json:"percent"`
+}
+
+// FaultAbort specifies an HTTP status with which a percentage of requests
+// are aborted.
+type FaultAbort struct {
+	// HTTP status code, from 200 to 599, returned for an aborted request.
+	HTTPStatus int `json:"http_status"`
+	// Percentage of requests, greater than 0 and at most 100, to abort.
+	Percent float64 `json:"percent"`
+}
+
+// Checks for exact equality between this fault injection and another. Exact
+// equality means each field must be equal (== or Equal, as appropriate) to the
+// corresponding field in the parameter.
+func (fi FaultInjection) Equals(o FaultInjection) bool {
+	return faultDelayEquals(fi.Delay, o.Delay) &&
+		faultAbortEquals(fi.Abort, o.Abort) &&
+		matchPtrEquals(fi.Match, o.Match)
+}
+
+// Convenience function for calling Equals when you have pointers to two fault
+// injections.
+func FaultInjectionEquals(a, b *FaultInjection) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	return a.Equals(*b)
+}
+
+// Checks validity of a fault injection. For a fault injection to be valid it
+// must have a Delay, an Abort, or both. A Delay must have a non-negative
+// DelayMsec, and an Abort must have an HTTPStatus from 200 to 599; each must
+// have a Percent greater than 0 and at most 100. A Match, if present, must be
+// a valid header Match with no To datum.
+func (fi FaultInjection) IsValid() *ValidationError {
+	scope := func(s string) string { return "fault_injection." + s }
+
+	errs := &ValidationError{}
+	if fi.Delay == nil && fi.Abort == nil {
+		errs.AddNew(ErrorCase{"fault_injection", "must have a delay or an abort"})
+	}
+
+	if fi.Delay != nil {
+		if fi.Delay.DelayMsec < 0 {
+			errs.AddNew(ErrorCase{scope("delay.delay_msec"), "must not be negative"})
+		}
+		errCheckPercent(fi.Delay.Percent, errs, scope("delay.percent"))
+	}
+
+	if fi.Abort != nil {
+		if fi.Abort.HTTPStatus < 200 || fi.Abort.HTTPStatus > 599 {
+			errs.AddNew(ErrorCase{
+				scope("abort.http_status"),
+				fmt.Sprintf("%d is not a valid HTTP status", fi.Abort.HTTPStatus),
+			})
+		}
+		errCheckPercent(fi.Abort.Percent, errs, scope("abort.percent"))
+	}
+
+	if fi.Match != nil {
+		if fi.Match.Kind != HeaderMatchKind {
+			errs.AddNew(ErrorCase{
+				scope("match.kind"),
+				fmt.Sprintf("must be %q", HeaderMatchKind),
+			})
+		}
+		if fi.Match.To.Key != "" || fi.Match.To.Value != "" {
+			errs.AddNew(ErrorCase{scope("match.to"), "must be empty"})
+		}
+		errs.MergePrefixed(fi.Match.IsValid(), scope("match"))
+	}
+
+	return errs.OrNil()
+}
+
+// FaultInjectionFor returns the FaultInjection that applies to a request
+// handled by the given SharedRules and Route and, if non-nil, the Rule that
+// matched it. The most specific FaultInjection set is returned, or nil if
+// none is set.
+func FaultInjectionFor(sr SharedRules, r Route, rule *Rule) *FaultInjection {
+	switch {
+	case rule != nil && rule.FaultInjection != nil:
+		return rule.FaultInjection
+	case r.FaultInjection != nil:
+		return r.FaultInjection
+	default:
+		return sr.FaultInjection
+	}
+}
+
+func errCheckPercent(pct float64, errs *ValidationError, field string) {
+	if pct <= 0 || pct > 100 {
+		errs.AddNew(ErrorCase{field, "must be greater than 0 and at most 100"})
+	}
+}
+
+func faultDelayEquals(a, b *FaultDelay) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func faultAbortEquals(a, b *FaultAbort) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func matchPtrEquals(a, b *Match) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equals(*b)
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/turbinelabs/test/assert"
+)
+
+func mkFI() *FaultInjection {
+	return &FaultInjection{
+		Delay: &FaultDelay{DelayMsec: 500, Percent: 10},
+		Abort: &FaultAbort{HTTPStatus: 503, Percent: 1.5},
+		Match: &Match{
+			Kind:     HeaderMatchKind,
+			Behavior: ExactMatchBehavior,
+			From:     Metadatum{Key: "x-fault-test", Value: "true"},
+		},
+	}
+}
+
+func TestFaultInjectionEquals(t *testing.T) {
+	assert.True(t, mkFI().Equals(*mkFI()))
+	assert.True(t, FaultInjectionEquals(mkFI(), mkFI()))
+	assert.True(t, FaultInjectionEquals(nil, nil))
+	assert.False(t, FaultInjectionEquals(mkFI(), nil))
+	assert.False(t, FaultInjectionEquals(nil, mkFI()))
+}
+
+func TestFaultInjectionEqualsVaries(t *testing.T) {
+	for _, mutate := range []func(*FaultInjection){
+		func(fi *FaultInjection) { fi.Delay = nil },
+		func(fi *FaultInjection) { fi.Delay.DelayMsec++ },
+		func(fi *FaultInjection) { fi.Delay.Percent++ },
+		func(fi *FaultInjection) { fi.Abort = nil },
+		func(fi *FaultInjection) { fi.Abort.HTTPStatus++ },
+		func(fi *FaultInjection) { fi.Abort.Percent++ },
+		func(fi *FaultInjection) { fi.Match = nil },
+		func(fi *FaultInjection) { fi.Match.From.Key = "x-other" },
+	} {
+		fi := mkFI()
+		mutate(fi)
+		assert.False(t, mkFI().Equals(*fi))
+		assert.False(t, fi.Equals(*mkFI()))
+	}
+}
+
+func TestFaultInjectionJSON(t *testing.T) {
+	b, err := json.Marshal(FaultInjection{Abort: &FaultAbort{HTTPStatus: 503, Percent: 2.5}})
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		string(b),
+		`{"delay":null,"abort":{"http_status":503,"percent":2.5},"match":null}`,
+	)
+
+	fi := FaultInjection{}
+	assert.Nil(t, json.Unmarshal(
+		[]byte(`{
+			"delay": {"delay_msec": 500, "percent": 10},
+			"abort": {"http_status": 503, "percent": 1.5},
+			"match": {"kind": "header", "behavior": "exact", "from": {"key": "x-fault-test", "value": "true"}}
+		}`),
+		&fi,
+	))
+	assert.True(t, fi.Equals(*mkFI()))
+}
+
+func TestFaultInjectionIsValid(t *testing.T) {
+	assert.Nil(t, mkFI().IsValid())
+
+	fi := mkFI()
+	fi.Delay = nil
+	fi.Match = nil
+	assert.Nil(t, fi.IsValid())
+
+	fi = mkFI()
+	fi.Abort = nil
+	fi.Delay.DelayMsec = 0
+	fi.Delay.Percent = 100
+	assert.Nil(t, fi.IsValid())
+}
+
+func TestFaultInjectionIsValidEmpty(t *testing.T) {
+	assert.DeepEqual(t, FaultInjection{}.IsValid(), &ValidationError{[]ErrorCase{
+		{"fault_injection", "must have a delay or an abort"},
+	}})
+}
+
+func TestFaultInjectionIsValidBadDelay(t *testing.T) {
+	fi := mkFI()
+	fi.Delay.DelayMsec = -1
+	fi.Delay.Percent = 100.5
+	assert.DeepEqual(t, fi.IsValid(), &ValidationError{[]ErrorCase{
+		{"fault_injection.delay.delay_msec", "must not be negative"},
+		{"fault_injection.delay.percent", "must be greater than 0 and at most 100"},
+	}})
+}
+
+func TestFaultInjectionIsValidBadAbort(t *testing.T) {
+	for _, status := range []int{0, 199, 600} {
+		fi := mkFI()
+		fi.Abort.HTTPStatus = status
+		fi.Abort.Percent = -1
+		assert.DeepEqual(t, fi.IsValid(), &ValidationError{[]ErrorCase{
+			{"fault_injection.abort.http_status", fmt.Sprintf("%d is not a valid HTTP status", status)},
+			{"fault_injection.abort.percent", "must be greater than 0 and at most 100"},
+		}})
+	}
+}
+
+func TestFaultInjectionIsValidBadMatch(t *testing.T) {
+	fi := mkFI()
+	fi.Match.Kind = CookieMatchKind
+	fi.Match.To = Metadatum{Key: "flag", Value: "true"}
+	assert.DeepEqual(t, fi.IsValid(), &ValidationError{[]ErrorCase{
+		{"fault_injection.match.kind", `must be "header"`},
+		{"fault_injection.match.to", "must be empty"},
+	}})
+
+	fi = mkFI()
+	fi.Match.From.Key = ""
+	assert.DeepEqual(t, fi.IsValid(), &ValidationError{[]ErrorCase{
+		{"fault_injection.match.from.key", "may not be empty"},
+	}})
+}
+
+func TestFaultInjectionFor(t *testing.T) {
+	srFI := &FaultInjection{Delay: &FaultDelay{DelayMsec: 1, Percent: 1}}
+	routeFI := &FaultInjection{Delay: &FaultDelay{DelayMsec: 2, Percent: 2}}
+	ruleFI := &FaultInjection{Delay: &FaultDelay{DelayMsec: 3, Percent: 3}}
+
+	sr := SharedRules{FaultInjection: srFI}
+	r := Route{FaultInjection: routeFI}
+	rule := Rule{FaultInjection: ruleFI}
+
+	assert.SameInstance(t, FaultInjectionFor(sr, r, &rule), ruleFI)
+	assert.SameInstance(t, FaultInjectionFor(sr, r, nil), routeFI)
+	assert.SameInstance(t, FaultInjectionFor(sr, r, &Rule{}), routeFI)
+	assert.SameInstance(t, FaultInjectionFor(sr, Route{}, &Rule{}), srFI)
+	assert.SameInstance(t, FaultInjectionFor(SharedRules{}, Route{}, &rule), ruleFI)
+	assert.Nil(t, FaultInjectionFor(SharedRules{}, Route{}, &Rule{}))
+}
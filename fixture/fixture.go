@@ -183,8 +183,10 @@ type DataFixturesT struct {
 	RouteSharedRulesKey1 api.SharedRulesKey
 	RouteRules1          api.Rules
 	RouteResponseData1   api.ResponseData
+	RouteRequestData1    api.RequestData
 	RouteCohortSeed1     *api.CohortSeed
 	RouteRetryPolicy1    *api.RetryPolicy
+	RouteFaultInjection1 *api.FaultInjection
 	RouteChecksum1       api.Checksum
 	RouteOrgKey1         api.OrgKey
 	RouteKey2            api.RouteKey
@@ -194,8 +196,10 @@ type DataFixturesT struct {
 	RouteSharedRulesKey2 api.SharedRulesKey
 	RouteRules2          api.Rules
 	RouteResponseData2   api.ResponseData
+	RouteRequestData2    api.RequestData
 	RouteCohortSeed2     *api.CohortSeed
 	RouteRetryPolicy2    *api.RetryPolicy
+	RouteFaultInjection2 *api.FaultInjection
 	RouteChecksum2       api.Checksum
 	RouteOrgKey2         api.OrgKey
 	Route1               api.Route
@@ -203,32 +207,36 @@ type DataFixturesT struct {
 	RouteSlice           api.Routes
 	PublicRouteSlice     api.Routes
 
-	SharedRulesKey1          api.SharedRulesKey
-	SharedRulesName1         string
-	SharedRulesZone1         api.ZoneKey
-	SharedRulesDefault1      api.AllConstraints
-	SharedRulesRules1        api.Rules
-	SharedRulesResponseData1 api.ResponseData
-	SharedRulesCohortSeed1   *api.CohortSeed
-	SharedRulesProperties1   api.Metadata
-	SharedRulesRetryPolicy1  *api.RetryPolicy
-	SharedRulesChecksum1     api.Checksum
-	SharedRulesOrgKey1       api.OrgKey
-	SharedRulesKey2          api.SharedRulesKey
-	SharedRulesName2         string
-	SharedRulesZone2         api.ZoneKey
-	SharedRulesDefault2      api.AllConstraints
-	SharedRulesRules2        api.Rules
-	SharedRulesResponseData2 api.ResponseData
-	SharedRulesCohortSeed2   *api.CohortSeed
-	SharedRulesProperties2   api.Metadata
-	SharedRulesRetryPolicy2  *api.RetryPolicy
-	SharedRulesChecksum2     api.Checksum
-	SharedRulesOrgKey2       api.OrgKey
-	SharedRules1             api.SharedRules
-	SharedRules2             api.SharedRules
-	SharedRulesSlice         api.SharedRulesSlice
-	PublicSharedRulesSlice   api.SharedRulesSlice
+	SharedRulesKey1            api.SharedRulesKey
+	SharedRulesName1           string
+	SharedRulesZone1           api.ZoneKey
+	SharedRulesDefault1        api.AllConstraints
+	SharedRulesRules1          api.Rules
+	SharedRulesResponseData1   api.ResponseData
+	SharedRulesRequestData1    api.RequestData
+	SharedRulesCohortSeed1     *api.CohortSeed
+	SharedRulesProperties1     api.Metadata
+	SharedRulesRetryPolicy1    *api.RetryPolicy
+	SharedRulesFaultInjection1 *api.FaultInjection
+	SharedRulesChecksum1       api.Checksum
+	SharedRulesOrgKey1         api.OrgKey
+	SharedRulesKey2            api.SharedRulesKey
+	SharedRulesName2           string
+	SharedRulesZone2           api.ZoneKey
+	SharedRulesDefault2        api.AllConstraints
+	SharedRulesRules2          api.Rules
+	SharedRulesResponseData2   api.ResponseData
+	SharedRulesRequestData2    api.RequestData
+	SharedRulesCohortSeed2     *api.CohortSeed
+	SharedRulesProperties2     api.Metadata
+	SharedRulesRetryPolicy2    *api.RetryPolicy
+	SharedRulesFaultInjection2 *api.FaultInjection
+	SharedRulesChecksum2       api.Checksum
+	SharedRulesOrgKey2         api.OrgKey
+	SharedRules1               api.SharedRules
+	SharedRules2               api.SharedRules
+	SharedRulesSlice           api.SharedRulesSlice
+	PublicSharedRulesSlice     api.SharedRulesSlice
 
 	AccessToken1            api.AccessToken
 	AccessTokenKey1         api.AccessTokenKey
@@ -742,13 +750,14 @@ func New() DataFixturesT {
 					api.Metadata{{"key-2", "value-2"}},
 					api.Metadata{{"state", "test"}},
 					api.ResponseData{},
+					api.RequestData{},
 					1234,
 				}}},
 	}
 
 	routeRule2 := api.Rule{
 		RuleKey: "rk-0-1",
-		Methods: []string{"PUT", "DELETE"},
+		Methods: []string{"PUT", "DELETE", "PATCH"},
 		Matches: api.Matches{
 			{
 				Kind:     api.CookieMatchKind,
@@ -756,12 +765,18 @@ func New() DataFixturesT {
 				From:     api.Metadatum{Key: "x-2", Value: "value"},
 				To:       api.Metadatum{Key: "other", Value: "true"},
 			},
+			{
+				Kind:     api.PathMatchKind,
+				Behavior: api.PrefixMatchBehavior,
+				From:     api.Metadatum{Value: "/v2/"},
+				To:       api.Metadatum{Key: "api-version", Value: "2"},
+			},
 		},
 		Constraints: api.AllConstraints{
 			Tap: api.ClusterConstraints{
-				{"cc-1", "ckey3", api.Metadata{{"key-2", "value-2"}}, api.Metadata{}, api.ResponseData{}, 1234}},
+				{"cc-1", "ckey3", api.Metadata{{"key-2", "value-2"}}, api.Metadata{}, api.ResponseData{}, api.RequestData{}, 1234}},
 			Light: api.ClusterConstraints{
-				{"cc-2", "ckey2", api.Metadata{{"key-2", "value-2"}}, api.Metadata{}, api.ResponseData{}, 1234}}},
+				{"cc-2", "ckey2", api.Metadata{{"key-2", "value-2"}}, api.Metadata{}, api.ResponseData{}, api.RequestData{}, 1234}}},
 	}
 
 	df.RouteRules1 = api.Rules{routeRule1}
@@ -800,8 +815,26 @@ func New() DataFixturesT {
 			},
 		},
 	}
+	df.RouteRequestData1 = api.RequestData{
+		Headers: []api.RequestHeaderDatum{
+			{
+				Name:   "X-Route1-Session",
+				Action: api.AddHeaderAction,
+				Value:  "cookie:session",
+			},
+		},
+	}
+	df.RouteFaultInjection1 = &api.FaultInjection{
+		Abort: &api.FaultAbort{HTTPStatus: 503, Percent: 5},
+		Match: &api.Match{
+			Kind:     api.HeaderMatchKind,
+			Behavior: api.ExactMatchBehavior,
+			From:     api.Metadatum{Key: "x-fault-test", Value: "true"},
+		},
+	}
 	df.RouteRules2 = api.Rules{routeRule1, routeRule2}
 	df.RouteResponseData2 = api.ResponseData{}
+	df.RouteRequestData2 = api.RequestData{}
 	df.Route1 = api.Route{
 		df.RouteKey1,
 		df.RouteDomain1,
@@ -810,8 +843,11 @@ func New() DataFixturesT {
 		df.SharedRulesKey1,
 		df.RouteRules1,
 		df.RouteResponseData1,
+		df.RouteRequestData1,
 		df.RouteCohortSeed1,
 		df.RouteRetryPolicy1,
+		df.RouteFaultInjection1,
+		nil,
 		df.RouteOrgKey1,
 		df.RouteChecksum1,
 	}
@@ -824,8 +860,11 @@ func New() DataFixturesT {
 		df.SharedRulesKey2,
 		df.RouteRules2,
 		df.RouteResponseData2,
+		df.RouteRequestData2,
 		df.RouteCohortSeed2,
 		df.RouteRetryPolicy2,
+		df.RouteFaultInjection2,
+		nil,
 		df.RouteOrgKey2,
 		df.RouteChecksum2,
 	}
@@ -857,13 +896,15 @@ func New() DataFixturesT {
 		},
 		api.AllConstraints{
 			Light: api.ClusterConstraints{
-				{"cc-0", "ckey2", api.Metadata{{"key-2", "value-2"}}, api.Metadata{{"state", "test"}}, api.ResponseData{}, 1234}}},
+				{"cc-0", "ckey2", api.Metadata{{"key-2", "value-2"}}, api.Metadata{{"state", "test"}}, api.ResponseData{}, api.RequestData{}, 1234}}},
+		nil,
+		nil,
 		nil,
 	}
 
 	sharedRulesRule2 := api.Rule{
 		"srk-0-1",
-		[]string{"PUT", "DELETE"},
+		[]string{"PUT", "DELETE", "OPTIONS"},
 		api.Matches{
 			api.Match{
 				Kind:     api.CookieMatchKind,
@@ -871,18 +912,34 @@ func New() DataFixturesT {
 				From:     api.Metadatum{Key: "x-2", Value: "value"},
 				To:       api.Metadatum{Key: "other", Value: "true"},
 			},
+			api.Match{
+				Kind:     api.SourceIPMatchKind,
+				Behavior: api.ExactMatchBehavior,
+				From:     api.Metadatum{Value: "10.0.0.0/8"},
+				To:       api.Metadatum{Key: "internal", Value: "true"},
+			},
+			api.Match{
+				Kind:     api.HostMatchKind,
+				Behavior: api.SuffixMatchBehavior,
+				From:     api.Metadatum{Value: ".example.com"},
+			},
 		},
 		api.AllConstraints{
 			Tap: api.ClusterConstraints{
-				{"cc-1", "ckey3", api.Metadata{{"key-2", "value-2"}}, api.Metadata{}, api.ResponseData{}, 1234}},
+				{"cc-1", "ckey3", api.Metadata{{"key-2", "value-2"}}, api.Metadata{}, api.ResponseData{}, api.RequestData{}, 1234}},
 			Light: api.ClusterConstraints{
-				{"cc-2", "ckey2", api.Metadata{{"key-2", "value-2"}}, api.Metadata{}, api.ResponseData{}, 1234}}},
+				{"cc-2", "ckey2", api.Metadata{{"key-2", "value-2"}}, api.Metadata{}, api.ResponseData{}, api.RequestData{}, 1234}}},
+		nil,
+		&api.FaultInjection{
+			Delay: &api.FaultDelay{DelayMsec: 1000, Percent: 50},
+			Abort: &api.FaultAbort{HTTPStatus: 500, Percent: 0.1},
+		},
 		nil,
 	}
 
 	sharedRulesDefault1 := api.AllConstraints{
 		Light: api.ClusterConstraints{
-			{"cc-4", "ckey4", api.Metadata{{"k", "v"}, {"k2", "v2"}}, api.Metadata{{"state", "released"}}, api.ResponseData{}, 23}}}
+			{"cc-4", "ckey4", api.Metadata{{"k", "v"}, {"k2", "v2"}}, api.Metadata{{"state", "released"}}, api.ResponseData{}, api.RequestData{}, 23}}}
 	sharedRulesDefault2 := sharedRulesDefault1
 
 	df.SharedRulesDefault1 = sharedRulesDefault1
@@ -922,9 +979,32 @@ func New() DataFixturesT {
 			},
 		},
 	}
+	df.SharedRulesRequestData1 = api.RequestData{
+		Headers: []api.RequestHeaderDatum{
+			{
+				Name:           "X-Tbn-Env",
+				Action:         api.OverwriteHeaderAction,
+				Value:          "production",
+				ValueIsLiteral: true,
+			},
+			{
+				Name:   "X-Tbn-Client-Ip",
+				Action: api.OverwriteHeaderAction,
+				Value:  "source_ip",
+			},
+			{
+				Name:   "X-Tbn-Debug",
+				Action: api.RemoveHeaderAction,
+			},
+		},
+	}
+	df.SharedRulesFaultInjection1 = &api.FaultInjection{
+		Delay: &api.FaultDelay{DelayMsec: 250, Percent: 12.5},
+	}
 	df.SharedRulesDefault2 = sharedRulesDefault2
 	df.SharedRulesRules2 = api.Rules{sharedRulesRule1, sharedRulesRule2}
 	df.SharedRulesResponseData2 = api.ResponseData{}
+	df.SharedRulesRequestData2 = api.RequestData{}
 	df.SharedRules1 = api.SharedRules{
 		df.SharedRulesKey1,
 		df.SharedRulesName1,
@@ -932,9 +1012,11 @@ func New() DataFixturesT {
 		df.SharedRulesDefault1,
 		df.SharedRulesRules1,
 		df.SharedRulesResponseData1,
+		df.SharedRulesRequestData1,
 		df.SharedRulesCohortSeed1,
 		df.SharedRulesProperties1,
 		df.SharedRulesRetryPolicy1,
+		df.SharedRulesFaultInjection1,
 		df.SharedRulesOrgKey1,
 		df.SharedRulesChecksum1,
 	}
@@ -946,9 +1028,11 @@ func New() DataFixturesT {
 		df.SharedRulesDefault2,
 		df.SharedRulesRules2,
 		df.SharedRulesResponseData2,
+		df.SharedRulesRequestData2,
 		df.SharedRulesCohortSeed2,
 		df.SharedRulesProperties2,
 		df.SharedRulesRetryPolicy2,
+		df.SharedRulesFaultInjection2,
 		df.SharedRulesOrgKey2,
 		df.SharedRulesChecksum2,
 	}
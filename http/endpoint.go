@@ -54,7 +54,8 @@ type Params map[string]string
 // The Endpoint object is configured with no custom headers (see
 // Endpoint.AddHeader), and the net/http.Client created by
 // HeaderPreservingClient. You may specify an alternate client via
-// Endpoint.SetClient.
+// Endpoint.SetClient. Requests are not retried unless a RetryPolicy is
+// specified via Endpoint.SetRetryPolicy.
 func NewEndpoint(protocol Protocol, hostPort string) (Endpoint, error) {
 	url, err := url.Parse(fmt.Sprintf("%s://%s", string(protocol), hostPort))
 	if err != nil {
@@ -66,6 +67,7 @@ func NewEndpoint(protocol Protocol, hostPort string) (Endpoint, error) {
 		protocol: protocol,
 		header:   http.Header{},
 		client:   HeaderPreservingClient(),
+		retry:    NoRetries(),
 		urlBase:  url,
 	}, nil
 }
@@ -77,6 +79,7 @@ type Endpoint struct {
 	protocol Protocol
 	header   http.Header
 	client   *http.Client
+	retry    RetryPolicy
 
 	urlBase *url.URL // computed at construction
 }
@@ -107,6 +110,20 @@ func (e *Endpoint) SetClient(c *http.Client) {
 	e.client = c
 }
 
+// RetryPolicy returns the RetryPolicy for this Endpoint.
+func (e *Endpoint) RetryPolicy() RetryPolicy {
+	if e.retry == nil {
+		return NoRetries()
+	}
+	return e.retry
+}
+
+// SetRetryPolicy sets the RetryPolicy used by RequestHandlers created for
+// this Endpoint (see NewRetryingRequestHandler).
+func (e *Endpoint) SetRetryPolicy(p RetryPolicy) {
+	e.retry = p
+}
+
 // AddHeader adds a header to be added to all requests created via NewRequest.
 // These headers are meant to be constant across all requests (e.g. a client
 // identifier). Headers specific to a particular request should be added
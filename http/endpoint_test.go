@@ -19,6 +19,7 @@ package http
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/turbinelabs/test/assert"
 	"github.com/turbinelabs/test/io"
@@ -61,6 +62,20 @@ func TestEndpointClient(t *testing.T) {
 	assert.SameInstance(t, e.Client(), otherClient)
 }
 
+func TestEndpointRetryPolicy(t *testing.T) {
+	e, _ := NewEndpoint(HTTP, "example.com:80")
+	assert.Equal(t, e.RetryPolicy(), NoRetries())
+
+	policy := NewBackoffRetryPolicy(3, time.Millisecond, time.Second, 0.0)
+	e.SetRetryPolicy(policy)
+	assert.SameInstance(t, e.RetryPolicy(), policy)
+
+	e2 := e.Copy()
+	assert.SameInstance(t, e2.RetryPolicy(), policy)
+
+	assert.Equal(t, (&Endpoint{}).RetryPolicy(), NoRetries())
+}
+
 func TestEndpointAddHeader(t *testing.T) {
 	e, _ := NewEndpoint(HTTP, "example.com:80")
 	assert.Equal(t, len(e.header), 0)
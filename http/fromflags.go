@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	tbnflag "github.com/turbinelabs/nonstdlib/flag"
 	tbnstrings "github.com/turbinelabs/nonstdlib/strings"
@@ -62,9 +63,41 @@ func NewFromFlags(defaultHostPort string, flagset tbnflag.FlagSet) FromFlags {
 		"Specifies a custom `header` to send with every {{NAME}} request. Headers are given as name:value pairs. Leading and trailing whitespace will be stripped from the name and value. For multiple headers, this flag may be repeated or multiple headers can be delimited with commas.",
 	)
 
+	flagset.IntVar(
+		&ff.maxAttempts,
+		"max-attempts",
+		defaultMaxAttempts,
+		"The maximum number of attempts made for each {{NAME}} request. If greater than 1, transient failures are retried with exponential backoff. By default, requests are not retried.",
+	)
+	flagset.DurationVar(
+		&ff.retryDelay,
+		"retry-delay",
+		defaultRetryDelay,
+		"The delay before the first retry of a failed {{NAME}} request. The delay doubles with each subsequent retry.",
+	)
+	flagset.DurationVar(
+		&ff.retryMaxDelay,
+		"retry-max-delay",
+		defaultRetryMaxDelay,
+		"The maximum delay between retries of a failed {{NAME}} request. A request for which the server requests a longer delay via Retry-After is not retried.",
+	)
+	flagset.Float64Var(
+		&ff.retryJitter,
+		"retry-jitter",
+		defaultRetryJitter,
+		"The maximum fraction (0.0 to 1.0) by which the delay between retries of a failed {{NAME}} request is randomly reduced.",
+	)
+
 	return ff
 }
 
+const (
+	defaultMaxAttempts   = 1
+	defaultRetryDelay    = 100 * time.Millisecond
+	defaultRetryMaxDelay = 5 * time.Second
+	defaultRetryJitter   = 0.5
+)
+
 type header string
 
 func (h header) split() (string, string, error) {
@@ -81,6 +114,11 @@ type fromFlags struct {
 	ssl      bool
 	insecure bool
 	headers  tbnflag.Strings
+
+	maxAttempts   int
+	retryDelay    time.Duration
+	retryMaxDelay time.Duration
+	retryJitter   float64
 }
 
 func (ff *fromFlags) makeClient() *http.Client {
@@ -94,6 +132,19 @@ func (ff *fromFlags) makeClient() *http.Client {
 	return cl
 }
 
+func (ff *fromFlags) makeRetryPolicy() RetryPolicy {
+	if ff.maxAttempts <= 1 {
+		return NoRetries()
+	}
+
+	return NewBackoffRetryPolicy(
+		ff.maxAttempts,
+		ff.retryDelay,
+		ff.retryMaxDelay,
+		ff.retryJitter,
+	)
+}
+
 func (ff *fromFlags) Validate() error {
 	for _, hs := range ff.headers.Strings {
 		if _, _, err := header(hs).split(); err != nil {
@@ -101,6 +152,28 @@ func (ff *fromFlags) Validate() error {
 		}
 	}
 
+	if ff.maxAttempts < 0 {
+		return fmt.Errorf("max-attempts must not be negative, got %d", ff.maxAttempts)
+	}
+
+	if ff.maxAttempts > 1 {
+		if ff.retryDelay <= 0 {
+			return fmt.Errorf("retry-delay must be positive, got %s", ff.retryDelay)
+		}
+
+		if ff.retryMaxDelay < ff.retryDelay {
+			return fmt.Errorf(
+				"retry-max-delay (%s) must not be less than retry-delay (%s)",
+				ff.retryMaxDelay,
+				ff.retryDelay,
+			)
+		}
+
+		if ff.retryJitter < 0.0 || ff.retryJitter > 1.0 {
+			return fmt.Errorf("retry-jitter must be between 0.0 and 1.0, got %g", ff.retryJitter)
+		}
+	}
+
 	return nil
 }
 
@@ -118,6 +191,7 @@ func (ff *fromFlags) MakeEndpoint() (Endpoint, error) {
 	}
 
 	e.SetClient(ff.makeClient())
+	e.SetRetryPolicy(ff.makeRetryPolicy())
 
 	for _, hs := range ff.headers.Strings {
 		hdr, value, err := header(hs).split()
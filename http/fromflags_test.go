@@ -19,6 +19,7 @@ package http
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	tbnflag "github.com/turbinelabs/nonstdlib/flag"
 	"github.com/turbinelabs/test/assert"
@@ -115,3 +116,83 @@ func TestFromFlagsMakeEndpoint(t *testing.T) {
 	assert.Equal(t, e.urlBase.String(), "https://example.com:443")
 	assert.NonNil(t, e.client)
 }
+
+func TestNewFromFlagsRetries(t *testing.T) {
+	flagset := tbnflag.NewTestFlagSet()
+
+	ff := NewFromFlags("api.turbinelabs.io", flagset.Scope("api", "API"))
+	ffImpl := ff.(*fromFlags)
+
+	assert.Equal(t, ffImpl.maxAttempts, defaultMaxAttempts)
+	assert.Equal(t, ffImpl.retryDelay, defaultRetryDelay)
+	assert.Equal(t, ffImpl.retryMaxDelay, defaultRetryMaxDelay)
+	assert.Equal(t, ffImpl.retryJitter, defaultRetryJitter)
+
+	flagset.Parse([]string{
+		"-api.max-attempts=5",
+		"-api.retry-delay=1s",
+		"-api.retry-max-delay=1m",
+		"-api.retry-jitter=0.25",
+	})
+
+	assert.Equal(t, ffImpl.maxAttempts, 5)
+	assert.Equal(t, ffImpl.retryDelay, time.Second)
+	assert.Equal(t, ffImpl.retryMaxDelay, time.Minute)
+	assert.Equal(t, ffImpl.retryJitter, 0.25)
+}
+
+func TestFromFlagsValidateRetries(t *testing.T) {
+	ff := &fromFlags{
+		hostPort:      tbnflag.NewHostPort("example.com:80"),
+		headers:       tbnflag.NewStrings(),
+		maxAttempts:   3,
+		retryDelay:    time.Second,
+		retryMaxDelay: time.Minute,
+		retryJitter:   0.5,
+	}
+	assert.Nil(t, ff.Validate())
+
+	ff.retryJitter = 1.5
+	assert.ErrorContains(t, ff.Validate(), "retry-jitter must be between 0.0 and 1.0")
+
+	ff.retryJitter = 0.5
+	ff.retryMaxDelay = time.Millisecond
+	assert.ErrorContains(t, ff.Validate(), "must not be less than retry-delay")
+
+	ff.retryDelay = 0
+	assert.ErrorContains(t, ff.Validate(), "retry-delay must be positive")
+
+	// retry settings are ignored when retries are disabled
+	ff.maxAttempts = 1
+	assert.Nil(t, ff.Validate())
+
+	ff.maxAttempts = -1
+	assert.ErrorContains(t, ff.Validate(), "max-attempts must not be negative")
+}
+
+func TestFromFlagsMakeRetryPolicy(t *testing.T) {
+	ff := &fromFlags{maxAttempts: 1}
+	assert.Equal(t, ff.makeRetryPolicy(), NoRetries())
+
+	ff = &fromFlags{
+		hostPort:      tbnflag.NewHostPort("example.com:80"),
+		headers:       tbnflag.NewStrings(),
+		maxAttempts:   4,
+		retryDelay:    time.Second,
+		retryMaxDelay: time.Minute,
+		retryJitter:   0.5,
+	}
+
+	policy, ok := ff.makeRetryPolicy().(*backoffRetryPolicy)
+	assert.True(t, ok)
+	assert.Equal(t, policy.maxAttempts, 4)
+	assert.Equal(t, policy.initialDelay, time.Second)
+	assert.Equal(t, policy.maxDelay, time.Minute)
+	assert.Equal(t, policy.jitter, 0.5)
+
+	e, err := ff.MakeEndpoint()
+	assert.Nil(t, err)
+	assert.NonNil(t, e.RetryPolicy())
+	_, ok = e.RetryPolicy().(*backoffRetryPolicy)
+	assert.True(t, ok)
+}
@@ -0,0 +1,46 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: retry.go
+
+package http
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+	time "time"
+)
+
+// MockRetryPolicy is a mock of RetryPolicy interface
+type MockRetryPolicy struct {
+	ctrl     *gomock.Controller
+	recorder *MockRetryPolicyMockRecorder
+}
+
+// MockRetryPolicyMockRecorder is the mock recorder for MockRetryPolicy
+type MockRetryPolicyMockRecorder struct {
+	mock *MockRetryPolicy
+}
+
+// NewMockRetryPolicy creates a new mock instance
+func NewMockRetryPolicy(ctrl *gomock.Controller) *MockRetryPolicy {
+	mock := &MockRetryPolicy{ctrl: ctrl}
+	mock.recorder = &MockRetryPolicyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRetryPolicy) EXPECT() *MockRetryPolicyMockRecorder {
+	return m.recorder
+}
+
+// Retry mocks base method
+func (m *MockRetryPolicy) Retry(a Attempt) (time.Duration, bool) {
+	ret := m.ctrl.Call(m, "Retry", a)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Retry indicates an expected call of Retry
+func (mr *MockRetryPolicyMockRecorder) Retry(a interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Retry", reflect.TypeOf((*MockRetryPolicy)(nil).Retry), a)
+}
@@ -17,21 +17,56 @@ limitations under the License.
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/turbinelabs/api/http/envelope"
 	httperr "github.com/turbinelabs/api/http/error"
 )
 
 type RequestHandler struct {
-	client *http.Client
+	client      *http.Client
+	retryPolicy RetryPolicy
+	sleep       func(context.Context, time.Duration) error
 }
 
+// NewRequestHandler returns a RequestHandler that makes a single attempt at
+// each request using the given net/http.Client.
 func NewRequestHandler(client *http.Client) RequestHandler {
-	return RequestHandler{client}
+	return NewRetryingRequestHandler(client, nil)
+}
+
+// NewRetryingRequestHandler returns a RequestHandler that uses the given
+// net/http.Client and consults the given RetryPolicy after each failed
+// attempt. A nil RetryPolicy is equivalent to NoRetries.
+func NewRetryingRequestHandler(client *http.Client, policy RetryPolicy) RequestHandler {
+	if policy == nil {
+		policy = NoRetries()
+	}
+
+	return RequestHandler{client: client, retryPolicy: policy, sleep: sleepContext}
+}
+
+// sleepContext waits for the given duration or until ctx is done, whichever
+// comes first. Returns ctx.Err() in the latter case.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
 func getBody(response *http.Response) ([]byte, *httperr.Error) {
@@ -113,15 +148,46 @@ func expectsPayload(url string, response *http.Response, payloadDest interface{}
 // Given a request and response container make the request and populate the
 // response object. If the server returns an error (an encoded service.error)
 // or there are problems decoding the response return an error.
+//
+// Failed attempts are retried according to the RequestHandler's RetryPolicy.
+// mkReq is invoked once per attempt, and so must produce a fresh request
+// (including its body) on each invocation.
 func (rh RequestHandler) Do(
 	mkReq func() (*http.Request, error),
 	response interface{},
 ) error {
-	req, err := mkReq()
-	if err != nil {
-		return fmt.Errorf("could not create request: %s", err.Error())
+	for attempt := 1; ; attempt++ {
+		req, err := mkReq()
+		if err != nil {
+			return fmt.Errorf("could not create request: %s", err.Error())
+		}
+
+		a, err := rh.do(req, response)
+		if err == nil || rh.retryPolicy == nil {
+			return err
+		}
+
+		a.Number = attempt
+		a.Method = req.Method
+		delay, retry := rh.retryPolicy.Retry(a)
+		if !retry || req.Context().Err() != nil {
+			return err
+		}
+
+		sleep := rh.sleep
+		if sleep == nil {
+			sleep = sleepContext
+		}
+		if sleep(req.Context(), delay) != nil {
+			return err
+		}
 	}
+}
 
+// do makes a single attempt at the given request. On failure, returns the
+// error to be reported to the caller along with an Attempt describing the
+// failure.
+func (rh RequestHandler) do(req *http.Request, response interface{}) (Attempt, error) {
 	url := "unknown API endpoint"
 	if req.URL != nil {
 		url = req.URL.String()
@@ -134,14 +200,45 @@ func (rh RequestHandler) Do(
 	// something was wrong with the server (this is, admittedly, a guess without
 	// further introspection but we'll let it stand for now).
 	if err != nil {
-		return fmt.Errorf("could not successfully make request to %s: %s", url, err.Error())
+		return Attempt{Err: err},
+			fmt.Errorf("could not successfully make request to %s: %s", url, err.Error())
 	}
+	defer resp.Body.Close()
 
 	if response == nil {
-		return expectsNoPayload(url, resp)
+		err = expectsNoPayload(url, resp)
+	} else {
+		err = expectsPayload(url, resp, response)
 	}
 
-	return expectsPayload(url, resp, response)
+	if err == nil {
+		return Attempt{}, nil
+	}
+
+	return Attempt{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		Err:        err,
+	}, err
+}
+
+// DoContext behaves as Do, but associates ctx with the request so that
+// cancellation or expiration of ctx aborts the request.
+func (rh RequestHandler) DoContext(
+	ctx context.Context,
+	mkReq func() (*http.Request, error),
+	response interface{},
+) error {
+	return rh.Do(
+		func() (*http.Request, error) {
+			req, err := mkReq()
+			if err != nil {
+				return nil, err
+			}
+			return req.WithContext(ctx), nil
+		},
+		response,
+	)
 }
 
 func mkNoBodyErr(url string, statusCode int) *httperr.Error {
@@ -17,6 +17,7 @@ limitations under the License.
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -26,7 +27,9 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/turbinelabs/api/http/envelope"
 	httperr "github.com/turbinelabs/api/http/error"
 	tbnheader "github.com/turbinelabs/api/http/header"
@@ -246,3 +249,238 @@ func TestDoWithPayload(t *testing.T) {
 	assert.Nil(t, err)
 	assert.DeepEqual(t, payload, expectedPayload)
 }
+
+func TestDoContextCanceled(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-done
+		}),
+	)
+	defer server.Close()
+	defer close(done)
+
+	rh := NewRequestHandler(http.DefaultClient)
+
+	mkReq := func() (*http.Request, error) {
+		return http.NewRequest("GET", server.URL, nil)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := rh.DoContext(ctx, mkReq, nil)
+	assert.ErrorContains(t, err, "could not successfully make request")
+	assert.ErrorContains(t, err, context.DeadlineExceeded.Error())
+}
+
+func TestDoContextMkReqError(t *testing.T) {
+	rh := NewRequestHandler(http.DefaultClient)
+	assert.ErrorContains(
+		t,
+		rh.DoContext(
+			context.Background(),
+			func() (*http.Request, error) {
+				return nil, errors.New("boom")
+			},
+			nil,
+		),
+		"could not create request: boom",
+	)
+}
+
+func TestNewRetryingRequestHandler(t *testing.T) {
+	policy := NewBackoffRetryPolicy(3, time.Millisecond, time.Second, 0.0)
+	rh := NewRetryingRequestHandler(http.DefaultClient, policy)
+	assert.SameInstance(t, rh.client, http.DefaultClient)
+	assert.SameInstance(t, rh.retryPolicy, policy)
+
+	rh = NewRetryingRequestHandler(http.DefaultClient, nil)
+	assert.Equal(t, rh.retryPolicy, NoRetries())
+}
+
+type recordingSleeper struct {
+	delays []time.Duration
+}
+
+func (rs *recordingSleeper) sleep(ctx context.Context, d time.Duration) error {
+	rs.delays = append(rs.delays, d)
+	return ctx.Err()
+}
+
+func TestDoRetries(t *testing.T) {
+	expectedPayload := &testPayload{N: 99}
+
+	requests := 0
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			switch requests {
+			case 1:
+				w.WriteHeader(http.StatusServiceUnavailable)
+			case 2:
+				w.Header().Set("Retry-After", "2")
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintln(w, mkBody(t, httperr.New400("busy", httperr.MiscBackpressure), nil))
+			default:
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprintln(w, mkBody(t, nil, expectedPayload))
+			}
+		}),
+	)
+	defer server.Close()
+
+	sleeper := &recordingSleeper{}
+	rh := NewRetryingRequestHandler(
+		http.DefaultClient,
+		NewBackoffRetryPolicy(3, 10*time.Millisecond, 5*time.Second, 0.0),
+	)
+	rh.sleep = sleeper.sleep
+
+	mkReq := func() (*http.Request, error) {
+		return http.NewRequest("GET", server.URL, nil)
+	}
+
+	payload := &testPayload{}
+	assert.Nil(t, rh.Do(mkReq, payload))
+	assert.DeepEqual(t, payload, expectedPayload)
+	assert.Equal(t, requests, 3)
+	assert.ArrayEqual(t, sleeper.delays, []time.Duration{10 * time.Millisecond, 2 * time.Second})
+}
+
+func TestDoRetryAfterExceedsMaxDelay(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("Retry-After", "86400")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}),
+	)
+	defer server.Close()
+
+	sleeper := &recordingSleeper{}
+	rh := NewRetryingRequestHandler(
+		http.DefaultClient,
+		NewBackoffRetryPolicy(3, 10*time.Millisecond, time.Second, 0.0),
+	)
+	rh.sleep = sleeper.sleep
+
+	mkReq := func() (*http.Request, error) {
+		return http.NewRequest("GET", server.URL, nil)
+	}
+
+	err := rh.Do(mkReq, &testPayload{})
+	assert.NonNil(t, err)
+	assert.Equal(t, requests, 1)
+	assert.Equal(t, len(sleeper.delays), 0)
+}
+
+func TestDoRetriesExhausted(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusBadGateway)
+		}),
+	)
+	defer server.Close()
+
+	sleeper := &recordingSleeper{}
+	rh := NewRetryingRequestHandler(
+		http.DefaultClient,
+		NewBackoffRetryPolicy(3, 10*time.Millisecond, time.Second, 0.0),
+	)
+	rh.sleep = sleeper.sleep
+
+	mkReq := func() (*http.Request, error) {
+		return http.NewRequest("GET", server.URL, nil)
+	}
+
+	err := rh.Do(mkReq, nil)
+	assert.DeepEqual(t, err, mkNoErrMessageErr(server.URL, http.StatusBadGateway))
+	assert.Equal(t, requests, 3)
+	assert.Equal(t, len(sleeper.delays), 2)
+}
+
+func TestDoRetryPolicyAttempts(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	requests := 0
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}),
+	)
+	defer server.Close()
+
+	policy := NewMockRetryPolicy(ctrl)
+	policy.EXPECT().
+		Retry(
+			Attempt{
+				Number:     1,
+				Method:     "POST",
+				StatusCode: http.StatusServiceUnavailable,
+				Err:        mkNoErrMessageErr(server.URL, http.StatusServiceUnavailable),
+			},
+		).
+		Return(time.Duration(0), false)
+
+	rh := NewRetryingRequestHandler(http.DefaultClient, policy)
+
+	mkReq := func() (*http.Request, error) {
+		return http.NewRequest("POST", server.URL, strings.NewReader("{}"))
+	}
+
+	assert.NonNil(t, rh.Do(mkReq, nil))
+	assert.Equal(t, requests, 1)
+}
+
+func TestDoRetriesUnsentPost(t *testing.T) {
+	sleeper := &recordingSleeper{}
+	rh := NewRetryingRequestHandler(
+		http.DefaultClient,
+		NewBackoffRetryPolicy(2, 10*time.Millisecond, time.Second, 0.0),
+	)
+	rh.sleep = sleeper.sleep
+
+	attempts := 0
+	mkReq := func() (*http.Request, error) {
+		attempts++
+		return http.NewRequest("POST", "http://127.0.0.1:1/nope", strings.NewReader("{}"))
+	}
+
+	err := rh.Do(mkReq, nil)
+	assert.ErrorContains(t, err, "could not successfully make request")
+	assert.Equal(t, attempts, 2)
+	assert.ArrayEqual(t, sleeper.delays, []time.Duration{10 * time.Millisecond})
+}
+
+func TestDoRetryContextCanceled(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}),
+	)
+	defer server.Close()
+
+	rh := NewRetryingRequestHandler(
+		http.DefaultClient,
+		NewBackoffRetryPolicy(5, time.Minute, time.Minute, 0.0),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	mkReq := func() (*http.Request, error) {
+		return http.NewRequest("GET", server.URL, nil)
+	}
+
+	err := rh.DoContext(ctx, mkReq, nil)
+	assert.DeepEqual(t, err, mkNoErrMessageErr(server.URL, http.StatusServiceUnavailable))
+	assert.Equal(t, requests, 1)
+}
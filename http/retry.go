@@ -0,0 +1,208 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+//go:generate mockgen -source $GOFILE -destination mock_$GOFILE -package $GOPACKAGE --write_package_comment=false
+
+import (
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	httperr "github.com/turbinelabs/api/http/error"
+)
+
+// Attempt describes the outcome of a single failed request attempt made by a
+// RequestHandler.
+type Attempt struct {
+	// Number is the number of attempts made so far, starting at 1.
+	Number int
+
+	// Method is the HTTP method of the request.
+	Method string
+
+	// StatusCode is the HTTP status of the response, or 0 if no response was
+	// received.
+	StatusCode int
+
+	// RetryAfter is the delay requested by the server via the Retry-After
+	// header, or 0 if none was given.
+	RetryAfter time.Duration
+
+	// Err is the error produced by the attempt. If a response was received
+	// it is an *httperr.Error, otherwise it is the error returned by the
+	// underlying net/http.Client.
+	Err error
+}
+
+// Code returns the httperr.ErrorCode of the Attempt's error, or the empty
+// string if the server did not return an *httperr.Error.
+func (a Attempt) Code() httperr.ErrorCode {
+	if herr, ok := a.Err.(*httperr.Error); ok {
+		return herr.Code
+	}
+	return ""
+}
+
+// Sent returns false if the request is known never to have reached the
+// server (e.g. the connection could not be established), and true otherwise.
+func (a Attempt) Sent() bool {
+	if a.StatusCode != 0 {
+		return true
+	}
+
+	err := a.Err
+	if uerr, ok := err.(*url.Error); ok {
+		err = uerr.Err
+	}
+
+	switch e := err.(type) {
+	case *net.OpError:
+		return e.Op != "dial"
+	case *net.DNSError:
+		return false
+	}
+
+	return true
+}
+
+// Retryable reports whether the failure described by the Attempt is
+// transient and may be safely retried. Requests that never reached the
+// server are always retryable. Otherwise, only safe requests (GET, HEAD and
+// OPTIONS) are retried, and only if the failure was a transport error, a
+// 502, 503 or 504 response, or a response with the httperr.MiscBackpressure
+// code. A DELETE that reached the server is not retried: if its response
+// was lost, the retry would fail with a spurious not found or checksum
+// error.
+func Retryable(a Attempt) bool {
+	if !a.Sent() {
+		return true
+	}
+
+	switch strings.ToUpper(a.Method) {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		return false
+	}
+
+	switch a.StatusCode {
+	case 0, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return a.Code() == httperr.MiscBackpressure
+}
+
+// RetryPolicy determines whether, and after what delay, a failed request
+// attempt is retried.
+type RetryPolicy interface {
+	// Retry is invoked after each failed attempt. It returns the delay
+	// before the next attempt and true if the request should be retried,
+	// or false if the failure should be returned to the caller.
+	Retry(a Attempt) (time.Duration, bool)
+}
+
+// NoRetries returns a RetryPolicy that never retries.
+func NoRetries() RetryPolicy {
+	return noRetries{}
+}
+
+type noRetries struct{}
+
+func (noRetries) Retry(Attempt) (time.Duration, bool) { return 0, false }
+
+// NewBackoffRetryPolicy returns a RetryPolicy that retries Retryable
+// failures up to a total of maxAttempts attempts. The delay before the nth
+// retry is initialDelay * 2^(n-1), capped at maxDelay, and then reduced by a
+// random fraction of up to jitter (0.0 to 1.0) of itself. If the server
+// supplied a longer delay via the Retry-After header, that delay is used
+// instead, unless it exceeds maxDelay, in which case the failure is returned
+// to the caller rather than waiting.
+func NewBackoffRetryPolicy(
+	maxAttempts int,
+	initialDelay time.Duration,
+	maxDelay time.Duration,
+	jitter float64,
+) RetryPolicy {
+	return &backoffRetryPolicy{
+		maxAttempts:  maxAttempts,
+		initialDelay: initialDelay,
+		maxDelay:     maxDelay,
+		jitter:       math.Max(0.0, math.Min(1.0, jitter)),
+		random:       rand.Float64,
+	}
+}
+
+type backoffRetryPolicy struct {
+	maxAttempts  int
+	initialDelay time.Duration
+	maxDelay     time.Duration
+	jitter       float64
+	random       func() float64
+}
+
+func (p *backoffRetryPolicy) Retry(a Attempt) (time.Duration, bool) {
+	if a.Number >= p.maxAttempts || !Retryable(a) || a.RetryAfter > p.maxDelay {
+		return 0, false
+	}
+
+	delay := p.initialDelay
+	for i := 1; i < a.Number && delay < p.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.maxDelay {
+		delay = p.maxDelay
+	}
+
+	if p.jitter > 0 {
+		delay -= time.Duration(p.random() * p.jitter * float64(delay))
+	}
+
+	if a.RetryAfter > delay {
+		delay = a.RetryAfter
+	}
+
+	return delay, true
+}
+
+// parseRetryAfter interprets the value of a Retry-After header, which may be
+// either a number of seconds or an HTTP date. Returns 0 if the value is
+// missing or malformed.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+
+	return 0
+}
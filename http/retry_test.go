@@ -0,0 +1,207 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/test/assert"
+)
+
+var (
+	dialErr = &url.Error{
+		Op:  "Post",
+		URL: "http://127.0.0.1:1/",
+		Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+	}
+
+	readErr = &url.Error{
+		Op:  "Get",
+		URL: "http://127.0.0.1:1/",
+		Err: &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")},
+	}
+)
+
+func TestAttemptCode(t *testing.T) {
+	a := Attempt{Err: httperr.New500("slow down", httperr.MiscBackpressure)}
+	assert.Equal(t, a.Code(), httperr.MiscBackpressure)
+
+	a = Attempt{Err: errors.New("boom")}
+	assert.Equal(t, a.Code(), httperr.ErrorCode(""))
+}
+
+func TestAttemptSent(t *testing.T) {
+	assert.False(t, Attempt{Err: dialErr}.Sent())
+	assert.False(t, Attempt{Err: &net.DNSError{Err: "no such host"}}.Sent())
+	assert.True(t, Attempt{Err: readErr}.Sent())
+	assert.True(t, Attempt{Err: errors.New("boom")}.Sent())
+	assert.True(t, Attempt{StatusCode: 503, Err: dialErr}.Sent())
+}
+
+func TestRetryable(t *testing.T) {
+	backpressure := httperr.New400("slow down", httperr.MiscBackpressure)
+	notFound := httperr.New404("nope", httperr.NotFoundErrorCode)
+
+	testCases := []struct {
+		attempt  Attempt
+		expected bool
+	}{
+		{Attempt{Method: "GET", Err: dialErr}, true},
+		{Attempt{Method: "POST", Err: dialErr}, true},
+		{Attempt{Method: "GET", Err: readErr}, true},
+		{Attempt{Method: "DELETE", Err: dialErr}, true},
+		{Attempt{Method: "DELETE", Err: readErr}, false},
+		{Attempt{Method: "DELETE", StatusCode: 502, Err: notFound}, false},
+		{Attempt{Method: "POST", Err: readErr}, false},
+		{Attempt{Method: "PUT", Err: readErr}, false},
+		{Attempt{Method: "GET", StatusCode: 502, Err: notFound}, true},
+		{Attempt{Method: "GET", StatusCode: 503, Err: notFound}, true},
+		{Attempt{Method: "GET", StatusCode: 504, Err: notFound}, true},
+		{Attempt{Method: "POST", StatusCode: 503, Err: notFound}, false},
+		{Attempt{Method: "GET", StatusCode: 500, Err: notFound}, false},
+		{Attempt{Method: "GET", StatusCode: 404, Err: notFound}, false},
+		{Attempt{Method: "GET", StatusCode: 400, Err: backpressure}, true},
+		{Attempt{Method: "head", StatusCode: 400, Err: backpressure}, true},
+		{Attempt{Method: "POST", StatusCode: 400, Err: backpressure}, false},
+	}
+
+	for i, tc := range testCases {
+		assert.Group(
+			tc.attempt.Method,
+			t,
+			func(g *assert.G) {
+				if !assert.Equal(g, Retryable(tc.attempt), tc.expected) {
+					g.Errorf("test case %d: %+v", i, tc.attempt)
+				}
+			},
+		)
+	}
+}
+
+func TestNoRetries(t *testing.T) {
+	delay, retry := NoRetries().Retry(Attempt{Number: 1, Method: "GET", Err: dialErr})
+	assert.Equal(t, delay, time.Duration(0))
+	assert.False(t, retry)
+}
+
+func TestBackoffRetryPolicy(t *testing.T) {
+	policy := NewBackoffRetryPolicy(5, 100*time.Millisecond, 300*time.Millisecond, 0.0)
+
+	a := Attempt{Method: "GET", StatusCode: 503, Err: errors.New("unavailable")}
+
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		300 * time.Millisecond,
+		300 * time.Millisecond,
+	}
+	for i, want := range expected {
+		a.Number = i + 1
+		delay, retry := policy.Retry(a)
+		assert.True(t, retry)
+		assert.Equal(t, delay, want)
+	}
+
+	a.Number = 5
+	_, retry := policy.Retry(a)
+	assert.False(t, retry)
+
+	a.Number = 1
+	a.Method = "POST"
+	_, retry = policy.Retry(a)
+	assert.False(t, retry)
+}
+
+func TestBackoffRetryPolicyJitter(t *testing.T) {
+	policy := NewBackoffRetryPolicy(3, 100*time.Millisecond, time.Second, 2.0)
+	impl := policy.(*backoffRetryPolicy)
+	assert.Equal(t, impl.jitter, 1.0)
+
+	impl.jitter = 0.5
+	impl.random = func() float64 { return 0.5 }
+
+	delay, retry := policy.Retry(Attempt{Number: 2, Method: "GET", Err: readErr})
+	assert.True(t, retry)
+	assert.Equal(t, delay, 150*time.Millisecond)
+}
+
+func TestBackoffRetryPolicyRetryAfter(t *testing.T) {
+	policy := NewBackoffRetryPolicy(3, 100*time.Millisecond, time.Second, 0.0)
+
+	a := Attempt{
+		Number:     1,
+		Method:     "GET",
+		StatusCode: 503,
+		RetryAfter: 500 * time.Millisecond,
+		Err:        errors.New("unavailable"),
+	}
+
+	delay, retry := policy.Retry(a)
+	assert.True(t, retry)
+	assert.Equal(t, delay, 500*time.Millisecond)
+
+	a.RetryAfter = time.Second
+	delay, retry = policy.Retry(a)
+	assert.True(t, retry)
+	assert.Equal(t, delay, time.Second)
+
+	a.RetryAfter = time.Millisecond
+	delay, retry = policy.Retry(a)
+	assert.True(t, retry)
+	assert.Equal(t, delay, 100*time.Millisecond)
+}
+
+func TestBackoffRetryPolicyRetryAfterExceedsMaxDelay(t *testing.T) {
+	policy := NewBackoffRetryPolicy(3, 100*time.Millisecond, time.Second, 0.0)
+
+	a := Attempt{
+		Number:     1,
+		Method:     "GET",
+		StatusCode: 503,
+		RetryAfter: 24 * time.Hour,
+		Err:        errors.New("unavailable"),
+	}
+
+	delay, retry := policy.Retry(a)
+	assert.False(t, retry)
+	assert.Equal(t, delay, time.Duration(0))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	assert.Equal(t, parseRetryAfter("", now), time.Duration(0))
+	assert.Equal(t, parseRetryAfter(" 5 ", now), 5*time.Second)
+	assert.Equal(t, parseRetryAfter("-5", now), time.Duration(0))
+	assert.Equal(t, parseRetryAfter("soon", now), time.Duration(0))
+	assert.Equal(
+		t,
+		parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now),
+		time.Minute,
+	)
+	assert.Equal(
+		t,
+		parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now),
+		time.Duration(0),
+	)
+}
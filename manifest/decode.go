@@ -0,0 +1,282 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Error is returned by Decode to describe a problem with a manifest. Line
+// and Column are 1-based, and are zero if the position is unknown.
+type Error struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Line == 0:
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	case e.Column == 0:
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	default:
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+	}
+}
+
+// ReadFile reads and decodes the manifest in the named file.
+func ReadFile(filename string) (*Manifest, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Decode(filename, f)
+}
+
+// Decode reads a YAML or JSON manifest from r. The filename is used only in
+// error messages. Returns an *Error if the manifest is malformed, contains
+// unknown fields, contains duplicate objects, or refers to objects it does
+// not contain.
+func Decode(filename string, r io.Reader) (*Manifest, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := parse(data)
+	if err != nil {
+		if se, ok := err.(*syntaxError); ok {
+			return nil, &Error{filename, se.line, se.col, se.msg}
+		}
+		return nil, err
+	}
+
+	if root.kind != mappingNode {
+		return nil, &Error{filename, root.line, root.col, "manifest must be a mapping"}
+	}
+
+	buf := &bytes.Buffer{}
+	positions := []position{}
+	root.toJSON(buf, &positions)
+
+	m := &Manifest{}
+	dec := json.NewDecoder(buf)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(m); err != nil {
+		return nil, decodeError(filename, root, positions, err)
+	}
+
+	if e := m.validate(root); e != nil {
+		e.File = filename
+		return nil, e
+	}
+
+	return m, nil
+}
+
+// decodeError converts an error from encoding/json into an *Error, using
+// the recorded positions to locate it in the source document.
+func decodeError(filename string, root *node, positions []position, err error) *Error {
+	e := &Error{File: filename, Message: err.Error()}
+
+	switch jerr := err.(type) {
+	case *json.UnmarshalTypeError:
+		// Offset is the end of the offending value; find the last value
+		// that starts before it.
+		for _, p := range positions {
+			if int64(p.offset) >= jerr.Offset {
+				break
+			}
+			e.Line, e.Column = p.line, p.col
+		}
+		e.Message = fmt.Sprintf("cannot use %s as %s", jerr.Value, jerr.Type.String())
+
+	default:
+		const unknownField = "json: unknown field "
+		msg := err.Error()
+		if strings.HasPrefix(msg, unknownField) {
+			name, uerr := strconv.Unquote(strings.TrimPrefix(msg, unknownField))
+			if uerr == nil {
+				e.Message = fmt.Sprintf("unknown field %q", name)
+				if k := root.find(name); k != nil {
+					e.Line, e.Column = k.line, k.col
+				}
+			}
+		} else {
+			e.Message = strings.TrimPrefix(msg, "json: ")
+		}
+	}
+
+	return e
+}
+
+// validator accumulates the first validation error found in a Manifest.
+type validator struct {
+	err *Error
+}
+
+func (v *validator) errorf(n *node, format string, args ...interface{}) {
+	if v.err != nil {
+		return
+	}
+	v.err = &Error{Message: fmt.Sprintf(format, args...)}
+	if n != nil {
+		v.err.Line, v.err.Column = n.line, n.col
+	}
+}
+
+// at returns the node at the given path of mapping keys and sequence
+// indices below n, or the deepest node found.
+func at(n *node, path ...interface{}) *node {
+	for _, p := range path {
+		var next *node
+		switch p := p.(type) {
+		case string:
+			next = n.get(p)
+		case int:
+			next = n.index(p)
+		}
+		if next == nil {
+			return n
+		}
+		n = next
+	}
+	return n
+}
+
+// validate checks that the Manifest names its Zone, contains no duplicate
+// objects, and refers only to objects it contains. The root node is used to
+// locate errors.
+func (m *Manifest) validate(root *node) *Error {
+	v := &validator{}
+
+	if m.Zone == "" {
+		v.errorf(at(root, "zone"), "zone name must be non-empty")
+	}
+
+	clusters := map[string]bool{}
+	for i, c := range m.Clusters {
+		if clusters[c.Name] {
+			v.errorf(at(root, "clusters", i, "name"), "duplicate cluster %q", c.Name)
+		}
+		clusters[c.Name] = true
+	}
+
+	domains := map[string]bool{}
+	for i, d := range m.Domains {
+		name := fmt.Sprintf("%s:%d", d.Name, d.Port)
+		if domains[name] {
+			v.errorf(at(root, "domains", i, "name"), "duplicate domain %q", name)
+		}
+		domains[name] = true
+	}
+
+	listeners := map[string]bool{}
+	for i, l := range m.Listeners {
+		if listeners[l.Name] {
+			v.errorf(at(root, "listeners", i, "name"), "duplicate listener %q", l.Name)
+		}
+		listeners[l.Name] = true
+		for j, d := range l.Domains {
+			if !domains[d] {
+				v.errorf(at(root, "listeners", i, "domains", j), "unknown domain %q", d)
+			}
+		}
+	}
+
+	proxies := map[string]bool{}
+	for i, p := range m.Proxies {
+		if proxies[p.Name] {
+			v.errorf(at(root, "proxies", i, "name"), "duplicate proxy %q", p.Name)
+		}
+		proxies[p.Name] = true
+		for j, d := range p.Domains {
+			if !domains[d] {
+				v.errorf(at(root, "proxies", i, "domains", j), "unknown domain %q", d)
+			}
+		}
+		for j, l := range p.Listeners {
+			if !listeners[l] {
+				v.errorf(at(root, "proxies", i, "listeners", j), "unknown listener %q", l)
+			}
+		}
+	}
+
+	checkConstraints := func(ac AllConstraints, path ...interface{}) {
+		for _, kind := range []string{"light", "dark", "tap"} {
+			ccs := ac.Light
+			switch kind {
+			case "dark":
+				ccs = ac.Dark
+			case "tap":
+				ccs = ac.Tap
+			}
+			for i, cc := range ccs {
+				if !clusters[cc.Cluster] {
+					p := append(append([]interface{}{}, path...), kind, i, "cluster")
+					v.errorf(at(root, p...), "unknown cluster %q", cc.Cluster)
+				}
+			}
+		}
+	}
+
+	checkRules := func(rules []Rule, path ...interface{}) {
+		for i, r := range rules {
+			p := append(append([]interface{}{}, path...), "rules", i, "constraints")
+			checkConstraints(r.Constraints, p...)
+		}
+	}
+
+	sharedRules := map[string]bool{}
+	for i, sr := range m.SharedRules {
+		if sharedRules[sr.Name] {
+			v.errorf(at(root, "shared_rules", i, "name"), "duplicate shared_rules %q", sr.Name)
+		}
+		sharedRules[sr.Name] = true
+		checkConstraints(sr.Default, "shared_rules", i, "default")
+		checkRules(sr.Rules, "shared_rules", i)
+	}
+
+	routes := map[string]bool{}
+	for i, r := range m.Routes {
+		name := r.Domain + r.Path
+		if routes[name] {
+			v.errorf(at(root, "routes", i, "path"), "duplicate route %q", name)
+		}
+		routes[name] = true
+		if !domains[r.Domain] {
+			v.errorf(at(root, "routes", i, "domain"), "unknown domain %q", r.Domain)
+		}
+		if !sharedRules[r.SharedRules] {
+			v.errorf(at(root, "routes", i, "shared_rules"), "unknown shared_rules %q", r.SharedRules)
+		}
+		checkRules(r.Rules, "routes", i)
+	}
+
+	return v.err
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// EncodeJSON writes the Manifest to w as indented JSON.
+func EncodeJSON(w io.Writer, m *Manifest) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	_, err = w.Write(b)
+	return err
+}
+
+// EncodeYAML writes the Manifest to w as YAML. Fields appear in the same
+// order as in the JSON encoding.
+func EncodeYAML(w io.Writer, m *Manifest) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	root, err := parse(b)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	writeYAML(bw, root, 0, false)
+	return bw.Flush()
+}
+
+// writeYAML writes a node as YAML at the given indentation. If inline is
+// true, the first line of the node continues a line already begun (after a
+// sequence entry indicator).
+func writeYAML(w *bufio.Writer, n *node, indent int, inline bool) {
+	pad := strings.Repeat(" ", indent)
+
+	switch {
+	case n.kind == mappingNode && len(n.keys) > 0:
+		for i, k := range n.keys {
+			if i > 0 || !inline {
+				w.WriteString(pad)
+			}
+			w.WriteString(yamlScalar(k))
+			w.WriteByte(':')
+			writeValue(w, n.items[i], indent)
+		}
+
+	case n.kind == sequenceNode && len(n.items) > 0:
+		for i, item := range n.items {
+			if i > 0 || !inline {
+				w.WriteString(pad)
+			}
+			w.WriteString("- ")
+			if isCollection(item) {
+				writeYAML(w, item, indent+2, true)
+			} else {
+				w.WriteString(yamlScalar(item))
+				w.WriteByte('\n')
+			}
+		}
+
+	default:
+		w.WriteString(yamlScalar(n))
+		w.WriteByte('\n')
+	}
+}
+
+// writeValue writes a mapping value following its key, which is at the
+// given indentation. Sequences are not indented relative to their key.
+func writeValue(w *bufio.Writer, n *node, indent int) {
+	if isCollection(n) {
+		w.WriteByte('\n')
+		if n.kind == mappingNode {
+			indent += 2
+		}
+		writeYAML(w, n, indent, false)
+		return
+	}
+	w.WriteByte(' ')
+	w.WriteString(yamlScalar(n))
+	w.WriteByte('\n')
+}
+
+// isCollection returns true for non-empty mappings and sequences.
+func isCollection(n *node) bool {
+	return n.kind != scalarNode && len(n.items) > 0
+}
+
+// yamlScalar returns the YAML representation of a scalar or empty
+// collection node parsed from JSON. Strings are written plainly unless they
+// would be read back as something else, in which case they are written as
+// JSON strings, which are valid YAML double-quoted scalars.
+func yamlScalar(n *node) string {
+	switch {
+	case n.kind == mappingNode:
+		return "{}"
+	case n.kind == sequenceNode:
+		return "[]"
+	case !n.quoted:
+		return n.value
+	}
+
+	plain := &node{kind: scalarNode, value: n.value}
+	quoted := &node{kind: scalarNode, value: n.value, quoted: true}
+	if isPlainSafe(n.value) && plain.scalarJSON() == quoted.scalarJSON() {
+		return n.value
+	}
+	return quoted.scalarJSON()
+}
+
+// isPlainSafe returns true if s may be written as a plain scalar in block
+// context and read back unchanged.
+func isPlainSafe(s string) bool {
+	if s == "" || s != strings.TrimSpace(s) {
+		return false
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return false
+	}
+	if strings.HasSuffix(s, ":") || strings.Contains(s, ": ") || strings.Contains(s, " #") {
+		return false
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,677 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package manifest defines a portable YAML or JSON file format describing
+// the routing objects of a single Zone. Unlike the api types, manifest
+// objects carry no server-assigned keys, OrgKeys, or Checksums; objects
+// refer to one another by name:
+//
+// 	zone: production
+// 	clusters:
+// 	- name: api
+// 	  instances:
+// 	  - host: 10.0.0.1
+// 	    port: 8080
+// 	domains:
+// 	- name: example.com
+// 	  port: 80
+// 	shared_rules:
+// 	- name: api-rules
+// 	  default:
+// 	    light:
+// 	    - cluster: api
+// 	      weight: 1
+// 	routes:
+// 	- domain: example.com:80
+// 	  path: /
+// 	  shared_rules: api-rules
+//
+// Domains are referred to as "name:port". Manifests are read with Decode,
+// which accepts either YAML or JSON and reports errors with their file and
+// line positions, and written with EncodeYAML or EncodeJSON. A Manifest
+// converts losslessly to and from a reconcile.State, which may be used to
+// apply it to a Zone.
+//
+// Manifests may use the following subset of YAML 1.2:
+//
+// 	- a single document, optionally starting with "---" and ending with "..."
+// 	- block mappings and sequences, indented with spaces; tabs are not
+// 	  allowed in indentation
+// 	- flow mappings and sequences ("{a: 1}", "[1, 2]"), and therefore JSON
+// 	- plain, single-quoted, and double-quoted scalars, which may not span
+// 	  multiple lines; unquoted null, true, false, and numbers are
+// 	  interpreted as in the YAML core schema
+// 	- comments beginning with "#"
+//
+// Anchors and aliases, tags, block scalars ("|" and ">"), complex ("?")
+// keys, directives, and multiple documents are not supported and produce
+// errors.
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service/reconcile"
+)
+
+// Manifest describes the routing objects of a Zone.
+type Manifest struct {
+	Zone        string        `json:"zone"`
+	Clusters    []Cluster     `json:"clusters,omitempty"`
+	Domains     []Domain      `json:"domains,omitempty"`
+	Listeners   []Listener    `json:"listeners,omitempty"`
+	Proxies     []Proxy       `json:"proxies,omitempty"`
+	SharedRules []SharedRules `json:"shared_rules,omitempty"`
+	Routes      []Route       `json:"routes,omitempty"`
+}
+
+// Cluster describes an api.Cluster.
+type Cluster struct {
+	Name             string                `json:"name"`
+	RequireTLS       bool                  `json:"require_tls,omitempty"`
+	Instances        api.Instances         `json:"instances,omitempty"`
+	CircuitBreakers  *api.CircuitBreakers  `json:"circuit_breakers,omitempty"`
+	OutlierDetection *api.OutlierDetection `json:"outlier_detection,omitempty"`
+	HealthChecks     api.HealthChecks      `json:"health_checks,omitempty"`
+}
+
+// Domain describes an api.Domain. It is referred to as "name:port".
+type Domain struct {
+	Name            string               `json:"name"`
+	Port            int                  `json:"port"`
+	SSLConfig       *api.SSLConfig       `json:"ssl_config,omitempty"`
+	Redirects       api.Redirects        `json:"redirects,omitempty"`
+	GzipEnabled     bool                 `json:"gzip_enabled,omitempty"`
+	CorsConfig      *api.CorsConfig      `json:"cors_config,omitempty"`
+	Aliases         api.DomainAliases    `json:"aliases,omitempty"`
+	RateLimitPolicy *api.RateLimitPolicy `json:"rate_limit_policy,omitempty"`
+	ForceHTTPS      bool                 `json:"force_https,omitempty"`
+}
+
+// Listener describes an api.Listener. Domains holds Domain "name:port"
+// values.
+type Listener struct {
+	Name          string               `json:"name"`
+	IP            string               `json:"ip"`
+	Port          int                  `json:"port"`
+	Protocol      api.ListenerProtocol `json:"protocol"`
+	Domains       []string             `json:"domains,omitempty"`
+	TracingConfig *api.TracingConfig   `json:"tracing_config,omitempty"`
+}
+
+// Proxy describes an api.Proxy. Domains holds Domain "name:port" values and
+// Listeners holds Listener names.
+type Proxy struct {
+	Name      string   `json:"name"`
+	Domains   []string `json:"domains,omitempty"`
+	Listeners []string `json:"listeners,omitempty"`
+}
+
+// SharedRules describes an api.SharedRules.
+type SharedRules struct {
+	Name           string              `json:"name"`
+	Default        AllConstraints      `json:"default"`
+	Rules          []Rule              `json:"rules,omitempty"`
+	ResponseData   *api.ResponseData   `json:"response_data,omitempty"`
+	RequestData    *api.RequestData    `json:"request_data,omitempty"`
+	CohortSeed     *api.CohortSeed     `json:"cohort_seed,omitempty"`
+	Properties     api.Metadata        `json:"properties,omitempty"`
+	RetryPolicy    *api.RetryPolicy    `json:"retry_policy,omitempty"`
+	FaultInjection *api.FaultInjection `json:"fault_injection,omitempty"`
+}
+
+// Route describes an api.Route. Domain holds a Domain "name:port" value and
+// SharedRules holds a SharedRules name.
+type Route struct {
+	Domain          string               `json:"domain"`
+	Path            string               `json:"path"`
+	SharedRules     string               `json:"shared_rules"`
+	Rules           []Rule               `json:"rules,omitempty"`
+	ResponseData    *api.ResponseData    `json:"response_data,omitempty"`
+	RequestData     *api.RequestData     `json:"request_data,omitempty"`
+	CohortSeed      *api.CohortSeed      `json:"cohort_seed,omitempty"`
+	RetryPolicy     *api.RetryPolicy     `json:"retry_policy,omitempty"`
+	FaultInjection  *api.FaultInjection  `json:"fault_injection,omitempty"`
+	RateLimitPolicy *api.RateLimitPolicy `json:"rate_limit_policy,omitempty"`
+}
+
+// Rule describes an api.Rule. If Key is empty, one is assigned when the
+// Manifest is applied.
+type Rule struct {
+	Key             api.RuleKey          `json:"key,omitempty"`
+	Methods         []string             `json:"methods,omitempty"`
+	Matches         api.Matches          `json:"matches,omitempty"`
+	Constraints     AllConstraints       `json:"constraints"`
+	CohortSeed      *api.CohortSeed      `json:"cohort_seed,omitempty"`
+	FaultInjection  *api.FaultInjection  `json:"fault_injection,omitempty"`
+	RateLimitPolicy *api.RateLimitPolicy `json:"rate_limit_policy,omitempty"`
+}
+
+// AllConstraints describes an api.AllConstraints.
+type AllConstraints struct {
+	Light []ClusterConstraint `json:"light,omitempty"`
+	Dark  []ClusterConstraint `json:"dark,omitempty"`
+	Tap   []ClusterConstraint `json:"tap,omitempty"`
+}
+
+// ClusterConstraint describes an api.ClusterConstraint. Cluster holds a
+// Cluster name. If Key is empty, one is assigned when the Manifest is
+// applied.
+type ClusterConstraint struct {
+	Key          api.ConstraintKey `json:"key,omitempty"`
+	Cluster      string            `json:"cluster"`
+	Metadata     api.Metadata      `json:"metadata,omitempty"`
+	Properties   api.Metadata      `json:"properties,omitempty"`
+	ResponseData *api.ResponseData `json:"response_data,omitempty"`
+	RequestData  *api.RequestData  `json:"request_data,omitempty"`
+	Weight       uint32            `json:"weight"`
+}
+
+// Objects holds the routing objects of a Zone as returned by the API, which
+// refer to one another by key.
+type Objects struct {
+	Zone        api.Zone
+	Clusters    api.Clusters
+	Domains     api.Domains
+	Listeners   api.Listeners
+	Proxies     api.Proxies
+	SharedRules api.SharedRulesSlice
+	Routes      api.Routes
+}
+
+// FromObjects returns a Manifest describing the given Objects. Returns an
+// error if an object refers to a key not present in the Objects.
+func FromObjects(o Objects) (*Manifest, error) {
+	clusters := map[api.ClusterKey]string{}
+	for _, c := range o.Clusters {
+		clusters[c.ClusterKey] = c.Name
+	}
+
+	domains := map[api.DomainKey]api.DomainKey{}
+	for _, d := range o.Domains {
+		domains[d.DomainKey] = api.DomainKey(d.Addr())
+	}
+
+	listeners := map[api.ListenerKey]api.ListenerKey{}
+	for _, l := range o.Listeners {
+		listeners[l.ListenerKey] = api.ListenerKey(l.Name)
+	}
+
+	sharedRules := map[api.SharedRulesKey]api.SharedRulesKey{}
+	for _, sr := range o.SharedRules {
+		sharedRules[sr.SharedRulesKey] = api.SharedRulesKey(sr.Name)
+	}
+
+	nameConstraints := func(ccs api.ClusterConstraints) (api.ClusterConstraints, error) {
+		if ccs == nil {
+			return nil, nil
+		}
+		result := make(api.ClusterConstraints, len(ccs))
+		for i, cc := range ccs {
+			name, ok := clusters[cc.ClusterKey]
+			if !ok {
+				return nil, fmt.Errorf("unknown cluster key %q", cc.ClusterKey)
+			}
+			cc.ClusterKey = api.ClusterKey(name)
+			result[i] = cc
+		}
+		return result, nil
+	}
+
+	nameAllConstraints := func(ac api.AllConstraints) (api.AllConstraints, error) {
+		var err error
+		if ac.Light, err = nameConstraints(ac.Light); err != nil {
+			return ac, err
+		}
+		if ac.Dark, err = nameConstraints(ac.Dark); err != nil {
+			return ac, err
+		}
+		ac.Tap, err = nameConstraints(ac.Tap)
+		return ac, err
+	}
+
+	nameRules := func(rules api.Rules) (api.Rules, error) {
+		if rules == nil {
+			return nil, nil
+		}
+		result := make(api.Rules, len(rules))
+		for i, r := range rules {
+			var err error
+			if r.Constraints, err = nameAllConstraints(r.Constraints); err != nil {
+				return nil, fmt.Errorf("rule %q: %s", r.RuleKey, err.Error())
+			}
+			result[i] = r
+		}
+		return result, nil
+	}
+
+	nameDomains := func(dks []api.DomainKey) ([]api.DomainKey, error) {
+		if dks == nil {
+			return nil, nil
+		}
+		result := make([]api.DomainKey, len(dks))
+		for i, dk := range dks {
+			name, ok := domains[dk]
+			if !ok {
+				return nil, fmt.Errorf("unknown domain key %q", dk)
+			}
+			result[i] = name
+		}
+		return result, nil
+	}
+
+	s := reconcile.State{Zone: o.Zone, Clusters: o.Clusters, Domains: o.Domains}
+
+	for _, l := range o.Listeners {
+		var err error
+		if l.DomainKeys, err = nameDomains(l.DomainKeys); err != nil {
+			return nil, fmt.Errorf("listener %q: %s", l.Name, err.Error())
+		}
+		s.Listeners = append(s.Listeners, l)
+	}
+
+	for _, p := range o.Proxies {
+		var err error
+		if p.DomainKeys, err = nameDomains(p.DomainKeys); err != nil {
+			return nil, fmt.Errorf("proxy %q: %s", p.Name, err.Error())
+		}
+		lks := make([]api.ListenerKey, len(p.ListenerKeys))
+		for i, lk := range p.ListenerKeys {
+			name, ok := listeners[lk]
+			if !ok {
+				return nil, fmt.Errorf("proxy %q: unknown listener key %q", p.Name, lk)
+			}
+			lks[i] = name
+		}
+		p.ListenerKeys = lks
+		s.Proxies = append(s.Proxies, p)
+	}
+
+	for _, sr := range o.SharedRules {
+		var err error
+		if sr.Default, err = nameAllConstraints(sr.Default); err != nil {
+			return nil, fmt.Errorf("shared_rules %q: default: %s", sr.Name, err.Error())
+		}
+		if sr.Rules, err = nameRules(sr.Rules); err != nil {
+			return nil, fmt.Errorf("shared_rules %q: %s", sr.Name, err.Error())
+		}
+		s.SharedRules = append(s.SharedRules, sr)
+	}
+
+	for _, r := range o.Routes {
+		dn, ok := domains[r.DomainKey]
+		if !ok {
+			return nil, fmt.Errorf("route %q: unknown domain key %q", r.Path, r.DomainKey)
+		}
+		name := string(dn) + r.Path
+		r.DomainKey = dn
+
+		srn, ok := sharedRules[r.SharedRulesKey]
+		if !ok {
+			return nil, fmt.Errorf("route %q: unknown shared_rules key %q", name, r.SharedRulesKey)
+		}
+		r.SharedRulesKey = srn
+
+		var err error
+		if r.Rules, err = nameRules(r.Rules); err != nil {
+			return nil, fmt.Errorf("route %q: %s", name, err.Error())
+		}
+		s.Routes = append(s.Routes, r)
+	}
+
+	return FromState(s), nil
+}
+
+// FromState returns a Manifest describing the given reconcile.State.
+func FromState(s reconcile.State) *Manifest {
+	m := &Manifest{Zone: s.Zone.Name}
+
+	for _, c := range s.Clusters {
+		m.Clusters = append(
+			m.Clusters,
+			Cluster{
+				Name:             c.Name,
+				RequireTLS:       c.RequireTLS,
+				Instances:        c.Instances,
+				CircuitBreakers:  c.CircuitBreakers,
+				OutlierDetection: c.OutlierDetection,
+				HealthChecks:     c.HealthChecks,
+			},
+		)
+	}
+
+	for _, d := range s.Domains {
+		m.Domains = append(
+			m.Domains,
+			Domain{
+				Name:            d.Name,
+				Port:            d.Port,
+				SSLConfig:       d.SSLConfig,
+				Redirects:       d.Redirects,
+				GzipEnabled:     d.GzipEnabled,
+				CorsConfig:      d.CorsConfig,
+				Aliases:         d.Aliases,
+				RateLimitPolicy: d.RateLimitPolicy,
+				ForceHTTPS:      d.ForceHTTPS,
+			},
+		)
+	}
+
+	for _, l := range s.Listeners {
+		m.Listeners = append(
+			m.Listeners,
+			Listener{
+				Name:          l.Name,
+				IP:            l.IP,
+				Port:          l.Port,
+				Protocol:      l.Protocol,
+				Domains:       domainStrings(l.DomainKeys),
+				TracingConfig: l.TracingConfig,
+			},
+		)
+	}
+
+	for _, p := range s.Proxies {
+		var listeners []string
+		for _, lk := range p.ListenerKeys {
+			listeners = append(listeners, string(lk))
+		}
+		m.Proxies = append(
+			m.Proxies,
+			Proxy{
+				Name:      p.Name,
+				Domains:   domainStrings(p.DomainKeys),
+				Listeners: listeners,
+			},
+		)
+	}
+
+	for _, sr := range s.SharedRules {
+		m.SharedRules = append(
+			m.SharedRules,
+			SharedRules{
+				Name:           sr.Name,
+				Default:        fromAllConstraints(sr.Default),
+				Rules:          fromRules(sr.Rules),
+				ResponseData:   fromResponseData(sr.ResponseData),
+				RequestData:    fromRequestData(sr.RequestData),
+				CohortSeed:     sr.CohortSeed,
+				Properties:     sr.Properties,
+				RetryPolicy:    sr.RetryPolicy,
+				FaultInjection: sr.FaultInjection,
+			},
+		)
+	}
+
+	for _, r := range s.Routes {
+		m.Routes = append(
+			m.Routes,
+			Route{
+				Domain:          string(r.DomainKey),
+				Path:            r.Path,
+				SharedRules:     string(r.SharedRulesKey),
+				Rules:           fromRules(r.Rules),
+				ResponseData:    fromResponseData(r.ResponseData),
+				RequestData:     fromRequestData(r.RequestData),
+				CohortSeed:      r.CohortSeed,
+				RetryPolicy:     r.RetryPolicy,
+				FaultInjection:  r.FaultInjection,
+				RateLimitPolicy: r.RateLimitPolicy,
+			},
+		)
+	}
+
+	return m
+}
+
+// State returns the reconcile.State described by the Manifest.
+func (m *Manifest) State() reconcile.State {
+	s := reconcile.State{Zone: api.Zone{Name: m.Zone}}
+
+	for _, c := range m.Clusters {
+		s.Clusters = append(
+			s.Clusters,
+			api.Cluster{
+				Name:             c.Name,
+				RequireTLS:       c.RequireTLS,
+				Instances:        c.Instances,
+				CircuitBreakers:  c.CircuitBreakers,
+				OutlierDetection: c.OutlierDetection,
+				HealthChecks:     c.HealthChecks,
+			},
+		)
+	}
+
+	for _, d := range m.Domains {
+		s.Domains = append(
+			s.Domains,
+			api.Domain{
+				Name:            d.Name,
+				Port:            d.Port,
+				SSLConfig:       d.SSLConfig,
+				Redirects:       d.Redirects,
+				GzipEnabled:     d.GzipEnabled,
+				CorsConfig:      d.CorsConfig,
+				Aliases:         d.Aliases,
+				RateLimitPolicy: d.RateLimitPolicy,
+				ForceHTTPS:      d.ForceHTTPS,
+			},
+		)
+	}
+
+	for _, l := range m.Listeners {
+		s.Listeners = append(
+			s.Listeners,
+			api.Listener{
+				Name:          l.Name,
+				IP:            l.IP,
+				Port:          l.Port,
+				Protocol:      l.Protocol,
+				DomainKeys:    domainKeys(l.Domains),
+				TracingConfig: l.TracingConfig,
+			},
+		)
+	}
+
+	for _, p := range m.Proxies {
+		var listenerKeys []api.ListenerKey
+		for _, l := range p.Listeners {
+			listenerKeys = append(listenerKeys, api.ListenerKey(l))
+		}
+		s.Proxies = append(
+			s.Proxies,
+			api.Proxy{
+				Name:         p.Name,
+				DomainKeys:   domainKeys(p.Domains),
+				ListenerKeys: listenerKeys,
+			},
+		)
+	}
+
+	for _, sr := range m.SharedRules {
+		s.SharedRules = append(
+			s.SharedRules,
+			api.SharedRules{
+				Name:           sr.Name,
+				Default:        sr.Default.toAPI(),
+				Rules:          toRules(sr.Rules),
+				ResponseData:   toResponseData(sr.ResponseData),
+				RequestData:    toRequestData(sr.RequestData),
+				CohortSeed:     sr.CohortSeed,
+				Properties:     sr.Properties,
+				RetryPolicy:    sr.RetryPolicy,
+				FaultInjection: sr.FaultInjection,
+			},
+		)
+	}
+
+	for _, r := range m.Routes {
+		s.Routes = append(
+			s.Routes,
+			api.Route{
+				DomainKey:       api.DomainKey(r.Domain),
+				Path:            r.Path,
+				SharedRulesKey:  api.SharedRulesKey(r.SharedRules),
+				Rules:           toRules(r.Rules),
+				ResponseData:    toResponseData(r.ResponseData),
+				RequestData:     toRequestData(r.RequestData),
+				CohortSeed:      r.CohortSeed,
+				RetryPolicy:     r.RetryPolicy,
+				FaultInjection:  r.FaultInjection,
+				RateLimitPolicy: r.RateLimitPolicy,
+			},
+		)
+	}
+
+	return s
+}
+
+func domainStrings(dks []api.DomainKey) []string {
+	var result []string
+	for _, dk := range dks {
+		result = append(result, string(dk))
+	}
+	return result
+}
+
+func domainKeys(names []string) []api.DomainKey {
+	var result []api.DomainKey
+	for _, name := range names {
+		result = append(result, api.DomainKey(name))
+	}
+	return result
+}
+
+func fromResponseData(rd api.ResponseData) *api.ResponseData {
+	if len(rd.Headers) == 0 && len(rd.Cookies) == 0 {
+		return nil
+	}
+	return &rd
+}
+
+func toResponseData(rd *api.ResponseData) api.ResponseData {
+	if rd == nil {
+		return api.ResponseData{}
+	}
+	return *rd
+}
+
+func fromRequestData(rd api.RequestData) *api.RequestData {
+	if len(rd.Headers) == 0 {
+		return nil
+	}
+	return &rd
+}
+
+func toRequestData(rd *api.RequestData) api.RequestData {
+	if rd == nil {
+		return api.RequestData{}
+	}
+	return *rd
+}
+
+func fromConstraints(ccs api.ClusterConstraints) []ClusterConstraint {
+	var result []ClusterConstraint
+	for _, cc := range ccs {
+		result = append(
+			result,
+			ClusterConstraint{
+				Key:          cc.ConstraintKey,
+				Cluster:      string(cc.ClusterKey),
+				Metadata:     cc.Metadata,
+				Properties:   cc.Properties,
+				ResponseData: fromResponseData(cc.ResponseData),
+				RequestData:  fromRequestData(cc.RequestData),
+				Weight:       cc.Weight,
+			},
+		)
+	}
+	return result
+}
+
+func toConstraints(ccs []ClusterConstraint) api.ClusterConstraints {
+	var result api.ClusterConstraints
+	for _, cc := range ccs {
+		result = append(
+			result,
+			api.ClusterConstraint{
+				ConstraintKey: cc.Key,
+				ClusterKey:    api.ClusterKey(cc.Cluster),
+				Metadata:      cc.Metadata,
+				Properties:    cc.Properties,
+				ResponseData:  toResponseData(cc.ResponseData),
+				RequestData:   toRequestData(cc.RequestData),
+				Weight:        cc.Weight,
+			},
+		)
+	}
+	return result
+}
+
+func fromAllConstraints(ac api.AllConstraints) AllConstraints {
+	return AllConstraints{
+		Light: fromConstraints(ac.Light),
+		Dark:  fromConstraints(ac.Dark),
+		Tap:   fromConstraints(ac.Tap),
+	}
+}
+
+func (ac AllConstraints) toAPI() api.AllConstraints {
+	return api.AllConstraints{
+		Light: toConstraints(ac.Light),
+		Dark:  toConstraints(ac.Dark),
+		Tap:   toConstraints(ac.Tap),
+	}
+}
+
+func fromRules(rules api.Rules) []Rule {
+	var result []Rule
+	for _, r := range rules {
+		result = append(
+			result,
+			Rule{
+				Key:             r.RuleKey,
+				Methods:         r.Methods,
+				Matches:         r.Matches,
+				Constraints:     fromAllConstraints(r.Constraints),
+				CohortSeed:      r.CohortSeed,
+				FaultInjection:  r.FaultInjection,
+				RateLimitPolicy: r.RateLimitPolicy,
+			},
+		)
+	}
+	return result
+}
+
+func toRules(rules []Rule) api.Rules {
+	var result api.Rules
+	for _, r := range rules {
+		result = append(
+			result,
+			api.Rule{
+				RuleKey:         r.Key,
+				Methods:         r.Methods,
+				Matches:         r.Matches,
+				Constraints:     r.Constraints.toAPI(),
+				CohortSeed:      r.CohortSeed,
+				FaultInjection:  r.FaultInjection,
+				RateLimitPolicy: r.RateLimitPolicy,
+			},
+		)
+	}
+	return result
+}
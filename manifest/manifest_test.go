@@ -0,0 +1,335 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/api/service/memory"
+	"github.com/turbinelabs/api/service/reconcile"
+	"github.com/turbinelabs/test/assert"
+)
+
+const testYAML = `# the production zone
+zone: production
+clusters:
+- name: api
+  require_tls: true
+  instances:
+  - host: 10.0.0.1
+    port: 8080
+    metadata:
+    - key: stage
+      value: prod
+- name: web
+domains:
+- name: example.com
+  port: 80
+  aliases: ["*.example.com"]
+  rate_limit_policy:
+    reject_status: 503
+    limits:
+    - name: per-client
+      descriptors: [{kind: remote_address}]
+      requests_per_unit: 10
+      unit: second
+listeners:
+- name: http
+  ip: 0.0.0.0
+  port: 80
+  protocol: http_auto
+proxies:
+- name: edge
+  domains: [example.com:80]
+  listeners: [http]
+shared_rules:
+- name: web-rules
+  default:
+    light:
+    - cluster: web
+      weight: 1
+      request_data:
+        headers:
+        - name: X-Client-Ip
+          action: overwrite
+          value: source_ip
+  rules:
+  - key: beta
+    methods: [GET]
+    fault_injection:
+      delay: {delay_msec: 100, percent: 50}
+    matches:
+    - kind: header
+      from: {key: x-beta, value: "true"}
+    constraints:
+      light:
+      - key: api-light
+        cluster: api
+        weight: 1
+routes:
+- domain: example.com:80
+  path: /
+  shared_rules: web-rules
+- domain: 'example.com:80'
+  path: "/api"
+  shared_rules: web-rules
+  response_data:
+    headers:
+    - name: X-Route
+      value: api
+      value_is_literal: true
+  request_data:
+    headers:
+    - name: X-Debug
+      action: remove
+`
+
+func testManifest(t *testing.T) *Manifest {
+	m, err := Decode("test.yaml", strings.NewReader(testYAML))
+	assert.Nil(t, err)
+	if m == nil {
+		t.FailNow()
+	}
+	return m
+}
+
+func assertStatesEqual(t *testing.T, got, want reconcile.State) {
+	assert.Equal(t, got.Zone.Name, want.Zone.Name)
+
+	assert.Equal(t, len(got.Clusters), len(want.Clusters))
+	for i := range want.Clusters {
+		assert.True(t, got.Clusters[i].Equals(want.Clusters[i]))
+	}
+	assert.Equal(t, len(got.Domains), len(want.Domains))
+	for i := range want.Domains {
+		assert.True(t, got.Domains[i].Equals(want.Domains[i]))
+	}
+	assert.Equal(t, len(got.Listeners), len(want.Listeners))
+	for i := range want.Listeners {
+		assert.True(t, got.Listeners[i].Equals(want.Listeners[i]))
+	}
+	assert.Equal(t, len(got.Proxies), len(want.Proxies))
+	for i := range want.Proxies {
+		assert.True(t, got.Proxies[i].Equals(want.Proxies[i]))
+		assert.ArrayEqual(t, got.Proxies[i].ListenerKeys, want.Proxies[i].ListenerKeys)
+	}
+	assert.Equal(t, len(got.SharedRules), len(want.SharedRules))
+	for i := range want.SharedRules {
+		assert.True(t, got.SharedRules[i].Equals(want.SharedRules[i]))
+	}
+	assert.Equal(t, len(got.Routes), len(want.Routes))
+	for i := range want.Routes {
+		assert.True(t, got.Routes[i].Equals(want.Routes[i]))
+	}
+}
+
+func TestDecode(t *testing.T) {
+	m := testManifest(t)
+
+	assert.Equal(t, m.Zone, "production")
+	assert.Equal(t, len(m.Clusters), 2)
+	assert.True(t, m.Clusters[0].RequireTLS)
+	assert.Equal(t, m.Clusters[0].Instances[0].Port, 8080)
+	assert.Equal(t, m.Clusters[0].Instances[0].Metadata[0].Value, "prod")
+	assert.ArrayEqual(t, m.Domains[0].Aliases, api.DomainAliases{"*.example.com"})
+	assert.Equal(t, m.Listeners[0].Protocol, api.HttpAutoListenerProtocol)
+	assert.ArrayEqual(t, m.Proxies[0].Listeners, []string{"http"})
+	assert.Equal(t, m.SharedRules[0].Rules[0].Matches[0].From.Value, "true")
+	assert.Equal(t, m.SharedRules[0].Rules[0].Matches[0].Behavior, api.ExactMatchBehavior)
+	assert.Equal(t, m.SharedRules[0].Rules[0].FaultInjection.Delay.DelayMsec, 100)
+	assert.Equal(t, m.Domains[0].RateLimitPolicy.Limits[0].Unit, api.SecondRateLimitUnit)
+	assert.Equal(t, m.Routes[1].Domain, "example.com:80")
+	assert.Equal(t, m.Routes[1].Path, "/api")
+	assert.Equal(t, m.Routes[1].ResponseData.Headers[0].Value, "api")
+	assert.Equal(t, m.Routes[1].RequestData.Headers[0].Action, api.RemoveHeaderAction)
+
+	s := m.State()
+	assert.Equal(t, s.Routes[1].DomainKey, api.DomainKey("example.com:80"))
+	assert.Equal(t, s.Routes[1].SharedRulesKey, api.SharedRulesKey("web-rules"))
+	assert.Equal(
+		t,
+		s.SharedRules[0].Rules[0].Constraints.Light[0].ClusterKey,
+		api.ClusterKey("api"),
+	)
+	assert.Equal(t, s.SharedRules[0].Default.Light[0].RequestData.Headers[0].Value, "source_ip")
+	assert.Equal(t, s.SharedRules[0].Rules[0].FaultInjection.Delay.Percent, 50.0)
+	assert.Nil(t, s.Routes[0].RequestData.Headers)
+	assert.Equal(t, s.Domains[0].RateLimitPolicy.RejectStatusOrDefault(), 503)
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	m := testManifest(t)
+
+	for _, encode := range []func(*bytes.Buffer, *Manifest) error{
+		func(buf *bytes.Buffer, m *Manifest) error { return EncodeYAML(buf, m) },
+		func(buf *bytes.Buffer, m *Manifest) error { return EncodeJSON(buf, m) },
+	} {
+		buf := &bytes.Buffer{}
+		assert.Nil(t, encode(buf, m))
+
+		got, err := Decode("encoded", buf)
+		assert.Nil(t, err)
+		if got == nil {
+			t.FailNow()
+		}
+		assertStatesEqual(t, got.State(), m.State())
+		assert.DeepEqual(t, FromState(got.State()), FromState(m.State()))
+	}
+}
+
+func TestEncodeYAMLQuoting(t *testing.T) {
+	m := &Manifest{
+		Zone: "true",
+		Clusters: []Cluster{
+			{Name: "123"},
+			{Name: "- dash"},
+			{Name: "a: b"},
+			{Name: ""},
+			{Name: "plain name"},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, EncodeYAML(buf, m))
+	assert.Equal(
+		t,
+		buf.String(),
+		`zone: "true"
+clusters:
+- name: "123"
+- name: "- dash"
+- name: "a: b"
+- name: ""
+- name: plain name
+`,
+	)
+
+	got, err := Decode("quoted", buf)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, m)
+}
+
+func TestFromObjects(t *testing.T) {
+	svc := memory.NewEmpty("the-org", "the-user")
+	m := testManifest(t)
+
+	p, err := reconcile.NewPlan(svc, m.State())
+	assert.Nil(t, err)
+	assert.Nil(t, p.Apply(svc))
+
+	zones, err := svc.Zone().Index(service.ZoneFilter{Name: "production"})
+	assert.Nil(t, err)
+	assert.Equal(t, len(zones), 1)
+	zk := zones[0].ZoneKey
+
+	o := Objects{Zone: zones[0]}
+	o.Clusters, err = svc.Cluster().Index(service.ClusterFilter{ZoneKey: zk})
+	assert.Nil(t, err)
+	o.Domains, err = svc.Domain().Index(service.DomainFilter{ZoneKey: zk})
+	assert.Nil(t, err)
+	o.Listeners, err = svc.Listener().Index(service.ListenerFilter{ZoneKey: zk})
+	assert.Nil(t, err)
+	o.Proxies, err = svc.Proxy().Index(service.ProxyFilter{ZoneKey: zk})
+	assert.Nil(t, err)
+	o.SharedRules, err = svc.SharedRules().Index(service.SharedRulesFilter{ZoneKey: zk})
+	assert.Nil(t, err)
+	o.Routes, err = svc.Route().Index(service.RouteFilter{ZoneKey: zk})
+	assert.Nil(t, err)
+
+	got, err := FromObjects(o)
+	assert.Nil(t, err)
+	assert.Equal(t, got.Zone, "production")
+	assert.Equal(t, got.SharedRules[0].Rules[0].Constraints.Light[0].Cluster, "api")
+	assert.ArrayEqual(t, got.Proxies[0].Listeners, []string{"http"})
+
+	// applying the exported manifest is a no-op
+	p, err = reconcile.NewPlan(svc, got.State())
+	assert.Nil(t, err)
+	assert.True(t, p.Empty())
+
+	o.Routes[0].SharedRulesKey = "nope"
+	_, err = FromObjects(o)
+	assert.ErrorContains(t, err, `unknown shared_rules key "nope"`)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	testCases := []struct {
+		input string
+		err   string
+	}{
+		{
+			input: "zone: z\nclusters:\n- name: a\n    port: 80\n",
+			err:   "f.yaml:4:5: unexpected indentation",
+		},
+		{
+			input: "zone: z\nclusters:\n- name: a\n\tport: 80\n",
+			err:   "f.yaml:4:1: tabs are not allowed in indentation",
+		},
+		{
+			input: "zone: z\nclusters:\n- name: a\n  bogus: 1\n",
+			err:   `f.yaml:4:3: unknown field "bogus"`,
+		},
+		{
+			input: "zone: z\ndomains:\n- name: a\n  port: eighty\n",
+			err:   "f.yaml:4:9: cannot use string as int",
+		},
+		{
+			input: "zone: z\nclusters:\n- name: a\n- name: a\n",
+			err:   `f.yaml:4:9: duplicate cluster "a"`,
+		},
+		{
+			input: "zone: z\nroutes:\n- domain: example.com:80\n  path: /\n",
+			err:   `f.yaml:3:11: unknown domain "example.com:80"`,
+		},
+		{
+			input: "{\n  \"zone\": \"z\",\n  \"shared_rules\": [\n" +
+				"    {\"name\": \"sr\", \"default\": {\"light\": [{\"cluster\": \"c\"}]}}\n  ]\n}\n",
+			err: `f.yaml:4:54: unknown cluster "c"`,
+		},
+		{
+			input: "zone: z\nclusters: [\n",
+			err:   "f.yaml:3:1: unterminated flow collection",
+		},
+		{
+			input: "clusters: []\n",
+			err:   "f.yaml:1:1: zone name must be non-empty",
+		},
+		{
+			input: "- zone\n",
+			err:   "f.yaml:1:1: manifest must be a mapping",
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Group(
+			tc.err,
+			t,
+			func(g *assert.G) {
+				_, err := Decode("f.yaml", strings.NewReader(tc.input))
+				if assert.NonNil(g, err) {
+					assert.Equal(g, err.Error(), tc.err)
+					_, ok := err.(*Error)
+					assert.True(g, ok)
+				}
+			},
+		)
+	}
+}
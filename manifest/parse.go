@@ -0,0 +1,741 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type nodeKind int
+
+const (
+	scalarNode nodeKind = iota
+	mappingNode
+	sequenceNode
+)
+
+// node is a parsed YAML or JSON value, annotated with its position in the
+// source document. Mappings retain the order of their keys.
+type node struct {
+	kind   nodeKind
+	line   int
+	col    int
+	value  string  // scalars only
+	quoted bool    // scalars only; quoted scalars are always strings
+	keys   []*node // mappings only
+	items  []*node // mapping values or sequence items
+}
+
+// get returns the value for the given key of a mapping node, or nil.
+func (n *node) get(key string) *node {
+	if n == nil || n.kind != mappingNode {
+		return nil
+	}
+	for i, k := range n.keys {
+		if k.value == key {
+			return n.items[i]
+		}
+	}
+	return nil
+}
+
+// index returns the ith item of a sequence node, or nil.
+func (n *node) index(i int) *node {
+	if n == nil || n.kind != sequenceNode || i < 0 || i >= len(n.items) {
+		return nil
+	}
+	return n.items[i]
+}
+
+// find returns the first mapping key, in document order, with the given
+// value, or nil.
+func (n *node) find(key string) *node {
+	if n == nil {
+		return nil
+	}
+	for i, k := range n.keys {
+		if k.value == key {
+			return k
+		}
+		if f := n.items[i].find(key); f != nil {
+			return f
+		}
+	}
+	if n.kind == sequenceNode {
+		for _, item := range n.items {
+			if f := item.find(key); f != nil {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	intPattern   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	floatPattern = regexp.MustCompile(`^[-+]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][-+]?[0-9]+)?$`)
+)
+
+// scalarJSON returns the JSON encoding of a scalar node, interpreting
+// unquoted values as YAML 1.2 core schema nulls, booleans, and numbers where
+// possible.
+func (n *node) scalarJSON() string {
+	if !n.quoted {
+		switch n.value {
+		case "", "~", "null", "Null", "NULL":
+			return "null"
+		case "true", "True", "TRUE":
+			return "true"
+		case "false", "False", "FALSE":
+			return "false"
+		}
+		if intPattern.MatchString(n.value) {
+			return strings.TrimPrefix(n.value, "+")
+		}
+		if floatPattern.MatchString(n.value) {
+			if f, err := strconv.ParseFloat(n.value, 64); err == nil {
+				return strconv.FormatFloat(f, 'g', -1, 64)
+			}
+		}
+	}
+
+	b, _ := json.Marshal(n.value)
+	return string(b)
+}
+
+// position records the source position of the value encoded at a given
+// offset of a JSON document produced by toJSON.
+type position struct {
+	offset int
+	line   int
+	col    int
+}
+
+// toJSON encodes the node as JSON, appending to buf and recording the source
+// position of each encoded value in positions.
+func (n *node) toJSON(buf *bytes.Buffer, positions *[]position) {
+	*positions = append(*positions, position{buf.Len(), n.line, n.col})
+
+	switch n.kind {
+	case scalarNode:
+		buf.WriteString(n.scalarJSON())
+
+	case mappingNode:
+		buf.WriteByte('{')
+		for i, k := range n.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(k.value)
+			*positions = append(*positions, position{buf.Len(), k.line, k.col})
+			buf.Write(key)
+			buf.WriteByte(':')
+			n.items[i].toJSON(buf, positions)
+		}
+		buf.WriteByte('}')
+
+	case sequenceNode:
+		buf.WriteByte('[')
+		for i, item := range n.items {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			item.toJSON(buf, positions)
+		}
+		buf.WriteByte(']')
+	}
+}
+
+// syntaxError is returned by parse.
+type syntaxError struct {
+	line int
+	col  int
+	msg  string
+}
+
+func (e *syntaxError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.line, e.col, e.msg)
+}
+
+// parser parses the subset of YAML used by manifests: block mappings and
+// sequences, flow mappings and sequences (and therefore JSON), and plain,
+// single-quoted, and double-quoted scalars. Anchors, aliases, tags, block
+// scalars, explicit keys, and multiple documents are not supported, and
+// block indentation must use spaces.
+//
+// A YAML library is not used because this package must not add
+// dependencies, and because manifest errors report the line and column of
+// the offending key, which the decoded values of a library do not carry.
+// Anything outside the subset is rejected with a syntaxError rather than
+// guessed at; TestParseMalformed exercises that on mutated documents.
+type parser struct {
+	lines []string
+	ln    int // current line, 0-based
+	col   int // current column, 0-based
+}
+
+// parse parses a YAML or JSON document. An empty document yields a null
+// scalar.
+func parse(data []byte) (*node, error) {
+	text := strings.Replace(string(data), "\r\n", "\n", -1)
+	text = strings.TrimPrefix(text, "\ufeff")
+	p := &parser{lines: strings.Split(text, "\n")}
+
+	if !p.atEOL() {
+		if err := p.checkIndent(); err != nil {
+			return nil, err
+		}
+	}
+
+	if ok, err := p.nextContentLine(); err != nil {
+		return nil, err
+	} else if !ok {
+		return &node{kind: scalarNode, line: 1, col: 1}, nil
+	}
+
+	if isMarker(p.line(), "---") {
+		p.col += 3
+		p.skipSpaces()
+	}
+
+	// the document ends at a "..." marker; subsequent documents are not
+	// supported
+	for i := p.ln + 1; i < len(p.lines); i++ {
+		if isMarker(p.lines[i], "...") {
+			p.lines = p.lines[:i]
+			break
+		}
+		if isMarker(p.lines[i], "---") {
+			return nil, &syntaxError{i + 1, 1, "multiple documents are not supported"}
+		}
+	}
+
+	if ok, err := p.nextContentLine(); err != nil {
+		return nil, err
+	} else if !ok {
+		return &node{kind: scalarNode, line: 1, col: 1}, nil
+	}
+
+	n, err := p.parseNode(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok, err := p.nextContentLine(); err != nil {
+		return nil, err
+	} else if ok {
+		return nil, p.errorf("unexpected content")
+	}
+
+	return n, nil
+}
+
+// isMarker returns true if the line begins with the given document marker.
+func isMarker(line, marker string) bool {
+	if !strings.HasPrefix(line, marker) {
+		return false
+	}
+	r := line[len(marker):]
+	return r == "" || isSpace(r[0])
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &syntaxError{p.ln + 1, p.col + 1, fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) eof() bool {
+	return p.ln >= len(p.lines)
+}
+
+func (p *parser) line() string {
+	if p.eof() {
+		return ""
+	}
+	return p.lines[p.ln]
+}
+
+// rest returns the remainder of the current line.
+func (p *parser) rest() string {
+	l := p.line()
+	if p.col >= len(l) {
+		return ""
+	}
+	return l[p.col:]
+}
+
+func (p *parser) peek() byte {
+	if r := p.rest(); r != "" {
+		return r[0]
+	}
+	return 0
+}
+
+func (p *parser) skipSpaces() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.col++
+	}
+}
+
+// atEOL returns true if the remainder of the current line is empty or a
+// comment.
+func (p *parser) atEOL() bool {
+	r := strings.TrimLeft(p.rest(), " \t")
+	return r == "" || r[0] == '#'
+}
+
+// nextContentLine advances to the first non-blank, non-comment line at or
+// after the current line (skipping the remainder of the current line if it
+// is blank), positioning the cursor at its first non-space character.
+// Returns false at the end of the document, or an error if a new line is
+// indented with tabs.
+func (p *parser) nextContentLine() (bool, error) {
+	if !p.eof() && !p.atEOL() {
+		return true, nil
+	}
+	for p.ln++; !p.eof(); p.ln++ {
+		p.col = 0
+		p.skipSpaces()
+		if !p.atEOL() {
+			return true, p.checkIndent()
+		}
+	}
+	return false, nil
+}
+
+// checkIndent returns an error if the indentation of the current line
+// contains a tab. As in YAML, block structure is determined by spaces
+// alone; tabs may only separate tokens within a line or flow collection.
+func (p *parser) checkIndent() error {
+	l := p.line()
+	indent := l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+	if i := strings.IndexByte(indent, '\t'); i >= 0 {
+		return &syntaxError{p.ln + 1, i + 1, "tabs are not allowed in indentation"}
+	}
+	return nil
+}
+
+// atSequenceEntry returns true if the cursor is at a block sequence entry
+// indicator.
+func (p *parser) atSequenceEntry() bool {
+	r := p.rest()
+	return r == "-" || strings.HasPrefix(r, "- ") || strings.HasPrefix(r, "-\t")
+}
+
+// atMappingKey returns true if the cursor is at a block mapping key.
+func (p *parser) atMappingKey() bool {
+	save := *p
+	defer func() { *p = save }()
+
+	switch p.peek() {
+	case '"', '\'':
+		if _, err := p.parseQuoted(); err != nil {
+			return false
+		}
+		p.skipSpaces()
+		return p.atMappingIndicator()
+	case '[', '{', '#', '-', 0:
+		if !(p.peek() == '-' && !p.atSequenceEntry()) {
+			return false
+		}
+	case '?':
+		if p.atComplexKey() {
+			return false
+		}
+	}
+
+	_, ok := p.scanPlainKey()
+	return ok
+}
+
+// atComplexKey returns true if the cursor is at a '?' followed by whitespace
+// or the end of the line, which introduces an explicit mapping key.
+func (p *parser) atComplexKey() bool {
+	r := p.rest()
+	return r == "?" || strings.HasPrefix(r, "? ") || strings.HasPrefix(r, "?\t")
+}
+
+// atMappingIndicator returns true if the cursor is at a ':' followed by
+// whitespace or the end of the line.
+func (p *parser) atMappingIndicator() bool {
+	r := p.rest()
+	return r == ":" || strings.HasPrefix(r, ": ") || strings.HasPrefix(r, ":\t")
+}
+
+// scanPlainKey scans a plain scalar terminated by a mapping indicator,
+// leaving the cursor at the indicator.
+func (p *parser) scanPlainKey() (string, bool) {
+	start := p.col
+	for ; p.peek() != 0; p.col++ {
+		if p.atMappingIndicator() {
+			return strings.TrimRight(p.line()[start:p.col], " \t"), true
+		}
+		if p.peek() == '#' && p.col > start && isSpace(p.line()[p.col-1]) {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t'
+}
+
+// parseNode parses the node at the cursor, which must be indented more than
+// parentIndent.
+func (p *parser) parseNode(parentIndent int) (*node, error) {
+	switch {
+	case p.atSequenceEntry():
+		return p.parseBlockSequence()
+	case p.atMappingKey():
+		return p.parseBlockMapping()
+	}
+
+	n, err := p.parseInline(false)
+	if err != nil {
+		return nil, err
+	}
+	if !p.atEOL() {
+		return nil, p.errorf("unexpected content after value")
+	}
+	return n, nil
+}
+
+func (p *parser) parseBlockSequence() (*node, error) {
+	seq := &node{kind: sequenceNode, line: p.ln + 1, col: p.col + 1}
+	col := p.col
+
+	for {
+		p.col++ // consume '-'
+		p.skipSpaces()
+
+		var (
+			item *node
+			err  error
+		)
+		if p.atEOL() {
+			itemLine := p.ln
+			var ok bool
+			if ok, err = p.nextContentLine(); err != nil {
+				return nil, err
+			}
+			if ok && p.ln != itemLine && p.col > col {
+				item, err = p.parseNode(col)
+			} else {
+				item = &node{kind: scalarNode, line: itemLine + 1, col: col + 2}
+			}
+		} else {
+			item, err = p.parseNode(col)
+		}
+		if err != nil {
+			return nil, err
+		}
+		seq.items = append(seq.items, item)
+
+		if ok, err := p.nextContentLine(); err != nil {
+			return nil, err
+		} else if !ok {
+			return seq, nil
+		}
+		switch {
+		case p.col < col:
+			return seq, nil
+		case p.col > col:
+			return nil, p.errorf("unexpected indentation")
+		case !p.atSequenceEntry():
+			return seq, nil
+		}
+	}
+}
+
+func (p *parser) parseBlockMapping() (*node, error) {
+	m := &node{kind: mappingNode, line: p.ln + 1, col: p.col + 1}
+	col := p.col
+
+	for {
+		key := &node{kind: scalarNode, line: p.ln + 1, col: p.col + 1}
+		switch p.peek() {
+		case '"', '\'':
+			v, err := p.parseQuoted()
+			if err != nil {
+				return nil, err
+			}
+			key.value = v
+			key.quoted = true
+			p.skipSpaces()
+		default:
+			v, ok := p.scanPlainKey()
+			if !ok {
+				return nil, p.errorf("expected mapping key")
+			}
+			key.value = v
+		}
+		if !p.atMappingIndicator() {
+			return nil, p.errorf("expected ':' after mapping key")
+		}
+		for _, k := range m.keys {
+			if k.value == key.value {
+				return nil, &syntaxError{key.line, key.col, fmt.Sprintf("duplicate key %q", key.value)}
+			}
+		}
+
+		p.col++ // consume ':'
+		p.skipSpaces()
+
+		var (
+			value *node
+			err   error
+		)
+		if p.atEOL() {
+			keyLine := p.ln
+			var ok bool
+			if ok, err = p.nextContentLine(); err != nil {
+				return nil, err
+			}
+			switch {
+			case !ok || p.ln == keyLine:
+				value = &node{kind: scalarNode, line: key.line, col: key.col}
+			case p.col > col, p.col == col && p.atSequenceEntry():
+				value, err = p.parseNode(col)
+			default:
+				value = &node{kind: scalarNode, line: key.line, col: key.col}
+			}
+		} else {
+			value, err = p.parseInline(false)
+			if err == nil && !p.atEOL() {
+				err = p.errorf("unexpected content after value")
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		m.keys = append(m.keys, key)
+		m.items = append(m.items, value)
+
+		if ok, err := p.nextContentLine(); err != nil {
+			return nil, err
+		} else if !ok {
+			return m, nil
+		}
+		switch {
+		case p.col < col:
+			return m, nil
+		case p.col > col:
+			return nil, p.errorf("unexpected indentation")
+		case p.atSequenceEntry():
+			// a sequence at the same indentation as its key; the
+			// enclosing sequence, if any, continues
+			return m, nil
+		}
+	}
+}
+
+// skipFlowSpace skips whitespace, newlines, and comments within a flow
+// collection.
+func (p *parser) skipFlowSpace() error {
+	for {
+		p.skipSpaces()
+		if !p.atEOL() {
+			return nil
+		}
+		if p.ln+1 >= len(p.lines) {
+			p.col = len(p.line())
+			return p.errorf("unterminated flow collection")
+		}
+		p.ln++
+		p.col = 0
+	}
+}
+
+// parseInline parses a flow collection or scalar starting at the cursor.
+func (p *parser) parseInline(inFlow bool) (*node, error) {
+	n := &node{kind: scalarNode, line: p.ln + 1, col: p.col + 1}
+
+	switch c := p.peek(); c {
+	case '[':
+		return p.parseFlowSequence()
+	case '{':
+		return p.parseFlowMapping()
+	case '"', '\'':
+		v, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		n.value = v
+		n.quoted = true
+		if !inFlow {
+			p.skipSpaces()
+		}
+		return n, nil
+	case '&', '*', '!', '|', '>', '%', '@', '`':
+		return nil, p.errorf("unsupported YAML syntax %q", string(c))
+	case '?':
+		if p.atComplexKey() {
+			return nil, p.errorf("unsupported YAML syntax %q", string(c))
+		}
+	case ']', '}':
+		if !inFlow {
+			return nil, p.errorf("unexpected %q", string(c))
+		}
+	}
+
+	start := p.col
+	for ; p.peek() != 0; p.col++ {
+		c := p.peek()
+		if c == '#' && p.col > start && isSpace(p.line()[p.col-1]) {
+			break
+		}
+		if inFlow && (c == ',' || c == ']' || c == '}' || p.atMappingIndicator()) {
+			break
+		}
+		if !inFlow && p.atMappingIndicator() {
+			return nil, p.errorf("unexpected mapping")
+		}
+	}
+	n.value = strings.TrimRight(p.line()[start:p.col], " \t")
+	return n, nil
+}
+
+func (p *parser) parseFlowSequence() (*node, error) {
+	seq := &node{kind: sequenceNode, line: p.ln + 1, col: p.col + 1}
+	p.col++ // consume '['
+
+	for {
+		if err := p.skipFlowSpace(); err != nil {
+			return nil, err
+		}
+		if p.peek() == ']' {
+			p.col++
+			return seq, nil
+		}
+		if p.peek() == ',' {
+			return nil, p.errorf("unexpected ','")
+		}
+
+		item, err := p.parseInline(true)
+		if err != nil {
+			return nil, err
+		}
+		seq.items = append(seq.items, item)
+
+		if err := p.skipFlowSpace(); err != nil {
+			return nil, err
+		}
+		switch p.peek() {
+		case ',':
+			p.col++
+		case ']':
+		default:
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *parser) parseFlowMapping() (*node, error) {
+	m := &node{kind: mappingNode, line: p.ln + 1, col: p.col + 1}
+	p.col++ // consume '{'
+
+	for {
+		if err := p.skipFlowSpace(); err != nil {
+			return nil, err
+		}
+		if p.peek() == '}' {
+			p.col++
+			return m, nil
+		}
+
+		key, err := p.parseInline(true)
+		if err != nil {
+			return nil, err
+		}
+		if key.kind != scalarNode {
+			return nil, &syntaxError{key.line, key.col, "mapping keys must be scalars"}
+		}
+		for _, k := range m.keys {
+			if k.value == key.value {
+				return nil, &syntaxError{key.line, key.col, fmt.Sprintf("duplicate key %q", key.value)}
+			}
+		}
+
+		if err := p.skipFlowSpace(); err != nil {
+			return nil, err
+		}
+		if p.peek() != ':' {
+			return nil, p.errorf("expected ':' after mapping key")
+		}
+		p.col++
+		if err := p.skipFlowSpace(); err != nil {
+			return nil, err
+		}
+
+		value, err := p.parseInline(true)
+		if err != nil {
+			return nil, err
+		}
+		m.keys = append(m.keys, key)
+		m.items = append(m.items, value)
+
+		if err := p.skipFlowSpace(); err != nil {
+			return nil, err
+		}
+		switch p.peek() {
+		case ',':
+			p.col++
+		case '}':
+		default:
+			return nil, p.errorf("expected ',' or '}'")
+		}
+	}
+}
+
+// parseQuoted parses a single- or double-quoted scalar, which must be
+// contained within a single line.
+func (p *parser) parseQuoted() (string, error) {
+	l := p.line()
+	quote := l[p.col]
+	start := p.col
+
+	for i := p.col + 1; i < len(l); i++ {
+		switch {
+		case quote == '\'' && l[i] == '\'':
+			if i+1 < len(l) && l[i+1] == '\'' {
+				i++
+				continue
+			}
+			p.col = i + 1
+			return strings.Replace(l[start+1:i], "''", "'", -1), nil
+
+		case quote == '"' && l[i] == '\\':
+			i++
+
+		case quote == '"' && l[i] == '"':
+			var s string
+			if err := json.Unmarshal([]byte(l[start:i+1]), &s); err != nil {
+				return "", p.errorf("invalid double-quoted string")
+			}
+			p.col = i + 1
+			return s, nil
+		}
+	}
+
+	return "", p.errorf("unterminated quoted string")
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/turbinelabs/test/assert"
+)
+
+func parseToJSON(t testing.TB, input string) string {
+	n, err := parse([]byte(input))
+	if !assert.Nil(t, err) {
+		return ""
+	}
+	buf := &bytes.Buffer{}
+	n.toJSON(buf, &[]position{})
+	return buf.String()
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  string
+	}{
+		{"", "null"},
+		{"--- # empty\n", "null"},
+		{"a: 1", `{"a":1}`},
+		{"a: 1.5e3\nb: -2\nc: +3", `{"a":1500,"b":-2,"c":3}`},
+		{"a: true\nb: ~\nc:\nd: 'x'", `{"a":true,"b":null,"c":null,"d":"x"}`},
+		{"a: it's # comment\nb: x#y", `{"a":"it's","b":"x#y"}`},
+		{"a: 'it''s'\nb: \"tab\\there\"", `{"a":"it's","b":"tab\there"}`},
+		{"a: http://example.com:80/x", `{"a":"http://example.com:80/x"}`},
+		{"---\na:\n  b:\n    - 1\n    - 2\n  c: 3\n...\n", `{"a":{"b":[1,2],"c":3}}`},
+		{"a:\n- b: 1\n  c: 2\n-\n  - x\n- - y\nd: 4", `{"a":[{"b":1,"c":2},["x"],["y"]],"d":4}`},
+		{"- \n- a\n", `[null,"a"]`},
+		{"\"quoted key\": 1", `{"quoted key":1}`},
+		{"a: [1, two, {b: c}]", `{"a":[1,"two",{"b":"c"}]}`},
+		{"{\n  \"a\": [\n    1, # one\n    2\n  ],\n  \"b\": {}\n}", `{"a":[1,2],"b":{}}`},
+		{"{\n\t\"a\": [1,\n\t\t2]\n}", `{"a":[1,2]}`},
+		{"a:\tb # tab separated\n", `{"a":"b"}`},
+	}
+
+	for _, tc := range testCases {
+		assert.Group(
+			tc.input,
+			t,
+			func(g *assert.G) {
+				assert.Equal(g, parseToJSON(g, tc.input), tc.want)
+			},
+		)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  string
+	}{
+		{"a: 1\na: 2", `2:1: duplicate key "a"`},
+		{"a: b: c", "1:5: unexpected mapping"},
+		{"a: &anchor x", `1:4: unsupported YAML syntax "&"`},
+		{"a: |\n  text", `1:4: unsupported YAML syntax "|"`},
+		{"a: 'open", "1:4: unterminated quoted string"},
+		{"a: {b: 1]", "1:9: expected ',' or '}'"},
+		{"a: 1\n b: 2", "2:2: unexpected indentation"},
+		{"a:\n\tb: 1", "2:1: tabs are not allowed in indentation"},
+		{"a:\n  - 1\n \t- 2", "3:2: tabs are not allowed in indentation"},
+		{"\ta: 1", "1:1: tabs are not allowed in indentation"},
+		{"a: 1\n--- \nb: 2", "2:1: multiple documents are not supported"},
+		{"[1]\n2", "2:1: unexpected content"},
+		{"{", "1:2: unterminated flow collection"},
+		{"a: [1, 2\n", "2:1: unterminated flow collection"},
+		{"\"open", "1:1: unterminated quoted string"},
+		{"a: \"\\q\"", "1:4: invalid double-quoted string"},
+		{"a: *alias", `1:4: unsupported YAML syntax "*"`},
+		{"a: !tag x", `1:4: unsupported YAML syntax "!"`},
+		{"? k\n: v", `1:1: unsupported YAML syntax "?"`},
+		{"a: }", `1:4: unexpected "}"`},
+		{"- ]", `1:3: unexpected "]"`},
+		{"[1,,2]", "1:4: unexpected ','"},
+		{"{a b}", "1:5: expected ':' after mapping key"},
+		{"{[a]: 1}", "1:2: mapping keys must be scalars"},
+		{"[a: 1]", "1:3: expected ',' or ']'"},
+		{"a:\n- 1\n  - 2", "3:3: unexpected indentation"},
+		{"- a\nb: 1", "2:1: unexpected content"},
+		{"{\"a\": 1} x", "1:9: unexpected content after value"},
+	}
+
+	for _, tc := range testCases {
+		assert.Group(
+			tc.input,
+			t,
+			func(g *assert.G) {
+				_, err := parse([]byte(tc.input))
+				assert.ErrorContains(g, err, tc.want)
+			},
+		)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	seeds := []string{
+		"a:\n  b:\n    - 1\n    - {c: 'd', e: [f, \"g\"]}\n  h: i # j\n",
+		"{\"a\": [1, 2, {\"b\": null}], \"c\": \"d\"}",
+		"---\n- a: 1\n  b:\n  - x\n-\n  - y\n...\n",
+	}
+	inserts := []string{"\t", "\n", " ", "-", ":", "#", "'", "\"", ",", "[", "]", "{", "}", "?", "\\"}
+	errRE := regexp.MustCompile(`^\d+:\d+: `)
+
+	check := func(t testing.TB, input string) {
+		_, err := parse([]byte(input))
+		if err == nil {
+			return
+		}
+		serr, ok := err.(*syntaxError)
+		if !assert.True(t, ok) {
+			return
+		}
+		assert.True(t, errRE.MatchString(serr.Error()))
+		assert.True(t, serr.line >= 1 && serr.line <= strings.Count(input, "\n")+1)
+		assert.True(t, serr.col >= 1)
+	}
+
+	for _, seed := range seeds {
+		for i := 0; i <= len(seed); i++ {
+			check(t, seed[:i])
+			if i < len(seed) {
+				check(t, seed[:i]+seed[i+1:])
+			}
+			for _, ins := range inserts {
+				check(t, seed[:i]+ins+seed[i:])
+			}
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"regexp"
 	"strconv"
 )
@@ -38,6 +39,14 @@ const (
 	HeaderMatchKind MatchKind = "header"
 	// QueryMatchKind matches against a requests's query parameters
 	QueryMatchKind MatchKind = "query"
+	// PathMatchKind matches against a request's path
+	PathMatchKind MatchKind = "path"
+	// SchemeMatchKind matches against a request's scheme (e.g. "https")
+	SchemeMatchKind MatchKind = "scheme"
+	// SourceIPMatchKind matches a request's source IP against a CIDR block
+	SourceIPMatchKind MatchKind = "source_ip"
+	// HostMatchKind matches against a request's host
+	HostMatchKind MatchKind = "host"
 
 	// ExactMatchBehavior matches a request attribute with an exact comparison.
 	ExactMatchBehavior MatchBehavior = "exact"
@@ -83,6 +92,23 @@ const (
 	would define a match which looks for the X-GitSha header in a request,
 	and if present, adds the value of that header as the value for a "git-sha"
 	metadata constraint on Instances in the Cluster defined by the Route.
+
+	Path, scheme, source IP, and host matches have no From.Key, and always
+	require a From.Value. A source IP match uses exact behavior, and its
+	From.Value is a CIDR block.
+
+	Example:
+
+		Match{
+			PathMatchKind,
+			PrefixMatchBehavior,
+			Metadatum{Value: "/beta/"},
+			Metadatum{"beta", "true"},
+		}
+
+	would define a match which looks for requests with paths beginning with
+	"/beta/", and if present, adds a specific key/value constraint of
+	beta=true.
 */
 type Match struct {
 	Kind     MatchKind     `json:"kind"`
@@ -95,23 +121,69 @@ var invalidKindBehaviorCombinations = []struct {
 	kind     MatchKind
 	behavior MatchBehavior
 }{
+	{
+		kind:     CookieMatchKind,
+		behavior: RangeMatchBehavior,
+	},
 	{
 		kind:     QueryMatchKind,
+		behavior: RangeMatchBehavior,
+	},
+	{
+		kind:     PathMatchKind,
+		behavior: RangeMatchBehavior,
+	},
+	{
+		kind:     SchemeMatchKind,
 		behavior: RegexMatchBehavior,
 	},
 	{
-		kind:     CookieMatchKind,
+		kind:     SchemeMatchKind,
 		behavior: RangeMatchBehavior,
 	},
 	{
-		kind:     QueryMatchKind,
+		kind:     SchemeMatchKind,
+		behavior: PrefixMatchBehavior,
+	},
+	{
+		kind:     SchemeMatchKind,
+		behavior: SuffixMatchBehavior,
+	},
+	{
+		kind:     SourceIPMatchKind,
+		behavior: RegexMatchBehavior,
+	},
+	{
+		kind:     SourceIPMatchKind,
+		behavior: RangeMatchBehavior,
+	},
+	{
+		kind:     SourceIPMatchKind,
+		behavior: PrefixMatchBehavior,
+	},
+	{
+		kind:     SourceIPMatchKind,
+		behavior: SuffixMatchBehavior,
+	},
+	{
+		kind:     HostMatchKind,
 		behavior: RangeMatchBehavior,
 	},
 }
 
+// keyless reports whether a MatchKind matches a request attribute that has
+// no name, and therefore does not use From.Key.
+func (k MatchKind) keyless() bool {
+	return k == PathMatchKind ||
+		k == SchemeMatchKind ||
+		k == SourceIPMatchKind ||
+		k == HostMatchKind
+}
+
 // Check this Match for validity. A valid match requires a valid matchkind,
-// a From datum with Key set, and either an empty To datum or one with both
-// Key and Value set.
+// a From datum with Key set (or, for path, scheme, source IP, and host
+// matches, with Value set and Key empty), and a To datum whose Key is set if
+// its Value is.
 func (m Match) IsValid() *ValidationError {
 	ecase := func(f, msg string) ErrorCase {
 		return ErrorCase{f, msg}
@@ -121,7 +193,8 @@ func (m Match) IsValid() *ValidationError {
 
 	validKind := m.Kind == CookieMatchKind ||
 		m.Kind == HeaderMatchKind ||
-		m.Kind == QueryMatchKind
+		m.Kind == QueryMatchKind ||
+		m.Kind.keyless()
 
 	if !validKind {
 		errs.AddNew(ecase(
@@ -141,7 +214,22 @@ func (m Match) IsValid() *ValidationError {
 			fmt.Sprintf("%q is not a valid behavior kind", m.Behavior)))
 	}
 
-	errCheckIndex(m.From.Key, errs, "from.key")
+	if m.Kind.keyless() {
+		if m.From.Key != "" {
+			errs.AddNew(ecase(
+				"from.key",
+				fmt.Sprintf("must be empty if kind is %q", m.Kind),
+			))
+		}
+		if m.From.Value == "" {
+			errs.AddNew(ecase(
+				"from.value",
+				fmt.Sprintf("must not be empty if kind is %q", m.Kind),
+			))
+		}
+	} else {
+		errCheckIndex(m.From.Key, errs, "from.key")
+	}
 
 	if m.To.Value != "" && m.To.Key == "" {
 		errs.AddNew(ecase("to.key", "must not be empty if to.value is set"))
@@ -149,7 +237,10 @@ func (m Match) IsValid() *ValidationError {
 
 	// The only time it's ok to not have a specific matched value is with
 	// exact behavior kind, to indicate that all values should be matched.
-	if validBehavior && m.From.Value == "" && m.Behavior != ExactMatchBehavior {
+	if validBehavior &&
+		!m.Kind.keyless() &&
+		m.From.Value == "" &&
+		m.Behavior != ExactMatchBehavior {
 		errs.AddNew(
 			ecase(
 				"from.value",
@@ -204,10 +295,29 @@ func (m Match) IsValid() *ValidationError {
 		}
 	}
 
+	if m.Kind == SourceIPMatchKind &&
+		m.Behavior == ExactMatchBehavior &&
+		m.From.Value != "" {
+		if _, _, err := net.ParseCIDR(m.From.Value); err != nil {
+			errs.AddNew(
+				ecase(
+					"from.value",
+					fmt.Sprintf("%q is not a valid CIDR block", m.From.Value),
+				),
+			)
+		}
+	}
+
 	return errs.OrNil()
 }
 
+// Key identifies the request attribute and behavior of a Match. Matches
+// of keyless kinds are further distinguished by their From.Value, so that a
+// Rule may, for example, match several path prefixes.
 func (m Match) Key() string {
+	if m.Kind.keyless() {
+		return fmt.Sprintf("%s:%s:%s", string(m.Kind), string(m.Behavior), m.From.Value)
+	}
 	return fmt.Sprintf("%s:%s:%s", string(m.Kind), string(m.Behavior), m.From.Key)
 }
 
@@ -548,10 +548,15 @@ func TestMatchIsValidInvalidBehaviorKindCombinations(t *testing.T) {
 					val = "[0,100)"
 				}
 
+				var key = "q1"
+				if c.kind.keyless() {
+					key = ""
+				}
+
 				m := Match{
 					Kind:     c.kind,
 					Behavior: c.behavior,
-					From:     Metadatum{Key: "q1", Value: val},
+					From:     Metadatum{Key: key, Value: val},
 					To:       Metadatum{Key: "stage", Value: "testing"},
 				}
 
@@ -569,6 +574,71 @@ func TestMatchIsValidInvalidBehaviorKindCombinations(t *testing.T) {
 	}
 }
 
+func TestMatchIsValidKeylessKinds(t *testing.T) {
+	for _, kind := range []MatchKind{PathMatchKind, SchemeMatchKind, SourceIPMatchKind, HostMatchKind} {
+		assert.Group(
+			string(kind),
+			t,
+			func(g *assert.G) {
+				value := "value"
+				if kind == SourceIPMatchKind {
+					value = "10.0.0.0/8"
+				}
+
+				m := Match{
+					Kind:     kind,
+					Behavior: ExactMatchBehavior,
+					From:     Metadatum{Value: value},
+					To:       Metadatum{Key: "stage", Value: "testing"},
+				}
+				assert.Nil(g, m.IsValid())
+
+				m.From.Key = "q1"
+				vm := ValidationMatcher("from.key", fmt.Sprintf("must be empty if kind is %q", kind))
+				assert.True(g, vm.Matches(m.IsValid()))
+
+				m.From = Metadatum{}
+				vm = ValidationMatcher("from.value", fmt.Sprintf("must not be empty if kind is %q", kind))
+				assert.True(g, vm.Matches(m.IsValid()))
+			},
+		)
+	}
+}
+
+func TestMatchIsValidPathBehaviors(t *testing.T) {
+	for _, b := range []MatchBehavior{
+		ExactMatchBehavior,
+		PrefixMatchBehavior,
+		SuffixMatchBehavior,
+		RegexMatchBehavior,
+	} {
+		m := Match{Kind: PathMatchKind, Behavior: b, From: Metadatum{Value: "/a"}}
+		assert.Nil(t, m.IsValid())
+	}
+}
+
+func TestMatchIsValidSourceIPInvalidCIDR(t *testing.T) {
+	m := Match{
+		Kind:     SourceIPMatchKind,
+		Behavior: ExactMatchBehavior,
+		From:     Metadatum{Value: "10.0.0.1"},
+	}
+
+	vm := ValidationMatcher("from.value", `"10.0.0.1" is not a valid CIDR block`)
+	assert.True(t, vm.Matches(m.IsValid()))
+}
+
+func TestMatchIsValidQueryRegex(t *testing.T) {
+	m := Match{
+		Kind:     QueryMatchKind,
+		Behavior: RegexMatchBehavior,
+		From:     Metadatum{Key: "q", Value: "a|b"},
+		To:       Metadatum{Key: "q", Value: "ab"},
+	}
+
+	assert.Nil(t, m.IsValid())
+}
+
 func TestMatchesIsValidSuccess(t *testing.T) {
 	m1 := Match{
 		Kind:     CookieMatchKind,
@@ -658,6 +728,26 @@ func TestMatchesIsValidDupeMatch(t *testing.T) {
 	assert.True(t, vm.Matches(m.IsValid()))
 }
 
+func TestMatchesIsValidKeylessMatchesDistinguishedByValue(t *testing.T) {
+	m1 := Match{
+		Kind:     PathMatchKind,
+		Behavior: PrefixMatchBehavior,
+		From:     Metadatum{Value: "/api"},
+	}
+
+	m2 := Match{
+		Kind:     PathMatchKind,
+		Behavior: PrefixMatchBehavior,
+		From:     Metadatum{Value: "/v2/api"},
+	}
+
+	assert.NotEqual(t, m1.Key(), m2.Key())
+	assert.Nil(t, Matches{m1, m2}.IsValid())
+
+	vm := ValidationMatcher("", "duplicate match found path:prefix:/api")
+	assert.True(t, vm.Matches(Matches{m1, m1}.IsValid()))
+}
+
 func TestMatchUnmarshalJSONDefaultsNullMatchBehavior(t *testing.T) {
 	bytes := []byte(`{
           "kind": "header",
@@ -0,0 +1,365 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultRateLimitRejectStatus is the HTTP status returned for a request
+// rejected by a RateLimitPolicy with no RejectStatus.
+const DefaultRateLimitRejectStatus = http.StatusTooManyRequests
+
+// RateLimitDescriptorKind is an Enumeration of the request attributes from
+// which a RateLimitDescriptor's value may be taken.
+type RateLimitDescriptorKind string
+
+const (
+	// HeaderRateLimitDescriptor takes its value from the request header
+	// named by Key.
+	HeaderRateLimitDescriptor RateLimitDescriptorKind = "header"
+
+	// CookieRateLimitDescriptor takes its value from the request cookie
+	// named by Key.
+	CookieRateLimitDescriptor RateLimitDescriptorKind = "cookie"
+
+	// RemoteAddressRateLimitDescriptor takes its value from the request's
+	// source IP.
+	RemoteAddressRateLimitDescriptor RateLimitDescriptorKind = "remote_address"
+
+	// MatchRateLimitDescriptor has a fixed value, and is present only for
+	// requests that satisfy its Match.
+	MatchRateLimitDescriptor RateLimitDescriptorKind = "match"
+)
+
+// RateLimitUnit is an Enumeration of the periods over which a RateLimit's
+// RequestsPerUnit are counted.
+type RateLimitUnit string
+
+const (
+	SecondRateLimitUnit RateLimitUnit = "second"
+	MinuteRateLimitUnit RateLimitUnit = "minute"
+	HourRateLimitUnit   RateLimitUnit = "hour"
+	DayRateLimitUnit    RateLimitUnit = "day"
+)
+
+var rateLimitUnitDurations = map[RateLimitUnit]time.Duration{
+	SecondRateLimitUnit: time.Second,
+	MinuteRateLimitUnit: time.Minute,
+	HourRateLimitUnit:   time.Hour,
+	DayRateLimitUnit:    24 * time.Hour,
+}
+
+// Duration returns the period represented by the RateLimitUnit, or 0 if it
+// is not a valid unit.
+func (u RateLimitUnit) Duration() time.Duration {
+	return rateLimitUnitDurations[u]
+}
+
+/*
+	RateLimitPolicy limits the rate at which requests are accepted by the
+	proxy. Each RateLimit builds a descriptor from attributes of a request;
+	requests with the same descriptor values share a budget of
+	RequestsPerUnit requests per Unit. A request that would exceed the
+	budget of any applicable RateLimit is rejected with RejectStatus.
+
+	A RateLimit applies to a request only if every one of its descriptors
+	can be built from it: a header or cookie descriptor requires the named
+	header or cookie, a remote_address descriptor requires a source IP, and
+	a match descriptor requires the request to satisfy its Match. A
+	RateLimit with no descriptors applies to every request.
+
+	It is possible to set a rate limit policy on a Domain, Route, or Rule
+	object. Only one of these will apply to any given request: one set on a
+	matching Rule takes precedence over one set on the Route, which takes
+	precedence over one set on the Domain. See RateLimitPolicyFor.
+
+	Example:
+
+		RateLimitPolicy{
+			Limits: []RateLimit{
+				{
+					Name:            "per-client",
+					Descriptors:     []RateLimitDescriptor{{Kind: RemoteAddressRateLimitDescriptor}},
+					RequestsPerUnit: 10,
+					Unit:            SecondRateLimitUnit,
+				},
+				{
+					Name: "beta-per-user",
+					Descriptors: []RateLimitDescriptor{
+						{
+							Kind: MatchRateLimitDescriptor,
+							Match: &Match{
+								Kind:     HeaderMatchKind,
+								Behavior: ExactMatchBehavior,
+								From:     Metadatum{Key: "X-Beta", Value: "true"},
+							},
+						},
+						{Kind: CookieRateLimitDescriptor, Key: "user"},
+					},
+					RequestsPerUnit: 100,
+					Unit:            MinuteRateLimitUnit,
+				},
+			},
+			RejectStatus: 503,
+		}
+
+	would allow each source IP 10 requests per second, and each user cookie
+	value 100 requests per minute among requests with an "X-Beta: true"
+	header, rejecting requests over either limit with a 503.
+*/
+type RateLimitPolicy struct {
+	// Limits are the RateLimits applied to requests. Each must have a
+	// unique Name.
+	Limits []RateLimit `json:"limits"`
+
+	// RejectStatus is the HTTP status, from 400 to 599, returned for a
+	// rejected request. If zero, DefaultRateLimitRejectStatus is used.
+	RejectStatus int `json:"reject_status"`
+}
+
+// RateLimit is a budget of requests shared by all requests with the same
+// descriptor values.
+type RateLimit struct {
+	// Name identifies the RateLimit within its RateLimitPolicy.
+	Name string `json:"name"`
+
+	// Descriptors are the request attributes from which the descriptor
+	// values are built. Their order is significant.
+	Descriptors []RateLimitDescriptor `json:"descriptors"`
+
+	// RequestsPerUnit is the number of requests allowed per Unit for each
+	// distinct set of descriptor values. It must be positive.
+	RequestsPerUnit uint32 `json:"requests_per_unit"`
+
+	// Unit is the period over which requests are counted.
+	Unit RateLimitUnit `json:"unit"`
+}
+
+// RateLimitDescriptor selects a request attribute to be used as part of a
+// RateLimit's descriptor.
+type RateLimitDescriptor struct {
+	// Kind is the request attribute from which the value is taken.
+	Kind RateLimitDescriptorKind `json:"kind"`
+
+	// Key names the header or cookie for header and cookie descriptors. It
+	// must be empty for other kinds.
+	Key string `json:"key"`
+
+	// Match is required for match descriptors, and must be empty for other
+	// kinds. It must not have a To datum.
+	Match *Match `json:"match"`
+}
+
+// RejectStatusOrDefault returns RejectStatus, or DefaultRateLimitRejectStatus
+// if it is zero.
+func (p RateLimitPolicy) RejectStatusOrDefault() int {
+	if p.RejectStatus == 0 {
+		return DefaultRateLimitRejectStatus
+	}
+	return p.RejectStatus
+}
+
+// Equals checks if two RateLimitPolicy objects are semantically equivalent.
+// They are considered equal iff they have the same RejectStatus and the same
+// set of Limits. A change in slice order is not considered a difference in
+// RateLimitPolicy objects, as RateLimits are identified by Name.
+func (p RateLimitPolicy) Equals(o RateLimitPolicy) bool {
+	if p.RejectStatus != o.RejectStatus || len(p.Limits) != len(o.Limits) {
+		return false
+	}
+
+	limits := map[string]RateLimit{}
+	for _, l := range p.Limits {
+		limits[l.Name] = l
+	}
+
+	checked := map[string]bool{}
+	for _, l := range o.Limits {
+		old, has := limits[l.Name]
+		if checked[l.Name] || !has || !old.Equals(l) {
+			return false
+		}
+		checked[l.Name] = true
+	}
+
+	return true
+}
+
+// Convenience function for calling Equals when you have pointers to two rate
+// limit policies.
+func RateLimitPolicyEquals(a, b *RateLimitPolicy) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	return a.Equals(*b)
+}
+
+// Checks validity of a rate limit policy. For a rate limit policy to be valid
+// it must have at least one RateLimit, each RateLimit must be valid and have a
+// unique Name, and RejectStatus must be zero or from 400 to 599.
+func (p RateLimitPolicy) IsValid() *ValidationError {
+	scope := func(s string) string { return "rate_limit_policy." + s }
+
+	errs := &ValidationError{}
+	if len(p.Limits) == 0 {
+		errs.AddNew(ErrorCase{scope("limits"), "must have at least one limit"})
+	}
+
+	seen := map[string]bool{}
+	for _, l := range p.Limits {
+		if seen[l.Name] {
+			errs.AddNew(ErrorCase{
+				scope("limits"),
+				fmt.Sprintf("name %q used multiple times", l.Name),
+			})
+		}
+		seen[l.Name] = true
+		errs.MergePrefixed(l.IsValid(), scope(fmt.Sprintf("limits[%v]", l.Name)))
+	}
+
+	if p.RejectStatus != 0 && (p.RejectStatus < 400 || p.RejectStatus > 599) {
+		errs.AddNew(ErrorCase{
+			scope("reject_status"),
+			fmt.Sprintf("%d is not a valid HTTP error status", p.RejectStatus),
+		})
+	}
+
+	return errs.OrNil()
+}
+
+// Equals compares two RateLimits. They are equal if each field is equal,
+// including the order of Descriptors.
+func (l RateLimit) Equals(o RateLimit) bool {
+	if l.Name != o.Name ||
+		l.RequestsPerUnit != o.RequestsPerUnit ||
+		l.Unit != o.Unit ||
+		len(l.Descriptors) != len(o.Descriptors) {
+		return false
+	}
+
+	for i := range l.Descriptors {
+		if !l.Descriptors[i].Equals(o.Descriptors[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsValid ensures that RateLimit attributes have reasonable values:
+//
+//   - Name must not be empty, and may not contain [ or ] characters
+//   - each Descriptor must be valid
+//   - RequestsPerUnit must be positive
+//   - Unit must be one of the defined RateLimitUnit values
+func (l RateLimit) IsValid() *ValidationError {
+	errs := &ValidationError{}
+
+	errCheckIndex(l.Name, errs, "name")
+
+	for i, d := range l.Descriptors {
+		errs.MergePrefixed(d.IsValid(), fmt.Sprintf("descriptors[%d]", i))
+	}
+
+	if l.RequestsPerUnit == 0 {
+		errs.AddNew(ErrorCase{"requests_per_unit", "must be positive"})
+	}
+
+	if l.Unit.Duration() == 0 {
+		errs.AddNew(ErrorCase{"unit", fmt.Sprintf("%q is not a valid unit", l.Unit)})
+	}
+
+	return errs.OrNil()
+}
+
+// Equals compares two RateLimitDescriptors. They are equal if each field is
+// equal.
+func (d RateLimitDescriptor) Equals(o RateLimitDescriptor) bool {
+	return d.Kind == o.Kind &&
+		d.Key == o.Key &&
+		matchPtrEquals(d.Match, o.Match)
+}
+
+// IsValid ensures that RateLimitDescriptor attributes have reasonable values:
+//
+//   - Kind must be one of the defined RateLimitDescriptorKind values
+//   - Key must be a valid header name for header descriptors, must not be
+//     empty for cookie descriptors, and must be empty otherwise
+//   - Match must be a valid Match with no To datum for match descriptors,
+//     and must be empty otherwise
+func (d RateLimitDescriptor) IsValid() *ValidationError {
+	errs := &ValidationError{}
+
+	switch d.Kind {
+	case HeaderRateLimitDescriptor:
+		errCheckPattern(false, d.Key, errs, HeaderNamePattern, "key", "")
+
+	case CookieRateLimitDescriptor:
+		errCheckIndex(d.Key, errs, "key")
+
+	case RemoteAddressRateLimitDescriptor, MatchRateLimitDescriptor:
+		if d.Key != "" {
+			errs.AddNew(ErrorCase{
+				"key",
+				fmt.Sprintf("must be empty for %q descriptors", d.Kind),
+			})
+		}
+
+	default:
+		errs.AddNew(ErrorCase{"kind", fmt.Sprintf("%q is not a valid descriptor kind", d.Kind)})
+	}
+
+	switch {
+	case d.Kind == MatchRateLimitDescriptor && d.Match == nil:
+		errs.AddNew(ErrorCase{"match", "may not be empty"})
+
+	case d.Kind == MatchRateLimitDescriptor:
+		if d.Match.To.Key != "" || d.Match.To.Value != "" {
+			errs.AddNew(ErrorCase{"match.to", "must be empty"})
+		}
+		errs.MergePrefixed(d.Match.IsValid(), "match")
+
+	case d.Match != nil:
+		errs.AddNew(ErrorCase{
+			"match",
+			fmt.Sprintf("must be empty unless kind is %q", MatchRateLimitDescriptor),
+		})
+	}
+
+	return errs.OrNil()
+}
+
+// RateLimitPolicyFor returns the RateLimitPolicy that applies to a request
+// handled by the given Domain and Route and, if non-nil, the Rule that
+// matched it. The most specific RateLimitPolicy set is returned, or nil if
+// none is set.
+func RateLimitPolicyFor(d Domain, r Route, rule *Rule) *RateLimitPolicy {
+	switch {
+	case rule != nil && rule.RateLimitPolicy != nil:
+		return rule.RateLimitPolicy
+	case r.RateLimitPolicy != nil:
+		return r.RateLimitPolicy
+	default:
+		return d.RateLimitPolicy
+	}
+}
@@ -0,0 +1,311 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/turbinelabs/test/assert"
+)
+
+func mkRLP() *RateLimitPolicy {
+	return &RateLimitPolicy{
+		Limits: []RateLimit{
+			{
+				Name:            "per-client",
+				Descriptors:     []RateLimitDescriptor{{Kind: RemoteAddressRateLimitDescriptor}},
+				RequestsPerUnit: 10,
+				Unit:            SecondRateLimitUnit,
+			},
+			{
+				Name: "beta-per-user",
+				Descriptors: []RateLimitDescriptor{
+					{
+						Kind: MatchRateLimitDescriptor,
+						Match: &Match{
+							Kind:     HeaderMatchKind,
+							Behavior: ExactMatchBehavior,
+							From:     Metadatum{Key: "x-beta", Value: "true"},
+						},
+					},
+					{Kind: CookieRateLimitDescriptor, Key: "user"},
+				},
+				RequestsPerUnit: 100,
+				Unit:            MinuteRateLimitUnit,
+			},
+		},
+	}
+}
+
+func TestRateLimitUnitDuration(t *testing.T) {
+	assert.Equal(t, SecondRateLimitUnit.Duration(), time.Second)
+	assert.Equal(t, MinuteRateLimitUnit.Duration(), time.Minute)
+	assert.Equal(t, HourRateLimitUnit.Duration(), time.Hour)
+	assert.Equal(t, DayRateLimitUnit.Duration(), 24*time.Hour)
+	assert.Equal(t, RateLimitUnit("week").Duration(), time.Duration(0))
+}
+
+func TestRateLimitPolicyRejectStatusOrDefault(t *testing.T) {
+	p := mkRLP()
+	assert.Equal(t, p.RejectStatusOrDefault(), 429)
+
+	p.RejectStatus = 503
+	assert.Equal(t, p.RejectStatusOrDefault(), 503)
+}
+
+func TestRateLimitPolicyEquals(t *testing.T) {
+	assert.True(t, mkRLP().Equals(*mkRLP()))
+	assert.True(t, RateLimitPolicyEquals(mkRLP(), mkRLP()))
+	assert.True(t, RateLimitPolicyEquals(nil, nil))
+	assert.False(t, RateLimitPolicyEquals(mkRLP(), nil))
+	assert.False(t, RateLimitPolicyEquals(nil, mkRLP()))
+
+	p := mkRLP()
+	p.Limits[0], p.Limits[1] = p.Limits[1], p.Limits[0]
+	assert.True(t, mkRLP().Equals(*p))
+	assert.True(t, p.Equals(*mkRLP()))
+}
+
+func TestRateLimitPolicyEqualsVaries(t *testing.T) {
+	for _, mutate := range []func(*RateLimitPolicy){
+		func(p *RateLimitPolicy) { p.RejectStatus = 503 },
+		func(p *RateLimitPolicy) { p.Limits = p.Limits[1:] },
+		func(p *RateLimitPolicy) { p.Limits[0].Name = "other" },
+		func(p *RateLimitPolicy) { p.Limits[0].RequestsPerUnit++ },
+		func(p *RateLimitPolicy) { p.Limits[0].Unit = HourRateLimitUnit },
+		func(p *RateLimitPolicy) { p.Limits[0].Descriptors = nil },
+		func(p *RateLimitPolicy) { p.Limits[0].Descriptors[0].Kind = HeaderRateLimitDescriptor },
+		func(p *RateLimitPolicy) { p.Limits[1].Descriptors[1].Key = "session" },
+		func(p *RateLimitPolicy) { p.Limits[1].Descriptors[0].Match.From.Value = "false" },
+		func(p *RateLimitPolicy) {
+			d := p.Limits[1].Descriptors
+			d[0], d[1] = d[1], d[0]
+		},
+	} {
+		p := mkRLP()
+		mutate(p)
+		assert.False(t, mkRLP().Equals(*p))
+		assert.False(t, p.Equals(*mkRLP()))
+	}
+}
+
+func TestRateLimitPolicyEqualsDuplicates(t *testing.T) {
+	p1 := mkRLP()
+	p1.Limits[1] = p1.Limits[0]
+	p2 := mkRLP()
+	assert.False(t, p1.Equals(*p2))
+	assert.False(t, p2.Equals(*p1))
+}
+
+func TestRateLimitPolicyJSON(t *testing.T) {
+	b, err := json.Marshal(RateLimitPolicy{
+		Limits: []RateLimit{
+			{
+				Name:            "all",
+				Descriptors:     []RateLimitDescriptor{{Kind: HeaderRateLimitDescriptor, Key: "x-api-key"}},
+				RequestsPerUnit: 5,
+				Unit:            DayRateLimitUnit,
+			},
+		},
+		RejectStatus: 503,
+	})
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		string(b),
+		`{"limits":[{"name":"all","descriptors":[{"kind":"header","key":"x-api-key","match":null}],`+
+			`"requests_per_unit":5,"unit":"day"}],"reject_status":503}`,
+	)
+
+	p := RateLimitPolicy{}
+	assert.Nil(t, json.Unmarshal(
+		[]byte(`{
+			"limits": [
+				{
+					"name": "per-client",
+					"descriptors": [{"kind": "remote_address"}],
+					"requests_per_unit": 10,
+					"unit": "second"
+				},
+				{
+					"name": "beta-per-user",
+					"descriptors": [
+						{"kind": "match", "match": {"kind": "header", "behavior": "exact", "from": {"key": "x-beta", "value": "true"}}},
+						{"kind": "cookie", "key": "user"}
+					],
+					"requests_per_unit": 100,
+					"unit": "minute"
+				}
+			]
+		}`),
+		&p,
+	))
+	assert.True(t, p.Equals(*mkRLP()))
+}
+
+func TestRateLimitPolicyIsValid(t *testing.T) {
+	assert.Nil(t, mkRLP().IsValid())
+
+	p := mkRLP()
+	p.RejectStatus = 503
+	p.Limits[0].Descriptors = nil
+	p.Limits[1].Descriptors = []RateLimitDescriptor{{Kind: HeaderRateLimitDescriptor, Key: "x-api-key"}}
+	assert.Nil(t, p.IsValid())
+}
+
+func TestRateLimitPolicyIsValidEmpty(t *testing.T) {
+	assert.DeepEqual(t, RateLimitPolicy{}.IsValid(), &ValidationError{[]ErrorCase{
+		{"rate_limit_policy.limits", "must have at least one limit"},
+	}})
+}
+
+func TestRateLimitPolicyIsValidBadRejectStatus(t *testing.T) {
+	for _, status := range []int{-1, 200, 399, 600} {
+		p := mkRLP()
+		p.RejectStatus = status
+		assert.DeepEqual(t, p.IsValid(), &ValidationError{[]ErrorCase{
+			{"rate_limit_policy.reject_status", fmt.Sprintf("%d is not a valid HTTP error status", status)},
+		}})
+	}
+}
+
+func TestRateLimitPolicyIsValidDuplicateName(t *testing.T) {
+	p := mkRLP()
+	p.Limits[1].Name = p.Limits[0].Name
+	assert.DeepEqual(t, p.IsValid(), &ValidationError{[]ErrorCase{
+		{"rate_limit_policy.limits", `name "per-client" used multiple times`},
+	}})
+}
+
+func TestRateLimitPolicyIsValidBadLimit(t *testing.T) {
+	p := mkRLP()
+	p.Limits[0].Name = "bad[name]"
+	p.Limits[0].RequestsPerUnit = 0
+	p.Limits[0].Unit = ""
+	assert.DeepEqual(t, p.IsValid(), &ValidationError{[]ErrorCase{
+		{"rate_limit_policy.limits[bad[name]].name", AllowedIndexPatternMatchFailure},
+		{"rate_limit_policy.limits[bad[name]].requests_per_unit", "must be positive"},
+		{"rate_limit_policy.limits[bad[name]].unit", `"" is not a valid unit`},
+	}})
+}
+
+func TestRateLimitDescriptorIsValid(t *testing.T) {
+	for _, d := range []RateLimitDescriptor{
+		{Kind: HeaderRateLimitDescriptor, Key: "X-Api-Key"},
+		{Kind: CookieRateLimitDescriptor, Key: "session"},
+		{Kind: RemoteAddressRateLimitDescriptor},
+		{
+			Kind: MatchRateLimitDescriptor,
+			Match: &Match{
+				Kind:     PathMatchKind,
+				Behavior: PrefixMatchBehavior,
+				From:     Metadatum{Value: "/api"},
+			},
+		},
+	} {
+		assert.Nil(t, d.IsValid())
+	}
+}
+
+func TestRateLimitDescriptorIsValidErrors(t *testing.T) {
+	mkMatch := func() *Match {
+		return &Match{
+			Kind:     HeaderMatchKind,
+			Behavior: ExactMatchBehavior,
+			From:     Metadatum{Key: "x-beta", Value: "true"},
+		}
+	}
+
+	for _, tc := range []struct {
+		descriptor RateLimitDescriptor
+		errs       []ErrorCase
+	}{
+		{
+			RateLimitDescriptor{Kind: "method"},
+			[]ErrorCase{{"kind", `"method" is not a valid descriptor kind`}},
+		},
+		{
+			RateLimitDescriptor{Kind: HeaderRateLimitDescriptor},
+			[]ErrorCase{{"key", "may not be empty"}},
+		},
+		{
+			RateLimitDescriptor{Kind: HeaderRateLimitDescriptor, Key: "x_api_key"},
+			[]ErrorCase{{"key", fmt.Sprintf("must match %v", HeaderNamePatternStr)}},
+		},
+		{
+			RateLimitDescriptor{Kind: CookieRateLimitDescriptor, Key: " "},
+			[]ErrorCase{{"key", "may not be empty"}},
+		},
+		{
+			RateLimitDescriptor{Kind: RemoteAddressRateLimitDescriptor, Key: "ip"},
+			[]ErrorCase{{"key", `must be empty for "remote_address" descriptors`}},
+		},
+		{
+			RateLimitDescriptor{Kind: CookieRateLimitDescriptor, Key: "session", Match: mkMatch()},
+			[]ErrorCase{{"match", `must be empty unless kind is "match"`}},
+		},
+		{
+			RateLimitDescriptor{Kind: MatchRateLimitDescriptor},
+			[]ErrorCase{{"match", "may not be empty"}},
+		},
+		{
+			RateLimitDescriptor{
+				Kind:  MatchRateLimitDescriptor,
+				Key:   "x-beta",
+				Match: &Match{Kind: HeaderMatchKind, Behavior: ExactMatchBehavior},
+			},
+			[]ErrorCase{
+				{"key", `must be empty for "match" descriptors`},
+				{"match.from.key", "may not be empty"},
+			},
+		},
+		{
+			RateLimitDescriptor{
+				Kind: MatchRateLimitDescriptor,
+				Match: &Match{
+					Kind:     HeaderMatchKind,
+					Behavior: ExactMatchBehavior,
+					From:     Metadatum{Key: "x-beta", Value: "true"},
+					To:       Metadatum{Key: "beta", Value: "true"},
+				},
+			},
+			[]ErrorCase{{"match.to", "must be empty"}},
+		},
+	} {
+		assert.DeepEqual(t, tc.descriptor.IsValid(), &ValidationError{tc.errs})
+	}
+}
+
+func TestRateLimitPolicyFor(t *testing.T) {
+	domainRLP := &RateLimitPolicy{RejectStatus: 501}
+	routeRLP := &RateLimitPolicy{RejectStatus: 502}
+	ruleRLP := &RateLimitPolicy{RejectStatus: 503}
+
+	d := Domain{RateLimitPolicy: domainRLP}
+	r := Route{RateLimitPolicy: routeRLP}
+	rule := Rule{RateLimitPolicy: ruleRLP}
+
+	assert.SameInstance(t, RateLimitPolicyFor(d, r, &rule), ruleRLP)
+	assert.SameInstance(t, RateLimitPolicyFor(d, r, nil), routeRLP)
+	assert.SameInstance(t, RateLimitPolicyFor(d, r, &Rule{}), routeRLP)
+	assert.SameInstance(t, RateLimitPolicyFor(d, Route{}, &Rule{}), domainRLP)
+	assert.SameInstance(t, RateLimitPolicyFor(Domain{}, Route{}, &rule), ruleRLP)
+	assert.Nil(t, RateLimitPolicyFor(Domain{}, Route{}, &Rule{}))
+}
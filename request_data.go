@@ -0,0 +1,258 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HeaderAction is an Enumeration of the ways a RequestHeaderDatum may modify
+// a request.
+type HeaderAction string
+
+const (
+	// AddHeaderAction adds a value to a request header, keeping any values
+	// already present.
+	AddHeaderAction HeaderAction = "add"
+
+	// OverwriteHeaderAction sets a request header, replacing any values
+	// already present.
+	OverwriteHeaderAction HeaderAction = "overwrite"
+
+	// RemoveHeaderAction removes a request header.
+	RemoveHeaderAction HeaderAction = "remove"
+)
+
+/*
+	RequestData is a collection of modifications that should be applied to
+	requests before they are sent to an Instance. It is the request-side
+	counterpart of ResponseData.
+
+	A RequestHeaderDatum's Value is either a literal or a reference to an
+	attribute of the request being modified. References take the form
+	"<kind>:<key>" for cookie, header, and query attributes, and "<kind>" for
+	path, scheme, source_ip, and host attributes, where kind is a MatchKind.
+
+	Example:
+
+		RequestData{
+			Headers: []RequestHeaderDatum{
+				{Name: "X-Env", Action: OverwriteHeaderAction, Value: "canary", ValueIsLiteral: true},
+				{Name: "X-Session", Action: AddHeaderAction, Value: "cookie:session"},
+				{Name: "X-Client-IP", Action: OverwriteHeaderAction, Value: "source_ip"},
+				{Name: "X-Debug", Action: RemoveHeaderAction},
+			},
+		}
+
+	would set X-Env to "canary", add the value of the session cookie to
+	X-Session, set X-Client-IP to the request's source IP, and remove X-Debug.
+*/
+type RequestData struct {
+	// Headers are HTTP headers that will be added to, overwritten on, or
+	// removed from a request.
+	Headers []RequestHeaderDatum `json:"headers,omitempty"`
+}
+
+// Equals checks if two RequestData objects are semantically equivalent. They
+// are considered equal iff they contain the same set of Headers. A change in
+// slice order is not considered a difference in RequestData objects.
+//
+// A RequestHeaderDatum is identified by its Name attribute via
+// case-insensitive comparison.
+func (rd RequestData) Equals(o RequestData) bool {
+	if len(rd.Headers) != len(o.Headers) {
+		return false
+	}
+
+	hdrs := map[string]RequestHeaderDatum{}
+	checked := map[string]bool{}
+	for _, hdr := range rd.Headers {
+		hdrs[hdr.CanonicalName()] = hdr
+	}
+
+	for _, hdr := range o.Headers {
+		n := hdr.CanonicalName()
+		old, has := hdrs[n]
+		if checked[n] || !has || !old.Equals(hdr) {
+			return false
+		}
+		checked[n] = true
+	}
+
+	return true
+}
+
+// IsValid verifies that each header is valid and unique within the
+// RequestData. Header names are not case sensitive.
+func (rd RequestData) IsValid() *ValidationError {
+	errs := &ValidationError{}
+
+	seen := map[string]int{}
+	for _, hdr := range rd.Headers {
+		seen[hdr.CanonicalName()]++
+		if seen[hdr.CanonicalName()] > 1 {
+			errs.AddNew(ErrorCase{"headers", fmt.Sprintf("Header %q modified multiple times", hdr.Name)})
+		}
+		parent := fmt.Sprintf("headers[%v]", hdr.Name)
+		errs.MergePrefixed(hdr.IsValid(), parent)
+	}
+
+	return errs.OrNil()
+}
+
+// Len returns the total number of RequestData headers.
+func (rd RequestData) Len() int {
+	return len(rd.Headers)
+}
+
+// MergeFrom combines two RequestData objects into a single, new RequestData.
+// The headers in the given RequestData override (by name) those in the
+// receiver RequestData, keeping the original RequestData's ordering.
+// Additional headers from the overrides are appended, also maintaining their
+// order. Both source RequestData objects are assumed to be valid.
+func (rd RequestData) MergeFrom(overrides RequestData) RequestData {
+	merged := RequestData{}
+
+	// Remember where the overrides are by name.
+	overrideIndexes := map[string]int{}
+	for idx, header := range overrides.Headers {
+		overrideIndexes[header.CanonicalName()] = idx
+	}
+
+	// For each header in the receiver, copy it to the result unless
+	// the overrides have a header with the same name.
+	for _, header := range rd.Headers {
+		name := header.CanonicalName()
+		headerToMerge := header
+		if idx, found := overrideIndexes[name]; found {
+			headerToMerge = overrides.Headers[idx]
+			delete(overrideIndexes, name)
+		}
+		merged.Headers = append(merged.Headers, headerToMerge)
+	}
+
+	// Copy remaining override headers into result.
+	for _, header := range overrides.Headers {
+		if _, remains := overrideIndexes[header.CanonicalName()]; remains {
+			merged.Headers = append(merged.Headers, header)
+		}
+	}
+
+	return merged
+}
+
+// RequestHeaderDatum represents a modification of a header on requests
+// served by the object containing a RequestData config. RequestHeaderDatum
+// are not case sensitive with respect to their Name value, which impacts
+// equality checks.
+type RequestHeaderDatum struct {
+	// Name of the header being modified.
+	Name string `json:"name"`
+
+	// Action determines how the header is modified.
+	Action HeaderAction `json:"action"`
+
+	// Value is either a literal value or a reference to an attribute of the
+	// request. It must be empty if Action is RemoveHeaderAction.
+	Value string `json:"value,omitempty"`
+
+	// ValueIsLiteral, if set, means that Value will be treated as a literal
+	// instead of a reference to be resolved against the request.
+	ValueIsLiteral bool `json:"value_is_literal,omitempty"`
+}
+
+// Equals compares two RequestHeaderDatum objects. A RequestHeaderDatum is
+// determined to be equal if the name (case insensitive check), action,
+// value, and ValueIsLiteral attributes are equal.
+func (hd RequestHeaderDatum) Equals(o RequestHeaderDatum) bool {
+	return hd.CanonicalName() == o.CanonicalName() &&
+		hd.Action == o.Action &&
+		hd.Value == o.Value &&
+		hd.ValueIsLiteral == o.ValueIsLiteral
+}
+
+// IsValid ensures that RequestHeaderDatum attributes have reasonable values:
+//
+//   - Name must not be empty
+//   - Name must be a valid header
+//   - Action must be one of the defined HeaderAction values
+//   - Value may not be empty, unless Action is RemoveHeaderAction, in which
+//     case it must be empty
+//   - Value must be a valid request attribute reference, unless
+//     ValueIsLiteral is set
+func (hd RequestHeaderDatum) IsValid() *ValidationError {
+	errs := &ValidationError{}
+
+	errCheckPattern(false, hd.Name, errs, HeaderNamePattern, "name", "")
+
+	switch hd.Action {
+	case AddHeaderAction, OverwriteHeaderAction:
+		if strings.TrimSpace(hd.Value) == "" {
+			errs.AddNew(ErrorCase{"value", "may not be empty"})
+		} else if !hd.ValueIsLiteral {
+			if _, _, err := hd.Attribute(); err != nil {
+				errs.AddNew(ErrorCase{"value", err.Error()})
+			}
+		}
+
+	case RemoveHeaderAction:
+		if hd.Value != "" || hd.ValueIsLiteral {
+			errs.AddNew(ErrorCase{
+				"value",
+				fmt.Sprintf("must be empty if action is %q", RemoveHeaderAction),
+			})
+		}
+
+	default:
+		errs.AddNew(ErrorCase{"action", fmt.Sprintf("%q is not a valid action", hd.Action)})
+	}
+
+	return errs.OrNil()
+}
+
+// Attribute parses the request attribute referenced by Value, returning its
+// MatchKind and, for cookie, header, and query attributes, its key. An error
+// is returned if Value is not a valid reference. The result is meaningless
+// if ValueIsLiteral is set.
+func (hd RequestHeaderDatum) Attribute() (MatchKind, string, error) {
+	parts := strings.SplitN(hd.Value, ":", 2)
+	kind := MatchKind(parts[0])
+
+	switch {
+	case kind.keyless():
+		if len(parts) > 1 {
+			return "", "", fmt.Errorf("%q attribute may not have a key", kind)
+		}
+		return kind, "", nil
+
+	case kind == CookieMatchKind, kind == HeaderMatchKind, kind == QueryMatchKind:
+		if len(parts) == 1 || strings.TrimSpace(parts[1]) == "" {
+			return "", "", fmt.Errorf("%q attribute must have a key", kind)
+		}
+		return kind, parts[1], nil
+
+	default:
+		return "", "", fmt.Errorf("%q is not a valid request attribute", hd.Value)
+	}
+}
+
+// CanonicalName returns a canonical name for the header, suitable for
+// comparison across RequestHeaderDatum.
+func (hd RequestHeaderDatum) CanonicalName() string {
+	return strings.ToLower(hd.Name)
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/turbinelabs/test/assert"
+)
+
+func mkRHD() RequestHeaderDatum {
+	return RequestHeaderDatum{
+		"X-Header-Name",
+		AddHeaderAction,
+		"header:X-Other-Header",
+		false,
+	}
+}
+
+func mkReqD() RequestData {
+	h1 := mkRHD()
+	h2 := mkRHD()
+	h2.Name += "-header2"
+	h2.Action = OverwriteHeaderAction
+	h2.Value = "literal"
+	h2.ValueIsLiteral = true
+	h3 := mkRHD()
+	h3.Name += "-header3"
+	h3.Action = RemoveHeaderAction
+	h3.Value = ""
+
+	return RequestData{[]RequestHeaderDatum{h1, h2, h3}}
+}
+
+func TestRequestHeaderDatumEquals(t *testing.T) {
+	hd := mkRHD()
+	assert.True(t, mkRHD().Equals(hd))
+
+	hd.Name = strings.ToUpper(hd.Name)
+	assert.True(t, mkRHD().Equals(hd))
+}
+
+func TestRequestHeaderDatumEqualsVaries(t *testing.T) {
+	for _, mutate := range []func(*RequestHeaderDatum){
+		func(hd *RequestHeaderDatum) { hd.Name += "-new" },
+		func(hd *RequestHeaderDatum) { hd.Action = OverwriteHeaderAction },
+		func(hd *RequestHeaderDatum) { hd.Value += "-new" },
+		func(hd *RequestHeaderDatum) { hd.ValueIsLiteral = true },
+	} {
+		hd := mkRHD()
+		mutate(&hd)
+		assert.False(t, mkRHD().Equals(hd))
+		assert.False(t, hd.Equals(mkRHD()))
+	}
+}
+
+func TestRequestHeaderDatumCanonicalName(t *testing.T) {
+	hd := mkRHD()
+	assert.Equal(t, hd.CanonicalName(), "x-header-name")
+}
+
+func TestRequestHeaderDatumIsValid(t *testing.T) {
+	for _, hd := range mkReqD().Headers {
+		assert.Nil(t, hd.IsValid())
+	}
+}
+
+func TestRequestHeaderDatumIsValidNoName(t *testing.T) {
+	hd := mkRHD()
+	hd.Name = ""
+	assert.DeepEqual(t, hd.IsValid(), &ValidationError{[]ErrorCase{
+		{"name", "may not be empty"},
+	}})
+}
+
+func TestRequestHeaderDatumIsValidBadName(t *testing.T) {
+	hd := mkRHD()
+	hd.Name = "x-header_foo"
+	assert.DeepEqual(t, hd.IsValid(), &ValidationError{[]ErrorCase{
+		{"name", fmt.Sprintf("must match %v", HeaderNamePatternStr)},
+	}})
+}
+
+func TestRequestHeaderDatumIsValidBadAction(t *testing.T) {
+	hd := mkRHD()
+	hd.Action = ""
+	assert.DeepEqual(t, hd.IsValid(), &ValidationError{[]ErrorCase{
+		{"action", `"" is not a valid action`},
+	}})
+}
+
+func TestRequestHeaderDatumIsValidNoValue(t *testing.T) {
+	for _, action := range []HeaderAction{AddHeaderAction, OverwriteHeaderAction} {
+		hd := mkRHD()
+		hd.Action = action
+		hd.Value = " "
+		assert.DeepEqual(t, hd.IsValid(), &ValidationError{[]ErrorCase{
+			{"value", "may not be empty"},
+		}})
+	}
+}
+
+func TestRequestHeaderDatumIsValidRemoveWithValue(t *testing.T) {
+	hd := mkRHD()
+	hd.Action = RemoveHeaderAction
+	assert.DeepEqual(t, hd.IsValid(), &ValidationError{[]ErrorCase{
+		{"value", `must be empty if action is "remove"`},
+	}})
+}
+
+func TestRequestHeaderDatumIsValidBadAttribute(t *testing.T) {
+	hd := mkRHD()
+	hd.Value = "method"
+	assert.DeepEqual(t, hd.IsValid(), &ValidationError{[]ErrorCase{
+		{"value", `"method" is not a valid request attribute`},
+	}})
+
+	hd.ValueIsLiteral = true
+	assert.Nil(t, hd.IsValid())
+}
+
+func TestRequestHeaderDatumAttribute(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		kind  MatchKind
+		key   string
+	}{
+		{"header:x-foo", HeaderMatchKind, "x-foo"},
+		{"cookie:session", CookieMatchKind, "session"},
+		{"query:a:b", QueryMatchKind, "a:b"},
+		{"path", PathMatchKind, ""},
+		{"scheme", SchemeMatchKind, ""},
+		{"source_ip", SourceIPMatchKind, ""},
+		{"host", HostMatchKind, ""},
+	} {
+		hd := mkRHD()
+		hd.Value = tc.value
+		kind, key, err := hd.Attribute()
+		assert.Nil(t, err)
+		assert.Equal(t, kind, tc.kind)
+		assert.Equal(t, key, tc.key)
+	}
+}
+
+func TestRequestHeaderDatumAttributeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		err   string
+	}{
+		{"header", `"header" attribute must have a key`},
+		{"cookie: ", `"cookie" attribute must have a key`},
+		{"path:/foo", `"path" attribute may not have a key`},
+		{"Header:x-foo", `"Header:x-foo" is not a valid request attribute`},
+		{"", `"" is not a valid request attribute`},
+	} {
+		hd := mkRHD()
+		hd.Value = tc.value
+		_, _, err := hd.Attribute()
+		assert.ErrorContains(t, err, tc.err)
+	}
+}
+
+func TestRequestDataEquals(t *testing.T) {
+	rd := mkReqD()
+	assert.True(t, mkReqD().Equals(rd))
+
+	rd.Headers[0], rd.Headers[2] = rd.Headers[2], rd.Headers[0]
+	assert.True(t, mkReqD().Equals(rd))
+
+	rd.Headers[1].Value += "new-value"
+	assert.False(t, mkReqD().Equals(rd))
+	assert.False(t, rd.Equals(mkReqD()))
+
+	rd = mkReqD()
+	rd.Headers = rd.Headers[1:]
+	assert.False(t, mkReqD().Equals(rd))
+	assert.False(t, rd.Equals(mkReqD()))
+}
+
+func TestRequestDataEqualsDuplicates(t *testing.T) {
+	rd1 := RequestData{[]RequestHeaderDatum{mkRHD(), mkRHD()}}
+	rd2 := mkReqD()
+	rd2.Headers = rd2.Headers[0:2]
+	assert.False(t, rd1.Equals(rd2))
+	assert.False(t, rd2.Equals(rd1))
+}
+
+func TestRequestDataIsValid(t *testing.T) {
+	assert.Nil(t, mkReqD().IsValid())
+	assert.Nil(t, RequestData{}.IsValid())
+}
+
+func TestRequestDataIsValidBadHeader(t *testing.T) {
+	rd := mkReqD()
+	rd.Headers[1].Name = ""
+	assert.DeepEqual(t, rd.IsValid(), &ValidationError{[]ErrorCase{
+		{"headers[].name", "may not be empty"},
+	}})
+}
+
+func TestRequestDataIsValidDuplicateHeadersCaseDiffers(t *testing.T) {
+	rd := mkReqD()
+	rd.Headers = append(rd.Headers, rd.Headers[1])
+	rd.Headers[3].Name = strings.ToUpper(rd.Headers[3].Name)
+	n := rd.Headers[3].Name
+
+	assert.DeepEqual(t, rd.IsValid(), &ValidationError{[]ErrorCase{
+		{"headers", `Header "` + n + `" modified multiple times`},
+	}})
+}
+
+func TestRequestDataLen(t *testing.T) {
+	assert.Equal(t, mkReqD().Len(), 3)
+	assert.Equal(t, RequestData{}.Len(), 0)
+}
+
+func TestRequestDataMergeFromTrivial(t *testing.T) {
+	rd := mkReqD()
+	rd2 := mkReqD()
+
+	assert.DeepEqual(t, rd.MergeFrom(rd2), mkReqD())
+	assert.DeepEqual(t, rd.MergeFrom(RequestData{}), mkReqD())
+}
+
+func TestRequestDataMergeFrom(t *testing.T) {
+	recv := RequestData{
+		Headers: []RequestHeaderDatum{
+			{Name: "x-a", Action: AddHeaderAction, Value: "v", ValueIsLiteral: true},
+			{Name: "x-b", Action: AddHeaderAction, Value: "v", ValueIsLiteral: true},
+			{Name: "x-c", Action: RemoveHeaderAction},
+		},
+	}
+
+	over := RequestData{
+		Headers: []RequestHeaderDatum{
+			{Name: "X-0", Action: OverwriteHeaderAction, Value: "host"},
+			{Name: "X-B", Action: RemoveHeaderAction},
+			{Name: "X-C", Action: OverwriteHeaderAction, Value: "path"},
+		},
+	}
+
+	expected := RequestData{
+		Headers: []RequestHeaderDatum{
+			{Name: "x-a", Action: AddHeaderAction, Value: "v", ValueIsLiteral: true},
+			{Name: "X-B", Action: RemoveHeaderAction},
+			{Name: "X-C", Action: OverwriteHeaderAction, Value: "path"},
+			{Name: "X-0", Action: OverwriteHeaderAction, Value: "host"},
+		},
+	}
+
+	assert.DeepEqual(t, recv.MergeFrom(over), expected)
+}
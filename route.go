@@ -41,18 +41,24 @@ type RouteKey string
 	Rule (regardless of the Rule source).
 
 	See CohortSeed docs for additional details of what a cohort seed does.
+	A FaultInjection resolves the same way; see FaultInjectionFor. A
+	RateLimitPolicy set on a Route overrides one set on its Domain; see
+	RateLimitPolicyFor.
 */
 type Route struct {
-	RouteKey       RouteKey       `json:"route_key"` // overwritten for create
-	DomainKey      DomainKey      `json:"domain_key"`
-	ZoneKey        ZoneKey        `json:"zone_key"`
-	Path           string         `json:"path"`
-	SharedRulesKey SharedRulesKey `json:"shared_rules_key"`
-	Rules          Rules          `json:"rules"`
-	ResponseData   ResponseData   `json:"response_data"`
-	CohortSeed     *CohortSeed    `json:"cohort_seed"`
-	RetryPolicy    *RetryPolicy   `json:"retry_policy"`
-	OrgKey         OrgKey         `json:"-"`
+	RouteKey        RouteKey         `json:"route_key"` // overwritten for create
+	DomainKey       DomainKey        `json:"domain_key"`
+	ZoneKey         ZoneKey          `json:"zone_key"`
+	Path            string           `json:"path"`
+	SharedRulesKey  SharedRulesKey   `json:"shared_rules_key"`
+	Rules           Rules            `json:"rules"`
+	ResponseData    ResponseData     `json:"response_data"`
+	RequestData     RequestData      `json:"request_data"`
+	CohortSeed      *CohortSeed      `json:"cohort_seed"`
+	RetryPolicy     *RetryPolicy     `json:"retry_policy"`
+	FaultInjection  *FaultInjection  `json:"fault_injection"`
+	RateLimitPolicy *RateLimitPolicy `json:"rate_limit_policy"`
+	OrgKey          OrgKey           `json:"-"`
 	Checksum
 }
 
@@ -78,12 +84,15 @@ func (r Route) Equals(o Route) bool {
 		eqOrg    = r.OrgKey == o.OrgKey
 		eqSRKey  = r.SharedRulesKey == o.SharedRulesKey
 		eqRd     = r.ResponseData.Equals(o.ResponseData)
+		eqReqD   = r.RequestData.Equals(o.RequestData)
 		eqCohort = CohortSeedPtrEquals(r.CohortSeed, o.CohortSeed)
 		eqRp     = RetryPolicyEquals(r.RetryPolicy, o.RetryPolicy)
+		eqFi     = FaultInjectionEquals(r.FaultInjection, o.FaultInjection)
+		eqRlp    = RateLimitPolicyEquals(r.RateLimitPolicy, o.RateLimitPolicy)
 	)
 
 	if !(eqKey && eqDom && eqZone && eqPath && eqCS &&
-		eqOrg && eqSRKey && eqRd && eqCohort && eqRp) {
+		eqOrg && eqSRKey && eqRd && eqReqD && eqCohort && eqRp && eqFi && eqRlp) {
 		return false
 	}
 
@@ -108,12 +117,19 @@ func (r Route) IsValid() *ValidationError {
 
 	errs.MergePrefixed(r.Rules.IsValid(), "route")
 	errs.MergePrefixed(r.ResponseData.IsValid(), scope("response_data"))
+	errs.MergePrefixed(r.RequestData.IsValid(), scope("request_data"))
 	if r.CohortSeed != nil {
 		errs.MergePrefixed(r.CohortSeed.IsValid(), "route")
 	}
 	if r.RetryPolicy != nil {
 		errs.MergePrefixed(r.RetryPolicy.IsValid(), "route")
 	}
+	if r.FaultInjection != nil {
+		errs.MergePrefixed(r.FaultInjection.IsValid(), "route")
+	}
+	if r.RateLimitPolicy != nil {
+		errs.MergePrefixed(r.RateLimitPolicy.IsValid(), "route")
+	}
 
 	return errs.OrNil()
 }
@@ -43,7 +43,9 @@ func getRulesDefaults() (Rule, Rule) {
 		},
 		AllConstraints{
 			Light: ClusterConstraints{
-				ClusterConstraint{"cckey1", "ckey2", Metadata{{"key-2", "value-2"}}, nil, ResponseData{}, 1234}}},
+				ClusterConstraint{"cckey1", "ckey2", Metadata{{"key-2", "value-2"}}, nil, ResponseData{}, RequestData{}, 1234}}},
+		nil,
+		nil,
 		nil,
 	}
 
@@ -60,9 +62,11 @@ func getRulesDefaults() (Rule, Rule) {
 		},
 		AllConstraints{
 			Tap: ClusterConstraints{
-				ClusterConstraint{"cckey1", "ckey3", Metadata{{"key-2", "value-2"}}, nil, ResponseData{}, 1234}},
+				ClusterConstraint{"cckey1", "ckey3", Metadata{{"key-2", "value-2"}}, nil, ResponseData{}, RequestData{}, 1234}},
 			Light: ClusterConstraints{
-				ClusterConstraint{"cckey2", "ckey2", Metadata{{"key-2", "value-2"}}, nil, ResponseData{}, 1234}}},
+				ClusterConstraint{"cckey2", "ckey2", Metadata{{"key-2", "value-2"}}, nil, ResponseData{}, RequestData{}, 1234}}},
+		nil,
+		nil,
 		nil,
 	}
 
@@ -82,8 +86,11 @@ func getRouteDefaults() (Route, Route) {
 		srk,
 		rules,
 		getRD(),
+		getReqD(),
 		&CohortSeed{CohortSeedHeader, "x-cohort-seed", true},
 		&RetryPolicy{1, 30, 60},
+		mkFI(),
+		mkRLP(),
 		"1",
 		Checksum{"cs-1"},
 	}
@@ -96,8 +103,11 @@ func getRouteDefaults() (Route, Route) {
 		srk,
 		rules,
 		getRD(),
+		getReqD(),
 		&CohortSeed{CohortSeedHeader, "x-cohort-seed", true},
 		&RetryPolicy{1, 30, 60},
+		mkFI(),
+		mkRLP(),
 		"1",
 		Checksum{"cs-1"},
 	}
@@ -138,6 +148,38 @@ func TestRouteEqualsRetryPolicyNilNil(t *testing.T) {
 	assert.True(t, r2.Equals(r1))
 }
 
+func TestRouteEqualsFaultInjectionVaries(t *testing.T) {
+	r1, r2 := getRouteDefaults()
+	r2.FaultInjection.Abort.HTTPStatus = 500
+
+	assert.False(t, r1.Equals(r2))
+	assert.False(t, r2.Equals(r1))
+}
+
+func TestRouteEqualsFaultInjectionNotNilNil(t *testing.T) {
+	r1, r2 := getRouteDefaults()
+	r2.FaultInjection = nil
+
+	assert.False(t, r1.Equals(r2))
+	assert.False(t, r2.Equals(r1))
+}
+
+func TestRouteEqualsRateLimitPolicyVaries(t *testing.T) {
+	r1, r2 := getRouteDefaults()
+	r2.RateLimitPolicy.Limits[0].RequestsPerUnit++
+
+	assert.False(t, r1.Equals(r2))
+	assert.False(t, r2.Equals(r1))
+}
+
+func TestRouteEqualsRateLimitPolicyNotNilNil(t *testing.T) {
+	r1, r2 := getRouteDefaults()
+	r2.RateLimitPolicy = nil
+
+	assert.False(t, r1.Equals(r2))
+	assert.False(t, r2.Equals(r1))
+}
+
 func TestRouteEqualsCohortSeedVaries(t *testing.T) {
 	r1, r2 := getRouteDefaults()
 	r2.CohortSeed.Name = r1.CohortSeed.Name + "aosentuh"
@@ -163,6 +205,14 @@ func TestRouteEqualsCohortSeedNilNil(t *testing.T) {
 	assert.True(t, r2.Equals(r1))
 }
 
+func TestRouteEqualsRequestDataVaries(t *testing.T) {
+	r1, r2 := getRouteDefaults()
+	r1.RequestData.Headers[0].Value += "aosenuth"
+
+	assert.False(t, r1.Equals(r2))
+	assert.False(t, r2.Equals(r1))
+}
+
 func TestRouteEqualsResponseDataVaries(t *testing.T) {
 	r1, r2 := getRouteDefaults()
 	r1.ResponseData.Headers[0].Value += "aosenuth"
@@ -268,6 +318,24 @@ func TestRouteIsValidBadRetryPolicy(t *testing.T) {
 	}})
 }
 
+func TestRouteIsValidBadFaultInjection(t *testing.T) {
+	r, _ := getRouteDefaults()
+	r.FaultInjection.Delay.DelayMsec = -1
+
+	assert.DeepEqual(t, r.IsValid(), &ValidationError{[]ErrorCase{
+		{"route.fault_injection.delay.delay_msec", "must not be negative"},
+	}})
+}
+
+func TestRouteIsValidBadRateLimitPolicy(t *testing.T) {
+	r, _ := getRouteDefaults()
+	r.RateLimitPolicy.RejectStatus = 200
+
+	assert.DeepEqual(t, r.IsValid(), &ValidationError{[]ErrorCase{
+		{"route.rate_limit_policy.reject_status", "200 is not a valid HTTP error status"},
+	}})
+}
+
 func TestRouteIsValidBadCohortSeed(t *testing.T) {
 	r, _ := getRouteDefaults()
 	r.CohortSeed.Name = ""
@@ -277,6 +345,16 @@ func TestRouteIsValidBadCohortSeed(t *testing.T) {
 	}})
 }
 
+func TestRouteIsValidBadRequestData(t *testing.T) {
+	r, _ := getRouteDefaults()
+	r.RequestData.Headers[0].Action = "replace"
+	n := r.RequestData.Headers[0].Name
+
+	assert.DeepEqual(t, r.IsValid(), &ValidationError{[]ErrorCase{
+		{"route.request_data.headers[" + n + "].action", `"replace" is not a valid action`},
+	}})
+}
+
 func TestRouteIsValidBadResponseData(t *testing.T) {
 	r, _ := getRouteDefaults()
 	r.ResponseData.Headers[0].Value = ""
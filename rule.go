@@ -40,13 +40,17 @@ type RuleKey string
 	of the rule source (SharedRules or Route).
 
 	See CohortSeed docs for additional details of what a cohort seed does.
+	A FaultInjection or RateLimitPolicy set on a Rule takes precedence in the
+	same way.
 */
 type Rule struct {
-	RuleKey     RuleKey        `json:"rule_key"`
-	Methods     []string       `json:"methods"`
-	Matches     Matches        `json:"matches"`
-	Constraints AllConstraints `json:"constraints"`
-	CohortSeed  *CohortSeed    `json:"cohort_seed"`
+	RuleKey         RuleKey          `json:"rule_key"`
+	Methods         []string         `json:"methods"`
+	Matches         Matches          `json:"matches"`
+	Constraints     AllConstraints   `json:"constraints"`
+	CohortSeed      *CohortSeed      `json:"cohort_seed"`
+	FaultInjection  *FaultInjection  `json:"fault_injection"`
+	RateLimitPolicy *RateLimitPolicy `json:"rate_limit_policy"`
 }
 
 type Rules []Rule
@@ -159,18 +163,13 @@ func (r Rule) Equals(o Rule) bool {
 		return false
 	}
 
-	return CohortSeedPtrEquals(r.CohortSeed, o.CohortSeed)
-}
-
-var validMethod map[string]bool = map[string]bool{
-	"GET":    true,
-	"PUT":    true,
-	"POST":   true,
-	"DELETE": true,
+	return CohortSeedPtrEquals(r.CohortSeed, o.CohortSeed) &&
+		FaultInjectionEquals(r.FaultInjection, o.FaultInjection) &&
+		RateLimitPolicyEquals(r.RateLimitPolicy, o.RateLimitPolicy)
 }
 
 // Checks this rule for validity. A rule is considered valid if it has a RuleKey,
-// at least one valid HTTP method (GET, PUT, POST, DELETE), the defined
+// at least one valid HTTP method (see MethodPattern) or match, the defined
 // matches are valid, and the Constraints are valid.
 func (r Rule) IsValid() *ValidationError {
 	ecase := func(f, m string) ErrorCase {
@@ -182,7 +181,7 @@ func (r Rule) IsValid() *ValidationError {
 	errCheckKey(string(r.RuleKey), errs, "rule_key")
 
 	for _, m := range r.Methods {
-		if !validMethod[m] {
+		if !MethodPattern.MatchString(m) {
 			errs.AddNew(ecase(
 				"methods",
 				fmt.Sprintf("%s is not a valid method", m),
@@ -199,6 +198,12 @@ func (r Rule) IsValid() *ValidationError {
 	if r.CohortSeed != nil {
 		errs.MergePrefixed(r.CohortSeed.IsValid(), "")
 	}
+	if r.FaultInjection != nil {
+		errs.MergePrefixed(r.FaultInjection.IsValid(), "")
+	}
+	if r.RateLimitPolicy != nil {
+		errs.MergePrefixed(r.RateLimitPolicy.IsValid(), "")
+	}
 
 	return errs.OrNil()
 }
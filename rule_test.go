@@ -49,6 +49,7 @@ func getRules() (Rule, Rule) {
 					Metadata{{"key", "value"}, {"key2", "value2"}},
 					nil,
 					ResponseData{},
+					RequestData{},
 					1234},
 				ClusterConstraint{
 					"cckey2",
@@ -56,8 +57,11 @@ func getRules() (Rule, Rule) {
 					Metadata{{"key-2", "value-2"}},
 					Metadata{{"state", "testing"}},
 					ResponseData{},
+					RequestData{},
 					1234}}},
 		&CohortSeed{CohortSeedHeader, "x-cohort-seed", true},
+		mkFI(),
+		mkRLP(),
 	}
 
 	r2 := Rule{
@@ -85,6 +89,7 @@ func getRules() (Rule, Rule) {
 					Metadata{{"key", "value"}, {"key2", "value2"}},
 					nil,
 					ResponseData{},
+					RequestData{},
 					1234},
 				ClusterConstraint{
 					"cckey2",
@@ -92,8 +97,11 @@ func getRules() (Rule, Rule) {
 					Metadata{{"key-2", "value-2"}},
 					Metadata{{"state", "testing"}},
 					ResponseData{},
+					RequestData{},
 					1234}}},
 		&CohortSeed{CohortSeedHeader, "x-cohort-seed", true},
+		mkFI(),
+		mkRLP(),
 	}
 
 	return r1, r2
@@ -141,6 +149,38 @@ func TestRuleEqualsFailureCohortNotNilNil(t *testing.T) {
 	assert.False(t, r2.Equals(r1))
 }
 
+func TestRuleEqualsFailureFaultInjectionVaries(t *testing.T) {
+	r1, r2 := getRules()
+	r1.FaultInjection.Match.From.Value = "false"
+
+	assert.False(t, r1.Equals(r2))
+	assert.False(t, r2.Equals(r1))
+}
+
+func TestRuleEqualsFailureFaultInjectionNilNotNil(t *testing.T) {
+	r1, r2 := getRules()
+	r1.FaultInjection = nil
+
+	assert.False(t, r1.Equals(r2))
+	assert.False(t, r2.Equals(r1))
+}
+
+func TestRuleEqualsFailureRateLimitPolicyVaries(t *testing.T) {
+	r1, r2 := getRules()
+	r1.RateLimitPolicy.Limits[1].Descriptors[1].Key = "other"
+
+	assert.False(t, r1.Equals(r2))
+	assert.False(t, r2.Equals(r1))
+}
+
+func TestRuleEqualsFailureRateLimitPolicyNilNotNil(t *testing.T) {
+	r1, r2 := getRules()
+	r1.RateLimitPolicy = nil
+
+	assert.False(t, r1.Equals(r2))
+	assert.False(t, r2.Equals(r1))
+}
+
 func TestRuleEqualsKeyMismatchFailure(t *testing.T) {
 	r1, r2 := getRules()
 	r2.RuleKey = "rkey2"
@@ -169,7 +209,7 @@ func TestRuleEqualsConstraintsMismatch(t *testing.T) {
 	r1, r2 := getRules()
 	r2.Constraints = AllConstraints{
 		Light: ClusterConstraints{
-			ClusterConstraint{"cckey1", "ckey2", Metadata{{"key-2", "value-2"}}, nil, ResponseData{}, 1234}},
+			ClusterConstraint{"cckey1", "ckey2", Metadata{{"key-2", "value-2"}}, nil, ResponseData{}, RequestData{}, 1234}},
 	}
 
 	assert.False(t, r1.Equals(r2))
@@ -198,6 +238,24 @@ func TestRuleIsValidBadCohort(t *testing.T) {
 	}})
 }
 
+func TestRuleIsValidBadFaultInjection(t *testing.T) {
+	r := getRuleValid()
+	r.FaultInjection.Abort.Percent = 0
+
+	assert.DeepEqual(t, r.IsValid(), &ValidationError{[]ErrorCase{
+		{"fault_injection.abort.percent", "must be greater than 0 and at most 100"},
+	}})
+}
+
+func TestRuleIsValidBadRateLimitPolicy(t *testing.T) {
+	r := getRuleValid()
+	r.RateLimitPolicy.Limits[0].Unit = "week"
+
+	assert.DeepEqual(t, r.IsValid(), &ValidationError{[]ErrorCase{
+		{"rate_limit_policy.limits[per-client].unit", `"week" is not a valid unit`},
+	}})
+}
+
 func TestRuleIsValidNoRuleKey(t *testing.T) {
 	r := getRuleValid()
 	r.RuleKey = ""
@@ -221,11 +279,36 @@ func TestRuleIsValidNoMethodOrMatches(t *testing.T) {
 
 func TestRuleIsValidBadMethod(t *testing.T) {
 	r := getRuleValid()
-	r.Methods = []string{"POST", "PUT", "GET_THAT_RESOURCE"}
+	r.Methods = []string{"POST", "PUT", "GET THAT RESOURCE"}
 
 	assert.NonNil(t, r.IsValid())
 }
 
+func TestRuleIsValidMethodTokens(t *testing.T) {
+	r := getRuleValid()
+	r.Methods = []string{"PATCH", "HEAD", "OPTIONS", "PROPFIND", "X-CUSTOM"}
+
+	assert.Nil(t, r.IsValid())
+}
+
+func TestRuleIsValidTwoPathPrefixMatches(t *testing.T) {
+	r := getRuleValid()
+	r.Matches = Matches{
+		Match{
+			Kind:     PathMatchKind,
+			Behavior: PrefixMatchBehavior,
+			From:     Metadatum{Value: "/api"},
+		},
+		Match{
+			Kind:     PathMatchKind,
+			Behavior: PrefixMatchBehavior,
+			From:     Metadatum{Value: "/api/v2"},
+		},
+	}
+
+	assert.Nil(t, r.IsValid())
+}
+
 func TestRuleIsValidBadMatches(t *testing.T) {
 	r := getRuleValid()
 	r.Matches = Matches{
@@ -249,7 +332,7 @@ func TestRuleIsValidBadMatches(t *testing.T) {
 func TestRuleIsValidBadConstraints(t *testing.T) {
 	r := getRuleValid()
 	r.Constraints = AllConstraints{
-		Dark: ClusterConstraints{{"cckey0", "ckey2", Metadata{{"key-2", "value-2"}}, Metadata{{"aoeu", "snth"}}, ResponseData{}, 1234}}}
+		Dark: ClusterConstraints{{"cckey0", "ckey2", Metadata{{"key-2", "value-2"}}, Metadata{{"aoeu", "snth"}}, ResponseData{}, RequestData{}, 1234}}}
 
 	assert.NonNil(t, r.IsValid())
 }
@@ -273,9 +356,11 @@ func getRulesValidTestRules() (Rule, Rule) {
 			},
 		},
 		AllConstraints{
-			Light: ClusterConstraints{{"ck0", "ckey2", Metadata{{"key-2", "value-2"}}, nil, ResponseData{}, 1234}},
+			Light: ClusterConstraints{{"ck0", "ckey2", Metadata{{"key-2", "value-2"}}, nil, ResponseData{}, RequestData{}, 1234}},
 		},
 		&CohortSeed{CohortSeedCookie, "cohort-cookie", false},
+		nil,
+		nil,
 	}
 
 	r2 := Rule{
@@ -296,9 +381,11 @@ func getRulesValidTestRules() (Rule, Rule) {
 			},
 		},
 		AllConstraints{
-			Light: ClusterConstraints{{"ck1", "ckey2", Metadata{{"key-2", "value-2"}}, nil, ResponseData{}, 1234}},
+			Light: ClusterConstraints{{"ck1", "ckey2", Metadata{{"key-2", "value-2"}}, nil, ResponseData{}, RequestData{}, 1234}},
 		},
 		nil,
+		nil,
+		nil,
 	}
 
 	return r1, r2
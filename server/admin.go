@@ -0,0 +1,137 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+
+	"github.com/turbinelabs/api"
+	apihttp "github.com/turbinelabs/api/http"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/queryargs"
+	"github.com/turbinelabs/api/service"
+)
+
+func userRoutes(svc service.User) objectRoutes {
+	return objectRoutes{
+		index: func(rr apihttp.RichRequest) (interface{}, error) {
+			filters := []service.UserFilter{}
+			if err := decodeFilters(rr, &filters); err != nil {
+				return nil, err
+			}
+			return svc.Index(filters...)
+		},
+		get: func(key string, rr apihttp.RichRequest) (interface{}, error) {
+			user, err := svc.Get(api.UserKey(key))
+			if err != nil {
+				return nil, err
+			}
+
+			// deleted users are only visible when explicitly requested
+			if _, ok := rr.QueryArgOk(queryargs.IncludeDeleted); !ok && user.DeletedAt != nil {
+				return nil, httperr.New404(
+					fmt.Sprintf("user %q has been deleted", key),
+					httperr.NotFoundErrorCode,
+				)
+			}
+
+			return user, nil
+		},
+		create: func(rr apihttp.RichRequest) (interface{}, error) {
+			user := api.User{}
+			if err := rr.GetBodyObject(&user); err != nil {
+				return nil, err
+			}
+			return svc.Create(user)
+		},
+		modify: func(key string, rr apihttp.RichRequest) (interface{}, error) {
+			user := api.User{}
+			if err := rr.GetBodyObject(&user); err != nil {
+				return nil, err
+			}
+			if err := checkKey(key, string(user.UserKey)); err != nil {
+				return nil, err
+			}
+			return svc.Modify(user)
+		},
+		delete: func(key string, checksum api.Checksum) error {
+			return svc.Delete(api.UserKey(key), checksum)
+		},
+	}
+}
+
+func orgRoutes(svc service.Org) objectRoutes {
+	return objectRoutes{
+		index: func(rr apihttp.RichRequest) (interface{}, error) {
+			filters := []service.OrgFilter{}
+			if err := decodeFilters(rr, &filters); err != nil {
+				return nil, err
+			}
+			return svc.Index(filters...)
+		},
+		get: func(key string, _ apihttp.RichRequest) (interface{}, error) {
+			return svc.Get(api.OrgKey(key))
+		},
+		create: func(rr apihttp.RichRequest) (interface{}, error) {
+			org := api.Org{}
+			if err := rr.GetBodyObject(&org); err != nil {
+				return nil, err
+			}
+			return svc.Create(org)
+		},
+		modify: func(key string, rr apihttp.RichRequest) (interface{}, error) {
+			org := api.Org{}
+			if err := rr.GetBodyObject(&org); err != nil {
+				return nil, err
+			}
+			if err := checkKey(key, string(org.OrgKey)); err != nil {
+				return nil, err
+			}
+			return svc.Modify(org)
+		},
+		delete: func(key string, checksum api.Checksum) error {
+			return svc.Delete(api.OrgKey(key), checksum)
+		},
+	}
+}
+
+// accessTokenRoutes serves /v1.0/admin/user/self/access_tokens. AccessTokens
+// may not be modified.
+func accessTokenRoutes(svc service.AccessToken) objectRoutes {
+	return objectRoutes{
+		index: func(rr apihttp.RichRequest) (interface{}, error) {
+			filters := []service.AccessTokenFilter{}
+			if err := decodeFilters(rr, &filters); err != nil {
+				return nil, err
+			}
+			return svc.Index(filters...)
+		},
+		get: func(key string, _ apihttp.RichRequest) (interface{}, error) {
+			return svc.Get(api.AccessTokenKey(key))
+		},
+		create: func(rr apihttp.RichRequest) (interface{}, error) {
+			token := api.AccessToken{}
+			if err := rr.GetBodyObject(&token); err != nil {
+				return nil, err
+			}
+			return svc.Create(token)
+		},
+		delete: func(key string, checksum api.Checksum) error {
+			return svc.Delete(api.AccessTokenKey(key), checksum)
+		},
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+
+	apihttp "github.com/turbinelabs/api/http"
+	"github.com/turbinelabs/api/service"
+)
+
+// serveBatch handles POST /v1.0/batch, whose body is a JSON array of
+// service.BatchOperations.
+func serveBatch(
+	svc service.Batch,
+	method string,
+	rest []string,
+	rr apihttp.RichRequest,
+) (interface{}, error) {
+	if len(rest) != 0 {
+		return nil, notFound(rest)
+	}
+
+	if method != http.MethodPost {
+		return nil, methodNotAllowed(method)
+	}
+
+	ops := []service.BatchOperation{}
+	if err := rr.GetBodyObject(&ops); err != nil {
+		return nil, err
+	}
+
+	return svc.Apply(ops)
+}
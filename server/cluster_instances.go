@@ -0,0 +1,84 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/turbinelabs/api"
+	apihttp "github.com/turbinelabs/api/http"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/service"
+)
+
+// serveClusterInstances handles
+//
+// 	POST   /v1.0/cluster/<key>/instance?checksum=<cs>
+// 	DELETE /v1.0/cluster/<key>/instance/<host>:<port>?checksum=<cs>
+func serveClusterInstances(
+	svc service.Cluster,
+	method string,
+	clusterKey api.ClusterKey,
+	rest []string,
+	rr apihttp.RichRequest,
+) (interface{}, error) {
+	if rest[0] != "instance" || len(rest) > 2 {
+		return nil, notFound(rest)
+	}
+
+	switch {
+	case len(rest) == 1 && method == http.MethodPost:
+		instance := api.Instance{}
+		if err := rr.GetBodyObject(&instance); err != nil {
+			return nil, err
+		}
+		return svc.AddInstance(clusterKey, checksumArg(rr), instance)
+
+	case len(rest) == 2 && method == http.MethodDelete:
+		instance, err := parseInstance(rest[1])
+		if err != nil {
+			return nil, err
+		}
+		return svc.RemoveInstance(clusterKey, checksumArg(rr), instance)
+	}
+
+	return nil, methodNotAllowed(method)
+}
+
+// parseInstance produces an Instance from a "<host>:<port>" string.
+func parseInstance(hostPort string) (api.Instance, error) {
+	idx := strings.LastIndex(hostPort, ":")
+	if idx <= 0 {
+		return api.Instance{}, httperr.New400(
+			fmt.Sprintf("instance %q must be of the form <host>:<port>", hostPort),
+			httperr.BadParameterErrorCode,
+		)
+	}
+
+	port, err := strconv.Atoi(hostPort[idx+1:])
+	if err != nil {
+		return api.Instance{}, httperr.New400(
+			fmt.Sprintf("instance %q has invalid port: %s", hostPort, err),
+			httperr.BadParameterErrorCode,
+		)
+	}
+
+	return api.Instance{Host: hostPort[:idx], Port: port}, nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/turbinelabs/api"
+	apihttp "github.com/turbinelabs/api/http"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/queryargs"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/api/service/changelog"
+	tbntime "github.com/turbinelabs/nonstdlib/time"
+)
+
+// serveHistory handles the routes under /v1.0/changelog:
+//
+// 	GET /adhoc?filters=<expr>&start=<us>&end=<us>
+// 	GET /domain-graph/<key>?start=<us>&end=<us>
+// 	GET /route-graph/<key>?start=<us>&end=<us>
+// 	GET /shared-rules-graph/<key>?start=<us>&end=<us>
+// 	GET /cluster-graph/<key>?start=<us>&end=<us>
+// 	GET /zone/<key>?start=<us>&end=<us>
+func serveHistory(
+	svc service.History,
+	method string,
+	rest []string,
+	rr apihttp.RichRequest,
+) (interface{}, error) {
+	if len(rest) == 0 || len(rest) > 2 {
+		return nil, notFound(rest)
+	}
+
+	if method != http.MethodGet {
+		return nil, methodNotAllowed(method)
+	}
+
+	start, err := timeArg(rr, queryargs.WindowStart)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := timeArg(rr, queryargs.WindowStop)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) == 1 {
+		if rest[0] != "adhoc" {
+			return nil, notFound(rest)
+		}
+
+		expr, err := decodeFilterExpr(rr.QueryArg(queryargs.IndexFilters))
+		if err != nil {
+			return nil, err
+		}
+
+		return svc.Index(expr, start, end)
+	}
+
+	key := rest[1]
+	switch rest[0] {
+	case "domain-graph":
+		return svc.DomainGraph(api.DomainKey(key), start, end)
+	case "route-graph":
+		return svc.RouteGraph(api.RouteKey(key), start, end)
+	case "shared-rules-graph":
+		return svc.SharedRulesGraph(api.SharedRulesKey(key), start, end)
+	case "cluster-graph":
+		return svc.ClusterGraph(api.ClusterKey(key), start, end)
+	case "zone":
+		return svc.Zone(api.ZoneKey(key), start, end)
+	}
+
+	return nil, notFound(rest)
+}
+
+// timeArg parses the named query argument as microseconds since the Unix
+// epoch. An absent argument produces the zero time.
+func timeArg(rr apihttp.RichRequest, name string) (time.Time, error) {
+	s, ok := rr.QueryArgOk(name)
+	if !ok || s == "" {
+		return time.Time{}, nil
+	}
+
+	micros, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, httperr.New400(
+			fmt.Sprintf("%s must be microseconds since the epoch, got %q", name, s),
+			httperr.BadParameterErrorCode,
+		)
+	}
+
+	return tbntime.FromUnixMicro(micros), nil
+}
+
+// decodeFilterExpr decodes a JSON-encoded changelog.FilterExpr, which may be
+// a Filter, FilterAnds, or FilterOrs. An empty or null expression decodes to
+// nil.
+func decodeFilterExpr(encoded string) (changelog.FilterExpr, error) {
+	if encoded == "" || encoded == "null" {
+		return nil, nil
+	}
+
+	mkErr := func(err error) error {
+		return httperr.New400(
+			fmt.Sprintf("unable to decode %s: %s", queryargs.IndexFilters, err),
+			httperr.UnknownDecodingCode,
+		)
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(encoded), &fields); err != nil {
+		return nil, mkErr(err)
+	}
+
+	var expr changelog.FilterExpr
+	switch {
+	case fields["or"] != nil:
+		ors := changelog.FilterOrs{}
+		if err := json.Unmarshal([]byte(encoded), &ors); err != nil {
+			return nil, mkErr(err)
+		}
+		expr = ors
+
+	case fields["and"] != nil:
+		ands := changelog.FilterAnds{}
+		if err := json.Unmarshal([]byte(encoded), &ands); err != nil {
+			return nil, mkErr(err)
+		}
+		expr = ands
+
+	default:
+		f := changelog.Filter{}
+		if err := json.Unmarshal([]byte(encoded), &f); err != nil {
+			return nil, mkErr(err)
+		}
+		expr = f
+	}
+
+	return expr, nil
+}
@@ -0,0 +1,365 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/turbinelabs/api"
+	apihttp "github.com/turbinelabs/api/http"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/queryargs"
+	"github.com/turbinelabs/api/service"
+)
+
+// objectRoutes serves the standard routes for a single object type:
+//
+// 	GET    /<type>                     index
+// 	POST   /<type>                     create
+// 	GET    /<type>/<key>               get
+// 	PUT    /<type>/<key>               modify
+// 	DELETE /<type>/<key>?checksum=<cs> delete
+//
+// A nil function causes the corresponding route to be rejected. Paths with
+// further segments are passed to sub, if set.
+type objectRoutes struct {
+	index  func(rr apihttp.RichRequest) (interface{}, error)
+	get    func(key string, rr apihttp.RichRequest) (interface{}, error)
+	create func(rr apihttp.RichRequest) (interface{}, error)
+	modify func(key string, rr apihttp.RichRequest) (interface{}, error)
+	delete func(key string, checksum api.Checksum) error
+	sub    func(method, key string, rest []string, rr apihttp.RichRequest) (interface{}, error)
+}
+
+func (o objectRoutes) serve(
+	method string,
+	rest []string,
+	rr apihttp.RichRequest,
+) (interface{}, error) {
+	switch {
+	case len(rest) == 0 && method == http.MethodGet && o.index != nil:
+		return o.index(rr)
+
+	case len(rest) == 0 && method == http.MethodPost && o.create != nil:
+		return o.create(rr)
+
+	case len(rest) == 1 && method == http.MethodGet && o.get != nil:
+		return o.get(rest[0], rr)
+
+	case len(rest) == 1 && method == http.MethodPut && o.modify != nil:
+		return o.modify(rest[0], rr)
+
+	case len(rest) == 1 && method == http.MethodDelete && o.delete != nil:
+		return nil, o.delete(rest[0], checksumArg(rr))
+
+	case len(rest) > 1 && o.sub != nil:
+		return o.sub(method, rest[0], rest[1:], rr)
+
+	case len(rest) > 1:
+		return nil, notFound(rest)
+	}
+
+	return nil, methodNotAllowed(method)
+}
+
+// decodeFilters decodes the JSON-encoded index filters, if any, into dest.
+func decodeFilters(rr apihttp.RichRequest, dest interface{}) error {
+	encoded, ok := rr.QueryArgOk(queryargs.IndexFilters)
+	if !ok || encoded == "" {
+		return nil
+	}
+
+	if err := json.Unmarshal([]byte(encoded), dest); err != nil {
+		return httperr.New400(
+			fmt.Sprintf("unable to decode %s: %s", queryargs.IndexFilters, err),
+			httperr.UnknownDecodingCode,
+		)
+	}
+
+	return nil
+}
+
+func checksumArg(rr apihttp.RichRequest) api.Checksum {
+	return api.Checksum{Checksum: rr.QueryArg(queryargs.Checksum)}
+}
+
+// checkKey verifies that the key given in a request body matches the key
+// given in the request path.
+func checkKey(pathKey, bodyKey string) error {
+	if pathKey != bodyKey {
+		return httperr.New400(
+			fmt.Sprintf("object key %q does not match path key %q", bodyKey, pathKey),
+			httperr.KeyImmutableErrorCode,
+		)
+	}
+	return nil
+}
+
+func clusterRoutes(svc service.Cluster) objectRoutes {
+	return objectRoutes{
+		index: func(rr apihttp.RichRequest) (interface{}, error) {
+			filters := []service.ClusterFilter{}
+			if err := decodeFilters(rr, &filters); err != nil {
+				return nil, err
+			}
+			return svc.Index(filters...)
+		},
+		get: func(key string, _ apihttp.RichRequest) (interface{}, error) {
+			return svc.Get(api.ClusterKey(key))
+		},
+		create: func(rr apihttp.RichRequest) (interface{}, error) {
+			cluster := api.Cluster{}
+			if err := rr.GetBodyObject(&cluster); err != nil {
+				return nil, err
+			}
+			return svc.Create(cluster)
+		},
+		modify: func(key string, rr apihttp.RichRequest) (interface{}, error) {
+			cluster := api.Cluster{}
+			if err := rr.GetBodyObject(&cluster); err != nil {
+				return nil, err
+			}
+			if err := checkKey(key, string(cluster.ClusterKey)); err != nil {
+				return nil, err
+			}
+			return svc.Modify(cluster)
+		},
+		delete: func(key string, checksum api.Checksum) error {
+			return svc.Delete(api.ClusterKey(key), checksum)
+		},
+		sub: func(
+			method string,
+			key string,
+			rest []string,
+			rr apihttp.RichRequest,
+		) (interface{}, error) {
+			return serveClusterInstances(svc, method, api.ClusterKey(key), rest, rr)
+		},
+	}
+}
+
+func domainRoutes(svc service.Domain) objectRoutes {
+	return objectRoutes{
+		index: func(rr apihttp.RichRequest) (interface{}, error) {
+			filters := []service.DomainFilter{}
+			if err := decodeFilters(rr, &filters); err != nil {
+				return nil, err
+			}
+			return svc.Index(filters...)
+		},
+		get: func(key string, _ apihttp.RichRequest) (interface{}, error) {
+			return svc.Get(api.DomainKey(key))
+		},
+		create: func(rr apihttp.RichRequest) (interface{}, error) {
+			domain := api.Domain{}
+			if err := rr.GetBodyObject(&domain); err != nil {
+				return nil, err
+			}
+			return svc.Create(domain)
+		},
+		modify: func(key string, rr apihttp.RichRequest) (interface{}, error) {
+			domain := api.Domain{}
+			if err := rr.GetBodyObject(&domain); err != nil {
+				return nil, err
+			}
+			if err := checkKey(key, string(domain.DomainKey)); err != nil {
+				return nil, err
+			}
+			return svc.Modify(domain)
+		},
+		delete: func(key string, checksum api.Checksum) error {
+			return svc.Delete(api.DomainKey(key), checksum)
+		},
+	}
+}
+
+func proxyRoutes(svc service.Proxy) objectRoutes {
+	return objectRoutes{
+		index: func(rr apihttp.RichRequest) (interface{}, error) {
+			filters := []service.ProxyFilter{}
+			if err := decodeFilters(rr, &filters); err != nil {
+				return nil, err
+			}
+			return svc.Index(filters...)
+		},
+		get: func(key string, _ apihttp.RichRequest) (interface{}, error) {
+			return svc.Get(api.ProxyKey(key))
+		},
+		create: func(rr apihttp.RichRequest) (interface{}, error) {
+			proxy := api.Proxy{}
+			if err := rr.GetBodyObject(&proxy); err != nil {
+				return nil, err
+			}
+			return svc.Create(proxy)
+		},
+		modify: func(key string, rr apihttp.RichRequest) (interface{}, error) {
+			proxy := api.Proxy{}
+			if err := rr.GetBodyObject(&proxy); err != nil {
+				return nil, err
+			}
+			if err := checkKey(key, string(proxy.ProxyKey)); err != nil {
+				return nil, err
+			}
+			return svc.Modify(proxy)
+		},
+		delete: func(key string, checksum api.Checksum) error {
+			return svc.Delete(api.ProxyKey(key), checksum)
+		},
+	}
+}
+
+func listenerRoutes(svc service.Listener) objectRoutes {
+	return objectRoutes{
+		index: func(rr apihttp.RichRequest) (interface{}, error) {
+			filters := []service.ListenerFilter{}
+			if err := decodeFilters(rr, &filters); err != nil {
+				return nil, err
+			}
+			return svc.Index(filters...)
+		},
+		get: func(key string, _ apihttp.RichRequest) (interface{}, error) {
+			return svc.Get(api.ListenerKey(key))
+		},
+		create: func(rr apihttp.RichRequest) (interface{}, error) {
+			listener := api.Listener{}
+			if err := rr.GetBodyObject(&listener); err != nil {
+				return nil, err
+			}
+			return svc.Create(listener)
+		},
+		modify: func(key string, rr apihttp.RichRequest) (interface{}, error) {
+			listener := api.Listener{}
+			if err := rr.GetBodyObject(&listener); err != nil {
+				return nil, err
+			}
+			if err := checkKey(key, string(listener.ListenerKey)); err != nil {
+				return nil, err
+			}
+			return svc.Modify(listener)
+		},
+		delete: func(key string, checksum api.Checksum) error {
+			return svc.Delete(api.ListenerKey(key), checksum)
+		},
+	}
+}
+
+func routeRoutes(svc service.Route) objectRoutes {
+	return objectRoutes{
+		index: func(rr apihttp.RichRequest) (interface{}, error) {
+			filters := []service.RouteFilter{}
+			if err := decodeFilters(rr, &filters); err != nil {
+				return nil, err
+			}
+			return svc.Index(filters...)
+		},
+		get: func(key string, _ apihttp.RichRequest) (interface{}, error) {
+			return svc.Get(api.RouteKey(key))
+		},
+		create: func(rr apihttp.RichRequest) (interface{}, error) {
+			route := api.Route{}
+			if err := rr.GetBodyObject(&route); err != nil {
+				return nil, err
+			}
+			return svc.Create(route)
+		},
+		modify: func(key string, rr apihttp.RichRequest) (interface{}, error) {
+			route := api.Route{}
+			if err := rr.GetBodyObject(&route); err != nil {
+				return nil, err
+			}
+			if err := checkKey(key, string(route.RouteKey)); err != nil {
+				return nil, err
+			}
+			return svc.Modify(route)
+		},
+		delete: func(key string, checksum api.Checksum) error {
+			return svc.Delete(api.RouteKey(key), checksum)
+		},
+	}
+}
+
+func sharedRulesRoutes(svc service.SharedRules) objectRoutes {
+	return objectRoutes{
+		index: func(rr apihttp.RichRequest) (interface{}, error) {
+			filters := []service.SharedRulesFilter{}
+			if err := decodeFilters(rr, &filters); err != nil {
+				return nil, err
+			}
+			return svc.Index(filters...)
+		},
+		get: func(key string, _ apihttp.RichRequest) (interface{}, error) {
+			return svc.Get(api.SharedRulesKey(key))
+		},
+		create: func(rr apihttp.RichRequest) (interface{}, error) {
+			sharedRules := api.SharedRules{}
+			if err := rr.GetBodyObject(&sharedRules); err != nil {
+				return nil, err
+			}
+			return svc.Create(sharedRules)
+		},
+		modify: func(key string, rr apihttp.RichRequest) (interface{}, error) {
+			sharedRules := api.SharedRules{}
+			if err := rr.GetBodyObject(&sharedRules); err != nil {
+				return nil, err
+			}
+			if err := checkKey(key, string(sharedRules.SharedRulesKey)); err != nil {
+				return nil, err
+			}
+			return svc.Modify(sharedRules)
+		},
+		delete: func(key string, checksum api.Checksum) error {
+			return svc.Delete(api.SharedRulesKey(key), checksum)
+		},
+	}
+}
+
+func zoneRoutes(svc service.Zone) objectRoutes {
+	return objectRoutes{
+		index: func(rr apihttp.RichRequest) (interface{}, error) {
+			filters := []service.ZoneFilter{}
+			if err := decodeFilters(rr, &filters); err != nil {
+				return nil, err
+			}
+			return svc.Index(filters...)
+		},
+		get: func(key string, _ apihttp.RichRequest) (interface{}, error) {
+			return svc.Get(api.ZoneKey(key))
+		},
+		create: func(rr apihttp.RichRequest) (interface{}, error) {
+			zone := api.Zone{}
+			if err := rr.GetBodyObject(&zone); err != nil {
+				return nil, err
+			}
+			return svc.Create(zone)
+		},
+		modify: func(key string, rr apihttp.RichRequest) (interface{}, error) {
+			zone := api.Zone{}
+			if err := rr.GetBodyObject(&zone); err != nil {
+				return nil, err
+			}
+			if err := checkKey(key, string(zone.ZoneKey)); err != nil {
+				return nil, err
+			}
+			return svc.Modify(zone)
+		},
+		delete: func(key string, checksum api.Checksum) error {
+			return svc.Delete(api.ZoneKey(key), checksum)
+		},
+	}
+}
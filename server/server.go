@@ -0,0 +1,218 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package server exposes implementations of the Turbine service interfaces
+// over the REST routes documented in the service package. The paths, query
+// arguments, and response envelopes match those spoken by the client package,
+// so any service.All may be served to an HTTP client:
+//
+// 	svc := memory.New()
+// 	handler := server.NewHandler(svc, svc, nil)
+// 	http.ListenAndServe(":8080", handler)
+//
+// Requests for a service that was not provided receive a 501 response.
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	apihttp "github.com/turbinelabs/api/http"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/service"
+	statsapi "github.com/turbinelabs/api/service/stats"
+)
+
+const (
+	v1Prefix = "v1.0"
+	v2Prefix = "v2.0"
+)
+
+// objectPaths are the v1.0 path segments served by service.All objects.
+var objectPaths = map[string]bool{
+	"cluster":      true,
+	"domain":       true,
+	"proxy":        true,
+	"listener":     true,
+	"route":        true,
+	"shared_rules": true,
+	"zone":         true,
+}
+
+type handler struct {
+	all   service.All
+	admin service.Admin
+	stats statsapi.StatsService
+
+	objects map[string]objectRoutes
+}
+
+// NewHandler returns an http.Handler serving the given services. Any of all,
+// admin, or stats may be nil, in which case the corresponding routes return a
+// 501 error.
+func NewHandler(
+	all service.All,
+	admin service.Admin,
+	stats statsapi.StatsService,
+) http.Handler {
+	h := &handler{
+		all:     all,
+		admin:   admin,
+		stats:   stats,
+		objects: map[string]objectRoutes{},
+	}
+
+	if all != nil {
+		h.objects["cluster"] = clusterRoutes(all.Cluster())
+		h.objects["domain"] = domainRoutes(all.Domain())
+		h.objects["proxy"] = proxyRoutes(all.Proxy())
+		h.objects["listener"] = listenerRoutes(all.Listener())
+		h.objects["route"] = routeRoutes(all.Route())
+		h.objects["shared_rules"] = sharedRulesRoutes(all.SharedRules())
+		h.objects["zone"] = zoneRoutes(all.Zone())
+	}
+
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rrw := apihttp.RichResponseWriter{ResponseWriter: w}
+
+	segments, err := pathSegments(r.URL)
+	if err != nil {
+		rrw.WriteEnvelope(err, nil)
+		return
+	}
+
+	result, err := h.dispatch(r.Method, segments, apihttp.NewRichRequest(r))
+	rrw.WriteEnvelope(err, result)
+}
+
+// dispatch routes a request by its path segments, the first of which is the
+// API version.
+func (h *handler) dispatch(
+	method string,
+	segments []string,
+	rr apihttp.RichRequest,
+) (interface{}, error) {
+	if len(segments) < 2 {
+		return nil, notFound(segments)
+	}
+
+	switch segments[0] {
+	case v1Prefix:
+		switch segments[1] {
+		case "admin":
+			return h.dispatchAdmin(method, segments, rr)
+
+		case "changelog":
+			if h.all == nil {
+				return nil, notImplemented("History")
+			}
+			return serveHistory(h.all.History(), method, segments[2:], rr)
+
+		case "batch":
+			if h.all == nil {
+				return nil, notImplemented("Batch")
+			}
+			return serveBatch(h.all.Batch(), method, segments[2:], rr)
+		}
+
+		if routes, ok := h.objects[segments[1]]; ok {
+			return routes.serve(method, segments[2:], rr)
+		}
+
+		if h.all == nil && objectPaths[segments[1]] {
+			return nil, notImplemented(segments[1])
+		}
+
+	case v2Prefix:
+		if segments[1] == "stats" {
+			if h.stats == nil {
+				return nil, notImplemented("StatsService")
+			}
+			return serveStats(h.stats, method, segments[2:], rr)
+		}
+	}
+
+	return nil, notFound(segments)
+}
+
+func (h *handler) dispatchAdmin(
+	method string,
+	segments []string,
+	rr apihttp.RichRequest,
+) (interface{}, error) {
+	if len(segments) < 3 || (segments[2] != "user" && segments[2] != "org") {
+		return nil, notFound(segments)
+	}
+
+	if h.admin == nil {
+		return nil, notImplemented("Admin")
+	}
+
+	rest := segments[3:]
+	if segments[2] == "org" {
+		return orgRoutes(h.admin.Org()).serve(method, rest, rr)
+	}
+
+	if len(rest) >= 2 && rest[0] == "self" && rest[1] == "access_tokens" {
+		return accessTokenRoutes(h.admin.AccessToken()).serve(method, rest[2:], rr)
+	}
+
+	return userRoutes(h.admin.User()).serve(method, rest, rr)
+}
+
+// pathSegments splits the escaped URL path on slashes and unescapes each
+// segment. Keys are escaped with url.QueryEscape by the client, so the same
+// is used to unescape them here.
+func pathSegments(u *url.URL) ([]string, error) {
+	segments := strings.Split(strings.Trim(u.EscapedPath(), "/"), "/")
+	for i, s := range segments {
+		unescaped, err := url.QueryUnescape(s)
+		if err != nil {
+			return nil, httperr.New400(
+				fmt.Sprintf("malformed path segment %q: %s", s, err),
+				httperr.BadParameterErrorCode,
+			)
+		}
+		segments[i] = unescaped
+	}
+	return segments, nil
+}
+
+func notFound(segments []string) error {
+	return httperr.New404(
+		fmt.Sprintf("no route for /%s", strings.Join(segments, "/")),
+		httperr.NotFoundErrorCode,
+	)
+}
+
+func notImplemented(what string) error {
+	return httperr.New501(
+		fmt.Sprintf("%s not implemented", what),
+		httperr.MiscErrorCode,
+	)
+}
+
+func methodNotAllowed(method string) error {
+	return httperr.New400(
+		fmt.Sprintf("method %s not supported for this route", method),
+		httperr.BadParameterErrorCode,
+	)
+}
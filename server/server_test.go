@@ -0,0 +1,377 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/client"
+	"github.com/turbinelabs/api/fixture"
+	apihttp "github.com/turbinelabs/api/http"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/objecttype"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/api/service/changelog"
+	"github.com/turbinelabs/api/service/memory"
+	"github.com/turbinelabs/test/assert"
+)
+
+type roundTrip struct {
+	server *httptest.Server
+	all    service.All
+	admin  service.Admin
+}
+
+func (rt roundTrip) close() {
+	rt.server.Close()
+}
+
+func newRoundTrip(t *testing.T, handler http.Handler) roundTrip {
+	server := httptest.NewServer(handler)
+
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+
+	endpoint, err := apihttp.NewEndpoint(apihttp.HTTP, u.Host)
+	assert.Nil(t, err)
+
+	all, err := client.NewAll(endpoint, "", "server-test")
+	assert.Nil(t, err)
+
+	admin, err := client.NewAdmin(endpoint, "", "server-test")
+	assert.Nil(t, err)
+
+	return roundTrip{server, all, admin}
+}
+
+func assertErrorCode(t *testing.T, err error, code httperr.ErrorCode) {
+	httpErr, ok := err.(*httperr.Error)
+	if assert.True(t, ok) {
+		assert.Equal(t, httpErr.Code, code)
+	}
+}
+
+func TestRoundTripCluster(t *testing.T) {
+	svc := memory.New()
+	rt := newRoundTrip(t, NewHandler(svc, svc, nil))
+	defer rt.close()
+
+	df := fixture.New()
+
+	clusters, err := rt.all.Cluster().Index()
+	assert.Nil(t, err)
+	assert.Equal(t, len(clusters), 2)
+
+	clusters, err = rt.all.Cluster().Index(service.ClusterFilter{Name: df.ClusterName2})
+	assert.Nil(t, err)
+	assert.Equal(t, len(clusters), 1)
+	// OrgKey is not part of the JSON encoding
+	expected := df.Cluster2
+	expected.OrgKey = ""
+	assert.True(t, clusters[0].Equals(expected))
+
+	created, err := rt.all.Cluster().Create(api.Cluster{ZoneKey: df.ZoneKey1, Name: "a b/c"})
+	assert.Nil(t, err)
+	assert.NotEqual(t, created.ClusterKey, "")
+
+	got, err := rt.all.Cluster().Get(created.ClusterKey)
+	assert.Nil(t, err)
+	assert.True(t, got.Equals(created))
+
+	got.RequireTLS = true
+	modified, err := rt.all.Cluster().Modify(got)
+	assert.Nil(t, err)
+	assert.True(t, modified.RequireTLS)
+
+	_, err = rt.all.Cluster().Modify(got)
+	assertErrorCode(t, err, httperr.UnknownModificationConflict)
+
+	withInstance, err := rt.all.Cluster().AddInstance(
+		modified.ClusterKey,
+		modified.Checksum,
+		api.Instance{Host: "some-host", Port: 8080},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, len(withInstance.Instances), 1)
+
+	withoutInstance, err := rt.all.Cluster().RemoveInstance(
+		withInstance.ClusterKey,
+		withInstance.Checksum,
+		api.Instance{Host: "some-host", Port: 8080},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, len(withoutInstance.Instances), 0)
+
+	err = rt.all.Cluster().Delete(withoutInstance.ClusterKey, withoutInstance.Checksum)
+	assert.Nil(t, err)
+
+	_, err = rt.all.Cluster().Get(withoutInstance.ClusterKey)
+	assertErrorCode(t, err, httperr.NotFoundErrorCode)
+}
+
+func TestRoundTripObjects(t *testing.T) {
+	svc := memory.New()
+	rt := newRoundTrip(t, NewHandler(svc, svc, nil))
+	defer rt.close()
+
+	df := fixture.New()
+	df.Domain1.OrgKey = ""
+	df.Listener1.OrgKey = ""
+	df.Route1.OrgKey = ""
+	df.SharedRules1.OrgKey = ""
+
+	domain, err := rt.all.Domain().Get(df.DomainKey1)
+	assert.Nil(t, err)
+	assert.True(t, domain.Equals(df.Domain1))
+
+	proxies, err := rt.all.Proxy().Index(
+		service.ProxyFilter{ListenerKeys: []api.ListenerKey{df.ListenerKey1}},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, len(proxies), 1)
+
+	listener, err := rt.all.Listener().Get(df.ListenerKey1)
+	assert.Nil(t, err)
+	assert.True(t, listener.Equals(df.Listener1))
+
+	route, err := rt.all.Route().Get(df.RouteKey1)
+	assert.Nil(t, err)
+	assert.True(t, route.Equals(df.Route1))
+
+	sharedRules, err := rt.all.SharedRules().Get(df.SharedRulesKey1)
+	assert.Nil(t, err)
+	assert.True(t, sharedRules.Equals(df.SharedRules1))
+
+	zone, err := rt.all.Zone().Create(api.Zone{Name: "new-zone"})
+	assert.Nil(t, err)
+	assert.Equal(t, zone.Name, "new-zone")
+}
+
+func TestRoundTripAdmin(t *testing.T) {
+	svc := memory.New()
+	rt := newRoundTrip(t, NewHandler(svc, svc, nil))
+	defer rt.close()
+
+	df := fixture.New()
+
+	user, err := rt.admin.User().Get(df.UserKey1)
+	assert.Nil(t, err)
+	assert.True(t, user.Equals(df.User1))
+
+	// deleted users require include_deleted
+	_, err = rt.admin.User().Get(df.UserKey2)
+	assertErrorCode(t, err, httperr.NotFoundErrorCode)
+
+	token, err := rt.admin.AccessToken().Create(api.AccessToken{Description: "token"})
+	assert.Nil(t, err)
+	assert.NotEqual(t, token.SignedToken, "")
+
+	tokens, err := rt.admin.AccessToken().Index(
+		service.AccessTokenFilter{Description: "token"},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, len(tokens), 1)
+
+	err = rt.admin.AccessToken().Delete(token.AccessTokenKey, token.Checksum)
+	assert.Nil(t, err)
+
+	orgs, err := rt.admin.Org().Index(service.OrgFilter{Name: df.OrgName1})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, orgs, api.Orgs{df.Org1})
+
+	org, err := rt.admin.Org().Create(api.Org{Name: "NewCo", ContactEmail: "x@example.com"})
+	assert.Nil(t, err)
+	org.Name = "NewCo2"
+	org, err = rt.admin.Org().Modify(org)
+	assert.Nil(t, err)
+	assert.Equal(t, org.Name, "NewCo2")
+
+	err = rt.admin.Org().Delete(org.OrgKey, org.Checksum)
+	assert.Nil(t, err)
+	_, err = rt.admin.Org().Get(org.OrgKey)
+	assertErrorCode(t, err, httperr.NotFoundErrorCode)
+}
+
+func TestRoundTripHistory(t *testing.T) {
+	svc := memory.New()
+	rt := newRoundTrip(t, NewHandler(svc, svc, nil))
+	defer rt.close()
+
+	df := fixture.New()
+
+	start := time.Now().Add(-time.Minute)
+
+	_, err := rt.all.Zone().Create(api.Zone{Name: "new-zone"})
+	assert.Nil(t, err)
+
+	end := time.Now().Add(time.Minute)
+
+	changes, err := rt.all.History().Index(
+		changelog.NewFilterUnion(changelog.Filter{ObjectType: "zone"}),
+		start,
+		end,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, len(changes), 1)
+
+	changes, err = rt.all.History().Index(
+		changelog.Filter{ObjectType: "cluster"},
+		start,
+		end,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, len(changes), 0)
+
+	changes, err = rt.all.History().ClusterGraph(df.ClusterKey1, start, end)
+	assert.Nil(t, err)
+	assert.Equal(t, len(changes), 0)
+
+	_, err = rt.all.History().Zone(df.ZoneKey1, end, start)
+	assertErrorCode(t, err, httperr.BadParameterErrorCode)
+}
+
+func TestRoundTripBatch(t *testing.T) {
+	svc := memory.New()
+	rt := newRoundTrip(t, NewHandler(svc, svc, nil))
+	defer rt.close()
+
+	df := fixture.New()
+
+	route := df.Route1
+	route.OrgKey = ""
+	route.Path = "/batch"
+
+	results, err := rt.all.Batch().Apply([]service.BatchOperation{
+		service.CreateOperation(api.Zone{Name: "new-zone"}),
+		service.ModifyOperation(route),
+		service.DeleteOperation(objecttype.Proxy, string(df.ProxyKey2), df.Proxy2.Checksum),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, len(results), 3)
+
+	zone, ok := results[0].Object.(api.Zone)
+	assert.True(t, ok)
+	assert.Equal(t, zone.Name, "new-zone")
+	assert.Equal(t, results[0].ObjectKey, string(zone.ZoneKey))
+
+	got, err := rt.all.Route().Get(df.RouteKey1)
+	assert.Nil(t, err)
+	assert.Equal(t, got.Path, "/batch")
+
+	_, err = rt.all.Proxy().Get(df.ProxyKey2)
+	assertErrorCode(t, err, httperr.NotFoundErrorCode)
+
+	_, err = rt.all.Batch().Apply([]service.BatchOperation{
+		service.CreateOperation(api.Zone{Name: "another-zone"}),
+		service.ModifyOperation(route),
+	})
+	assertErrorCode(t, err, httperr.UnknownModificationConflict)
+	assert.ErrorContains(t, err, "batch operation 1 (modify route) failed")
+
+	zones, err := rt.all.Zone().Index(service.ZoneFilter{Name: "another-zone"})
+	assert.Nil(t, err)
+	assert.Equal(t, len(zones), 0)
+}
+
+func TestHandlerWithoutServices(t *testing.T) {
+	rt := newRoundTrip(t, NewHandler(nil, nil, nil))
+	defer rt.close()
+
+	_, err := rt.all.Cluster().Index()
+	assert.ErrorContains(t, err, "not implemented")
+
+	_, err = rt.admin.User().Index()
+	assert.ErrorContains(t, err, "not implemented")
+}
+
+func TestHandlerUnknownRoute(t *testing.T) {
+	svc := memory.New()
+	server := httptest.NewServer(NewHandler(svc, svc, nil))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/v1.0/nope")
+	assert.Nil(t, err)
+	assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+
+	resp, err = http.Post(server.URL+"/v1.0/cluster/some-key", "application/json", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+}
+
+func TestHandlerModifyKeyMismatch(t *testing.T) {
+	svc := memory.New()
+	server := httptest.NewServer(NewHandler(svc, svc, nil))
+	defer server.Close()
+
+	req, err := http.NewRequest(
+		http.MethodPut,
+		server.URL+"/v1.0/zone/other-key",
+		strings.NewReader(`{"zone_key":"zk1","name":"z"}`),
+	)
+	assert.Nil(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+}
+
+func TestParseInstance(t *testing.T) {
+	inst, err := parseInstance("host:1234")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, inst, api.Instance{Host: "host", Port: 1234})
+
+	inst, err = parseInstance("::1:1234")
+	assert.Nil(t, err)
+	assert.Equal(t, inst.Host, "::1")
+
+	_, err = parseInstance("host")
+	assertErrorCode(t, err, httperr.BadParameterErrorCode)
+
+	_, err = parseInstance("host:port")
+	assertErrorCode(t, err, httperr.BadParameterErrorCode)
+}
+
+func TestDecodeFilterExpr(t *testing.T) {
+	expr, err := decodeFilterExpr("")
+	assert.Nil(t, err)
+	assert.Nil(t, expr)
+
+	expr, err = decodeFilterExpr(`{"object_type":"cluster"}`)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, expr, changelog.Filter{ObjectType: "cluster"})
+
+	expr, err = decodeFilterExpr(`{"and":[{"object_type":"cluster"}]}`)
+	assert.Nil(t, err)
+	assert.DeepEqual(
+		t,
+		expr,
+		changelog.NewFilterIntersection(changelog.Filter{ObjectType: "cluster"}),
+	)
+
+	expr, err = decodeFilterExpr(`{"or":[{"and":[{"object_type":"cluster"}]}]}`)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, expr, changelog.NewFilterUnion(changelog.Filter{ObjectType: "cluster"}))
+
+	_, err = decodeFilterExpr(`[`)
+	assertErrorCode(t, err, httperr.UnknownDecodingCode)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	apihttp "github.com/turbinelabs/api/http"
+	httperr "github.com/turbinelabs/api/http/error"
+	statsapi "github.com/turbinelabs/api/service/stats"
+)
+
+// serveStats handles
+//
+// 	POST /v2.0/stats/forward
+// 	POST /v2.0/stats/query
+//
+// Request bodies may be gzipped, as indicated by the Content-Encoding header.
+func serveStats(
+	svc statsapi.StatsService,
+	method string,
+	rest []string,
+	rr apihttp.RichRequest,
+) (interface{}, error) {
+	if len(rest) != 1 {
+		return nil, notFound(rest)
+	}
+
+	if method != http.MethodPost {
+		return nil, methodNotAllowed(method)
+	}
+
+	switch rest[0] {
+	case "forward":
+		payload := &statsapi.Payload{}
+		if err := decodeStatsBody(rr, payload); err != nil {
+			return nil, err
+		}
+		return svc.ForwardV2(payload)
+
+	case "query":
+		query := &statsapi.Query{}
+		if err := decodeStatsBody(rr, query); err != nil {
+			return nil, err
+		}
+		return svc.QueryV2(query)
+	}
+
+	return nil, notFound(rest)
+}
+
+func decodeStatsBody(rr apihttp.RichRequest, dest interface{}) error {
+	body, err := rr.GetBody()
+	if err != nil {
+		return err
+	}
+
+	if rr.Underlying().Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return httperr.New400(
+				fmt.Sprintf("could not decompress body: %s", err),
+				httperr.UnknownDecodingCode,
+			)
+		}
+		defer gz.Close()
+
+		if body, err = ioutil.ReadAll(gz); err != nil {
+			return httperr.New400(
+				fmt.Sprintf("could not decompress body: %s", err),
+				httperr.UnknownDecodingCode,
+			)
+		}
+	}
+
+	if err := json.Unmarshal(body, dest); err != nil {
+		return httperr.New400(
+			fmt.Sprintf("could not decode body: %s", err),
+			httperr.UnknownDecodingCode,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/turbinelabs/api/client"
+	apihttp "github.com/turbinelabs/api/http"
+	"github.com/turbinelabs/api/http/envelope"
+	statsapi "github.com/turbinelabs/api/service/stats"
+	"github.com/turbinelabs/test/assert"
+)
+
+func TestServeStatsQuery(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	want := &statsapi.QueryResult{}
+
+	stats := statsapi.NewMockStatsService(ctrl)
+	stats.EXPECT().QueryV2(&statsapi.Query{}).Return(want, nil)
+
+	server := httptest.NewServer(NewHandler(nil, nil, stats))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+
+	endpoint, err := apihttp.NewEndpoint(apihttp.HTTP, u.Host)
+	assert.Nil(t, err)
+
+	statsClient, err := client.NewStatsV2Client(endpoint, "", "server-test", nil)
+	assert.Nil(t, err)
+
+	got, err := statsClient.QueryV2(&statsapi.Query{})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, want)
+}
+
+func TestServeStatsForward(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	payload := &statsapi.Payload{Source: "the-source"}
+
+	stats := statsapi.NewMockStatsService(ctrl)
+	stats.EXPECT().ForwardV2(payload).Return(&statsapi.ForwardResult{NumAccepted: 1}, nil)
+
+	server := httptest.NewServer(NewHandler(nil, nil, stats))
+	defer server.Close()
+
+	var buffer bytes.Buffer
+	gz := gzip.NewWriter(&buffer)
+	assert.Nil(t, json.NewEncoder(gz).Encode(payload))
+	assert.Nil(t, gz.Close())
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/v2.0/stats/forward", &buffer)
+	assert.Nil(t, err)
+	req.Header.Set("Content-Encoding", "gzip")
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	result := &statsapi.ForwardResult{}
+	assert.Nil(t, json.Unmarshal(body, &envelope.Response{Payload: result}))
+	assert.Equal(t, result.NumAccepted, 1)
+}
+
+func TestServeStatsNotImplemented(t *testing.T) {
+	server := httptest.NewServer(NewHandler(nil, nil, nil))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/v2.0/stats/query", "application/json", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, resp.StatusCode, http.StatusNotImplemented)
+}
@@ -34,6 +34,9 @@ import (
 type Admin interface {
 	User() User
 
+	// Org returns an interface to administer Orgs.
+	Org() Org
+
 	// AccessToken returns an interface to interact with the access tokens
 	// for the user who is making an authenticated request.
 	AccessToken() AccessToken
@@ -0,0 +1,322 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/turbinelabs/api"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/objecttype"
+)
+
+// BatchOpType identifies the mutation made by a BatchOperation.
+type BatchOpType string
+
+const (
+	// BatchCreate creates the operation's Object.
+	BatchCreate BatchOpType = "create"
+
+	// BatchModify modifies the operation's Object. The Object's Checksum
+	// must match the existing Checksum.
+	BatchModify BatchOpType = "modify"
+
+	// BatchDelete deletes the object identified by the operation's
+	// ObjectType and ObjectKey. The operation's Checksum must match the
+	// existing Checksum.
+	BatchDelete BatchOpType = "delete"
+)
+
+// BatchOperation is a single mutation applied by Batch.Apply. Use
+// CreateOperation, ModifyOperation, and DeleteOperation to construct them.
+type BatchOperation struct {
+	// Op is the kind of mutation.
+	Op BatchOpType `json:"op"`
+
+	// ObjectType is the type of the mutated object.
+	objecttype.ObjectType
+
+	// ObjectKey identifies the object to delete. It is ignored for other
+	// operations.
+	ObjectKey string `json:"object_key,omitempty"`
+
+	// Checksum is the expected Checksum of the object to delete. It is
+	// ignored for other operations.
+	api.Checksum
+
+	// Object is the object to create or modify: an api.Cluster, api.Domain,
+	// api.SharedRules, api.Route, api.Proxy, api.Listener, or api.Zone.
+	Object interface{} `json:"object,omitempty"`
+}
+
+// CreateOperation returns a BatchOperation creating the given object.
+func CreateOperation(object interface{}) BatchOperation {
+	ot, _ := batchObjectType(object)
+	return BatchOperation{Op: BatchCreate, ObjectType: ot, Object: object}
+}
+
+// ModifyOperation returns a BatchOperation modifying the given object.
+func ModifyOperation(object interface{}) BatchOperation {
+	ot, _ := batchObjectType(object)
+	return BatchOperation{Op: BatchModify, ObjectType: ot, Object: object}
+}
+
+// DeleteOperation returns a BatchOperation deleting the object of the given
+// type and key.
+func DeleteOperation(
+	ot objecttype.ObjectType,
+	key string,
+	checksum api.Checksum,
+) BatchOperation {
+	return BatchOperation{Op: BatchDelete, ObjectType: ot, ObjectKey: key, Checksum: checksum}
+}
+
+// UnmarshalJSON decodes a BatchOperation, decoding its Object according to
+// its ObjectType.
+func (op *BatchOperation) UnmarshalJSON(b []byte) error {
+	type plain BatchOperation
+	var decoded struct {
+		plain
+		Object json.RawMessage `json:"object,omitempty"`
+	}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return err
+	}
+
+	ot, obj, err := decodeBatchObject(decoded.Name, decoded.Object)
+	if err != nil {
+		return err
+	}
+
+	*op = BatchOperation(decoded.plain)
+	op.ObjectType = ot
+	op.Object = obj
+	return nil
+}
+
+// BatchResult describes the outcome of a single BatchOperation.
+type BatchResult struct {
+	// ObjectType is the type of the mutated object.
+	objecttype.ObjectType
+
+	// ObjectKey is the key of the mutated object. For created objects it is
+	// the newly assigned key.
+	ObjectKey string `json:"object_key"`
+
+	// Object is the created or modified object, or nil if the object was
+	// deleted.
+	Object interface{} `json:"object,omitempty"`
+}
+
+// UnmarshalJSON decodes a BatchResult, decoding its Object according to its
+// ObjectType.
+func (r *BatchResult) UnmarshalJSON(b []byte) error {
+	type plain BatchResult
+	var decoded struct {
+		plain
+		Object json.RawMessage `json:"object,omitempty"`
+	}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return err
+	}
+
+	ot, obj, err := decodeBatchObject(decoded.Name, decoded.Object)
+	if err != nil {
+		return err
+	}
+
+	*r = BatchResult(decoded.plain)
+	r.ObjectType = ot
+	r.Object = obj
+	return nil
+}
+
+// ApplyBatchOperation applies a single BatchOperation to svc. It does not
+// provide atomicity; implementations of Batch use it to apply each
+// operation once a transaction has been established.
+func ApplyBatchOperation(svc All, op BatchOperation) (BatchResult, error) {
+	result := BatchResult{ObjectType: op.ObjectType}
+
+	switch op.Op {
+	case BatchCreate, BatchModify:
+		if ot, ok := batchObjectType(op.Object); !ok || ot != op.ObjectType {
+			return BatchResult{}, httperr.New400(
+				fmt.Sprintf("%s operation has %T object for %q", op.Op, op.Object, op.Name),
+				httperr.InvalidObjectErrorCode,
+			)
+		}
+
+		obj, err := mutate(svc, op.Op, op.Object)
+		if err != nil {
+			return BatchResult{}, err
+		}
+		result.ObjectKey, _ = api.GetKey(obj)
+		result.Object = obj
+
+	case BatchDelete:
+		if err := deleteObject(svc, op.ObjectType, op.ObjectKey, op.Checksum); err != nil {
+			return BatchResult{}, err
+		}
+		result.ObjectKey = op.ObjectKey
+
+	default:
+		return BatchResult{}, httperr.New400(
+			fmt.Sprintf("unknown batch operation %q", op.Op),
+			httperr.BadParameterErrorCode,
+		)
+	}
+
+	return result, nil
+}
+
+func mutate(svc All, op BatchOpType, object interface{}) (interface{}, error) {
+	create := op == BatchCreate
+
+	switch o := object.(type) {
+	case api.Cluster:
+		if create {
+			return svc.Cluster().Create(o)
+		}
+		return svc.Cluster().Modify(o)
+	case api.Domain:
+		if create {
+			return svc.Domain().Create(o)
+		}
+		return svc.Domain().Modify(o)
+	case api.SharedRules:
+		if create {
+			return svc.SharedRules().Create(o)
+		}
+		return svc.SharedRules().Modify(o)
+	case api.Route:
+		if create {
+			return svc.Route().Create(o)
+		}
+		return svc.Route().Modify(o)
+	case api.Proxy:
+		if create {
+			return svc.Proxy().Create(o)
+		}
+		return svc.Proxy().Modify(o)
+	case api.Listener:
+		if create {
+			return svc.Listener().Create(o)
+		}
+		return svc.Listener().Modify(o)
+	case api.Zone:
+		if create {
+			return svc.Zone().Create(o)
+		}
+		return svc.Zone().Modify(o)
+	}
+
+	panic(fmt.Sprintf("unexpected batch object %T", object))
+}
+
+func deleteObject(
+	svc All,
+	ot objecttype.ObjectType,
+	key string,
+	checksum api.Checksum,
+) error {
+	switch ot {
+	case objecttype.Cluster:
+		return svc.Cluster().Delete(api.ClusterKey(key), checksum)
+	case objecttype.Domain:
+		return svc.Domain().Delete(api.DomainKey(key), checksum)
+	case objecttype.SharedRules:
+		return svc.SharedRules().Delete(api.SharedRulesKey(key), checksum)
+	case objecttype.Route:
+		return svc.Route().Delete(api.RouteKey(key), checksum)
+	case objecttype.Proxy:
+		return svc.Proxy().Delete(api.ProxyKey(key), checksum)
+	case objecttype.Listener:
+		return svc.Listener().Delete(api.ListenerKey(key), checksum)
+	case objecttype.Zone:
+		return svc.Zone().Delete(api.ZoneKey(key), checksum)
+	}
+
+	return httperr.New400(
+		fmt.Sprintf("object type %q may not be used in a batch", ot.Name),
+		httperr.BadParameterErrorCode,
+	)
+}
+
+// batchObjectType returns the ObjectType of an object that may be used in a
+// batch.
+func batchObjectType(object interface{}) (objecttype.ObjectType, bool) {
+	switch object.(type) {
+	case api.Cluster:
+		return objecttype.Cluster, true
+	case api.Domain:
+		return objecttype.Domain, true
+	case api.SharedRules:
+		return objecttype.SharedRules, true
+	case api.Route:
+		return objecttype.Route, true
+	case api.Proxy:
+		return objecttype.Proxy, true
+	case api.Listener:
+		return objecttype.Listener, true
+	case api.Zone:
+		return objecttype.Zone, true
+	}
+	return objecttype.ObjectType{}, false
+}
+
+// decodeBatchObject resolves an ObjectType name and decodes the given JSON
+// as an object of that type.
+func decodeBatchObject(
+	name string,
+	raw json.RawMessage,
+) (objecttype.ObjectType, interface{}, error) {
+	ot, err := objecttype.FromName(name)
+	if err != nil {
+		return objecttype.ObjectType{}, nil, fmt.Errorf("%v: %q", err, name)
+	}
+
+	if len(raw) == 0 || string(raw) == "null" {
+		return ot, nil, nil
+	}
+
+	var target interface{}
+	switch ot {
+	case objecttype.Cluster:
+		target = &api.Cluster{}
+	case objecttype.Domain:
+		target = &api.Domain{}
+	case objecttype.SharedRules:
+		target = &api.SharedRules{}
+	case objecttype.Route:
+		target = &api.Route{}
+	case objecttype.Proxy:
+		target = &api.Proxy{}
+	case objecttype.Listener:
+		target = &api.Listener{}
+	case objecttype.Zone:
+		target = &api.Zone{}
+	default:
+		return ot, nil, fmt.Errorf("object type %q may not be used in a batch", name)
+	}
+
+	if err := json.Unmarshal(raw, target); err != nil {
+		return ot, nil, err
+	}
+	return ot, reflect.ValueOf(target).Elem().Interface(), nil
+}
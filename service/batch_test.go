@@ -0,0 +1,129 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/turbinelabs/api"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/objecttype"
+	"github.com/turbinelabs/test/assert"
+)
+
+func TestBatchOperationJSON(t *testing.T) {
+	ops := []BatchOperation{
+		CreateOperation(api.Cluster{Name: "c", RequireTLS: true}),
+		ModifyOperation(api.Zone{ZoneKey: "zk", Name: "z"}),
+		DeleteOperation(objecttype.Route, "rk", api.Checksum{Checksum: "cs"}),
+	}
+
+	b, err := json.Marshal(ops)
+	assert.Nil(t, err)
+
+	var got []BatchOperation
+	assert.Nil(t, json.Unmarshal(b, &got))
+	assert.DeepEqual(t, got, ops)
+}
+
+func TestBatchOperationJSONErrors(t *testing.T) {
+	var op BatchOperation
+	err := json.Unmarshal([]byte(`{"op":"create","object_type":"nope"}`), &op)
+	assert.ErrorContains(t, err, `"nope"`)
+
+	err = json.Unmarshal([]byte(`{"op":"create","object_type":"user","object":{}}`), &op)
+	assert.ErrorContains(t, err, `object type "user" may not be used in a batch`)
+
+	err = json.Unmarshal([]byte(`{"op":"create","object_type":"zone","object":[]}`), &op)
+	assert.NonNil(t, err)
+}
+
+func TestBatchResultJSON(t *testing.T) {
+	results := []BatchResult{
+		{ObjectType: objecttype.Domain, ObjectKey: "dk", Object: api.Domain{DomainKey: "dk"}},
+		{ObjectType: objecttype.Proxy, ObjectKey: "pk"},
+	}
+
+	b, err := json.Marshal(results)
+	assert.Nil(t, err)
+
+	var got []BatchResult
+	assert.Nil(t, json.Unmarshal(b, &got))
+	assert.DeepEqual(t, got, results)
+}
+
+func TestApplyBatchOperation(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	cluster := api.Cluster{ClusterKey: "ck", Name: "c"}
+	checksum := api.Checksum{Checksum: "cs"}
+
+	svc := NewMockAll(ctrl)
+	clusterSvc := NewMockCluster(ctrl)
+	svc.EXPECT().Cluster().Return(clusterSvc).AnyTimes()
+	clusterSvc.EXPECT().Create(cluster).Return(cluster, nil)
+	clusterSvc.EXPECT().Modify(cluster).Return(cluster, nil)
+	clusterSvc.EXPECT().Delete(api.ClusterKey("ck"), checksum).Return(nil)
+
+	result, err := ApplyBatchOperation(svc, CreateOperation(cluster))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, result, BatchResult{objecttype.Cluster, "ck", cluster})
+
+	result, err = ApplyBatchOperation(svc, ModifyOperation(cluster))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, result, BatchResult{objecttype.Cluster, "ck", cluster})
+
+	result, err = ApplyBatchOperation(svc, DeleteOperation(objecttype.Cluster, "ck", checksum))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, result, BatchResult{ObjectType: objecttype.Cluster, ObjectKey: "ck"})
+}
+
+func TestApplyBatchOperationErrors(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	boom := errors.New("boom")
+
+	svc := NewMockAll(ctrl)
+	domainSvc := NewMockDomain(ctrl)
+	svc.EXPECT().Domain().Return(domainSvc)
+	domainSvc.EXPECT().Create(api.Domain{Name: "d"}).Return(api.Domain{}, boom)
+
+	_, err := ApplyBatchOperation(svc, CreateOperation(api.Domain{Name: "d"}))
+	assert.Equal(t, err, boom)
+
+	op := CreateOperation(api.Domain{Name: "d"})
+	op.ObjectType = objecttype.Zone
+	_, err = ApplyBatchOperation(svc, op)
+	assert.ErrorContains(t, err, `create operation has api.Domain object for "zone"`)
+	assert.Equal(t, err.(*httperr.Error).Code, httperr.InvalidObjectErrorCode)
+
+	_, err = ApplyBatchOperation(svc, CreateOperation(api.User{}))
+	assert.Equal(t, err.(*httperr.Error).Code, httperr.InvalidObjectErrorCode)
+
+	_, err = ApplyBatchOperation(svc, DeleteOperation(objecttype.User, "uk", api.Checksum{}))
+	assert.ErrorContains(t, err, `object type "user" may not be used in a batch`)
+	assert.Equal(t, err.(*httperr.Error).Code, httperr.BadParameterErrorCode)
+
+	_, err = ApplyBatchOperation(svc, BatchOperation{Op: "upsert"})
+	assert.ErrorContains(t, err, `unknown batch operation "upsert"`)
+	assert.Equal(t, err.(*httperr.Error).Code, httperr.BadParameterErrorCode)
+}
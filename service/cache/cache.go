@@ -0,0 +1,360 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cache provides a read-through caching decorator for service.All:
+//
+// 	c := cache.New(svc, cache.Options{TTL: time.Minute})
+// 	cluster, err := c.Cluster().Get(clusterKey)
+//
+// Objects are cached by key, and Index results are memoized per set of
+// filters. Create, Modify and Delete (and the Cluster instance methods) are
+// passed through to the underlying service, and their results are written
+// to the cache; if they fail, the object is discarded instead. Objects
+// changed by a Batch are discarded. Because Index filters may depend on any
+// object, any change discards all memoized Index results.
+//
+// Changes made by other clients are discovered when cached entries expire
+// after the TTL, or by calling PollZone periodically to invalidate the
+// objects changed according to the changelog. History calls are not
+// cached.
+//
+// A Cache is safe for concurrent use. Objects returned are copies, and may
+// be modified by the caller.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/objecttype"
+	"github.com/turbinelabs/api/service"
+	tbntime "github.com/turbinelabs/nonstdlib/time"
+)
+
+const (
+	// DefaultPollOverlap is the default amount by which successive PollZone
+	// windows overlap.
+	DefaultPollOverlap = time.Minute
+
+	// maxPollWindow is the largest window service.History.Zone accepts.
+	maxPollWindow = 24 * time.Hour
+)
+
+// Options configure a Cache.
+type Options struct {
+	// TTL is how long cached entries are used before they are fetched
+	// again. If zero, entries are used until invalidated.
+	TTL time.Duration
+
+	// PollOverlap is the amount by which successive PollZone windows
+	// overlap. It should exceed the longest delay between a change's
+	// timestamp and its appearance in the changelog. If zero,
+	// DefaultPollOverlap is used.
+	PollOverlap time.Duration
+
+	// Time is the source of the current time. If nil, the system clock is
+	// used.
+	Time tbntime.Source
+}
+
+// Stats are counts of cache lookups and invalidations.
+type Stats struct {
+	// Hits is the number of Get and Index calls answered from the cache.
+	Hits uint64
+
+	// Misses is the number of Get and Index calls passed to the underlying
+	// service.
+	Misses uint64
+
+	// Invalidations is the number of cached objects discarded by PollZone,
+	// Invalidate, or a failed mutation.
+	Invalidations uint64
+}
+
+// Cache is a caching service.All. See the package documentation.
+type Cache struct {
+	underlying service.All
+	options    Options
+
+	mu sync.Mutex
+
+	// generation is incremented whenever entries are invalidated, so that
+	// results fetched concurrently with an invalidation are not cached.
+	generation uint64
+	objects    map[objecttype.ObjectType]map[string]entry
+	indexes    map[objecttype.ObjectType]map[string]entry
+	polled     map[api.ZoneKey]time.Time
+	created    time.Time
+	stats      Stats
+}
+
+type entry struct {
+	value   interface{}
+	expires time.Time
+}
+
+var _ service.All = &Cache{}
+
+// New returns a Cache wrapping svc.
+func New(svc service.All, options Options) *Cache {
+	if options.PollOverlap <= 0 {
+		options.PollOverlap = DefaultPollOverlap
+	}
+	if options.Time == nil {
+		options.Time = tbntime.NewSource()
+	}
+
+	return &Cache{
+		underlying: svc,
+		options:    options,
+		objects:    map[objecttype.ObjectType]map[string]entry{},
+		indexes:    map[objecttype.ObjectType]map[string]entry{},
+		polled:     map[api.ZoneKey]time.Time{},
+		created:    options.Time.Now(),
+	}
+}
+
+func (c *Cache) Cluster() service.Cluster {
+	return cachedCluster{c, c.underlying.Cluster()}
+}
+
+func (c *Cache) Domain() service.Domain {
+	return cachedDomain{c, c.underlying.Domain()}
+}
+
+func (c *Cache) SharedRules() service.SharedRules {
+	return cachedSharedRules{c, c.underlying.SharedRules()}
+}
+
+func (c *Cache) Route() service.Route {
+	return cachedRoute{c, c.underlying.Route()}
+}
+
+func (c *Cache) Proxy() service.Proxy {
+	return cachedProxy{c, c.underlying.Proxy()}
+}
+
+func (c *Cache) Listener() service.Listener {
+	return cachedListener{c, c.underlying.Listener()}
+}
+
+func (c *Cache) Zone() service.Zone {
+	return cachedZone{c, c.underlying.Zone()}
+}
+
+// History returns the underlying service.History.
+func (c *Cache) History() service.History {
+	return c.underlying.History()
+}
+
+// Batch returns the underlying service.Batch. Objects changed by a batch
+// are discarded from the cache.
+func (c *Cache) Batch() service.Batch {
+	return cachedBatch{c, c.underlying.Batch()}
+}
+
+// Stats returns the Cache's current Stats.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Invalidate discards the cached object of the given type and key, and all
+// memoized Index results.
+func (c *Cache) Invalidate(ot objecttype.ObjectType, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.invalidate(ot, key)
+}
+
+// InvalidateAll discards all cached objects and Index results.
+func (c *Cache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.objects = map[objecttype.ObjectType]map[string]entry{}
+	c.indexes = map[objecttype.ObjectType]map[string]entry{}
+}
+
+// PollZone invalidates the objects in the given Zone that have changed
+// since the previous call for the Zone, or since the Cache was created,
+// according to service.History.Zone. If that is longer ago than the History
+// API allows, all entries are invalidated.
+func (c *Cache) PollZone(zoneKey api.ZoneKey) error {
+	now := c.options.Time.Now()
+
+	c.mu.Lock()
+	start, ok := c.polled[zoneKey]
+	if !ok {
+		start = c.created
+	}
+	c.mu.Unlock()
+
+	start = start.Add(-c.options.PollOverlap)
+	if now.Sub(start) > maxPollWindow {
+		c.InvalidateAll()
+	} else {
+		changes, err := c.underlying.History().Zone(zoneKey, start, now)
+		if err != nil {
+			return err
+		}
+
+		c.mu.Lock()
+		for _, cd := range changes {
+			for _, e := range cd.Diffs {
+				c.invalidate(e.ObjectType, e.ObjectKey)
+			}
+		}
+		c.mu.Unlock()
+	}
+
+	c.mu.Lock()
+	c.polled[zoneKey] = now
+	c.mu.Unlock()
+	return nil
+}
+
+// invalidate discards an object and all memoized Index results. Callers
+// must hold the lock.
+func (c *Cache) invalidate(ot objecttype.ObjectType, key string) {
+	c.generation++
+	c.indexes = map[objecttype.ObjectType]map[string]entry{}
+	if _, ok := c.objects[ot][key]; ok {
+		delete(c.objects[ot], key)
+		c.stats.Invalidations++
+	}
+}
+
+// get returns the cached object of the given type and key, and the current
+// generation, which must be passed to put if the object is not found.
+func (c *Cache) get(ot objecttype.ObjectType, key string) (interface{}, uint64, bool) {
+	return c.lookup(c.objects, ot, key)
+}
+
+// getIndex returns the memoized Index result for the given filters.
+func (c *Cache) getIndex(ot objecttype.ObjectType, filters interface{}) (interface{}, uint64, bool) {
+	return c.lookup(c.indexes, ot, indexKey(filters))
+}
+
+func (c *Cache) lookup(
+	entries map[objecttype.ObjectType]map[string]entry,
+	ot objecttype.ObjectType,
+	key string,
+) (interface{}, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := entries[ot][key]
+	if ok && (e.expires.IsZero() || c.options.Time.Now().Before(e.expires)) {
+		c.stats.Hits++
+		return e.value, c.generation, true
+	}
+
+	c.stats.Misses++
+	return nil, c.generation, false
+}
+
+// put caches an object fetched during the given generation. If entries
+// have been invalidated since, the object may be stale and is not cached.
+func (c *Cache) put(generation uint64, ot objecttype.ObjectType, key string, v interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation == c.generation {
+		c.store(c.objects, ot, key, v)
+	}
+}
+
+// putIndex memoizes an Index result fetched during the given generation,
+// and caches its objects.
+func (c *Cache) putIndex(
+	generation uint64,
+	ot objecttype.ObjectType,
+	filters interface{},
+	v interface{},
+	objects map[string]interface{},
+) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation == c.generation {
+		c.store(c.indexes, ot, indexKey(filters), v)
+		for key, o := range objects {
+			c.store(c.objects, ot, key, o)
+		}
+	}
+}
+
+// modified caches the result of a mutation, discarding all memoized Index
+// results. A nil value removes the object.
+func (c *Cache) modified(ot objecttype.ObjectType, key string, v interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.indexes = map[objecttype.ObjectType]map[string]entry{}
+	if v == nil {
+		delete(c.objects[ot], key)
+	} else {
+		c.store(c.objects, ot, key, v)
+	}
+}
+
+// store records an entry. Callers must hold the lock.
+func (c *Cache) store(
+	entries map[objecttype.ObjectType]map[string]entry,
+	ot objecttype.ObjectType,
+	key string,
+	v interface{},
+) {
+	e := entry{value: v}
+	if c.options.TTL > 0 {
+		e.expires = c.options.Time.Now().Add(c.options.TTL)
+	}
+
+	if entries[ot] == nil {
+		entries[ot] = map[string]entry{}
+	}
+	entries[ot][key] = e
+}
+
+// indexKey returns a key identifying a set of Index filters.
+func indexKey(filters interface{}) string {
+	b, err := json.Marshal(filters)
+	if err != nil {
+		panic(fmt.Sprintf("unable to encode %T: %v", filters, err))
+	}
+	if string(b) == "null" {
+		return "[]"
+	}
+	return string(b)
+}
+
+// clone makes a deep copy of src into dst via a JSON round trip. Fields that
+// are not serialized (e.g. OrgKey) must be restored by the caller.
+func clone(src, dst interface{}) {
+	b, err := json.Marshal(src)
+	if err != nil {
+		panic(fmt.Sprintf("unable to clone %T: %v", src, err))
+	}
+	if err := json.Unmarshal(b, dst); err != nil {
+		panic(fmt.Sprintf("unable to clone %T: %v", src, err))
+	}
+}
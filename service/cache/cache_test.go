@@ -0,0 +1,359 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/objecttype"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/api/service/memory"
+	tbntime "github.com/turbinelabs/nonstdlib/time"
+	"github.com/turbinelabs/test/assert"
+)
+
+var now = time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC)
+
+type mocks struct {
+	all     *service.MockAll
+	cluster *service.MockCluster
+	history *service.MockHistory
+	time    tbntime.ControlledSource
+}
+
+func newMocks(ctrl *gomock.Controller) mocks {
+	m := mocks{
+		all:     service.NewMockAll(ctrl),
+		cluster: service.NewMockCluster(ctrl),
+		history: service.NewMockHistory(ctrl),
+		time:    tbntime.NewControlledSource(now),
+	}
+	m.all.EXPECT().Cluster().Return(m.cluster).AnyTimes()
+	m.all.EXPECT().History().Return(m.history).AnyTimes()
+	return m
+}
+
+func testCluster() api.Cluster {
+	return api.Cluster{
+		ClusterKey: "ck",
+		ZoneKey:    "zk",
+		Name:       "c",
+		Instances:  api.Instances{{Host: "h", Port: 80}},
+		OrgKey:     "ok",
+		Checksum:   api.Checksum{Checksum: "cs"},
+	}
+}
+
+func TestGet(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	m := newMocks(ctrl)
+	m.cluster.EXPECT().Get(api.ClusterKey("ck")).Return(testCluster(), nil)
+
+	c := New(m.all, Options{Time: m.time})
+
+	got, err := c.Cluster().Get("ck")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, testCluster())
+
+	// callers may modify the results
+	got.Instances[0].Port = 81
+
+	got, err = c.Cluster().Get("ck")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, testCluster())
+	assert.Equal(t, c.Stats(), Stats{Hits: 1, Misses: 1})
+}
+
+func TestGetError(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	boom := errors.New("boom")
+	m := newMocks(ctrl)
+	m.cluster.EXPECT().Get(api.ClusterKey("ck")).Return(api.Cluster{}, boom).Times(2)
+
+	c := New(m.all, Options{Time: m.time})
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Cluster().Get("ck")
+		assert.Equal(t, err, boom)
+	}
+	assert.Equal(t, c.Stats(), Stats{Misses: 2})
+}
+
+func TestGetTTL(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	m := newMocks(ctrl)
+	m.cluster.EXPECT().Get(api.ClusterKey("ck")).Return(testCluster(), nil).Times(2)
+
+	c := New(m.all, Options{TTL: time.Minute, Time: m.time})
+
+	c.Cluster().Get("ck")
+	m.time.Advance(59 * time.Second)
+	c.Cluster().Get("ck")
+	m.time.Advance(time.Second)
+	c.Cluster().Get("ck")
+	assert.Equal(t, c.Stats(), Stats{Hits: 1, Misses: 2})
+}
+
+func TestIndex(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	filter := service.ClusterFilter{Name: "c"}
+	other := testCluster()
+	other.ClusterKey = "ck2"
+
+	m := newMocks(ctrl)
+	gomock.InOrder(
+		m.cluster.EXPECT().Index().Return(api.Clusters{testCluster(), other}, nil),
+		m.cluster.EXPECT().Index(filter).Return(api.Clusters{testCluster()}, nil),
+	)
+
+	c := New(m.all, Options{Time: m.time})
+
+	got, err := c.Cluster().Index()
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, api.Clusters{testCluster(), other})
+
+	got, err = c.Cluster().Index([]service.ClusterFilter{}...)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, api.Clusters{testCluster(), other})
+
+	got, err = c.Cluster().Index(filter)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, api.Clusters{testCluster()})
+
+	// indexed objects are cached
+	cl, err := c.Cluster().Get("ck2")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, cl, other)
+
+	assert.Equal(t, c.Stats(), Stats{Hits: 2, Misses: 2})
+}
+
+func TestWriteThrough(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	cluster := testCluster()
+	modified := testCluster()
+	modified.Name = "c2"
+	instance := api.Instance{Host: "h2", Port: 80}
+	added := modified
+	added.Instances = append(api.Instances{}, modified.Instances...)
+	added.Instances = append(added.Instances, instance)
+
+	m := newMocks(ctrl)
+	gomock.InOrder(
+		m.cluster.EXPECT().Index().Return(api.Clusters{}, nil),
+		m.cluster.EXPECT().Create(cluster).Return(cluster, nil),
+		m.cluster.EXPECT().Index().Return(api.Clusters{cluster}, nil),
+		m.cluster.EXPECT().Modify(modified).Return(modified, nil),
+		m.cluster.EXPECT().AddInstance(api.ClusterKey("ck"), cluster.Checksum, instance).
+			Return(added, nil),
+		m.cluster.EXPECT().Delete(api.ClusterKey("ck"), cluster.Checksum).Return(nil),
+		m.cluster.EXPECT().Get(api.ClusterKey("ck")).Return(api.Cluster{}, errors.New("gone")),
+	)
+
+	c := New(m.all, Options{Time: m.time})
+
+	got, err := c.Cluster().Index()
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 0)
+
+	_, err = c.Cluster().Create(cluster)
+	assert.Nil(t, err)
+
+	// mutations discard memoized Index results
+	got, err = c.Cluster().Index()
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, api.Clusters{cluster})
+
+	_, err = c.Cluster().Modify(modified)
+	assert.Nil(t, err)
+	cl, err := c.Cluster().Get("ck")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, cl, modified)
+
+	_, err = c.Cluster().AddInstance("ck", cluster.Checksum, instance)
+	assert.Nil(t, err)
+	cl, err = c.Cluster().Get("ck")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, cl, added)
+
+	assert.Nil(t, c.Cluster().Delete("ck", cluster.Checksum))
+	_, err = c.Cluster().Get("ck")
+	assert.ErrorContains(t, err, "gone")
+}
+
+func TestModifyError(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	boom := errors.New("checksum mismatch")
+	m := newMocks(ctrl)
+	gomock.InOrder(
+		m.cluster.EXPECT().Get(api.ClusterKey("ck")).Return(testCluster(), nil),
+		m.cluster.EXPECT().Modify(testCluster()).Return(api.Cluster{}, boom),
+		m.cluster.EXPECT().Get(api.ClusterKey("ck")).Return(testCluster(), nil),
+	)
+
+	c := New(m.all, Options{Time: m.time})
+
+	c.Cluster().Get("ck")
+	_, err := c.Cluster().Modify(testCluster())
+	assert.Equal(t, err, boom)
+	c.Cluster().Get("ck")
+	assert.Equal(t, c.Stats(), Stats{Misses: 2, Invalidations: 1})
+}
+
+func TestInvalidate(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	m := newMocks(ctrl)
+	m.cluster.EXPECT().Get(api.ClusterKey("ck")).Return(testCluster(), nil).Times(3)
+
+	c := New(m.all, Options{Time: m.time})
+
+	c.Cluster().Get("ck")
+	c.Invalidate(objecttype.Cluster, "ck")
+	c.Invalidate(objecttype.Cluster, "ck")
+	c.Cluster().Get("ck")
+	c.InvalidateAll()
+	c.Cluster().Get("ck")
+	assert.Equal(t, c.Stats(), Stats{Misses: 3, Invalidations: 1})
+}
+
+func TestBatch(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	ops := []service.BatchOperation{
+		service.DeleteOperation(objecttype.Cluster, "ck", api.Checksum{Checksum: "cs"}),
+	}
+
+	m := newMocks(ctrl)
+	batch := service.NewMockBatch(ctrl)
+	m.all.EXPECT().Batch().Return(batch)
+	batch.EXPECT().
+		Apply(ops).
+		Return([]service.BatchResult{{ObjectType: objecttype.Cluster, ObjectKey: "ck"}}, nil)
+	m.cluster.EXPECT().Get(api.ClusterKey("ck")).Return(testCluster(), nil).Times(2)
+
+	c := New(m.all, Options{Time: m.time})
+
+	c.Cluster().Get("ck")
+	_, err := c.Batch().Apply(ops)
+	assert.Nil(t, err)
+	c.Cluster().Get("ck")
+	assert.Equal(t, c.Stats(), Stats{Misses: 2})
+}
+
+func TestPollZone(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	changes := []api.ChangeDescription{
+		{
+			Diffs: []api.ChangeEntry{
+				{ObjectType: objecttype.Cluster, ObjectKey: "ck", ZoneKey: "zk"},
+			},
+		},
+	}
+
+	m := newMocks(ctrl)
+	gomock.InOrder(
+		m.cluster.EXPECT().Get(api.ClusterKey("ck")).Return(testCluster(), nil),
+		m.history.EXPECT().Zone(api.ZoneKey("zk"), now.Add(-time.Minute), now.Add(time.Hour)).
+			Return(changes, nil),
+		m.cluster.EXPECT().Get(api.ClusterKey("ck")).Return(testCluster(), nil),
+		m.history.EXPECT().Zone(api.ZoneKey("zk"), now.Add(59*time.Minute), now.Add(2*time.Hour)).
+			Return(nil, nil),
+		m.cluster.EXPECT().Get(api.ClusterKey("ck")).Return(testCluster(), nil),
+	)
+
+	c := New(m.all, Options{Time: m.time})
+
+	c.Cluster().Get("ck")
+	m.time.Advance(time.Hour)
+	assert.Nil(t, c.PollZone("zk"))
+	c.Cluster().Get("ck")
+	m.time.Advance(time.Hour)
+	assert.Nil(t, c.PollZone("zk"))
+	c.Cluster().Get("ck")
+
+	// too long since the last poll to query the changelog
+	m.time.Advance(24 * time.Hour)
+	assert.Nil(t, c.PollZone("zk"))
+	c.Cluster().Get("ck")
+	assert.Equal(t, c.Stats(), Stats{Hits: 1, Misses: 3, Invalidations: 1})
+}
+
+func TestPollZoneError(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	boom := errors.New("boom")
+	m := newMocks(ctrl)
+	gomock.InOrder(
+		m.history.EXPECT().Zone(api.ZoneKey("zk"), now.Add(-time.Minute), now).Return(nil, boom),
+		m.history.EXPECT().Zone(api.ZoneKey("zk"), now.Add(-time.Minute), now).Return(nil, nil),
+	)
+
+	c := New(m.all, Options{Time: m.time})
+
+	assert.Equal(t, c.PollZone("zk"), boom)
+	// the window is not advanced after an error
+	assert.Nil(t, c.PollZone("zk"))
+}
+
+func TestPollZoneMemory(t *testing.T) {
+	svc := memory.NewEmpty("ok", "uk")
+	z, err := svc.Zone().Create(api.Zone{Name: "z"})
+	assert.Nil(t, err)
+	cl, err := svc.Cluster().Create(api.Cluster{ZoneKey: z.ZoneKey, Name: "c"})
+	assert.Nil(t, err)
+
+	c := New(svc, Options{})
+
+	got, err := c.Cluster().Get(cl.ClusterKey)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, cl)
+
+	cl.Name = "c2"
+	cl, err = svc.Cluster().Modify(cl)
+	assert.Nil(t, err)
+
+	got, err = c.Cluster().Get(cl.ClusterKey)
+	assert.Nil(t, err)
+	assert.Equal(t, got.Name, "c")
+
+	assert.Nil(t, c.PollZone(z.ZoneKey))
+	got, err = c.Cluster().Get(cl.ClusterKey)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, cl)
+}
@@ -0,0 +1,659 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/objecttype"
+	"github.com/turbinelabs/api/service"
+)
+
+type cachedBatch struct {
+	c   *Cache
+	svc service.Batch
+}
+
+func (cb cachedBatch) Apply(ops []service.BatchOperation) ([]service.BatchResult, error) {
+	results, err := cb.svc.Apply(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range results {
+		cb.c.modified(r.ObjectType, r.ObjectKey, nil)
+	}
+	return results, nil
+}
+
+type cachedCluster struct {
+	c   *Cache
+	svc service.Cluster
+}
+
+func (cc cachedCluster) Index(filters ...service.ClusterFilter) (api.Clusters, error) {
+	v, generation, ok := cc.c.getIndex(objecttype.Cluster, filters)
+	if ok {
+		return cloneClusters(v.(api.Clusters)), nil
+	}
+
+	cls, err := cc.svc.Index(filters...)
+	if err != nil {
+		return cls, err
+	}
+
+	objects := make(map[string]interface{}, len(cls))
+	for _, cl := range cls {
+		objects[string(cl.ClusterKey)] = cloneCluster(cl)
+	}
+	cc.c.putIndex(generation, objecttype.Cluster, filters, cloneClusters(cls), objects)
+	return cls, nil
+}
+
+func (cc cachedCluster) Get(key api.ClusterKey) (api.Cluster, error) {
+	v, generation, ok := cc.c.get(objecttype.Cluster, string(key))
+	if ok {
+		return cloneCluster(v.(api.Cluster)), nil
+	}
+
+	cl, err := cc.svc.Get(key)
+	if err != nil {
+		return cl, err
+	}
+	cc.c.put(generation, objecttype.Cluster, string(key), cloneCluster(cl))
+	return cl, nil
+}
+
+func (cc cachedCluster) Create(cluster api.Cluster) (api.Cluster, error) {
+	cl, err := cc.svc.Create(cluster)
+	if err != nil {
+		return cl, err
+	}
+	cc.c.modified(objecttype.Cluster, string(cl.ClusterKey), cloneCluster(cl))
+	return cl, nil
+}
+
+func (cc cachedCluster) Modify(cluster api.Cluster) (api.Cluster, error) {
+	cl, err := cc.svc.Modify(cluster)
+	if err != nil {
+		cc.c.Invalidate(objecttype.Cluster, string(cluster.ClusterKey))
+		return cl, err
+	}
+	cc.c.modified(objecttype.Cluster, string(cl.ClusterKey), cloneCluster(cl))
+	return cl, nil
+}
+
+func (cc cachedCluster) Delete(key api.ClusterKey, checksum api.Checksum) error {
+	if err := cc.svc.Delete(key, checksum); err != nil {
+		cc.c.Invalidate(objecttype.Cluster, string(key))
+		return err
+	}
+	cc.c.modified(objecttype.Cluster, string(key), nil)
+	return nil
+}
+
+type cachedDomain struct {
+	c   *Cache
+	svc service.Domain
+}
+
+func (cd cachedDomain) Index(filters ...service.DomainFilter) (api.Domains, error) {
+	v, generation, ok := cd.c.getIndex(objecttype.Domain, filters)
+	if ok {
+		return cloneDomains(v.(api.Domains)), nil
+	}
+
+	ds, err := cd.svc.Index(filters...)
+	if err != nil {
+		return ds, err
+	}
+
+	objects := make(map[string]interface{}, len(ds))
+	for _, d := range ds {
+		objects[string(d.DomainKey)] = cloneDomain(d)
+	}
+	cd.c.putIndex(generation, objecttype.Domain, filters, cloneDomains(ds), objects)
+	return ds, nil
+}
+
+func (cd cachedDomain) Get(key api.DomainKey) (api.Domain, error) {
+	v, generation, ok := cd.c.get(objecttype.Domain, string(key))
+	if ok {
+		return cloneDomain(v.(api.Domain)), nil
+	}
+
+	d, err := cd.svc.Get(key)
+	if err != nil {
+		return d, err
+	}
+	cd.c.put(generation, objecttype.Domain, string(key), cloneDomain(d))
+	return d, nil
+}
+
+func (cd cachedDomain) Create(domain api.Domain) (api.Domain, error) {
+	d, err := cd.svc.Create(domain)
+	if err != nil {
+		return d, err
+	}
+	cd.c.modified(objecttype.Domain, string(d.DomainKey), cloneDomain(d))
+	return d, nil
+}
+
+func (cd cachedDomain) Modify(domain api.Domain) (api.Domain, error) {
+	d, err := cd.svc.Modify(domain)
+	if err != nil {
+		cd.c.Invalidate(objecttype.Domain, string(domain.DomainKey))
+		return d, err
+	}
+	cd.c.modified(objecttype.Domain, string(d.DomainKey), cloneDomain(d))
+	return d, nil
+}
+
+func (cd cachedDomain) Delete(key api.DomainKey, checksum api.Checksum) error {
+	if err := cd.svc.Delete(key, checksum); err != nil {
+		cd.c.Invalidate(objecttype.Domain, string(key))
+		return err
+	}
+	cd.c.modified(objecttype.Domain, string(key), nil)
+	return nil
+}
+
+type cachedSharedRules struct {
+	c   *Cache
+	svc service.SharedRules
+}
+
+func (csr cachedSharedRules) Index(filters ...service.SharedRulesFilter) (api.SharedRulesSlice, error) {
+	v, generation, ok := csr.c.getIndex(objecttype.SharedRules, filters)
+	if ok {
+		return cloneSharedRulesSlice(v.(api.SharedRulesSlice)), nil
+	}
+
+	srs, err := csr.svc.Index(filters...)
+	if err != nil {
+		return srs, err
+	}
+
+	objects := make(map[string]interface{}, len(srs))
+	for _, sr := range srs {
+		objects[string(sr.SharedRulesKey)] = cloneSharedRules(sr)
+	}
+	csr.c.putIndex(generation, objecttype.SharedRules, filters, cloneSharedRulesSlice(srs), objects)
+	return srs, nil
+}
+
+func (csr cachedSharedRules) Get(key api.SharedRulesKey) (api.SharedRules, error) {
+	v, generation, ok := csr.c.get(objecttype.SharedRules, string(key))
+	if ok {
+		return cloneSharedRules(v.(api.SharedRules)), nil
+	}
+
+	sr, err := csr.svc.Get(key)
+	if err != nil {
+		return sr, err
+	}
+	csr.c.put(generation, objecttype.SharedRules, string(key), cloneSharedRules(sr))
+	return sr, nil
+}
+
+func (csr cachedSharedRules) Create(sharedRules api.SharedRules) (api.SharedRules, error) {
+	sr, err := csr.svc.Create(sharedRules)
+	if err != nil {
+		return sr, err
+	}
+	csr.c.modified(objecttype.SharedRules, string(sr.SharedRulesKey), cloneSharedRules(sr))
+	return sr, nil
+}
+
+func (csr cachedSharedRules) Modify(sharedRules api.SharedRules) (api.SharedRules, error) {
+	sr, err := csr.svc.Modify(sharedRules)
+	if err != nil {
+		csr.c.Invalidate(objecttype.SharedRules, string(sharedRules.SharedRulesKey))
+		return sr, err
+	}
+	csr.c.modified(objecttype.SharedRules, string(sr.SharedRulesKey), cloneSharedRules(sr))
+	return sr, nil
+}
+
+func (csr cachedSharedRules) Delete(key api.SharedRulesKey, checksum api.Checksum) error {
+	if err := csr.svc.Delete(key, checksum); err != nil {
+		csr.c.Invalidate(objecttype.SharedRules, string(key))
+		return err
+	}
+	csr.c.modified(objecttype.SharedRules, string(key), nil)
+	return nil
+}
+
+type cachedRoute struct {
+	c   *Cache
+	svc service.Route
+}
+
+func (cr cachedRoute) Index(filters ...service.RouteFilter) (api.Routes, error) {
+	v, generation, ok := cr.c.getIndex(objecttype.Route, filters)
+	if ok {
+		return cloneRoutes(v.(api.Routes)), nil
+	}
+
+	rs, err := cr.svc.Index(filters...)
+	if err != nil {
+		return rs, err
+	}
+
+	objects := make(map[string]interface{}, len(rs))
+	for _, r := range rs {
+		objects[string(r.RouteKey)] = cloneRoute(r)
+	}
+	cr.c.putIndex(generation, objecttype.Route, filters, cloneRoutes(rs), objects)
+	return rs, nil
+}
+
+func (cr cachedRoute) Get(key api.RouteKey) (api.Route, error) {
+	v, generation, ok := cr.c.get(objecttype.Route, string(key))
+	if ok {
+		return cloneRoute(v.(api.Route)), nil
+	}
+
+	r, err := cr.svc.Get(key)
+	if err != nil {
+		return r, err
+	}
+	cr.c.put(generation, objecttype.Route, string(key), cloneRoute(r))
+	return r, nil
+}
+
+func (cr cachedRoute) Create(route api.Route) (api.Route, error) {
+	r, err := cr.svc.Create(route)
+	if err != nil {
+		return r, err
+	}
+	cr.c.modified(objecttype.Route, string(r.RouteKey), cloneRoute(r))
+	return r, nil
+}
+
+func (cr cachedRoute) Modify(route api.Route) (api.Route, error) {
+	r, err := cr.svc.Modify(route)
+	if err != nil {
+		cr.c.Invalidate(objecttype.Route, string(route.RouteKey))
+		return r, err
+	}
+	cr.c.modified(objecttype.Route, string(r.RouteKey), cloneRoute(r))
+	return r, nil
+}
+
+func (cr cachedRoute) Delete(key api.RouteKey, checksum api.Checksum) error {
+	if err := cr.svc.Delete(key, checksum); err != nil {
+		cr.c.Invalidate(objecttype.Route, string(key))
+		return err
+	}
+	cr.c.modified(objecttype.Route, string(key), nil)
+	return nil
+}
+
+type cachedProxy struct {
+	c   *Cache
+	svc service.Proxy
+}
+
+func (cp cachedProxy) Index(filters ...service.ProxyFilter) (api.Proxies, error) {
+	v, generation, ok := cp.c.getIndex(objecttype.Proxy, filters)
+	if ok {
+		return cloneProxies(v.(api.Proxies)), nil
+	}
+
+	ps, err := cp.svc.Index(filters...)
+	if err != nil {
+		return ps, err
+	}
+
+	objects := make(map[string]interface{}, len(ps))
+	for _, p := range ps {
+		objects[string(p.ProxyKey)] = cloneProxy(p)
+	}
+	cp.c.putIndex(generation, objecttype.Proxy, filters, cloneProxies(ps), objects)
+	return ps, nil
+}
+
+func (cp cachedProxy) Get(key api.ProxyKey) (api.Proxy, error) {
+	v, generation, ok := cp.c.get(objecttype.Proxy, string(key))
+	if ok {
+		return cloneProxy(v.(api.Proxy)), nil
+	}
+
+	p, err := cp.svc.Get(key)
+	if err != nil {
+		return p, err
+	}
+	cp.c.put(generation, objecttype.Proxy, string(key), cloneProxy(p))
+	return p, nil
+}
+
+func (cp cachedProxy) Create(proxy api.Proxy) (api.Proxy, error) {
+	p, err := cp.svc.Create(proxy)
+	if err != nil {
+		return p, err
+	}
+	cp.c.modified(objecttype.Proxy, string(p.ProxyKey), cloneProxy(p))
+	return p, nil
+}
+
+func (cp cachedProxy) Modify(proxy api.Proxy) (api.Proxy, error) {
+	p, err := cp.svc.Modify(proxy)
+	if err != nil {
+		cp.c.Invalidate(objecttype.Proxy, string(proxy.ProxyKey))
+		return p, err
+	}
+	cp.c.modified(objecttype.Proxy, string(p.ProxyKey), cloneProxy(p))
+	return p, nil
+}
+
+func (cp cachedProxy) Delete(key api.ProxyKey, checksum api.Checksum) error {
+	if err := cp.svc.Delete(key, checksum); err != nil {
+		cp.c.Invalidate(objecttype.Proxy, string(key))
+		return err
+	}
+	cp.c.modified(objecttype.Proxy, string(key), nil)
+	return nil
+}
+
+type cachedListener struct {
+	c   *Cache
+	svc service.Listener
+}
+
+func (cl cachedListener) Index(filters ...service.ListenerFilter) (api.Listeners, error) {
+	v, generation, ok := cl.c.getIndex(objecttype.Listener, filters)
+	if ok {
+		return cloneListeners(v.(api.Listeners)), nil
+	}
+
+	ls, err := cl.svc.Index(filters...)
+	if err != nil {
+		return ls, err
+	}
+
+	objects := make(map[string]interface{}, len(ls))
+	for _, l := range ls {
+		objects[string(l.ListenerKey)] = cloneListener(l)
+	}
+	cl.c.putIndex(generation, objecttype.Listener, filters, cloneListeners(ls), objects)
+	return ls, nil
+}
+
+func (cl cachedListener) Get(key api.ListenerKey) (api.Listener, error) {
+	v, generation, ok := cl.c.get(objecttype.Listener, string(key))
+	if ok {
+		return cloneListener(v.(api.Listener)), nil
+	}
+
+	l, err := cl.svc.Get(key)
+	if err != nil {
+		return l, err
+	}
+	cl.c.put(generation, objecttype.Listener, string(key), cloneListener(l))
+	return l, nil
+}
+
+func (cl cachedListener) Create(listener api.Listener) (api.Listener, error) {
+	l, err := cl.svc.Create(listener)
+	if err != nil {
+		return l, err
+	}
+	cl.c.modified(objecttype.Listener, string(l.ListenerKey), cloneListener(l))
+	return l, nil
+}
+
+func (cl cachedListener) Modify(listener api.Listener) (api.Listener, error) {
+	l, err := cl.svc.Modify(listener)
+	if err != nil {
+		cl.c.Invalidate(objecttype.Listener, string(listener.ListenerKey))
+		return l, err
+	}
+	cl.c.modified(objecttype.Listener, string(l.ListenerKey), cloneListener(l))
+	return l, nil
+}
+
+func (cl cachedListener) Delete(key api.ListenerKey, checksum api.Checksum) error {
+	if err := cl.svc.Delete(key, checksum); err != nil {
+		cl.c.Invalidate(objecttype.Listener, string(key))
+		return err
+	}
+	cl.c.modified(objecttype.Listener, string(key), nil)
+	return nil
+}
+
+type cachedZone struct {
+	c   *Cache
+	svc service.Zone
+}
+
+func (cz cachedZone) Index(filters ...service.ZoneFilter) (api.Zones, error) {
+	v, generation, ok := cz.c.getIndex(objecttype.Zone, filters)
+	if ok {
+		return cloneZones(v.(api.Zones)), nil
+	}
+
+	zs, err := cz.svc.Index(filters...)
+	if err != nil {
+		return zs, err
+	}
+
+	objects := make(map[string]interface{}, len(zs))
+	for _, z := range zs {
+		objects[string(z.ZoneKey)] = cloneZone(z)
+	}
+	cz.c.putIndex(generation, objecttype.Zone, filters, cloneZones(zs), objects)
+	return zs, nil
+}
+
+func (cz cachedZone) Get(key api.ZoneKey) (api.Zone, error) {
+	v, generation, ok := cz.c.get(objecttype.Zone, string(key))
+	if ok {
+		return cloneZone(v.(api.Zone)), nil
+	}
+
+	z, err := cz.svc.Get(key)
+	if err != nil {
+		return z, err
+	}
+	cz.c.put(generation, objecttype.Zone, string(key), cloneZone(z))
+	return z, nil
+}
+
+func (cz cachedZone) Create(zone api.Zone) (api.Zone, error) {
+	z, err := cz.svc.Create(zone)
+	if err != nil {
+		return z, err
+	}
+	cz.c.modified(objecttype.Zone, string(z.ZoneKey), cloneZone(z))
+	return z, nil
+}
+
+func (cz cachedZone) Modify(zone api.Zone) (api.Zone, error) {
+	z, err := cz.svc.Modify(zone)
+	if err != nil {
+		cz.c.Invalidate(objecttype.Zone, string(zone.ZoneKey))
+		return z, err
+	}
+	cz.c.modified(objecttype.Zone, string(z.ZoneKey), cloneZone(z))
+	return z, nil
+}
+
+func (cz cachedZone) Delete(key api.ZoneKey, checksum api.Checksum) error {
+	if err := cz.svc.Delete(key, checksum); err != nil {
+		cz.c.Invalidate(objecttype.Zone, string(key))
+		return err
+	}
+	cz.c.modified(objecttype.Zone, string(key), nil)
+	return nil
+}
+
+func (cc cachedCluster) AddInstance(
+	key api.ClusterKey,
+	checksum api.Checksum,
+	instance api.Instance,
+) (api.Cluster, error) {
+	cl, err := cc.svc.AddInstance(key, checksum, instance)
+	if err != nil {
+		cc.c.Invalidate(objecttype.Cluster, string(key))
+		return cl, err
+	}
+	cc.c.modified(objecttype.Cluster, string(key), cloneCluster(cl))
+	return cl, nil
+}
+
+func (cc cachedCluster) RemoveInstance(
+	key api.ClusterKey,
+	checksum api.Checksum,
+	instance api.Instance,
+) (api.Cluster, error) {
+	cl, err := cc.svc.RemoveInstance(key, checksum, instance)
+	if err != nil {
+		cc.c.Invalidate(objecttype.Cluster, string(key))
+		return cl, err
+	}
+	cc.c.modified(objecttype.Cluster, string(key), cloneCluster(cl))
+	return cl, nil
+}
+
+func cloneCluster(cl api.Cluster) api.Cluster {
+	var c api.Cluster
+	clone(cl, &c)
+	c.OrgKey = cl.OrgKey
+	return c
+}
+
+func cloneClusters(cls api.Clusters) api.Clusters {
+	if cls == nil {
+		return nil
+	}
+	c := make(api.Clusters, len(cls))
+	for i := range cls {
+		c[i] = cloneCluster(cls[i])
+	}
+	return c
+}
+
+func cloneDomain(d api.Domain) api.Domain {
+	var c api.Domain
+	clone(d, &c)
+	c.OrgKey = d.OrgKey
+	return c
+}
+
+func cloneDomains(ds api.Domains) api.Domains {
+	if ds == nil {
+		return nil
+	}
+	c := make(api.Domains, len(ds))
+	for i := range ds {
+		c[i] = cloneDomain(ds[i])
+	}
+	return c
+}
+
+func cloneSharedRules(sr api.SharedRules) api.SharedRules {
+	var c api.SharedRules
+	clone(sr, &c)
+	c.OrgKey = sr.OrgKey
+	return c
+}
+
+func cloneSharedRulesSlice(srs api.SharedRulesSlice) api.SharedRulesSlice {
+	if srs == nil {
+		return nil
+	}
+	c := make(api.SharedRulesSlice, len(srs))
+	for i := range srs {
+		c[i] = cloneSharedRules(srs[i])
+	}
+	return c
+}
+
+func cloneRoute(r api.Route) api.Route {
+	var c api.Route
+	clone(r, &c)
+	c.OrgKey = r.OrgKey
+	return c
+}
+
+func cloneRoutes(rs api.Routes) api.Routes {
+	if rs == nil {
+		return nil
+	}
+	c := make(api.Routes, len(rs))
+	for i := range rs {
+		c[i] = cloneRoute(rs[i])
+	}
+	return c
+}
+
+func cloneProxy(p api.Proxy) api.Proxy {
+	var c api.Proxy
+	clone(p, &c)
+	c.OrgKey = p.OrgKey
+	for i := range c.Listeners {
+		c.Listeners[i].OrgKey = p.Listeners[i].OrgKey
+	}
+	return c
+}
+
+func cloneProxies(ps api.Proxies) api.Proxies {
+	if ps == nil {
+		return nil
+	}
+	c := make(api.Proxies, len(ps))
+	for i := range ps {
+		c[i] = cloneProxy(ps[i])
+	}
+	return c
+}
+
+func cloneListener(l api.Listener) api.Listener {
+	var c api.Listener
+	clone(l, &c)
+	c.OrgKey = l.OrgKey
+	return c
+}
+
+func cloneListeners(ls api.Listeners) api.Listeners {
+	if ls == nil {
+		return nil
+	}
+	c := make(api.Listeners, len(ls))
+	for i := range ls {
+		c[i] = cloneListener(ls[i])
+	}
+	return c
+}
+
+func cloneZone(z api.Zone) api.Zone {
+	var c api.Zone
+	clone(z, &c)
+	c.OrgKey = z.OrgKey
+	return c
+}
+
+func cloneZones(zs api.Zones) api.Zones {
+	if zs == nil {
+		return nil
+	}
+	c := make(api.Zones, len(zs))
+	for i := range zs {
+		c[i] = cloneZone(zs[i])
+	}
+	return c
+}
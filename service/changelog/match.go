@@ -0,0 +1,117 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"strings"
+
+	"github.com/turbinelabs/api"
+)
+
+func matchesString(want, got string) bool {
+	return want == "" || want == got
+}
+
+// Matches determines whether the Filter applies to a ChangeEntry recorded
+// as part of the change described by meta. Unset criteria match any entry.
+// ObjectType is compared case-insensitively. If AttributePath is set, it
+// must be a prefix of the entry's path, or equal to it if AbsoluteMatchOnly
+// is set. NegativeMatch inverts the result of all criteria except the
+// TimeRange.
+func (f Filter) Matches(meta api.ChangeMeta, e api.ChangeEntry) bool {
+	atUs := meta.AtMs * 1000
+	if f.TimeRange.Start != nil && atUs < *f.TimeRange.Start {
+		return false
+	}
+	if f.TimeRange.End != nil && atUs >= *f.TimeRange.End {
+		return false
+	}
+
+	ff := f.FieldFilter
+	matches := matchesString(strings.ToLower(f.ObjectType), e.ObjectType.Name) &&
+		matchesString(f.ObjectKey, e.ObjectKey) &&
+		matchesString(f.ChangeTxn, meta.Txn) &&
+		matchesString(string(f.ZoneKey), string(e.ZoneKey)) &&
+		matchesString(string(f.OrgKey), string(meta.OrgKey)) &&
+		matchesString(string(f.Actor), string(meta.ActorKey)) &&
+		matchesString(string(ff.ChangeType), e.ChangeType.Name) &&
+		(ff.AttributeValue == nil || *ff.AttributeValue == e.Value) &&
+		(!ff.ExcludeEmptyValues || e.Value != "")
+
+	if matches && ff.AttributePath != "" {
+		if ff.AbsoluteMatchOnly {
+			matches = e.Path == ff.AttributePath
+		} else {
+			matches = strings.HasPrefix(e.Path, ff.AttributePath)
+		}
+	}
+
+	if f.NegativeMatch {
+		return !matches
+	}
+	return matches
+}
+
+// Matches determines whether a FilterExpr applies to a ChangeEntry recorded
+// as part of the change described by meta. A nil or empty expression
+// applies to all entries.
+func Matches(expr FilterExpr, meta api.ChangeMeta, e api.ChangeEntry) bool {
+	if expr == nil {
+		return true
+	}
+
+	ors := expr.AsExpr()
+	if len(ors.FilterAnds) == 0 {
+		return true
+	}
+
+	for _, ands := range ors.FilterAnds {
+		all := true
+		for _, f := range ands.Filters {
+			if !f.Matches(meta, e) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Select returns the ChangeDescriptions limited to the ChangeEntries to
+// which the FilterExpr applies, as the server does when evaluating an
+// expression. Descriptions with no remaining entries are omitted.
+func Select(expr FilterExpr, changes []api.ChangeDescription) []api.ChangeDescription {
+	result := []api.ChangeDescription{}
+	for _, cd := range changes {
+		diffs := []api.ChangeEntry{}
+		for _, e := range cd.Diffs {
+			if Matches(expr, cd.ChangeMeta, e) {
+				diffs = append(diffs, e)
+			}
+		}
+
+		if len(diffs) > 0 {
+			result = append(result, api.ChangeDescription{ChangeMeta: cd.ChangeMeta, Diffs: diffs})
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"testing"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/changetype"
+	"github.com/turbinelabs/api/objecttype"
+	"github.com/turbinelabs/nonstdlib/ptr"
+	"github.com/turbinelabs/test/assert"
+)
+
+var (
+	// 2018-01-01T00:00:00Z
+	testAtMs = int64(1514764800000)
+
+	testMeta = api.ChangeMeta{
+		AtMs:     testAtMs,
+		Txn:      "txn-1",
+		OrgKey:   "ok",
+		ActorKey: "bob",
+	}
+
+	testEntry = api.ChangeEntry{
+		ObjectType: objecttype.Cluster,
+		ObjectKey:  "ck",
+		ZoneKey:    "zk",
+		ChangeType: changetype.Addition,
+		Path:       "cluster.instances[10.0.0.1:8080].metadata[stage]",
+		Value:      "prod",
+	}
+)
+
+func TestFilterMatches(t *testing.T) {
+	atUs := testAtMs * 1000
+	field := func(ff FieldFilter) Filter { return Filter{FieldFilter: ff} }
+
+	for _, tc := range []struct {
+		name   string
+		filter Filter
+		value  string
+		want   bool
+	}{
+		{"empty", Filter{}, "prod", true},
+
+		{"time range start inclusive", Filter{TimeRange: TimeRange{Start: ptr.Int64(atUs)}}, "prod", true},
+		{"time range start", Filter{TimeRange: TimeRange{Start: ptr.Int64(atUs + 1)}}, "prod", false},
+		{"time range end exclusive", Filter{TimeRange: TimeRange{End: ptr.Int64(atUs)}}, "prod", false},
+		{"time range end", Filter{TimeRange: TimeRange{End: ptr.Int64(atUs + 1)}}, "prod", true},
+		{
+			"time range",
+			Filter{TimeRange: TimeRange{Start: ptr.Int64(atUs - 1), End: ptr.Int64(atUs + 1)}},
+			"prod",
+			true,
+		},
+
+		{"object type", Filter{ObjectType: "cluster"}, "prod", true},
+		{"object type case", Filter{ObjectType: "Cluster"}, "prod", true},
+		{"object type mismatch", Filter{ObjectType: "route"}, "prod", false},
+		{"object key", Filter{ObjectKey: "ck"}, "prod", true},
+		{"object key mismatch", Filter{ObjectKey: "ck2"}, "prod", false},
+		{"change txn", Filter{ChangeTxn: "txn-1"}, "prod", true},
+		{"change txn mismatch", Filter{ChangeTxn: "txn-2"}, "prod", false},
+		{"zone key", Filter{ZoneKey: "zk"}, "prod", true},
+		{"zone key mismatch", Filter{ZoneKey: "zk2"}, "prod", false},
+		{"org key", Filter{OrgKey: "ok"}, "prod", true},
+		{"org key mismatch", Filter{OrgKey: "ok2"}, "prod", false},
+		{"actor", Filter{Actor: "bob"}, "prod", true},
+		{"actor mismatch", Filter{Actor: "alice"}, "prod", false},
+		{"all", Filter{ObjectType: "cluster", ObjectKey: "ck", Actor: "bob"}, "prod", true},
+		{"all but one", Filter{ObjectType: "cluster", ObjectKey: "ck", Actor: "alice"}, "prod", false},
+
+		{"path prefix", field(FieldFilter{AttributePath: "cluster.instances"}), "prod", true},
+		{"path exact prefix", field(FieldFilter{AttributePath: testEntry.Path}), "prod", true},
+		{"path prefix mismatch", field(FieldFilter{AttributePath: "cluster.name"}), "prod", false},
+		{
+			"absolute path",
+			field(FieldFilter{AttributePath: testEntry.Path, AbsoluteMatchOnly: true}),
+			"prod",
+			true,
+		},
+		{
+			"absolute path prefix",
+			field(FieldFilter{AttributePath: "cluster.instances", AbsoluteMatchOnly: true}),
+			"prod",
+			false,
+		},
+		{"absolute without path", field(FieldFilter{AbsoluteMatchOnly: true}), "prod", true},
+		{"value", field(FieldFilter{AttributeValue: ptr.String("prod")}), "prod", true},
+		{"value mismatch", field(FieldFilter{AttributeValue: ptr.String("dev")}), "prod", false},
+		{"empty value", field(FieldFilter{AttributeValue: ptr.String("")}), "", true},
+		{"empty value mismatch", field(FieldFilter{AttributeValue: ptr.String("")}), "prod", false},
+		{"exclude empty values", field(FieldFilter{ExcludeEmptyValues: true}), "prod", true},
+		{"exclude empty values empty", field(FieldFilter{ExcludeEmptyValues: true}), "", false},
+		{"change type", field(FieldFilter{ChangeType: ValueAdded}), "prod", true},
+		{"change type mismatch", field(FieldFilter{ChangeType: ValueRemoved}), "prod", false},
+
+		{"negative", Filter{NegativeMatch: true}, "prod", false},
+		{"negative object type", Filter{NegativeMatch: true, ObjectType: "route"}, "prod", true},
+		{"negative actor", Filter{NegativeMatch: true, Actor: "bob"}, "prod", false},
+		{
+			"negative path",
+			Filter{NegativeMatch: true, FieldFilter: FieldFilter{AttributePath: "cluster.name"}},
+			"prod",
+			true,
+		},
+		{
+			"negative ignores time range",
+			Filter{NegativeMatch: true, TimeRange: TimeRange{Start: ptr.Int64(atUs + 1)}},
+			"prod",
+			false,
+		},
+		{
+			"negative within time range",
+			Filter{
+				NegativeMatch: true,
+				TimeRange:     TimeRange{Start: ptr.Int64(atUs)},
+				ObjectType:    "route",
+			},
+			"prod",
+			true,
+		},
+	} {
+		assert.Group(tc.name, t, func(g *assert.G) {
+			e := testEntry
+			e.Value = tc.value
+			assert.Equal(g, tc.filter.Matches(testMeta, e), tc.want)
+			assert.Equal(g, Matches(tc.filter, testMeta, e), tc.want)
+		})
+	}
+}
+
+func TestMatchesExpr(t *testing.T) {
+	yes := Filter{ObjectType: "cluster"}
+	no := Filter{ObjectType: "route"}
+
+	for _, tc := range []struct {
+		name string
+		expr FilterExpr
+		want bool
+	}{
+		{"nil", nil, true},
+		{"empty ors", FilterOrs{}, true},
+		{"empty ands", NewFilterIntersection(), true},
+		{"ands", NewFilterIntersection(yes, yes), true},
+		{"ands mismatch", NewFilterIntersection(yes, no), false},
+		{"ors", NewFilterUnion(no, yes), true},
+		{"ors mismatch", NewFilterUnion(no, no), false},
+		{
+			"sum of products",
+			FilterOrs{[]FilterAnds{NewFilterIntersection(yes, no), NewFilterIntersection(yes, yes)}},
+			true,
+		},
+		{
+			"sum of products mismatch",
+			FilterOrs{[]FilterAnds{NewFilterIntersection(yes, no), NewFilterIntersection(no, yes)}},
+			false,
+		},
+	} {
+		assert.Group(tc.name, t, func(g *assert.G) {
+			assert.Equal(g, Matches(tc.expr, testMeta, testEntry), tc.want)
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	other := testEntry
+	other.ObjectKey = "ck2"
+
+	changes := []api.ChangeDescription{
+		{ChangeMeta: testMeta, Diffs: []api.ChangeEntry{testEntry, other}},
+		{ChangeMeta: api.ChangeMeta{Txn: "txn-2"}, Diffs: []api.ChangeEntry{other}},
+	}
+
+	assert.DeepEqual(
+		t,
+		Select(Filter{ObjectKey: "ck"}, changes),
+		[]api.ChangeDescription{{ChangeMeta: testMeta, Diffs: []api.ChangeEntry{testEntry}}},
+	)
+	assert.DeepEqual(t, Select(nil, changes), changes)
+	assert.DeepEqual(t, Select(Filter{ObjectKey: "ck3"}, changes), []api.ChangeDescription{})
+}
@@ -0,0 +1,658 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/nonstdlib/ptr"
+	tbntime "github.com/turbinelabs/nonstdlib/time"
+)
+
+// ParseError describes a syntax error in a query.
+type ParseError struct {
+	// Column is the 1-based position of the error, in characters.
+	Column int
+
+	// Msg describes the error.
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Msg)
+}
+
+// ParseQuery parses a query, a textual form of a FilterExpr, into a
+// FilterOrs. Queries are composed of terms combined with AND, OR, NOT and
+// parentheses. NOT binds most tightly, followed by AND and then OR. For
+// example:
+//
+// 	object_type=cluster AND actor=bob OR (attribute_path~"rules" AND NOT zone=prod)
+//
+// Each term compares a field with a value, which must be double-quoted if it
+// contains whitespace, parentheses, '=', '~' or '"', or is a keyword. The
+// fields, and the Filter attributes they correspond to, are:
+//
+// 	object_type           ObjectType
+// 	object_key            ObjectKey
+// 	txn                   ChangeTxn
+// 	zone                  ZoneKey
+// 	org                   OrgKey
+// 	actor                 Actor
+// 	attribute_path        AttributePath; '~' matches by prefix, '=' sets
+// 	                      AbsoluteMatchOnly
+// 	attribute_value       AttributeValue
+// 	change_type           ChangeType ("addition" or "removal")
+// 	exclude_empty_values  ExcludeEmptyValues ("true" or "false")
+// 	since                 TimeRange.Start
+// 	until                 TimeRange.End
+//
+// The Filter attribute JSON names (e.g. actor_key) may also be used. Times
+// are given as RFC 3339 timestamps, microseconds since the Unix epoch, or
+// durations relative to the current time (e.g. "-2h"). Time ranges may not
+// be negated.
+//
+// The query is converted into sum-of-products form by pushing negations
+// down to individual terms and distributing AND over OR. Relative times are
+// resolved against the current time. Because distributing AND over OR may
+// multiply the number of products, a query whose sum-of-products form has
+// more than 256 products is rejected. An empty query matches all changes.
+func ParseQuery(q string) (FilterOrs, error) {
+	return ParseQueryAt(q, time.Now())
+}
+
+// ParseQueryAt parses a query into a FilterOrs, resolving relative times
+// against now.
+func ParseQueryAt(q string, now time.Time) (FilterOrs, error) {
+	p := &parser{query: q, now: now}
+	if err := p.next(); err != nil {
+		return FilterOrs{}, err
+	}
+	if p.tok.kind == tokEOF {
+		return FilterOrs{}, nil
+	}
+
+	n, err := p.or()
+	if err != nil {
+		return FilterOrs{}, err
+	}
+	if p.tok.kind != tokEOF {
+		return FilterOrs{}, p.errorf("unexpected %s", p.tok)
+	}
+
+	products, err := n.dnf(false)
+	if err != nil {
+		return FilterOrs{}, err
+	}
+
+	ors := FilterOrs{FilterAnds: make([]FilterAnds, len(products))}
+	for i, filters := range products {
+		ors.FilterAnds[i] = NewFilterIntersection(filters...)
+	}
+	return ors, nil
+}
+
+// FormatQuery returns the canonical query for a FilterExpr, which
+// ParseQuery parses into an equivalent expression. Times are formatted as
+// RFC 3339 timestamps.
+func FormatQuery(expr FilterExpr) string {
+	if expr == nil {
+		return ""
+	}
+
+	ors := []string{}
+	for _, ands := range expr.AsExpr().FilterAnds {
+		terms := []string{}
+		for _, f := range ands.Filters {
+			terms = append(terms, formatFilter(f)...)
+		}
+		ors = append(ors, strings.Join(terms, " AND "))
+	}
+	return strings.Join(ors, " OR ")
+}
+
+func formatFilter(f Filter) []string {
+	terms := []string{}
+	term := func(field, op, value string) {
+		terms = append(terms, field+op+formatValue(value))
+	}
+
+	set := func(field, value string) {
+		if value != "" {
+			term(field, "=", value)
+		}
+	}
+
+	set(fieldObjectType, f.ObjectType)
+	set(fieldObjectKey, f.ObjectKey)
+	set(fieldTxn, f.ChangeTxn)
+	set(fieldZone, string(f.ZoneKey))
+	set(fieldOrg, string(f.OrgKey))
+	set(fieldActor, string(f.Actor))
+	if ff := f.FieldFilter; ff.AttributePath != "" {
+		if ff.AbsoluteMatchOnly {
+			term(fieldAttributePath, "=", ff.AttributePath)
+		} else {
+			term(fieldAttributePath, "~", ff.AttributePath)
+		}
+	}
+	if f.AttributeValue != nil {
+		term(fieldAttributeValue, "=", *f.AttributeValue)
+	}
+	set(fieldChangeType, string(f.ChangeType))
+	if f.ExcludeEmptyValues {
+		term(fieldExcludeEmptyValues, "=", "true")
+	}
+
+	if f.NegativeMatch {
+		switch len(terms) {
+		case 0:
+		case 1:
+			terms = []string{"NOT " + terms[0]}
+		default:
+			terms = []string{"NOT (" + strings.Join(terms, " AND ") + ")"}
+		}
+	}
+
+	// NegativeMatch does not apply to the TimeRange
+	if t := f.TimeRange.StartTime(); t != nil {
+		term(fieldSince, "=", t.UTC().Format(time.RFC3339Nano))
+	}
+	if t := f.TimeRange.EndTime(); t != nil {
+		term(fieldUntil, "=", t.UTC().Format(time.RFC3339Nano))
+	}
+
+	return terms
+}
+
+func formatValue(v string) string {
+	if v == "" || isKeyword(v) || strings.IndexFunc(v, isSpecial) >= 0 {
+		return strconv.Quote(v)
+	}
+	return v
+}
+
+const (
+	fieldObjectType         = "object_type"
+	fieldObjectKey          = "object_key"
+	fieldTxn                = "txn"
+	fieldZone               = "zone"
+	fieldOrg                = "org"
+	fieldActor              = "actor"
+	fieldAttributePath      = "attribute_path"
+	fieldAttributeValue     = "attribute_value"
+	fieldChangeType         = "change_type"
+	fieldExcludeEmptyValues = "exclude_empty_values"
+	fieldSince              = "since"
+	fieldUntil              = "until"
+)
+
+// fieldAliases maps alternate field names to their canonical names.
+var fieldAliases = map[string]string{
+	"change_txn": fieldTxn,
+	"zone_key":   fieldZone,
+	"org_key":    fieldOrg,
+	"actor_key":  fieldActor,
+	"start":      fieldSince,
+	"end":        fieldUntil,
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value string
+	pos   int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of query"
+	}
+	return strconv.Quote(t.text)
+}
+
+func isSpecial(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(`()=~"`, r)
+}
+
+func isKeyword(s string) bool {
+	switch strings.ToUpper(s) {
+	case "AND", "OR", "NOT":
+		return true
+	}
+	return false
+}
+
+// node is an element of a parsed query's syntax tree.
+type node interface {
+	// dnf returns the node, negated if requested, in disjunctive normal
+	// form.
+	dnf(negate bool) ([][]Filter, error)
+}
+
+// maxProducts is the maximum number of products in the sum-of-products form
+// of a query.
+const maxProducts = 256
+
+// andNode and orNode record the column of their operator, at which errors
+// are reported if the query expands to too many products.
+type andNode struct {
+	left, right node
+	col         int
+}
+
+type orNode struct {
+	left, right node
+	col         int
+}
+
+type notNode struct{ operand node }
+
+type termNode struct {
+	pos   int
+	field string
+	set   func(*Filter)
+	time  bool
+}
+
+func (n andNode) dnf(negate bool) ([][]Filter, error) {
+	if negate {
+		return orNode{notNode{n.left}, notNode{n.right}, n.col}.dnf(false)
+	}
+
+	left, err := n.left.dnf(false)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.dnf(false)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(left)*len(right) > maxProducts {
+		return nil, tooManyProducts(n.col)
+	}
+
+	result := [][]Filter{}
+	for _, l := range left {
+		for _, r := range right {
+			product := make([]Filter, 0, len(l)+len(r))
+			product = append(product, l...)
+			result = append(result, append(product, r...))
+		}
+	}
+	return result, nil
+}
+
+func (n orNode) dnf(negate bool) ([][]Filter, error) {
+	if negate {
+		return andNode{notNode{n.left}, notNode{n.right}, n.col}.dnf(false)
+	}
+
+	left, err := n.left.dnf(false)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.dnf(false)
+	if err != nil {
+		return nil, err
+	}
+	if len(left)+len(right) > maxProducts {
+		return nil, tooManyProducts(n.col)
+	}
+	return append(left, right...), nil
+}
+
+func tooManyProducts(col int) error {
+	return &ParseError{
+		Column: col,
+		Msg:    fmt.Sprintf("query expands to more than %d alternatives", maxProducts),
+	}
+}
+
+func (n notNode) dnf(negate bool) ([][]Filter, error) {
+	if negate {
+		return n.operand.dnf(false)
+	}
+
+	// A negated conjunction of distinct fields is a single negated Filter.
+	if terms, ok := conjunction(n.operand, map[string]bool{}); ok && len(terms) > 1 {
+		f := Filter{NegativeMatch: true}
+		for _, t := range terms {
+			t.set(&f)
+		}
+		return [][]Filter{{f}}, nil
+	}
+
+	return n.operand.dnf(true)
+}
+
+func (n termNode) dnf(negate bool) ([][]Filter, error) {
+	f := Filter{}
+	n.set(&f)
+	if negate {
+		if n.time {
+			return nil, &ParseError{Column: n.pos, Msg: n.field + " may not be negated"}
+		}
+		f.NegativeMatch = true
+	}
+	return [][]Filter{{f}}, nil
+}
+
+// conjunction returns the terms of a node composed only of ANDed terms of
+// distinct, non-time fields.
+func conjunction(n node, seen map[string]bool) ([]termNode, bool) {
+	switch n := n.(type) {
+	case termNode:
+		if n.time || seen[n.field] {
+			return nil, false
+		}
+		seen[n.field] = true
+		return []termNode{n}, true
+
+	case andNode:
+		left, ok := conjunction(n.left, seen)
+		if !ok {
+			return nil, false
+		}
+		right, ok := conjunction(n.right, seen)
+		if !ok {
+			return nil, false
+		}
+		return append(left, right...), true
+	}
+
+	return nil, false
+}
+
+type parser struct {
+	query string
+	now   time.Time
+	pos   int
+	tok   token
+}
+
+func (p *parser) column(pos int) int {
+	return utf8.RuneCountInString(p.query[:pos]) + 1
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &ParseError{Column: p.column(p.tok.pos), Msg: fmt.Sprintf(format, args...)}
+}
+
+// next advances to the next token.
+func (p *parser) next() error {
+	for p.pos < len(p.query) {
+		r, size := utf8.DecodeRuneInString(p.query[p.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		p.pos += size
+	}
+
+	start := p.pos
+	p.tok = token{pos: start}
+	if start == len(p.query) {
+		p.tok.kind = tokEOF
+		return nil
+	}
+
+	switch c := p.query[start]; c {
+	case '(':
+		p.tok.kind = tokLParen
+		p.pos++
+	case ')':
+		p.tok.kind = tokRParen
+		p.pos++
+	case '=', '~':
+		p.tok.kind = tokOp
+		p.pos++
+	case '"':
+		end := start + 1
+		for ; end < len(p.query) && p.query[end] != '"'; end++ {
+			if p.query[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(p.query) {
+			return &ParseError{Column: p.column(start), Msg: "unterminated string"}
+		}
+		p.pos = end + 1
+		value, err := strconv.Unquote(p.query[start:p.pos])
+		if err != nil {
+			return &ParseError{Column: p.column(start), Msg: "invalid string"}
+		}
+		p.tok.kind = tokString
+		p.tok.value = value
+	default:
+		end := strings.IndexFunc(p.query[start:], isSpecial)
+		if end < 0 {
+			end = len(p.query) - start
+		}
+		p.pos = start + end
+		p.tok.kind = tokWord
+		p.tok.value = p.query[start:p.pos]
+		switch strings.ToUpper(p.tok.value) {
+		case "AND":
+			p.tok.kind = tokAnd
+		case "OR":
+			p.tok.kind = tokOr
+		case "NOT":
+			p.tok.kind = tokNot
+		}
+	}
+
+	p.tok.text = p.query[start:p.pos]
+	return nil
+}
+
+func (p *parser) or() (node, error) {
+	n, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOr {
+		col := p.column(p.tok.pos)
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		n = orNode{n, right, col}
+	}
+	return n, nil
+}
+
+func (p *parser) and() (node, error) {
+	n, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokAnd {
+		col := p.column(p.tok.pos)
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		n = andNode{n, right, col}
+	}
+	return n, nil
+}
+
+func (p *parser) unary() (node, error) {
+	switch p.tok.kind {
+	case tokNot:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		n, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{n}, nil
+
+	case tokLParen:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf("expected \")\", found %s", p.tok)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		return n, nil
+
+	case tokWord:
+		return p.term()
+	}
+
+	return nil, p.errorf("expected field name, found %s", p.tok)
+}
+
+func (p *parser) term() (node, error) {
+	pos := p.column(p.tok.pos)
+	field := strings.ToLower(p.tok.value)
+	if alias, ok := fieldAliases[field]; ok {
+		field = alias
+	}
+
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokOp {
+		return nil, p.errorf("expected \"=\" or \"~\", found %s", p.tok)
+	}
+	op := p.tok.text
+	opCol := p.column(p.tok.pos)
+
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokWord && p.tok.kind != tokString {
+		return nil, p.errorf("expected value, found %s", p.tok)
+	}
+	value := p.tok.value
+	valueCol := p.column(p.tok.pos)
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	if op == "~" && field != fieldAttributePath {
+		return nil, &ParseError{Column: opCol, Msg: "\"~\" may only be used with " + fieldAttributePath}
+	}
+
+	n := termNode{pos: pos, field: field}
+	invalid := func(msg string) error {
+		return &ParseError{Column: valueCol, Msg: fmt.Sprintf("invalid %s %q: %s", field, value, msg)}
+	}
+
+	switch field {
+	case fieldObjectType:
+		n.set = func(f *Filter) { f.ObjectType = value }
+	case fieldObjectKey:
+		n.set = func(f *Filter) { f.ObjectKey = value }
+	case fieldTxn:
+		n.set = func(f *Filter) { f.ChangeTxn = value }
+	case fieldZone:
+		n.set = func(f *Filter) { f.ZoneKey = api.ZoneKey(value) }
+	case fieldOrg:
+		n.set = func(f *Filter) { f.OrgKey = api.OrgKey(value) }
+	case fieldActor:
+		n.set = func(f *Filter) { f.Actor = api.UserKey(value) }
+	case fieldAttributePath:
+		absolute := op == "="
+		n.set = func(f *Filter) {
+			f.AttributePath = value
+			f.AbsoluteMatchOnly = absolute
+		}
+	case fieldAttributeValue:
+		n.set = func(f *Filter) { f.AttributeValue = ptr.String(value) }
+	case fieldChangeType:
+		ct := ChangeType(strings.ToLower(value))
+		if ct != ValueAdded && ct != ValueRemoved {
+			return nil, invalid(fmt.Sprintf("must be %q or %q", ValueAdded, ValueRemoved))
+		}
+		n.set = func(f *Filter) { f.ChangeType = ct }
+	case fieldExcludeEmptyValues:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, invalid("must be true or false")
+		}
+		n.set = func(f *Filter) { f.ExcludeEmptyValues = b }
+	case fieldSince, fieldUntil:
+		us, err := parseTime(value, p.now)
+		if err != nil {
+			return nil, invalid(err.Error())
+		}
+		n.time = true
+		if field == fieldSince {
+			n.set = func(f *Filter) { f.TimeRange.Start = ptr.Int64(us) }
+		} else {
+			n.set = func(f *Filter) { f.TimeRange.End = ptr.Int64(us) }
+		}
+	default:
+		return nil, &ParseError{Column: pos, Msg: fmt.Sprintf("unknown field %q", field)}
+	}
+
+	return n, nil
+}
+
+// parseTime parses a time given in microseconds since the Unix epoch, as an
+// RFC 3339 timestamp, or as a duration relative to now. The result is in
+// microseconds since the Unix epoch.
+func parseTime(s string, now time.Time) (int64, error) {
+	if us, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return us, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return tbntime.ToUnixMicro(t), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return tbntime.ToUnixMicro(now.Add(d)), nil
+	}
+	return 0, fmt.Errorf("expected timestamp, microseconds, or duration")
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changelog
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turbinelabs/nonstdlib/ptr"
+	tbntime "github.com/turbinelabs/nonstdlib/time"
+	"github.com/turbinelabs/test/assert"
+)
+
+var queryNow = time.Date(2018, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestParseQuery(t *testing.T) {
+	ands := NewFilterIntersection
+	twoHoursAgo := ptr.Int64(tbntime.ToUnixMicro(queryNow.Add(-2 * time.Hour)))
+
+	for _, tc := range []struct {
+		name  string
+		query string
+		want  FilterOrs
+	}{
+		{"empty", "  ", FilterOrs{}},
+		{"term", "object_type=cluster", NewFilterUnion(Filter{ObjectType: "cluster"})},
+		{
+			"example",
+			`object_type=cluster AND actor=bob OR (attribute_path~"rules" AND NOT zone=prod)`,
+			FilterOrs{[]FilterAnds{
+				ands(Filter{ObjectType: "cluster"}, Filter{Actor: "bob"}),
+				ands(
+					Filter{FieldFilter: FieldFilter{AttributePath: "rules"}},
+					Filter{NegativeMatch: true, ZoneKey: "prod"},
+				),
+			}},
+		},
+		{
+			"keywords are case-insensitive",
+			"object_key=a or not object_key=b",
+			NewFilterUnion(Filter{ObjectKey: "a"}, Filter{NegativeMatch: true, ObjectKey: "b"}),
+		},
+		{
+			"aliases",
+			"change_txn=t AND zone_key=z AND org_key=o AND actor_key=a",
+			FilterOrs{[]FilterAnds{ands(
+				Filter{ChangeTxn: "t"},
+				Filter{ZoneKey: "z"},
+				Filter{OrgKey: "o"},
+				Filter{Actor: "a"},
+			)}},
+		},
+		{
+			"field filters",
+			`attribute_path="cluster.instances[a:80]" AND attribute_value="" AND ` +
+				`change_type=removal AND exclude_empty_values=true`,
+			FilterOrs{[]FilterAnds{ands(
+				Filter{FieldFilter: FieldFilter{AttributePath: "cluster.instances[a:80]", AbsoluteMatchOnly: true}},
+				Filter{FieldFilter: FieldFilter{AttributeValue: ptr.String("")}},
+				Filter{FieldFilter: FieldFilter{ChangeType: ValueRemoved}},
+				Filter{FieldFilter: FieldFilter{ExcludeEmptyValues: true}},
+			)}},
+		},
+		{
+			"times",
+			"since=-2h AND until=2018-01-01T12:00:00Z AND start=5",
+			FilterOrs{[]FilterAnds{ands(
+				Filter{TimeRange: TimeRange{Start: twoHoursAgo}},
+				Filter{TimeRange: TimeRange{End: ptr.Int64(tbntime.ToUnixMicro(queryNow))}},
+				Filter{TimeRange: TimeRange{Start: ptr.Int64(5)}},
+			)}},
+		},
+		{
+			"distributes and over or",
+			"(actor=a OR actor=b) AND (zone=x OR zone=y)",
+			FilterOrs{[]FilterAnds{
+				ands(Filter{Actor: "a"}, Filter{ZoneKey: "x"}),
+				ands(Filter{Actor: "a"}, Filter{ZoneKey: "y"}),
+				ands(Filter{Actor: "b"}, Filter{ZoneKey: "x"}),
+				ands(Filter{Actor: "b"}, Filter{ZoneKey: "y"}),
+			}},
+		},
+		{
+			"negated conjunction",
+			"NOT (actor=a AND zone=x)",
+			NewFilterUnion(Filter{NegativeMatch: true, Actor: "a", ZoneKey: "x"}),
+		},
+		{
+			"negated conjunction of one field",
+			"NOT (actor=a AND actor=b)",
+			NewFilterUnion(
+				Filter{NegativeMatch: true, Actor: "a"},
+				Filter{NegativeMatch: true, Actor: "b"},
+			),
+		},
+		{
+			"negated disjunction",
+			"NOT (actor=a OR zone=x)",
+			FilterOrs{[]FilterAnds{ands(
+				Filter{NegativeMatch: true, Actor: "a"},
+				Filter{NegativeMatch: true, ZoneKey: "x"},
+			)}},
+		},
+		{"double negation", "NOT NOT actor=a", NewFilterUnion(Filter{Actor: "a"})},
+		{
+			"quoted keyword",
+			`actor="AND" AND actor="say \"hi\""`,
+			FilterOrs{[]FilterAnds{ands(Filter{Actor: "AND"}, Filter{Actor: `say "hi"`})}},
+		},
+	} {
+		assert.Group(tc.name, t, func(g *assert.G) {
+			got, err := ParseQueryAt(tc.query, queryNow)
+			assert.Nil(g, err)
+			assert.DeepEqual(g, got, tc.want)
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		err   string
+	}{
+		{"actor", `column 6: expected "=" or "~", found end of query`},
+		{"actor=", "column 7: expected value, found end of query"},
+		{"actor=a AND", "column 12: expected field name, found end of query"},
+		{"actor=a zone=b", `column 9: unexpected "zone"`},
+		{"(actor=a", `column 9: expected ")", found end of query`},
+		{"actor=a)", `column 8: unexpected ")"`},
+		{`actor="a`, "column 7: unterminated string"},
+		{"nope=a", `column 1: unknown field "nope"`},
+		{"actor~a", `column 6: "~" may only be used with attribute_path`},
+		{"change_type=modify", `column 13: invalid change_type "modify": must be "addition" or "removal"`},
+		{"exclude_empty_values=maybe", `column 22: invalid exclude_empty_values "maybe": must be true or false`},
+		{"since=yesterday", `column 7: invalid since "yesterday": expected timestamp`},
+		{"actor=a AND NOT since=-1h", "column 17: since may not be negated"},
+		{"NOT (actor=a OR until=-1h)", "column 17: until may not be negated"},
+		{"actor=é AND =", `column 13: expected field name, found "="`},
+	} {
+		assert.Group(tc.query, t, func(g *assert.G) {
+			_, err := ParseQueryAt(tc.query, queryNow)
+			assert.ErrorContains(g, err, tc.err)
+			_, ok := err.(*ParseError)
+			assert.True(g, ok)
+		})
+	}
+}
+
+func TestParseQueryTooManyProducts(t *testing.T) {
+	term := "(actor=a OR actor=b)"
+	terms := make([]string, 9)
+	for i := range terms {
+		terms[i] = term
+	}
+
+	// eight terms expand to exactly 256 products
+	ors, err := ParseQueryAt(strings.Join(terms[:8], " AND "), queryNow)
+	assert.Nil(t, err)
+	assert.Equal(t, len(ors.FilterAnds), 256)
+
+	// the ninth is rejected at its AND
+	_, err = ParseQueryAt(strings.Join(terms, " AND "), queryNow)
+	col := 8*len(term) + 7*len(" AND ") + 2
+	assert.DeepEqual(t, err, &ParseError{
+		Column: col,
+		Msg:    "query expands to more than 256 alternatives",
+	})
+}
+
+func TestFormatQuery(t *testing.T) {
+	start := tbntime.ToUnixMicro(queryNow)
+
+	for _, tc := range []struct {
+		name string
+		expr FilterExpr
+		want string
+	}{
+		{"nil", nil, ""},
+		{"empty", FilterOrs{}, ""},
+		{"filter", Filter{ObjectType: "cluster", Actor: "bob"}, "object_type=cluster AND actor=bob"},
+		{
+			"negated filter",
+			Filter{
+				NegativeMatch: true,
+				ObjectKey:     "ck",
+				TimeRange:     TimeRange{Start: ptr.Int64(start)},
+			},
+			"NOT object_key=ck AND since=2018-01-01T12:00:00Z",
+		},
+		{
+			"negated conjunction",
+			Filter{NegativeMatch: true, ChangeTxn: "t", OrgKey: "o"},
+			"NOT (txn=t AND org=o)",
+		},
+		{
+			"field filter",
+			Filter{FieldFilter: FieldFilter{
+				AttributePath:      "cluster.name",
+				AttributeValue:     ptr.String("a b"),
+				ChangeType:         ValueAdded,
+				ExcludeEmptyValues: true,
+			}},
+			`attribute_path~cluster.name AND attribute_value="a b" AND change_type=addition AND ` +
+				"exclude_empty_values=true",
+		},
+		{
+			"absolute path",
+			Filter{FieldFilter: FieldFilter{AttributePath: "a", AbsoluteMatchOnly: true}},
+			"attribute_path=a",
+		},
+		{
+			"sum of products",
+			FilterOrs{[]FilterAnds{
+				NewFilterIntersection(Filter{Actor: "or"}, Filter{ZoneKey: "z"}),
+				NewFilterIntersection(Filter{TimeRange: TimeRange{End: ptr.Int64(start + 1)}}),
+			}},
+			`actor="or" AND zone=z OR until=2018-01-01T12:00:00.000001Z`,
+		},
+	} {
+		assert.Group(tc.name, t, func(g *assert.G) {
+			got := FormatQuery(tc.expr)
+			assert.Equal(g, got, tc.want)
+
+			if tc.expr != nil {
+				parsed, err := ParseQueryAt(got, queryNow)
+				assert.Nil(g, err)
+				assert.Equal(g, FormatQuery(parsed), got)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+//go:generate mockgen -source $GOFILE -destination mock_$GOFILE -package $GOPACKAGE --write_package_comment=false
+
+import (
+	"context"
+	"time"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service/changelog"
+)
+
+// AllContext is the context-aware counterpart of All. Each method of each
+// sub-interface behaves as its All counterpart, but accepts a
+// context.Context whose cancellation or deadline aborts the call. See
+// FromAllContext and ToAllContext to adapt between the two.
+type AllContext interface {
+	Cluster() ClusterContext
+	Domain() DomainContext
+	SharedRules() SharedRulesContext
+	Route() RouteContext
+	Proxy() ProxyContext
+	Listener() ListenerContext
+	Zone() ZoneContext
+	History() HistoryContext
+	Batch() BatchContext
+}
+
+// AdminContext is the context-aware counterpart of Admin.
+type AdminContext interface {
+	User() UserContext
+	Org() OrgContext
+	AccessToken() AccessTokenContext
+}
+
+// ClusterContext is the context-aware counterpart of Cluster.
+type ClusterContext interface {
+	Index(ctx context.Context, filters ...ClusterFilter) (api.Clusters, error)
+	Get(ctx context.Context, clusterKey api.ClusterKey) (api.Cluster, error)
+	Create(ctx context.Context, cluster api.Cluster) (api.Cluster, error)
+	Modify(ctx context.Context, cluster api.Cluster) (api.Cluster, error)
+	Delete(ctx context.Context, clusterKey api.ClusterKey, checksum api.Checksum) error
+
+	AddInstance(
+		ctx context.Context,
+		clusterKey api.ClusterKey,
+		checksum api.Checksum,
+		instance api.Instance,
+	) (api.Cluster, error)
+
+	RemoveInstance(
+		ctx context.Context,
+		clusterKey api.ClusterKey,
+		checksum api.Checksum,
+		instance api.Instance,
+	) (api.Cluster, error)
+}
+
+// DomainContext is the context-aware counterpart of Domain.
+type DomainContext interface {
+	Index(ctx context.Context, filters ...DomainFilter) (api.Domains, error)
+	Get(ctx context.Context, domainKey api.DomainKey) (api.Domain, error)
+	Create(ctx context.Context, domain api.Domain) (api.Domain, error)
+	Modify(ctx context.Context, domain api.Domain) (api.Domain, error)
+	Delete(ctx context.Context, domainKey api.DomainKey, checksum api.Checksum) error
+}
+
+// ProxyContext is the context-aware counterpart of Proxy.
+type ProxyContext interface {
+	Index(ctx context.Context, filters ...ProxyFilter) (api.Proxies, error)
+	Get(ctx context.Context, proxyKey api.ProxyKey) (api.Proxy, error)
+	Create(ctx context.Context, proxy api.Proxy) (api.Proxy, error)
+	Modify(ctx context.Context, proxy api.Proxy) (api.Proxy, error)
+	Delete(ctx context.Context, proxyKey api.ProxyKey, checksum api.Checksum) error
+}
+
+// ListenerContext is the context-aware counterpart of Listener.
+type ListenerContext interface {
+	Index(ctx context.Context, filters ...ListenerFilter) (api.Listeners, error)
+	Get(ctx context.Context, listenerKey api.ListenerKey) (api.Listener, error)
+	Create(ctx context.Context, listener api.Listener) (api.Listener, error)
+	Modify(ctx context.Context, listener api.Listener) (api.Listener, error)
+	Delete(ctx context.Context, listenerKey api.ListenerKey, checksum api.Checksum) error
+}
+
+// SharedRulesContext is the context-aware counterpart of SharedRules.
+type SharedRulesContext interface {
+	Index(ctx context.Context, filters ...SharedRulesFilter) (api.SharedRulesSlice, error)
+	Get(ctx context.Context, sharedRulesKey api.SharedRulesKey) (api.SharedRules, error)
+	Create(ctx context.Context, sharedRules api.SharedRules) (api.SharedRules, error)
+	Modify(ctx context.Context, sharedRules api.SharedRules) (api.SharedRules, error)
+	Delete(
+		ctx context.Context,
+		sharedRulesKey api.SharedRulesKey,
+		checksum api.Checksum,
+	) error
+}
+
+// RouteContext is the context-aware counterpart of Route.
+type RouteContext interface {
+	Index(ctx context.Context, filters ...RouteFilter) (api.Routes, error)
+	Get(ctx context.Context, routeKey api.RouteKey) (api.Route, error)
+	Create(ctx context.Context, route api.Route) (api.Route, error)
+	Modify(ctx context.Context, route api.Route) (api.Route, error)
+	Delete(ctx context.Context, routeKey api.RouteKey, checksum api.Checksum) error
+}
+
+// ZoneContext is the context-aware counterpart of Zone.
+type ZoneContext interface {
+	Index(ctx context.Context, filters ...ZoneFilter) (api.Zones, error)
+	Get(ctx context.Context, zoneKey api.ZoneKey) (api.Zone, error)
+	Create(ctx context.Context, zone api.Zone) (api.Zone, error)
+	Modify(ctx context.Context, zone api.Zone) (api.Zone, error)
+	Delete(ctx context.Context, zoneKey api.ZoneKey, checksum api.Checksum) error
+}
+
+// UserContext is the context-aware counterpart of User.
+type UserContext interface {
+	Index(ctx context.Context, filters ...UserFilter) (api.Users, error)
+	Get(ctx context.Context, userKey api.UserKey) (api.User, error)
+	Create(ctx context.Context, user api.User) (api.User, error)
+	Modify(ctx context.Context, user api.User) (api.User, error)
+	Delete(ctx context.Context, userKey api.UserKey, checksum api.Checksum) error
+}
+
+// OrgContext is the context-aware counterpart of Org.
+type OrgContext interface {
+	Index(ctx context.Context, filters ...OrgFilter) (api.Orgs, error)
+	Get(ctx context.Context, orgKey api.OrgKey) (api.Org, error)
+	Create(ctx context.Context, org api.Org) (api.Org, error)
+	Modify(ctx context.Context, org api.Org) (api.Org, error)
+	Delete(ctx context.Context, orgKey api.OrgKey, checksum api.Checksum) error
+}
+
+// AccessTokenContext is the context-aware counterpart of AccessToken.
+type AccessTokenContext interface {
+	Index(ctx context.Context, filters ...AccessTokenFilter) (api.AccessTokens, error)
+	Get(ctx context.Context, key api.AccessTokenKey) (api.AccessToken, error)
+	Create(ctx context.Context, token api.AccessToken) (api.AccessToken, error)
+	Delete(ctx context.Context, key api.AccessTokenKey, checksum api.Checksum) error
+}
+
+// HistoryContext is the context-aware counterpart of History.
+type HistoryContext interface {
+	Index(
+		ctx context.Context,
+		filters changelog.FilterExpr,
+		start,
+		end time.Time,
+	) ([]api.ChangeDescription, error)
+
+	DomainGraph(
+		ctx context.Context,
+		domainKey api.DomainKey,
+		start,
+		stop time.Time,
+	) ([]api.ChangeDescription, error)
+
+	RouteGraph(
+		ctx context.Context,
+		routeKey api.RouteKey,
+		start,
+		stop time.Time,
+	) ([]api.ChangeDescription, error)
+
+	SharedRulesGraph(
+		ctx context.Context,
+		sharedRulesKey api.SharedRulesKey,
+		start,
+		stop time.Time,
+	) ([]api.ChangeDescription, error)
+
+	ClusterGraph(
+		ctx context.Context,
+		clusterKey api.ClusterKey,
+		start,
+		stop time.Time,
+	) ([]api.ChangeDescription, error)
+
+	Zone(
+		ctx context.Context,
+		zoneKey api.ZoneKey,
+		start,
+		stop time.Time,
+	) ([]api.ChangeDescription, error)
+}
+
+// BatchContext is the context-aware counterpart of Batch.
+type BatchContext interface {
+	Apply(ctx context.Context, ops []BatchOperation) ([]BatchResult, error)
+}
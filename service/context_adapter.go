@@ -0,0 +1,1118 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service/changelog"
+)
+
+// FromAllContext adapts an AllContext to All. Each call is made with
+// context.Background().
+func FromAllContext(svc AllContext) All {
+	return allFromContext{svc}
+}
+
+// FromAdminContext adapts an AdminContext to Admin. Each call is made with
+// context.Background().
+func FromAdminContext(svc AdminContext) Admin {
+	return adminFromContext{svc}
+}
+
+// ToAllContext adapts an All to AllContext. Since All cannot be interrupted,
+// each call fails immediately with the context's error if the context is
+// already done, and otherwise runs to completion.
+func ToAllContext(svc All) AllContext {
+	return allToContext{svc}
+}
+
+// ToAdminContext adapts an Admin to AdminContext. See ToAllContext.
+func ToAdminContext(svc Admin) AdminContext {
+	return adminToContext{svc}
+}
+
+type allFromContext struct {
+	svc AllContext
+}
+
+func (a allFromContext) Cluster() Cluster {
+	return FromClusterContext(a.svc.Cluster())
+}
+
+func (a allFromContext) Domain() Domain {
+	return FromDomainContext(a.svc.Domain())
+}
+
+func (a allFromContext) SharedRules() SharedRules {
+	return FromSharedRulesContext(a.svc.SharedRules())
+}
+
+func (a allFromContext) Route() Route {
+	return FromRouteContext(a.svc.Route())
+}
+
+func (a allFromContext) Proxy() Proxy {
+	return FromProxyContext(a.svc.Proxy())
+}
+
+func (a allFromContext) Listener() Listener {
+	return FromListenerContext(a.svc.Listener())
+}
+
+func (a allFromContext) Zone() Zone {
+	return FromZoneContext(a.svc.Zone())
+}
+
+func (a allFromContext) History() History {
+	return FromHistoryContext(a.svc.History())
+}
+
+func (a allFromContext) Batch() Batch {
+	return FromBatchContext(a.svc.Batch())
+}
+
+type adminFromContext struct {
+	svc AdminContext
+}
+
+func (a adminFromContext) User() User {
+	return FromUserContext(a.svc.User())
+}
+
+func (a adminFromContext) Org() Org {
+	return FromOrgContext(a.svc.Org())
+}
+
+func (a adminFromContext) AccessToken() AccessToken {
+	return FromAccessTokenContext(a.svc.AccessToken())
+}
+
+type allToContext struct {
+	svc All
+}
+
+func (a allToContext) Cluster() ClusterContext {
+	return clusterToContext{a.svc.Cluster()}
+}
+
+func (a allToContext) Domain() DomainContext {
+	return domainToContext{a.svc.Domain()}
+}
+
+func (a allToContext) SharedRules() SharedRulesContext {
+	return sharedRulesToContext{a.svc.SharedRules()}
+}
+
+func (a allToContext) Route() RouteContext {
+	return routeToContext{a.svc.Route()}
+}
+
+func (a allToContext) Proxy() ProxyContext {
+	return proxyToContext{a.svc.Proxy()}
+}
+
+func (a allToContext) Listener() ListenerContext {
+	return listenerToContext{a.svc.Listener()}
+}
+
+func (a allToContext) Zone() ZoneContext {
+	return zoneToContext{a.svc.Zone()}
+}
+
+func (a allToContext) History() HistoryContext {
+	return historyToContext{a.svc.History()}
+}
+
+func (a allToContext) Batch() BatchContext {
+	return batchToContext{a.svc.Batch()}
+}
+
+type adminToContext struct {
+	svc Admin
+}
+
+func (a adminToContext) User() UserContext {
+	return userToContext{a.svc.User()}
+}
+
+func (a adminToContext) Org() OrgContext {
+	return orgToContext{a.svc.Org()}
+}
+
+func (a adminToContext) AccessToken() AccessTokenContext {
+	return accessTokenToContext{a.svc.AccessToken()}
+}
+
+// FromClusterContext adapts a ClusterContext to Cluster. Each call is made with
+// context.Background().
+func FromClusterContext(svc ClusterContext) Cluster {
+	return clusterFromContext{svc}
+}
+
+type clusterFromContext struct {
+	svc ClusterContext
+}
+
+func (a clusterFromContext) Index(filters ...ClusterFilter) (api.Clusters, error) {
+	return a.svc.Index(context.Background(), filters...)
+}
+
+func (a clusterFromContext) Get(clusterKey api.ClusterKey) (api.Cluster, error) {
+	return a.svc.Get(context.Background(), clusterKey)
+}
+
+func (a clusterFromContext) Create(cluster api.Cluster) (api.Cluster, error) {
+	return a.svc.Create(context.Background(), cluster)
+}
+
+func (a clusterFromContext) Modify(cluster api.Cluster) (api.Cluster, error) {
+	return a.svc.Modify(context.Background(), cluster)
+}
+
+func (a clusterFromContext) Delete(clusterKey api.ClusterKey, checksum api.Checksum) error {
+	return a.svc.Delete(context.Background(), clusterKey, checksum)
+}
+
+func (a clusterFromContext) AddInstance(
+	clusterKey api.ClusterKey,
+	checksum api.Checksum,
+	instance api.Instance,
+) (api.Cluster, error) {
+	return a.svc.AddInstance(context.Background(), clusterKey, checksum, instance)
+}
+
+func (a clusterFromContext) RemoveInstance(
+	clusterKey api.ClusterKey,
+	checksum api.Checksum,
+	instance api.Instance,
+) (api.Cluster, error) {
+	return a.svc.RemoveInstance(context.Background(), clusterKey, checksum, instance)
+}
+
+type clusterToContext struct {
+	svc Cluster
+}
+
+func (a clusterToContext) Index(
+	ctx context.Context,
+	filters ...ClusterFilter,
+) (api.Clusters, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.svc.Index(filters...)
+}
+
+func (a clusterToContext) Get(ctx context.Context, clusterKey api.ClusterKey) (api.Cluster, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Cluster{}, err
+	}
+	return a.svc.Get(clusterKey)
+}
+
+func (a clusterToContext) Create(ctx context.Context, cluster api.Cluster) (api.Cluster, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Cluster{}, err
+	}
+	return a.svc.Create(cluster)
+}
+
+func (a clusterToContext) Modify(ctx context.Context, cluster api.Cluster) (api.Cluster, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Cluster{}, err
+	}
+	return a.svc.Modify(cluster)
+}
+
+func (a clusterToContext) Delete(
+	ctx context.Context,
+	clusterKey api.ClusterKey,
+	checksum api.Checksum,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.svc.Delete(clusterKey, checksum)
+}
+
+func (a clusterToContext) AddInstance(
+	ctx context.Context,
+	clusterKey api.ClusterKey,
+	checksum api.Checksum,
+	instance api.Instance,
+) (api.Cluster, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Cluster{}, err
+	}
+	return a.svc.AddInstance(clusterKey, checksum, instance)
+}
+
+func (a clusterToContext) RemoveInstance(
+	ctx context.Context,
+	clusterKey api.ClusterKey,
+	checksum api.Checksum,
+	instance api.Instance,
+) (api.Cluster, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Cluster{}, err
+	}
+	return a.svc.RemoveInstance(clusterKey, checksum, instance)
+}
+
+// FromDomainContext adapts a DomainContext to Domain. Each call is made with
+// context.Background().
+func FromDomainContext(svc DomainContext) Domain {
+	return domainFromContext{svc}
+}
+
+type domainFromContext struct {
+	svc DomainContext
+}
+
+func (a domainFromContext) Index(filters ...DomainFilter) (api.Domains, error) {
+	return a.svc.Index(context.Background(), filters...)
+}
+
+func (a domainFromContext) Get(domainKey api.DomainKey) (api.Domain, error) {
+	return a.svc.Get(context.Background(), domainKey)
+}
+
+func (a domainFromContext) Create(domain api.Domain) (api.Domain, error) {
+	return a.svc.Create(context.Background(), domain)
+}
+
+func (a domainFromContext) Modify(domain api.Domain) (api.Domain, error) {
+	return a.svc.Modify(context.Background(), domain)
+}
+
+func (a domainFromContext) Delete(domainKey api.DomainKey, checksum api.Checksum) error {
+	return a.svc.Delete(context.Background(), domainKey, checksum)
+}
+
+type domainToContext struct {
+	svc Domain
+}
+
+func (a domainToContext) Index(ctx context.Context, filters ...DomainFilter) (api.Domains, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.svc.Index(filters...)
+}
+
+func (a domainToContext) Get(ctx context.Context, domainKey api.DomainKey) (api.Domain, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Domain{}, err
+	}
+	return a.svc.Get(domainKey)
+}
+
+func (a domainToContext) Create(ctx context.Context, domain api.Domain) (api.Domain, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Domain{}, err
+	}
+	return a.svc.Create(domain)
+}
+
+func (a domainToContext) Modify(ctx context.Context, domain api.Domain) (api.Domain, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Domain{}, err
+	}
+	return a.svc.Modify(domain)
+}
+
+func (a domainToContext) Delete(
+	ctx context.Context,
+	domainKey api.DomainKey,
+	checksum api.Checksum,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.svc.Delete(domainKey, checksum)
+}
+
+// FromProxyContext adapts a ProxyContext to Proxy. Each call is made with
+// context.Background().
+func FromProxyContext(svc ProxyContext) Proxy {
+	return proxyFromContext{svc}
+}
+
+type proxyFromContext struct {
+	svc ProxyContext
+}
+
+func (a proxyFromContext) Index(filters ...ProxyFilter) (api.Proxies, error) {
+	return a.svc.Index(context.Background(), filters...)
+}
+
+func (a proxyFromContext) Get(proxyKey api.ProxyKey) (api.Proxy, error) {
+	return a.svc.Get(context.Background(), proxyKey)
+}
+
+func (a proxyFromContext) Create(proxy api.Proxy) (api.Proxy, error) {
+	return a.svc.Create(context.Background(), proxy)
+}
+
+func (a proxyFromContext) Modify(proxy api.Proxy) (api.Proxy, error) {
+	return a.svc.Modify(context.Background(), proxy)
+}
+
+func (a proxyFromContext) Delete(proxyKey api.ProxyKey, checksum api.Checksum) error {
+	return a.svc.Delete(context.Background(), proxyKey, checksum)
+}
+
+type proxyToContext struct {
+	svc Proxy
+}
+
+func (a proxyToContext) Index(ctx context.Context, filters ...ProxyFilter) (api.Proxies, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.svc.Index(filters...)
+}
+
+func (a proxyToContext) Get(ctx context.Context, proxyKey api.ProxyKey) (api.Proxy, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Proxy{}, err
+	}
+	return a.svc.Get(proxyKey)
+}
+
+func (a proxyToContext) Create(ctx context.Context, proxy api.Proxy) (api.Proxy, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Proxy{}, err
+	}
+	return a.svc.Create(proxy)
+}
+
+func (a proxyToContext) Modify(ctx context.Context, proxy api.Proxy) (api.Proxy, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Proxy{}, err
+	}
+	return a.svc.Modify(proxy)
+}
+
+func (a proxyToContext) Delete(
+	ctx context.Context,
+	proxyKey api.ProxyKey,
+	checksum api.Checksum,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.svc.Delete(proxyKey, checksum)
+}
+
+// FromListenerContext adapts a ListenerContext to Listener. Each call is made with
+// context.Background().
+func FromListenerContext(svc ListenerContext) Listener {
+	return listenerFromContext{svc}
+}
+
+type listenerFromContext struct {
+	svc ListenerContext
+}
+
+func (a listenerFromContext) Index(filters ...ListenerFilter) (api.Listeners, error) {
+	return a.svc.Index(context.Background(), filters...)
+}
+
+func (a listenerFromContext) Get(listenerKey api.ListenerKey) (api.Listener, error) {
+	return a.svc.Get(context.Background(), listenerKey)
+}
+
+func (a listenerFromContext) Create(listener api.Listener) (api.Listener, error) {
+	return a.svc.Create(context.Background(), listener)
+}
+
+func (a listenerFromContext) Modify(listener api.Listener) (api.Listener, error) {
+	return a.svc.Modify(context.Background(), listener)
+}
+
+func (a listenerFromContext) Delete(listenerKey api.ListenerKey, checksum api.Checksum) error {
+	return a.svc.Delete(context.Background(), listenerKey, checksum)
+}
+
+type listenerToContext struct {
+	svc Listener
+}
+
+func (a listenerToContext) Index(
+	ctx context.Context,
+	filters ...ListenerFilter,
+) (api.Listeners, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.svc.Index(filters...)
+}
+
+func (a listenerToContext) Get(
+	ctx context.Context,
+	listenerKey api.ListenerKey,
+) (api.Listener, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Listener{}, err
+	}
+	return a.svc.Get(listenerKey)
+}
+
+func (a listenerToContext) Create(
+	ctx context.Context,
+	listener api.Listener,
+) (api.Listener, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Listener{}, err
+	}
+	return a.svc.Create(listener)
+}
+
+func (a listenerToContext) Modify(
+	ctx context.Context,
+	listener api.Listener,
+) (api.Listener, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Listener{}, err
+	}
+	return a.svc.Modify(listener)
+}
+
+func (a listenerToContext) Delete(
+	ctx context.Context,
+	listenerKey api.ListenerKey,
+	checksum api.Checksum,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.svc.Delete(listenerKey, checksum)
+}
+
+// FromSharedRulesContext adapts a SharedRulesContext to SharedRules. Each call is made with
+// context.Background().
+func FromSharedRulesContext(svc SharedRulesContext) SharedRules {
+	return sharedRulesFromContext{svc}
+}
+
+type sharedRulesFromContext struct {
+	svc SharedRulesContext
+}
+
+func (a sharedRulesFromContext) Index(filters ...SharedRulesFilter) (api.SharedRulesSlice, error) {
+	return a.svc.Index(context.Background(), filters...)
+}
+
+func (a sharedRulesFromContext) Get(sharedRulesKey api.SharedRulesKey) (api.SharedRules, error) {
+	return a.svc.Get(context.Background(), sharedRulesKey)
+}
+
+func (a sharedRulesFromContext) Create(sharedRules api.SharedRules) (api.SharedRules, error) {
+	return a.svc.Create(context.Background(), sharedRules)
+}
+
+func (a sharedRulesFromContext) Modify(sharedRules api.SharedRules) (api.SharedRules, error) {
+	return a.svc.Modify(context.Background(), sharedRules)
+}
+
+func (a sharedRulesFromContext) Delete(
+	sharedRulesKey api.SharedRulesKey,
+	checksum api.Checksum,
+) error {
+	return a.svc.Delete(context.Background(), sharedRulesKey, checksum)
+}
+
+type sharedRulesToContext struct {
+	svc SharedRules
+}
+
+func (a sharedRulesToContext) Index(
+	ctx context.Context,
+	filters ...SharedRulesFilter,
+) (api.SharedRulesSlice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.svc.Index(filters...)
+}
+
+func (a sharedRulesToContext) Get(
+	ctx context.Context,
+	sharedRulesKey api.SharedRulesKey,
+) (api.SharedRules, error) {
+	if err := ctx.Err(); err != nil {
+		return api.SharedRules{}, err
+	}
+	return a.svc.Get(sharedRulesKey)
+}
+
+func (a sharedRulesToContext) Create(
+	ctx context.Context,
+	sharedRules api.SharedRules,
+) (api.SharedRules, error) {
+	if err := ctx.Err(); err != nil {
+		return api.SharedRules{}, err
+	}
+	return a.svc.Create(sharedRules)
+}
+
+func (a sharedRulesToContext) Modify(
+	ctx context.Context,
+	sharedRules api.SharedRules,
+) (api.SharedRules, error) {
+	if err := ctx.Err(); err != nil {
+		return api.SharedRules{}, err
+	}
+	return a.svc.Modify(sharedRules)
+}
+
+func (a sharedRulesToContext) Delete(
+	ctx context.Context,
+	sharedRulesKey api.SharedRulesKey,
+	checksum api.Checksum,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.svc.Delete(sharedRulesKey, checksum)
+}
+
+// FromRouteContext adapts a RouteContext to Route. Each call is made with
+// context.Background().
+func FromRouteContext(svc RouteContext) Route {
+	return routeFromContext{svc}
+}
+
+type routeFromContext struct {
+	svc RouteContext
+}
+
+func (a routeFromContext) Index(filters ...RouteFilter) (api.Routes, error) {
+	return a.svc.Index(context.Background(), filters...)
+}
+
+func (a routeFromContext) Get(routeKey api.RouteKey) (api.Route, error) {
+	return a.svc.Get(context.Background(), routeKey)
+}
+
+func (a routeFromContext) Create(route api.Route) (api.Route, error) {
+	return a.svc.Create(context.Background(), route)
+}
+
+func (a routeFromContext) Modify(route api.Route) (api.Route, error) {
+	return a.svc.Modify(context.Background(), route)
+}
+
+func (a routeFromContext) Delete(routeKey api.RouteKey, checksum api.Checksum) error {
+	return a.svc.Delete(context.Background(), routeKey, checksum)
+}
+
+type routeToContext struct {
+	svc Route
+}
+
+func (a routeToContext) Index(ctx context.Context, filters ...RouteFilter) (api.Routes, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.svc.Index(filters...)
+}
+
+func (a routeToContext) Get(ctx context.Context, routeKey api.RouteKey) (api.Route, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Route{}, err
+	}
+	return a.svc.Get(routeKey)
+}
+
+func (a routeToContext) Create(ctx context.Context, route api.Route) (api.Route, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Route{}, err
+	}
+	return a.svc.Create(route)
+}
+
+func (a routeToContext) Modify(ctx context.Context, route api.Route) (api.Route, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Route{}, err
+	}
+	return a.svc.Modify(route)
+}
+
+func (a routeToContext) Delete(
+	ctx context.Context,
+	routeKey api.RouteKey,
+	checksum api.Checksum,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.svc.Delete(routeKey, checksum)
+}
+
+// FromZoneContext adapts a ZoneContext to Zone. Each call is made with
+// context.Background().
+func FromZoneContext(svc ZoneContext) Zone {
+	return zoneFromContext{svc}
+}
+
+type zoneFromContext struct {
+	svc ZoneContext
+}
+
+func (a zoneFromContext) Index(filters ...ZoneFilter) (api.Zones, error) {
+	return a.svc.Index(context.Background(), filters...)
+}
+
+func (a zoneFromContext) Get(zoneKey api.ZoneKey) (api.Zone, error) {
+	return a.svc.Get(context.Background(), zoneKey)
+}
+
+func (a zoneFromContext) Create(zone api.Zone) (api.Zone, error) {
+	return a.svc.Create(context.Background(), zone)
+}
+
+func (a zoneFromContext) Modify(zone api.Zone) (api.Zone, error) {
+	return a.svc.Modify(context.Background(), zone)
+}
+
+func (a zoneFromContext) Delete(zoneKey api.ZoneKey, checksum api.Checksum) error {
+	return a.svc.Delete(context.Background(), zoneKey, checksum)
+}
+
+type zoneToContext struct {
+	svc Zone
+}
+
+func (a zoneToContext) Index(ctx context.Context, filters ...ZoneFilter) (api.Zones, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.svc.Index(filters...)
+}
+
+func (a zoneToContext) Get(ctx context.Context, zoneKey api.ZoneKey) (api.Zone, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Zone{}, err
+	}
+	return a.svc.Get(zoneKey)
+}
+
+func (a zoneToContext) Create(ctx context.Context, zone api.Zone) (api.Zone, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Zone{}, err
+	}
+	return a.svc.Create(zone)
+}
+
+func (a zoneToContext) Modify(ctx context.Context, zone api.Zone) (api.Zone, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Zone{}, err
+	}
+	return a.svc.Modify(zone)
+}
+
+func (a zoneToContext) Delete(
+	ctx context.Context,
+	zoneKey api.ZoneKey,
+	checksum api.Checksum,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.svc.Delete(zoneKey, checksum)
+}
+
+// FromUserContext adapts a UserContext to User. Each call is made with
+// context.Background().
+func FromUserContext(svc UserContext) User {
+	return userFromContext{svc}
+}
+
+type userFromContext struct {
+	svc UserContext
+}
+
+func (a userFromContext) Index(filters ...UserFilter) (api.Users, error) {
+	return a.svc.Index(context.Background(), filters...)
+}
+
+func (a userFromContext) Get(userKey api.UserKey) (api.User, error) {
+	return a.svc.Get(context.Background(), userKey)
+}
+
+func (a userFromContext) Create(user api.User) (api.User, error) {
+	return a.svc.Create(context.Background(), user)
+}
+
+func (a userFromContext) Modify(user api.User) (api.User, error) {
+	return a.svc.Modify(context.Background(), user)
+}
+
+func (a userFromContext) Delete(userKey api.UserKey, checksum api.Checksum) error {
+	return a.svc.Delete(context.Background(), userKey, checksum)
+}
+
+type userToContext struct {
+	svc User
+}
+
+func (a userToContext) Index(ctx context.Context, filters ...UserFilter) (api.Users, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.svc.Index(filters...)
+}
+
+func (a userToContext) Get(ctx context.Context, userKey api.UserKey) (api.User, error) {
+	if err := ctx.Err(); err != nil {
+		return api.User{}, err
+	}
+	return a.svc.Get(userKey)
+}
+
+func (a userToContext) Create(ctx context.Context, user api.User) (api.User, error) {
+	if err := ctx.Err(); err != nil {
+		return api.User{}, err
+	}
+	return a.svc.Create(user)
+}
+
+func (a userToContext) Modify(ctx context.Context, user api.User) (api.User, error) {
+	if err := ctx.Err(); err != nil {
+		return api.User{}, err
+	}
+	return a.svc.Modify(user)
+}
+
+func (a userToContext) Delete(
+	ctx context.Context,
+	userKey api.UserKey,
+	checksum api.Checksum,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.svc.Delete(userKey, checksum)
+}
+
+// FromOrgContext adapts an OrgContext to Org. Each call is made with
+// context.Background().
+func FromOrgContext(svc OrgContext) Org {
+	return orgFromContext{svc}
+}
+
+type orgFromContext struct {
+	svc OrgContext
+}
+
+func (a orgFromContext) Index(filters ...OrgFilter) (api.Orgs, error) {
+	return a.svc.Index(context.Background(), filters...)
+}
+
+func (a orgFromContext) Get(orgKey api.OrgKey) (api.Org, error) {
+	return a.svc.Get(context.Background(), orgKey)
+}
+
+func (a orgFromContext) Create(org api.Org) (api.Org, error) {
+	return a.svc.Create(context.Background(), org)
+}
+
+func (a orgFromContext) Modify(org api.Org) (api.Org, error) {
+	return a.svc.Modify(context.Background(), org)
+}
+
+func (a orgFromContext) Delete(orgKey api.OrgKey, checksum api.Checksum) error {
+	return a.svc.Delete(context.Background(), orgKey, checksum)
+}
+
+type orgToContext struct {
+	svc Org
+}
+
+func (a orgToContext) Index(ctx context.Context, filters ...OrgFilter) (api.Orgs, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.svc.Index(filters...)
+}
+
+func (a orgToContext) Get(ctx context.Context, orgKey api.OrgKey) (api.Org, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Org{}, err
+	}
+	return a.svc.Get(orgKey)
+}
+
+func (a orgToContext) Create(ctx context.Context, org api.Org) (api.Org, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Org{}, err
+	}
+	return a.svc.Create(org)
+}
+
+func (a orgToContext) Modify(ctx context.Context, org api.Org) (api.Org, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Org{}, err
+	}
+	return a.svc.Modify(org)
+}
+
+func (a orgToContext) Delete(
+	ctx context.Context,
+	orgKey api.OrgKey,
+	checksum api.Checksum,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.svc.Delete(orgKey, checksum)
+}
+
+// FromAccessTokenContext adapts a AccessTokenContext to AccessToken. Each call is made with
+// context.Background().
+func FromAccessTokenContext(svc AccessTokenContext) AccessToken {
+	return accessTokenFromContext{svc}
+}
+
+type accessTokenFromContext struct {
+	svc AccessTokenContext
+}
+
+func (a accessTokenFromContext) Index(filters ...AccessTokenFilter) (api.AccessTokens, error) {
+	return a.svc.Index(context.Background(), filters...)
+}
+
+func (a accessTokenFromContext) Get(key api.AccessTokenKey) (api.AccessToken, error) {
+	return a.svc.Get(context.Background(), key)
+}
+
+func (a accessTokenFromContext) Create(token api.AccessToken) (api.AccessToken, error) {
+	return a.svc.Create(context.Background(), token)
+}
+
+func (a accessTokenFromContext) Delete(key api.AccessTokenKey, checksum api.Checksum) error {
+	return a.svc.Delete(context.Background(), key, checksum)
+}
+
+type accessTokenToContext struct {
+	svc AccessToken
+}
+
+func (a accessTokenToContext) Index(
+	ctx context.Context,
+	filters ...AccessTokenFilter,
+) (api.AccessTokens, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.svc.Index(filters...)
+}
+
+func (a accessTokenToContext) Get(
+	ctx context.Context,
+	key api.AccessTokenKey,
+) (api.AccessToken, error) {
+	if err := ctx.Err(); err != nil {
+		return api.AccessToken{}, err
+	}
+	return a.svc.Get(key)
+}
+
+func (a accessTokenToContext) Create(
+	ctx context.Context,
+	token api.AccessToken,
+) (api.AccessToken, error) {
+	if err := ctx.Err(); err != nil {
+		return api.AccessToken{}, err
+	}
+	return a.svc.Create(token)
+}
+
+func (a accessTokenToContext) Delete(
+	ctx context.Context,
+	key api.AccessTokenKey,
+	checksum api.Checksum,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.svc.Delete(key, checksum)
+}
+
+// FromHistoryContext adapts a HistoryContext to History. Each call is made
+// with context.Background().
+func FromHistoryContext(svc HistoryContext) History {
+	return historyFromContext{svc}
+}
+
+type historyFromContext struct {
+	svc HistoryContext
+}
+
+func (a historyFromContext) Index(
+	filters changelog.FilterExpr,
+	start,
+	end time.Time,
+) ([]api.ChangeDescription, error) {
+	return a.svc.Index(context.Background(), filters, start, end)
+}
+
+func (a historyFromContext) DomainGraph(
+	domainKey api.DomainKey,
+	start,
+	stop time.Time,
+) ([]api.ChangeDescription, error) {
+	return a.svc.DomainGraph(context.Background(), domainKey, start, stop)
+}
+
+func (a historyFromContext) RouteGraph(
+	routeKey api.RouteKey,
+	start,
+	stop time.Time,
+) ([]api.ChangeDescription, error) {
+	return a.svc.RouteGraph(context.Background(), routeKey, start, stop)
+}
+
+func (a historyFromContext) SharedRulesGraph(
+	sharedRulesKey api.SharedRulesKey,
+	start,
+	stop time.Time,
+) ([]api.ChangeDescription, error) {
+	return a.svc.SharedRulesGraph(context.Background(), sharedRulesKey, start, stop)
+}
+
+func (a historyFromContext) ClusterGraph(
+	clusterKey api.ClusterKey,
+	start,
+	stop time.Time,
+) ([]api.ChangeDescription, error) {
+	return a.svc.ClusterGraph(context.Background(), clusterKey, start, stop)
+}
+
+func (a historyFromContext) Zone(
+	zoneKey api.ZoneKey,
+	start,
+	stop time.Time,
+) ([]api.ChangeDescription, error) {
+	return a.svc.Zone(context.Background(), zoneKey, start, stop)
+}
+
+type historyToContext struct {
+	svc History
+}
+
+func (a historyToContext) Index(
+	ctx context.Context,
+	filters changelog.FilterExpr,
+	start,
+	end time.Time,
+) ([]api.ChangeDescription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.svc.Index(filters, start, end)
+}
+
+func (a historyToContext) DomainGraph(
+	ctx context.Context,
+	domainKey api.DomainKey,
+	start,
+	stop time.Time,
+) ([]api.ChangeDescription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.svc.DomainGraph(domainKey, start, stop)
+}
+
+func (a historyToContext) RouteGraph(
+	ctx context.Context,
+	routeKey api.RouteKey,
+	start,
+	stop time.Time,
+) ([]api.ChangeDescription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.svc.RouteGraph(routeKey, start, stop)
+}
+
+func (a historyToContext) SharedRulesGraph(
+	ctx context.Context,
+	sharedRulesKey api.SharedRulesKey,
+	start,
+	stop time.Time,
+) ([]api.ChangeDescription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.svc.SharedRulesGraph(sharedRulesKey, start, stop)
+}
+
+func (a historyToContext) ClusterGraph(
+	ctx context.Context,
+	clusterKey api.ClusterKey,
+	start,
+	stop time.Time,
+) ([]api.ChangeDescription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.svc.ClusterGraph(clusterKey, start, stop)
+}
+
+func (a historyToContext) Zone(
+	ctx context.Context,
+	zoneKey api.ZoneKey,
+	start,
+	stop time.Time,
+) ([]api.ChangeDescription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.svc.Zone(zoneKey, start, stop)
+}
+
+// FromBatchContext adapts a BatchContext to Batch. Each call is made with
+// context.Background().
+func FromBatchContext(svc BatchContext) Batch {
+	return batchFromContext{svc}
+}
+
+type batchFromContext struct {
+	svc BatchContext
+}
+
+func (a batchFromContext) Apply(ops []BatchOperation) ([]BatchResult, error) {
+	return a.svc.Apply(context.Background(), ops)
+}
+
+type batchToContext struct {
+	svc Batch
+}
+
+func (a batchToContext) Apply(ctx context.Context, ops []BatchOperation) ([]BatchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.svc.Apply(ops)
+}
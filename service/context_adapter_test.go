@@ -0,0 +1,123 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/test/assert"
+)
+
+func TestFromAllContext(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	filter := ClusterFilter{Name: "c"}
+	clusters := api.Clusters{{ClusterKey: "ck"}}
+	instance := api.Instance{Host: "h", Port: 1}
+	checksum := api.Checksum{Checksum: "cs"}
+
+	mockCluster := NewMockClusterContext(ctrl)
+	mockCluster.EXPECT().Index(context.Background(), filter).Return(clusters, nil)
+	mockCluster.EXPECT().
+		AddInstance(context.Background(), api.ClusterKey("ck"), checksum, instance).
+		Return(clusters[0], nil)
+	mockCluster.EXPECT().
+		Delete(context.Background(), api.ClusterKey("ck"), checksum).
+		Return(errors.New("boom"))
+
+	mockAll := NewMockAllContext(ctrl)
+	mockAll.EXPECT().Cluster().Return(mockCluster).Times(3)
+
+	svc := FromAllContext(mockAll)
+
+	got, err := svc.Cluster().Index(filter)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, clusters)
+
+	cluster, err := svc.Cluster().AddInstance("ck", checksum, instance)
+	assert.Nil(t, err)
+	assert.Equal(t, cluster.ClusterKey, api.ClusterKey("ck"))
+
+	assert.ErrorContains(t, svc.Cluster().Delete("ck", checksum), "boom")
+}
+
+func TestFromHistoryContext(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	start := time.Unix(1000, 0)
+	stop := time.Unix(2000, 0)
+	changes := []api.ChangeDescription{{}}
+
+	mockHistory := NewMockHistoryContext(ctrl)
+	mockHistory.EXPECT().
+		Zone(context.Background(), api.ZoneKey("zk"), start, stop).
+		Return(changes, nil)
+
+	got, err := FromHistoryContext(mockHistory).Zone("zk", start, stop)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, changes)
+}
+
+func TestToAllContext(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	domain := api.Domain{DomainKey: "dk"}
+
+	mockDomain := NewMockDomain(ctrl)
+	mockDomain.EXPECT().Get(api.DomainKey("dk")).Return(domain, nil)
+
+	mockAll := NewMockAll(ctrl)
+	mockAll.EXPECT().Domain().Return(mockDomain).Times(2)
+
+	svc := ToAllContext(mockAll)
+
+	got, err := svc.Domain().Get(context.Background(), "dk")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, domain)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	got, err = svc.Domain().Get(ctx, "dk")
+	assert.Equal(t, err, context.Canceled)
+	assert.DeepEqual(t, got, api.Domain{})
+}
+
+func TestToAdminContext(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	token := api.AccessToken{AccessTokenKey: "atk"}
+
+	mockToken := NewMockAccessToken(ctrl)
+	mockToken.EXPECT().Create(token).Return(token, nil)
+
+	mockAdmin := NewMockAdmin(ctrl)
+	mockAdmin.EXPECT().AccessToken().Return(mockToken)
+
+	got, err := ToAdminContext(mockAdmin).AccessToken().Create(context.Background(), token)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, token)
+}
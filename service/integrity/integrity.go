@@ -0,0 +1,353 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package integrity checks the references between the objects of a Zone.
+// Where each object's IsValid method checks only that object's fields,
+// Check and CheckZone examine the Zone's object graph as a whole, reporting
+// references to objects that do not exist or that belong to another Zone,
+// and Domains served by no Proxy. Problems are reported as an
+// api.ValidationError whose ErrorCase attributes identify the offending
+// field, e.g.:
+//
+// 	route[route-1].shared_rules_key: shared rules "sr-1" is in zone "zone-2"
+package integrity
+
+import (
+	"fmt"
+
+	"github.com/turbinelabs/api"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/service"
+)
+
+// Objects is the set of objects of a Zone. It may additionally contain
+// objects belonging to other Zones: these are not themselves checked, but
+// allow references to them to be reported as cross-zone rather than
+// dangling references.
+type Objects struct {
+	Zone        api.Zone
+	Clusters    api.Clusters
+	Domains     api.Domains
+	Listeners   api.Listeners
+	Proxies     api.Proxies
+	SharedRules api.SharedRulesSlice
+	Routes      api.Routes
+}
+
+// CheckZone reads the objects of the Zone with the given key from svc and
+// checks them as described by Check. Referenced objects not found in the
+// Zone are looked up by key, so that references to other Zones are
+// reported as such. A non-nil error indicates that svc could not be read.
+func CheckZone(svc service.All, zk api.ZoneKey) (*api.ValidationError, error) {
+	o, err := readZone(svc, zk)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := readForeign(svc, o); err != nil {
+		return nil, err
+	}
+
+	return Check(*o), nil
+}
+
+// Check returns a ValidationError describing the following problems with the
+// objects in o that belong to o.Zone, or nil if there are none:
+//
+//   - references to Domains, Listeners, Clusters, or SharedRules that do not
+//     exist in o,
+//   - references to objects in other Zones, and
+//   - Domains that are not served by any Proxy.
+func Check(o Objects) *api.ValidationError {
+	c := newChecker(o)
+
+	for _, l := range o.Listeners {
+		if l.ZoneKey != c.zone {
+			continue
+		}
+		scope := fmt.Sprintf("listener[%s]", l.ListenerKey)
+		for _, dk := range l.DomainKeys {
+			c.checkDomain(fmt.Sprintf("%s.domain_keys[%s]", scope, dk), dk)
+		}
+	}
+
+	served := map[api.DomainKey]bool{}
+	for _, p := range o.Proxies {
+		if p.ZoneKey != c.zone {
+			continue
+		}
+		scope := fmt.Sprintf("proxy[%s]", p.ProxyKey)
+		for _, dk := range p.DomainKeys {
+			c.checkDomain(fmt.Sprintf("%s.domain_keys[%s]", scope, dk), dk)
+			served[dk] = true
+		}
+		for _, lk := range p.ListenerKeys {
+			c.checkRef(
+				fmt.Sprintf("%s.listener_keys[%s]", scope, lk),
+				"listener",
+				string(lk),
+				c.listeners[lk],
+			)
+		}
+	}
+
+	for _, d := range o.Domains {
+		if d.ZoneKey == c.zone && !served[d.DomainKey] {
+			c.add(fmt.Sprintf("domain[%s]", d.DomainKey), "is not served by any proxy")
+		}
+	}
+
+	for _, sr := range o.SharedRules {
+		if sr.ZoneKey != c.zone {
+			continue
+		}
+		scope := fmt.Sprintf("shared_rules[%s]", sr.SharedRulesKey)
+		c.checkConstraints(scope+".default", sr.Default)
+		c.checkRules(scope, sr.Rules)
+	}
+
+	for _, r := range o.Routes {
+		if r.ZoneKey != c.zone {
+			continue
+		}
+		scope := fmt.Sprintf("route[%s]", r.RouteKey)
+		c.checkDomain(scope+".domain_key", r.DomainKey)
+		c.checkRef(
+			scope+".shared_rules_key",
+			"shared rules",
+			string(r.SharedRulesKey),
+			c.sharedRules[r.SharedRulesKey],
+		)
+		c.checkRules(scope, r.Rules)
+	}
+
+	return c.errs.OrNil()
+}
+
+// checker records the Zone of each object in an Objects, by key, and
+// accumulates errors.
+type checker struct {
+	zone api.ZoneKey
+	errs *api.ValidationError
+
+	clusters    map[api.ClusterKey]api.ZoneKey
+	domains     map[api.DomainKey]api.ZoneKey
+	listeners   map[api.ListenerKey]api.ZoneKey
+	sharedRules map[api.SharedRulesKey]api.ZoneKey
+}
+
+func newChecker(o Objects) *checker {
+	c := &checker{
+		zone:        o.Zone.ZoneKey,
+		errs:        &api.ValidationError{},
+		clusters:    map[api.ClusterKey]api.ZoneKey{},
+		domains:     map[api.DomainKey]api.ZoneKey{},
+		listeners:   map[api.ListenerKey]api.ZoneKey{},
+		sharedRules: map[api.SharedRulesKey]api.ZoneKey{},
+	}
+
+	for _, cl := range o.Clusters {
+		c.clusters[cl.ClusterKey] = cl.ZoneKey
+	}
+	for _, d := range o.Domains {
+		c.domains[d.DomainKey] = d.ZoneKey
+	}
+	for _, l := range o.Listeners {
+		c.listeners[l.ListenerKey] = l.ZoneKey
+	}
+	for _, sr := range o.SharedRules {
+		c.sharedRules[sr.SharedRulesKey] = sr.ZoneKey
+	}
+
+	return c
+}
+
+func (c *checker) add(attr, msg string) {
+	c.errs.AddNew(api.ErrorCase{attr, msg})
+}
+
+// checkRef checks a reference to an object of the given kind and key, whose
+// Zone is refZone, or empty if the object is unknown.
+func (c *checker) checkRef(attr, kind, key string, refZone api.ZoneKey) {
+	switch refZone {
+	case c.zone:
+	case "":
+		c.add(attr, fmt.Sprintf("%s %q does not exist", kind, key))
+	default:
+		c.add(attr, fmt.Sprintf("%s %q is in zone %q", kind, key, refZone))
+	}
+}
+
+func (c *checker) checkDomain(attr string, dk api.DomainKey) {
+	c.checkRef(attr, "domain", string(dk), c.domains[dk])
+}
+
+func (c *checker) checkConstraints(scope string, ac api.AllConstraints) {
+	for _, cs := range []struct {
+		name string
+		ccs  api.ClusterConstraints
+	}{
+		{"light", ac.Light},
+		{"dark", ac.Dark},
+		{"tap", ac.Tap},
+	} {
+		for _, cc := range cs.ccs {
+			c.checkRef(
+				fmt.Sprintf("%s.%s[%s].cluster_key", scope, cs.name, cc.ConstraintKey),
+				"cluster",
+				string(cc.ClusterKey),
+				c.clusters[cc.ClusterKey],
+			)
+		}
+	}
+}
+
+func (c *checker) checkRules(scope string, rules api.Rules) {
+	for _, r := range rules {
+		c.checkConstraints(fmt.Sprintf("%s.rules[%s].constraints", scope, r.RuleKey), r.Constraints)
+	}
+}
+
+func readZone(svc service.All, zk api.ZoneKey) (*Objects, error) {
+	zone, err := svc.Zone().Get(zk)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Objects{Zone: zone}
+
+	if o.Clusters, err = svc.Cluster().Index(service.ClusterFilter{ZoneKey: zk}); err != nil {
+		return nil, err
+	}
+	if o.Domains, err = svc.Domain().Index(service.DomainFilter{ZoneKey: zk}); err != nil {
+		return nil, err
+	}
+	if o.Listeners, err = svc.Listener().Index(service.ListenerFilter{ZoneKey: zk}); err != nil {
+		return nil, err
+	}
+	if o.Proxies, err = svc.Proxy().Index(service.ProxyFilter{ZoneKey: zk}); err != nil {
+		return nil, err
+	}
+	o.SharedRules, err = svc.SharedRules().Index(service.SharedRulesFilter{ZoneKey: zk})
+	if err != nil {
+		return nil, err
+	}
+	if o.Routes, err = svc.Route().Index(service.RouteFilter{ZoneKey: zk}); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// isNotFound returns true if err indicates that an object does not exist.
+func isNotFound(err error) bool {
+	if herr, ok := err.(*httperr.Error); ok {
+		return herr.Code == httperr.NotFoundErrorCode || herr.Status == 404
+	}
+	return false
+}
+
+// readForeign looks up each object referred to by o's objects but not
+// present in o, adding those that exist (necessarily in another Zone) to o.
+func readForeign(svc service.All, o *Objects) error {
+	c := newChecker(*o)
+
+	clusters := map[api.ClusterKey]bool{}
+	addConstraints := func(ac api.AllConstraints) {
+		for _, ccs := range []api.ClusterConstraints{ac.Light, ac.Dark, ac.Tap} {
+			for _, cc := range ccs {
+				if cc.ClusterKey != "" && c.clusters[cc.ClusterKey] == "" {
+					clusters[cc.ClusterKey] = true
+				}
+			}
+		}
+	}
+	addRules := func(rules api.Rules) {
+		for _, r := range rules {
+			addConstraints(r.Constraints)
+		}
+	}
+
+	domains := map[api.DomainKey]bool{}
+	addDomains := func(dks []api.DomainKey) {
+		for _, dk := range dks {
+			if dk != "" && c.domains[dk] == "" {
+				domains[dk] = true
+			}
+		}
+	}
+
+	listeners := map[api.ListenerKey]bool{}
+	sharedRules := map[api.SharedRulesKey]bool{}
+
+	for _, l := range o.Listeners {
+		addDomains(l.DomainKeys)
+	}
+	for _, p := range o.Proxies {
+		addDomains(p.DomainKeys)
+		for _, lk := range p.ListenerKeys {
+			if lk != "" && c.listeners[lk] == "" {
+				listeners[lk] = true
+			}
+		}
+	}
+	for _, sr := range o.SharedRules {
+		addConstraints(sr.Default)
+		addRules(sr.Rules)
+	}
+	for _, r := range o.Routes {
+		addDomains([]api.DomainKey{r.DomainKey})
+		if r.SharedRulesKey != "" && c.sharedRules[r.SharedRulesKey] == "" {
+			sharedRules[r.SharedRulesKey] = true
+		}
+		addRules(r.Rules)
+	}
+
+	for ck := range clusters {
+		cl, err := svc.Cluster().Get(ck)
+		if err == nil {
+			o.Clusters = append(o.Clusters, cl)
+		} else if !isNotFound(err) {
+			return err
+		}
+	}
+	for dk := range domains {
+		d, err := svc.Domain().Get(dk)
+		if err == nil {
+			o.Domains = append(o.Domains, d)
+		} else if !isNotFound(err) {
+			return err
+		}
+	}
+	for lk := range listeners {
+		l, err := svc.Listener().Get(lk)
+		if err == nil {
+			o.Listeners = append(o.Listeners, l)
+		} else if !isNotFound(err) {
+			return err
+		}
+	}
+	for srk := range sharedRules {
+		sr, err := svc.SharedRules().Get(srk)
+		if err == nil {
+			o.SharedRules = append(o.SharedRules, sr)
+		} else if !isNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
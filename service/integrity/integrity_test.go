@@ -0,0 +1,225 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integrity
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/api/service/memory"
+	"github.com/turbinelabs/test/assert"
+)
+
+func testObjects() Objects {
+	light := func(ck api.ClusterKey) api.AllConstraints {
+		return api.AllConstraints{
+			Light: api.ClusterConstraints{{ConstraintKey: "cc-1", ClusterKey: ck, Weight: 1}},
+		}
+	}
+
+	return Objects{
+		Zone:     api.Zone{ZoneKey: "zk"},
+		Clusters: api.Clusters{{ClusterKey: "ck", ZoneKey: "zk"}},
+		Domains:  api.Domains{{DomainKey: "dk", ZoneKey: "zk"}},
+		Listeners: api.Listeners{
+			{ListenerKey: "lk", ZoneKey: "zk"},
+		},
+		Proxies: api.Proxies{
+			{
+				ProxyKey:     "pk",
+				ZoneKey:      "zk",
+				DomainKeys:   []api.DomainKey{"dk"},
+				ListenerKeys: []api.ListenerKey{"lk"},
+			},
+		},
+		SharedRules: api.SharedRulesSlice{
+			{SharedRulesKey: "srk", ZoneKey: "zk", Default: light("ck")},
+		},
+		Routes: api.Routes{
+			{
+				RouteKey:       "rk",
+				ZoneKey:        "zk",
+				DomainKey:      "dk",
+				SharedRulesKey: "srk",
+				Rules:          api.Rules{{RuleKey: "r-1", Constraints: light("ck")}},
+			},
+		},
+	}
+}
+
+func TestCheckValid(t *testing.T) {
+	assert.Nil(t, Check(testObjects()))
+}
+
+func TestCheckDanglingReferences(t *testing.T) {
+	o := testObjects()
+	o.Proxies[0].ListenerKeys = []api.ListenerKey{"nope"}
+	o.SharedRules[0].Default.Light[0].ClusterKey = "nope"
+	o.Routes[0].DomainKey = "nope"
+	o.Routes[0].Rules[0].Constraints.Light[0].ClusterKey = "nope"
+
+	errs := Check(o)
+	assert.NonNil(t, errs)
+	assert.ArrayEqual(
+		t,
+		errs.Errors,
+		[]api.ErrorCase{
+			{"proxy[pk].listener_keys[nope]", `listener "nope" does not exist`},
+			{"shared_rules[srk].default.light[cc-1].cluster_key", `cluster "nope" does not exist`},
+			{"route[rk].domain_key", `domain "nope" does not exist`},
+			{
+				"route[rk].rules[r-1].constraints.light[cc-1].cluster_key",
+				`cluster "nope" does not exist`,
+			},
+		},
+	)
+}
+
+func TestCheckCrossZoneReferences(t *testing.T) {
+	o := testObjects()
+	o.Clusters = append(o.Clusters, api.Cluster{ClusterKey: "other-ck", ZoneKey: "other-zk"})
+	o.Listeners = append(o.Listeners, api.Listener{ListenerKey: "other-lk", ZoneKey: "other-zk"})
+	o.SharedRules = append(
+		o.SharedRules,
+		api.SharedRules{SharedRulesKey: "other-srk", ZoneKey: "other-zk"},
+	)
+
+	// objects in other zones are not themselves checked
+	o.Routes = append(
+		o.Routes,
+		api.Route{RouteKey: "other-rk", ZoneKey: "other-zk", DomainKey: "nope"},
+	)
+
+	o.Proxies[0].ListenerKeys = append(o.Proxies[0].ListenerKeys, "other-lk")
+	o.SharedRules[0].Default.Dark = api.ClusterConstraints{
+		{ConstraintKey: "cc-2", ClusterKey: "other-ck"},
+	}
+	o.Routes[0].SharedRulesKey = "other-srk"
+
+	errs := Check(o)
+	assert.NonNil(t, errs)
+	assert.ArrayEqual(
+		t,
+		errs.Errors,
+		[]api.ErrorCase{
+			{"proxy[pk].listener_keys[other-lk]", `listener "other-lk" is in zone "other-zk"`},
+			{
+				"shared_rules[srk].default.dark[cc-2].cluster_key",
+				`cluster "other-ck" is in zone "other-zk"`,
+			},
+			{"route[rk].shared_rules_key", `shared rules "other-srk" is in zone "other-zk"`},
+		},
+	)
+}
+
+func TestCheckUnservedDomain(t *testing.T) {
+	o := testObjects()
+	o.Domains = append(o.Domains, api.Domain{DomainKey: "dk-2", ZoneKey: "zk"})
+
+	errs := Check(o)
+	assert.NonNil(t, errs)
+	assert.ArrayEqual(
+		t,
+		errs.Errors,
+		[]api.ErrorCase{{"domain[dk-2]", "is not served by any proxy"}},
+	)
+}
+
+func TestCheckZone(t *testing.T) {
+	svc := memory.NewEmpty("the-org", "the-user")
+
+	zone, err := svc.Zone().Create(api.Zone{Name: "zone"})
+	assert.Nil(t, err)
+	other, err := svc.Zone().Create(api.Zone{Name: "other"})
+	assert.Nil(t, err)
+
+	cluster, err := svc.Cluster().Create(api.Cluster{ZoneKey: zone.ZoneKey, Name: "c"})
+	assert.Nil(t, err)
+	domain, err := svc.Domain().Create(api.Domain{ZoneKey: zone.ZoneKey, Name: "d", Port: 80})
+	assert.Nil(t, err)
+	_, err = svc.Proxy().Create(
+		api.Proxy{ZoneKey: zone.ZoneKey, Name: "p", DomainKeys: []api.DomainKey{domain.DomainKey}},
+	)
+	assert.Nil(t, err)
+
+	otherRules, err := svc.SharedRules().Create(
+		api.SharedRules{
+			ZoneKey: other.ZoneKey,
+			Name:    "sr",
+			Default: api.AllConstraints{
+				Light: api.ClusterConstraints{
+					{ConstraintKey: "cc", ClusterKey: cluster.ClusterKey, Weight: 1},
+				},
+			},
+		},
+	)
+	assert.Nil(t, err)
+
+	route, err := svc.Route().Create(
+		api.Route{
+			ZoneKey:        zone.ZoneKey,
+			DomainKey:      domain.DomainKey,
+			Path:           "/",
+			SharedRulesKey: otherRules.SharedRulesKey,
+		},
+	)
+	assert.Nil(t, err)
+
+	errs, err := CheckZone(svc, zone.ZoneKey)
+	assert.Nil(t, err)
+	assert.NonNil(t, errs)
+	assert.ArrayEqual(
+		t,
+		errs.Errors,
+		[]api.ErrorCase{
+			{
+				"route[" + string(route.RouteKey) + "].shared_rules_key",
+				`shared rules "` + string(otherRules.SharedRulesKey) + `" is in zone "` +
+					string(other.ZoneKey) + `"`,
+			},
+		},
+	)
+
+	// the other zone's shared rules refer to a cluster in the first zone
+	errs, err = CheckZone(svc, other.ZoneKey)
+	assert.Nil(t, err)
+	assert.NonNil(t, errs)
+	assert.Equal(t, len(errs.Errors), 1)
+	assert.Equal(
+		t,
+		errs.Errors[0].Attribute,
+		"shared_rules["+string(otherRules.SharedRulesKey)+"].default.light[cc].cluster_key",
+	)
+}
+
+func TestCheckZoneError(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	svc := service.NewMockAll(ctrl)
+	zoneSvc := service.NewMockZone(ctrl)
+	svc.EXPECT().Zone().Return(zoneSvc)
+	zoneSvc.EXPECT().Get(api.ZoneKey("zk")).Return(api.Zone{}, errors.New("boom"))
+
+	errs, err := CheckZone(svc, "zk")
+	assert.Nil(t, errs)
+	assert.ErrorContains(t, err, "boom")
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"sort"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/nonstdlib/ptr"
+)
+
+const accessTokenType = "access_token"
+
+type memAccessToken struct {
+	*store
+}
+
+func accessTokenFilterMatches(f service.AccessTokenFilter, t api.AccessToken) bool {
+	if !((f.AccessTokenKey == "" || f.AccessTokenKey == t.AccessTokenKey) &&
+		(f.Description == "" || f.Description == t.Description) &&
+		(f.UserKey == "" || f.UserKey == t.UserKey) &&
+		(f.OrgKey == "" || f.OrgKey == t.OrgKey)) {
+		return false
+	}
+
+	if f.CreatedAfter != nil && (t.CreatedAt == nil || !t.CreatedAt.After(*f.CreatedAfter)) {
+		return false
+	}
+
+	if f.CreatedBefore != nil && (t.CreatedAt == nil || !t.CreatedAt.Before(*f.CreatedBefore)) {
+		return false
+	}
+
+	return true
+}
+
+func (mat memAccessToken) Index(
+	filters ...service.AccessTokenFilter,
+) (api.AccessTokens, error) {
+	mat.RLock()
+	defer mat.RUnlock()
+
+	result := api.AccessTokens{}
+	for _, t := range mat.accessTokens {
+		if len(filters) == 0 {
+			result = append(result, cloneAccessToken(t))
+			continue
+		}
+		for _, f := range filters {
+			if accessTokenFilterMatches(f, t) {
+				result = append(result, cloneAccessToken(t))
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].AccessTokenKey < result[j].AccessTokenKey
+	})
+
+	return result, nil
+}
+
+func (mat memAccessToken) Get(key api.AccessTokenKey) (api.AccessToken, error) {
+	if key == "" {
+		return api.AccessToken{}, keyRequired(accessTokenType)
+	}
+
+	mat.RLock()
+	defer mat.RUnlock()
+
+	t, ok := mat.accessTokens[key]
+	if !ok {
+		return api.AccessToken{}, notFound(accessTokenType, string(key))
+	}
+
+	return cloneAccessToken(t), nil
+}
+
+// Create issues a new AccessToken for the Store's actor. Only the
+// Description of the given AccessToken is used. The SignedToken is returned
+// in the result but is not retained.
+func (mat memAccessToken) Create(token api.AccessToken) (api.AccessToken, error) {
+	mat.Lock()
+	defer mat.Unlock()
+
+	t := api.AccessToken{
+		AccessTokenKey: api.AccessTokenKey(newKey()),
+		Description:    token.Description,
+		UserKey:        mat.actor,
+		OrgKey:         mat.orgKey,
+		CreatedAt:      ptr.Time(mat.time.Now()),
+		Checksum:       newChecksum(),
+	}
+	if err := invalid(t.IsValid()); err != nil {
+		return api.AccessToken{}, err
+	}
+
+	mat.accessTokens[t.AccessTokenKey] = t
+	mat.record(change{
+		orgKey: t.OrgKey,
+		after:  t,
+	})
+
+	result := cloneAccessToken(t)
+	result.SignedToken = newKey()
+
+	return result, nil
+}
+
+func (mat memAccessToken) Delete(key api.AccessTokenKey, checksum api.Checksum) error {
+	if key == "" {
+		return keyRequired(accessTokenType)
+	}
+
+	mat.Lock()
+	defer mat.Unlock()
+
+	prev, ok := mat.accessTokens[key]
+	if !ok {
+		return notFound(accessTokenType, string(key))
+	}
+
+	if !prev.Checksum.Equals(checksum) {
+		return checksumMismatch(accessTokenType, string(key))
+	}
+
+	delete(mat.accessTokens, key)
+	mat.record(change{
+		orgKey: prev.OrgKey,
+		before: prev,
+	})
+
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/turbinelabs/api"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/test/assert"
+)
+
+func TestAccessTokenCreate(t *testing.T) {
+	s, df := newTestStore()
+
+	got, err := s.AccessToken().Create(api.AccessToken{
+		AccessTokenKey: "ignored",
+		Description:    "a token",
+		UserKey:        "ignored",
+	})
+	assert.Nil(t, err)
+	assert.NotEqual(t, got.AccessTokenKey, api.AccessTokenKey("ignored"))
+	assert.Equal(t, got.UserKey, df.UserKey1)
+	assert.Equal(t, got.OrgKey, df.ValidOrgID)
+	assert.NonNil(t, got.CreatedAt)
+	assert.NotEqual(t, got.SignedToken, "")
+
+	stored, err := s.AccessToken().Get(got.AccessTokenKey)
+	assert.Nil(t, err)
+	assert.Equal(t, stored.SignedToken, "")
+
+	index, err := s.AccessToken().Index(service.AccessTokenFilter{Description: "a token"})
+	assert.Nil(t, err)
+	assert.Equal(t, len(index), 1)
+}
+
+func TestAccessTokenCreateInvalid(t *testing.T) {
+	s, _ := newTestStore()
+
+	_, err := s.AccessToken().Create(api.AccessToken{})
+	assertErrorCode(t, err, httperr.InvalidObjectErrorCode)
+}
+
+func TestAccessTokenDelete(t *testing.T) {
+	s, df := newTestStore()
+
+	err := s.AccessToken().Delete(df.AccessTokenKey1, df.AccessTokenChecksum2)
+	assertErrorCode(t, err, httperr.UnknownModificationConflict)
+
+	err = s.AccessToken().Delete(df.AccessTokenKey1, df.AccessTokenChecksum1)
+	assert.Nil(t, err)
+
+	_, err = s.AccessToken().Get(df.AccessTokenKey1)
+	assertErrorCode(t, err, httperr.NotFoundErrorCode)
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"fmt"
+
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/service"
+)
+
+type memBatch struct {
+	*store
+}
+
+// Apply applies the operations to a copy of the store while holding the
+// write lock. The copy replaces the store's objects only if every operation
+// succeeds, and its changes are recorded as a single ChangeDescription.
+func (mb memBatch) Apply(ops []service.BatchOperation) ([]service.BatchResult, error) {
+	mb.Lock()
+	defer mb.Unlock()
+
+	tx := mb.fork()
+	results := make([]service.BatchResult, 0, len(ops))
+	for i, op := range ops {
+		result, err := service.ApplyBatchOperation(tx, op)
+		if err != nil {
+			return nil, batchFailed(i, op, err)
+		}
+		results = append(results, result)
+	}
+
+	mb.join(tx)
+	return results, nil
+}
+
+// fork returns a store holding copies of the store's objects and no
+// changes. Callers must hold the lock.
+func (s *store) fork() *store {
+	tx := newStore(s.orgKey, s.actor)
+	tx.time = s.time
+
+	for k, v := range s.zones {
+		tx.zones[k] = v
+	}
+	for k, v := range s.orgs {
+		tx.orgs[k] = v
+	}
+	for k, v := range s.users {
+		tx.users[k] = v
+	}
+	for k, v := range s.accessTokens {
+		tx.accessTokens[k] = v
+	}
+	for k, v := range s.clusters {
+		tx.clusters[k] = v
+	}
+	for k, v := range s.domains {
+		tx.domains[k] = v
+	}
+	for k, v := range s.proxies {
+		tx.proxies[k] = v
+	}
+	for k, v := range s.listeners {
+		tx.listeners[k] = v
+	}
+	for k, v := range s.routes {
+		tx.routes[k] = v
+	}
+	for k, v := range s.sharedRules {
+		tx.sharedRules[k] = v
+	}
+
+	return tx
+}
+
+// join replaces the store's objects with those of a store returned by fork,
+// and records its changes under a single Txn. Callers must hold the write
+// lock.
+func (s *store) join(tx *store) {
+	s.zones = tx.zones
+	s.orgs = tx.orgs
+	s.users = tx.users
+	s.accessTokens = tx.accessTokens
+	s.clusters = tx.clusters
+	s.domains = tx.domains
+	s.proxies = tx.proxies
+	s.listeners = tx.listeners
+	s.routes = tx.routes
+	s.sharedRules = tx.sharedRules
+
+	if len(tx.changes) == 0 {
+		return
+	}
+
+	cd := tx.changes[0]
+	for _, c := range tx.changes[1:] {
+		cd.Diffs = append(cd.Diffs, c.Diffs...)
+	}
+	s.changes = append(s.changes, cd)
+}
+
+// batchFailed describes the failure of the operation at the given index,
+// preserving the status and code of httperr.Errors.
+func batchFailed(i int, op service.BatchOperation, err error) error {
+	msg := fmt.Sprintf("batch operation %d (%s %s) failed", i, op.Op, op.Name)
+
+	if httpErr, ok := err.(*httperr.Error); ok {
+		return &httperr.Error{
+			Message: fmt.Sprintf("%s: %s", msg, httpErr.Message),
+			Code:    httpErr.Code,
+			Status:  httpErr.Status,
+			Details: httpErr.Details,
+		}
+	}
+
+	return httperr.New500(fmt.Sprintf("%s: %v", msg, err), httperr.UnknownUnclassifiedCode)
+}
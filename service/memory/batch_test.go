@@ -0,0 +1,117 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/changetype"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/objecttype"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/test/assert"
+)
+
+func TestBatchApply(t *testing.T) {
+	s, df := newTestStore()
+
+	c := df.Cluster1
+	c.RequireTLS = true
+
+	results, err := s.Batch().Apply([]service.BatchOperation{
+		service.CreateOperation(api.Cluster{ZoneKey: df.ZoneKey1, Name: "new"}),
+		service.ModifyOperation(c),
+		service.DeleteOperation(objecttype.Domain, string(df.DomainKey2), df.Domain2.Checksum),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, len(results), 3)
+
+	created := results[0].Object.(api.Cluster)
+	assert.Equal(t, results[0].ObjectType, objecttype.Cluster)
+	assert.Equal(t, results[0].ObjectKey, string(created.ClusterKey))
+	got, err := s.Cluster().Get(created.ClusterKey)
+	assert.Nil(t, err)
+	assert.Equal(t, got.Name, "new")
+
+	got, err = s.Cluster().Get(df.ClusterKey1)
+	assert.Nil(t, err)
+	assert.True(t, got.RequireTLS)
+	assert.DeepEqual(t, results[1].Object, got)
+
+	assert.DeepEqual(t, results[2], service.BatchResult{
+		ObjectType: objecttype.Domain,
+		ObjectKey:  string(df.DomainKey2),
+	})
+	_, err = s.Domain().Get(df.DomainKey2)
+	assertErrorCode(t, err, httperr.NotFoundErrorCode)
+
+	// additions for the created cluster, a removal and an addition for the
+	// modified attribute, and removals for the deleted domain
+	assert.Equal(t, len(s.changes), 1)
+	byObject := map[string][]changetype.ChangeType{}
+	for _, e := range s.changes[0].Diffs {
+		byObject[e.ObjectKey] = append(byObject[e.ObjectKey], e.ChangeType)
+	}
+	assert.Equal(t, len(byObject), 3)
+	for _, ct := range byObject[string(created.ClusterKey)] {
+		assert.Equal(t, ct, changetype.Addition)
+	}
+	assert.ArrayEqual(
+		t,
+		byObject[string(df.ClusterKey1)],
+		[]changetype.ChangeType{changetype.Removal, changetype.Addition},
+	)
+	for _, ct := range byObject[string(df.DomainKey2)] {
+		assert.Equal(t, ct, changetype.Removal)
+	}
+}
+
+func TestBatchApplyFailure(t *testing.T) {
+	s, df := newTestStore()
+
+	c := df.Cluster1
+	c.RequireTLS = true
+	c.Checksum = api.Checksum{Checksum: "nope"}
+
+	results, err := s.Batch().Apply([]service.BatchOperation{
+		service.CreateOperation(api.Cluster{ZoneKey: df.ZoneKey1, Name: "new"}),
+		service.ModifyOperation(c),
+	})
+	assert.Nil(t, results)
+	assertErrorCode(t, err, httperr.UnknownModificationConflict)
+	assert.ErrorContains(t, err, "batch operation 1 (modify cluster) failed")
+
+	clusters, err := s.Cluster().Index(service.ClusterFilter{Name: "new"})
+	assert.Nil(t, err)
+	assert.Equal(t, len(clusters), 0)
+
+	got, err := s.Cluster().Get(df.ClusterKey1)
+	assert.Nil(t, err)
+	assert.False(t, got.RequireTLS)
+
+	assert.Equal(t, len(s.changes), 0)
+}
+
+func TestBatchApplyEmpty(t *testing.T) {
+	s, _ := newTestStore()
+
+	results, err := s.Batch().Apply(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, len(results), 0)
+	assert.Equal(t, len(s.changes), 0)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/diff"
+)
+
+// change describes a single object mutation to be recorded in the changelog.
+// Before is nil for creations and after is nil for deletions.
+type change struct {
+	orgKey api.OrgKey
+	before interface{}
+	after  interface{}
+}
+
+// record appends a ChangeDescription describing the given change to the
+// changelog. Its entries are computed by the diff package, and so use the
+// same attribute paths as the Turbine Labs API. Changes with no effect are
+// not recorded. Callers must hold the write lock.
+func (s *store) record(c change) {
+	entries := c.entries()
+	if len(entries) == 0 {
+		return
+	}
+
+	cd := api.ChangeDescription{
+		ChangeMeta: api.ChangeMeta{
+			Txn:      newKey(),
+			OrgKey:   c.orgKey,
+			ActorKey: s.actor,
+		},
+		Diffs: entries,
+	}
+	cd.SetAt(s.time.Now())
+
+	s.changes = append(s.changes, cd)
+}
+
+// entries returns the diff of the change's before and after objects.
+func (c change) entries() []api.ChangeEntry {
+	v := c.after
+	if v == nil {
+		v = c.before
+	}
+
+	switch v.(type) {
+	case api.Zone:
+		b, _ := c.before.(api.Zone)
+		a, _ := c.after.(api.Zone)
+		return diff.Zone(b, a)
+
+	case api.Org:
+		b, _ := c.before.(api.Org)
+		a, _ := c.after.(api.Org)
+		return diff.Org(b, a)
+
+	case api.User:
+		b, _ := c.before.(api.User)
+		a, _ := c.after.(api.User)
+		return diff.User(b, a)
+
+	case api.AccessToken:
+		b, _ := c.before.(api.AccessToken)
+		a, _ := c.after.(api.AccessToken)
+		return diff.AccessToken(b, a)
+
+	case api.Cluster:
+		b, _ := c.before.(api.Cluster)
+		a, _ := c.after.(api.Cluster)
+		return diff.Cluster(b, a)
+
+	case api.Domain:
+		b, _ := c.before.(api.Domain)
+		a, _ := c.after.(api.Domain)
+		return diff.Domain(b, a)
+
+	case api.Proxy:
+		b, _ := c.before.(api.Proxy)
+		a, _ := c.after.(api.Proxy)
+		return diff.Proxy(b, a)
+
+	case api.Listener:
+		b, _ := c.before.(api.Listener)
+		a, _ := c.after.(api.Listener)
+		return diff.Listener(b, a)
+
+	case api.Route:
+		b, _ := c.before.(api.Route)
+		a, _ := c.after.(api.Route)
+		return diff.Route(b, a)
+
+	case api.SharedRules:
+		b, _ := c.before.(api.SharedRules)
+		a, _ := c.after.(api.SharedRules)
+		return diff.SharedRules(b, a)
+	}
+
+	return nil
+}
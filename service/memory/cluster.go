@@ -0,0 +1,235 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+)
+
+const clusterType = "cluster"
+
+type memCluster struct {
+	*store
+}
+
+func clusterFilterMatches(f service.ClusterFilter, c api.Cluster) bool {
+	return (f.ClusterKey == "" || f.ClusterKey == c.ClusterKey) &&
+		(f.Name == "" || f.Name == c.Name) &&
+		(f.ZoneKey == "" || f.ZoneKey == c.ZoneKey) &&
+		(f.OrgKey == "" || f.OrgKey == c.OrgKey)
+}
+
+func (mc memCluster) Index(filters ...service.ClusterFilter) (api.Clusters, error) {
+	mc.RLock()
+	defer mc.RUnlock()
+
+	result := api.Clusters{}
+	for _, c := range mc.clusters {
+		if len(filters) == 0 {
+			result = append(result, cloneCluster(c))
+			continue
+		}
+		for _, f := range filters {
+			if clusterFilterMatches(f, c) {
+				result = append(result, cloneCluster(c))
+				break
+			}
+		}
+	}
+	sort.Sort(api.ClusterByClusterKey(result))
+
+	return result, nil
+}
+
+func (mc memCluster) Get(clusterKey api.ClusterKey) (api.Cluster, error) {
+	if clusterKey == "" {
+		return api.Cluster{}, keyRequired(clusterType)
+	}
+
+	mc.RLock()
+	defer mc.RUnlock()
+
+	c, ok := mc.clusters[clusterKey]
+	if !ok {
+		return api.Cluster{}, notFound(clusterType, string(clusterKey))
+	}
+
+	return cloneCluster(c), nil
+}
+
+// checkUnique verifies no other Cluster shares c's Name and ZoneKey. Callers
+// must hold the lock.
+func (mc memCluster) checkUnique(c api.Cluster) error {
+	for _, o := range mc.clusters {
+		if o.ClusterKey != c.ClusterKey && o.ZoneKey == c.ZoneKey && o.Name == c.Name {
+			return duplicate(clusterType, fmt.Sprintf("name %q in zone %q", c.Name, c.ZoneKey))
+		}
+	}
+	return nil
+}
+
+// put validates and stores c with a new Checksum, recording the change from
+// prev. Callers must hold the write lock.
+func (mc memCluster) put(prev *api.Cluster, c api.Cluster) (api.Cluster, error) {
+	c.Checksum = newChecksum()
+	if err := invalid(c.IsValid()); err != nil {
+		return api.Cluster{}, err
+	}
+	if err := mc.checkUnique(c); err != nil {
+		return api.Cluster{}, err
+	}
+
+	mc.clusters[c.ClusterKey] = c
+
+	chg := change{
+		orgKey: c.OrgKey,
+		after:  c,
+	}
+	if prev != nil {
+		chg.before = *prev
+	}
+	mc.record(chg)
+
+	return cloneCluster(c), nil
+}
+
+// lookup returns the existing Cluster for the given key after verifying its
+// Checksum. Callers must hold the lock.
+func (mc memCluster) lookup(
+	clusterKey api.ClusterKey,
+	checksum api.Checksum,
+) (api.Cluster, error) {
+	if clusterKey == "" {
+		return api.Cluster{}, keyRequired(clusterType)
+	}
+
+	prev, ok := mc.clusters[clusterKey]
+	if !ok {
+		return api.Cluster{}, notFound(clusterType, string(clusterKey))
+	}
+
+	if !prev.Checksum.Equals(checksum) {
+		return api.Cluster{}, checksumMismatch(clusterType, string(clusterKey))
+	}
+
+	return prev, nil
+}
+
+func (mc memCluster) Create(cluster api.Cluster) (api.Cluster, error) {
+	mc.Lock()
+	defer mc.Unlock()
+
+	c := cloneCluster(cluster)
+	c.ClusterKey = api.ClusterKey(newKey())
+	c.OrgKey = mc.orgKey
+
+	return mc.put(nil, c)
+}
+
+func (mc memCluster) Modify(cluster api.Cluster) (api.Cluster, error) {
+	mc.Lock()
+	defer mc.Unlock()
+
+	prev, err := mc.lookup(cluster.ClusterKey, cluster.Checksum)
+	if err != nil {
+		return api.Cluster{}, err
+	}
+
+	c := cloneCluster(cluster)
+	c.OrgKey = prev.OrgKey
+
+	return mc.put(&prev, c)
+}
+
+func (mc memCluster) Delete(clusterKey api.ClusterKey, checksum api.Checksum) error {
+	mc.Lock()
+	defer mc.Unlock()
+
+	prev, err := mc.lookup(clusterKey, checksum)
+	if err != nil {
+		return err
+	}
+
+	delete(mc.clusters, clusterKey)
+	mc.record(change{
+		orgKey: prev.OrgKey,
+		before: prev,
+	})
+
+	return nil
+}
+
+func (mc memCluster) AddInstance(
+	clusterKey api.ClusterKey,
+	checksum api.Checksum,
+	instance api.Instance,
+) (api.Cluster, error) {
+	mc.Lock()
+	defer mc.Unlock()
+
+	prev, err := mc.lookup(clusterKey, checksum)
+	if err != nil {
+		return api.Cluster{}, err
+	}
+
+	c := cloneCluster(prev)
+	found := false
+	for i, inst := range c.Instances {
+		if inst.Key() == instance.Key() {
+			c.Instances[i] = instance
+			found = true
+			break
+		}
+	}
+	if !found {
+		c.Instances = append(c.Instances, instance)
+	}
+
+	return mc.put(&prev, c)
+}
+
+func (mc memCluster) RemoveInstance(
+	clusterKey api.ClusterKey,
+	checksum api.Checksum,
+	instance api.Instance,
+) (api.Cluster, error) {
+	mc.Lock()
+	defer mc.Unlock()
+
+	prev, err := mc.lookup(clusterKey, checksum)
+	if err != nil {
+		return api.Cluster{}, err
+	}
+
+	c := cloneCluster(prev)
+	instances := api.Instances{}
+	for _, inst := range c.Instances {
+		if inst.Key() != instance.Key() {
+			instances = append(instances, inst)
+		}
+	}
+	if len(instances) == len(c.Instances) {
+		return api.Cluster{}, notFound("instance", instance.Key())
+	}
+	c.Instances = instances
+
+	return mc.put(&prev, c)
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/changetype"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/objecttype"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/test/assert"
+)
+
+func TestClusterGet(t *testing.T) {
+	s, df := newTestStore()
+
+	got, err := s.Cluster().Get(df.ClusterKey1)
+	assert.Nil(t, err)
+	assert.True(t, got.Equals(df.Cluster1))
+
+	_, err = s.Cluster().Get("nope")
+	assertErrorCode(t, err, httperr.NotFoundErrorCode)
+
+	_, err = s.Cluster().Get("")
+	assertErrorCode(t, err, httperr.ObjectKeyRequiredErrorCode)
+}
+
+func TestClusterIndexFilters(t *testing.T) {
+	s, df := newTestStore()
+
+	got, err := s.Cluster().Index(service.ClusterFilter{Name: df.ClusterName1})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 1)
+	assert.Equal(t, got[0].ClusterKey, df.ClusterKey1)
+
+	got, err = s.Cluster().Index(
+		service.ClusterFilter{Name: df.ClusterName1, ZoneKey: df.ClusterZone2},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 0)
+
+	got, err = s.Cluster().Index(
+		service.ClusterFilter{ClusterKey: df.ClusterKey1},
+		service.ClusterFilter{ZoneKey: df.ClusterZone2},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 2)
+}
+
+func TestClusterCreate(t *testing.T) {
+	s, df := newTestStore()
+
+	c := df.Cluster1
+	c.ClusterKey = "ignored"
+	c.Name = "new-cluster"
+	c.OrgKey = "ignored"
+
+	got, err := s.Cluster().Create(c)
+	assert.Nil(t, err)
+	assert.NotEqual(t, got.ClusterKey, api.ClusterKey("ignored"))
+	assert.NotEqual(t, got.Checksum, df.Cluster1.Checksum)
+	assert.Equal(t, got.OrgKey, df.ValidOrgID)
+	assert.Equal(t, got.Name, "new-cluster")
+
+	stored, err := s.Cluster().Get(got.ClusterKey)
+	assert.Nil(t, err)
+	assert.True(t, stored.Equals(got))
+
+	assert.Equal(t, len(s.changes), 1)
+	cd := s.changes[0]
+	assert.Equal(t, cd.ActorKey, df.UserKey1)
+	assert.Equal(t, cd.OrgKey, df.ValidOrgID)
+	var name []api.ChangeEntry
+	for _, e := range cd.Diffs {
+		if e.Path == "cluster.name" {
+			name = append(name, e)
+		}
+	}
+	assert.ArrayEqual(t, name, []api.ChangeEntry{
+		{
+			ObjectType: objecttype.Cluster,
+			ObjectKey:  string(got.ClusterKey),
+			ZoneKey:    got.ZoneKey,
+			ChangeType: changetype.Addition,
+			Path:       "cluster.name",
+			Value:      "new-cluster",
+		},
+	})
+	for _, e := range cd.Diffs {
+		assert.Equal(t, e.ChangeType, changetype.Addition)
+		assert.Equal(t, e.ObjectKey, string(got.ClusterKey))
+	}
+}
+
+func TestClusterCreateDuplicateName(t *testing.T) {
+	s, df := newTestStore()
+
+	_, err := s.Cluster().Create(df.Cluster1)
+	assertErrorCode(t, err, httperr.DataConstraintErrorCode)
+
+	c := df.Cluster1
+	c.ZoneKey = "other-zone"
+	_, err = s.Cluster().Create(c)
+	assert.Nil(t, err)
+}
+
+func TestClusterCreateInvalid(t *testing.T) {
+	s, _ := newTestStore()
+
+	_, err := s.Cluster().Create(api.Cluster{Name: "no-zone"})
+	assertErrorCode(t, err, httperr.InvalidObjectErrorCode)
+	assert.Equal(t, len(s.changes), 0)
+}
+
+func TestClusterModify(t *testing.T) {
+	s, df := newTestStore()
+
+	c := df.Cluster1
+	c.RequireTLS = true
+	c.OrgKey = ""
+
+	got, err := s.Cluster().Modify(c)
+	assert.Nil(t, err)
+	assert.True(t, got.RequireTLS)
+	assert.Equal(t, got.OrgKey, df.ClusterOrgKey1)
+	assert.NotEqual(t, got.Checksum, df.ClusterChecksum1)
+
+	assert.Equal(t, len(s.changes), 1)
+	assert.Equal(t, len(s.changes[0].Diffs), 2)
+	assert.Equal(t, s.changes[0].Diffs[0].ChangeType, changetype.Removal)
+	assert.Equal(t, s.changes[0].Diffs[0].Path, "cluster.require_tls")
+	assert.Equal(t, s.changes[0].Diffs[1].ChangeType, changetype.Addition)
+	assert.Equal(t, s.changes[0].Diffs[1].Path, "cluster.require_tls")
+
+	// the original checksum is now stale
+	_, err = s.Cluster().Modify(c)
+	assertErrorCode(t, err, httperr.UnknownModificationConflict)
+
+	c.ClusterKey = "nope"
+	_, err = s.Cluster().Modify(c)
+	assertErrorCode(t, err, httperr.NotFoundErrorCode)
+}
+
+func TestClusterModifyDuplicateName(t *testing.T) {
+	s, df := newTestStore()
+
+	c := df.Cluster2
+	c.ZoneKey = df.ClusterZone1
+	c.Name = df.ClusterName1
+
+	_, err := s.Cluster().Modify(c)
+	assertErrorCode(t, err, httperr.DataConstraintErrorCode)
+}
+
+func TestClusterDelete(t *testing.T) {
+	s, df := newTestStore()
+
+	err := s.Cluster().Delete(df.ClusterKey1, api.Checksum{Checksum: "wrong"})
+	assertErrorCode(t, err, httperr.UnknownModificationConflict)
+
+	err = s.Cluster().Delete(df.ClusterKey1, df.ClusterChecksum1)
+	assert.Nil(t, err)
+
+	_, err = s.Cluster().Get(df.ClusterKey1)
+	assertErrorCode(t, err, httperr.NotFoundErrorCode)
+
+	assert.Equal(t, len(s.changes), 1)
+	for _, e := range s.changes[0].Diffs {
+		assert.Equal(t, e.ChangeType, changetype.Removal)
+		assert.Equal(t, e.ObjectKey, string(df.ClusterKey1))
+	}
+}
+
+func TestClusterAddInstance(t *testing.T) {
+	s, df := newTestStore()
+
+	inst := api.Instance{Host: "new-host", Port: 8080}
+	got, err := s.Cluster().AddInstance(df.ClusterKey2, df.ClusterChecksum2, inst)
+	assert.Nil(t, err)
+	assert.Equal(t, len(got.Instances), 3)
+	assert.NotEqual(t, got.Checksum, df.ClusterChecksum2)
+
+	updated := df.Instance22
+	updated.Metadata = api.Metadata{{Key: "k", Value: "v"}}
+	got, err = s.Cluster().AddInstance(df.ClusterKey2, got.Checksum, updated)
+	assert.Nil(t, err)
+	assert.Equal(t, len(got.Instances), 3)
+	assert.True(t, got.Instances[1].Equals(updated))
+
+	_, err = s.Cluster().AddInstance(df.ClusterKey2, df.ClusterChecksum2, inst)
+	assertErrorCode(t, err, httperr.UnknownModificationConflict)
+}
+
+func TestClusterRemoveInstance(t *testing.T) {
+	s, df := newTestStore()
+
+	got, err := s.Cluster().RemoveInstance(df.ClusterKey2, df.ClusterChecksum2, df.Instance21)
+	assert.Nil(t, err)
+	assert.Equal(t, len(got.Instances), 1)
+	assert.True(t, got.Instances[0].Equals(df.Instance22))
+
+	_, err = s.Cluster().RemoveInstance(df.ClusterKey2, got.Checksum, df.Instance21)
+	assertErrorCode(t, err, httperr.NotFoundErrorCode)
+}
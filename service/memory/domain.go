@@ -0,0 +1,216 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+)
+
+const domainType = "domain"
+
+type memDomain struct {
+	*store
+}
+
+// proxyKeysFor returns the set of Proxies that reference the given Domain.
+// Callers must hold the lock.
+func (md memDomain) proxyKeysFor(domainKey api.DomainKey) map[api.ProxyKey]bool {
+	result := map[api.ProxyKey]bool{}
+	for _, p := range md.proxies {
+		for _, dk := range p.DomainKeys {
+			if dk == domainKey {
+				result[p.ProxyKey] = true
+				break
+			}
+		}
+	}
+	return result
+}
+
+// filterMatches determines whether f applies to d. Callers must hold the lock.
+func (md memDomain) filterMatches(f service.DomainFilter, d api.Domain) bool {
+	if !((f.DomainKey == "" || f.DomainKey == d.DomainKey) &&
+		(f.Name == "" || f.Name == d.Name) &&
+		(f.ZoneKey == "" || f.ZoneKey == d.ZoneKey) &&
+		(f.OrgKey == "" || f.OrgKey == d.OrgKey)) {
+		return false
+	}
+
+	if len(f.ProxyKeys) == 0 {
+		return true
+	}
+
+	proxyKeys := md.proxyKeysFor(d.DomainKey)
+	if f.HasNoProxies() {
+		return len(proxyKeys) == 0
+	}
+
+	for _, pk := range f.ProxyKeys {
+		if !proxyKeys[pk] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (md memDomain) Index(filters ...service.DomainFilter) (api.Domains, error) {
+	md.RLock()
+	defer md.RUnlock()
+
+	result := api.Domains{}
+	for _, d := range md.domains {
+		if len(filters) == 0 {
+			result = append(result, cloneDomain(d))
+			continue
+		}
+		for _, f := range filters {
+			if md.filterMatches(f, d) {
+				result = append(result, cloneDomain(d))
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].DomainKey < result[j].DomainKey })
+
+	return result, nil
+}
+
+func (md memDomain) Get(domainKey api.DomainKey) (api.Domain, error) {
+	if domainKey == "" {
+		return api.Domain{}, keyRequired(domainType)
+	}
+
+	md.RLock()
+	defer md.RUnlock()
+
+	d, ok := md.domains[domainKey]
+	if !ok {
+		return api.Domain{}, notFound(domainType, string(domainKey))
+	}
+
+	return cloneDomain(d), nil
+}
+
+// checkUnique verifies no other Domain shares d's (Name, Port, ZoneKey).
+// Callers must hold the lock.
+func (md memDomain) checkUnique(d api.Domain) error {
+	for _, o := range md.domains {
+		if o.DomainKey != d.DomainKey &&
+			o.ZoneKey == d.ZoneKey &&
+			o.Name == d.Name &&
+			o.Port == d.Port {
+			return duplicate(
+				domainType,
+				fmt.Sprintf("name %q and port %d in zone %q", d.Name, d.Port, d.ZoneKey),
+			)
+		}
+	}
+	return nil
+}
+
+// put validates and stores d with a new Checksum, recording the change from
+// prev. Callers must hold the write lock.
+func (md memDomain) put(prev *api.Domain, d api.Domain) (api.Domain, error) {
+	d.Checksum = newChecksum()
+	if err := invalid(d.IsValid()); err != nil {
+		return api.Domain{}, err
+	}
+	if err := md.checkUnique(d); err != nil {
+		return api.Domain{}, err
+	}
+
+	md.domains[d.DomainKey] = d
+
+	chg := change{
+		orgKey: d.OrgKey,
+		after:  d,
+	}
+	if prev != nil {
+		chg.before = *prev
+	}
+	md.record(chg)
+
+	return cloneDomain(d), nil
+}
+
+// lookup returns the existing Domain for the given key after verifying its
+// Checksum. Callers must hold the lock.
+func (md memDomain) lookup(domainKey api.DomainKey, checksum api.Checksum) (api.Domain, error) {
+	if domainKey == "" {
+		return api.Domain{}, keyRequired(domainType)
+	}
+
+	prev, ok := md.domains[domainKey]
+	if !ok {
+		return api.Domain{}, notFound(domainType, string(domainKey))
+	}
+
+	if !prev.Checksum.Equals(checksum) {
+		return api.Domain{}, checksumMismatch(domainType, string(domainKey))
+	}
+
+	return prev, nil
+}
+
+func (md memDomain) Create(domain api.Domain) (api.Domain, error) {
+	md.Lock()
+	defer md.Unlock()
+
+	d := cloneDomain(domain)
+	d.DomainKey = api.DomainKey(newKey())
+	d.OrgKey = md.orgKey
+
+	return md.put(nil, d)
+}
+
+func (md memDomain) Modify(domain api.Domain) (api.Domain, error) {
+	md.Lock()
+	defer md.Unlock()
+
+	prev, err := md.lookup(domain.DomainKey, domain.Checksum)
+	if err != nil {
+		return api.Domain{}, err
+	}
+
+	d := cloneDomain(domain)
+	d.OrgKey = prev.OrgKey
+
+	return md.put(&prev, d)
+}
+
+func (md memDomain) Delete(domainKey api.DomainKey, checksum api.Checksum) error {
+	md.Lock()
+	defer md.Unlock()
+
+	prev, err := md.lookup(domainKey, checksum)
+	if err != nil {
+		return err
+	}
+
+	delete(md.domains, domainKey)
+	md.record(change{
+		orgKey: prev.OrgKey,
+		before: prev,
+	})
+
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/turbinelabs/api"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/test/assert"
+)
+
+func TestDomainIndexProxyKeys(t *testing.T) {
+	s, df := newTestStore()
+
+	got, err := s.Domain().Index(
+		service.DomainFilter{ProxyKeys: []api.ProxyKey{df.ProxyKey1, df.ProxyKey2}},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 2)
+
+	got, err = s.Domain().Index(service.DomainFilter{ProxyKeys: []api.ProxyKey{"nope"}})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 0)
+
+	got, err = s.Domain().Index(service.DomainFilter{ProxyKeys: []api.ProxyKey{service.None}})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 0)
+
+	d := df.Domain1
+	d.Name = "unreferenced"
+	created, err := s.Domain().Create(d)
+	assert.Nil(t, err)
+
+	got, err = s.Domain().Index(service.DomainFilter{ProxyKeys: []api.ProxyKey{service.None}})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 1)
+	assert.Equal(t, got[0].DomainKey, created.DomainKey)
+}
+
+func TestDomainCreateDuplicate(t *testing.T) {
+	s, df := newTestStore()
+
+	_, err := s.Domain().Create(df.Domain1)
+	assertErrorCode(t, err, httperr.DataConstraintErrorCode)
+
+	d := df.Domain1
+	d.Port = d.Port + 1
+	got, err := s.Domain().Create(d)
+	assert.Nil(t, err)
+	assert.Equal(t, got.OrgKey, df.ValidOrgID)
+}
+
+func TestDomainModifyAndDelete(t *testing.T) {
+	s, df := newTestStore()
+
+	d := df.Domain1
+	d.GzipEnabled = false
+	got, err := s.Domain().Modify(d)
+	assert.Nil(t, err)
+	assert.False(t, got.GzipEnabled)
+
+	err = s.Domain().Delete(df.DomainKey1, df.DomainChecksum1)
+	assertErrorCode(t, err, httperr.UnknownModificationConflict)
+
+	err = s.Domain().Delete(df.DomainKey1, got.Checksum)
+	assert.Nil(t, err)
+
+	_, err = s.Domain().Get(df.DomainKey1)
+	assertErrorCode(t, err, httperr.NotFoundErrorCode)
+	assert.Equal(t, len(s.changes), 2)
+}
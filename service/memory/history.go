@@ -0,0 +1,382 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/turbinelabs/api"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/objecttype"
+	"github.com/turbinelabs/api/service/changelog"
+)
+
+const (
+	// defaultIndexWindow is the window size used by Index when neither
+	// start nor end is given.
+	defaultIndexWindow = 3 * time.Hour
+
+	// defaultGraphWindow is the window size used by the graph queries when
+	// either window edge is unset.
+	defaultGraphWindow = time.Hour
+
+	// maxGraphWindow is the largest window the graph queries accept.
+	maxGraphWindow = 24 * time.Hour
+)
+
+type memHistory struct {
+	*store
+}
+
+// window fills in any unset window edges and checks the resulting window.
+// A max of zero indicates no maximum window size.
+func (mh memHistory) window(
+	start,
+	end time.Time,
+	def time.Duration,
+	max time.Duration,
+) (time.Time, time.Time, error) {
+	switch {
+	case start.IsZero() && end.IsZero():
+		end = mh.time.Now()
+		start = end.Add(-def)
+	case start.IsZero():
+		start = end.Add(-def)
+	case end.IsZero():
+		end = start.Add(def)
+	}
+
+	if end.Before(start) {
+		return start, end, httperr.New400(
+			"window end may not precede window start",
+			httperr.BadParameterErrorCode,
+		)
+	}
+
+	if max > 0 && end.Sub(start) > max {
+		return start, end, httperr.New400(
+			fmt.Sprintf("window may not exceed %s", max),
+			httperr.BadParameterErrorCode,
+		)
+	}
+
+	return start, end, nil
+}
+
+type entryPredicate func(api.ChangeMeta, api.ChangeEntry) bool
+
+// selectChanges returns the ChangeDescriptions recorded in [start, end),
+// limited to the ChangeEntries for which pred returns true. Descriptions
+// with no remaining entries are omitted. Callers must hold the lock.
+func (mh memHistory) selectChanges(
+	start,
+	end time.Time,
+	pred entryPredicate,
+) []api.ChangeDescription {
+	result := []api.ChangeDescription{}
+	for _, cd := range mh.changes {
+		if at := cd.At(); at.Before(start) || !at.Before(end) {
+			continue
+		}
+
+		diffs := []api.ChangeEntry{}
+		for _, e := range cd.Diffs {
+			if pred(cd.ChangeMeta, e) {
+				diffs = append(diffs, e)
+			}
+		}
+
+		if len(diffs) > 0 {
+			result = append(result, api.ChangeDescription{ChangeMeta: cd.ChangeMeta, Diffs: diffs})
+		}
+	}
+
+	return result
+}
+
+// entriesInWindow returns all ChangeEntries recorded in [start, end) for the
+// given ObjectType. Callers must hold the lock.
+func (mh memHistory) entriesInWindow(
+	start,
+	end time.Time,
+	ot objecttype.ObjectType,
+) []api.ChangeEntry {
+	entries := []api.ChangeEntry{}
+	mh.selectChanges(start, end, func(_ api.ChangeMeta, e api.ChangeEntry) bool {
+		if e.ObjectType == ot {
+			entries = append(entries, e)
+		}
+		return false
+	})
+	return entries
+}
+
+// isClusterKeyPath returns true if the attribute path refers to the
+// ClusterKey of a ClusterConstraint.
+func isClusterKeyPath(path string) bool {
+	return strings.HasSuffix(path, ".cluster_key")
+}
+
+// clusterKeysForRoute adds to into the Clusters referenced by the given
+// Route, either directly or through its SharedRules, now or by any of the
+// given Route entries. Callers must hold the lock.
+func (mh memHistory) clusterKeysForRoute(
+	routeKey api.RouteKey,
+	routeEntries []api.ChangeEntry,
+	into map[api.ClusterKey]bool,
+) {
+	visit := func(cc api.ClusterConstraint, _ *api.Rule) {
+		into[cc.ClusterKey] = true
+	}
+
+	sharedRulesKeys := map[api.SharedRulesKey]bool{}
+	if r, ok := mh.routes[routeKey]; ok {
+		api.WalkConstraintsFromRules(r.Rules, visit)
+		sharedRulesKeys[r.SharedRulesKey] = true
+	}
+	for _, e := range routeEntries {
+		if e.ObjectKey != string(routeKey) {
+			continue
+		}
+		switch {
+		case isClusterKeyPath(e.Path):
+			into[api.ClusterKey(e.Value)] = true
+		case e.Path == "route.shared_rules_key":
+			sharedRulesKeys[api.SharedRulesKey(e.Value)] = true
+		}
+	}
+
+	for srk := range sharedRulesKeys {
+		if sr, ok := mh.sharedRules[srk]; ok {
+			api.WalkConstraintsFromSharedRules(sr, visit)
+		}
+	}
+}
+
+func sharedRulesReferencesCluster(sr api.SharedRules, clusterKey api.ClusterKey) bool {
+	found := false
+	api.WalkConstraintsFromSharedRules(sr, func(cc api.ClusterConstraint, _ *api.Rule) {
+		found = found || cc.ClusterKey == clusterKey
+	})
+	return found
+}
+
+func routeReferencesCluster(r api.Route, clusterKey api.ClusterKey) bool {
+	found := false
+	api.WalkConstraintsFromRules(r.Rules, func(cc api.ClusterConstraint, _ *api.Rule) {
+		found = found || cc.ClusterKey == clusterKey
+	})
+	return found
+}
+
+func (mh memHistory) Index(
+	filters changelog.FilterExpr,
+	start,
+	end time.Time,
+) ([]api.ChangeDescription, error) {
+	start, end, err := mh.window(start, end, defaultIndexWindow, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	mh.RLock()
+	defer mh.RUnlock()
+
+	return mh.selectChanges(start, end, func(meta api.ChangeMeta, e api.ChangeEntry) bool {
+		return changelog.Matches(filters, meta, e)
+	}), nil
+}
+
+func (mh memHistory) DomainGraph(
+	domainKey api.DomainKey,
+	start,
+	stop time.Time,
+) ([]api.ChangeDescription, error) {
+	start, stop, err := mh.window(start, stop, defaultGraphWindow, maxGraphWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	mh.RLock()
+	defer mh.RUnlock()
+
+	routeKeys := map[api.RouteKey]bool{}
+	for _, r := range mh.routes {
+		if r.DomainKey == domainKey {
+			routeKeys[r.RouteKey] = true
+		}
+	}
+	routeEntries := mh.entriesInWindow(start, stop, objecttype.Route)
+	for _, e := range routeEntries {
+		if e.Path == "route.domain_key" && e.Value == string(domainKey) {
+			routeKeys[api.RouteKey(e.ObjectKey)] = true
+		}
+	}
+
+	clusterKeys := map[api.ClusterKey]bool{}
+	for rk := range routeKeys {
+		mh.clusterKeysForRoute(rk, routeEntries, clusterKeys)
+	}
+
+	proxyKeys := map[api.ProxyKey]bool{}
+	for _, p := range mh.proxies {
+		for _, dk := range p.DomainKeys {
+			if dk == domainKey {
+				proxyKeys[p.ProxyKey] = true
+			}
+		}
+	}
+	for _, e := range mh.entriesInWindow(start, stop, objecttype.Proxy) {
+		if e.Path == "proxy.domain_keys" && e.Value == string(domainKey) {
+			proxyKeys[api.ProxyKey(e.ObjectKey)] = true
+		}
+	}
+
+	return mh.selectChanges(start, stop, func(_ api.ChangeMeta, e api.ChangeEntry) bool {
+		switch e.ObjectType {
+		case objecttype.Domain:
+			return e.ObjectKey == string(domainKey)
+		case objecttype.Route:
+			return routeKeys[api.RouteKey(e.ObjectKey)]
+		case objecttype.Cluster:
+			return clusterKeys[api.ClusterKey(e.ObjectKey)]
+		case objecttype.Proxy:
+			return proxyKeys[api.ProxyKey(e.ObjectKey)]
+		}
+		return false
+	}), nil
+}
+
+func (mh memHistory) RouteGraph(
+	routeKey api.RouteKey,
+	start,
+	stop time.Time,
+) ([]api.ChangeDescription, error) {
+	start, stop, err := mh.window(start, stop, defaultGraphWindow, maxGraphWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	mh.RLock()
+	defer mh.RUnlock()
+
+	clusterKeys := map[api.ClusterKey]bool{}
+	mh.clusterKeysForRoute(
+		routeKey,
+		mh.entriesInWindow(start, stop, objecttype.Route),
+		clusterKeys,
+	)
+
+	return mh.selectChanges(start, stop, func(_ api.ChangeMeta, e api.ChangeEntry) bool {
+		switch e.ObjectType {
+		case objecttype.Route:
+			return e.ObjectKey == string(routeKey)
+		case objecttype.Cluster:
+			return clusterKeys[api.ClusterKey(e.ObjectKey)]
+		}
+		return false
+	}), nil
+}
+
+func (mh memHistory) SharedRulesGraph(
+	sharedRulesKey api.SharedRulesKey,
+	start,
+	stop time.Time,
+) ([]api.ChangeDescription, error) {
+	start, stop, err := mh.window(start, stop, defaultGraphWindow, maxGraphWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	mh.RLock()
+	defer mh.RUnlock()
+
+	return mh.selectChanges(start, stop, func(_ api.ChangeMeta, e api.ChangeEntry) bool {
+		return e.ObjectType == objecttype.SharedRules && e.ObjectKey == string(sharedRulesKey)
+	}), nil
+}
+
+func (mh memHistory) ClusterGraph(
+	clusterKey api.ClusterKey,
+	start,
+	stop time.Time,
+) ([]api.ChangeDescription, error) {
+	start, stop, err := mh.window(start, stop, defaultGraphWindow, maxGraphWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	mh.RLock()
+	defer mh.RUnlock()
+
+	routeKeys := map[string]bool{}
+	for _, r := range mh.routes {
+		if routeReferencesCluster(r, clusterKey) {
+			routeKeys[string(r.RouteKey)] = true
+		}
+	}
+	sharedRulesKeys := map[string]bool{}
+	for _, sr := range mh.sharedRules {
+		if sharedRulesReferencesCluster(sr, clusterKey) {
+			sharedRulesKeys[string(sr.SharedRulesKey)] = true
+		}
+	}
+	mh.selectChanges(start, stop, func(_ api.ChangeMeta, e api.ChangeEntry) bool {
+		if !isClusterKeyPath(e.Path) || e.Value != string(clusterKey) {
+			return false
+		}
+		switch e.ObjectType {
+		case objecttype.Route:
+			routeKeys[e.ObjectKey] = true
+		case objecttype.SharedRules:
+			sharedRulesKeys[e.ObjectKey] = true
+		}
+		return false
+	})
+
+	return mh.selectChanges(start, stop, func(_ api.ChangeMeta, e api.ChangeEntry) bool {
+		switch e.ObjectType {
+		case objecttype.Cluster:
+			return e.ObjectKey == string(clusterKey)
+		case objecttype.Route:
+			return routeKeys[e.ObjectKey]
+		case objecttype.SharedRules:
+			return sharedRulesKeys[e.ObjectKey]
+		}
+		return false
+	}), nil
+}
+
+func (mh memHistory) Zone(
+	zoneKey api.ZoneKey,
+	start,
+	stop time.Time,
+) ([]api.ChangeDescription, error) {
+	start, stop, err := mh.window(start, stop, defaultGraphWindow, maxGraphWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	mh.RLock()
+	defer mh.RUnlock()
+
+	return mh.selectChanges(start, stop, func(_ api.ChangeMeta, e api.ChangeEntry) bool {
+		return e.ZoneKey == zoneKey
+	}), nil
+}
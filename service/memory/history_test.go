@@ -0,0 +1,197 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/turbinelabs/api"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/objecttype"
+	"github.com/turbinelabs/api/service/changelog"
+	tbntime "github.com/turbinelabs/nonstdlib/time"
+	"github.com/turbinelabs/test/assert"
+)
+
+var historyTestTime = time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestHistoryIndex(t *testing.T) {
+	tbntime.WithTimeAt(historyTestTime, func(cs tbntime.ControlledSource) {
+		s, df := newTestStore()
+		s.time = cs
+
+		c := df.Cluster1
+		c.RequireTLS = true
+		_, err := s.Cluster().Modify(c)
+		assert.Nil(t, err)
+
+		cs.Advance(time.Minute)
+
+		d := df.Domain1
+		d.GzipEnabled = false
+		_, err = s.Domain().Modify(d)
+		assert.Nil(t, err)
+
+		cs.Advance(time.Minute)
+
+		got, err := s.History().Index(nil, time.Time{}, time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, len(got), 2)
+
+		got, err = s.History().Index(
+			changelog.Filter{ObjectType: objecttype.Cluster.Name},
+			time.Time{},
+			time.Time{},
+		)
+		assert.Nil(t, err)
+		assert.Equal(t, len(got), 1)
+		assert.Equal(t, got[0].Diffs[0].ObjectKey, string(df.ClusterKey1))
+
+		got, err = s.History().Index(
+			changelog.Filter{ObjectType: objecttype.Cluster.Name, NegativeMatch: true},
+			time.Time{},
+			time.Time{},
+		)
+		assert.Nil(t, err)
+		assert.Equal(t, len(got), 1)
+		assert.Equal(t, got[0].Diffs[0].ObjectKey, string(df.DomainKey1))
+
+		got, err = s.History().Index(
+			changelog.Filter{
+				FieldFilter: changelog.FieldFilter{ChangeType: changelog.ValueAdded},
+			},
+			time.Time{},
+			time.Time{},
+		)
+		assert.Nil(t, err)
+		assert.Equal(t, len(got), 2)
+		for _, cd := range got {
+			assert.Equal(t, len(cd.Diffs), 1)
+		}
+
+		got, err = s.History().Index(nil, historyTestTime.Add(30*time.Second), time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, len(got), 1)
+		assert.Equal(t, got[0].Diffs[0].ObjectType, objecttype.Domain)
+	})
+}
+
+func TestHistoryIndexAttributePath(t *testing.T) {
+	tbntime.WithTimeAt(historyTestTime, func(cs tbntime.ControlledSource) {
+		s, df := newTestStore()
+		s.time = cs
+
+		c := df.Cluster1
+		c.RequireTLS = true
+		_, err := s.Cluster().Modify(c)
+		assert.Nil(t, err)
+
+		r := df.Route1
+		r.Path = "/updated"
+		r.Rules[0].Constraints.Light[0].ClusterKey = df.ClusterKey2
+		_, err = s.Route().Modify(r)
+		assert.Nil(t, err)
+
+		cs.Advance(time.Minute)
+
+		got, err := s.History().Index(
+			changelog.Filter{
+				FieldFilter: changelog.FieldFilter{
+					AttributePath:     "cluster.require_tls",
+					AbsoluteMatchOnly: true,
+				},
+			},
+			time.Time{},
+			time.Time{},
+		)
+		assert.Nil(t, err)
+		assert.Equal(t, len(got), 1)
+		assert.Equal(t, len(got[0].Diffs), 2)
+		assert.Equal(t, got[0].Diffs[1].Value, "true")
+
+		query, err := changelog.ParseQuery(`attribute_path~"route.rules"`)
+		assert.Nil(t, err)
+		got, err = s.History().Index(query, time.Time{}, time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, len(got), 1)
+		assert.Equal(t, len(got[0].Diffs), 2)
+		for _, e := range got[0].Diffs {
+			assert.Equal(t, e.ObjectKey, string(df.RouteKey1))
+			assert.Equal(t, e.Path, "route.rules["+string(r.Rules[0].RuleKey)+"].constraints.light["+
+				string(r.Rules[0].Constraints.Light[0].ConstraintKey)+"].cluster_key")
+		}
+	})
+}
+
+func TestHistoryWindowErrors(t *testing.T) {
+	s, df := newTestStore()
+
+	_, err := s.History().Index(nil, historyTestTime, historyTestTime.Add(-time.Second))
+	assertErrorCode(t, err, httperr.BadParameterErrorCode)
+
+	_, err = s.History().ClusterGraph(
+		df.ClusterKey1,
+		historyTestTime,
+		historyTestTime.Add(25*time.Hour),
+	)
+	assertErrorCode(t, err, httperr.BadParameterErrorCode)
+}
+
+func TestHistoryGraphs(t *testing.T) {
+	tbntime.WithTimeAt(historyTestTime, func(cs tbntime.ControlledSource) {
+		s, df := newTestStore()
+		s.time = cs
+
+		c := df.Cluster2
+		c.RequireTLS = false
+		_, err := s.Cluster().Modify(c)
+		assert.Nil(t, err)
+
+		r := df.Route1
+		r.Path = "/updated"
+		r.Rules[0].Constraints.Light[0].ClusterKey = df.ClusterKey2
+		_, err = s.Route().Modify(r)
+		assert.Nil(t, err)
+
+		_, err = s.Zone().Create(api.Zone{Name: "unrelated"})
+		assert.Nil(t, err)
+
+		cs.Advance(time.Minute)
+
+		got, err := s.History().RouteGraph(df.RouteKey1, time.Time{}, time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, len(got), 2)
+
+		got, err = s.History().DomainGraph(df.RouteDomain1, time.Time{}, time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, len(got), 2)
+
+		got, err = s.History().ClusterGraph(df.ClusterKey2, time.Time{}, time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, len(got), 2)
+
+		got, err = s.History().SharedRulesGraph(df.SharedRulesKey1, time.Time{}, time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, len(got), 0)
+
+		got, err = s.History().Zone(df.ClusterZone2, time.Time{}, time.Time{})
+		assert.Nil(t, err)
+		assert.Equal(t, len(got), 1)
+		assert.Equal(t, got[0].Diffs[0].ObjectType, objecttype.Cluster)
+	})
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+)
+
+const listenerType = "listener"
+
+type memListener struct {
+	*store
+}
+
+func listenerFilterMatches(f service.ListenerFilter, l api.Listener) bool {
+	if !((f.ListenerKey == "" || f.ListenerKey == l.ListenerKey) &&
+		(f.Name == "" || f.Name == l.Name) &&
+		(f.ZoneKey == "" || f.ZoneKey == l.ZoneKey) &&
+		(f.OrgKey == "" || f.OrgKey == l.OrgKey)) {
+		return false
+	}
+
+	if f.HasNoDomains() {
+		return len(l.DomainKeys) == 0
+	}
+
+	domainKeys := map[api.DomainKey]bool{}
+	for _, dk := range l.DomainKeys {
+		domainKeys[dk] = true
+	}
+	for _, dk := range f.DomainKeys {
+		if !domainKeys[dk] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (ml memListener) Index(filters ...service.ListenerFilter) (api.Listeners, error) {
+	ml.RLock()
+	defer ml.RUnlock()
+
+	result := api.Listeners{}
+	for _, l := range ml.listeners {
+		if len(filters) == 0 {
+			result = append(result, cloneListener(l))
+			continue
+		}
+		for _, f := range filters {
+			if listenerFilterMatches(f, l) {
+				result = append(result, cloneListener(l))
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ListenerKey < result[j].ListenerKey })
+
+	return result, nil
+}
+
+func (ml memListener) Get(listenerKey api.ListenerKey) (api.Listener, error) {
+	if listenerKey == "" {
+		return api.Listener{}, keyRequired(listenerType)
+	}
+
+	ml.RLock()
+	defer ml.RUnlock()
+
+	l, ok := ml.listeners[listenerKey]
+	if !ok {
+		return api.Listener{}, notFound(listenerType, string(listenerKey))
+	}
+
+	return cloneListener(l), nil
+}
+
+// checkUnique verifies no other Listener shares l's Name and ZoneKey, or its
+// (IP, Port, ZoneKey). Callers must hold the lock.
+func (ml memListener) checkUnique(l api.Listener) error {
+	for _, o := range ml.listeners {
+		if o.ListenerKey == l.ListenerKey || o.ZoneKey != l.ZoneKey {
+			continue
+		}
+		if o.Name == l.Name {
+			return duplicate(listenerType, fmt.Sprintf("name %q in zone %q", l.Name, l.ZoneKey))
+		}
+		if o.IP == l.IP && o.Port == l.Port {
+			return duplicate(
+				listenerType,
+				fmt.Sprintf("ip %q and port %d in zone %q", l.IP, l.Port, l.ZoneKey),
+			)
+		}
+	}
+	return nil
+}
+
+// put validates and stores l with a new Checksum, recording the change from
+// prev. Callers must hold the write lock.
+func (ml memListener) put(prev *api.Listener, l api.Listener) (api.Listener, error) {
+	l.Checksum = newChecksum()
+	if err := invalid(l.IsValid()); err != nil {
+		return api.Listener{}, err
+	}
+	if err := ml.checkUnique(l); err != nil {
+		return api.Listener{}, err
+	}
+
+	ml.listeners[l.ListenerKey] = l
+
+	chg := change{
+		orgKey: l.OrgKey,
+		after:  l,
+	}
+	if prev != nil {
+		chg.before = *prev
+	}
+	ml.record(chg)
+
+	return cloneListener(l), nil
+}
+
+// lookup returns the existing Listener for the given key after verifying its
+// Checksum. Callers must hold the lock.
+func (ml memListener) lookup(
+	listenerKey api.ListenerKey,
+	checksum api.Checksum,
+) (api.Listener, error) {
+	if listenerKey == "" {
+		return api.Listener{}, keyRequired(listenerType)
+	}
+
+	prev, ok := ml.listeners[listenerKey]
+	if !ok {
+		return api.Listener{}, notFound(listenerType, string(listenerKey))
+	}
+
+	if !prev.Checksum.Equals(checksum) {
+		return api.Listener{}, checksumMismatch(listenerType, string(listenerKey))
+	}
+
+	return prev, nil
+}
+
+func (ml memListener) Create(listener api.Listener) (api.Listener, error) {
+	ml.Lock()
+	defer ml.Unlock()
+
+	l := cloneListener(listener)
+	l.ListenerKey = api.ListenerKey(newKey())
+	l.OrgKey = ml.orgKey
+
+	return ml.put(nil, l)
+}
+
+func (ml memListener) Modify(listener api.Listener) (api.Listener, error) {
+	ml.Lock()
+	defer ml.Unlock()
+
+	prev, err := ml.lookup(listener.ListenerKey, listener.Checksum)
+	if err != nil {
+		return api.Listener{}, err
+	}
+
+	l := cloneListener(listener)
+	l.OrgKey = prev.OrgKey
+
+	return ml.put(&prev, l)
+}
+
+func (ml memListener) Delete(listenerKey api.ListenerKey, checksum api.Checksum) error {
+	ml.Lock()
+	defer ml.Unlock()
+
+	prev, err := ml.lookup(listenerKey, checksum)
+	if err != nil {
+		return err
+	}
+
+	delete(ml.listeners, listenerKey)
+	ml.record(change{
+		orgKey: prev.OrgKey,
+		before: prev,
+	})
+
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/turbinelabs/api"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/test/assert"
+)
+
+func TestListenerIndex(t *testing.T) {
+	s, df := newTestStore()
+
+	got, err := s.Listener().Index(service.ListenerFilter{Name: df.ListenerName2})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 1)
+	assert.Equal(t, got[0].ListenerKey, df.ListenerKey2)
+
+	got, err = s.Listener().Index(service.ListenerFilter{DomainKeys: []api.DomainKey{service.None}})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 2)
+
+	got, err = s.Listener().Index(service.ListenerFilter{DomainKeys: []api.DomainKey{df.DomainKey1}})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 0)
+}
+
+func TestListenerCreateDuplicates(t *testing.T) {
+	s, df := newTestStore()
+
+	l := df.Listener1
+	l.Port = 9999
+	_, err := s.Listener().Create(l)
+	assertErrorCode(t, err, httperr.DataConstraintErrorCode)
+
+	l = df.Listener1
+	l.Name = "other-name"
+	_, err = s.Listener().Create(l)
+	assertErrorCode(t, err, httperr.DataConstraintErrorCode)
+
+	l.Port = 9999
+	got, err := s.Listener().Create(l)
+	assert.Nil(t, err)
+	assert.Equal(t, got.Name, "other-name")
+}
+
+func TestListenerModifyAndDelete(t *testing.T) {
+	s, df := newTestStore()
+
+	l := df.Listener1
+	l.Port = 81
+	got, err := s.Listener().Modify(l)
+	assert.Nil(t, err)
+	assert.Equal(t, got.Port, 81)
+
+	err = s.Listener().Delete(df.ListenerKey1, got.Checksum)
+	assert.Nil(t, err)
+
+	_, err = s.Listener().Get(df.ListenerKey1)
+	assertErrorCode(t, err, httperr.NotFoundErrorCode)
+}
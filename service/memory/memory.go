@@ -0,0 +1,304 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package memory provides a thread-safe, in-memory implementation of
+// service.All, service.Admin, and service.History. It enforces the semantics
+// documented on those interfaces (key assignment on Create, checksum
+// rotation on Modify, checksum verification on Delete, uniqueness
+// constraints, and filtering) and records a changelog entry for each
+// mutation that changes an object, using the attribute paths computed by
+// the diff package. It is intended for use in tests and local development:
+//
+// 	svc := memory.New()
+// 	clusters, err := svc.Cluster().Index(service.ClusterFilter{Name: "cluster1"})
+//
+// Errors returned are *httperr.Error values with the same codes and statuses
+// an api server would produce.
+package memory
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/fixture"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/service"
+	tbntime "github.com/turbinelabs/nonstdlib/time"
+)
+
+// Store is an in-memory implementation of both service.All and
+// service.Admin.
+type Store interface {
+	service.All
+	service.Admin
+}
+
+// New returns a Store seeded with the objects from fixture.New(). See
+// NewFromFixtures.
+func New() Store {
+	return NewFromFixtures(fixture.New())
+}
+
+// NewFromFixtures returns a Store seeded with the Zones, Orgs, Users,
+// AccessTokens, Clusters, Domains, Proxies, Listeners, Routes, and SharedRules
+// found in the given DataFixturesT. Seeded objects are stored as-is, without validation.
+// Objects created through the Store are owned by df.ValidOrgID and changes
+// are attributed to df.UserKey1.
+func NewFromFixtures(df fixture.DataFixturesT) Store {
+	s := newStore(df.ValidOrgID, df.UserKey1)
+
+	for _, z := range df.ZoneSlice {
+		s.zones[z.ZoneKey] = cloneZone(z)
+	}
+	for _, o := range df.OrgSlice {
+		s.orgs[o.OrgKey] = cloneOrg(o)
+	}
+	for _, u := range df.UserSlice {
+		s.users[u.UserKey] = cloneUser(u)
+	}
+	for _, t := range df.AccessTokenSlice {
+		s.accessTokens[t.AccessTokenKey] = cloneAccessToken(t)
+	}
+	for _, c := range df.ClusterSlice {
+		s.clusters[c.ClusterKey] = cloneCluster(c)
+	}
+	for _, d := range df.DomainSlice {
+		s.domains[d.DomainKey] = cloneDomain(d)
+	}
+	for _, p := range df.ProxySlice {
+		s.proxies[p.ProxyKey] = cloneProxy(p)
+	}
+	for _, l := range df.ListenerSlice {
+		s.listeners[l.ListenerKey] = cloneListener(l)
+	}
+	for _, r := range df.RouteSlice {
+		s.routes[r.RouteKey] = cloneRoute(r)
+	}
+	for _, sr := range df.SharedRulesSlice {
+		s.sharedRules[sr.SharedRulesKey] = cloneSharedRules(sr)
+	}
+
+	return s
+}
+
+// NewEmpty returns a Store containing no objects. Objects created through
+// the Store are owned by the given OrgKey and changes are attributed to the
+// given UserKey.
+func NewEmpty(orgKey api.OrgKey, actor api.UserKey) Store {
+	return newStore(orgKey, actor)
+}
+
+func newStore(orgKey api.OrgKey, actor api.UserKey) *store {
+	return &store{
+		orgKey:       orgKey,
+		actor:        actor,
+		time:         tbntime.NewSource(),
+		zones:        map[api.ZoneKey]api.Zone{},
+		orgs:         map[api.OrgKey]api.Org{},
+		users:        map[api.UserKey]api.User{},
+		accessTokens: map[api.AccessTokenKey]api.AccessToken{},
+		clusters:     map[api.ClusterKey]api.Cluster{},
+		domains:      map[api.DomainKey]api.Domain{},
+		proxies:      map[api.ProxyKey]api.Proxy{},
+		listeners:    map[api.ListenerKey]api.Listener{},
+		routes:       map[api.RouteKey]api.Route{},
+		sharedRules:  map[api.SharedRulesKey]api.SharedRules{},
+	}
+}
+
+// store holds all objects behind a single lock, which keeps cross-object
+// operations (e.g. filtering Domains by the Proxies that reference them)
+// consistent.
+type store struct {
+	sync.RWMutex
+
+	orgKey api.OrgKey
+	actor  api.UserKey
+	time   tbntime.Source
+
+	zones        map[api.ZoneKey]api.Zone
+	orgs         map[api.OrgKey]api.Org
+	users        map[api.UserKey]api.User
+	accessTokens map[api.AccessTokenKey]api.AccessToken
+	clusters     map[api.ClusterKey]api.Cluster
+	domains      map[api.DomainKey]api.Domain
+	proxies      map[api.ProxyKey]api.Proxy
+	listeners    map[api.ListenerKey]api.Listener
+	routes       map[api.RouteKey]api.Route
+	sharedRules  map[api.SharedRulesKey]api.SharedRules
+
+	changes []api.ChangeDescription
+}
+
+var _ Store = &store{}
+
+func (s *store) Cluster() service.Cluster         { return memCluster{s} }
+func (s *store) Domain() service.Domain           { return memDomain{s} }
+func (s *store) SharedRules() service.SharedRules { return memSharedRules{s} }
+func (s *store) Route() service.Route             { return memRoute{s} }
+func (s *store) Proxy() service.Proxy             { return memProxy{s} }
+func (s *store) Listener() service.Listener       { return memListener{s} }
+func (s *store) Zone() service.Zone               { return memZone{s} }
+func (s *store) History() service.History         { return memHistory{s} }
+func (s *store) Batch() service.Batch             { return memBatch{s} }
+func (s *store) User() service.User               { return memUser{s} }
+func (s *store) Org() service.Org                 { return memOrg{s} }
+func (s *store) AccessToken() service.AccessToken { return memAccessToken{s} }
+
+// newKey produces a random, hex-encoded UUID which satisfies api.KeyPattern.
+func newKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("unable to generate random key: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf(
+		"%s-%s-%s-%s-%s",
+		hex.EncodeToString(b[0:4]),
+		hex.EncodeToString(b[4:6]),
+		hex.EncodeToString(b[6:8]),
+		hex.EncodeToString(b[8:10]),
+		hex.EncodeToString(b[10:16]),
+	)
+}
+
+func newChecksum() api.Checksum {
+	return api.Checksum{Checksum: newKey()}
+}
+
+func notFound(objType string, key string) error {
+	return httperr.New404(
+		fmt.Sprintf("%s %q not found", objType, key),
+		httperr.NotFoundErrorCode,
+	)
+}
+
+func keyRequired(objType string) error {
+	return httperr.New400(
+		fmt.Sprintf("%s key is required", objType),
+		httperr.ObjectKeyRequiredErrorCode,
+	)
+}
+
+func checksumMismatch(objType string, key string) error {
+	return httperr.New409(
+		fmt.Sprintf("checksum mismatch for %s %q", objType, key),
+		httperr.UnknownModificationConflict,
+	)
+}
+
+func duplicate(objType string, desc string) error {
+	return httperr.New400(
+		fmt.Sprintf("%s with %s already exists", objType, desc),
+		httperr.DataConstraintErrorCode,
+	)
+}
+
+func invalid(err *api.ValidationError) error {
+	if err == nil {
+		return nil
+	}
+	return httperr.NewDetailed400(err.Error(), httperr.InvalidObjectErrorCode, err)
+}
+
+// clone makes a deep copy of src into dst via a JSON round trip. Fields that
+// are not serialized (e.g. OrgKey) must be restored by the caller.
+func clone(src, dst interface{}) {
+	b, err := json.Marshal(src)
+	if err != nil {
+		panic(fmt.Sprintf("unable to clone %T: %v", src, err))
+	}
+	if err := json.Unmarshal(b, dst); err != nil {
+		panic(fmt.Sprintf("unable to clone %T: %v", src, err))
+	}
+}
+
+func cloneZone(z api.Zone) api.Zone {
+	var c api.Zone
+	clone(z, &c)
+	c.OrgKey = z.OrgKey
+	return c
+}
+
+func cloneOrg(o api.Org) api.Org {
+	var c api.Org
+	clone(o, &c)
+	return c
+}
+
+func cloneUser(u api.User) api.User {
+	var c api.User
+	clone(u, &c)
+	return c
+}
+
+func cloneAccessToken(t api.AccessToken) api.AccessToken {
+	var c api.AccessToken
+	clone(t, &c)
+	c.OrgKey = t.OrgKey
+	return c
+}
+
+func cloneCluster(cl api.Cluster) api.Cluster {
+	var c api.Cluster
+	clone(cl, &c)
+	c.OrgKey = cl.OrgKey
+	return c
+}
+
+func cloneDomain(d api.Domain) api.Domain {
+	var c api.Domain
+	clone(d, &c)
+	c.OrgKey = d.OrgKey
+	return c
+}
+
+func cloneProxy(p api.Proxy) api.Proxy {
+	var c api.Proxy
+	clone(p, &c)
+	c.OrgKey = p.OrgKey
+	for i := range c.Listeners {
+		c.Listeners[i].OrgKey = p.Listeners[i].OrgKey
+	}
+	return c
+}
+
+func cloneListener(l api.Listener) api.Listener {
+	var c api.Listener
+	clone(l, &c)
+	c.OrgKey = l.OrgKey
+	return c
+}
+
+func cloneRoute(r api.Route) api.Route {
+	var c api.Route
+	clone(r, &c)
+	c.OrgKey = r.OrgKey
+	return c
+}
+
+func cloneSharedRules(sr api.SharedRules) api.SharedRules {
+	var c api.SharedRules
+	clone(sr, &c)
+	c.OrgKey = sr.OrgKey
+	return c
+}
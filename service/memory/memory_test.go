@@ -0,0 +1,136 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/fixture"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/test/assert"
+)
+
+func newTestStore() (*store, fixture.DataFixturesT) {
+	df := fixture.New()
+	return NewFromFixtures(df).(*store), df
+}
+
+func assertErrorCode(t *testing.T, err error, code httperr.ErrorCode) {
+	httpErr, ok := err.(*httperr.Error)
+	if assert.True(t, ok) {
+		assert.Equal(t, httpErr.Code, code)
+	}
+}
+
+func TestNewKeyMatchesKeyPattern(t *testing.T) {
+	k1 := newKey()
+	k2 := newKey()
+	assert.True(t, api.KeyPattern.MatchString(k1))
+	assert.True(t, api.KeyPattern.MatchString(k2))
+	assert.NotEqual(t, k1, k2)
+}
+
+func TestNewSeedsFromFixtures(t *testing.T) {
+	s, df := newTestStore()
+
+	clusters, err := s.Cluster().Index()
+	assert.Nil(t, err)
+	assert.Equal(t, len(clusters), len(df.ClusterSlice))
+
+	domains, err := s.Domain().Index()
+	assert.Nil(t, err)
+	assert.Equal(t, len(domains), len(df.DomainSlice))
+
+	proxies, err := s.Proxy().Index()
+	assert.Nil(t, err)
+	assert.Equal(t, len(proxies), len(df.ProxySlice))
+
+	listeners, err := s.Listener().Index()
+	assert.Nil(t, err)
+	assert.Equal(t, len(listeners), len(df.ListenerSlice))
+
+	routes, err := s.Route().Index()
+	assert.Nil(t, err)
+	assert.Equal(t, len(routes), len(df.RouteSlice))
+
+	sharedRules, err := s.SharedRules().Index()
+	assert.Nil(t, err)
+	assert.Equal(t, len(sharedRules), len(df.SharedRulesSlice))
+
+	zones, err := s.Zone().Index()
+	assert.Nil(t, err)
+	assert.Equal(t, len(zones), len(df.ZoneSlice))
+
+	users, err := s.User().Index()
+	assert.Nil(t, err)
+	assert.Equal(t, len(users), len(df.UserSlice))
+
+	tokens, err := s.AccessToken().Index()
+	assert.Nil(t, err)
+	assert.Equal(t, len(tokens), len(df.AccessTokenSlice))
+
+	assert.Equal(t, s.orgKey, df.ValidOrgID)
+	assert.Equal(t, s.actor, df.UserKey1)
+	assert.Equal(t, len(s.changes), 0)
+}
+
+func TestNewEmpty(t *testing.T) {
+	s := NewEmpty("org", "actor").(*store)
+
+	clusters, err := s.Cluster().Index()
+	assert.Nil(t, err)
+	assert.Equal(t, len(clusters), 0)
+	assert.Equal(t, s.orgKey, api.OrgKey("org"))
+	assert.Equal(t, s.actor, api.UserKey("actor"))
+}
+
+func TestStoreReturnsCopies(t *testing.T) {
+	s, df := newTestStore()
+
+	c, err := s.Cluster().Get(df.ClusterKey2)
+	assert.Nil(t, err)
+	c.Instances[0].Host = "mutated"
+	c.Instances = append(c.Instances, api.Instance{Host: "another", Port: 1})
+
+	got, err := s.Cluster().Get(df.ClusterKey2)
+	assert.Nil(t, err)
+	assert.True(t, got.Equals(df.Cluster2))
+}
+
+func TestStoreConcurrentAccess(t *testing.T) {
+	s := NewEmpty("1", "1")
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := s.Zone().Create(api.Zone{Name: string(rune('a' + i))})
+			assert.Nil(t, err)
+			_, err = s.Zone().Index(service.ZoneFilter{OrgKey: "1"})
+			assert.Nil(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	zones, err := s.Zone().Index()
+	assert.Nil(t, err)
+	assert.Equal(t, len(zones), 10)
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+)
+
+const orgType = "org"
+
+type memOrg struct {
+	*store
+}
+
+func orgFilterMatches(f service.OrgFilter, o api.Org) bool {
+	return (f.OrgKey == "" || f.OrgKey == o.OrgKey) &&
+		(f.Name == "" || f.Name == o.Name) &&
+		(f.ContactEmail == "" || f.ContactEmail == o.ContactEmail)
+}
+
+func (mo memOrg) Index(filters ...service.OrgFilter) (api.Orgs, error) {
+	mo.RLock()
+	defer mo.RUnlock()
+
+	result := api.Orgs{}
+	for _, o := range mo.orgs {
+		if len(filters) == 0 {
+			result = append(result, cloneOrg(o))
+			continue
+		}
+		for _, f := range filters {
+			if orgFilterMatches(f, o) {
+				result = append(result, cloneOrg(o))
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].OrgKey < result[j].OrgKey })
+
+	return result, nil
+}
+
+func (mo memOrg) Get(orgKey api.OrgKey) (api.Org, error) {
+	if orgKey == "" {
+		return api.Org{}, keyRequired(orgType)
+	}
+
+	mo.RLock()
+	defer mo.RUnlock()
+
+	o, ok := mo.orgs[orgKey]
+	if !ok {
+		return api.Org{}, notFound(orgType, string(orgKey))
+	}
+
+	return cloneOrg(o), nil
+}
+
+// checkUnique verifies no other Org shares o's Name. Callers must hold the
+// lock.
+func (mo memOrg) checkUnique(o api.Org) error {
+	for _, other := range mo.orgs {
+		if other.OrgKey != o.OrgKey && other.Name == o.Name {
+			return duplicate(orgType, fmt.Sprintf("name %q", o.Name))
+		}
+	}
+	return nil
+}
+
+// put validates and stores o with a new Checksum, recording the change from
+// prev. Callers must hold the write lock.
+func (mo memOrg) put(prev *api.Org, o api.Org) (api.Org, error) {
+	o.Checksum = newChecksum()
+	if err := invalid(o.IsValid()); err != nil {
+		return api.Org{}, err
+	}
+	if err := mo.checkUnique(o); err != nil {
+		return api.Org{}, err
+	}
+
+	mo.orgs[o.OrgKey] = o
+
+	chg := change{
+		orgKey: o.OrgKey,
+		after:  o,
+	}
+	if prev != nil {
+		chg.before = *prev
+	}
+	mo.record(chg)
+
+	return cloneOrg(o), nil
+}
+
+// lookup returns the existing Org for the given key after verifying its
+// Checksum. Callers must hold the lock.
+func (mo memOrg) lookup(orgKey api.OrgKey, checksum api.Checksum) (api.Org, error) {
+	if orgKey == "" {
+		return api.Org{}, keyRequired(orgType)
+	}
+
+	prev, ok := mo.orgs[orgKey]
+	if !ok {
+		return api.Org{}, notFound(orgType, string(orgKey))
+	}
+
+	if !prev.Checksum.Equals(checksum) {
+		return api.Org{}, checksumMismatch(orgType, string(orgKey))
+	}
+
+	return prev, nil
+}
+
+func (mo memOrg) Create(org api.Org) (api.Org, error) {
+	mo.Lock()
+	defer mo.Unlock()
+
+	o := cloneOrg(org)
+	o.OrgKey = api.OrgKey(newKey())
+
+	return mo.put(nil, o)
+}
+
+func (mo memOrg) Modify(org api.Org) (api.Org, error) {
+	mo.Lock()
+	defer mo.Unlock()
+
+	prev, err := mo.lookup(org.OrgKey, org.Checksum)
+	if err != nil {
+		return api.Org{}, err
+	}
+
+	return mo.put(&prev, cloneOrg(org))
+}
+
+func (mo memOrg) Delete(orgKey api.OrgKey, checksum api.Checksum) error {
+	mo.Lock()
+	defer mo.Unlock()
+
+	prev, err := mo.lookup(orgKey, checksum)
+	if err != nil {
+		return err
+	}
+
+	delete(mo.orgs, orgKey)
+	mo.record(change{
+		orgKey: orgKey,
+		before: prev,
+	})
+
+	return nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/turbinelabs/api"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/objecttype"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/test/assert"
+)
+
+func TestOrgIndex(t *testing.T) {
+	s, df := newTestStore()
+
+	got, err := s.Org().Index()
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, df.OrgSlice)
+
+	got, err = s.Org().Index(
+		service.OrgFilter{Name: df.OrgName2},
+		service.OrgFilter{ContactEmail: "nobody@example.com"},
+	)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, api.Orgs{df.Org2})
+}
+
+func TestOrgCreateModifyDelete(t *testing.T) {
+	s, df := newTestStore()
+
+	_, err := s.Org().Create(api.Org{Name: df.OrgName1, ContactEmail: "x@example.com"})
+	assertErrorCode(t, err, httperr.DataConstraintErrorCode)
+
+	_, err = s.Org().Create(api.Org{Name: "NewCo"})
+	assertErrorCode(t, err, httperr.InvalidObjectErrorCode)
+
+	org, err := s.Org().Create(api.Org{Name: "NewCo", ContactEmail: "x@example.com"})
+	assert.Nil(t, err)
+	assert.NotEqual(t, org.OrgKey, "")
+	assert.NotEqual(t, org.Checksum, api.Checksum{})
+
+	org.ContactEmail = "y@example.com"
+	modified, err := s.Org().Modify(org)
+	assert.Nil(t, err)
+	assert.Equal(t, modified.ContactEmail, "y@example.com")
+
+	_, err = s.Org().Modify(org)
+	assertErrorCode(t, err, httperr.UnknownModificationConflict)
+
+	assert.Nil(t, s.Org().Delete(org.OrgKey, modified.Checksum))
+	_, err = s.Org().Get(org.OrgKey)
+	assertErrorCode(t, err, httperr.NotFoundErrorCode)
+
+	assert.Equal(t, len(s.changes), 3)
+	for _, cd := range s.changes {
+		assert.Equal(t, cd.OrgKey, org.OrgKey)
+		assert.Equal(t, cd.Diffs[0].ObjectType, objecttype.Org)
+	}
+}
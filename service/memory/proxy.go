@@ -0,0 +1,211 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+)
+
+const proxyType = "proxy"
+
+type memProxy struct {
+	*store
+}
+
+func proxyFilterMatches(f service.ProxyFilter, p api.Proxy) bool {
+	if !((f.ProxyKey == "" || f.ProxyKey == p.ProxyKey) &&
+		(f.Name == "" || f.Name == p.Name) &&
+		(f.ZoneKey == "" || f.ZoneKey == p.ZoneKey) &&
+		(f.OrgKey == "" || f.OrgKey == p.OrgKey)) {
+		return false
+	}
+
+	if f.HasNoDomains() {
+		if len(p.DomainKeys) != 0 {
+			return false
+		}
+	} else if len(f.DomainKeys) > 0 {
+		domainKeys := map[api.DomainKey]bool{}
+		for _, dk := range p.DomainKeys {
+			domainKeys[dk] = true
+		}
+		for _, dk := range f.DomainKeys {
+			if !domainKeys[dk] {
+				return false
+			}
+		}
+	}
+
+	if f.HasNoListeners() {
+		if len(p.ListenerKeys) != 0 {
+			return false
+		}
+	} else if len(f.ListenerKeys) > 0 {
+		listenerKeys := map[api.ListenerKey]bool{}
+		for _, lk := range p.ListenerKeys {
+			listenerKeys[lk] = true
+		}
+		for _, lk := range f.ListenerKeys {
+			if !listenerKeys[lk] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (mp memProxy) Index(filters ...service.ProxyFilter) (api.Proxies, error) {
+	mp.RLock()
+	defer mp.RUnlock()
+
+	result := api.Proxies{}
+	for _, p := range mp.proxies {
+		if len(filters) == 0 {
+			result = append(result, cloneProxy(p))
+			continue
+		}
+		for _, f := range filters {
+			if proxyFilterMatches(f, p) {
+				result = append(result, cloneProxy(p))
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ProxyKey < result[j].ProxyKey })
+
+	return result, nil
+}
+
+func (mp memProxy) Get(proxyKey api.ProxyKey) (api.Proxy, error) {
+	if proxyKey == "" {
+		return api.Proxy{}, keyRequired(proxyType)
+	}
+
+	mp.RLock()
+	defer mp.RUnlock()
+
+	p, ok := mp.proxies[proxyKey]
+	if !ok {
+		return api.Proxy{}, notFound(proxyType, string(proxyKey))
+	}
+
+	return cloneProxy(p), nil
+}
+
+// checkUnique verifies no other Proxy shares p's Name and ZoneKey. Callers
+// must hold the lock.
+func (mp memProxy) checkUnique(p api.Proxy) error {
+	for _, o := range mp.proxies {
+		if o.ProxyKey != p.ProxyKey && o.ZoneKey == p.ZoneKey && o.Name == p.Name {
+			return duplicate(proxyType, fmt.Sprintf("name %q in zone %q", p.Name, p.ZoneKey))
+		}
+	}
+	return nil
+}
+
+// put validates and stores p with a new Checksum, recording the change from
+// prev. Callers must hold the write lock.
+func (mp memProxy) put(prev *api.Proxy, p api.Proxy) (api.Proxy, error) {
+	p.Checksum = newChecksum()
+	if err := invalid(p.IsValid()); err != nil {
+		return api.Proxy{}, err
+	}
+	if err := mp.checkUnique(p); err != nil {
+		return api.Proxy{}, err
+	}
+
+	mp.proxies[p.ProxyKey] = p
+
+	chg := change{
+		orgKey: p.OrgKey,
+		after:  p,
+	}
+	if prev != nil {
+		chg.before = *prev
+	}
+	mp.record(chg)
+
+	return cloneProxy(p), nil
+}
+
+// lookup returns the existing Proxy for the given key after verifying its
+// Checksum. Callers must hold the lock.
+func (mp memProxy) lookup(proxyKey api.ProxyKey, checksum api.Checksum) (api.Proxy, error) {
+	if proxyKey == "" {
+		return api.Proxy{}, keyRequired(proxyType)
+	}
+
+	prev, ok := mp.proxies[proxyKey]
+	if !ok {
+		return api.Proxy{}, notFound(proxyType, string(proxyKey))
+	}
+
+	if !prev.Checksum.Equals(checksum) {
+		return api.Proxy{}, checksumMismatch(proxyType, string(proxyKey))
+	}
+
+	return prev, nil
+}
+
+func (mp memProxy) Create(proxy api.Proxy) (api.Proxy, error) {
+	mp.Lock()
+	defer mp.Unlock()
+
+	p := cloneProxy(proxy)
+	p.ProxyKey = api.ProxyKey(newKey())
+	p.OrgKey = mp.orgKey
+
+	return mp.put(nil, p)
+}
+
+func (mp memProxy) Modify(proxy api.Proxy) (api.Proxy, error) {
+	mp.Lock()
+	defer mp.Unlock()
+
+	prev, err := mp.lookup(proxy.ProxyKey, proxy.Checksum)
+	if err != nil {
+		return api.Proxy{}, err
+	}
+
+	p := cloneProxy(proxy)
+	p.OrgKey = prev.OrgKey
+
+	return mp.put(&prev, p)
+}
+
+func (mp memProxy) Delete(proxyKey api.ProxyKey, checksum api.Checksum) error {
+	mp.Lock()
+	defer mp.Unlock()
+
+	prev, err := mp.lookup(proxyKey, checksum)
+	if err != nil {
+		return err
+	}
+
+	delete(mp.proxies, proxyKey)
+	mp.record(change{
+		orgKey: prev.OrgKey,
+		before: prev,
+	})
+
+	return nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/turbinelabs/api"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/test/assert"
+)
+
+func TestProxyIndexListenerKeys(t *testing.T) {
+	s, df := newTestStore()
+
+	got, err := s.Proxy().Index(service.ProxyFilter{ListenerKeys: []api.ListenerKey{df.ListenerKey1}})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 1)
+	assert.Equal(t, got[0].ProxyKey, df.ProxyKey1)
+
+	got, err = s.Proxy().Index(service.ProxyFilter{ListenerKeys: []api.ListenerKey{service.None}})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 1)
+	assert.Equal(t, got[0].ProxyKey, df.ProxyKey2)
+}
+
+func TestProxyIndexDomainKeys(t *testing.T) {
+	s, df := newTestStore()
+
+	got, err := s.Proxy().Index(service.ProxyFilter{DomainKeys: []api.DomainKey{df.DomainKey1}})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 2)
+
+	got, err = s.Proxy().Index(
+		service.ProxyFilter{DomainKeys: []api.DomainKey{df.DomainKey1, "nope"}},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 0)
+
+	got, err = s.Proxy().Index(service.ProxyFilter{DomainKeys: []api.DomainKey{service.None}})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 0)
+
+	created, err := s.Proxy().Create(api.Proxy{ZoneKey: df.ProxyZone1, Name: "lonely"})
+	assert.Nil(t, err)
+
+	got, err = s.Proxy().Index(service.ProxyFilter{DomainKeys: []api.DomainKey{service.None}})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 1)
+	assert.Equal(t, got[0].ProxyKey, created.ProxyKey)
+}
+
+func TestProxyCreateDuplicateName(t *testing.T) {
+	s, df := newTestStore()
+
+	_, err := s.Proxy().Create(df.Proxy1)
+	assertErrorCode(t, err, httperr.DataConstraintErrorCode)
+}
+
+func TestProxyModifyAndDelete(t *testing.T) {
+	s, df := newTestStore()
+
+	p := df.Proxy2
+	p.DomainKeys = nil
+	got, err := s.Proxy().Modify(p)
+	assert.Nil(t, err)
+	assert.Equal(t, len(got.DomainKeys), 0)
+
+	err = s.Proxy().Delete(df.ProxyKey2, got.Checksum)
+	assert.Nil(t, err)
+
+	_, err = s.Proxy().Get(df.ProxyKey2)
+	assertErrorCode(t, err, httperr.NotFoundErrorCode)
+}
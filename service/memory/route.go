@@ -0,0 +1,185 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+)
+
+const routeType = "route"
+
+type memRoute struct {
+	*store
+}
+
+func routeFilterMatches(f service.RouteFilter, r api.Route) bool {
+	return (f.RouteKey == "" || f.RouteKey == r.RouteKey) &&
+		(f.DomainKey == "" || f.DomainKey == r.DomainKey) &&
+		(f.SharedRulesKey == "" || f.SharedRulesKey == r.SharedRulesKey) &&
+		(f.Path == "" || f.Path == r.Path) &&
+		(f.PathPrefix == "" || strings.HasPrefix(r.Path, f.PathPrefix)) &&
+		(f.ZoneKey == "" || f.ZoneKey == r.ZoneKey) &&
+		(f.OrgKey == "" || f.OrgKey == r.OrgKey)
+}
+
+func (mr memRoute) Index(filters ...service.RouteFilter) (api.Routes, error) {
+	mr.RLock()
+	defer mr.RUnlock()
+
+	result := api.Routes{}
+	for _, r := range mr.routes {
+		if len(filters) == 0 {
+			result = append(result, cloneRoute(r))
+			continue
+		}
+		for _, f := range filters {
+			if routeFilterMatches(f, r) {
+				result = append(result, cloneRoute(r))
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].RouteKey < result[j].RouteKey })
+
+	return result, nil
+}
+
+func (mr memRoute) Get(routeKey api.RouteKey) (api.Route, error) {
+	if routeKey == "" {
+		return api.Route{}, keyRequired(routeType)
+	}
+
+	mr.RLock()
+	defer mr.RUnlock()
+
+	r, ok := mr.routes[routeKey]
+	if !ok {
+		return api.Route{}, notFound(routeType, string(routeKey))
+	}
+
+	return cloneRoute(r), nil
+}
+
+// checkUnique verifies no other Route in r's Zone shares its Domain and Path.
+// Callers must hold the lock.
+func (mr memRoute) checkUnique(r api.Route) error {
+	for _, o := range mr.routes {
+		if o.RouteKey != r.RouteKey &&
+			o.ZoneKey == r.ZoneKey &&
+			o.DomainKey == r.DomainKey &&
+			o.Path == r.Path {
+			return duplicate(
+				routeType,
+				fmt.Sprintf("path %q for domain %q in zone %q", r.Path, r.DomainKey, r.ZoneKey),
+			)
+		}
+	}
+	return nil
+}
+
+// put validates and stores r with a new Checksum, recording the change from
+// prev. Callers must hold the write lock.
+func (mr memRoute) put(prev *api.Route, r api.Route) (api.Route, error) {
+	r.Checksum = newChecksum()
+	if err := invalid(r.IsValid()); err != nil {
+		return api.Route{}, err
+	}
+	if err := mr.checkUnique(r); err != nil {
+		return api.Route{}, err
+	}
+
+	mr.routes[r.RouteKey] = r
+
+	chg := change{
+		orgKey: r.OrgKey,
+		after:  r,
+	}
+	if prev != nil {
+		chg.before = *prev
+	}
+	mr.record(chg)
+
+	return cloneRoute(r), nil
+}
+
+// lookup returns the existing Route for the given key after verifying its
+// Checksum. Callers must hold the lock.
+func (mr memRoute) lookup(routeKey api.RouteKey, checksum api.Checksum) (api.Route, error) {
+	if routeKey == "" {
+		return api.Route{}, keyRequired(routeType)
+	}
+
+	prev, ok := mr.routes[routeKey]
+	if !ok {
+		return api.Route{}, notFound(routeType, string(routeKey))
+	}
+
+	if !prev.Checksum.Equals(checksum) {
+		return api.Route{}, checksumMismatch(routeType, string(routeKey))
+	}
+
+	return prev, nil
+}
+
+func (mr memRoute) Create(route api.Route) (api.Route, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	r := cloneRoute(route)
+	r.RouteKey = api.RouteKey(newKey())
+	r.OrgKey = mr.orgKey
+
+	return mr.put(nil, r)
+}
+
+func (mr memRoute) Modify(route api.Route) (api.Route, error) {
+	mr.Lock()
+	defer mr.Unlock()
+
+	prev, err := mr.lookup(route.RouteKey, route.Checksum)
+	if err != nil {
+		return api.Route{}, err
+	}
+
+	r := cloneRoute(route)
+	r.OrgKey = prev.OrgKey
+
+	return mr.put(&prev, r)
+}
+
+func (mr memRoute) Delete(routeKey api.RouteKey, checksum api.Checksum) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	prev, err := mr.lookup(routeKey, checksum)
+	if err != nil {
+		return err
+	}
+
+	delete(mr.routes, routeKey)
+	mr.record(change{
+		orgKey: prev.OrgKey,
+		before: prev,
+	})
+
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"testing"
+
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/test/assert"
+)
+
+func TestRouteIndex(t *testing.T) {
+	s, df := newTestStore()
+
+	got, err := s.Route().Index(service.RouteFilter{PathPrefix: "for/"})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 1)
+	assert.Equal(t, got[0].RouteKey, df.RouteKey1)
+
+	got, err = s.Route().Index(service.RouteFilter{Path: "for/"})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 0)
+
+	got, err = s.Route().Index(
+		service.RouteFilter{SharedRulesKey: df.SharedRulesKey2},
+		service.RouteFilter{DomainKey: df.RouteDomain1},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 2)
+}
+
+func TestRouteCreateDuplicatePath(t *testing.T) {
+	s, df := newTestStore()
+
+	_, err := s.Route().Create(df.Route1)
+	assertErrorCode(t, err, httperr.DataConstraintErrorCode)
+
+	r := df.Route1
+	r.DomainKey = df.RouteDomain2
+	got, err := s.Route().Create(r)
+	assert.Nil(t, err)
+	assert.Equal(t, got.Path, df.RoutePath1)
+}
+
+func TestRouteModifyAndDelete(t *testing.T) {
+	s, df := newTestStore()
+
+	r := df.Route1
+	r.Path = "/new"
+	got, err := s.Route().Modify(r)
+	assert.Nil(t, err)
+	assert.Equal(t, got.Path, "/new")
+
+	err = s.Route().Delete(df.RouteKey1, df.RouteChecksum1)
+	assertErrorCode(t, err, httperr.UnknownModificationConflict)
+
+	err = s.Route().Delete(df.RouteKey1, got.Checksum)
+	assert.Nil(t, err)
+}
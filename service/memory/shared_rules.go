@@ -0,0 +1,191 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+)
+
+const sharedRulesType = "shared_rules"
+
+type memSharedRules struct {
+	*store
+}
+
+func sharedRulesFilterMatches(f service.SharedRulesFilter, sr api.SharedRules) bool {
+	return (f.SharedRulesKey == "" || f.SharedRulesKey == sr.SharedRulesKey) &&
+		(f.Name == "" || f.Name == sr.Name) &&
+		(f.ZoneKey == "" || f.ZoneKey == sr.ZoneKey) &&
+		(f.OrgKey == "" || f.OrgKey == sr.OrgKey)
+}
+
+func (msr memSharedRules) Index(
+	filters ...service.SharedRulesFilter,
+) (api.SharedRulesSlice, error) {
+	msr.RLock()
+	defer msr.RUnlock()
+
+	result := api.SharedRulesSlice{}
+	for _, sr := range msr.sharedRules {
+		if len(filters) == 0 {
+			result = append(result, cloneSharedRules(sr))
+			continue
+		}
+		for _, f := range filters {
+			if sharedRulesFilterMatches(f, sr) {
+				result = append(result, cloneSharedRules(sr))
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].SharedRulesKey < result[j].SharedRulesKey
+	})
+
+	return result, nil
+}
+
+func (msr memSharedRules) Get(sharedRulesKey api.SharedRulesKey) (api.SharedRules, error) {
+	if sharedRulesKey == "" {
+		return api.SharedRules{}, keyRequired(sharedRulesType)
+	}
+
+	msr.RLock()
+	defer msr.RUnlock()
+
+	sr, ok := msr.sharedRules[sharedRulesKey]
+	if !ok {
+		return api.SharedRules{}, notFound(sharedRulesType, string(sharedRulesKey))
+	}
+
+	return cloneSharedRules(sr), nil
+}
+
+// checkUnique verifies no other SharedRules shares sr's Name and ZoneKey.
+// Callers must hold the lock.
+func (msr memSharedRules) checkUnique(sr api.SharedRules) error {
+	for _, o := range msr.sharedRules {
+		if o.SharedRulesKey != sr.SharedRulesKey && o.ZoneKey == sr.ZoneKey && o.Name == sr.Name {
+			return duplicate(
+				sharedRulesType,
+				fmt.Sprintf("name %q in zone %q", sr.Name, sr.ZoneKey),
+			)
+		}
+	}
+	return nil
+}
+
+// put validates and stores sr with a new Checksum, recording the change from
+// prev. Callers must hold the write lock.
+func (msr memSharedRules) put(
+	prev *api.SharedRules,
+	sr api.SharedRules,
+) (api.SharedRules, error) {
+	sr.Checksum = newChecksum()
+	if err := invalid(sr.IsValid()); err != nil {
+		return api.SharedRules{}, err
+	}
+	if err := msr.checkUnique(sr); err != nil {
+		return api.SharedRules{}, err
+	}
+
+	msr.sharedRules[sr.SharedRulesKey] = sr
+
+	chg := change{
+		orgKey: sr.OrgKey,
+		after:  sr,
+	}
+	if prev != nil {
+		chg.before = *prev
+	}
+	msr.record(chg)
+
+	return cloneSharedRules(sr), nil
+}
+
+// lookup returns the existing SharedRules for the given key after verifying
+// its Checksum. Callers must hold the lock.
+func (msr memSharedRules) lookup(
+	sharedRulesKey api.SharedRulesKey,
+	checksum api.Checksum,
+) (api.SharedRules, error) {
+	if sharedRulesKey == "" {
+		return api.SharedRules{}, keyRequired(sharedRulesType)
+	}
+
+	prev, ok := msr.sharedRules[sharedRulesKey]
+	if !ok {
+		return api.SharedRules{}, notFound(sharedRulesType, string(sharedRulesKey))
+	}
+
+	if !prev.Checksum.Equals(checksum) {
+		return api.SharedRules{}, checksumMismatch(sharedRulesType, string(sharedRulesKey))
+	}
+
+	return prev, nil
+}
+
+func (msr memSharedRules) Create(sharedRules api.SharedRules) (api.SharedRules, error) {
+	msr.Lock()
+	defer msr.Unlock()
+
+	sr := cloneSharedRules(sharedRules)
+	sr.SharedRulesKey = api.SharedRulesKey(newKey())
+	sr.OrgKey = msr.orgKey
+
+	return msr.put(nil, sr)
+}
+
+func (msr memSharedRules) Modify(sharedRules api.SharedRules) (api.SharedRules, error) {
+	msr.Lock()
+	defer msr.Unlock()
+
+	prev, err := msr.lookup(sharedRules.SharedRulesKey, sharedRules.Checksum)
+	if err != nil {
+		return api.SharedRules{}, err
+	}
+
+	sr := cloneSharedRules(sharedRules)
+	sr.OrgKey = prev.OrgKey
+
+	return msr.put(&prev, sr)
+}
+
+func (msr memSharedRules) Delete(
+	sharedRulesKey api.SharedRulesKey,
+	checksum api.Checksum,
+) error {
+	msr.Lock()
+	defer msr.Unlock()
+
+	prev, err := msr.lookup(sharedRulesKey, checksum)
+	if err != nil {
+		return err
+	}
+
+	delete(msr.sharedRules, sharedRulesKey)
+	msr.record(change{
+		orgKey: prev.OrgKey,
+		before: prev,
+	})
+
+	return nil
+}
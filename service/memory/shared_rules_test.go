@@ -0,0 +1,63 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"testing"
+
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/test/assert"
+)
+
+func TestSharedRulesIndex(t *testing.T) {
+	s, df := newTestStore()
+
+	got, err := s.SharedRules().Index(service.SharedRulesFilter{Name: df.SharedRulesName2})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 1)
+	assert.True(t, got[0].Equals(df.SharedRules2))
+}
+
+func TestSharedRulesCreateDuplicateName(t *testing.T) {
+	s, df := newTestStore()
+
+	_, err := s.SharedRules().Create(df.SharedRules1)
+	assertErrorCode(t, err, httperr.DataConstraintErrorCode)
+
+	sr := df.SharedRules1
+	sr.Name = "another"
+	got, err := s.SharedRules().Create(sr)
+	assert.Nil(t, err)
+	assert.NotEqual(t, got.SharedRulesKey, df.SharedRulesKey1)
+}
+
+func TestSharedRulesModifyAndDelete(t *testing.T) {
+	s, df := newTestStore()
+
+	sr := df.SharedRules1
+	sr.CohortSeed = nil
+	got, err := s.SharedRules().Modify(sr)
+	assert.Nil(t, err)
+	assert.Nil(t, got.CohortSeed)
+
+	err = s.SharedRules().Delete(df.SharedRulesKey1, got.Checksum)
+	assert.Nil(t, err)
+
+	_, err = s.SharedRules().Get(df.SharedRulesKey1)
+	assertErrorCode(t, err, httperr.NotFoundErrorCode)
+}
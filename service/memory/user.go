@@ -0,0 +1,194 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/nonstdlib/ptr"
+)
+
+const userType = "user"
+
+type memUser struct {
+	*store
+}
+
+func userFilterMatches(f service.UserFilter, u api.User) bool {
+	if !((f.UserKey == "" || f.UserKey == u.UserKey) &&
+		(f.LoginEmail == "" || f.LoginEmail == u.LoginEmail) &&
+		(f.APIAuthKey == "" || f.APIAuthKey == u.APIAuthKey) &&
+		(f.OrgKey == "" || f.OrgKey == u.OrgKey)) {
+		return false
+	}
+
+	if f.Active != nil && *f.Active != (u.DeletedAt == nil) {
+		return false
+	}
+
+	if f.DeletedBefore != nil && (u.DeletedAt == nil || !u.DeletedAt.Before(*f.DeletedBefore)) {
+		return false
+	}
+
+	if f.DeletedAfter != nil && (u.DeletedAt == nil || !u.DeletedAt.After(*f.DeletedAfter)) {
+		return false
+	}
+
+	return true
+}
+
+func (mu memUser) Index(filters ...service.UserFilter) (api.Users, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	result := api.Users{}
+	for _, u := range mu.users {
+		if len(filters) == 0 {
+			result = append(result, cloneUser(u))
+			continue
+		}
+		for _, f := range filters {
+			if userFilterMatches(f, u) {
+				result = append(result, cloneUser(u))
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].UserKey < result[j].UserKey })
+
+	return result, nil
+}
+
+func (mu memUser) Get(userKey api.UserKey) (api.User, error) {
+	if userKey == "" {
+		return api.User{}, keyRequired(userType)
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	u, ok := mu.users[userKey]
+	if !ok {
+		return api.User{}, notFound(userType, string(userKey))
+	}
+
+	return cloneUser(u), nil
+}
+
+// checkUnique verifies no other User shares u's LoginEmail. Callers must hold
+// the lock.
+func (mu memUser) checkUnique(u api.User) error {
+	for _, o := range mu.users {
+		if o.UserKey != u.UserKey && o.LoginEmail == u.LoginEmail {
+			return duplicate(userType, fmt.Sprintf("login email %q", u.LoginEmail))
+		}
+	}
+	return nil
+}
+
+// put validates and stores u with a new Checksum, recording the change from
+// prev. Callers must hold the write lock.
+func (mu memUser) put(prev *api.User, u api.User) (api.User, error) {
+	u.Checksum = newChecksum()
+	if err := invalid(u.IsValid()); err != nil {
+		return api.User{}, err
+	}
+	if err := mu.checkUnique(u); err != nil {
+		return api.User{}, err
+	}
+
+	mu.users[u.UserKey] = u
+
+	chg := change{
+		orgKey: u.OrgKey,
+		after:  u,
+	}
+	if prev != nil {
+		chg.before = *prev
+	}
+	mu.record(chg)
+
+	return cloneUser(u), nil
+}
+
+// lookup returns the existing User for the given key after verifying its
+// Checksum. Callers must hold the lock.
+func (mu memUser) lookup(userKey api.UserKey, checksum api.Checksum) (api.User, error) {
+	if userKey == "" {
+		return api.User{}, keyRequired(userType)
+	}
+
+	prev, ok := mu.users[userKey]
+	if !ok {
+		return api.User{}, notFound(userType, string(userKey))
+	}
+
+	if !prev.Checksum.Equals(checksum) {
+		return api.User{}, checksumMismatch(userType, string(userKey))
+	}
+
+	return prev, nil
+}
+
+func (mu memUser) Create(user api.User) (api.User, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	u := cloneUser(user)
+	u.UserKey = api.UserKey(newKey())
+	u.OrgKey = mu.orgKey
+	u.DeletedAt = nil
+
+	return mu.put(nil, u)
+}
+
+func (mu memUser) Modify(user api.User) (api.User, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	prev, err := mu.lookup(user.UserKey, user.Checksum)
+	if err != nil {
+		return api.User{}, err
+	}
+
+	u := cloneUser(user)
+	u.OrgKey = prev.OrgKey
+	u.DeletedAt = prev.DeletedAt
+
+	return mu.put(&prev, u)
+}
+
+// Delete marks the User as deleted rather than removing it, as documented on
+// service.User.
+func (mu memUser) Delete(userKey api.UserKey, checksum api.Checksum) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	prev, err := mu.lookup(userKey, checksum)
+	if err != nil {
+		return err
+	}
+
+	u := cloneUser(prev)
+	u.DeletedAt = ptr.Time(mu.time.Now())
+
+	_, err = mu.put(&prev, u)
+	return err
+}
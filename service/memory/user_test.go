@@ -0,0 +1,83 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/turbinelabs/api"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/nonstdlib/ptr"
+	"github.com/turbinelabs/test/assert"
+)
+
+func TestUserIndex(t *testing.T) {
+	s, df := newTestStore()
+
+	got, err := s.User().Index(service.UserFilter{Active: ptr.Bool(true)})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 1)
+	assert.Equal(t, got[0].UserKey, df.UserKey1)
+
+	got, err = s.User().Index(service.UserFilter{Active: ptr.Bool(false)})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 1)
+	assert.Equal(t, got[0].UserKey, df.UserKey2)
+
+	got, err = s.User().Index(
+		service.UserFilter{DeletedBefore: ptr.Time(df.UserDeletedAt2.Add(time.Second))},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 1)
+
+	got, err = s.User().Index(service.UserFilter{DeletedAfter: ptr.Time(*df.UserDeletedAt2)})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 0)
+
+	got, err = s.User().Index(service.UserFilter{LoginEmail: df.UserLoginEmail1})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 1)
+}
+
+func TestUserCreateDuplicateEmail(t *testing.T) {
+	s, df := newTestStore()
+
+	_, err := s.User().Create(api.User{LoginEmail: df.UserLoginEmail1})
+	assertErrorCode(t, err, httperr.DataConstraintErrorCode)
+
+	got, err := s.User().Create(api.User{LoginEmail: "new@example.com"})
+	assert.Nil(t, err)
+	assert.Equal(t, got.OrgKey, df.ValidOrgID)
+	assert.Nil(t, got.DeletedAt)
+}
+
+func TestUserDeleteMarksDeleted(t *testing.T) {
+	s, df := newTestStore()
+
+	err := s.User().Delete(df.UserKey1, df.UserChecksum1)
+	assert.Nil(t, err)
+
+	got, err := s.User().Get(df.UserKey1)
+	assert.Nil(t, err)
+	assert.NonNil(t, got.DeletedAt)
+	assert.NotEqual(t, got.Checksum, df.UserChecksum1)
+
+	err = s.User().Delete(df.UserKey1, df.UserChecksum1)
+	assertErrorCode(t, err, httperr.UnknownModificationConflict)
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+)
+
+const zoneType = "zone"
+
+type memZone struct {
+	*store
+}
+
+func zoneFilterMatches(f service.ZoneFilter, z api.Zone) bool {
+	return (f.ZoneKey == "" || f.ZoneKey == z.ZoneKey) &&
+		(f.Name == "" || f.Name == z.Name) &&
+		(f.OrgKey == "" || f.OrgKey == z.OrgKey)
+}
+
+func (mz memZone) Index(filters ...service.ZoneFilter) (api.Zones, error) {
+	mz.RLock()
+	defer mz.RUnlock()
+
+	result := api.Zones{}
+	for _, z := range mz.zones {
+		if len(filters) == 0 {
+			result = append(result, cloneZone(z))
+			continue
+		}
+		for _, f := range filters {
+			if zoneFilterMatches(f, z) {
+				result = append(result, cloneZone(z))
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ZoneKey < result[j].ZoneKey })
+
+	return result, nil
+}
+
+func (mz memZone) Get(zoneKey api.ZoneKey) (api.Zone, error) {
+	if zoneKey == "" {
+		return api.Zone{}, keyRequired(zoneType)
+	}
+
+	mz.RLock()
+	defer mz.RUnlock()
+
+	z, ok := mz.zones[zoneKey]
+	if !ok {
+		return api.Zone{}, notFound(zoneType, string(zoneKey))
+	}
+
+	return cloneZone(z), nil
+}
+
+// checkUnique verifies no other Zone in z's Org shares its Name. Callers
+// must hold the lock.
+func (mz memZone) checkUnique(z api.Zone) error {
+	for _, o := range mz.zones {
+		if o.ZoneKey != z.ZoneKey && o.OrgKey == z.OrgKey && o.Name == z.Name {
+			return duplicate(zoneType, fmt.Sprintf("name %q", z.Name))
+		}
+	}
+	return nil
+}
+
+// put validates and stores z with a new Checksum, recording the change from
+// prev. Callers must hold the write lock.
+func (mz memZone) put(prev *api.Zone, z api.Zone) (api.Zone, error) {
+	z.Checksum = newChecksum()
+	if err := invalid(z.IsValid()); err != nil {
+		return api.Zone{}, err
+	}
+	if err := mz.checkUnique(z); err != nil {
+		return api.Zone{}, err
+	}
+
+	mz.zones[z.ZoneKey] = z
+
+	chg := change{
+		orgKey: z.OrgKey,
+		after:  z,
+	}
+	if prev != nil {
+		chg.before = *prev
+	}
+	mz.record(chg)
+
+	return cloneZone(z), nil
+}
+
+// lookup returns the existing Zone for the given key after verifying its
+// Checksum. Callers must hold the lock.
+func (mz memZone) lookup(zoneKey api.ZoneKey, checksum api.Checksum) (api.Zone, error) {
+	if zoneKey == "" {
+		return api.Zone{}, keyRequired(zoneType)
+	}
+
+	prev, ok := mz.zones[zoneKey]
+	if !ok {
+		return api.Zone{}, notFound(zoneType, string(zoneKey))
+	}
+
+	if !prev.Checksum.Equals(checksum) {
+		return api.Zone{}, checksumMismatch(zoneType, string(zoneKey))
+	}
+
+	return prev, nil
+}
+
+func (mz memZone) Create(zone api.Zone) (api.Zone, error) {
+	mz.Lock()
+	defer mz.Unlock()
+
+	z := cloneZone(zone)
+	z.ZoneKey = api.ZoneKey(newKey())
+	z.OrgKey = mz.orgKey
+
+	return mz.put(nil, z)
+}
+
+func (mz memZone) Modify(zone api.Zone) (api.Zone, error) {
+	mz.Lock()
+	defer mz.Unlock()
+
+	prev, err := mz.lookup(zone.ZoneKey, zone.Checksum)
+	if err != nil {
+		return api.Zone{}, err
+	}
+
+	z := cloneZone(zone)
+	z.OrgKey = prev.OrgKey
+
+	return mz.put(&prev, z)
+}
+
+func (mz memZone) Delete(zoneKey api.ZoneKey, checksum api.Checksum) error {
+	mz.Lock()
+	defer mz.Unlock()
+
+	prev, err := mz.lookup(zoneKey, checksum)
+	if err != nil {
+		return err
+	}
+
+	delete(mz.zones, zoneKey)
+	mz.record(change{
+		orgKey: prev.OrgKey,
+		before: prev,
+	})
+
+	return nil
+}
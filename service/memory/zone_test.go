@@ -0,0 +1,63 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/turbinelabs/api"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/test/assert"
+)
+
+func TestZoneIndex(t *testing.T) {
+	s, df := newTestStore()
+
+	got, err := s.Zone().Index(service.ZoneFilter{OrgKey: df.ZoneOrgKey2})
+	assert.Nil(t, err)
+	assert.Equal(t, len(got), 1)
+	assert.True(t, got[0].Equals(df.Zone2))
+}
+
+func TestZoneCreateDuplicateName(t *testing.T) {
+	s, df := newTestStore()
+
+	_, err := s.Zone().Create(api.Zone{Name: df.ZoneName1})
+	assertErrorCode(t, err, httperr.DataConstraintErrorCode)
+
+	// zone names are unique within an org
+	_, err = s.Zone().Create(api.Zone{Name: df.ZoneName2})
+	assert.Nil(t, err)
+}
+
+func TestZoneModifyAndDelete(t *testing.T) {
+	s, df := newTestStore()
+
+	z := df.Zone1
+	z.Name = "renamed"
+	got, err := s.Zone().Modify(z)
+	assert.Nil(t, err)
+	assert.Equal(t, got.Name, "renamed")
+	assert.Equal(t, got.OrgKey, df.ZoneOrgKey1)
+
+	err = s.Zone().Delete(df.ZoneKey1, got.Checksum)
+	assert.Nil(t, err)
+
+	_, err = s.Zone().Get(df.ZoneKey1)
+	assertErrorCode(t, err, httperr.NotFoundErrorCode)
+}
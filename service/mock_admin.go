@@ -44,6 +44,18 @@ func (mr *MockAdminMockRecorder) User() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "User", reflect.TypeOf((*MockAdmin)(nil).User))
 }
 
+// Org mocks base method
+func (m *MockAdmin) Org() Org {
+	ret := m.ctrl.Call(m, "Org")
+	ret0, _ := ret[0].(Org)
+	return ret0
+}
+
+// Org indicates an expected call of Org
+func (mr *MockAdminMockRecorder) Org() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Org", reflect.TypeOf((*MockAdmin)(nil).Org))
+}
+
 // AccessToken mocks base method
 func (m *MockAdmin) AccessToken() AccessToken {
 	ret := m.ctrl.Call(m, "AccessToken")
@@ -0,0 +1,1273 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: context.go
+
+package service
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	api "github.com/turbinelabs/api"
+	changelog "github.com/turbinelabs/api/service/changelog"
+	reflect "reflect"
+	time "time"
+)
+
+// MockAllContext is a mock of AllContext interface
+type MockAllContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockAllContextMockRecorder
+}
+
+// MockAllContextMockRecorder is the mock recorder for MockAllContext
+type MockAllContextMockRecorder struct {
+	mock *MockAllContext
+}
+
+// NewMockAllContext creates a new mock instance
+func NewMockAllContext(ctrl *gomock.Controller) *MockAllContext {
+	mock := &MockAllContext{ctrl: ctrl}
+	mock.recorder = &MockAllContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAllContext) EXPECT() *MockAllContextMockRecorder {
+	return m.recorder
+}
+
+// Batch mocks base method
+func (m *MockAllContext) Batch() BatchContext {
+	ret := m.ctrl.Call(m, "Batch")
+	ret0, _ := ret[0].(BatchContext)
+	return ret0
+}
+
+// Batch indicates an expected call of Batch
+func (mr *MockAllContextMockRecorder) Batch() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Batch", reflect.TypeOf((*MockAllContext)(nil).Batch))
+}
+
+// Cluster mocks base method
+func (m *MockAllContext) Cluster() ClusterContext {
+	ret := m.ctrl.Call(m, "Cluster")
+	ret0, _ := ret[0].(ClusterContext)
+	return ret0
+}
+
+// Cluster indicates an expected call of Cluster
+func (mr *MockAllContextMockRecorder) Cluster() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cluster", reflect.TypeOf((*MockAllContext)(nil).Cluster))
+}
+
+// Domain mocks base method
+func (m *MockAllContext) Domain() DomainContext {
+	ret := m.ctrl.Call(m, "Domain")
+	ret0, _ := ret[0].(DomainContext)
+	return ret0
+}
+
+// Domain indicates an expected call of Domain
+func (mr *MockAllContextMockRecorder) Domain() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Domain", reflect.TypeOf((*MockAllContext)(nil).Domain))
+}
+
+// History mocks base method
+func (m *MockAllContext) History() HistoryContext {
+	ret := m.ctrl.Call(m, "History")
+	ret0, _ := ret[0].(HistoryContext)
+	return ret0
+}
+
+// History indicates an expected call of History
+func (mr *MockAllContextMockRecorder) History() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockAllContext)(nil).History))
+}
+
+// Listener mocks base method
+func (m *MockAllContext) Listener() ListenerContext {
+	ret := m.ctrl.Call(m, "Listener")
+	ret0, _ := ret[0].(ListenerContext)
+	return ret0
+}
+
+// Listener indicates an expected call of Listener
+func (mr *MockAllContextMockRecorder) Listener() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Listener", reflect.TypeOf((*MockAllContext)(nil).Listener))
+}
+
+// Proxy mocks base method
+func (m *MockAllContext) Proxy() ProxyContext {
+	ret := m.ctrl.Call(m, "Proxy")
+	ret0, _ := ret[0].(ProxyContext)
+	return ret0
+}
+
+// Proxy indicates an expected call of Proxy
+func (mr *MockAllContextMockRecorder) Proxy() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Proxy", reflect.TypeOf((*MockAllContext)(nil).Proxy))
+}
+
+// Route mocks base method
+func (m *MockAllContext) Route() RouteContext {
+	ret := m.ctrl.Call(m, "Route")
+	ret0, _ := ret[0].(RouteContext)
+	return ret0
+}
+
+// Route indicates an expected call of Route
+func (mr *MockAllContextMockRecorder) Route() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Route", reflect.TypeOf((*MockAllContext)(nil).Route))
+}
+
+// SharedRules mocks base method
+func (m *MockAllContext) SharedRules() SharedRulesContext {
+	ret := m.ctrl.Call(m, "SharedRules")
+	ret0, _ := ret[0].(SharedRulesContext)
+	return ret0
+}
+
+// SharedRules indicates an expected call of SharedRules
+func (mr *MockAllContextMockRecorder) SharedRules() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SharedRules", reflect.TypeOf((*MockAllContext)(nil).SharedRules))
+}
+
+// Zone mocks base method
+func (m *MockAllContext) Zone() ZoneContext {
+	ret := m.ctrl.Call(m, "Zone")
+	ret0, _ := ret[0].(ZoneContext)
+	return ret0
+}
+
+// Zone indicates an expected call of Zone
+func (mr *MockAllContextMockRecorder) Zone() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Zone", reflect.TypeOf((*MockAllContext)(nil).Zone))
+}
+
+// MockAdminContext is a mock of AdminContext interface
+type MockAdminContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminContextMockRecorder
+}
+
+// MockAdminContextMockRecorder is the mock recorder for MockAdminContext
+type MockAdminContextMockRecorder struct {
+	mock *MockAdminContext
+}
+
+// NewMockAdminContext creates a new mock instance
+func NewMockAdminContext(ctrl *gomock.Controller) *MockAdminContext {
+	mock := &MockAdminContext{ctrl: ctrl}
+	mock.recorder = &MockAdminContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAdminContext) EXPECT() *MockAdminContextMockRecorder {
+	return m.recorder
+}
+
+// AccessToken mocks base method
+func (m *MockAdminContext) AccessToken() AccessTokenContext {
+	ret := m.ctrl.Call(m, "AccessToken")
+	ret0, _ := ret[0].(AccessTokenContext)
+	return ret0
+}
+
+// AccessToken indicates an expected call of AccessToken
+func (mr *MockAdminContextMockRecorder) AccessToken() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccessToken", reflect.TypeOf((*MockAdminContext)(nil).AccessToken))
+}
+
+// Org mocks base method
+func (m *MockAdminContext) Org() OrgContext {
+	ret := m.ctrl.Call(m, "Org")
+	ret0, _ := ret[0].(OrgContext)
+	return ret0
+}
+
+// Org indicates an expected call of Org
+func (mr *MockAdminContextMockRecorder) Org() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Org", reflect.TypeOf((*MockAdminContext)(nil).Org))
+}
+
+// User mocks base method
+func (m *MockAdminContext) User() UserContext {
+	ret := m.ctrl.Call(m, "User")
+	ret0, _ := ret[0].(UserContext)
+	return ret0
+}
+
+// User indicates an expected call of User
+func (mr *MockAdminContextMockRecorder) User() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "User", reflect.TypeOf((*MockAdminContext)(nil).User))
+}
+
+// MockClusterContext is a mock of ClusterContext interface
+type MockClusterContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockClusterContextMockRecorder
+}
+
+// MockClusterContextMockRecorder is the mock recorder for MockClusterContext
+type MockClusterContextMockRecorder struct {
+	mock *MockClusterContext
+}
+
+// NewMockClusterContext creates a new mock instance
+func NewMockClusterContext(ctrl *gomock.Controller) *MockClusterContext {
+	mock := &MockClusterContext{ctrl: ctrl}
+	mock.recorder = &MockClusterContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockClusterContext) EXPECT() *MockClusterContextMockRecorder {
+	return m.recorder
+}
+
+// AddInstance mocks base method
+func (m *MockClusterContext) AddInstance(ctx context.Context, clusterKey api.ClusterKey, checksum api.Checksum, instance api.Instance) (api.Cluster, error) {
+	ret := m.ctrl.Call(m, "AddInstance", ctx, clusterKey, checksum, instance)
+	ret0, _ := ret[0].(api.Cluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddInstance indicates an expected call of AddInstance
+func (mr *MockClusterContextMockRecorder) AddInstance(ctx, clusterKey, checksum, instance interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddInstance", reflect.TypeOf((*MockClusterContext)(nil).AddInstance), ctx, clusterKey, checksum, instance)
+}
+
+// Create mocks base method
+func (m *MockClusterContext) Create(ctx context.Context, cluster api.Cluster) (api.Cluster, error) {
+	ret := m.ctrl.Call(m, "Create", ctx, cluster)
+	ret0, _ := ret[0].(api.Cluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockClusterContextMockRecorder) Create(ctx, cluster interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockClusterContext)(nil).Create), ctx, cluster)
+}
+
+// Delete mocks base method
+func (m *MockClusterContext) Delete(ctx context.Context, clusterKey api.ClusterKey, checksum api.Checksum) error {
+	ret := m.ctrl.Call(m, "Delete", ctx, clusterKey, checksum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockClusterContextMockRecorder) Delete(ctx, clusterKey, checksum interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockClusterContext)(nil).Delete), ctx, clusterKey, checksum)
+}
+
+// Get mocks base method
+func (m *MockClusterContext) Get(ctx context.Context, clusterKey api.ClusterKey) (api.Cluster, error) {
+	ret := m.ctrl.Call(m, "Get", ctx, clusterKey)
+	ret0, _ := ret[0].(api.Cluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockClusterContextMockRecorder) Get(ctx, clusterKey interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockClusterContext)(nil).Get), ctx, clusterKey)
+}
+
+// Index mocks base method
+func (m *MockClusterContext) Index(ctx context.Context, filters ...ClusterFilter) (api.Clusters, error) {
+	varargs := []interface{}{ctx}
+	for _, a := range filters {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Index", varargs...)
+	ret0, _ := ret[0].(api.Clusters)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Index indicates an expected call of Index
+func (mr *MockClusterContextMockRecorder) Index(ctx interface{}, filters ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{ctx}, filters...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Index", reflect.TypeOf((*MockClusterContext)(nil).Index), varargs...)
+}
+
+// Modify mocks base method
+func (m *MockClusterContext) Modify(ctx context.Context, cluster api.Cluster) (api.Cluster, error) {
+	ret := m.ctrl.Call(m, "Modify", ctx, cluster)
+	ret0, _ := ret[0].(api.Cluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Modify indicates an expected call of Modify
+func (mr *MockClusterContextMockRecorder) Modify(ctx, cluster interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Modify", reflect.TypeOf((*MockClusterContext)(nil).Modify), ctx, cluster)
+}
+
+// RemoveInstance mocks base method
+func (m *MockClusterContext) RemoveInstance(ctx context.Context, clusterKey api.ClusterKey, checksum api.Checksum, instance api.Instance) (api.Cluster, error) {
+	ret := m.ctrl.Call(m, "RemoveInstance", ctx, clusterKey, checksum, instance)
+	ret0, _ := ret[0].(api.Cluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveInstance indicates an expected call of RemoveInstance
+func (mr *MockClusterContextMockRecorder) RemoveInstance(ctx, clusterKey, checksum, instance interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveInstance", reflect.TypeOf((*MockClusterContext)(nil).RemoveInstance), ctx, clusterKey, checksum, instance)
+}
+
+// MockDomainContext is a mock of DomainContext interface
+type MockDomainContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockDomainContextMockRecorder
+}
+
+// MockDomainContextMockRecorder is the mock recorder for MockDomainContext
+type MockDomainContextMockRecorder struct {
+	mock *MockDomainContext
+}
+
+// NewMockDomainContext creates a new mock instance
+func NewMockDomainContext(ctrl *gomock.Controller) *MockDomainContext {
+	mock := &MockDomainContext{ctrl: ctrl}
+	mock.recorder = &MockDomainContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDomainContext) EXPECT() *MockDomainContextMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockDomainContext) Create(ctx context.Context, domain api.Domain) (api.Domain, error) {
+	ret := m.ctrl.Call(m, "Create", ctx, domain)
+	ret0, _ := ret[0].(api.Domain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockDomainContextMockRecorder) Create(ctx, domain interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDomainContext)(nil).Create), ctx, domain)
+}
+
+// Delete mocks base method
+func (m *MockDomainContext) Delete(ctx context.Context, domainKey api.DomainKey, checksum api.Checksum) error {
+	ret := m.ctrl.Call(m, "Delete", ctx, domainKey, checksum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockDomainContextMockRecorder) Delete(ctx, domainKey, checksum interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDomainContext)(nil).Delete), ctx, domainKey, checksum)
+}
+
+// Get mocks base method
+func (m *MockDomainContext) Get(ctx context.Context, domainKey api.DomainKey) (api.Domain, error) {
+	ret := m.ctrl.Call(m, "Get", ctx, domainKey)
+	ret0, _ := ret[0].(api.Domain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockDomainContextMockRecorder) Get(ctx, domainKey interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockDomainContext)(nil).Get), ctx, domainKey)
+}
+
+// Index mocks base method
+func (m *MockDomainContext) Index(ctx context.Context, filters ...DomainFilter) (api.Domains, error) {
+	varargs := []interface{}{ctx}
+	for _, a := range filters {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Index", varargs...)
+	ret0, _ := ret[0].(api.Domains)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Index indicates an expected call of Index
+func (mr *MockDomainContextMockRecorder) Index(ctx interface{}, filters ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{ctx}, filters...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Index", reflect.TypeOf((*MockDomainContext)(nil).Index), varargs...)
+}
+
+// Modify mocks base method
+func (m *MockDomainContext) Modify(ctx context.Context, domain api.Domain) (api.Domain, error) {
+	ret := m.ctrl.Call(m, "Modify", ctx, domain)
+	ret0, _ := ret[0].(api.Domain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Modify indicates an expected call of Modify
+func (mr *MockDomainContextMockRecorder) Modify(ctx, domain interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Modify", reflect.TypeOf((*MockDomainContext)(nil).Modify), ctx, domain)
+}
+
+// MockProxyContext is a mock of ProxyContext interface
+type MockProxyContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockProxyContextMockRecorder
+}
+
+// MockProxyContextMockRecorder is the mock recorder for MockProxyContext
+type MockProxyContextMockRecorder struct {
+	mock *MockProxyContext
+}
+
+// NewMockProxyContext creates a new mock instance
+func NewMockProxyContext(ctrl *gomock.Controller) *MockProxyContext {
+	mock := &MockProxyContext{ctrl: ctrl}
+	mock.recorder = &MockProxyContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockProxyContext) EXPECT() *MockProxyContextMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockProxyContext) Create(ctx context.Context, proxy api.Proxy) (api.Proxy, error) {
+	ret := m.ctrl.Call(m, "Create", ctx, proxy)
+	ret0, _ := ret[0].(api.Proxy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockProxyContextMockRecorder) Create(ctx, proxy interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockProxyContext)(nil).Create), ctx, proxy)
+}
+
+// Delete mocks base method
+func (m *MockProxyContext) Delete(ctx context.Context, proxyKey api.ProxyKey, checksum api.Checksum) error {
+	ret := m.ctrl.Call(m, "Delete", ctx, proxyKey, checksum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockProxyContextMockRecorder) Delete(ctx, proxyKey, checksum interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockProxyContext)(nil).Delete), ctx, proxyKey, checksum)
+}
+
+// Get mocks base method
+func (m *MockProxyContext) Get(ctx context.Context, proxyKey api.ProxyKey) (api.Proxy, error) {
+	ret := m.ctrl.Call(m, "Get", ctx, proxyKey)
+	ret0, _ := ret[0].(api.Proxy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockProxyContextMockRecorder) Get(ctx, proxyKey interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockProxyContext)(nil).Get), ctx, proxyKey)
+}
+
+// Index mocks base method
+func (m *MockProxyContext) Index(ctx context.Context, filters ...ProxyFilter) (api.Proxies, error) {
+	varargs := []interface{}{ctx}
+	for _, a := range filters {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Index", varargs...)
+	ret0, _ := ret[0].(api.Proxies)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Index indicates an expected call of Index
+func (mr *MockProxyContextMockRecorder) Index(ctx interface{}, filters ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{ctx}, filters...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Index", reflect.TypeOf((*MockProxyContext)(nil).Index), varargs...)
+}
+
+// Modify mocks base method
+func (m *MockProxyContext) Modify(ctx context.Context, proxy api.Proxy) (api.Proxy, error) {
+	ret := m.ctrl.Call(m, "Modify", ctx, proxy)
+	ret0, _ := ret[0].(api.Proxy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Modify indicates an expected call of Modify
+func (mr *MockProxyContextMockRecorder) Modify(ctx, proxy interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Modify", reflect.TypeOf((*MockProxyContext)(nil).Modify), ctx, proxy)
+}
+
+// MockListenerContext is a mock of ListenerContext interface
+type MockListenerContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockListenerContextMockRecorder
+}
+
+// MockListenerContextMockRecorder is the mock recorder for MockListenerContext
+type MockListenerContextMockRecorder struct {
+	mock *MockListenerContext
+}
+
+// NewMockListenerContext creates a new mock instance
+func NewMockListenerContext(ctrl *gomock.Controller) *MockListenerContext {
+	mock := &MockListenerContext{ctrl: ctrl}
+	mock.recorder = &MockListenerContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockListenerContext) EXPECT() *MockListenerContextMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockListenerContext) Create(ctx context.Context, listener api.Listener) (api.Listener, error) {
+	ret := m.ctrl.Call(m, "Create", ctx, listener)
+	ret0, _ := ret[0].(api.Listener)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockListenerContextMockRecorder) Create(ctx, listener interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockListenerContext)(nil).Create), ctx, listener)
+}
+
+// Delete mocks base method
+func (m *MockListenerContext) Delete(ctx context.Context, listenerKey api.ListenerKey, checksum api.Checksum) error {
+	ret := m.ctrl.Call(m, "Delete", ctx, listenerKey, checksum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockListenerContextMockRecorder) Delete(ctx, listenerKey, checksum interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockListenerContext)(nil).Delete), ctx, listenerKey, checksum)
+}
+
+// Get mocks base method
+func (m *MockListenerContext) Get(ctx context.Context, listenerKey api.ListenerKey) (api.Listener, error) {
+	ret := m.ctrl.Call(m, "Get", ctx, listenerKey)
+	ret0, _ := ret[0].(api.Listener)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockListenerContextMockRecorder) Get(ctx, listenerKey interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockListenerContext)(nil).Get), ctx, listenerKey)
+}
+
+// Index mocks base method
+func (m *MockListenerContext) Index(ctx context.Context, filters ...ListenerFilter) (api.Listeners, error) {
+	varargs := []interface{}{ctx}
+	for _, a := range filters {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Index", varargs...)
+	ret0, _ := ret[0].(api.Listeners)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Index indicates an expected call of Index
+func (mr *MockListenerContextMockRecorder) Index(ctx interface{}, filters ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{ctx}, filters...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Index", reflect.TypeOf((*MockListenerContext)(nil).Index), varargs...)
+}
+
+// Modify mocks base method
+func (m *MockListenerContext) Modify(ctx context.Context, listener api.Listener) (api.Listener, error) {
+	ret := m.ctrl.Call(m, "Modify", ctx, listener)
+	ret0, _ := ret[0].(api.Listener)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Modify indicates an expected call of Modify
+func (mr *MockListenerContextMockRecorder) Modify(ctx, listener interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Modify", reflect.TypeOf((*MockListenerContext)(nil).Modify), ctx, listener)
+}
+
+// MockSharedRulesContext is a mock of SharedRulesContext interface
+type MockSharedRulesContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockSharedRulesContextMockRecorder
+}
+
+// MockSharedRulesContextMockRecorder is the mock recorder for MockSharedRulesContext
+type MockSharedRulesContextMockRecorder struct {
+	mock *MockSharedRulesContext
+}
+
+// NewMockSharedRulesContext creates a new mock instance
+func NewMockSharedRulesContext(ctrl *gomock.Controller) *MockSharedRulesContext {
+	mock := &MockSharedRulesContext{ctrl: ctrl}
+	mock.recorder = &MockSharedRulesContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSharedRulesContext) EXPECT() *MockSharedRulesContextMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockSharedRulesContext) Create(ctx context.Context, sharedRules api.SharedRules) (api.SharedRules, error) {
+	ret := m.ctrl.Call(m, "Create", ctx, sharedRules)
+	ret0, _ := ret[0].(api.SharedRules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockSharedRulesContextMockRecorder) Create(ctx, sharedRules interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSharedRulesContext)(nil).Create), ctx, sharedRules)
+}
+
+// Delete mocks base method
+func (m *MockSharedRulesContext) Delete(ctx context.Context, sharedRulesKey api.SharedRulesKey, checksum api.Checksum) error {
+	ret := m.ctrl.Call(m, "Delete", ctx, sharedRulesKey, checksum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockSharedRulesContextMockRecorder) Delete(ctx, sharedRulesKey, checksum interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSharedRulesContext)(nil).Delete), ctx, sharedRulesKey, checksum)
+}
+
+// Get mocks base method
+func (m *MockSharedRulesContext) Get(ctx context.Context, sharedRulesKey api.SharedRulesKey) (api.SharedRules, error) {
+	ret := m.ctrl.Call(m, "Get", ctx, sharedRulesKey)
+	ret0, _ := ret[0].(api.SharedRules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockSharedRulesContextMockRecorder) Get(ctx, sharedRulesKey interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSharedRulesContext)(nil).Get), ctx, sharedRulesKey)
+}
+
+// Index mocks base method
+func (m *MockSharedRulesContext) Index(ctx context.Context, filters ...SharedRulesFilter) (api.SharedRulesSlice, error) {
+	varargs := []interface{}{ctx}
+	for _, a := range filters {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Index", varargs...)
+	ret0, _ := ret[0].(api.SharedRulesSlice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Index indicates an expected call of Index
+func (mr *MockSharedRulesContextMockRecorder) Index(ctx interface{}, filters ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{ctx}, filters...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Index", reflect.TypeOf((*MockSharedRulesContext)(nil).Index), varargs...)
+}
+
+// Modify mocks base method
+func (m *MockSharedRulesContext) Modify(ctx context.Context, sharedRules api.SharedRules) (api.SharedRules, error) {
+	ret := m.ctrl.Call(m, "Modify", ctx, sharedRules)
+	ret0, _ := ret[0].(api.SharedRules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Modify indicates an expected call of Modify
+func (mr *MockSharedRulesContextMockRecorder) Modify(ctx, sharedRules interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Modify", reflect.TypeOf((*MockSharedRulesContext)(nil).Modify), ctx, sharedRules)
+}
+
+// MockRouteContext is a mock of RouteContext interface
+type MockRouteContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockRouteContextMockRecorder
+}
+
+// MockRouteContextMockRecorder is the mock recorder for MockRouteContext
+type MockRouteContextMockRecorder struct {
+	mock *MockRouteContext
+}
+
+// NewMockRouteContext creates a new mock instance
+func NewMockRouteContext(ctrl *gomock.Controller) *MockRouteContext {
+	mock := &MockRouteContext{ctrl: ctrl}
+	mock.recorder = &MockRouteContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRouteContext) EXPECT() *MockRouteContextMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockRouteContext) Create(ctx context.Context, route api.Route) (api.Route, error) {
+	ret := m.ctrl.Call(m, "Create", ctx, route)
+	ret0, _ := ret[0].(api.Route)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockRouteContextMockRecorder) Create(ctx, route interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRouteContext)(nil).Create), ctx, route)
+}
+
+// Delete mocks base method
+func (m *MockRouteContext) Delete(ctx context.Context, routeKey api.RouteKey, checksum api.Checksum) error {
+	ret := m.ctrl.Call(m, "Delete", ctx, routeKey, checksum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockRouteContextMockRecorder) Delete(ctx, routeKey, checksum interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRouteContext)(nil).Delete), ctx, routeKey, checksum)
+}
+
+// Get mocks base method
+func (m *MockRouteContext) Get(ctx context.Context, routeKey api.RouteKey) (api.Route, error) {
+	ret := m.ctrl.Call(m, "Get", ctx, routeKey)
+	ret0, _ := ret[0].(api.Route)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockRouteContextMockRecorder) Get(ctx, routeKey interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRouteContext)(nil).Get), ctx, routeKey)
+}
+
+// Index mocks base method
+func (m *MockRouteContext) Index(ctx context.Context, filters ...RouteFilter) (api.Routes, error) {
+	varargs := []interface{}{ctx}
+	for _, a := range filters {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Index", varargs...)
+	ret0, _ := ret[0].(api.Routes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Index indicates an expected call of Index
+func (mr *MockRouteContextMockRecorder) Index(ctx interface{}, filters ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{ctx}, filters...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Index", reflect.TypeOf((*MockRouteContext)(nil).Index), varargs...)
+}
+
+// Modify mocks base method
+func (m *MockRouteContext) Modify(ctx context.Context, route api.Route) (api.Route, error) {
+	ret := m.ctrl.Call(m, "Modify", ctx, route)
+	ret0, _ := ret[0].(api.Route)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Modify indicates an expected call of Modify
+func (mr *MockRouteContextMockRecorder) Modify(ctx, route interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Modify", reflect.TypeOf((*MockRouteContext)(nil).Modify), ctx, route)
+}
+
+// MockZoneContext is a mock of ZoneContext interface
+type MockZoneContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockZoneContextMockRecorder
+}
+
+// MockZoneContextMockRecorder is the mock recorder for MockZoneContext
+type MockZoneContextMockRecorder struct {
+	mock *MockZoneContext
+}
+
+// NewMockZoneContext creates a new mock instance
+func NewMockZoneContext(ctrl *gomock.Controller) *MockZoneContext {
+	mock := &MockZoneContext{ctrl: ctrl}
+	mock.recorder = &MockZoneContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockZoneContext) EXPECT() *MockZoneContextMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockZoneContext) Create(ctx context.Context, zone api.Zone) (api.Zone, error) {
+	ret := m.ctrl.Call(m, "Create", ctx, zone)
+	ret0, _ := ret[0].(api.Zone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockZoneContextMockRecorder) Create(ctx, zone interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockZoneContext)(nil).Create), ctx, zone)
+}
+
+// Delete mocks base method
+func (m *MockZoneContext) Delete(ctx context.Context, zoneKey api.ZoneKey, checksum api.Checksum) error {
+	ret := m.ctrl.Call(m, "Delete", ctx, zoneKey, checksum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockZoneContextMockRecorder) Delete(ctx, zoneKey, checksum interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockZoneContext)(nil).Delete), ctx, zoneKey, checksum)
+}
+
+// Get mocks base method
+func (m *MockZoneContext) Get(ctx context.Context, zoneKey api.ZoneKey) (api.Zone, error) {
+	ret := m.ctrl.Call(m, "Get", ctx, zoneKey)
+	ret0, _ := ret[0].(api.Zone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockZoneContextMockRecorder) Get(ctx, zoneKey interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockZoneContext)(nil).Get), ctx, zoneKey)
+}
+
+// Index mocks base method
+func (m *MockZoneContext) Index(ctx context.Context, filters ...ZoneFilter) (api.Zones, error) {
+	varargs := []interface{}{ctx}
+	for _, a := range filters {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Index", varargs...)
+	ret0, _ := ret[0].(api.Zones)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Index indicates an expected call of Index
+func (mr *MockZoneContextMockRecorder) Index(ctx interface{}, filters ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{ctx}, filters...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Index", reflect.TypeOf((*MockZoneContext)(nil).Index), varargs...)
+}
+
+// Modify mocks base method
+func (m *MockZoneContext) Modify(ctx context.Context, zone api.Zone) (api.Zone, error) {
+	ret := m.ctrl.Call(m, "Modify", ctx, zone)
+	ret0, _ := ret[0].(api.Zone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Modify indicates an expected call of Modify
+func (mr *MockZoneContextMockRecorder) Modify(ctx, zone interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Modify", reflect.TypeOf((*MockZoneContext)(nil).Modify), ctx, zone)
+}
+
+// MockUserContext is a mock of UserContext interface
+type MockUserContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserContextMockRecorder
+}
+
+// MockUserContextMockRecorder is the mock recorder for MockUserContext
+type MockUserContextMockRecorder struct {
+	mock *MockUserContext
+}
+
+// NewMockUserContext creates a new mock instance
+func NewMockUserContext(ctrl *gomock.Controller) *MockUserContext {
+	mock := &MockUserContext{ctrl: ctrl}
+	mock.recorder = &MockUserContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockUserContext) EXPECT() *MockUserContextMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockUserContext) Create(ctx context.Context, user api.User) (api.User, error) {
+	ret := m.ctrl.Call(m, "Create", ctx, user)
+	ret0, _ := ret[0].(api.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockUserContextMockRecorder) Create(ctx, user interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserContext)(nil).Create), ctx, user)
+}
+
+// Delete mocks base method
+func (m *MockUserContext) Delete(ctx context.Context, userKey api.UserKey, checksum api.Checksum) error {
+	ret := m.ctrl.Call(m, "Delete", ctx, userKey, checksum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockUserContextMockRecorder) Delete(ctx, userKey, checksum interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserContext)(nil).Delete), ctx, userKey, checksum)
+}
+
+// Get mocks base method
+func (m *MockUserContext) Get(ctx context.Context, userKey api.UserKey) (api.User, error) {
+	ret := m.ctrl.Call(m, "Get", ctx, userKey)
+	ret0, _ := ret[0].(api.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockUserContextMockRecorder) Get(ctx, userKey interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUserContext)(nil).Get), ctx, userKey)
+}
+
+// Index mocks base method
+func (m *MockUserContext) Index(ctx context.Context, filters ...UserFilter) (api.Users, error) {
+	varargs := []interface{}{ctx}
+	for _, a := range filters {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Index", varargs...)
+	ret0, _ := ret[0].(api.Users)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Index indicates an expected call of Index
+func (mr *MockUserContextMockRecorder) Index(ctx interface{}, filters ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{ctx}, filters...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Index", reflect.TypeOf((*MockUserContext)(nil).Index), varargs...)
+}
+
+// Modify mocks base method
+func (m *MockUserContext) Modify(ctx context.Context, user api.User) (api.User, error) {
+	ret := m.ctrl.Call(m, "Modify", ctx, user)
+	ret0, _ := ret[0].(api.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Modify indicates an expected call of Modify
+func (mr *MockUserContextMockRecorder) Modify(ctx, user interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Modify", reflect.TypeOf((*MockUserContext)(nil).Modify), ctx, user)
+}
+
+// MockOrgContext is a mock of OrgContext interface
+type MockOrgContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrgContextMockRecorder
+}
+
+// MockOrgContextMockRecorder is the mock recorder for MockOrgContext
+type MockOrgContextMockRecorder struct {
+	mock *MockOrgContext
+}
+
+// NewMockOrgContext creates a new mock instance
+func NewMockOrgContext(ctrl *gomock.Controller) *MockOrgContext {
+	mock := &MockOrgContext{ctrl: ctrl}
+	mock.recorder = &MockOrgContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockOrgContext) EXPECT() *MockOrgContextMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockOrgContext) Create(ctx context.Context, org api.Org) (api.Org, error) {
+	ret := m.ctrl.Call(m, "Create", ctx, org)
+	ret0, _ := ret[0].(api.Org)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockOrgContextMockRecorder) Create(ctx, org interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrgContext)(nil).Create), ctx, org)
+}
+
+// Delete mocks base method
+func (m *MockOrgContext) Delete(ctx context.Context, orgKey api.OrgKey, checksum api.Checksum) error {
+	ret := m.ctrl.Call(m, "Delete", ctx, orgKey, checksum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockOrgContextMockRecorder) Delete(ctx, orgKey, checksum interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOrgContext)(nil).Delete), ctx, orgKey, checksum)
+}
+
+// Get mocks base method
+func (m *MockOrgContext) Get(ctx context.Context, orgKey api.OrgKey) (api.Org, error) {
+	ret := m.ctrl.Call(m, "Get", ctx, orgKey)
+	ret0, _ := ret[0].(api.Org)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockOrgContextMockRecorder) Get(ctx, orgKey interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOrgContext)(nil).Get), ctx, orgKey)
+}
+
+// Index mocks base method
+func (m *MockOrgContext) Index(ctx context.Context, filters ...OrgFilter) (api.Orgs, error) {
+	varargs := []interface{}{ctx}
+	for _, a := range filters {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Index", varargs...)
+	ret0, _ := ret[0].(api.Orgs)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Index indicates an expected call of Index
+func (mr *MockOrgContextMockRecorder) Index(ctx interface{}, filters ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{ctx}, filters...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Index", reflect.TypeOf((*MockOrgContext)(nil).Index), varargs...)
+}
+
+// Modify mocks base method
+func (m *MockOrgContext) Modify(ctx context.Context, org api.Org) (api.Org, error) {
+	ret := m.ctrl.Call(m, "Modify", ctx, org)
+	ret0, _ := ret[0].(api.Org)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Modify indicates an expected call of Modify
+func (mr *MockOrgContextMockRecorder) Modify(ctx, org interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Modify", reflect.TypeOf((*MockOrgContext)(nil).Modify), ctx, org)
+}
+
+// MockAccessTokenContext is a mock of AccessTokenContext interface
+type MockAccessTokenContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccessTokenContextMockRecorder
+}
+
+// MockAccessTokenContextMockRecorder is the mock recorder for MockAccessTokenContext
+type MockAccessTokenContextMockRecorder struct {
+	mock *MockAccessTokenContext
+}
+
+// NewMockAccessTokenContext creates a new mock instance
+func NewMockAccessTokenContext(ctrl *gomock.Controller) *MockAccessTokenContext {
+	mock := &MockAccessTokenContext{ctrl: ctrl}
+	mock.recorder = &MockAccessTokenContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAccessTokenContext) EXPECT() *MockAccessTokenContextMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockAccessTokenContext) Create(ctx context.Context, token api.AccessToken) (api.AccessToken, error) {
+	ret := m.ctrl.Call(m, "Create", ctx, token)
+	ret0, _ := ret[0].(api.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockAccessTokenContextMockRecorder) Create(ctx, token interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAccessTokenContext)(nil).Create), ctx, token)
+}
+
+// Delete mocks base method
+func (m *MockAccessTokenContext) Delete(ctx context.Context, key api.AccessTokenKey, checksum api.Checksum) error {
+	ret := m.ctrl.Call(m, "Delete", ctx, key, checksum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockAccessTokenContextMockRecorder) Delete(ctx, key, checksum interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAccessTokenContext)(nil).Delete), ctx, key, checksum)
+}
+
+// Get mocks base method
+func (m *MockAccessTokenContext) Get(ctx context.Context, key api.AccessTokenKey) (api.AccessToken, error) {
+	ret := m.ctrl.Call(m, "Get", ctx, key)
+	ret0, _ := ret[0].(api.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockAccessTokenContextMockRecorder) Get(ctx, key interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockAccessTokenContext)(nil).Get), ctx, key)
+}
+
+// Index mocks base method
+func (m *MockAccessTokenContext) Index(ctx context.Context, filters ...AccessTokenFilter) (api.AccessTokens, error) {
+	varargs := []interface{}{ctx}
+	for _, a := range filters {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Index", varargs...)
+	ret0, _ := ret[0].(api.AccessTokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Index indicates an expected call of Index
+func (mr *MockAccessTokenContextMockRecorder) Index(ctx interface{}, filters ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{ctx}, filters...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Index", reflect.TypeOf((*MockAccessTokenContext)(nil).Index), varargs...)
+}
+
+// MockHistoryContext is a mock of HistoryContext interface
+type MockHistoryContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistoryContextMockRecorder
+}
+
+// MockHistoryContextMockRecorder is the mock recorder for MockHistoryContext
+type MockHistoryContextMockRecorder struct {
+	mock *MockHistoryContext
+}
+
+// NewMockHistoryContext creates a new mock instance
+func NewMockHistoryContext(ctrl *gomock.Controller) *MockHistoryContext {
+	mock := &MockHistoryContext{ctrl: ctrl}
+	mock.recorder = &MockHistoryContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockHistoryContext) EXPECT() *MockHistoryContextMockRecorder {
+	return m.recorder
+}
+
+// ClusterGraph mocks base method
+func (m *MockHistoryContext) ClusterGraph(ctx context.Context, clusterKey api.ClusterKey, start, stop time.Time) ([]api.ChangeDescription, error) {
+	ret := m.ctrl.Call(m, "ClusterGraph", ctx, clusterKey, start, stop)
+	ret0, _ := ret[0].([]api.ChangeDescription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClusterGraph indicates an expected call of ClusterGraph
+func (mr *MockHistoryContextMockRecorder) ClusterGraph(ctx, clusterKey, start, stop interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClusterGraph", reflect.TypeOf((*MockHistoryContext)(nil).ClusterGraph), ctx, clusterKey, start, stop)
+}
+
+// DomainGraph mocks base method
+func (m *MockHistoryContext) DomainGraph(ctx context.Context, domainKey api.DomainKey, start, stop time.Time) ([]api.ChangeDescription, error) {
+	ret := m.ctrl.Call(m, "DomainGraph", ctx, domainKey, start, stop)
+	ret0, _ := ret[0].([]api.ChangeDescription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DomainGraph indicates an expected call of DomainGraph
+func (mr *MockHistoryContextMockRecorder) DomainGraph(ctx, domainKey, start, stop interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DomainGraph", reflect.TypeOf((*MockHistoryContext)(nil).DomainGraph), ctx, domainKey, start, stop)
+}
+
+// Index mocks base method
+func (m *MockHistoryContext) Index(ctx context.Context, filters changelog.FilterExpr, start, end time.Time) ([]api.ChangeDescription, error) {
+	ret := m.ctrl.Call(m, "Index", ctx, filters, start, end)
+	ret0, _ := ret[0].([]api.ChangeDescription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Index indicates an expected call of Index
+func (mr *MockHistoryContextMockRecorder) Index(ctx, filters, start, end interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Index", reflect.TypeOf((*MockHistoryContext)(nil).Index), ctx, filters, start, end)
+}
+
+// RouteGraph mocks base method
+func (m *MockHistoryContext) RouteGraph(ctx context.Context, routeKey api.RouteKey, start, stop time.Time) ([]api.ChangeDescription, error) {
+	ret := m.ctrl.Call(m, "RouteGraph", ctx, routeKey, start, stop)
+	ret0, _ := ret[0].([]api.ChangeDescription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RouteGraph indicates an expected call of RouteGraph
+func (mr *MockHistoryContextMockRecorder) RouteGraph(ctx, routeKey, start, stop interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RouteGraph", reflect.TypeOf((*MockHistoryContext)(nil).RouteGraph), ctx, routeKey, start, stop)
+}
+
+// SharedRulesGraph mocks base method
+func (m *MockHistoryContext) SharedRulesGraph(ctx context.Context, sharedRulesKey api.SharedRulesKey, start, stop time.Time) ([]api.ChangeDescription, error) {
+	ret := m.ctrl.Call(m, "SharedRulesGraph", ctx, sharedRulesKey, start, stop)
+	ret0, _ := ret[0].([]api.ChangeDescription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SharedRulesGraph indicates an expected call of SharedRulesGraph
+func (mr *MockHistoryContextMockRecorder) SharedRulesGraph(ctx, sharedRulesKey, start, stop interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SharedRulesGraph", reflect.TypeOf((*MockHistoryContext)(nil).SharedRulesGraph), ctx, sharedRulesKey, start, stop)
+}
+
+// Zone mocks base method
+func (m *MockHistoryContext) Zone(ctx context.Context, zoneKey api.ZoneKey, start, stop time.Time) ([]api.ChangeDescription, error) {
+	ret := m.ctrl.Call(m, "Zone", ctx, zoneKey, start, stop)
+	ret0, _ := ret[0].([]api.ChangeDescription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Zone indicates an expected call of Zone
+func (mr *MockHistoryContextMockRecorder) Zone(ctx, zoneKey, start, stop interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Zone", reflect.TypeOf((*MockHistoryContext)(nil).Zone), ctx, zoneKey, start, stop)
+}
+
+// MockBatchContext is a mock of BatchContext interface
+type MockBatchContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockBatchContextMockRecorder
+}
+
+// MockBatchContextMockRecorder is the mock recorder for MockBatchContext
+type MockBatchContextMockRecorder struct {
+	mock *MockBatchContext
+}
+
+// NewMockBatchContext creates a new mock instance
+func NewMockBatchContext(ctrl *gomock.Controller) *MockBatchContext {
+	mock := &MockBatchContext{ctrl: ctrl}
+	mock.recorder = &MockBatchContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockBatchContext) EXPECT() *MockBatchContextMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method
+func (m *MockBatchContext) Apply(ctx context.Context, ops []BatchOperation) ([]BatchResult, error) {
+	ret := m.ctrl.Call(m, "Apply", ctx, ops)
+	ret0, _ := ret[0].([]BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Apply indicates an expected call of Apply
+func (mr *MockBatchContextMockRecorder) Apply(ctx, ops interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockBatchContext)(nil).Apply), ctx, ops)
+}
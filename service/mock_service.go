@@ -128,6 +128,18 @@ func (mr *MockAllMockRecorder) History() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockAll)(nil).History))
 }
 
+// Batch mocks base method
+func (m *MockAll) Batch() Batch {
+	ret := m.ctrl.Call(m, "Batch")
+	ret0, _ := ret[0].(Batch)
+	return ret0
+}
+
+// Batch indicates an expected call of Batch
+func (mr *MockAllMockRecorder) Batch() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Batch", reflect.TypeOf((*MockAll)(nil).Batch))
+}
+
 // MockCluster is a mock of Cluster interface
 type MockCluster struct {
 	ctrl     *gomock.Controller
@@ -790,3 +802,39 @@ func (m *MockZone) Delete(zoneKey api.ZoneKey, checksum api.Checksum) error {
 func (mr *MockZoneMockRecorder) Delete(zoneKey, checksum interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockZone)(nil).Delete), zoneKey, checksum)
 }
+
+// MockBatch is a mock of Batch interface
+type MockBatch struct {
+	ctrl     *gomock.Controller
+	recorder *MockBatchMockRecorder
+}
+
+// MockBatchMockRecorder is the mock recorder for MockBatch
+type MockBatchMockRecorder struct {
+	mock *MockBatch
+}
+
+// NewMockBatch creates a new mock instance
+func NewMockBatch(ctrl *gomock.Controller) *MockBatch {
+	mock := &MockBatch{ctrl: ctrl}
+	mock.recorder = &MockBatchMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockBatch) EXPECT() *MockBatchMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method
+func (m *MockBatch) Apply(ops []BatchOperation) ([]BatchResult, error) {
+	ret := m.ctrl.Call(m, "Apply", ops)
+	ret0, _ := ret[0].([]BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Apply indicates an expected call of Apply
+func (mr *MockBatchMockRecorder) Apply(ops interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockBatch)(nil).Apply), ops)
+}
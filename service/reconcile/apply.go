@@ -0,0 +1,165 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"fmt"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+)
+
+// apply executes a single Step, recording the keys of created objects so
+// that subsequent Steps may refer to them.
+func (p *Plan) apply(svc service.All, s Step) error {
+	t := p.keys
+	t.strict = true
+
+	switch obj := s.Object.(type) {
+	case api.Zone:
+		z, err := svc.Zone().Create(obj)
+		if err != nil {
+			return err
+		}
+		t.zone = z.ZoneKey
+		return nil
+
+	case api.Cluster:
+		if s.Action == Delete {
+			return svc.Cluster().Delete(api.ClusterKey(s.Key), s.Checksum)
+		}
+
+		c := t.resolveCluster(obj)
+		c.ClusterKey = api.ClusterKey(s.Key)
+		c.Checksum = s.Checksum
+		if s.Action == Modify {
+			_, err := svc.Cluster().Modify(c)
+			return err
+		}
+
+		created, err := svc.Cluster().Create(c)
+		if err != nil {
+			return err
+		}
+		t.clusters[s.Name] = created.ClusterKey
+		return nil
+
+	case api.Domain:
+		if s.Action == Delete {
+			return svc.Domain().Delete(api.DomainKey(s.Key), s.Checksum)
+		}
+
+		d := t.resolveDomain(obj)
+		d.DomainKey = api.DomainKey(s.Key)
+		d.Checksum = s.Checksum
+		if s.Action == Modify {
+			_, err := svc.Domain().Modify(d)
+			return err
+		}
+
+		created, err := svc.Domain().Create(d)
+		if err != nil {
+			return err
+		}
+		t.domains[s.Name] = created.DomainKey
+		return nil
+
+	case api.Listener:
+		if s.Action == Delete {
+			return svc.Listener().Delete(api.ListenerKey(s.Key), s.Checksum)
+		}
+
+		l, err := t.resolveListener(obj)
+		if err != nil {
+			return err
+		}
+		l.ListenerKey = api.ListenerKey(s.Key)
+		l.Checksum = s.Checksum
+		if s.Action == Modify {
+			_, err := svc.Listener().Modify(l)
+			return err
+		}
+
+		created, err := svc.Listener().Create(l)
+		if err != nil {
+			return err
+		}
+		t.listeners[s.Name] = created.ListenerKey
+		return nil
+
+	case api.Proxy:
+		if s.Action == Delete {
+			return svc.Proxy().Delete(api.ProxyKey(s.Key), s.Checksum)
+		}
+
+		px, err := t.resolveProxy(obj)
+		if err != nil {
+			return err
+		}
+		px.ProxyKey = api.ProxyKey(s.Key)
+		px.Checksum = s.Checksum
+		if s.Action == Modify {
+			_, err = svc.Proxy().Modify(px)
+		} else {
+			_, err = svc.Proxy().Create(px)
+		}
+		return err
+
+	case api.SharedRules:
+		if s.Action == Delete {
+			return svc.SharedRules().Delete(api.SharedRulesKey(s.Key), s.Checksum)
+		}
+
+		sr, err := t.resolveSharedRules(obj)
+		if err != nil {
+			return err
+		}
+		sr.SharedRulesKey = api.SharedRulesKey(s.Key)
+		sr.Checksum = s.Checksum
+		if s.Action == Modify {
+			_, err := svc.SharedRules().Modify(sr)
+			return err
+		}
+
+		created, err := svc.SharedRules().Create(sr)
+		if err != nil {
+			return err
+		}
+		t.sharedRules[s.Name] = created.SharedRulesKey
+		return nil
+
+	case api.Route:
+		if s.Action == Delete {
+			return svc.Route().Delete(api.RouteKey(s.Key), s.Checksum)
+		}
+
+		r, err := t.resolveRoute(obj)
+		if err != nil {
+			return err
+		}
+		r.RouteKey = api.RouteKey(s.Key)
+		r.Checksum = s.Checksum
+		if s.Action == Modify {
+			_, err = svc.Route().Modify(r)
+		} else {
+			_, err = svc.Route().Create(r)
+		}
+		return err
+	}
+
+	return fmt.Errorf("unexpected object type %T", s.Object)
+}
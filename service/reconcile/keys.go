@@ -0,0 +1,305 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"fmt"
+
+	"github.com/turbinelabs/api"
+)
+
+// keyTable maps the names used by a State to the keys of the corresponding
+// objects. Objects that have yet to be created map to the empty key. If
+// strict is set, references to such objects are errors.
+type keyTable struct {
+	strict      bool
+	zone        api.ZoneKey
+	clusters    map[string]api.ClusterKey
+	domains     map[string]api.DomainKey
+	listeners   map[string]api.ListenerKey
+	sharedRules map[string]api.SharedRulesKey
+}
+
+func newKeyTable() *keyTable {
+	return &keyTable{
+		clusters:    map[string]api.ClusterKey{},
+		domains:     map[string]api.DomainKey{},
+		listeners:   map[string]api.ListenerKey{},
+		sharedRules: map[string]api.SharedRulesKey{},
+	}
+}
+
+func (t *keyTable) check(kind, name string, found, pending bool) error {
+	if !found {
+		return fmt.Errorf("unknown %s %q", kind, name)
+	}
+	if pending && t.strict {
+		return fmt.Errorf("%s %q has not been created", kind, name)
+	}
+	return nil
+}
+
+func (t *keyTable) clusterKey(name api.ClusterKey) (api.ClusterKey, error) {
+	k, ok := t.clusters[string(name)]
+	return k, t.check("cluster", string(name), ok, k == "")
+}
+
+func (t *keyTable) domainKey(name api.DomainKey) (api.DomainKey, error) {
+	k, ok := t.domains[string(name)]
+	return k, t.check("domain", string(name), ok, k == "")
+}
+
+func (t *keyTable) listenerKey(name api.ListenerKey) (api.ListenerKey, error) {
+	k, ok := t.listeners[string(name)]
+	return k, t.check("listener", string(name), ok, k == "")
+}
+
+func (t *keyTable) sharedRulesKey(name api.SharedRulesKey) (api.SharedRulesKey, error) {
+	k, ok := t.sharedRules[string(name)]
+	return k, t.check("shared_rules", string(name), ok, k == "")
+}
+
+func (t *keyTable) resolveConstraints(
+	ccs api.ClusterConstraints,
+) (api.ClusterConstraints, error) {
+	if ccs == nil {
+		return nil, nil
+	}
+
+	result := make(api.ClusterConstraints, len(ccs))
+	for i, cc := range ccs {
+		ck, err := t.clusterKey(cc.ClusterKey)
+		if err != nil {
+			return nil, err
+		}
+		cc.ClusterKey = ck
+		result[i] = cc
+	}
+	return result, nil
+}
+
+func (t *keyTable) resolveAllConstraints(ac api.AllConstraints) (api.AllConstraints, error) {
+	var err error
+	if ac.Light, err = t.resolveConstraints(ac.Light); err != nil {
+		return api.AllConstraints{}, err
+	}
+	if ac.Dark, err = t.resolveConstraints(ac.Dark); err != nil {
+		return api.AllConstraints{}, err
+	}
+	if ac.Tap, err = t.resolveConstraints(ac.Tap); err != nil {
+		return api.AllConstraints{}, err
+	}
+	return ac, nil
+}
+
+func (t *keyTable) resolveRules(rs api.Rules) (api.Rules, error) {
+	if rs == nil {
+		return nil, nil
+	}
+
+	result := make(api.Rules, len(rs))
+	for i, r := range rs {
+		ac, err := t.resolveAllConstraints(r.Constraints)
+		if err != nil {
+			return nil, fmt.Errorf("rules[%d]: %s", i, err.Error())
+		}
+		r.Constraints = ac
+		result[i] = r
+	}
+	return result, nil
+}
+
+func (t *keyTable) resolveDomainKeys(dks []api.DomainKey) ([]api.DomainKey, error) {
+	if dks == nil {
+		return nil, nil
+	}
+
+	result := make([]api.DomainKey, len(dks))
+	for i, name := range dks {
+		dk, err := t.domainKey(name)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = dk
+	}
+	return result, nil
+}
+
+func (t *keyTable) resolveCluster(c api.Cluster) api.Cluster {
+	c.ZoneKey = t.zone
+	return c
+}
+
+func (t *keyTable) resolveDomain(d api.Domain) api.Domain {
+	d.ZoneKey = t.zone
+	return d
+}
+
+func (t *keyTable) resolveListener(l api.Listener) (api.Listener, error) {
+	dks, err := t.resolveDomainKeys(l.DomainKeys)
+	if err != nil {
+		return api.Listener{}, err
+	}
+
+	l.ZoneKey = t.zone
+	l.DomainKeys = dks
+	return l, nil
+}
+
+func (t *keyTable) resolveProxy(p api.Proxy) (api.Proxy, error) {
+	dks, err := t.resolveDomainKeys(p.DomainKeys)
+	if err != nil {
+		return api.Proxy{}, err
+	}
+
+	var lks []api.ListenerKey
+	if p.ListenerKeys != nil {
+		lks = make([]api.ListenerKey, len(p.ListenerKeys))
+		for i, name := range p.ListenerKeys {
+			if lks[i], err = t.listenerKey(name); err != nil {
+				return api.Proxy{}, err
+			}
+		}
+	}
+
+	p.ZoneKey = t.zone
+	p.DomainKeys = dks
+	p.ListenerKeys = lks
+	p.Listeners = nil
+	return p, nil
+}
+
+func (t *keyTable) resolveSharedRules(sr api.SharedRules) (api.SharedRules, error) {
+	def, err := t.resolveAllConstraints(sr.Default)
+	if err != nil {
+		return api.SharedRules{}, fmt.Errorf("default: %s", err.Error())
+	}
+
+	rules, err := t.resolveRules(sr.Rules)
+	if err != nil {
+		return api.SharedRules{}, err
+	}
+
+	sr.ZoneKey = t.zone
+	sr.Default = def
+	sr.Rules = rules
+	return sr, nil
+}
+
+func (t *keyTable) resolveRoute(r api.Route) (api.Route, error) {
+	dk, err := t.domainKey(r.DomainKey)
+	if err != nil {
+		return api.Route{}, err
+	}
+
+	srk, err := t.sharedRulesKey(r.SharedRulesKey)
+	if err != nil {
+		return api.Route{}, err
+	}
+
+	rules, err := t.resolveRules(r.Rules)
+	if err != nil {
+		return api.Route{}, err
+	}
+
+	r.ZoneKey = t.zone
+	r.DomainKey = dk
+	r.SharedRulesKey = srk
+	r.Rules = rules
+	return r, nil
+}
+
+// inheritRuleKeys returns a copy of desired in which empty RuleKeys and
+// ConstraintKeys are taken from the Rule at the same position in live, if
+// any, and are otherwise generated.
+func inheritRuleKeys(desired, live api.Rules) api.Rules {
+	if desired == nil {
+		return nil
+	}
+
+	used := map[api.RuleKey]bool{}
+	for _, r := range desired {
+		used[r.RuleKey] = true
+	}
+
+	result := make(api.Rules, len(desired))
+	for i, r := range desired {
+		var liveConstraints api.AllConstraints
+		if i < len(live) {
+			liveConstraints = live[i].Constraints
+			if r.RuleKey == "" && !used[live[i].RuleKey] {
+				r.RuleKey = live[i].RuleKey
+				used[r.RuleKey] = true
+			}
+		}
+
+		for n := i; r.RuleKey == ""; n++ {
+			if k := api.RuleKey(fmt.Sprintf("rule-%d", n)); !used[k] {
+				r.RuleKey = k
+				used[k] = true
+			}
+		}
+
+		r.Constraints = inheritConstraintKeys(r.Constraints, liveConstraints)
+		result[i] = r
+	}
+	return result
+}
+
+// inheritConstraintKeys returns a copy of desired in which empty
+// ConstraintKeys are taken from the ClusterConstraint at the same position in
+// live, if any, and are otherwise generated.
+func inheritConstraintKeys(desired, live api.AllConstraints) api.AllConstraints {
+	return api.AllConstraints{
+		Light: inheritClusterConstraintKeys("light", desired.Light, live.Light),
+		Dark:  inheritClusterConstraintKeys("dark", desired.Dark, live.Dark),
+		Tap:   inheritClusterConstraintKeys("tap", desired.Tap, live.Tap),
+	}
+}
+
+func inheritClusterConstraintKeys(
+	prefix string,
+	desired api.ClusterConstraints,
+	live api.ClusterConstraints,
+) api.ClusterConstraints {
+	if desired == nil {
+		return nil
+	}
+
+	used := map[api.ConstraintKey]bool{}
+	for _, cc := range desired {
+		used[cc.ConstraintKey] = true
+	}
+
+	result := make(api.ClusterConstraints, len(desired))
+	for i, cc := range desired {
+		if cc.ConstraintKey == "" && i < len(live) && !used[live[i].ConstraintKey] {
+			cc.ConstraintKey = live[i].ConstraintKey
+			used[cc.ConstraintKey] = true
+		}
+
+		for n := i; cc.ConstraintKey == ""; n++ {
+			if k := api.ConstraintKey(fmt.Sprintf("%s-%d", prefix, n)); !used[k] {
+				cc.ConstraintKey = k
+				used[k] = true
+			}
+		}
+
+		result[i] = cc
+	}
+	return result
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"fmt"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+)
+
+// liveState holds the existing objects of a Zone, indexed by the names used
+// in a State. If the Zone does not exist, zone is nil and the maps are
+// empty.
+type liveState struct {
+	zone        *api.Zone
+	clusters    map[string]api.Cluster
+	domains     map[string]api.Domain
+	listeners   map[string]api.Listener
+	proxies     map[string]api.Proxy
+	sharedRules map[string]api.SharedRules
+	routes      map[string]api.Route
+
+	// maps existing DomainKeys to Domain names, for naming Routes
+	domainNames map[api.DomainKey]string
+}
+
+func newLiveState() *liveState {
+	return &liveState{
+		clusters:    map[string]api.Cluster{},
+		domains:     map[string]api.Domain{},
+		listeners:   map[string]api.Listener{},
+		proxies:     map[string]api.Proxy{},
+		sharedRules: map[string]api.SharedRules{},
+		routes:      map[string]api.Route{},
+		domainNames: map[api.DomainKey]string{},
+	}
+}
+
+// domainName returns the name used to identify the Domain in a State.
+func domainName(d api.Domain) string {
+	return d.Addr()
+}
+
+// routeName returns the name used to identify a Route in a State, given the
+// name of its Domain.
+func routeName(domainName string, r api.Route) string {
+	return domainName + r.Path
+}
+
+// readLive reads the existing objects of the named Zone from svc.
+func readLive(svc service.All, zoneName string) (*liveState, error) {
+	live := newLiveState()
+
+	zones, err := svc.Zone().Index(service.ZoneFilter{Name: zoneName})
+	if err != nil {
+		return nil, err
+	}
+	if len(zones) == 0 {
+		return live, nil
+	}
+	live.zone = &zones[0]
+	zk := live.zone.ZoneKey
+
+	clusters, err := svc.Cluster().Index(service.ClusterFilter{ZoneKey: zk})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range clusters {
+		live.clusters[c.Name] = c
+	}
+
+	domains, err := svc.Domain().Index(service.DomainFilter{ZoneKey: zk})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range domains {
+		live.domains[domainName(d)] = d
+		live.domainNames[d.DomainKey] = domainName(d)
+	}
+
+	listeners, err := svc.Listener().Index(service.ListenerFilter{ZoneKey: zk})
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range listeners {
+		live.listeners[l.Name] = l
+	}
+
+	proxies, err := svc.Proxy().Index(service.ProxyFilter{ZoneKey: zk})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range proxies {
+		live.proxies[p.Name] = p
+	}
+
+	sharedRules, err := svc.SharedRules().Index(service.SharedRulesFilter{ZoneKey: zk})
+	if err != nil {
+		return nil, err
+	}
+	for _, sr := range sharedRules {
+		live.sharedRules[sr.Name] = sr
+	}
+
+	routes, err := svc.Route().Index(service.RouteFilter{ZoneKey: zk})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range routes {
+		dn, ok := live.domainNames[r.DomainKey]
+		if !ok {
+			return nil, fmt.Errorf(
+				"route %s refers to domain %s, which is not in zone %q",
+				r.RouteKey,
+				r.DomainKey,
+				zoneName,
+			)
+		}
+		live.routes[routeName(dn, r)] = r
+	}
+
+	return live, nil
+}
@@ -0,0 +1,386 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/objecttype"
+)
+
+// check verifies that the State names a Zone and contains no duplicate
+// objects.
+func (s State) check() error {
+	if s.Zone.Name == "" {
+		return errors.New("zone name must be non-empty")
+	}
+
+	names := map[string]bool{}
+	dup := func(ot objecttype.ObjectType, name string) error {
+		k := ot.Name + " " + name
+		if names[k] {
+			return fmt.Errorf("duplicate %s %q", ot.Name, name)
+		}
+		names[k] = true
+		return nil
+	}
+
+	for _, c := range s.Clusters {
+		if err := dup(objecttype.Cluster, c.Name); err != nil {
+			return err
+		}
+	}
+	for _, d := range s.Domains {
+		if err := dup(objecttype.Domain, domainName(d)); err != nil {
+			return err
+		}
+	}
+	for _, l := range s.Listeners {
+		if err := dup(objecttype.Listener, l.Name); err != nil {
+			return err
+		}
+	}
+	for _, p := range s.Proxies {
+		if err := dup(objecttype.Proxy, p.Name); err != nil {
+			return err
+		}
+	}
+	for _, sr := range s.SharedRules {
+		if err := dup(objecttype.SharedRules, sr.Name); err != nil {
+			return err
+		}
+	}
+	for _, r := range s.Routes {
+		if err := dup(objecttype.Route, routeName(string(r.DomainKey), r)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *Plan) create(ot objecttype.ObjectType, name string, obj interface{}) {
+	p.Steps = append(p.Steps, Step{Action: Create, ObjectType: ot, Name: name, Object: obj})
+}
+
+func (p *Plan) modify(
+	ot objecttype.ObjectType,
+	name string,
+	key string,
+	checksum api.Checksum,
+	obj interface{},
+) {
+	p.Steps = append(
+		p.Steps,
+		Step{
+			Action:     Modify,
+			ObjectType: ot,
+			Name:       name,
+			Key:        key,
+			Checksum:   checksum,
+			Object:     obj,
+		},
+	)
+}
+
+func (p *Plan) delete(
+	ot objecttype.ObjectType,
+	name string,
+	key string,
+	checksum api.Checksum,
+	obj interface{},
+) {
+	p.Steps = append(
+		p.Steps,
+		Step{
+			Action:     Delete,
+			ObjectType: ot,
+			Name:       name,
+			Key:        key,
+			Checksum:   checksum,
+			Object:     obj,
+		},
+	)
+}
+
+func sameListenerKeys(a, b []api.ListenerKey) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	has := map[api.ListenerKey]bool{}
+	for _, lk := range a {
+		has[lk] = true
+	}
+	for _, lk := range b {
+		if !has[lk] {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedNames returns the keys of m not present in keep, sorted.
+func sortedNames(m map[string]bool, keep map[string]bool) []string {
+	names := []string{}
+	for name := range m {
+		if !keep[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newPlan(desired State, live *liveState) (*Plan, error) {
+	t := newKeyTable()
+	p := &Plan{keys: t}
+
+	if live.zone == nil {
+		z := desired.Zone
+		z.ZoneKey = ""
+		p.create(objecttype.Zone, z.Name, z)
+	} else {
+		t.zone = live.zone.ZoneKey
+	}
+
+	// register each desired object, mapping it to the key of the
+	// existing object, if any
+	wantClusters := map[string]bool{}
+	for _, c := range desired.Clusters {
+		t.clusters[c.Name] = live.clusters[c.Name].ClusterKey
+		wantClusters[c.Name] = true
+	}
+
+	wantDomains := map[string]bool{}
+	for _, d := range desired.Domains {
+		name := domainName(d)
+		t.domains[name] = live.domains[name].DomainKey
+		wantDomains[name] = true
+	}
+
+	wantListeners := map[string]bool{}
+	for _, l := range desired.Listeners {
+		t.listeners[l.Name] = live.listeners[l.Name].ListenerKey
+		wantListeners[l.Name] = true
+	}
+
+	wantProxies := map[string]bool{}
+	for _, px := range desired.Proxies {
+		wantProxies[px.Name] = true
+	}
+
+	wantSharedRules := map[string]bool{}
+	for _, sr := range desired.SharedRules {
+		t.sharedRules[sr.Name] = live.sharedRules[sr.Name].SharedRulesKey
+		wantSharedRules[sr.Name] = true
+	}
+
+	wantRoutes := map[string]bool{}
+	for _, r := range desired.Routes {
+		wantRoutes[routeName(string(r.DomainKey), r)] = true
+	}
+
+	// creates and modifies, in dependency order
+
+	for _, c := range desired.Clusters {
+		existing, ok := live.clusters[c.Name]
+		if !ok {
+			p.create(objecttype.Cluster, c.Name, c)
+			continue
+		}
+
+		want := t.resolveCluster(c)
+		want.ClusterKey = existing.ClusterKey
+		want.OrgKey = existing.OrgKey
+		want.Checksum = existing.Checksum
+		if !want.Equals(existing) {
+			p.modify(objecttype.Cluster, c.Name, string(want.ClusterKey), want.Checksum, c)
+		}
+	}
+
+	for _, d := range desired.Domains {
+		name := domainName(d)
+		existing, ok := live.domains[name]
+		if !ok {
+			p.create(objecttype.Domain, name, d)
+			continue
+		}
+
+		want := t.resolveDomain(d)
+		want.DomainKey = existing.DomainKey
+		want.OrgKey = existing.OrgKey
+		want.Checksum = existing.Checksum
+		if !want.Equals(existing) {
+			p.modify(objecttype.Domain, name, string(want.DomainKey), want.Checksum, d)
+		}
+	}
+
+	for _, l := range desired.Listeners {
+		want, err := t.resolveListener(l)
+		if err != nil {
+			return nil, fmt.Errorf("listener %q: %s", l.Name, err.Error())
+		}
+
+		existing, ok := live.listeners[l.Name]
+		if !ok {
+			p.create(objecttype.Listener, l.Name, l)
+			continue
+		}
+
+		want.ListenerKey = existing.ListenerKey
+		want.OrgKey = existing.OrgKey
+		want.Checksum = existing.Checksum
+		if !want.Equals(existing) {
+			p.modify(objecttype.Listener, l.Name, string(want.ListenerKey), want.Checksum, l)
+		}
+	}
+
+	for _, px := range desired.Proxies {
+		want, err := t.resolveProxy(px)
+		if err != nil {
+			return nil, fmt.Errorf("proxy %q: %s", px.Name, err.Error())
+		}
+
+		existing, ok := live.proxies[px.Name]
+		if !ok {
+			p.create(objecttype.Proxy, px.Name, px)
+			continue
+		}
+
+		want.ProxyKey = existing.ProxyKey
+		want.OrgKey = existing.OrgKey
+		want.Checksum = existing.Checksum
+		// Proxy.Equals does not consider ListenerKeys
+		if !want.Equals(existing) || !sameListenerKeys(want.ListenerKeys, existing.ListenerKeys) {
+			p.modify(objecttype.Proxy, px.Name, string(want.ProxyKey), want.Checksum, px)
+		}
+	}
+
+	for _, sr := range desired.SharedRules {
+		existing, ok := live.sharedRules[sr.Name]
+		sr.Default = inheritConstraintKeys(sr.Default, existing.Default)
+		sr.Rules = inheritRuleKeys(sr.Rules, existing.Rules)
+
+		want, err := t.resolveSharedRules(sr)
+		if err != nil {
+			return nil, fmt.Errorf("shared_rules %q: %s", sr.Name, err.Error())
+		}
+
+		if !ok {
+			p.create(objecttype.SharedRules, sr.Name, sr)
+			continue
+		}
+
+		want.SharedRulesKey = existing.SharedRulesKey
+		want.OrgKey = existing.OrgKey
+		want.Checksum = existing.Checksum
+		if !want.Equals(existing) {
+			p.modify(
+				objecttype.SharedRules,
+				sr.Name,
+				string(want.SharedRulesKey),
+				want.Checksum,
+				sr,
+			)
+		}
+	}
+
+	for _, r := range desired.Routes {
+		name := routeName(string(r.DomainKey), r)
+		existing, ok := live.routes[name]
+		r.Rules = inheritRuleKeys(r.Rules, existing.Rules)
+
+		want, err := t.resolveRoute(r)
+		if err != nil {
+			return nil, fmt.Errorf("route %q: %s", name, err.Error())
+		}
+
+		if !ok {
+			p.create(objecttype.Route, name, r)
+			continue
+		}
+
+		want.RouteKey = existing.RouteKey
+		want.OrgKey = existing.OrgKey
+		want.Checksum = existing.Checksum
+		if !want.Equals(existing) {
+			p.modify(objecttype.Route, name, string(want.RouteKey), want.Checksum, r)
+		}
+	}
+
+	// deletes, in reverse dependency order
+
+	liveNames := map[string]bool{}
+	for name := range live.routes {
+		liveNames[name] = true
+	}
+	for _, name := range sortedNames(liveNames, wantRoutes) {
+		r := live.routes[name]
+		p.delete(objecttype.Route, name, string(r.RouteKey), r.Checksum, r)
+	}
+
+	liveNames = map[string]bool{}
+	for name := range live.sharedRules {
+		liveNames[name] = true
+	}
+	for _, name := range sortedNames(liveNames, wantSharedRules) {
+		sr := live.sharedRules[name]
+		p.delete(objecttype.SharedRules, name, string(sr.SharedRulesKey), sr.Checksum, sr)
+	}
+
+	liveNames = map[string]bool{}
+	for name := range live.proxies {
+		liveNames[name] = true
+	}
+	for _, name := range sortedNames(liveNames, wantProxies) {
+		px := live.proxies[name]
+		p.delete(objecttype.Proxy, name, string(px.ProxyKey), px.Checksum, px)
+	}
+
+	liveNames = map[string]bool{}
+	for name := range live.listeners {
+		liveNames[name] = true
+	}
+	for _, name := range sortedNames(liveNames, wantListeners) {
+		l := live.listeners[name]
+		p.delete(objecttype.Listener, name, string(l.ListenerKey), l.Checksum, l)
+	}
+
+	liveNames = map[string]bool{}
+	for name := range live.domains {
+		liveNames[name] = true
+	}
+	for _, name := range sortedNames(liveNames, wantDomains) {
+		d := live.domains[name]
+		p.delete(objecttype.Domain, name, string(d.DomainKey), d.Checksum, d)
+	}
+
+	liveNames = map[string]bool{}
+	for name := range live.clusters {
+		liveNames[name] = true
+	}
+	for _, name := range sortedNames(liveNames, wantClusters) {
+		c := live.clusters[name]
+		p.delete(objecttype.Cluster, name, string(c.ClusterKey), c.Checksum, c)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package reconcile computes and applies the changes needed to bring the
+// objects of a zone, as seen through a service.All, in line with a desired
+// State. A Plan is computed by NewPlan, may be inspected (e.g. for a dry
+// run) via its Steps or String method, and is carried out by Apply:
+//
+// 	plan, err := reconcile.NewPlan(svc, desired)
+// 	if err != nil {
+// 		return err
+// 	}
+// 	fmt.Print(plan)
+// 	if !dryRun {
+// 		err = plan.Apply(svc)
+// 	}
+//
+// Objects in a State refer to one another by name rather than by key. See
+// State for details.
+package reconcile
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/objecttype"
+	"github.com/turbinelabs/api/service"
+)
+
+// State is the desired configuration of a single Zone. Objects are
+// identified by name, and refer to one another by name, rather than by the
+// keys assigned by the server:
+//
+//   - Clusters, Listeners, Proxies and SharedRules are identified by Name.
+//   - Domains are identified by "name:port" (see api.Domain.Addr).
+//   - Routes are identified by their DomainKey and Path.
+//   - ClusterConstraint.ClusterKey holds a Cluster name.
+//   - Route.DomainKey, Listener.DomainKeys and Proxy.DomainKeys hold Domain
+//     "name:port" values.
+//   - Route.SharedRulesKey holds a SharedRules name.
+//   - Proxy.ListenerKeys holds Listener names.
+//
+// Object keys, ZoneKeys, OrgKeys, Checksums, and Proxy.Listeners are
+// ignored. Empty RuleKeys and ConstraintKeys are inherited from the Rule or
+// ClusterConstraint at the same position in the existing object, if any,
+// and are otherwise generated.
+type State struct {
+	Zone        api.Zone
+	Clusters    api.Clusters
+	Domains     api.Domains
+	Listeners   api.Listeners
+	Proxies     api.Proxies
+	SharedRules api.SharedRulesSlice
+	Routes      api.Routes
+}
+
+// Action is the kind of change made by a Step.
+type Action string
+
+const (
+	// Create indicates a Step that creates a new object.
+	Create Action = "create"
+
+	// Modify indicates a Step that modifies an existing object.
+	Modify Action = "modify"
+
+	// Delete indicates a Step that deletes an existing object.
+	Delete Action = "delete"
+)
+
+// Step is a single change in a Plan.
+type Step struct {
+	Action     Action
+	ObjectType objecttype.ObjectType
+
+	// Name identifies the object, as described in State.
+	Name string
+
+	// Key and Checksum identify the existing object for Modify and Delete
+	// Steps.
+	Key      string
+	Checksum api.Checksum
+
+	// Object is the desired object, with references expressed by name, for
+	// Create and Modify Steps, and the existing object for Delete Steps.
+	Object interface{}
+}
+
+func (s Step) String() string {
+	if s.Key == "" {
+		return fmt.Sprintf("%s %s %s", s.Action, s.ObjectType.Name, s.Name)
+	}
+	return fmt.Sprintf("%s %s %s (%s)", s.Action, s.ObjectType.Name, s.Name, s.Key)
+}
+
+// Plan is an ordered list of Steps that bring a Zone in line with a State.
+// Creates and modifies come first, ordered such that objects are written
+// after the objects they refer to (Clusters before SharedRules before
+// Routes; Domains before Listeners before Proxies). Deletes follow, in the
+// reverse order.
+type Plan struct {
+	Steps []Step
+
+	keys *keyTable
+}
+
+// Empty returns true if the Plan has no Steps.
+func (p *Plan) Empty() bool {
+	return len(p.Steps) == 0
+}
+
+// String returns a human-readable description of the Plan, one Step per
+// line.
+func (p *Plan) String() string {
+	buf := &bytes.Buffer{}
+	for _, s := range p.Steps {
+		fmt.Fprintln(buf, s.String())
+	}
+	return buf.String()
+}
+
+// NewPlan compares the desired State with the objects of the corresponding
+// Zone in svc and returns the Plan required to reconcile them. If the Zone
+// does not exist, the Plan creates it. Returns an error if the State
+// contains duplicate objects or references to objects it does not contain.
+func NewPlan(svc service.All, desired State) (*Plan, error) {
+	if err := desired.check(); err != nil {
+		return nil, err
+	}
+
+	live, err := readLive(svc, desired.Zone.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPlan(desired, live)
+}
+
+// Apply executes the Plan's Steps in order against svc. Modify and Delete
+// Steps submit the Checksum observed when the Plan was computed, so Apply
+// fails rather than overwrite objects changed in the meantime. Apply stops
+// at the first error; Steps already executed are not rolled back. A Plan may
+// be applied at most once.
+func (p *Plan) Apply(svc service.All) error {
+	for _, s := range p.Steps {
+		if err := p.apply(svc, s); err != nil {
+			return fmt.Errorf("%s: %s", s.String(), err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/api/service/memory"
+	"github.com/turbinelabs/test/assert"
+)
+
+func testState() State {
+	return State{
+		Zone: api.Zone{Name: "the-zone"},
+		Clusters: api.Clusters{
+			{Name: "api", Instances: api.Instances{{Host: "10.0.0.1", Port: 8080}}},
+			{Name: "web"},
+		},
+		Domains: api.Domains{
+			{Name: "example.com", Port: 80},
+		},
+		Listeners: api.Listeners{
+			{Name: "http", IP: "0.0.0.0", Port: 80, Protocol: api.HttpAutoListenerProtocol},
+		},
+		Proxies: api.Proxies{
+			{
+				Name:         "the-proxy",
+				DomainKeys:   []api.DomainKey{"example.com:80"},
+				ListenerKeys: []api.ListenerKey{"http"},
+			},
+		},
+		SharedRules: api.SharedRulesSlice{
+			{
+				Name: "web-rules",
+				Default: api.AllConstraints{
+					Light: api.ClusterConstraints{{ClusterKey: "web", Weight: 1}},
+				},
+			},
+		},
+		Routes: api.Routes{
+			{
+				DomainKey:      "example.com:80",
+				Path:           "/",
+				SharedRulesKey: "web-rules",
+			},
+			{
+				DomainKey:      "example.com:80",
+				Path:           "/api",
+				SharedRulesKey: "web-rules",
+				Rules: api.Rules{
+					{
+						Methods: []string{"GET"},
+						Constraints: api.AllConstraints{
+							Light: api.ClusterConstraints{{ClusterKey: "api", Weight: 1}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func stepStrings(p *Plan) []string {
+	result := make([]string, len(p.Steps))
+	for i, s := range p.Steps {
+		result[i] = string(s.Action) + " " + s.ObjectType.Name + " " + s.Name
+	}
+	return result
+}
+
+func mustPlan(t *testing.T, svc service.All, desired State) *Plan {
+	p, err := NewPlan(svc, desired)
+	assert.Nil(t, err)
+	if p == nil {
+		t.FailNow()
+	}
+	return p
+}
+
+func TestNewPlanCreatesZone(t *testing.T) {
+	svc := memory.NewEmpty("the-org", "the-user")
+
+	p := mustPlan(t, svc, testState())
+	assert.ArrayEqual(
+		t,
+		stepStrings(p),
+		[]string{
+			"create zone the-zone",
+			"create cluster api",
+			"create cluster web",
+			"create domain example.com:80",
+			"create listener http",
+			"create proxy the-proxy",
+			"create shared_rules web-rules",
+			"create route example.com:80/",
+			"create route example.com:80/api",
+		},
+	)
+	assert.Equal(t, strings.Count(p.String(), "\n"), len(p.Steps))
+	assert.False(t, p.Empty())
+}
+
+func TestPlanApply(t *testing.T) {
+	svc := memory.NewEmpty("the-org", "the-user")
+
+	assert.Nil(t, mustPlan(t, svc, testState()).Apply(svc))
+
+	zones, err := svc.Zone().Index(service.ZoneFilter{Name: "the-zone"})
+	assert.Nil(t, err)
+	assert.Equal(t, len(zones), 1)
+	zk := zones[0].ZoneKey
+
+	clusters, err := svc.Cluster().Index(service.ClusterFilter{ZoneKey: zk, Name: "api"})
+	assert.Nil(t, err)
+	assert.Equal(t, len(clusters), 1)
+
+	domains, err := svc.Domain().Index(service.DomainFilter{ZoneKey: zk})
+	assert.Nil(t, err)
+	assert.Equal(t, len(domains), 1)
+
+	listeners, err := svc.Listener().Index(service.ListenerFilter{ZoneKey: zk})
+	assert.Nil(t, err)
+	assert.Equal(t, len(listeners), 1)
+
+	proxies, err := svc.Proxy().Index(service.ProxyFilter{ZoneKey: zk})
+	assert.Nil(t, err)
+	assert.Equal(t, len(proxies), 1)
+	assert.ArrayEqual(t, proxies[0].DomainKeys, []api.DomainKey{domains[0].DomainKey})
+	assert.ArrayEqual(t, proxies[0].ListenerKeys, []api.ListenerKey{listeners[0].ListenerKey})
+
+	routes, err := svc.Route().Index(service.RouteFilter{ZoneKey: zk, Path: "/api"})
+	assert.Nil(t, err)
+	assert.Equal(t, len(routes), 1)
+	assert.Equal(t, routes[0].DomainKey, domains[0].DomainKey)
+	assert.Equal(t, len(routes[0].Rules), 1)
+	assert.Equal(t, routes[0].Rules[0].RuleKey, api.RuleKey("rule-0"))
+	assert.Equal(
+		t,
+		routes[0].Rules[0].Constraints.Light[0].ClusterKey,
+		clusters[0].ClusterKey,
+	)
+
+	// nothing left to do
+	assert.True(t, mustPlan(t, svc, testState()).Empty())
+}
+
+func TestPlanModifyAndDelete(t *testing.T) {
+	svc := memory.NewEmpty("the-org", "the-user")
+	assert.Nil(t, mustPlan(t, svc, testState()).Apply(svc))
+
+	desired := testState()
+	desired.Clusters[0].RequireTLS = true
+	desired.Routes = desired.Routes[1:]
+	desired.Routes[0].Rules[0].Methods = []string{"GET", "POST"}
+	desired.Routes = append(
+		desired.Routes,
+		api.Route{DomainKey: "example.com:80", Path: "/static", SharedRulesKey: "web-rules"},
+	)
+
+	p := mustPlan(t, svc, desired)
+	assert.ArrayEqual(
+		t,
+		stepStrings(p),
+		[]string{
+			"modify cluster api",
+			"modify route example.com:80/api",
+			"create route example.com:80/static",
+			"delete route example.com:80/",
+		},
+	)
+	assert.Nil(t, p.Apply(svc))
+	assert.True(t, mustPlan(t, svc, desired).Empty())
+
+	// rule keys are preserved across modifications
+	routes, err := svc.Route().Index(service.RouteFilter{Path: "/api"})
+	assert.Nil(t, err)
+	assert.Equal(t, len(routes), 1)
+	assert.Equal(t, routes[0].Rules[0].RuleKey, api.RuleKey("rule-0"))
+	assert.ArrayEqual(t, routes[0].Rules[0].Methods, []string{"GET", "POST"})
+
+	// removing everything but the zone deletes in reverse dependency order
+	p = mustPlan(t, svc, State{Zone: api.Zone{Name: "the-zone"}})
+	assert.ArrayEqual(
+		t,
+		stepStrings(p),
+		[]string{
+			"delete route example.com:80/api",
+			"delete route example.com:80/static",
+			"delete shared_rules web-rules",
+			"delete proxy the-proxy",
+			"delete listener http",
+			"delete domain example.com:80",
+			"delete cluster api",
+			"delete cluster web",
+		},
+	)
+	assert.Nil(t, p.Apply(svc))
+}
+
+func TestPlanApplyChecksumConflict(t *testing.T) {
+	svc := memory.NewEmpty("the-org", "the-user")
+	assert.Nil(t, mustPlan(t, svc, testState()).Apply(svc))
+
+	desired := testState()
+	desired.Clusters[1].RequireTLS = true
+	p := mustPlan(t, svc, desired)
+
+	// concurrent modification invalidates the plan
+	clusters, err := svc.Cluster().Index(service.ClusterFilter{Name: "web"})
+	assert.Nil(t, err)
+	assert.Equal(t, len(clusters), 1)
+	clusters[0].Instances = api.Instances{{Host: "10.0.0.2", Port: 80}}
+	_, err = svc.Cluster().Modify(clusters[0])
+	assert.Nil(t, err)
+
+	err = p.Apply(svc)
+	assert.ErrorContains(t, err, "modify cluster web")
+	assert.ErrorContains(t, err, "checksum mismatch")
+}
+
+func TestNewPlanErrors(t *testing.T) {
+	svc := memory.NewEmpty("the-org", "the-user")
+
+	_, err := NewPlan(svc, State{})
+	assert.ErrorContains(t, err, "zone name must be non-empty")
+
+	desired := testState()
+	desired.Clusters = append(desired.Clusters, api.Cluster{Name: "api"})
+	_, err = NewPlan(svc, desired)
+	assert.ErrorContains(t, err, `duplicate cluster "api"`)
+
+	desired = testState()
+	desired.Routes[0].SharedRulesKey = "nope"
+	_, err = NewPlan(svc, desired)
+	assert.ErrorContains(t, err, `route "example.com:80/": unknown shared_rules "nope"`)
+
+	desired = testState()
+	desired.SharedRules[0].Default.Light[0].ClusterKey = "nope"
+	_, err = NewPlan(svc, desired)
+	assert.ErrorContains(t, err, `shared_rules "web-rules": default: unknown cluster "nope"`)
+
+	desired = testState()
+	desired.Proxies[0].ListenerKeys = []api.ListenerKey{"nope"}
+	_, err = NewPlan(svc, desired)
+	assert.ErrorContains(t, err, `proxy "the-proxy": unknown listener "nope"`)
+}
+
+func TestInheritRuleKeys(t *testing.T) {
+	live := api.Rules{
+		{
+			RuleKey: "live-1",
+			Constraints: api.AllConstraints{
+				Light: api.ClusterConstraints{{ConstraintKey: "cc-1"}},
+			},
+		},
+		{RuleKey: "live-2"},
+	}
+
+	desired := api.Rules{
+		{Constraints: api.AllConstraints{Light: api.ClusterConstraints{{}, {}}}},
+		{RuleKey: "explicit"},
+		{},
+		{RuleKey: "rule-2"},
+	}
+
+	got := inheritRuleKeys(desired, live)
+	assert.Equal(t, len(got), 4)
+	assert.Equal(t, got[0].RuleKey, api.RuleKey("live-1"))
+	assert.Equal(t, got[0].Constraints.Light[0].ConstraintKey, api.ConstraintKey("cc-1"))
+	assert.Equal(t, got[0].Constraints.Light[1].ConstraintKey, api.ConstraintKey("light-1"))
+	assert.Equal(t, got[1].RuleKey, api.RuleKey("explicit"))
+	assert.Equal(t, got[2].RuleKey, api.RuleKey("rule-3"))
+	assert.Equal(t, got[3].RuleKey, api.RuleKey("rule-2"))
+
+	// desired is not modified
+	assert.Equal(t, desired[0].RuleKey, api.RuleKey(""))
+	assert.Nil(t, inheritRuleKeys(nil, live))
+}
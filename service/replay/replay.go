@@ -0,0 +1,333 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package replay reconstructs past versions of objects from their current
+// version and the changes recorded by the changelog. Changes made after the
+// requested Point are reverted, newest first:
+//
+// 	changes, err := svc.History().RouteGraph(routeKey, start, time.Now())
+// 	if err != nil {
+// 		return err
+// 	}
+// 	route, err := replay.Route(
+// 		routeKey,
+// 		current,
+// 		replay.History{Start: start, Changes: changes},
+// 		replay.At(yesterday),
+// 	)
+//
+// Only the changes to the requested object are considered, so histories
+// covering a graph of objects may be used as-is. If the History does not
+// cover the Point, or its changes do not agree with the current version of
+// the object, a *GapError is returned.
+//
+// The Revert functions use this to restore an object to its state at a
+// given time, via Modify.
+package replay
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/diff"
+	"github.com/turbinelabs/api/objecttype"
+	tbntime "github.com/turbinelabs/nonstdlib/time"
+)
+
+// Point identifies a moment in an object's history.
+type Point struct {
+	at  time.Time
+	txn string
+}
+
+// At returns a Point including all changes made at or before t.
+func At(t time.Time) Point {
+	return Point{at: t}
+}
+
+// AfterTxn returns a Point including all changes made up to and including
+// those of the given transaction (see api.ChangeMeta).
+func AfterTxn(txn string) Point {
+	return Point{txn: txn}
+}
+
+func (p Point) String() string {
+	if p.txn != "" {
+		return fmt.Sprintf("transaction %q", p.txn)
+	}
+	return p.at.UTC().Format(time.RFC3339Nano)
+}
+
+// History is a set of changes recorded by the changelog, as returned by
+// the service.History methods.
+type History struct {
+	// Start is the beginning of the window queried for changes. Every change
+	// since Start must be present.
+	Start time.Time
+
+	// Changes are the changes made since Start, in any order.
+	Changes []api.ChangeDescription
+}
+
+// GapError is returned when a History is not sufficient to reconstruct an
+// object at a Point.
+type GapError struct {
+	// Point is the requested Point.
+	Point Point
+
+	// Reason describes what is missing.
+	Reason string
+}
+
+func (e *GapError) Error() string {
+	return fmt.Sprintf("cannot replay history to %s: %s", e.Point, e.Reason)
+}
+
+// Cluster returns the Cluster with the given key as it was at p. The
+// current version of the Cluster should be the zero value if it has been
+// deleted. A zero-value Cluster is returned if it did not exist at p.
+func Cluster(key api.ClusterKey, current api.Cluster, h History, p Point) (api.Cluster, error) {
+	result := api.Cluster{}
+	if err := replay(objecttype.Cluster, string(key), current, &result, h, p); err != nil {
+		return api.Cluster{}, err
+	}
+	if result.ClusterKey == "" {
+		return api.Cluster{}, nil
+	}
+	result.OrgKey = current.OrgKey
+	result.Checksum = current.Checksum
+	return result, nil
+}
+
+// Domain returns the Domain with the given key as it was at p. The current
+// version of the Domain should be the zero value if it has been deleted. A
+// zero-value Domain is returned if it did not exist at p.
+func Domain(key api.DomainKey, current api.Domain, h History, p Point) (api.Domain, error) {
+	result := api.Domain{}
+	if err := replay(objecttype.Domain, string(key), current, &result, h, p); err != nil {
+		return api.Domain{}, err
+	}
+	if result.DomainKey == "" {
+		return api.Domain{}, nil
+	}
+	result.OrgKey = current.OrgKey
+	result.Checksum = current.Checksum
+	return result, nil
+}
+
+// Route returns the Route with the given key as it was at p. The current
+// version of the Route should be the zero value if it has been deleted. A
+// zero-value Route is returned if it did not exist at p.
+func Route(key api.RouteKey, current api.Route, h History, p Point) (api.Route, error) {
+	result := api.Route{}
+	if err := replay(objecttype.Route, string(key), current, &result, h, p); err != nil {
+		return api.Route{}, err
+	}
+	if result.RouteKey == "" {
+		return api.Route{}, nil
+	}
+	result.OrgKey = current.OrgKey
+	result.Checksum = current.Checksum
+	return result, nil
+}
+
+// SharedRules returns the SharedRules with the given key as it was at p.
+// The current version of the SharedRules should be the zero value if it has
+// been deleted. A zero-value SharedRules is returned if it did not exist at
+// p.
+func SharedRules(
+	key api.SharedRulesKey,
+	current api.SharedRules,
+	h History,
+	p Point,
+) (api.SharedRules, error) {
+	result := api.SharedRules{}
+	if err := replay(objecttype.SharedRules, string(key), current, &result, h, p); err != nil {
+		return api.SharedRules{}, err
+	}
+	if result.SharedRulesKey == "" {
+		return api.SharedRules{}, nil
+	}
+	result.OrgKey = current.OrgKey
+	result.Checksum = current.Checksum
+	return result, nil
+}
+
+// Listener returns the Listener with the given key as it was at p. The
+// current version of the Listener should be the zero value if it has been
+// deleted. A zero-value Listener is returned if it did not exist at p.
+func Listener(key api.ListenerKey, current api.Listener, h History, p Point) (api.Listener, error) {
+	result := api.Listener{}
+	if err := replay(objecttype.Listener, string(key), current, &result, h, p); err != nil {
+		return api.Listener{}, err
+	}
+	if result.ListenerKey == "" {
+		return api.Listener{}, nil
+	}
+	result.OrgKey = current.OrgKey
+	result.Checksum = current.Checksum
+	return result, nil
+}
+
+// Proxy returns the Proxy with the given key as it was at p. The current
+// version of the Proxy should be the zero value if it has been deleted. A
+// zero-value Proxy is returned if it did not exist at p.
+func Proxy(key api.ProxyKey, current api.Proxy, h History, p Point) (api.Proxy, error) {
+	result := api.Proxy{}
+	if err := replay(objecttype.Proxy, string(key), current, &result, h, p); err != nil {
+		return api.Proxy{}, err
+	}
+	if result.ProxyKey == "" {
+		return api.Proxy{}, nil
+	}
+	result.OrgKey = current.OrgKey
+	result.Checksum = current.Checksum
+	return result, nil
+}
+
+// Zone returns the Zone with the given key as it was at p. The current
+// version of the Zone should be the zero value if it has been deleted. A
+// zero-value Zone is returned if it did not exist at p.
+func Zone(key api.ZoneKey, current api.Zone, h History, p Point) (api.Zone, error) {
+	result := api.Zone{}
+	if err := replay(objecttype.Zone, string(key), current, &result, h, p); err != nil {
+		return api.Zone{}, err
+	}
+	if result.ZoneKey == "" {
+		return api.Zone{}, nil
+	}
+	result.OrgKey = current.OrgKey
+	result.Checksum = current.Checksum
+	return result, nil
+}
+
+// User returns the User with the given key as it was at p. The current
+// version of the User should be the zero value if it has been deleted. A
+// zero-value User is returned if it did not exist at p.
+func User(key api.UserKey, current api.User, h History, p Point) (api.User, error) {
+	result := api.User{}
+	if err := replay(objecttype.User, string(key), current, &result, h, p); err != nil {
+		return api.User{}, err
+	}
+	if result.UserKey == "" {
+		return api.User{}, nil
+	}
+	result.Checksum = current.Checksum
+	return result, nil
+}
+
+// replay copies current into the object pointed to by result, and reverts
+// the changes to the identified object made after p.
+func replay(
+	ot objecttype.ObjectType,
+	key string,
+	current interface{},
+	result interface{},
+	h History,
+	p Point,
+) error {
+	changes := make([]api.ChangeDescription, len(h.Changes))
+	copy(changes, h.Changes)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].AtMs < changes[j].AtMs
+	})
+
+	first := 0
+	if p.txn != "" {
+		first = -1
+		for i, cd := range changes {
+			if cd.Txn == p.txn {
+				first = i + 1
+			}
+		}
+		if first < 0 {
+			return &GapError{Point: p, Reason: "transaction not found"}
+		}
+	} else {
+		if p.at.Before(h.Start) {
+			return &GapError{
+				Point:  p,
+				Reason: fmt.Sprintf("history starts at %s", h.Start.UTC().Format(time.RFC3339Nano)),
+			}
+		}
+		atMs := tbntime.ToUnixMilli(p.at)
+		first = sort.Search(len(changes), func(i int) bool {
+			return changes[i].AtMs > atMs
+		})
+	}
+
+	// Reverting modifies slices in place, so work on a copy.
+	rv := reflect.ValueOf(result).Elem()
+	rv.Set(deepCopy(reflect.ValueOf(current)))
+
+	for i := len(changes) - 1; i >= first; i-- {
+		diffs := changes[i].Diffs
+		for j := len(diffs) - 1; j >= 0; j-- {
+			e := diffs[j]
+			if e.ObjectType != ot || e.ObjectKey != key {
+				continue
+			}
+			if err := diff.Revert(result, e); err != nil {
+				if _, ok := err.(*diff.ConflictError); ok {
+					return &GapError{
+						Point:  p,
+						Reason: fmt.Sprintf("transaction %q: %s", changes[i].Txn, err.Error()),
+					}
+				}
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// deepCopy returns a copy of v that shares no slices or pointers with it.
+func deepCopy(v reflect.Value) reflect.Value {
+	result := reflect.New(v.Type()).Elem()
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			result.Set(reflect.New(v.Type().Elem()))
+			result.Elem().Set(deepCopy(v.Elem()))
+		}
+
+	case reflect.Slice:
+		if !v.IsNil() {
+			result.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+			for i := 0; i < v.Len(); i++ {
+				result.Index(i).Set(deepCopy(v.Index(i)))
+			}
+		}
+
+	case reflect.Struct:
+		result.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if result.Field(i).CanSet() {
+				result.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+
+	default:
+		result.Set(v)
+	}
+
+	return result
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/diff"
+	"github.com/turbinelabs/test/assert"
+)
+
+func ms(n int64) time.Time {
+	return time.Unix(0, n*int64(time.Millisecond))
+}
+
+func versions() []api.Cluster {
+	v1 := api.Cluster{
+		ClusterKey: "ck",
+		ZoneKey:    "zk",
+		Name:       "api",
+		Instances:  api.Instances{{Host: "10.0.0.1", Port: 8080}},
+	}
+
+	v2 := v1
+	v2.Instances = api.Instances{
+		{Host: "10.0.0.1", Port: 8080},
+		{Host: "10.0.0.2", Port: 8080, Metadata: api.Metadata{{Key: "stage", Value: "canary"}}},
+	}
+
+	v3 := v2
+	v3.Name = "api-v3"
+	v3.Instances = api.Instances{{Host: "10.0.0.2", Port: 8080}}
+
+	return []api.Cluster{v1, v2, v3}
+}
+
+func testHistory() History {
+	v := versions()
+	change := func(txn string, at int64, entries ...api.ChangeEntry) api.ChangeDescription {
+		return api.ChangeDescription{
+			ChangeMeta: api.ChangeMeta{Txn: txn, AtMs: at},
+			Diffs:      entries,
+		}
+	}
+
+	other := diff.Cluster(api.Cluster{ClusterKey: "other", Name: "x"}, api.Cluster{ClusterKey: "other"})
+
+	// deliberately out of order
+	return History{
+		Start: ms(0),
+		Changes: []api.ChangeDescription{
+			change("t3", 3000, diff.Cluster(v[1], v[2])...),
+			change("t1", 1000, diff.Cluster(api.Cluster{}, v[0])...),
+			change("t2", 2000, append(other, diff.Cluster(v[0], v[1])...)...),
+		},
+	}
+}
+
+func current() api.Cluster {
+	c := versions()[2]
+	c.OrgKey = "ok"
+	c.Checksum = api.Checksum{Checksum: "cs"}
+	return c
+}
+
+func TestCluster(t *testing.T) {
+	v := versions()
+	for _, tc := range []struct {
+		name string
+		p    Point
+		want api.Cluster
+	}{
+		{"before creation", At(ms(500)), api.Cluster{}},
+		{"at creation", At(ms(1000)), v[0]},
+		{"after creation", At(ms(1999)), v[0]},
+		{"after modification", At(ms(2500)), v[1]},
+		{"current", At(ms(3000)), v[2]},
+		{"after txn", AfterTxn("t1"), v[0]},
+		{"after last txn", AfterTxn("t3"), v[2]},
+	} {
+		assert.Group(tc.name, t, func(g *assert.G) {
+			got, err := Cluster("ck", current(), testHistory(), tc.p)
+			assert.Nil(g, err)
+			assert.Equal(g, len(diff.Cluster(got, tc.want)), 0)
+			if tc.want.ClusterKey == "" {
+				assert.DeepEqual(g, got, api.Cluster{})
+			} else {
+				assert.Equal(g, got.OrgKey, api.OrgKey("ok"))
+				assert.Equal(g, got.Checksum, api.Checksum{Checksum: "cs"})
+			}
+		})
+	}
+}
+
+func TestClusterDoesNotModifyCurrent(t *testing.T) {
+	c := current()
+	_, err := Cluster("ck", c, testHistory(), At(ms(1500)))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, c, current())
+}
+
+func TestClusterDeleted(t *testing.T) {
+	h := testHistory()
+	h.Changes = append(
+		h.Changes,
+		api.ChangeDescription{
+			ChangeMeta: api.ChangeMeta{Txn: "t4", AtMs: 4000},
+			Diffs:      diff.Cluster(versions()[2], api.Cluster{}),
+		},
+	)
+
+	got, err := Cluster("ck", api.Cluster{}, h, At(ms(3500)))
+	assert.Nil(t, err)
+	assert.Equal(t, len(diff.Cluster(got, versions()[2])), 0)
+}
+
+func TestClusterGaps(t *testing.T) {
+	h := testHistory()
+	h.Start = ms(1500)
+
+	_, err := Cluster("ck", current(), h, At(ms(1000)))
+	assert.ErrorContains(t, err, "history starts at 1970-01-01T00:00:01.5Z")
+	gapErr, ok := err.(*GapError)
+	assert.True(t, ok)
+	assert.Equal(t, gapErr.Point, At(ms(1000)))
+
+	_, err = Cluster("ck", current(), h, AfterTxn("t0"))
+	assert.ErrorContains(t, err, `cannot replay history to transaction "t0": transaction not found`)
+
+	// the current version includes a change missing from the history
+	c := current()
+	c.Name = "api-v4"
+	_, err = Cluster("ck", c, testHistory(), At(ms(2500)))
+	assert.ErrorContains(t, err, `transaction "t3": cluster.name: expected "api-v3", found "api-v4"`)
+	_, ok = err.(*GapError)
+	assert.True(t, ok)
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/objecttype"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/api/service/changelog"
+	"github.com/turbinelabs/api/service/update"
+)
+
+// history fetches the changes made to an object since start.
+func history(
+	svc service.History,
+	ot objecttype.ObjectType,
+	key string,
+	start time.Time,
+) (History, error) {
+	changes, err := svc.Index(
+		changelog.Filter{ObjectType: ot.Name, ObjectKey: key},
+		start,
+		time.Now(),
+	)
+	if err != nil {
+		return History{}, err
+	}
+	return History{Start: start, Changes: changes}, nil
+}
+
+func notFound(ot objecttype.ObjectType, key string, at time.Time) error {
+	return fmt.Errorf(
+		"%s %q did not exist at %s",
+		ot.Name,
+		key,
+		at.UTC().Format(time.RFC3339Nano),
+	)
+}
+
+// RevertCluster modifies the Cluster with the given key to match its state
+// at the given time. Deleted Clusters cannot be reverted, nor can Clusters
+// be reverted to a time before they were created.
+func RevertCluster(svc service.All, key api.ClusterKey, at time.Time) (api.Cluster, error) {
+	return update.Cluster(
+		svc.Cluster(),
+		key,
+		func(current api.Cluster) (api.Cluster, error) {
+			h, err := history(svc.History(), objecttype.Cluster, string(key), at)
+			if err != nil {
+				return api.Cluster{}, err
+			}
+			c, err := Cluster(key, current, h, At(at))
+			if err == nil && c.ClusterKey == "" {
+				err = notFound(objecttype.Cluster, string(key), at)
+			}
+			return c, err
+		},
+	)
+}
+
+// RevertDomain modifies the Domain with the given key to match its state at
+// the given time. Deleted Domains cannot be reverted, nor can Domains be
+// reverted to a time before they were created.
+func RevertDomain(svc service.All, key api.DomainKey, at time.Time) (api.Domain, error) {
+	return update.Domain(
+		svc.Domain(),
+		key,
+		func(current api.Domain) (api.Domain, error) {
+			h, err := history(svc.History(), objecttype.Domain, string(key), at)
+			if err != nil {
+				return api.Domain{}, err
+			}
+			d, err := Domain(key, current, h, At(at))
+			if err == nil && d.DomainKey == "" {
+				err = notFound(objecttype.Domain, string(key), at)
+			}
+			return d, err
+		},
+	)
+}
+
+// RevertRoute modifies the Route with the given key to match its state at
+// the given time. Deleted Routes cannot be reverted, nor can Routes be
+// reverted to a time before they were created.
+func RevertRoute(svc service.All, key api.RouteKey, at time.Time) (api.Route, error) {
+	return update.Route(
+		svc.Route(),
+		key,
+		func(current api.Route) (api.Route, error) {
+			h, err := history(svc.History(), objecttype.Route, string(key), at)
+			if err != nil {
+				return api.Route{}, err
+			}
+			r, err := Route(key, current, h, At(at))
+			if err == nil && r.RouteKey == "" {
+				err = notFound(objecttype.Route, string(key), at)
+			}
+			return r, err
+		},
+	)
+}
+
+// RevertSharedRules modifies the SharedRules with the given key to match
+// its state at the given time. Deleted SharedRules cannot be reverted, nor
+// can SharedRules be reverted to a time before they were created.
+func RevertSharedRules(
+	svc service.All,
+	key api.SharedRulesKey,
+	at time.Time,
+) (api.SharedRules, error) {
+	return update.SharedRules(
+		svc.SharedRules(),
+		key,
+		func(current api.SharedRules) (api.SharedRules, error) {
+			h, err := history(svc.History(), objecttype.SharedRules, string(key), at)
+			if err != nil {
+				return api.SharedRules{}, err
+			}
+			sr, err := SharedRules(key, current, h, At(at))
+			if err == nil && sr.SharedRulesKey == "" {
+				err = notFound(objecttype.SharedRules, string(key), at)
+			}
+			return sr, err
+		},
+	)
+}
+
+// RevertListener modifies the Listener with the given key to match its
+// state at the given time. Deleted Listeners cannot be reverted, nor can
+// Listeners be reverted to a time before they were created.
+func RevertListener(svc service.All, key api.ListenerKey, at time.Time) (api.Listener, error) {
+	return update.Listener(
+		svc.Listener(),
+		key,
+		func(current api.Listener) (api.Listener, error) {
+			h, err := history(svc.History(), objecttype.Listener, string(key), at)
+			if err != nil {
+				return api.Listener{}, err
+			}
+			l, err := Listener(key, current, h, At(at))
+			if err == nil && l.ListenerKey == "" {
+				err = notFound(objecttype.Listener, string(key), at)
+			}
+			return l, err
+		},
+	)
+}
+
+// RevertProxy modifies the Proxy with the given key to match its state at
+// the given time. Deleted Proxies cannot be reverted, nor can Proxies be
+// reverted to a time before they were created.
+func RevertProxy(svc service.All, key api.ProxyKey, at time.Time) (api.Proxy, error) {
+	return update.Proxy(
+		svc.Proxy(),
+		key,
+		func(current api.Proxy) (api.Proxy, error) {
+			h, err := history(svc.History(), objecttype.Proxy, string(key), at)
+			if err != nil {
+				return api.Proxy{}, err
+			}
+			p, err := Proxy(key, current, h, At(at))
+			if err == nil && p.ProxyKey == "" {
+				err = notFound(objecttype.Proxy, string(key), at)
+			}
+			return p, err
+		},
+	)
+}
+
+// RevertZone modifies the Zone with the given key to match its state at the
+// given time. Deleted Zones cannot be reverted, nor can Zones be reverted to
+// a time before they were created.
+func RevertZone(svc service.All, key api.ZoneKey, at time.Time) (api.Zone, error) {
+	return update.Zone(
+		svc.Zone(),
+		key,
+		func(current api.Zone) (api.Zone, error) {
+			h, err := history(svc.History(), objecttype.Zone, string(key), at)
+			if err != nil {
+				return api.Zone{}, err
+			}
+			z, err := Zone(key, current, h, At(at))
+			if err == nil && z.ZoneKey == "" {
+				err = notFound(objecttype.Zone, string(key), at)
+			}
+			return z, err
+		},
+	)
+}
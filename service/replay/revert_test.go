@@ -0,0 +1,95 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/api/service/changelog"
+	"github.com/turbinelabs/api/service/memory"
+	"github.com/turbinelabs/test/assert"
+)
+
+// sleep ensures that subsequent changes are recorded with a later
+// millisecond timestamp.
+func sleep() {
+	time.Sleep(5 * time.Millisecond)
+}
+
+func TestRevertCluster(t *testing.T) {
+	svc := memory.NewEmpty("ok", "uk")
+	z, err := svc.Zone().Create(api.Zone{Name: "z"})
+	assert.Nil(t, err)
+
+	sleep()
+	before := time.Now()
+	sleep()
+
+	c, err := svc.Cluster().Create(api.Cluster{ZoneKey: z.ZoneKey, Name: "c1"})
+	assert.Nil(t, err)
+
+	sleep()
+	created := time.Now()
+	sleep()
+
+	c.Name = "c2"
+	c.RequireTLS = true
+	_, err = svc.Cluster().Modify(c)
+	assert.Nil(t, err)
+
+	sleep()
+
+	reverted, err := RevertCluster(svc, c.ClusterKey, created)
+	assert.Nil(t, err)
+	assert.Equal(t, reverted.Name, "c1")
+	assert.False(t, reverted.RequireTLS)
+
+	got, err := svc.Cluster().Get(c.ClusterKey)
+	assert.Nil(t, err)
+	assert.True(t, got.Equals(reverted))
+
+	_, err = RevertCluster(svc, c.ClusterKey, before)
+	assert.ErrorContains(t, err, `cluster "`+string(c.ClusterKey)+`" did not exist at`)
+}
+
+func TestRevertClusterHistoryError(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	at := time.Now().Add(-time.Hour)
+	c := api.Cluster{ClusterKey: "ck", Name: "c"}
+	boom := errors.New("boom")
+
+	svc := service.NewMockAll(ctrl)
+	clusterSvc := service.NewMockCluster(ctrl)
+	historySvc := service.NewMockHistory(ctrl)
+
+	svc.EXPECT().Cluster().Return(clusterSvc)
+	svc.EXPECT().History().Return(historySvc)
+	clusterSvc.EXPECT().Get(api.ClusterKey("ck")).Return(c, nil)
+	historySvc.EXPECT().
+		Index(changelog.Filter{ObjectType: "cluster", ObjectKey: "ck"}, at, gomock.Any()).
+		Return(nil, boom)
+
+	_, err := RevertCluster(svc, "ck", at)
+	assert.Equal(t, err, boom)
+}
@@ -0,0 +1,727 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rollout progressively shifts traffic from one ClusterConstraint to
+// another, in scheduled steps:
+//
+// 	r, err := rollout.Start(
+// 		ctx,
+// 		svc,
+// 		statsSvc,
+// 		rollout.Plan{
+// 			SharedRulesKey: "web-rules",
+// 			From:           "stable",
+// 			To:             "canary",
+// 			Steps:          []float64{1, 5, 25, 100},
+// 			Interval:       10 * time.Minute,
+// 			Checks: []rollout.Check{
+// 				{QueryType: querytype.SuccessRate, Filter: filter, Min: ptr.Float64(0.99)},
+// 			},
+// 		},
+// 		rollout.Options{},
+// 	)
+// 	...
+// 	<-r.Done()
+// 	if status := r.Status(); status.State != rollout.Completed {
+// 		...
+// 	}
+//
+// Each step gives the To constraint a percentage of the combined weight that
+// the From and To constraints had when the rollout started, so the share of
+// traffic sent to other constraints is unchanged. At 100%, the From
+// constraint is removed. Steps are applied via Modify, retrying on checksum
+// conflicts.
+//
+// An Interval after each step, the Checks are evaluated against the stats
+// recorded since the step was applied. If any is breached, or too few points
+// are recorded to evaluate it (e.g. because the To constraint receives no
+// traffic), the From and To constraints are restored to their original weights. Otherwise, the next
+// step is applied, or, after the final step, the rollout completes.
+package rollout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+	statsapi "github.com/turbinelabs/api/service/stats/v2"
+	"github.com/turbinelabs/api/service/stats/v2/querytype"
+	"github.com/turbinelabs/api/service/stats/v2/timegranularity"
+	"github.com/turbinelabs/api/service/update"
+	"github.com/turbinelabs/nonstdlib/ptr"
+	tbntime "github.com/turbinelabs/nonstdlib/time"
+)
+
+// State describes the progress of a Rollout.
+type State string
+
+const (
+	// Running indicates that steps are being applied.
+	Running State = "running"
+
+	// Paused indicates that Pause was called. No steps are applied or
+	// checked until Resume is called.
+	Paused State = "paused"
+
+	// Completed indicates that the final step was applied and its checks
+	// passed.
+	Completed State = "completed"
+
+	// RolledBack indicates that a check was breached, and the original
+	// weights were restored.
+	RolledBack State = "rolled_back"
+
+	// Aborted indicates that Abort was called, and the original weights were
+	// restored.
+	Aborted State = "aborted"
+
+	// Failed indicates that the Rollout stopped because of an error. The
+	// weights are left as they were when the error occurred.
+	Failed State = "failed"
+)
+
+// Plan describes a Rollout.
+type Plan struct {
+	// SharedRulesKey identifies the SharedRules to modify. Exactly one of
+	// SharedRulesKey and RouteKey must be set.
+	SharedRulesKey api.SharedRulesKey
+
+	// RouteKey identifies the Route to modify. Exactly one of SharedRulesKey
+	// and RouteKey must be set.
+	RouteKey api.RouteKey
+
+	// RuleKey identifies the Rule whose Light ClusterConstraints are
+	// modified. If empty, the SharedRules' Default constraints are modified.
+	// Required for Routes.
+	RuleKey api.RuleKey
+
+	// From is the ClusterConstraint from which traffic is moved.
+	From api.ConstraintKey
+
+	// To is the ClusterConstraint to which traffic is moved.
+	To api.ConstraintKey
+
+	// Steps are the percentages of traffic given to To at each step. They
+	// must increase, and lie in (0, 100].
+	Steps []float64
+
+	// Interval is the time between steps.
+	Interval time.Duration
+
+	// Checks are evaluated an Interval after each step.
+	Checks []Check
+}
+
+// Check is a threshold on a stat, evaluated as the mean of the points
+// returned by the stats API. A Check with fewer than MinPoints points is
+// breached.
+type Check struct {
+	// QueryType is the stat to query, e.g. querytype.SuccessRate or
+	// querytype.LatencyP99.
+	QueryType querytype.QueryType
+
+	// Filter selects the traffic whose stats are queried, typically that
+	// sent to the To constraint.
+	Filter statsapi.QueryFilter
+
+	// Min, if set, is the smallest acceptable value.
+	Min *float64
+
+	// Max, if set, is the largest acceptable value.
+	Max *float64
+
+	// MinPoints is the number of points required to evaluate the Check. If
+	// zero, one point is required.
+	MinPoints int
+}
+
+func (c Check) String() string {
+	return c.QueryType.String()
+}
+
+func (c Check) minPoints() int {
+	if c.MinPoints <= 0 {
+		return 1
+	}
+	return c.MinPoints
+}
+
+// ThresholdError is the Status Err of a Rollout that was rolled back
+// because a Check was breached.
+type ThresholdError struct {
+	// Check is the breached Check.
+	Check Check
+
+	// Value is the value of the stat, or zero if too few points were
+	// returned to evaluate the Check.
+	Value float64
+
+	// Points is the number of points returned.
+	Points int
+}
+
+func (e *ThresholdError) Error() string {
+	if e.Points < e.Check.minPoints() {
+		return fmt.Sprintf(
+			"%s has %d points, fewer than the required %d",
+			e.Check,
+			e.Points,
+			e.Check.minPoints(),
+		)
+	}
+	if e.Check.Min != nil && e.Value < *e.Check.Min {
+		return fmt.Sprintf("%s of %g is below minimum %g", e.Check, e.Value, *e.Check.Min)
+	}
+	return fmt.Sprintf("%s of %g exceeds maximum %g", e.Check, e.Value, *e.Check.Max)
+}
+
+// Options configure a Rollout.
+type Options struct {
+	// Time is the source of the current time. If nil, the system clock is
+	// used.
+	Time tbntime.Source
+
+	// MaxAttempts is the number of attempts made at each modification when
+	// checksums conflict. If zero, update.DefaultMaxAttempts is used.
+	MaxAttempts int
+}
+
+// Status describes the progress of a Rollout.
+type Status struct {
+	// State is the Rollout's State.
+	State State
+
+	// Step is the index of the most recently applied step, or -1 if none has
+	// been applied.
+	Step int
+
+	// Percent is the percentage of traffic given to To.
+	Percent float64
+
+	// Err is the error that ended the Rollout, if any.
+	Err error
+}
+
+type command int
+
+const (
+	pauseCommand command = iota
+	resumeCommand
+	abortCommand
+)
+
+// Rollout is a running rollout. See the package documentation.
+type Rollout struct {
+	svc      service.All
+	stats    statsapi.StatsQueryService
+	plan     Plan
+	options  Options
+	updater  update.Updater
+	from     api.ClusterConstraint
+	to       api.ClusterConstraint
+	total    uint32
+	commands chan command
+	acks     chan struct{}
+	done     chan struct{}
+
+	// appliedAt is the time at which the current step was applied.
+	appliedAt time.Time
+
+	mu     sync.Mutex
+	status Status
+}
+
+// Start validates the Plan against the current SharedRules or Route and
+// starts applying its steps, beginning immediately. The Rollout stops when
+// ctx is cancelled, leaving the weights unchanged. If the Plan has Checks,
+// stats must not be nil.
+func Start(
+	ctx context.Context,
+	svc service.All,
+	stats statsapi.StatsQueryService,
+	plan Plan,
+	options Options,
+) (*Rollout, error) {
+	if err := plan.validate(stats); err != nil {
+		return nil, err
+	}
+
+	if options.Time == nil {
+		options.Time = tbntime.NewSource()
+	}
+	if options.MaxAttempts == 0 {
+		options.MaxAttempts = update.DefaultMaxAttempts
+	}
+
+	r := &Rollout{
+		svc:      svc,
+		stats:    stats,
+		plan:     plan,
+		options:  options,
+		updater:  update.New(options.MaxAttempts),
+		commands: make(chan command),
+		acks:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	constraints, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+
+	fromIdx, toIdx, err := r.find(constraints)
+	if err != nil {
+		return nil, err
+	}
+	if fromIdx < 0 {
+		return nil, fmt.Errorf("constraint %q does not exist", plan.From)
+	}
+
+	r.from = constraints.Light[fromIdx]
+	r.to = constraints.Light[toIdx]
+	r.total = r.from.Weight + r.to.Weight
+	r.status = Status{
+		State:   Running,
+		Step:    -1,
+		Percent: 100 * float64(r.to.Weight) / float64(r.total),
+	}
+
+	go r.run(ctx)
+	return r, nil
+}
+
+func (p Plan) validate(stats statsapi.StatsQueryService) error {
+	switch {
+	case p.SharedRulesKey == "" && p.RouteKey == "":
+		return errors.New("one of SharedRulesKey and RouteKey must be set")
+	case p.SharedRulesKey != "" && p.RouteKey != "":
+		return errors.New("only one of SharedRulesKey and RouteKey may be set")
+	case p.RouteKey != "" && p.RuleKey == "":
+		return errors.New("RuleKey must be set for Routes")
+	case p.From == "" || p.To == "":
+		return errors.New("From and To must be set")
+	case p.From == p.To:
+		return errors.New("From and To must differ")
+	case len(p.Steps) == 0:
+		return errors.New("at least one step is required")
+	case p.Interval <= 0:
+		return errors.New("Interval must be positive")
+	case len(p.Checks) > 0 && stats == nil:
+		return errors.New("a StatsQueryService is required for Checks")
+	}
+
+	prev := 0.0
+	for i, s := range p.Steps {
+		if s <= prev || s > 100 {
+			return fmt.Errorf("step %d: %g must be greater than %g and at most 100", i, s, prev)
+		}
+		prev = s
+	}
+
+	for i, c := range p.Checks {
+		if !querytype.IsValid(c.QueryType) || c.QueryType == querytype.Unknown {
+			return fmt.Errorf("check %d: invalid query type", i)
+		}
+		if c.Min == nil && c.Max == nil {
+			return fmt.Errorf("check %d: one of Min and Max must be set", i)
+		}
+		if c.MinPoints < 0 {
+			return fmt.Errorf("check %d: MinPoints must not be negative", i)
+		}
+	}
+
+	return nil
+}
+
+// Pause stops applying steps until Resume is called. Pause, Resume, and
+// Abort return once the command has been applied, and have no effect once
+// the Rollout has stopped.
+func (r *Rollout) Pause() {
+	r.send(pauseCommand)
+}
+
+// Resume continues a paused Rollout. The current step's checks are
+// evaluated an Interval after Resume is called.
+func (r *Rollout) Resume() {
+	r.send(resumeCommand)
+}
+
+// Abort stops the Rollout and restores the original weights. Once Done is
+// closed, the Status reports whether the weights were restored.
+func (r *Rollout) Abort() {
+	r.send(abortCommand)
+}
+
+func (r *Rollout) send(c command) {
+	select {
+	case r.commands <- c:
+	case <-r.done:
+		return
+	}
+
+	select {
+	case <-r.acks:
+	case <-r.done:
+	}
+}
+
+// Done returns a channel that is closed when the Rollout stops.
+func (r *Rollout) Done() <-chan struct{} {
+	return r.done
+}
+
+// Status returns the Rollout's current Status.
+func (r *Rollout) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+func (r *Rollout) setStatus(f func(*Status)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f(&r.status)
+}
+
+func (r *Rollout) run(ctx context.Context) {
+	defer close(r.done)
+
+	timer := r.options.Time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.setStatus(func(s *Status) {
+				s.State = Failed
+				s.Err = ctx.Err()
+			})
+			return
+
+		case c := <-r.commands:
+			switch c {
+			case pauseCommand:
+				if r.Status().State == Running {
+					if !timer.Stop() {
+						select {
+						case <-timer.C():
+						default:
+						}
+					}
+					r.setStatus(func(s *Status) { s.State = Paused })
+				}
+
+			case resumeCommand:
+				if r.Status().State == Paused {
+					timer.Reset(r.plan.Interval)
+					r.setStatus(func(s *Status) { s.State = Running })
+				}
+
+			case abortCommand:
+				r.rollback(Aborted, nil)
+				return
+			}
+			r.acks <- struct{}{}
+
+		case <-timer.C():
+			if r.advance() {
+				return
+			}
+			timer.Reset(r.plan.Interval)
+		}
+	}
+}
+
+// advance evaluates the current step's checks and applies the next step.
+// It returns true if the Rollout has stopped.
+func (r *Rollout) advance() bool {
+	step := r.Status().Step
+
+	if step >= 0 {
+		if err := r.check(r.appliedAt, r.options.Time.Now()); err != nil {
+			if _, ok := err.(*ThresholdError); ok {
+				r.rollback(RolledBack, err)
+			} else {
+				r.setStatus(func(s *Status) {
+					s.State = Failed
+					s.Err = err
+				})
+			}
+			return true
+		}
+	}
+
+	if step == len(r.plan.Steps)-1 {
+		r.setStatus(func(s *Status) { s.State = Completed })
+		return true
+	}
+
+	step++
+	percent := r.plan.Steps[step]
+	if err := r.modify(func(c *api.AllConstraints) { r.shift(c, percent) }); err != nil {
+		r.setStatus(func(s *Status) {
+			s.State = Failed
+			s.Err = err
+		})
+		return true
+	}
+
+	r.appliedAt = r.options.Time.Now()
+	r.setStatus(func(s *Status) {
+		s.Step = step
+		s.Percent = percent
+	})
+	return false
+}
+
+// rollback restores the original From and To constraints, ending the
+// Rollout in the given State.
+func (r *Rollout) rollback(state State, cause error) {
+	err := r.modify(r.restore)
+
+	r.setStatus(func(s *Status) {
+		if err != nil {
+			s.State = Failed
+			if cause != nil {
+				s.Err = fmt.Errorf("%s; rollback failed: %s", cause.Error(), err.Error())
+			} else {
+				s.Err = fmt.Errorf("rollback failed: %s", err.Error())
+			}
+			return
+		}
+
+		s.State = state
+		s.Err = cause
+		s.Step = -1
+		s.Percent = 100 * float64(r.to.Weight) / float64(r.total)
+	})
+}
+
+// shift gives To the given percentage of the combined weight of the From
+// and To constraints, removing From at 100%.
+func (r *Rollout) shift(c *api.AllConstraints, percent float64) {
+	toWeight := uint32(math.Floor(float64(r.total)*percent/100 + 0.5))
+	if percent < 100 {
+		// ClusterConstraint weights must be positive
+		if toWeight < 1 {
+			toWeight = 1
+		} else if toWeight > r.total-1 {
+			toWeight = r.total - 1
+		}
+	}
+
+	fromIdx, toIdx, _ := r.find(*c)
+	c.Light[toIdx].Weight = toWeight
+	if fromIdx < 0 {
+		return
+	}
+
+	if toWeight == r.total {
+		c.Light = append(c.Light[:fromIdx:fromIdx], c.Light[fromIdx+1:]...)
+	} else {
+		c.Light[fromIdx].Weight = r.total - toWeight
+	}
+}
+
+// restore returns the From and To constraints to their original weights.
+func (r *Rollout) restore(c *api.AllConstraints) {
+	fromIdx, toIdx, _ := r.find(*c)
+	c.Light[toIdx].Weight = r.to.Weight
+	if fromIdx >= 0 {
+		c.Light[fromIdx].Weight = r.from.Weight
+	} else {
+		c.Light = append(c.Light, r.from)
+	}
+}
+
+// find returns the indexes of the From and To constraints. The index of
+// From is -1 if it is not present. An error is returned if To is not
+// present.
+func (r *Rollout) find(c api.AllConstraints) (int, int, error) {
+	fromIdx, toIdx := -1, -1
+	for i, cc := range c.Light {
+		switch cc.ConstraintKey {
+		case r.plan.From:
+			fromIdx = i
+		case r.plan.To:
+			toIdx = i
+		}
+	}
+
+	if toIdx < 0 {
+		return -1, -1, fmt.Errorf("constraint %q does not exist", r.plan.To)
+	}
+	return fromIdx, toIdx, nil
+}
+
+// get returns the constraints modified by the Rollout.
+func (r *Rollout) get() (api.AllConstraints, error) {
+	if r.plan.SharedRulesKey != "" {
+		sr, err := r.svc.SharedRules().Get(r.plan.SharedRulesKey)
+		if err != nil {
+			return api.AllConstraints{}, err
+		}
+		c, err := r.constraints(&sr.Default, sr.Rules)
+		if err != nil {
+			return api.AllConstraints{}, err
+		}
+		return *c, nil
+	}
+
+	route, err := r.svc.Route().Get(r.plan.RouteKey)
+	if err != nil {
+		return api.AllConstraints{}, err
+	}
+	c, err := r.constraints(nil, route.Rules)
+	if err != nil {
+		return api.AllConstraints{}, err
+	}
+	return *c, nil
+}
+
+// modify applies f to the constraints modified by the Rollout.
+func (r *Rollout) modify(f func(*api.AllConstraints)) error {
+	if r.plan.SharedRulesKey != "" {
+		_, err := r.updater.SharedRules(
+			r.svc.SharedRules(),
+			r.plan.SharedRulesKey,
+			func(sr api.SharedRules) (api.SharedRules, error) {
+				sr.Rules = copyRules(sr.Rules)
+				c, err := r.constraints(&sr.Default, sr.Rules)
+				if err != nil {
+					return sr, err
+				}
+				if _, _, err := r.find(*c); err != nil {
+					return sr, err
+				}
+				c.Light = append(api.ClusterConstraints(nil), c.Light...)
+				f(c)
+				return sr, nil
+			},
+		)
+		return err
+	}
+
+	_, err := r.updater.Route(
+		r.svc.Route(),
+		r.plan.RouteKey,
+		func(route api.Route) (api.Route, error) {
+			route.Rules = copyRules(route.Rules)
+			c, err := r.constraints(nil, route.Rules)
+			if err != nil {
+				return route, err
+			}
+			if _, _, err := r.find(*c); err != nil {
+				return route, err
+			}
+			c.Light = append(api.ClusterConstraints(nil), c.Light...)
+			f(c)
+			return route, nil
+		},
+	)
+	return err
+}
+
+// constraints returns the Rule's constraints, or def if the Plan has no
+// RuleKey.
+func (r *Rollout) constraints(def *api.AllConstraints, rules api.Rules) (*api.AllConstraints, error) {
+	if r.plan.RuleKey == "" {
+		return def, nil
+	}
+
+	for i := range rules {
+		if rules[i].RuleKey == r.plan.RuleKey {
+			return &rules[i].Constraints, nil
+		}
+	}
+	return nil, fmt.Errorf("rule %q does not exist", r.plan.RuleKey)
+}
+
+func copyRules(rules api.Rules) api.Rules {
+	if rules == nil {
+		return nil
+	}
+	return append(api.Rules(nil), rules...)
+}
+
+// check evaluates the Plan's Checks over the given period, returning a
+// *ThresholdError for the first that is breached.
+func (r *Rollout) check(start, end time.Time) error {
+	if len(r.plan.Checks) == 0 {
+		return nil
+	}
+
+	q := &statsapi.Query{
+		TimeRange: statsapi.TimeRange{
+			SimpleTimeRange: statsapi.SimpleTimeRange{
+				Start: ptr.Int64(start.Unix()),
+				End:   ptr.Int64(end.Unix()),
+			},
+			Granularity: timegranularity.Minutes,
+		},
+	}
+	for i, c := range r.plan.Checks {
+		filter := c.Filter
+		q.TimeSeries = append(q.TimeSeries, statsapi.QueryTimeSeries{
+			Name:      checkName(i),
+			QueryType: c.QueryType,
+			Filter:    &filter,
+		})
+	}
+
+	result, err := r.stats.QueryV2(q)
+	if err != nil {
+		return err
+	}
+
+	series := make(map[string][]statsapi.Point, len(result.TimeSeries))
+	for _, ts := range result.TimeSeries {
+		series[ts.Query.Name] = ts.Points
+	}
+
+	for i, c := range r.plan.Checks {
+		name := checkName(i)
+		points, ok := series[name]
+		if !ok {
+			return fmt.Errorf("no time series named %q", name)
+		}
+		if len(points) < c.minPoints() {
+			return &ThresholdError{Check: c, Points: len(points)}
+		}
+
+		sum := 0.0
+		for _, p := range points {
+			sum += p.Value
+		}
+		value := sum / float64(len(points))
+
+		if (c.Min != nil && value < *c.Min) || (c.Max != nil && value > *c.Max) {
+			return &ThresholdError{Check: c, Value: value, Points: len(points)}
+		}
+	}
+
+	return nil
+}
+
+// checkName returns the name of the time series queried for the i'th Check.
+func checkName(i int) string {
+	return fmt.Sprintf("check-%d", i)
+}
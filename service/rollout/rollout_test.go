@@ -0,0 +1,518 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/api/service/memory"
+	statsapi "github.com/turbinelabs/api/service/stats/v2"
+	"github.com/turbinelabs/api/service/stats/v2/querytype"
+	"github.com/turbinelabs/nonstdlib/ptr"
+	"github.com/turbinelabs/test/assert"
+)
+
+func newTestService(t *testing.T) (service.All, api.SharedRulesKey, api.RouteKey) {
+	svc := memory.NewEmpty("ok", "uk")
+
+	z, err := svc.Zone().Create(api.Zone{Name: "z"})
+	assert.Nil(t, err)
+
+	light := api.ClusterConstraints{
+		{ConstraintKey: "stable", ClusterKey: "ck", Weight: 90},
+		{ConstraintKey: "canary", ClusterKey: "ck", Weight: 10},
+		{ConstraintKey: "other", ClusterKey: "ck", Weight: 50},
+	}
+
+	sr, err := svc.SharedRules().Create(api.SharedRules{
+		ZoneKey: z.ZoneKey,
+		Name:    "sr",
+		Default: api.AllConstraints{Light: light},
+	})
+	assert.Nil(t, err)
+
+	d, err := svc.Domain().Create(api.Domain{ZoneKey: z.ZoneKey, Name: "example.com", Port: 80})
+	assert.Nil(t, err)
+
+	route, err := svc.Route().Create(api.Route{
+		ZoneKey:        z.ZoneKey,
+		DomainKey:      d.DomainKey,
+		SharedRulesKey: sr.SharedRulesKey,
+		Path:           "/",
+		Rules: api.Rules{
+			{
+				RuleKey:     "rk",
+				Methods:     []string{"GET"},
+				Constraints: api.AllConstraints{Light: light[:2]},
+			},
+		},
+	})
+	assert.Nil(t, err)
+
+	return svc, sr.SharedRulesKey, route.RouteKey
+}
+
+func weights(c api.AllConstraints) map[api.ConstraintKey]uint32 {
+	w := map[api.ConstraintKey]uint32{}
+	for _, cc := range c.Light {
+		w[cc.ConstraintKey] = cc.Weight
+	}
+	return w
+}
+
+func sharedRulesWeights(t *testing.T, svc service.All, key api.SharedRulesKey) map[api.ConstraintKey]uint32 {
+	sr, err := svc.SharedRules().Get(key)
+	assert.Nil(t, err)
+	return weights(sr.Default)
+}
+
+func waitDone(t *testing.T, r *Rollout) Status {
+	select {
+	case <-r.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for rollout")
+	}
+	return r.Status()
+}
+
+func waitFor(t *testing.T, r *Rollout, f func(Status) bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !f(r.Status()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for status; got %+v", r.Status())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRolloutCompletes(t *testing.T) {
+	svc, srKey, _ := newTestService(t)
+
+	r, err := Start(
+		context.Background(),
+		svc,
+		nil,
+		Plan{
+			SharedRulesKey: srKey,
+			From:           "stable",
+			To:             "canary",
+			Steps:          []float64{25, 50, 100},
+			Interval:       time.Millisecond,
+		},
+		Options{},
+	)
+	assert.Nil(t, err)
+
+	status := waitDone(t, r)
+	assert.DeepEqual(t, status, Status{State: Completed, Step: 2, Percent: 100})
+	assert.DeepEqual(
+		t,
+		sharedRulesWeights(t, svc, srKey),
+		map[api.ConstraintKey]uint32{"canary": 100, "other": 50},
+	)
+}
+
+func TestRolloutRoute(t *testing.T) {
+	svc, _, routeKey := newTestService(t)
+
+	r, err := Start(
+		context.Background(),
+		svc,
+		nil,
+		Plan{
+			RouteKey: routeKey,
+			RuleKey:  "rk",
+			From:     "stable",
+			To:       "canary",
+			Steps:    []float64{50},
+			Interval: time.Millisecond,
+		},
+		Options{},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, waitDone(t, r).State, Completed)
+
+	route, err := svc.Route().Get(routeKey)
+	assert.Nil(t, err)
+	assert.DeepEqual(
+		t,
+		weights(route.Rules[0].Constraints),
+		map[api.ConstraintKey]uint32{"stable": 50, "canary": 50},
+	)
+}
+
+func TestRolloutRollsBack(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	svc, srKey, _ := newTestService(t)
+
+	canary := api.ConstraintKey("canary")
+	filter := statsapi.QueryFilter{ConstraintKey: &canary}
+	check := Check{QueryType: querytype.SuccessRate, Filter: filter, Min: ptr.Float64(0.99)}
+
+	stats := statsapi.NewMockStatsQueryService(ctrl)
+	gomock.InOrder(
+		stats.EXPECT().QueryV2(gomock.Any()).Return(
+			&statsapi.QueryResult{
+				TimeSeries: []statsapi.TimeSeries{
+					{Query: statsapi.QueryTimeSeries{Name: "check-0"}, Points: []statsapi.Point{{Value: 1}}},
+				},
+			},
+			nil,
+		),
+		stats.EXPECT().QueryV2(gomock.Any()).DoAndReturn(
+			func(q *statsapi.Query) (*statsapi.QueryResult, error) {
+				assert.Equal(t, len(q.TimeSeries), 1)
+				assert.Equal(t, q.TimeSeries[0].QueryType, querytype.SuccessRate)
+				assert.DeepEqual(t, *q.TimeSeries[0].Filter, filter)
+				assert.NonNil(t, q.TimeRange.Start)
+				assert.NonNil(t, q.TimeRange.End)
+
+				return &statsapi.QueryResult{
+					TimeSeries: []statsapi.TimeSeries{
+						{
+							Query:  q.TimeSeries[0],
+							Points: []statsapi.Point{{Value: 1}, {Value: 0.9}},
+						},
+					},
+				}, nil
+			},
+		),
+	)
+
+	r, err := Start(
+		context.Background(),
+		svc,
+		stats,
+		Plan{
+			SharedRulesKey: srKey,
+			From:           "stable",
+			To:             "canary",
+			Steps:          []float64{20, 100},
+			Interval:       time.Millisecond,
+			Checks:         []Check{check},
+		},
+		Options{},
+	)
+	assert.Nil(t, err)
+
+	status := waitDone(t, r)
+	assert.Equal(t, status.State, RolledBack)
+	assert.Equal(t, status.Step, -1)
+	assert.Equal(t, status.Percent, 10.0)
+	assert.DeepEqual(t, status.Err, &ThresholdError{Check: check, Value: 0.95, Points: 2})
+	assert.ErrorContains(t, status.Err, "success_rate of 0.95 is below minimum 0.99")
+
+	assert.DeepEqual(
+		t,
+		sharedRulesWeights(t, svc, srKey),
+		map[api.ConstraintKey]uint32{"stable": 90, "canary": 10, "other": 50},
+	)
+}
+
+func TestRolloutRollsBackWithoutData(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	svc, srKey, _ := newTestService(t)
+
+	check := Check{QueryType: querytype.SuccessRate, Min: ptr.Float64(0.99), MinPoints: 2}
+
+	stats := statsapi.NewMockStatsQueryService(ctrl)
+	stats.EXPECT().QueryV2(gomock.Any()).Return(
+		&statsapi.QueryResult{
+			TimeSeries: []statsapi.TimeSeries{
+				{Query: statsapi.QueryTimeSeries{Name: "check-0"}, Points: []statsapi.Point{{Value: 1}}},
+			},
+		},
+		nil,
+	)
+
+	r, err := Start(
+		context.Background(),
+		svc,
+		stats,
+		Plan{
+			SharedRulesKey: srKey,
+			From:           "stable",
+			To:             "canary",
+			Steps:          []float64{20, 100},
+			Interval:       time.Millisecond,
+			Checks:         []Check{check},
+		},
+		Options{},
+	)
+	assert.Nil(t, err)
+
+	status := waitDone(t, r)
+	assert.Equal(t, status.State, RolledBack)
+	assert.DeepEqual(t, status.Err, &ThresholdError{Check: check, Points: 1})
+	assert.ErrorContains(t, status.Err, "success_rate has 1 points, fewer than the required 2")
+	assert.Equal(t, sharedRulesWeights(t, svc, srKey)["canary"], uint32(10))
+}
+
+func TestRolloutMatchesTimeSeriesByName(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	svc, srKey, _ := newTestService(t)
+
+	checks := []Check{
+		{QueryType: querytype.SuccessRate, Min: ptr.Float64(0.99)},
+		{QueryType: querytype.LatencyP99, Max: ptr.Float64(500)},
+	}
+
+	stats := statsapi.NewMockStatsQueryService(ctrl)
+	stats.EXPECT().QueryV2(gomock.Any()).Return(
+		&statsapi.QueryResult{
+			TimeSeries: []statsapi.TimeSeries{
+				{Query: statsapi.QueryTimeSeries{Name: "check-1"}, Points: []statsapi.Point{{Value: 100}}},
+				{Query: statsapi.QueryTimeSeries{Name: "check-0"}, Points: []statsapi.Point{{Value: 1}}},
+			},
+		},
+		nil,
+	).Times(2)
+
+	r, err := Start(
+		context.Background(),
+		svc,
+		stats,
+		Plan{
+			SharedRulesKey: srKey,
+			From:           "stable",
+			To:             "canary",
+			Steps:          []float64{20, 100},
+			Interval:       time.Millisecond,
+			Checks:         checks,
+		},
+		Options{},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, waitDone(t, r).State, Completed)
+}
+
+func TestRolloutStatsError(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	svc, srKey, _ := newTestService(t)
+
+	boom := errors.New("boom")
+	stats := statsapi.NewMockStatsQueryService(ctrl)
+	stats.EXPECT().QueryV2(gomock.Any()).Return(nil, boom)
+
+	r, err := Start(
+		context.Background(),
+		svc,
+		stats,
+		Plan{
+			SharedRulesKey: srKey,
+			From:           "stable",
+			To:             "canary",
+			Steps:          []float64{20, 100},
+			Interval:       time.Millisecond,
+			Checks:         []Check{{QueryType: querytype.LatencyP99, Max: ptr.Float64(500)}},
+		},
+		Options{},
+	)
+	assert.Nil(t, err)
+
+	status := waitDone(t, r)
+	assert.DeepEqual(t, status, Status{State: Failed, Step: 0, Percent: 20, Err: boom})
+	assert.Equal(t, sharedRulesWeights(t, svc, srKey)["canary"], uint32(20))
+}
+
+func TestRolloutPauseResumeAbort(t *testing.T) {
+	svc, srKey, _ := newTestService(t)
+
+	r, err := Start(
+		context.Background(),
+		svc,
+		nil,
+		Plan{
+			SharedRulesKey: srKey,
+			From:           "stable",
+			To:             "canary",
+			Steps:          []float64{1, 100},
+			Interval:       time.Hour,
+		},
+		Options{},
+	)
+	assert.Nil(t, err)
+
+	waitFor(t, r, func(s Status) bool { return s.Step == 0 })
+	assert.DeepEqual(
+		t,
+		sharedRulesWeights(t, svc, srKey),
+		map[api.ConstraintKey]uint32{"stable": 99, "canary": 1, "other": 50},
+	)
+
+	r.Pause()
+	assert.Equal(t, r.Status().State, Paused)
+	r.Resume()
+	assert.Equal(t, r.Status().State, Running)
+	r.Abort()
+
+	status := waitDone(t, r)
+	assert.DeepEqual(t, status, Status{State: Aborted, Step: -1, Percent: 10})
+	assert.DeepEqual(
+		t,
+		sharedRulesWeights(t, svc, srKey),
+		map[api.ConstraintKey]uint32{"stable": 90, "canary": 10, "other": 50},
+	)
+
+	// commands after the rollout has stopped are ignored
+	r.Pause()
+	assert.Equal(t, r.Status().State, Aborted)
+}
+
+func TestRolloutCancel(t *testing.T) {
+	svc, srKey, _ := newTestService(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r, err := Start(
+		ctx,
+		svc,
+		nil,
+		Plan{
+			SharedRulesKey: srKey,
+			From:           "stable",
+			To:             "canary",
+			Steps:          []float64{50, 100},
+			Interval:       time.Hour,
+		},
+		Options{},
+	)
+	assert.Nil(t, err)
+
+	waitFor(t, r, func(s Status) bool { return s.Step == 0 })
+	cancel()
+
+	status := waitDone(t, r)
+	assert.Equal(t, status.State, Failed)
+	assert.Equal(t, status.Err, context.Canceled)
+	assert.Equal(t, sharedRulesWeights(t, svc, srKey)["canary"], uint32(50))
+}
+
+func TestStartErrors(t *testing.T) {
+	svc, srKey, routeKey := newTestService(t)
+
+	valid := Plan{
+		SharedRulesKey: srKey,
+		From:           "stable",
+		To:             "canary",
+		Steps:          []float64{50, 100},
+		Interval:       time.Minute,
+	}
+
+	for _, tc := range []struct {
+		name   string
+		modify func(*Plan)
+		err    string
+	}{
+		{"no object", func(p *Plan) { p.SharedRulesKey = "" }, "one of SharedRulesKey and RouteKey must be set"},
+		{"two objects", func(p *Plan) { p.RouteKey = routeKey }, "only one of SharedRulesKey and RouteKey may be set"},
+		{
+			"route without rule",
+			func(p *Plan) { p.SharedRulesKey, p.RouteKey = "", routeKey },
+			"RuleKey must be set for Routes",
+		},
+		{"same constraint", func(p *Plan) { p.To = "stable" }, "From and To must differ"},
+		{"no steps", func(p *Plan) { p.Steps = nil }, "at least one step is required"},
+		{"decreasing steps", func(p *Plan) { p.Steps = []float64{50, 10} }, "step 1: 10 must be greater than 50"},
+		{"too large", func(p *Plan) { p.Steps = []float64{150} }, "at most 100"},
+		{"no interval", func(p *Plan) { p.Interval = 0 }, "Interval must be positive"},
+		{
+			"checks without stats",
+			func(p *Plan) { p.Checks = []Check{{QueryType: querytype.SuccessRate}} },
+			"a StatsQueryService is required for Checks",
+		},
+		{"missing from", func(p *Plan) { p.From = "nope" }, `constraint "nope" does not exist`},
+		{"missing to", func(p *Plan) { p.To = "nope" }, `constraint "nope" does not exist`},
+		{"missing rule", func(p *Plan) { p.RuleKey = "nope" }, `rule "nope" does not exist`},
+		{"missing shared rules", func(p *Plan) { p.SharedRulesKey = "nope" }, "nope"},
+	} {
+		assert.Group(tc.name, t, func(g *assert.G) {
+			p := valid
+			tc.modify(&p)
+			r, err := Start(context.Background(), svc, nil, p, Options{})
+			assert.Nil(g, r)
+			assert.ErrorContains(g, err, tc.err)
+		})
+	}
+}
+
+func TestStartCheckErrors(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	svc, srKey, _ := newTestService(t)
+	stats := statsapi.NewMockStatsQueryService(ctrl)
+
+	plan := Plan{
+		SharedRulesKey: srKey,
+		From:           "stable",
+		To:             "canary",
+		Steps:          []float64{100},
+		Interval:       time.Minute,
+		Checks:         []Check{{QueryType: querytype.SuccessRate}},
+	}
+	_, err := Start(context.Background(), svc, stats, plan, Options{})
+	assert.ErrorContains(t, err, "check 0: one of Min and Max must be set")
+
+	plan.Checks = []Check{{Max: ptr.Float64(1)}}
+	_, err = Start(context.Background(), svc, stats, plan, Options{})
+	assert.ErrorContains(t, err, "check 0: invalid query type")
+
+	plan.Checks = []Check{{QueryType: querytype.SuccessRate, Max: ptr.Float64(1), MinPoints: -1}}
+	_, err = Start(context.Background(), svc, stats, plan, Options{})
+	assert.ErrorContains(t, err, "check 0: MinPoints must not be negative")
+}
+
+func TestShift(t *testing.T) {
+	r := &Rollout{
+		plan:  Plan{From: "a", To: "b"},
+		from:  api.ClusterConstraint{ConstraintKey: "a", Weight: 3},
+		to:    api.ClusterConstraint{ConstraintKey: "b", Weight: 1},
+		total: 4,
+	}
+
+	for _, tc := range []struct {
+		percent float64
+		want    map[api.ConstraintKey]uint32
+	}{
+		// weights are kept positive until From is removed
+		{1, map[api.ConstraintKey]uint32{"a": 3, "b": 1}},
+		{50, map[api.ConstraintKey]uint32{"a": 2, "b": 2}},
+		{99, map[api.ConstraintKey]uint32{"a": 1, "b": 3}},
+		{100, map[api.ConstraintKey]uint32{"b": 4}},
+	} {
+		c := api.AllConstraints{Light: api.ClusterConstraints{r.from, r.to}}
+		r.shift(&c, tc.percent)
+		assert.DeepEqual(t, weights(c), tc.want)
+
+		r.restore(&c)
+		assert.DeepEqual(t, weights(c), map[api.ConstraintKey]uint32{"a": 3, "b": 1})
+	}
+}
@@ -51,6 +51,7 @@ type All interface {
 	Listener() Listener
 	Zone() Zone
 	History() History
+	Batch() Batch
 }
 
 // ClusterFilter describes a filter on the full list of Clusters
@@ -559,6 +560,21 @@ type Zone interface {
 	Delete(zoneKey api.ZoneKey, checksum api.Checksum) error
 }
 
+// Batch describes the interface for applying several mutations atomically
+type Batch interface {
+	// POST /v1.0/batch
+	//
+	// Apply applies the given BatchOperations in order. If any operation
+	// fails, none are applied and the returned error describes the first
+	// failure. Otherwise a BatchResult is returned for each operation, in the
+	// same order, and the changes are recorded in the changelog under a
+	// single Txn.
+	//
+	// Keys of created objects are assigned when the batch is applied, so
+	// operations may not refer to objects created earlier in the same batch.
+	Apply(ops []BatchOperation) ([]BatchResult, error)
+}
+
 // ZoneFilter describes a filter on the full list of Zones
 type ZoneFilter struct {
 	ZoneKey api.ZoneKey `json:"zone_key"`
@@ -58,7 +58,9 @@ func getSharedRulesFilterTestSharedRules() api.SharedRules {
 		},
 		api.AllConstraints{
 			Light: api.ClusterConstraints{
-				{"cckey2", "ckey2", api.Metadata{{"key-2", "value-2"}}, api.Metadata{{"state", "releasing"}}, api.ResponseData{}, 1234}}},
+				{"cckey2", "ckey2", api.Metadata{{"key-2", "value-2"}}, api.Metadata{{"state", "releasing"}}, api.ResponseData{}, api.RequestData{}, 1234}}},
+		nil,
+		nil,
 		nil,
 	}
 
@@ -71,9 +73,11 @@ func getSharedRulesFilterTestSharedRules() api.SharedRules {
 		defaultCC,
 		rules,
 		api.ResponseData{},
+		api.RequestData{},
 		nil,
 		api.Metadata{{"pk", "pv"}, {"pk2", "pv2"}},
 		nil,
+		nil,
 		"123",
 		api.Checksum{"cs-1"},
 	}
@@ -101,7 +105,9 @@ func getRouteFilterTestRoute() api.Route {
 		},
 		api.AllConstraints{
 			Light: api.ClusterConstraints{
-				{"cckey2", "ckey2", api.Metadata{{"key-2", "value-2"}}, api.Metadata{{"state", "releasing"}}, api.ResponseData{}, 1234}}},
+				{"cckey2", "ckey2", api.Metadata{{"key-2", "value-2"}}, api.Metadata{{"state", "releasing"}}, api.ResponseData{}, api.RequestData{}, 1234}}},
+		nil,
+		nil,
 		nil,
 	}
 
@@ -115,6 +121,9 @@ func getRouteFilterTestRoute() api.Route {
 		api.SharedRulesKey("shared-rules-key"),
 		rules,
 		api.ResponseData{},
+		api.RequestData{},
+		nil,
+		nil,
 		nil,
 		nil,
 		"123",
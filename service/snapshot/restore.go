@@ -0,0 +1,264 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"fmt"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+)
+
+// KeyMap maps the keys of the objects in a Snapshot to the keys of the
+// objects created when it was restored.
+type KeyMap struct {
+	Zone        api.ZoneKey
+	Clusters    map[api.ClusterKey]api.ClusterKey
+	Domains     map[api.DomainKey]api.DomainKey
+	Listeners   map[api.ListenerKey]api.ListenerKey
+	Proxies     map[api.ProxyKey]api.ProxyKey
+	SharedRules map[api.SharedRulesKey]api.SharedRulesKey
+	Routes      map[api.RouteKey]api.RouteKey
+}
+
+func newKeyMap() *KeyMap {
+	return &KeyMap{
+		Clusters:    map[api.ClusterKey]api.ClusterKey{},
+		Domains:     map[api.DomainKey]api.DomainKey{},
+		Listeners:   map[api.ListenerKey]api.ListenerKey{},
+		Proxies:     map[api.ProxyKey]api.ProxyKey{},
+		SharedRules: map[api.SharedRulesKey]api.SharedRulesKey{},
+		Routes:      map[api.RouteKey]api.RouteKey{},
+	}
+}
+
+func (km *KeyMap) domainKeys(dks []api.DomainKey) []api.DomainKey {
+	if dks == nil {
+		return nil
+	}
+	result := make([]api.DomainKey, len(dks))
+	for i, dk := range dks {
+		result[i] = km.Domains[dk]
+	}
+	return result
+}
+
+func (km *KeyMap) constraints(ccs api.ClusterConstraints) api.ClusterConstraints {
+	if ccs == nil {
+		return nil
+	}
+	result := make(api.ClusterConstraints, len(ccs))
+	for i, cc := range ccs {
+		cc.ClusterKey = km.Clusters[cc.ClusterKey]
+		result[i] = cc
+	}
+	return result
+}
+
+func (km *KeyMap) allConstraints(ac api.AllConstraints) api.AllConstraints {
+	return api.AllConstraints{
+		Light: km.constraints(ac.Light),
+		Dark:  km.constraints(ac.Dark),
+		Tap:   km.constraints(ac.Tap),
+	}
+}
+
+func (km *KeyMap) rules(rules api.Rules) api.Rules {
+	if rules == nil {
+		return nil
+	}
+	result := make(api.Rules, len(rules))
+	for i, r := range rules {
+		r.Constraints = km.allConstraints(r.Constraints)
+		result[i] = r
+	}
+	return result
+}
+
+// Restore creates the objects in the Snapshot in the Zone with the given
+// key, which must contain no objects. If zk is empty, a new Zone with the
+// Snapshot's Zone name is created. The objects are created in the Org of
+// svc, which need not be the Org from which the Snapshot was exported.
+//
+// The Snapshot is checked with IsValid before any objects are created, and
+// the restored Zone is exported and checked with IsValid afterwards. If an
+// error occurs, objects already created are not removed; the returned
+// KeyMap, if non-nil, describes them.
+func Restore(svc service.All, s *Snapshot, zk api.ZoneKey) (*KeyMap, error) {
+	if errs := s.IsValid(); errs != nil {
+		return nil, errs
+	}
+
+	if zk == "" {
+		zone, err := svc.Zone().Create(api.Zone{Name: s.Zone.Name})
+		if err != nil {
+			return nil, err
+		}
+		zk = zone.ZoneKey
+	} else if err := checkEmpty(svc, zk); err != nil {
+		return nil, err
+	}
+
+	km := newKeyMap()
+	km.Zone = zk
+
+	if err := restore(svc, s, km); err != nil {
+		return km, err
+	}
+
+	restored, err := Export(svc, zk)
+	if err != nil {
+		return km, err
+	}
+	if errs := restored.IsValid(); errs != nil {
+		return km, fmt.Errorf("restored zone is invalid: %s", errs.Error())
+	}
+
+	return km, nil
+}
+
+// checkEmpty returns an error if the Zone does not exist or contains any
+// objects.
+func checkEmpty(svc service.All, zk api.ZoneKey) error {
+	if _, err := svc.Zone().Get(zk); err != nil {
+		return err
+	}
+
+	clusters, err := svc.Cluster().Index(service.ClusterFilter{ZoneKey: zk})
+	if err != nil {
+		return err
+	}
+	domains, err := svc.Domain().Index(service.DomainFilter{ZoneKey: zk})
+	if err != nil {
+		return err
+	}
+	listeners, err := svc.Listener().Index(service.ListenerFilter{ZoneKey: zk})
+	if err != nil {
+		return err
+	}
+	proxies, err := svc.Proxy().Index(service.ProxyFilter{ZoneKey: zk})
+	if err != nil {
+		return err
+	}
+	sharedRules, err := svc.SharedRules().Index(service.SharedRulesFilter{ZoneKey: zk})
+	if err != nil {
+		return err
+	}
+	routes, err := svc.Route().Index(service.RouteFilter{ZoneKey: zk})
+	if err != nil {
+		return err
+	}
+
+	n := len(clusters) + len(domains) + len(listeners) + len(proxies) +
+		len(sharedRules) + len(routes)
+	if n > 0 {
+		return fmt.Errorf("zone %s is not empty: it contains %d objects", zk, n)
+	}
+
+	return nil
+}
+
+// restore creates the Snapshot's objects, in dependency order, recording
+// their new keys in km.
+func restore(svc service.All, s *Snapshot, km *KeyMap) error {
+	for _, c := range s.Clusters {
+		oldKey := c.ClusterKey
+		c.ClusterKey = ""
+		c.ZoneKey = km.Zone
+		c.Checksum = api.Checksum{}
+		created, err := svc.Cluster().Create(c)
+		if err != nil {
+			return fmt.Errorf("cluster %s: %s", oldKey, err.Error())
+		}
+		km.Clusters[oldKey] = created.ClusterKey
+	}
+
+	for _, d := range s.Domains {
+		oldKey := d.DomainKey
+		d.DomainKey = ""
+		d.ZoneKey = km.Zone
+		d.Checksum = api.Checksum{}
+		created, err := svc.Domain().Create(d)
+		if err != nil {
+			return fmt.Errorf("domain %s: %s", oldKey, err.Error())
+		}
+		km.Domains[oldKey] = created.DomainKey
+	}
+
+	for _, l := range s.Listeners {
+		oldKey := l.ListenerKey
+		l.ListenerKey = ""
+		l.ZoneKey = km.Zone
+		l.DomainKeys = km.domainKeys(l.DomainKeys)
+		l.Checksum = api.Checksum{}
+		created, err := svc.Listener().Create(l)
+		if err != nil {
+			return fmt.Errorf("listener %s: %s", oldKey, err.Error())
+		}
+		km.Listeners[oldKey] = created.ListenerKey
+	}
+
+	for _, p := range s.Proxies {
+		oldKey := p.ProxyKey
+		p.ProxyKey = ""
+		p.ZoneKey = km.Zone
+		p.DomainKeys = km.domainKeys(p.DomainKeys)
+		lks := make([]api.ListenerKey, len(p.ListenerKeys))
+		for i, lk := range p.ListenerKeys {
+			lks[i] = km.Listeners[lk]
+		}
+		p.ListenerKeys = lks
+		p.Listeners = nil
+		p.Checksum = api.Checksum{}
+		created, err := svc.Proxy().Create(p)
+		if err != nil {
+			return fmt.Errorf("proxy %s: %s", oldKey, err.Error())
+		}
+		km.Proxies[oldKey] = created.ProxyKey
+	}
+
+	for _, sr := range s.SharedRules {
+		oldKey := sr.SharedRulesKey
+		sr.SharedRulesKey = ""
+		sr.ZoneKey = km.Zone
+		sr.Default = km.allConstraints(sr.Default)
+		sr.Rules = km.rules(sr.Rules)
+		sr.Checksum = api.Checksum{}
+		created, err := svc.SharedRules().Create(sr)
+		if err != nil {
+			return fmt.Errorf("shared_rules %s: %s", oldKey, err.Error())
+		}
+		km.SharedRules[oldKey] = created.SharedRulesKey
+	}
+
+	for _, r := range s.Routes {
+		oldKey := r.RouteKey
+		r.RouteKey = ""
+		r.ZoneKey = km.Zone
+		r.DomainKey = km.Domains[r.DomainKey]
+		r.SharedRulesKey = km.SharedRules[r.SharedRulesKey]
+		r.Rules = km.rules(r.Rules)
+		r.Checksum = api.Checksum{}
+		created, err := svc.Route().Create(r)
+		if err != nil {
+			return fmt.Errorf("route %s: %s", oldKey, err.Error())
+		}
+		km.Routes[oldKey] = created.RouteKey
+	}
+
+	return nil
+}
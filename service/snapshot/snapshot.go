@@ -0,0 +1,302 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package snapshot exports the objects of a Zone to a versioned,
+// checksummed archive, and restores such archives into an empty Zone, which
+// may belong to a different Org:
+//
+// 	s, err := snapshot.Export(svc, zoneKey)
+// 	if err != nil {
+// 		return err
+// 	}
+// 	err = snapshot.Write(w, s)
+//
+// 	...
+//
+// 	s, err := snapshot.Read(r)
+// 	if err != nil {
+// 		return err
+// 	}
+// 	keys, err := snapshot.Restore(otherSvc, s, "")
+//
+// Restored objects are assigned new keys, and all references between them
+// are rewritten accordingly.
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+)
+
+// Version is the archive format version written by this package.
+const Version = 1
+
+// Snapshot is an archive of the objects of a single Zone. OrgKeys are not
+// recorded.
+type Snapshot struct {
+	// Version is the archive format version.
+	Version int `json:"version"`
+
+	// Checksum is the hex-encoded SHA-256 digest of the JSON encoding of
+	// the Snapshot with an empty Checksum.
+	Checksum string `json:"checksum"`
+
+	Zone        api.Zone             `json:"zone"`
+	Clusters    api.Clusters         `json:"clusters"`
+	Domains     api.Domains          `json:"domains"`
+	Listeners   api.Listeners        `json:"listeners"`
+	Proxies     api.Proxies          `json:"proxies"`
+	SharedRules api.SharedRulesSlice `json:"shared_rules"`
+	Routes      api.Routes           `json:"routes"`
+}
+
+// Export reads every object in the Zone with the given key from svc and
+// returns them as a checksummed Snapshot. Objects are ordered by key.
+func Export(svc service.All, zk api.ZoneKey) (*Snapshot, error) {
+	zone, err := svc.Zone().Get(zk)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Snapshot{Version: Version, Zone: zone}
+
+	if s.Clusters, err = svc.Cluster().Index(service.ClusterFilter{ZoneKey: zk}); err != nil {
+		return nil, err
+	}
+	sort.Sort(api.ClusterByClusterKey(s.Clusters))
+
+	if s.Domains, err = svc.Domain().Index(service.DomainFilter{ZoneKey: zk}); err != nil {
+		return nil, err
+	}
+	sort.Slice(s.Domains, func(i, j int) bool {
+		return s.Domains[i].DomainKey < s.Domains[j].DomainKey
+	})
+
+	if s.Listeners, err = svc.Listener().Index(service.ListenerFilter{ZoneKey: zk}); err != nil {
+		return nil, err
+	}
+	sort.Slice(s.Listeners, func(i, j int) bool {
+		return s.Listeners[i].ListenerKey < s.Listeners[j].ListenerKey
+	})
+
+	if s.Proxies, err = svc.Proxy().Index(service.ProxyFilter{ZoneKey: zk}); err != nil {
+		return nil, err
+	}
+	sort.Slice(s.Proxies, func(i, j int) bool {
+		return s.Proxies[i].ProxyKey < s.Proxies[j].ProxyKey
+	})
+
+	s.SharedRules, err = svc.SharedRules().Index(service.SharedRulesFilter{ZoneKey: zk})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(s.SharedRules, func(i, j int) bool {
+		return s.SharedRules[i].SharedRulesKey < s.SharedRules[j].SharedRulesKey
+	})
+
+	if s.Routes, err = svc.Route().Index(service.RouteFilter{ZoneKey: zk}); err != nil {
+		return nil, err
+	}
+	sort.Slice(s.Routes, func(i, j int) bool {
+		return s.Routes[i].RouteKey < s.Routes[j].RouteKey
+	})
+
+	if s.Checksum, err = s.computeChecksum(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// computeChecksum returns the checksum of the Snapshot's contents.
+func (s *Snapshot) computeChecksum() (string, error) {
+	contents := *s
+	contents.Checksum = ""
+
+	b, err := json.Marshal(contents)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Write writes the Snapshot to w as JSON.
+func Write(w io.Writer, s *Snapshot) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// Read reads a Snapshot written by Write from r. Returns an error if the
+// archive's version is not supported or its contents do not match its
+// checksum.
+func Read(r io.Reader) (*Snapshot, error) {
+	s := &Snapshot{}
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, err
+	}
+
+	if s.Version != Version {
+		return nil, fmt.Errorf("unsupported snapshot version %d", s.Version)
+	}
+
+	sum, err := s.computeChecksum()
+	if err != nil {
+		return nil, err
+	}
+	if sum != s.Checksum {
+		return nil, fmt.Errorf("snapshot checksum mismatch: expected %s, got %s", s.Checksum, sum)
+	}
+
+	return s, nil
+}
+
+// IsValid checks that every object in the Snapshot is valid and belongs to
+// its Zone, and that every reference between objects refers to an object in
+// the Snapshot. Because Snapshots do not record OrgKeys, missing OrgKeys are
+// not reported.
+func (s *Snapshot) IsValid() *api.ValidationError {
+	errs := &api.ValidationError{}
+
+	errs.Merge(s.Zone.IsValid())
+
+	inZone := func(name string, zk api.ZoneKey) {
+		if zk != s.Zone.ZoneKey {
+			errs.AddNew(api.ErrorCase{name + ".zone_key", "must match the snapshot's zone"})
+		}
+	}
+
+	clusters := map[api.ClusterKey]bool{}
+	for _, c := range s.Clusters {
+		scope := fmt.Sprintf("clusters[%s]", c.ClusterKey)
+		errs.MergePrefixed(c.IsValid(), scope)
+		inZone(scope, c.ZoneKey)
+		clusters[c.ClusterKey] = true
+	}
+
+	domains := map[api.DomainKey]bool{}
+	for _, d := range s.Domains {
+		scope := fmt.Sprintf("domains[%s]", d.DomainKey)
+		errs.MergePrefixed(d.IsValid(), scope)
+		inZone(scope, d.ZoneKey)
+		domains[d.DomainKey] = true
+	}
+
+	checkDomains := func(scope string, dks []api.DomainKey) {
+		for _, dk := range dks {
+			if !domains[dk] {
+				errs.AddNew(api.ErrorCase{scope, fmt.Sprintf("unknown domain key %q", dk)})
+			}
+		}
+	}
+
+	listeners := map[api.ListenerKey]bool{}
+	for _, l := range s.Listeners {
+		scope := fmt.Sprintf("listeners[%s]", l.ListenerKey)
+		errs.MergePrefixed(l.IsValid(), scope)
+		inZone(scope, l.ZoneKey)
+		checkDomains(scope+".domain_keys", l.DomainKeys)
+		listeners[l.ListenerKey] = true
+	}
+
+	for _, p := range s.Proxies {
+		scope := fmt.Sprintf("proxies[%s]", p.ProxyKey)
+		errs.MergePrefixed(p.IsValid(), scope)
+		inZone(scope, p.ZoneKey)
+		checkDomains(scope+".domain_keys", p.DomainKeys)
+		for _, lk := range p.ListenerKeys {
+			if !listeners[lk] {
+				errs.AddNew(
+					api.ErrorCase{scope + ".listener_keys", fmt.Sprintf("unknown listener key %q", lk)},
+				)
+			}
+		}
+	}
+
+	checkConstraints := func(scope string, ac api.AllConstraints) {
+		for _, ccs := range []api.ClusterConstraints{ac.Light, ac.Dark, ac.Tap} {
+			for _, cc := range ccs {
+				if !clusters[cc.ClusterKey] {
+					errs.AddNew(
+						api.ErrorCase{
+							fmt.Sprintf("%s[%s].cluster_key", scope, cc.ConstraintKey),
+							fmt.Sprintf("unknown cluster key %q", cc.ClusterKey),
+						},
+					)
+				}
+			}
+		}
+	}
+
+	checkRules := func(scope string, rules api.Rules) {
+		for _, r := range rules {
+			checkConstraints(fmt.Sprintf("%s.rules[%s].constraints", scope, r.RuleKey), r.Constraints)
+		}
+	}
+
+	sharedRules := map[api.SharedRulesKey]bool{}
+	for _, sr := range s.SharedRules {
+		scope := fmt.Sprintf("shared_rules[%s]", sr.SharedRulesKey)
+		errs.MergePrefixed(sr.IsValid(), scope)
+		inZone(scope, sr.ZoneKey)
+		checkConstraints(scope+".default", sr.Default)
+		checkRules(scope, sr.Rules)
+		sharedRules[sr.SharedRulesKey] = true
+	}
+
+	for _, r := range s.Routes {
+		scope := fmt.Sprintf("routes[%s]", r.RouteKey)
+		errs.MergePrefixed(r.IsValid(), scope)
+		inZone(scope, r.ZoneKey)
+		if !domains[r.DomainKey] {
+			errs.AddNew(
+				api.ErrorCase{scope + ".domain_key", fmt.Sprintf("unknown domain key %q", r.DomainKey)},
+			)
+		}
+		if !sharedRules[r.SharedRulesKey] {
+			errs.AddNew(
+				api.ErrorCase{
+					scope + ".shared_rules_key",
+					fmt.Sprintf("unknown shared rules key %q", r.SharedRulesKey),
+				},
+			)
+		}
+		checkRules(scope, r.Rules)
+	}
+
+	return withoutOrgKeyErrors(errs).OrNil()
+}
+
+// withoutOrgKeyErrors removes errors concerning OrgKeys.
+func withoutOrgKeyErrors(errs *api.ValidationError) *api.ValidationError {
+	result := &api.ValidationError{}
+	for _, e := range errs.Errors {
+		if !strings.HasSuffix(e.Attribute, "org_key") {
+			result.AddNew(e)
+		}
+	}
+	return result
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/api/service/memory"
+	"github.com/turbinelabs/api/service/reconcile"
+	"github.com/turbinelabs/test/assert"
+)
+
+func populate(t *testing.T, svc service.All) api.ZoneKey {
+	state := reconcile.State{
+		Zone: api.Zone{Name: "the-zone"},
+		Clusters: api.Clusters{
+			{Name: "api", Instances: api.Instances{{Host: "10.0.0.1", Port: 8080}}},
+			{Name: "web"},
+		},
+		Domains: api.Domains{{Name: "example.com", Port: 80}},
+		Listeners: api.Listeners{
+			{Name: "http", IP: "0.0.0.0", Port: 80, Protocol: api.HttpAutoListenerProtocol},
+		},
+		Proxies: api.Proxies{
+			{
+				Name:         "the-proxy",
+				DomainKeys:   []api.DomainKey{"example.com:80"},
+				ListenerKeys: []api.ListenerKey{"http"},
+			},
+		},
+		SharedRules: api.SharedRulesSlice{
+			{
+				Name: "web-rules",
+				Default: api.AllConstraints{
+					Light: api.ClusterConstraints{{ClusterKey: "web", Weight: 1}},
+				},
+			},
+		},
+		Routes: api.Routes{
+			{
+				DomainKey:      "example.com:80",
+				Path:           "/api",
+				SharedRulesKey: "web-rules",
+				Rules: api.Rules{
+					{
+						Methods: []string{"GET"},
+						Constraints: api.AllConstraints{
+							Light: api.ClusterConstraints{{ClusterKey: "api", Weight: 1}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	p, err := reconcile.NewPlan(svc, state)
+	assert.Nil(t, err)
+	assert.Nil(t, p.Apply(svc))
+
+	zones, err := svc.Zone().Index(service.ZoneFilter{Name: "the-zone"})
+	assert.Nil(t, err)
+	assert.Equal(t, len(zones), 1)
+	return zones[0].ZoneKey
+}
+
+func TestExportWriteRead(t *testing.T) {
+	svc := memory.NewEmpty("the-org", "the-user")
+	zk := populate(t, svc)
+
+	s, err := Export(svc, zk)
+	assert.Nil(t, err)
+	assert.Equal(t, s.Version, Version)
+	assert.NotEqual(t, s.Checksum, "")
+	assert.Equal(t, len(s.Clusters), 2)
+	assert.Equal(t, len(s.Routes), 1)
+	assert.Nil(t, s.IsValid())
+
+	// export is deterministic
+	again, err := Export(svc, zk)
+	assert.Nil(t, err)
+	assert.Equal(t, again.Checksum, s.Checksum)
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, Write(buf, s))
+	archive := buf.String()
+
+	got, err := Read(strings.NewReader(archive))
+	assert.Nil(t, err)
+	assert.Equal(t, got.Checksum, s.Checksum)
+	assert.Equal(t, got.Zone.Name, "the-zone")
+
+	tampered := strings.Replace(archive, "10.0.0.1", "10.0.0.2", 1)
+	_, err = Read(strings.NewReader(tampered))
+	assert.ErrorContains(t, err, "snapshot checksum mismatch")
+
+	future := strings.Replace(archive, `"version": 1`, `"version": 2`, 1)
+	_, err = Read(strings.NewReader(future))
+	assert.ErrorContains(t, err, "unsupported snapshot version 2")
+}
+
+func TestRestoreToOtherOrg(t *testing.T) {
+	src := memory.NewEmpty("the-org", "the-user")
+	s, err := Export(src, populate(t, src))
+	assert.Nil(t, err)
+
+	dst := memory.NewEmpty("other-org", "other-user")
+	km, err := Restore(dst, s, "")
+	assert.Nil(t, err)
+	if km == nil {
+		t.FailNow()
+	}
+
+	restored, err := Export(dst, km.Zone)
+	assert.Nil(t, err)
+	assert.Equal(t, restored.Zone.Name, "the-zone")
+	assert.Equal(t, len(restored.Clusters), len(s.Clusters))
+	assert.Equal(t, len(restored.Domains), len(s.Domains))
+	assert.Equal(t, len(restored.Listeners), len(s.Listeners))
+	assert.Equal(t, len(restored.Proxies), len(s.Proxies))
+	assert.Equal(t, len(restored.SharedRules), len(s.SharedRules))
+	assert.Equal(t, len(restored.Routes), len(s.Routes))
+
+	// references are remapped consistently
+	proxy, err := dst.Proxy().Get(km.Proxies[s.Proxies[0].ProxyKey])
+	assert.Nil(t, err)
+	assert.Equal(t, proxy.OrgKey, api.OrgKey("other-org"))
+	assert.ArrayEqual(t, proxy.DomainKeys, []api.DomainKey{km.Domains[s.Domains[0].DomainKey]})
+	assert.ArrayEqual(
+		t,
+		proxy.ListenerKeys,
+		[]api.ListenerKey{km.Listeners[s.Listeners[0].ListenerKey]},
+	)
+
+	srcRoute := s.Routes[0]
+	route, err := dst.Route().Get(km.Routes[srcRoute.RouteKey])
+	assert.Nil(t, err)
+	assert.Equal(t, route.ZoneKey, km.Zone)
+	assert.Equal(t, route.DomainKey, km.Domains[srcRoute.DomainKey])
+	assert.Equal(t, route.SharedRulesKey, km.SharedRules[srcRoute.SharedRulesKey])
+	assert.Equal(
+		t,
+		route.Rules[0].Constraints.Light[0].ClusterKey,
+		km.Clusters[srcRoute.Rules[0].Constraints.Light[0].ClusterKey],
+	)
+	assert.Equal(t, route.Rules[0].RuleKey, srcRoute.Rules[0].RuleKey)
+}
+
+func TestRestoreToOtherZone(t *testing.T) {
+	svc := memory.NewEmpty("the-org", "the-user")
+	zk := populate(t, svc)
+	s, err := Export(svc, zk)
+	assert.Nil(t, err)
+
+	_, err = Restore(svc, s, zk)
+	assert.ErrorContains(t, err, "is not empty")
+
+	clone, err := svc.Zone().Create(api.Zone{Name: "the-clone"})
+	assert.Nil(t, err)
+
+	km, err := Restore(svc, s, clone.ZoneKey)
+	assert.Nil(t, err)
+	assert.Equal(t, km.Zone, clone.ZoneKey)
+
+	clusters, err := svc.Cluster().Index(service.ClusterFilter{ZoneKey: clone.ZoneKey})
+	assert.Nil(t, err)
+	assert.Equal(t, len(clusters), 2)
+	for _, c := range clusters {
+		assert.NotEqual(t, km.Clusters[c.ClusterKey], c.ClusterKey)
+	}
+}
+
+func TestSnapshotIsValid(t *testing.T) {
+	svc := memory.NewEmpty("the-org", "the-user")
+	s, err := Export(svc, populate(t, svc))
+	assert.Nil(t, err)
+
+	s.Routes[0].SharedRulesKey = "missing"
+	s.Proxies[0].ListenerKeys = []api.ListenerKey{"missing"}
+	s.Clusters[0].ZoneKey = "other-zone"
+
+	errs := s.IsValid()
+	assert.NonNil(t, errs)
+	assert.ErrorContains(t, errs, `unknown shared rules key "missing"`)
+	assert.ErrorContains(t, errs, `unknown listener key "missing"`)
+	assert.ErrorContains(t, errs, "must match the snapshot's zone")
+
+	_, err = Restore(memory.NewEmpty("other-org", "other-user"), s, "")
+	assert.ErrorContains(t, err, "validation errors")
+}
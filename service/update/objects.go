@@ -0,0 +1,391 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package update
+
+import (
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/service"
+)
+
+// Cluster fetches the api.Cluster with the given key from svc, applies mutate, and
+// submits the result via svc.Modify, retrying on checksum conflicts up to
+// DefaultMaxAttempts times.
+func Cluster(
+	svc service.Cluster,
+	key api.ClusterKey,
+	mutate func(api.Cluster) (api.Cluster, error),
+) (api.Cluster, error) {
+	return defaultUpdater.Cluster(svc, key, mutate)
+}
+
+// Cluster behaves as the package-level Cluster function, but with the Updater's
+// bound on attempts.
+func (u Updater) Cluster(
+	svc service.Cluster,
+	key api.ClusterKey,
+	mutate func(api.Cluster) (api.Cluster, error),
+) (api.Cluster, error) {
+	var result api.Cluster
+	err := u.run("cluster", string(key), func() (bool, error) {
+		current, err := svc.Get(key)
+		if err != nil {
+			return false, err
+		}
+
+		updated, err := mutate(current)
+		if err != nil {
+			return false, err
+		}
+
+		updated.Checksum = current.Checksum
+		result, err = svc.Modify(updated)
+		return IsConflict(err), err
+	})
+	if err != nil {
+		return api.Cluster{}, err
+	}
+
+	return result, nil
+}
+
+// Domain fetches the api.Domain with the given key from svc, applies mutate, and
+// submits the result via svc.Modify, retrying on checksum conflicts up to
+// DefaultMaxAttempts times.
+func Domain(
+	svc service.Domain,
+	key api.DomainKey,
+	mutate func(api.Domain) (api.Domain, error),
+) (api.Domain, error) {
+	return defaultUpdater.Domain(svc, key, mutate)
+}
+
+// Domain behaves as the package-level Domain function, but with the Updater's
+// bound on attempts.
+func (u Updater) Domain(
+	svc service.Domain,
+	key api.DomainKey,
+	mutate func(api.Domain) (api.Domain, error),
+) (api.Domain, error) {
+	var result api.Domain
+	err := u.run("domain", string(key), func() (bool, error) {
+		current, err := svc.Get(key)
+		if err != nil {
+			return false, err
+		}
+
+		updated, err := mutate(current)
+		if err != nil {
+			return false, err
+		}
+
+		updated.Checksum = current.Checksum
+		result, err = svc.Modify(updated)
+		return IsConflict(err), err
+	})
+	if err != nil {
+		return api.Domain{}, err
+	}
+
+	return result, nil
+}
+
+// Proxy fetches the api.Proxy with the given key from svc, applies mutate, and
+// submits the result via svc.Modify, retrying on checksum conflicts up to
+// DefaultMaxAttempts times.
+func Proxy(
+	svc service.Proxy,
+	key api.ProxyKey,
+	mutate func(api.Proxy) (api.Proxy, error),
+) (api.Proxy, error) {
+	return defaultUpdater.Proxy(svc, key, mutate)
+}
+
+// Proxy behaves as the package-level Proxy function, but with the Updater's
+// bound on attempts.
+func (u Updater) Proxy(
+	svc service.Proxy,
+	key api.ProxyKey,
+	mutate func(api.Proxy) (api.Proxy, error),
+) (api.Proxy, error) {
+	var result api.Proxy
+	err := u.run("proxy", string(key), func() (bool, error) {
+		current, err := svc.Get(key)
+		if err != nil {
+			return false, err
+		}
+
+		updated, err := mutate(current)
+		if err != nil {
+			return false, err
+		}
+
+		updated.Checksum = current.Checksum
+		result, err = svc.Modify(updated)
+		return IsConflict(err), err
+	})
+	if err != nil {
+		return api.Proxy{}, err
+	}
+
+	return result, nil
+}
+
+// Listener fetches the api.Listener with the given key from svc, applies mutate, and
+// submits the result via svc.Modify, retrying on checksum conflicts up to
+// DefaultMaxAttempts times.
+func Listener(
+	svc service.Listener,
+	key api.ListenerKey,
+	mutate func(api.Listener) (api.Listener, error),
+) (api.Listener, error) {
+	return defaultUpdater.Listener(svc, key, mutate)
+}
+
+// Listener behaves as the package-level Listener function, but with the Updater's
+// bound on attempts.
+func (u Updater) Listener(
+	svc service.Listener,
+	key api.ListenerKey,
+	mutate func(api.Listener) (api.Listener, error),
+) (api.Listener, error) {
+	var result api.Listener
+	err := u.run("listener", string(key), func() (bool, error) {
+		current, err := svc.Get(key)
+		if err != nil {
+			return false, err
+		}
+
+		updated, err := mutate(current)
+		if err != nil {
+			return false, err
+		}
+
+		updated.Checksum = current.Checksum
+		result, err = svc.Modify(updated)
+		return IsConflict(err), err
+	})
+	if err != nil {
+		return api.Listener{}, err
+	}
+
+	return result, nil
+}
+
+// Route fetches the api.Route with the given key from svc, applies mutate, and
+// submits the result via svc.Modify, retrying on checksum conflicts up to
+// DefaultMaxAttempts times.
+func Route(
+	svc service.Route,
+	key api.RouteKey,
+	mutate func(api.Route) (api.Route, error),
+) (api.Route, error) {
+	return defaultUpdater.Route(svc, key, mutate)
+}
+
+// Route behaves as the package-level Route function, but with the Updater's
+// bound on attempts.
+func (u Updater) Route(
+	svc service.Route,
+	key api.RouteKey,
+	mutate func(api.Route) (api.Route, error),
+) (api.Route, error) {
+	var result api.Route
+	err := u.run("route", string(key), func() (bool, error) {
+		current, err := svc.Get(key)
+		if err != nil {
+			return false, err
+		}
+
+		updated, err := mutate(current)
+		if err != nil {
+			return false, err
+		}
+
+		updated.Checksum = current.Checksum
+		result, err = svc.Modify(updated)
+		return IsConflict(err), err
+	})
+	if err != nil {
+		return api.Route{}, err
+	}
+
+	return result, nil
+}
+
+// SharedRules fetches the api.SharedRules with the given key from svc, applies mutate, and
+// submits the result via svc.Modify, retrying on checksum conflicts up to
+// DefaultMaxAttempts times.
+func SharedRules(
+	svc service.SharedRules,
+	key api.SharedRulesKey,
+	mutate func(api.SharedRules) (api.SharedRules, error),
+) (api.SharedRules, error) {
+	return defaultUpdater.SharedRules(svc, key, mutate)
+}
+
+// SharedRules behaves as the package-level SharedRules function, but with the Updater's
+// bound on attempts.
+func (u Updater) SharedRules(
+	svc service.SharedRules,
+	key api.SharedRulesKey,
+	mutate func(api.SharedRules) (api.SharedRules, error),
+) (api.SharedRules, error) {
+	var result api.SharedRules
+	err := u.run("shared_rules", string(key), func() (bool, error) {
+		current, err := svc.Get(key)
+		if err != nil {
+			return false, err
+		}
+
+		updated, err := mutate(current)
+		if err != nil {
+			return false, err
+		}
+
+		updated.Checksum = current.Checksum
+		result, err = svc.Modify(updated)
+		return IsConflict(err), err
+	})
+	if err != nil {
+		return api.SharedRules{}, err
+	}
+
+	return result, nil
+}
+
+// Zone fetches the api.Zone with the given key from svc, applies mutate, and
+// submits the result via svc.Modify, retrying on checksum conflicts up to
+// DefaultMaxAttempts times.
+func Zone(
+	svc service.Zone,
+	key api.ZoneKey,
+	mutate func(api.Zone) (api.Zone, error),
+) (api.Zone, error) {
+	return defaultUpdater.Zone(svc, key, mutate)
+}
+
+// Zone behaves as the package-level Zone function, but with the Updater's
+// bound on attempts.
+func (u Updater) Zone(
+	svc service.Zone,
+	key api.ZoneKey,
+	mutate func(api.Zone) (api.Zone, error),
+) (api.Zone, error) {
+	var result api.Zone
+	err := u.run("zone", string(key), func() (bool, error) {
+		current, err := svc.Get(key)
+		if err != nil {
+			return false, err
+		}
+
+		updated, err := mutate(current)
+		if err != nil {
+			return false, err
+		}
+
+		updated.Checksum = current.Checksum
+		result, err = svc.Modify(updated)
+		return IsConflict(err), err
+	})
+	if err != nil {
+		return api.Zone{}, err
+	}
+
+	return result, nil
+}
+
+// User fetches the api.User with the given key from svc, applies mutate, and
+// submits the result via svc.Modify, retrying on checksum conflicts up to
+// DefaultMaxAttempts times.
+func User(
+	svc service.User,
+	key api.UserKey,
+	mutate func(api.User) (api.User, error),
+) (api.User, error) {
+	return defaultUpdater.User(svc, key, mutate)
+}
+
+// User behaves as the package-level User function, but with the Updater's
+// bound on attempts.
+func (u Updater) User(
+	svc service.User,
+	key api.UserKey,
+	mutate func(api.User) (api.User, error),
+) (api.User, error) {
+	var result api.User
+	err := u.run("user", string(key), func() (bool, error) {
+		current, err := svc.Get(key)
+		if err != nil {
+			return false, err
+		}
+
+		updated, err := mutate(current)
+		if err != nil {
+			return false, err
+		}
+
+		updated.Checksum = current.Checksum
+		result, err = svc.Modify(updated)
+		return IsConflict(err), err
+	})
+	if err != nil {
+		return api.User{}, err
+	}
+
+	return result, nil
+}
+
+// Org fetches the api.Org with the given key from svc, applies mutate, and
+// submits the result via svc.Modify, retrying on checksum conflicts up to
+// DefaultMaxAttempts times.
+func Org(
+	svc service.Org,
+	key api.OrgKey,
+	mutate func(api.Org) (api.Org, error),
+) (api.Org, error) {
+	return defaultUpdater.Org(svc, key, mutate)
+}
+
+// Org behaves as the package-level Org function, but with the Updater's
+// bound on attempts.
+func (u Updater) Org(
+	svc service.Org,
+	key api.OrgKey,
+	mutate func(api.Org) (api.Org, error),
+) (api.Org, error) {
+	var result api.Org
+	err := u.run("org", string(key), func() (bool, error) {
+		current, err := svc.Get(key)
+		if err != nil {
+			return false, err
+		}
+
+		updated, err := mutate(current)
+		if err != nil {
+			return false, err
+		}
+
+		updated.Checksum = current.Checksum
+		result, err = svc.Modify(updated)
+		return IsConflict(err), err
+	})
+	if err != nil {
+		return api.Org{}, err
+	}
+
+	return result, nil
+}
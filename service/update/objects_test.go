@@ -0,0 +1,230 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package update
+
+import (
+	"testing"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/fixture"
+	"github.com/turbinelabs/api/service/memory"
+	"github.com/turbinelabs/test/assert"
+)
+
+// Each test below simulates a concurrent writer by modifying the object from
+// within the first invocation of the mutation, which forces the helper's
+// first Modify to fail with a checksum conflict.
+
+func TestClusterConcurrentWriter(t *testing.T) {
+	svc := memory.New()
+	df := fixture.New()
+
+	calls := 0
+	got, err := Cluster(
+		svc.Cluster(),
+		df.ClusterKey1,
+		func(c api.Cluster) (api.Cluster, error) {
+			calls++
+			if calls == 1 {
+				concurrent := c
+				concurrent.Name = "concurrent"
+				_, err := svc.Cluster().Modify(concurrent)
+				assert.Nil(t, err)
+			}
+			c.Name += "-updated"
+			return c, nil
+		},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, calls, 2)
+	assert.Equal(t, got.Name, "concurrent-updated")
+}
+
+func TestDomainConcurrentWriter(t *testing.T) {
+	svc := memory.New()
+	df := fixture.New()
+
+	calls := 0
+	got, err := Domain(
+		svc.Domain(),
+		df.DomainKey1,
+		func(d api.Domain) (api.Domain, error) {
+			calls++
+			if calls == 1 {
+				concurrent := d
+				concurrent.Name = "concurrent"
+				_, err := svc.Domain().Modify(concurrent)
+				assert.Nil(t, err)
+			}
+			d.Name += "-updated"
+			return d, nil
+		},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, calls, 2)
+	assert.Equal(t, got.Name, "concurrent-updated")
+}
+
+func TestProxyConcurrentWriter(t *testing.T) {
+	svc := memory.New()
+	df := fixture.New()
+
+	calls := 0
+	got, err := Proxy(
+		svc.Proxy(),
+		df.ProxyKey1,
+		func(p api.Proxy) (api.Proxy, error) {
+			calls++
+			if calls == 1 {
+				concurrent := p
+				concurrent.Name = "concurrent"
+				_, err := svc.Proxy().Modify(concurrent)
+				assert.Nil(t, err)
+			}
+			p.Name += "-updated"
+			return p, nil
+		},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, calls, 2)
+	assert.Equal(t, got.Name, "concurrent-updated")
+}
+
+func TestListenerConcurrentWriter(t *testing.T) {
+	svc := memory.New()
+	df := fixture.New()
+
+	calls := 0
+	got, err := Listener(
+		svc.Listener(),
+		df.ListenerKey1,
+		func(l api.Listener) (api.Listener, error) {
+			calls++
+			if calls == 1 {
+				concurrent := l
+				concurrent.Name = "concurrent"
+				_, err := svc.Listener().Modify(concurrent)
+				assert.Nil(t, err)
+			}
+			l.Name += "-updated"
+			return l, nil
+		},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, calls, 2)
+	assert.Equal(t, got.Name, "concurrent-updated")
+}
+
+func TestRouteConcurrentWriter(t *testing.T) {
+	svc := memory.New()
+	df := fixture.New()
+
+	calls := 0
+	got, err := Route(
+		svc.Route(),
+		df.RouteKey1,
+		func(r api.Route) (api.Route, error) {
+			calls++
+			if calls == 1 {
+				concurrent := r
+				concurrent.Path = "/concurrent"
+				_, err := svc.Route().Modify(concurrent)
+				assert.Nil(t, err)
+			}
+			r.Path += "-updated"
+			return r, nil
+		},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, calls, 2)
+	assert.Equal(t, got.Path, "/concurrent-updated")
+}
+
+func TestSharedRulesConcurrentWriter(t *testing.T) {
+	svc := memory.New()
+	df := fixture.New()
+
+	calls := 0
+	got, err := SharedRules(
+		svc.SharedRules(),
+		df.SharedRulesKey1,
+		func(sr api.SharedRules) (api.SharedRules, error) {
+			calls++
+			if calls == 1 {
+				concurrent := sr
+				concurrent.Name = "concurrent"
+				_, err := svc.SharedRules().Modify(concurrent)
+				assert.Nil(t, err)
+			}
+			sr.Name += "-updated"
+			return sr, nil
+		},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, calls, 2)
+	assert.Equal(t, got.Name, "concurrent-updated")
+}
+
+func TestZoneConcurrentWriter(t *testing.T) {
+	svc := memory.New()
+	df := fixture.New()
+
+	calls := 0
+	got, err := Zone(
+		svc.Zone(),
+		df.ZoneKey1,
+		func(z api.Zone) (api.Zone, error) {
+			calls++
+			if calls == 1 {
+				concurrent := z
+				concurrent.Name = "concurrent"
+				_, err := svc.Zone().Modify(concurrent)
+				assert.Nil(t, err)
+			}
+			z.Name += "-updated"
+			return z, nil
+		},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, calls, 2)
+	assert.Equal(t, got.Name, "concurrent-updated")
+}
+
+func TestUserConcurrentWriter(t *testing.T) {
+	svc := memory.New()
+	df := fixture.New()
+
+	calls := 0
+	got, err := User(
+		svc.User(),
+		df.UserKey1,
+		func(u api.User) (api.User, error) {
+			calls++
+			if calls == 1 {
+				concurrent := u
+				concurrent.LoginEmail = "concurrent@example.com"
+				_, err := svc.User().Modify(concurrent)
+				assert.Nil(t, err)
+			}
+			u.LoginEmail += ".au"
+			return u, nil
+		},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, calls, 2)
+	assert.Equal(t, got.LoginEmail, "concurrent@example.com.au")
+}
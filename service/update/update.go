@@ -0,0 +1,123 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package update provides optimistic-concurrency helpers for modifying
+// objects via the service interfaces. Each helper fetches the current
+// version of an object, applies a caller-supplied mutation, and submits the
+// result with the fetched checksum. If the Modify is rejected because the
+// object changed in the meantime, the object is re-fetched and the mutation
+// re-applied, up to a bounded number of attempts:
+//
+// 	cluster, err := update.Cluster(
+// 		svc.Cluster(),
+// 		clusterKey,
+// 		func(c api.Cluster) (api.Cluster, error) {
+// 			c.RequireTLS = true
+// 			return c, nil
+// 		},
+// 	)
+//
+// Because it may be invoked more than once, the mutation must be a pure
+// function of the object it is given.
+package update
+
+import (
+	"fmt"
+
+	httperr "github.com/turbinelabs/api/http/error"
+)
+
+// DefaultMaxAttempts is the number of attempts made by the package-level
+// update functions before giving up with a *ConflictError.
+const DefaultMaxAttempts = 5
+
+// ConflictError is returned when every attempt to modify an object failed
+// because of a checksum conflict.
+type ConflictError struct {
+	// ObjectType is the type of the object being updated (e.g. "cluster").
+	ObjectType string
+
+	// Key is the key of the object being updated.
+	Key string
+
+	// Attempts is the number of attempts made.
+	Attempts int
+
+	// Err is the conflict error returned by the final attempt.
+	Err error
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf(
+		"could not update %s %q after %d attempts: %s",
+		e.ObjectType,
+		e.Key,
+		e.Attempts,
+		e.Err.Error(),
+	)
+}
+
+// IsConflict returns true if the given error indicates that a Modify failed
+// because the submitted checksum did not match the current version of the
+// object.
+func IsConflict(err error) bool {
+	if herr, ok := err.(*httperr.Error); ok {
+		return herr.Code == httperr.UnknownModificationConflict || herr.Status == 409
+	}
+	return false
+}
+
+// Updater applies updates with a configurable bound on the number of
+// attempts.
+type Updater struct {
+	maxAttempts int
+}
+
+// New returns an Updater that makes at most maxAttempts attempts at each
+// update. Values less than 1 are treated as 1.
+func New(maxAttempts int) Updater {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return Updater{maxAttempts: maxAttempts}
+}
+
+var defaultUpdater = New(DefaultMaxAttempts)
+
+// run invokes attempt until it succeeds, fails without requesting a retry,
+// or the maximum number of attempts is reached. In the last case, a
+// *ConflictError is returned.
+func (u Updater) run(objectType, key string, attempt func() (bool, error)) error {
+	maxAttempts := u.maxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var err error
+	for i := 0; i < maxAttempts; i++ {
+		var retry bool
+		if retry, err = attempt(); !retry {
+			return err
+		}
+	}
+
+	return &ConflictError{
+		ObjectType: objectType,
+		Key:        key,
+		Attempts:   maxAttempts,
+		Err:        err,
+	}
+}
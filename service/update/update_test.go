@@ -0,0 +1,174 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package update
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/turbinelabs/api"
+	httperr "github.com/turbinelabs/api/http/error"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/test/assert"
+)
+
+var conflict = httperr.New409("checksum mismatch", httperr.UnknownModificationConflict)
+
+func TestIsConflict(t *testing.T) {
+	assert.True(t, IsConflict(conflict))
+	assert.True(t, IsConflict(&httperr.Error{Code: httperr.MiscErrorCode, Status: 409}))
+	assert.False(t, IsConflict(httperr.New400("bad", httperr.BadParameterErrorCode)))
+	assert.False(t, IsConflict(errors.New("boom")))
+	assert.False(t, IsConflict(nil))
+}
+
+func TestConflictError(t *testing.T) {
+	err := &ConflictError{ObjectType: "cluster", Key: "ck", Attempts: 3, Err: conflict}
+	assert.ErrorContains(t, err, `could not update cluster "ck" after 3 attempts`)
+	assert.ErrorContains(t, err, "checksum mismatch")
+}
+
+func TestNew(t *testing.T) {
+	assert.Equal(t, New(3).maxAttempts, 3)
+	assert.Equal(t, New(0).maxAttempts, 1)
+	assert.Equal(t, defaultUpdater.maxAttempts, DefaultMaxAttempts)
+}
+
+func TestClusterRetriesOnConflict(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	v1 := api.Cluster{ClusterKey: "ck", Name: "v1", Checksum: api.Checksum{Checksum: "1"}}
+	v2 := api.Cluster{ClusterKey: "ck", Name: "v2", Checksum: api.Checksum{Checksum: "2"}}
+
+	modified := v2
+	modified.RequireTLS = true
+
+	want := modified
+	want.Checksum = api.Checksum{Checksum: "3"}
+
+	svc := service.NewMockCluster(ctrl)
+	gomock.InOrder(
+		svc.EXPECT().Get(api.ClusterKey("ck")).Return(v1, nil),
+		svc.EXPECT().Modify(gomock.Any()).Return(api.Cluster{}, conflict),
+		svc.EXPECT().Get(api.ClusterKey("ck")).Return(v2, nil),
+		svc.EXPECT().Modify(modified).Return(want, nil),
+	)
+
+	calls := 0
+	got, err := Cluster(svc, "ck", func(c api.Cluster) (api.Cluster, error) {
+		calls++
+		c.RequireTLS = true
+		// the helper always submits the fetched checksum
+		c.Checksum = api.Checksum{Checksum: "bogus"}
+		return c, nil
+	})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, want)
+	assert.Equal(t, calls, 2)
+}
+
+func TestClusterConflictsExhausted(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	svc := service.NewMockCluster(ctrl)
+	svc.EXPECT().Get(api.ClusterKey("ck")).Return(api.Cluster{ClusterKey: "ck"}, nil).Times(2)
+	svc.EXPECT().Modify(gomock.Any()).Return(api.Cluster{}, conflict).Times(2)
+
+	got, err := New(2).Cluster(svc, "ck", func(c api.Cluster) (api.Cluster, error) {
+		return c, nil
+	})
+	assert.DeepEqual(t, got, api.Cluster{})
+	assert.DeepEqual(
+		t,
+		err,
+		&ConflictError{ObjectType: "cluster", Key: "ck", Attempts: 2, Err: conflict},
+	)
+}
+
+func TestClusterGetError(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	notFound := httperr.New404("nope", httperr.NotFoundErrorCode)
+
+	svc := service.NewMockCluster(ctrl)
+	svc.EXPECT().Get(api.ClusterKey("ck")).Return(api.Cluster{}, notFound)
+
+	_, err := Cluster(svc, "ck", func(c api.Cluster) (api.Cluster, error) {
+		t.Error("unexpected call to mutate")
+		return c, nil
+	})
+	assert.Equal(t, err, error(notFound))
+}
+
+func TestClusterMutateError(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	mutateErr := errors.New("boom")
+
+	svc := service.NewMockCluster(ctrl)
+	svc.EXPECT().Get(api.ClusterKey("ck")).Return(api.Cluster{ClusterKey: "ck"}, nil)
+
+	_, err := Cluster(svc, "ck", func(c api.Cluster) (api.Cluster, error) {
+		return c, mutateErr
+	})
+	assert.Equal(t, err, mutateErr)
+}
+
+func TestClusterModifyError(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	invalid := httperr.New400("invalid", httperr.InvalidObjectErrorCode)
+
+	svc := service.NewMockCluster(ctrl)
+	svc.EXPECT().Get(api.ClusterKey("ck")).Return(api.Cluster{ClusterKey: "ck"}, nil)
+	svc.EXPECT().Modify(gomock.Any()).Return(api.Cluster{}, invalid)
+
+	_, err := Cluster(svc, "ck", func(c api.Cluster) (api.Cluster, error) {
+		return c, nil
+	})
+	assert.Equal(t, err, error(invalid))
+}
+
+func TestOrg(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	current := api.Org{OrgKey: "ok", Name: "org", Checksum: api.Checksum{Checksum: "1"}}
+
+	modified := current
+	modified.ContactEmail = "org@example.com"
+
+	want := modified
+	want.Checksum = api.Checksum{Checksum: "2"}
+
+	svc := service.NewMockOrg(ctrl)
+	svc.EXPECT().Get(api.OrgKey("ok")).Return(current, nil)
+	svc.EXPECT().Modify(modified).Return(want, nil)
+
+	got, err := Org(svc, "ok", func(o api.Org) (api.Org, error) {
+		o.ContactEmail = "org@example.com"
+		return o, nil
+	})
+	assert.Nil(t, err)
+	assert.DeepEqual(t, got, want)
+}
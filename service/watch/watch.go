@@ -0,0 +1,341 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package watch delivers changes recorded by the changelog as a stream of
+// Events, by polling service.HistoryContext.Index:
+//
+// 	s := watch.Watch(
+// 		ctx,
+// 		service.ToAllContext(svc).History(),
+// 		changelog.Filter{ObjectType: "cluster"},
+// 		cursor,
+// 		watch.Options{},
+// 	)
+// 	for event := range s.Events() {
+// 		...
+// 		saveCursor(event.Cursor)
+// 	}
+// 	if err := s.Err(); err != nil {
+// 		...
+// 	}
+//
+// Each poll queries a window beginning somewhat before the end of the
+// previous one, so that changes recorded late are not missed, and changes
+// are de-duplicated by transaction. Windows longer than the maximum the
+// History API accepts are read in several polls.
+//
+// Events are delivered at least once: an Event's Cursor may be persisted
+// and passed to Watch to resume the stream after a restart.
+package watch
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/objecttype"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/api/service/changelog"
+	tbntime "github.com/turbinelabs/nonstdlib/time"
+)
+
+const (
+	// DefaultPollInterval is the default time between polls.
+	DefaultPollInterval = 10 * time.Second
+
+	// DefaultOverlap is the default amount by which successive polling
+	// windows overlap.
+	DefaultOverlap = time.Minute
+
+	// DefaultMaxWindow is the default maximum window size, matching the
+	// maximum documented by service.History.
+	DefaultMaxWindow = 24 * time.Hour
+)
+
+// Options configure a Stream. Zero values are replaced by the defaults.
+type Options struct {
+	// PollInterval is the time between polls.
+	PollInterval time.Duration
+
+	// Overlap is the amount by which successive polling windows overlap. It
+	// should exceed the longest delay between a change's timestamp and its
+	// appearance in the changelog.
+	Overlap time.Duration
+
+	// MaxWindow is the largest window queried by a single poll.
+	MaxWindow time.Duration
+
+	// Time is the source of the current time.
+	Time tbntime.Source
+}
+
+func (o Options) withDefaults() Options {
+	if o.PollInterval <= 0 {
+		o.PollInterval = DefaultPollInterval
+	}
+	if o.Overlap <= 0 {
+		o.Overlap = DefaultOverlap
+	}
+	if o.MaxWindow <= o.Overlap {
+		o.MaxWindow = DefaultMaxWindow
+	}
+	if o.Time == nil {
+		o.Time = tbntime.NewSource()
+	}
+	return o
+}
+
+// Cursor is a position in the changelog. It may be serialized as JSON.
+type Cursor struct {
+	// At is the time up to which the changelog has been read. If zero, the
+	// stream begins at the current time.
+	At time.Time `json:"at"`
+
+	// Txns are the transactions delivered at or after At, less the Overlap.
+	Txns []string `json:"txns,omitempty"`
+}
+
+// Event describes the changes made to a single object by a single
+// transaction.
+type Event struct {
+	// ObjectType is the type of the changed object.
+	ObjectType objecttype.ObjectType
+
+	// ObjectKey is the key of the changed object.
+	ObjectKey string
+
+	// ZoneKey is the Zone of the changed object, if any.
+	ZoneKey api.ZoneKey
+
+	// Meta describes the transaction.
+	Meta api.ChangeMeta
+
+	// Changes are the ChangeEntries recorded for the object.
+	Changes []api.ChangeEntry
+
+	// Cursor is a position from which the stream may be resumed. Events
+	// delivered before this one need not be delivered again.
+	Cursor Cursor
+}
+
+// Stream is a stream of Events.
+type Stream struct {
+	events chan Event
+
+	mu     sync.Mutex
+	cursor Cursor
+	err    error
+}
+
+// Events returns the channel on which Events are delivered. Polling waits
+// until each Event has been received. The channel is closed when the
+// stream's context is cancelled or polling fails.
+func (s *Stream) Events() <-chan Event {
+	return s.events
+}
+
+// Err returns the error that ended the stream, if any. It should be called
+// after the Events channel is closed.
+func (s *Stream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Cursor returns the position of the stream after the most recently
+// received Event, or the most recently completed poll.
+func (s *Stream) Cursor() Cursor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyCursor(s.cursor)
+}
+
+// Watch starts a Stream of the changes matching filter, beginning at
+// cursor.
+func Watch(
+	ctx context.Context,
+	svc service.HistoryContext,
+	filter changelog.FilterExpr,
+	cursor Cursor,
+	options Options,
+) *Stream {
+	options = options.withDefaults()
+	if cursor.At.IsZero() {
+		cursor.At = options.Time.Now()
+	}
+
+	s := &Stream{events: make(chan Event), cursor: copyCursor(cursor)}
+	w := &watcher{
+		svc:     svc,
+		filter:  filter,
+		options: options,
+		stream:  s,
+		cursor:  copyCursor(cursor),
+	}
+	go w.run(ctx)
+	return s
+}
+
+type watcher struct {
+	svc     service.HistoryContext
+	filter  changelog.FilterExpr
+	options Options
+	stream  *Stream
+	cursor  Cursor
+}
+
+func (w *watcher) run(ctx context.Context) {
+	defer close(w.stream.events)
+
+	timer := w.options.Time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+		}
+
+		caughtUp, err := w.poll(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.stream.mu.Lock()
+				w.stream.err = err
+				w.stream.mu.Unlock()
+			}
+			return
+		}
+
+		if caughtUp {
+			timer.Reset(w.options.PollInterval)
+		} else {
+			timer.Reset(0)
+		}
+	}
+}
+
+// poll reads the next window of the changelog, delivering any new changes.
+// It returns true if the window ended at the current time.
+func (w *watcher) poll(ctx context.Context) (bool, error) {
+	now := w.options.Time.Now()
+	start := w.cursor.At.Add(-w.options.Overlap)
+	end := now
+	caughtUp := true
+	if end.Sub(start) > w.options.MaxWindow {
+		end = start.Add(w.options.MaxWindow)
+		caughtUp = false
+	}
+	if !end.After(start) {
+		return true, nil
+	}
+
+	changes, err := w.svc.Index(ctx, w.filter, start, end)
+	if err != nil {
+		return false, err
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].AtMs < changes[j].AtMs
+	})
+
+	seen := map[string]bool{}
+	for _, txn := range w.cursor.Txns {
+		seen[txn] = true
+	}
+
+	for _, cd := range changes {
+		if seen[cd.Txn] {
+			continue
+		}
+
+		events := split(cd)
+		for i := range events {
+			if i == len(events)-1 {
+				seen[cd.Txn] = true
+				w.cursor.Txns = append(w.cursor.Txns, cd.Txn)
+			}
+			events[i].Cursor = copyCursor(w.cursor)
+
+			select {
+			case <-ctx.Done():
+				return false, ctx.Err()
+			case w.stream.events <- events[i]:
+			}
+
+			w.stream.mu.Lock()
+			w.stream.cursor = events[i].Cursor
+			w.stream.mu.Unlock()
+		}
+	}
+
+	// Transactions are remembered for as long as they may be returned by
+	// the next poll.
+	w.cursor.At = end
+	at := map[string]time.Time{}
+	for _, cd := range changes {
+		at[cd.Txn] = cd.At()
+	}
+	txns := []string{}
+	for _, txn := range w.cursor.Txns {
+		if t, ok := at[txn]; ok && !t.Before(end.Add(-w.options.Overlap)) {
+			txns = append(txns, txn)
+		}
+	}
+	w.cursor.Txns = txns
+
+	w.stream.mu.Lock()
+	w.stream.cursor = copyCursor(w.cursor)
+	w.stream.mu.Unlock()
+
+	return caughtUp, nil
+}
+
+// split divides a ChangeDescription into Events for each object, in the
+// order in which they first appear.
+func split(cd api.ChangeDescription) []Event {
+	events := []Event{}
+	index := map[objecttype.ObjectType]map[string]int{}
+	for _, e := range cd.Diffs {
+		keys := index[e.ObjectType]
+		if keys == nil {
+			keys = map[string]int{}
+			index[e.ObjectType] = keys
+		}
+
+		i, ok := keys[e.ObjectKey]
+		if !ok {
+			i = len(events)
+			keys[e.ObjectKey] = i
+			events = append(events, Event{
+				ObjectType: e.ObjectType,
+				ObjectKey:  e.ObjectKey,
+				ZoneKey:    e.ZoneKey,
+				Meta:       cd.ChangeMeta,
+			})
+		}
+		events[i].Changes = append(events[i].Changes, e)
+	}
+	return events
+}
+
+func copyCursor(c Cursor) Cursor {
+	if c.Txns != nil {
+		c.Txns = append([]string(nil), c.Txns...)
+	}
+	return c
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/api/changetype"
+	"github.com/turbinelabs/api/objecttype"
+	"github.com/turbinelabs/api/service"
+	"github.com/turbinelabs/api/service/changelog"
+	"github.com/turbinelabs/api/service/memory"
+	tbntime "github.com/turbinelabs/nonstdlib/time"
+	"github.com/turbinelabs/test/assert"
+)
+
+var (
+	now    = time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC)
+	filter = changelog.Filter{ObjectType: "cluster"}
+)
+
+func testOptions() Options {
+	return Options{
+		PollInterval: time.Hour,
+		Overlap:      time.Minute,
+		MaxWindow:    24 * time.Hour,
+		Time:         tbntime.NewControlledSource(now),
+	}
+}
+
+func change(txn string, at time.Time, keys ...string) api.ChangeDescription {
+	cd := api.ChangeDescription{ChangeMeta: api.ChangeMeta{Txn: txn}}
+	cd.SetAt(at)
+	for _, k := range keys {
+		cd.Diffs = append(cd.Diffs, api.ChangeEntry{
+			ObjectType: objecttype.Cluster,
+			ObjectKey:  k,
+			ZoneKey:    "zk",
+			ChangeType: changetype.Addition,
+			Path:       "cluster.name",
+			Value:      k,
+		})
+	}
+	return cd
+}
+
+func receive(t *testing.T, s *Stream) Event {
+	select {
+	case e, ok := <-s.Events():
+		assert.True(t, ok)
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return Event{}
+}
+
+func waitClosed(t *testing.T, s *Stream) {
+	select {
+	case e, ok := <-s.Events():
+		assert.False(t, ok)
+		assert.DeepEqual(t, e, Event{})
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for close")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a := change("a", now.Add(-29*time.Hour), "c1")
+	// b falls within the overlap of the two windows
+	b := change("b", now.Add(-6*time.Hour-90*time.Second), "c1", "c2", "c1")
+	c := change("c", now.Add(-30*time.Second), "c3")
+
+	svc := service.NewMockHistoryContext(ctrl)
+	gomock.InOrder(
+		// the window is limited to MaxWindow
+		svc.EXPECT().
+			Index(gomock.Any(), filter, now.Add(-30*time.Hour-time.Minute), now.Add(-6*time.Hour-time.Minute)).
+			Return([]api.ChangeDescription{b, a}, nil),
+		svc.EXPECT().
+			Index(gomock.Any(), filter, now.Add(-6*time.Hour-2*time.Minute), now).
+			Return([]api.ChangeDescription{b, c}, nil),
+	)
+
+	s := Watch(ctx, svc, filter, Cursor{At: now.Add(-30 * time.Hour)}, testOptions())
+
+	e := receive(t, s)
+	assert.Equal(t, e.ObjectKey, "c1")
+	assert.Equal(t, e.Meta.Txn, "a")
+	assert.DeepEqual(t, e.Cursor.Txns, []string{"a"})
+
+	e = receive(t, s)
+	assert.Equal(t, e.ObjectType, objecttype.Cluster)
+	assert.Equal(t, e.ObjectKey, "c1")
+	assert.Equal(t, e.ZoneKey, api.ZoneKey("zk"))
+	assert.DeepEqual(t, e.Changes, []api.ChangeEntry{b.Diffs[0], b.Diffs[2]})
+	// the transaction is incomplete until its last event
+	assert.DeepEqual(t, e.Cursor, Cursor{At: now.Add(-30 * time.Hour), Txns: []string{"a"}})
+
+	e = receive(t, s)
+	assert.Equal(t, e.ObjectKey, "c2")
+	assert.DeepEqual(t, e.Cursor.Txns, []string{"a", "b"})
+
+	// b is not delivered again
+	e = receive(t, s)
+	assert.Equal(t, e.Meta.Txn, "c")
+	assert.DeepEqual(t, e.Cursor, Cursor{At: now.Add(-6*time.Hour - time.Minute), Txns: []string{"b", "c"}})
+
+	cancel()
+	waitClosed(t, s)
+	assert.Nil(t, s.Err())
+	assert.DeepEqual(t, s.Cursor(), Cursor{At: now, Txns: []string{"c"}})
+}
+
+func TestWatchResume(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a := change("a", now.Add(-time.Hour), "c1")
+	b := change("b", now.Add(-time.Hour), "c2")
+
+	svc := service.NewMockHistoryContext(ctrl)
+	svc.EXPECT().
+		Index(gomock.Any(), filter, now.Add(-time.Hour-time.Minute), now).
+		Return([]api.ChangeDescription{a, b}, nil)
+
+	s := Watch(ctx, svc, filter, Cursor{At: now.Add(-time.Hour), Txns: []string{"a"}}, testOptions())
+
+	e := receive(t, s)
+	assert.Equal(t, e.Meta.Txn, "b")
+
+	cancel()
+	waitClosed(t, s)
+}
+
+func TestWatchError(t *testing.T) {
+	ctrl := gomock.NewController(assert.Tracing(t))
+	defer ctrl.Finish()
+
+	boom := errors.New("boom")
+	svc := service.NewMockHistoryContext(ctrl)
+	svc.EXPECT().
+		Index(gomock.Any(), filter, now.Add(-time.Minute), now).
+		Return(nil, boom)
+
+	s := Watch(context.Background(), svc, filter, Cursor{}, testOptions())
+	waitClosed(t, s)
+	assert.Equal(t, s.Err(), boom)
+	assert.DeepEqual(t, s.Cursor(), Cursor{At: now})
+}
+
+func TestWatchMemory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	svc := memory.NewEmpty("ok", "uk")
+	z, err := svc.Zone().Create(api.Zone{Name: "z"})
+	assert.Nil(t, err)
+
+	s := Watch(
+		ctx,
+		service.ToAllContext(svc).History(),
+		filter,
+		Cursor{At: time.Now().Add(-time.Minute)},
+		Options{PollInterval: 5 * time.Millisecond},
+	)
+
+	c, err := svc.Cluster().Create(api.Cluster{ZoneKey: z.ZoneKey, Name: "c"})
+	assert.Nil(t, err)
+
+	e := receive(t, s)
+	assert.Equal(t, e.ObjectType, objecttype.Cluster)
+	assert.Equal(t, e.ObjectKey, string(c.ClusterKey))
+	assert.Equal(t, e.ZoneKey, z.ZoneKey)
+	var name []api.ChangeEntry
+	for _, e := range e.Changes {
+		if e.Path == "cluster.name" {
+			name = append(name, e)
+		}
+	}
+	assert.ArrayEqual(t, name, []api.ChangeEntry{
+		{
+			ObjectType: objecttype.Cluster,
+			ObjectKey:  string(c.ClusterKey),
+			ZoneKey:    z.ZoneKey,
+			ChangeType: changetype.Addition,
+			Path:       "cluster.name",
+			Value:      "c",
+		},
+	})
+
+	c.Name = "c2"
+	_, err = svc.Cluster().Modify(c)
+	assert.Nil(t, err)
+
+	e = receive(t, s)
+	assert.Equal(t, e.ObjectKey, string(c.ClusterKey))
+	assert.Equal(t, len(e.Changes), 2)
+
+	cancel()
+	waitClosed(t, s)
+	assert.Nil(t, s.Err())
+}
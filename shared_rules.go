@@ -38,18 +38,21 @@ type SharedRulesKey string
   precedence.
 
   See CohortSeed docs for additional details of what a cohort seed does.
+  A FaultInjection resolves the same way; see FaultInjectionFor.
 */
 type SharedRules struct {
-	SharedRulesKey SharedRulesKey `json:"shared_rules_key"` // overwritten for create
-	Name           string         `json:"name"`
-	ZoneKey        ZoneKey        `json:"zone_key"`
-	Default        AllConstraints `json:"default"`
-	Rules          Rules          `json:"rules"`
-	ResponseData   ResponseData   `json:"response_data"`
-	CohortSeed     *CohortSeed    `json:"cohort_seed"`
-	Properties     Metadata       `json:"properties"`
-	RetryPolicy    *RetryPolicy   `json:"retry_policy"`
-	OrgKey         OrgKey         `json:"-"`
+	SharedRulesKey SharedRulesKey  `json:"shared_rules_key"` // overwritten for create
+	Name           string          `json:"name"`
+	ZoneKey        ZoneKey         `json:"zone_key"`
+	Default        AllConstraints  `json:"default"`
+	Rules          Rules           `json:"rules"`
+	ResponseData   ResponseData    `json:"response_data"`
+	RequestData    RequestData     `json:"request_data"`
+	CohortSeed     *CohortSeed     `json:"cohort_seed"`
+	Properties     Metadata        `json:"properties"`
+	RetryPolicy    *RetryPolicy    `json:"retry_policy"`
+	FaultInjection *FaultInjection `json:"fault_injection"`
+	OrgKey         OrgKey          `json:"-"`
 	Checksum
 }
 
@@ -75,12 +78,14 @@ func (r SharedRules) Equals(o SharedRules) bool {
 		eqCS   = r.Checksum.Equals(o.Checksum)
 		eqOrg  = r.OrgKey == o.OrgKey
 		eqRd   = r.ResponseData.Equals(o.ResponseData)
+		eqReqD = r.RequestData.Equals(o.RequestData)
 		eqCs   = CohortSeedPtrEquals(r.CohortSeed, o.CohortSeed)
 		eqPr   = r.Properties.Equals(o.Properties)
 		eqRp   = RetryPolicyEquals(r.RetryPolicy, o.RetryPolicy)
+		eqFi   = FaultInjectionEquals(r.FaultInjection, o.FaultInjection)
 	)
 
-	if !(eqKey && eqName && eqZone && eqCS && eqOrg && eqRd && eqCs && eqPr && eqRp) {
+	if !(eqKey && eqName && eqZone && eqCS && eqOrg && eqRd && eqReqD && eqCs && eqPr && eqRp && eqFi) {
 		return false
 	}
 
@@ -101,6 +106,7 @@ func (r SharedRules) IsValid() *ValidationError {
 	errs.MergePrefixed(r.Default.IsValid("default"), "shared_rules")
 	errs.MergePrefixed(r.Rules.IsValid(), "shared_rules")
 	errs.MergePrefixed(r.ResponseData.IsValid(), scope("response_data"))
+	errs.MergePrefixed(r.RequestData.IsValid(), scope("request_data"))
 	if r.CohortSeed != nil {
 		errs.MergePrefixed(r.CohortSeed.IsValid(), "shared_rules")
 	}
@@ -108,6 +114,9 @@ func (r SharedRules) IsValid() *ValidationError {
 	if r.RetryPolicy != nil {
 		errs.MergePrefixed(r.RetryPolicy.IsValid(), "shared_rules")
 	}
+	if r.FaultInjection != nil {
+		errs.MergePrefixed(r.FaultInjection.IsValid(), "shared_rules")
+	}
 
 	return errs.OrNil()
 }
@@ -31,6 +31,7 @@ func getSharedRulesDefaults() (SharedRules, SharedRules) {
 				Metadata{{"k", "v"}, {"k2", "v2"}},
 				Metadata{{"state", "released"}},
 				getRD(),
+				getReqD(),
 				23}}}
 
 	rule1, rule2 := getRulesDefaults()
@@ -43,9 +44,11 @@ func getSharedRulesDefaults() (SharedRules, SharedRules) {
 		defaultCC,
 		rules,
 		getRD(),
+		getReqD(),
 		&CohortSeed{CohortSeedHeader, "x-cohort-data", false},
 		Metadata{{"pk", "pv"}, {"pk2", "pv2"}},
 		&RetryPolicy{1, 30, 60},
+		mkFI(),
 		"1",
 		Checksum{"cs-1"},
 	}
@@ -57,9 +60,11 @@ func getSharedRulesDefaults() (SharedRules, SharedRules) {
 		defaultCC,
 		rules,
 		getRD(),
+		getReqD(),
 		&CohortSeed{CohortSeedHeader, "x-cohort-data", false},
 		Metadata{{"pk", "pv"}, {"pk2", "pv2"}},
 		&RetryPolicy{1, 30, 60},
+		mkFI(),
 		"1",
 		Checksum{"cs-1"},
 	}
@@ -108,6 +113,22 @@ func TestSharedRulesEqualsRetryPolicyVaries(t *testing.T) {
 	assert.False(t, r2.Equals(r1))
 }
 
+func TestSharedRulesEqualsFaultInjectionNilNotNil(t *testing.T) {
+	r1, r2 := getSharedRulesDefaults()
+	r1.FaultInjection = nil
+
+	assert.False(t, r1.Equals(r2))
+	assert.False(t, r2.Equals(r1))
+}
+
+func TestSharedRulesEqualsFaultInjectionVaries(t *testing.T) {
+	r1, r2 := getSharedRulesDefaults()
+	r2.FaultInjection.Delay.Percent = 99
+
+	assert.False(t, r1.Equals(r2))
+	assert.False(t, r2.Equals(r1))
+}
+
 func TestSharedRulesEqualsCohortSeedNilNil(t *testing.T) {
 	r1, r2 := getSharedRulesDefaults()
 	r1.CohortSeed = nil
@@ -141,6 +162,14 @@ func TestSharedRulesEqualsCohortSeedVaries(t *testing.T) {
 	assert.False(t, r2.Equals(r1))
 }
 
+func TestSharedRulesEqualsRequestDataVaries(t *testing.T) {
+	r1, r2 := getSharedRulesDefaults()
+	r1.RequestData.Headers[0].Value += "-new"
+
+	assert.False(t, r1.Equals(r2))
+	assert.False(t, r2.Equals(r1))
+}
+
 func TestSharedRulesEqualsResponseDataVaries(t *testing.T) {
 	r1, r2 := getSharedRulesDefaults()
 	r1.ResponseData.Headers[0].Value += "-new"
@@ -184,8 +213,8 @@ func TestSharedRulesEqualsZoneKeyVaries(t *testing.T) {
 func TestSharedRulesEqualsDefaultVaries(t *testing.T) {
 	defaultCC := AllConstraints{
 		Light: ClusterConstraints{
-			ClusterConstraint{"cckey1", "ckey1", Metadata{{"k1", "v1"}, {"k2", "v2"}}, nil, ResponseData{}, 23},
-			ClusterConstraint{"cckey2", "ckey2", Metadata{{"k2", "v2"}, {"k2", "v2"}}, nil, ResponseData{}, 23}}}
+			ClusterConstraint{"cckey1", "ckey1", Metadata{{"k1", "v1"}, {"k2", "v2"}}, nil, ResponseData{}, RequestData{}, 23},
+			ClusterConstraint{"cckey2", "ckey2", Metadata{{"k2", "v2"}, {"k2", "v2"}}, nil, ResponseData{}, RequestData{}, 23}}}
 
 	r1, r2 := getSharedRulesDefaults()
 	r1.Default = defaultCC
@@ -227,6 +256,16 @@ func TestSharedRulesIsValidSuccess(t *testing.T) {
 	assert.Nil(t, r.IsValid())
 }
 
+func TestSharedRulesIsValidBadRequestData(t *testing.T) {
+	r, _ := getSharedRulesDefaults()
+	r.RequestData.Headers[0].Action = "replace"
+	n := r.RequestData.Headers[0].Name
+
+	assert.DeepEqual(t, r.IsValid(), &ValidationError{[]ErrorCase{
+		{"shared_rules.request_data.headers[" + n + "].action", `"replace" is not a valid action`},
+	}})
+}
+
 func TestSharedRulesIsValidBadResponseData(t *testing.T) {
 	r, _ := getSharedRulesDefaults()
 	r.ResponseData.Headers[0].Value = ""
@@ -308,6 +347,16 @@ func TestSharedRulesIsValidBadRetryPolicy(t *testing.T) {
 	}})
 }
 
+func TestSharedRulesIsValidBadFaultInjection(t *testing.T) {
+	r, _ := getSharedRulesDefaults()
+	r.FaultInjection.Abort = nil
+	r.FaultInjection.Delay = nil
+
+	assert.DeepEqual(t, r.IsValid(), &ValidationError{[]ErrorCase{
+		{"shared_rules.fault_injection", "must have a delay or an abort"},
+	}})
+}
+
 func TestSharedRulesIsValidNoCohort(t *testing.T) {
 	r, _ := getSharedRulesDefaults()
 	r.CohortSeed = nil
@@ -349,12 +398,12 @@ func TestSharedRulesIsValidNoMetadataNoProperty(t *testing.T) {
 func TestSharedRulesIsValidBadRules(t *testing.T) {
 	r, _ := getSharedRulesDefaults()
 	rule1, _ := getRulesDefaults()
-	rule1.Methods = []string{"WHEE"}
+	rule1.Methods = []string{"WHEE?"}
 	r.Rules[0] = rule1
 	r.Default.Light[0].Weight = 0
 
 	assert.DeepEqual(t, r.IsValid(), &ValidationError{[]ErrorCase{
 		{"shared_rules.default.light[cckey1].weight", "must be greater than 0"},
-		{"shared_rules.rules[rk0].methods", "WHEE is not a valid method"},
+		{"shared_rules.rules[rk0].methods", "WHEE? is not a valid method"},
 	}})
 }
@@ -0,0 +1,171 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulate
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/turbinelabs/api"
+)
+
+// matchRule determines whether the Rule applies to the request. If so, it
+// returns the metadata constraints derived from the Rule's Matches.
+func matchRule(r api.Rule, req Request) (api.Metadata, bool, error) {
+	if len(r.Methods) > 0 {
+		ok := false
+		for _, m := range r.Methods {
+			if strings.EqualFold(m, req.Method) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return nil, false, nil
+		}
+	}
+
+	var constraints api.Metadata
+	for _, m := range r.Matches {
+		md, ok, err := matchMatch(m, req)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		if md != nil {
+			constraints = append(constraints, *md)
+		}
+	}
+
+	return constraints, true, nil
+}
+
+// requestValue returns the value of the request attribute named by the
+// Match's Kind and From.Key, and whether it is present.
+func requestValue(m api.Match, req Request) (string, bool) {
+	switch m.Kind {
+	case api.HeaderMatchKind:
+		values, ok := req.Headers[http.CanonicalHeaderKey(m.From.Key)]
+		if !ok || len(values) == 0 {
+			return "", false
+		}
+		return values[0], true
+
+	case api.CookieMatchKind:
+		v, ok := req.Cookies[m.From.Key]
+		return v, ok
+
+	case api.QueryMatchKind:
+		values, ok := req.Query[m.From.Key]
+		if !ok || len(values) == 0 {
+			return "", false
+		}
+		return values[0], true
+
+	case api.PathMatchKind:
+		return req.Path, true
+
+	case api.SchemeMatchKind:
+		return requestScheme(req), true
+
+	case api.SourceIPMatchKind:
+		return req.SourceIP, req.SourceIP != ""
+
+	case api.HostMatchKind:
+		return req.Host, true
+	}
+
+	return "", false
+}
+
+// requestScheme returns the request's Scheme, its X-Forwarded-Proto header,
+// or "http".
+func requestScheme(req Request) string {
+	switch {
+	case req.Scheme != "":
+		return strings.ToLower(req.Scheme)
+	case req.Headers.Get("X-Forwarded-Proto") != "":
+		return strings.ToLower(req.Headers.Get("X-Forwarded-Proto"))
+	}
+	return "http"
+}
+
+// matchMatch determines whether the Match applies to the request. If so,
+// it returns the metadata constraint derived from the Match's To, if any.
+func matchMatch(m api.Match, req Request) (*api.Metadatum, bool, error) {
+	value, ok := requestValue(m, req)
+	if !ok {
+		return nil, false, nil
+	}
+
+	// the value of the derived constraint, unless To.Value is set
+	derived := value
+
+	switch m.Behavior {
+	case api.ExactMatchBehavior, "":
+		if m.Kind == api.SourceIPMatchKind {
+			_, block, err := net.ParseCIDR(m.From.Value)
+			if err != nil {
+				return nil, false, err
+			}
+			ip := net.ParseIP(value)
+			ok = ip != nil && block.Contains(ip)
+			break
+		}
+		ok = m.From.Value == "" || value == m.From.Value
+
+	case api.PrefixMatchBehavior:
+		ok = strings.HasPrefix(value, m.From.Value)
+
+	case api.SuffixMatchBehavior:
+		ok = strings.HasSuffix(value, m.From.Value)
+
+	case api.RegexMatchBehavior:
+		re, err := regexp.Compile("^(?:" + m.From.Value + ")$")
+		if err != nil {
+			return nil, false, err
+		}
+		groups := re.FindStringSubmatch(value)
+		ok = groups != nil
+		if len(groups) > 1 {
+			derived = groups[1]
+		}
+
+	case api.RangeMatchBehavior:
+		start, end, err := api.ParseRangeBoundaries(m.From.Value)
+		if err != nil {
+			return nil, false, err
+		}
+		n, err := strconv.Atoi(value)
+		ok = err == nil && n >= start && n < end
+
+	default:
+		return nil, false, fmt.Errorf("unknown match behavior %q", m.Behavior)
+	}
+
+	if !ok || m.To.Key == "" {
+		return nil, ok, nil
+	}
+
+	if m.To.Value != "" {
+		derived = m.To.Value
+	}
+	return &api.Metadatum{Key: m.To.Key, Value: derived}, true, nil
+}
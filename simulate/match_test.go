@@ -0,0 +1,253 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulate
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/test/assert"
+)
+
+func TestMatchMatch(t *testing.T) {
+	req := Request{
+		Host:     "api.example.com",
+		Path:     "/v2/users",
+		Headers:  http.Header{"X-Version": {"1.2"}, "X-Forwarded-Proto": {"https"}},
+		Cookies:  map[string]string{"user": "user-42"},
+		Query:    url.Values{"n": {"15"}},
+		SourceIP: "10.1.2.3",
+	}
+
+	testCases := []struct {
+		name    string
+		match   api.Match
+		ok      bool
+		derived *api.Metadatum
+	}{
+		{
+			name:  "exact any value",
+			match: api.Match{Kind: api.HeaderMatchKind, From: api.Metadatum{Key: "x-version"}},
+			ok:    true,
+		},
+		{
+			name: "exact value, derived from request",
+			match: api.Match{
+				Kind:     api.HeaderMatchKind,
+				Behavior: api.ExactMatchBehavior,
+				From:     api.Metadatum{Key: "X-Version", Value: "1.2"},
+				To:       api.Metadatum{Key: "version"},
+			},
+			ok:      true,
+			derived: &api.Metadatum{Key: "version", Value: "1.2"},
+		},
+		{
+			name: "exact mismatch",
+			match: api.Match{
+				Kind:     api.HeaderMatchKind,
+				Behavior: api.ExactMatchBehavior,
+				From:     api.Metadatum{Key: "X-Version", Value: "1.3"},
+			},
+		},
+		{
+			name: "missing",
+			match: api.Match{
+				Kind:     api.HeaderMatchKind,
+				Behavior: api.ExactMatchBehavior,
+				From:     api.Metadatum{Key: "X-Other"},
+			},
+		},
+		{
+			name: "prefix",
+			match: api.Match{
+				Kind:     api.CookieMatchKind,
+				Behavior: api.PrefixMatchBehavior,
+				From:     api.Metadatum{Key: "user", Value: "user-"},
+				To:       api.Metadatum{Key: "cohort", Value: "users"},
+			},
+			ok:      true,
+			derived: &api.Metadatum{Key: "cohort", Value: "users"},
+		},
+		{
+			name: "suffix",
+			match: api.Match{
+				Kind:     api.CookieMatchKind,
+				Behavior: api.SuffixMatchBehavior,
+				From:     api.Metadatum{Key: "user", Value: "-43"},
+			},
+		},
+		{
+			name: "regex subgroup",
+			match: api.Match{
+				Kind:     api.CookieMatchKind,
+				Behavior: api.RegexMatchBehavior,
+				From:     api.Metadatum{Key: "user", Value: "user-([0-9]+)"},
+				To:       api.Metadatum{Key: "id"},
+			},
+			ok:      true,
+			derived: &api.Metadatum{Key: "id", Value: "42"},
+		},
+		{
+			name: "regex is anchored",
+			match: api.Match{
+				Kind:     api.CookieMatchKind,
+				Behavior: api.RegexMatchBehavior,
+				From:     api.Metadatum{Key: "user", Value: "[0-9]+"},
+			},
+		},
+		{
+			name: "range",
+			match: api.Match{
+				Kind:     api.QueryMatchKind,
+				Behavior: api.RangeMatchBehavior,
+				From:     api.Metadatum{Key: "n", Value: "[10,20)"},
+			},
+			ok: true,
+		},
+		{
+			name: "path regex",
+			match: api.Match{
+				Kind:     api.PathMatchKind,
+				Behavior: api.RegexMatchBehavior,
+				From:     api.Metadatum{Value: "/v([0-9]+)/.*"},
+				To:       api.Metadatum{Key: "api-version"},
+			},
+			ok:      true,
+			derived: &api.Metadatum{Key: "api-version", Value: "2"},
+		},
+		{
+			name: "host suffix",
+			match: api.Match{
+				Kind:     api.HostMatchKind,
+				Behavior: api.SuffixMatchBehavior,
+				From:     api.Metadatum{Value: ".example.com"},
+			},
+			ok: true,
+		},
+		{
+			name: "scheme from header",
+			match: api.Match{
+				Kind:     api.SchemeMatchKind,
+				Behavior: api.ExactMatchBehavior,
+				From:     api.Metadatum{Value: "https"},
+			},
+			ok: true,
+		},
+		{
+			name: "source ip",
+			match: api.Match{
+				Kind:     api.SourceIPMatchKind,
+				Behavior: api.ExactMatchBehavior,
+				From:     api.Metadatum{Value: "10.0.0.0/8"},
+				To:       api.Metadatum{Key: "client"},
+			},
+			ok:      true,
+			derived: &api.Metadatum{Key: "client", Value: "10.1.2.3"},
+		},
+		{
+			name: "source ip mismatch",
+			match: api.Match{
+				Kind:     api.SourceIPMatchKind,
+				Behavior: api.ExactMatchBehavior,
+				From:     api.Metadatum{Value: "192.168.0.0/16"},
+			},
+		},
+		{
+			name: "range excludes end",
+			match: api.Match{
+				Kind:     api.QueryMatchKind,
+				Behavior: api.RangeMatchBehavior,
+				From:     api.Metadatum{Key: "n", Value: "[0,15)"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Group(
+			tc.name,
+			t,
+			func(g *assert.G) {
+				derived, ok, err := matchMatch(tc.match, req)
+				assert.Nil(g, err)
+				assert.Equal(g, ok, tc.ok)
+				assert.DeepEqual(g, derived, tc.derived)
+			},
+		)
+	}
+}
+
+func TestMatchMatchErrors(t *testing.T) {
+	req := Request{Query: url.Values{"n": {"15"}}}
+
+	_, _, err := matchMatch(
+		api.Match{
+			Kind:     api.QueryMatchKind,
+			Behavior: api.RangeMatchBehavior,
+			From:     api.Metadatum{Key: "n", Value: "10-20"},
+		},
+		req,
+	)
+	assert.ErrorContains(t, err, "Invalid range pattern")
+
+	_, _, err = matchMatch(
+		api.Match{
+			Kind:     api.QueryMatchKind,
+			Behavior: api.RegexMatchBehavior,
+			From:     api.Metadatum{Key: "n", Value: "("},
+		},
+		req,
+	)
+	assert.NonNil(t, err)
+}
+
+func TestMatchRule(t *testing.T) {
+	r := api.Rule{
+		Methods: []string{"GET", "PUT"},
+		Matches: api.Matches{
+			{
+				Kind:     api.HeaderMatchKind,
+				Behavior: api.ExactMatchBehavior,
+				From:     api.Metadatum{Key: "X-A"},
+				To:       api.Metadatum{Key: "a"},
+			},
+			{
+				Kind:     api.HeaderMatchKind,
+				Behavior: api.ExactMatchBehavior,
+				From:     api.Metadatum{Key: "X-B"},
+			},
+		},
+	}
+
+	req := Request{Method: "put", Headers: http.Header{"X-A": {"1"}, "X-B": {"2"}}}
+	md, ok, err := matchRule(r, req)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.ArrayEqual(t, md, api.Metadata{{Key: "a", Value: "1"}})
+
+	req.Headers.Del("X-B")
+	_, ok, err = matchRule(r, req)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	req.Method = "DELETE"
+	req.Headers.Set("X-B", "2")
+	_, ok, err = matchRule(r, req)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
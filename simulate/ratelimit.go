@@ -0,0 +1,176 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulate
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/turbinelabs/api"
+	tbntime "github.com/turbinelabs/nonstdlib/time"
+)
+
+// RateLimitResult describes whether a RateLimiter admits a Request.
+type RateLimitResult struct {
+	// Allowed is true if the request is within every applicable RateLimit.
+	Allowed bool
+
+	// Limit is the Name of the RateLimit that rejected the request, and
+	// Status is the HTTP status with which it is rejected. Both are unset
+	// if the request is allowed.
+	Limit  string
+	Status int
+}
+
+// RateLimiter evaluates Requests against an api.RateLimitPolicy using a
+// token bucket for each distinct set of descriptor values. Each bucket
+// holds up to RequestsPerUnit tokens, starts full, and refills continuously
+// at RequestsPerUnit tokens per Unit. The policy that applies to a
+// simulated request may be found with api.RateLimitPolicyFor, passing the
+// Result's Domain, Route and Rule.
+//
+// A RateLimiter is safe for concurrent use.
+type RateLimiter struct {
+	policy api.RateLimitPolicy
+	time   tbntime.Source
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimiter returns a RateLimiter for the given RateLimitPolicy, which
+// is assumed to be valid. Time is read from the given tbntime.Source, so
+// that tests may control it.
+func NewRateLimiter(policy api.RateLimitPolicy, src tbntime.Source) *RateLimiter {
+	return &RateLimiter{
+		policy:  policy,
+		time:    src,
+		buckets: map[string]*tokenBucket{},
+	}
+}
+
+// Check determines whether the Request is admitted. The request is
+// rejected if any applicable RateLimit's bucket holds less than one token,
+// in which case no tokens are consumed; otherwise one token is consumed
+// from each applicable bucket. RateLimits are checked in order, and the
+// first to reject the request is reported. An error is returned if a match
+// descriptor's Match cannot be evaluated.
+func (rl *RateLimiter) Check(req Request) (RateLimitResult, error) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.time.Now()
+
+	var applicable []*tokenBucket
+	for _, l := range rl.policy.Limits {
+		values, ok, err := descriptorValues(l, req)
+		if err != nil {
+			return RateLimitResult{}, fmt.Errorf("rate limit %s: %s", l.Name, err.Error())
+		}
+		if !ok {
+			continue
+		}
+
+		b := rl.bucket(l, values, now)
+		if b.tokens < 1 {
+			return RateLimitResult{
+				Limit:  l.Name,
+				Status: rl.policy.RejectStatusOrDefault(),
+			}, nil
+		}
+		applicable = append(applicable, b)
+	}
+
+	for _, b := range applicable {
+		b.tokens--
+	}
+
+	return RateLimitResult{Allowed: true}, nil
+}
+
+// bucket returns the RateLimit's bucket for the given descriptor values,
+// refilled as of now.
+func (rl *RateLimiter) bucket(l api.RateLimit, values []string, now time.Time) *tokenBucket {
+	capacity := float64(l.RequestsPerUnit)
+
+	key := strings.Join(append([]string{l.Name}, values...), "\x00")
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: capacity, updated: now}
+		rl.buckets[key] = b
+		return b
+	}
+
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens += capacity * float64(elapsed) / float64(l.Unit.Duration())
+		if b.tokens > capacity {
+			b.tokens = capacity
+		}
+		b.updated = now
+	}
+
+	return b
+}
+
+// descriptorValues returns the values of the RateLimit's descriptors for
+// the request, and whether the RateLimit applies to it.
+func descriptorValues(l api.RateLimit, req Request) ([]string, bool, error) {
+	values := make([]string, 0, len(l.Descriptors))
+	for _, d := range l.Descriptors {
+		var (
+			value string
+			ok    bool
+		)
+
+		switch d.Kind {
+		case api.HeaderRateLimitDescriptor:
+			value, ok = requestValue(api.Match{Kind: api.HeaderMatchKind, From: api.Metadatum{Key: d.Key}}, req)
+
+		case api.CookieRateLimitDescriptor:
+			value, ok = requestValue(api.Match{Kind: api.CookieMatchKind, From: api.Metadatum{Key: d.Key}}, req)
+
+		case api.RemoteAddressRateLimitDescriptor:
+			value, ok = requestValue(api.Match{Kind: api.SourceIPMatchKind}, req)
+
+		case api.MatchRateLimitDescriptor:
+			if d.Match == nil {
+				return nil, false, fmt.Errorf("%q descriptor has no match", d.Kind)
+			}
+			var err error
+			if _, ok, err = matchMatch(*d.Match, req); err != nil {
+				return nil, false, err
+			}
+
+		default:
+			return nil, false, fmt.Errorf("unknown descriptor kind %q", d.Kind)
+		}
+
+		if !ok {
+			return nil, false, nil
+		}
+		values = append(values, value)
+	}
+
+	return values, true, nil
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulate
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/turbinelabs/api"
+	tbntime "github.com/turbinelabs/nonstdlib/time"
+	"github.com/turbinelabs/test/assert"
+)
+
+func testRateLimitPolicy() api.RateLimitPolicy {
+	return api.RateLimitPolicy{
+		Limits: []api.RateLimit{
+			{
+				Name:            "per-client",
+				Descriptors:     []api.RateLimitDescriptor{{Kind: api.RemoteAddressRateLimitDescriptor}},
+				RequestsPerUnit: 2,
+				Unit:            api.SecondRateLimitUnit,
+			},
+			{
+				Name: "beta-per-user",
+				Descriptors: []api.RateLimitDescriptor{
+					{
+						Kind: api.MatchRateLimitDescriptor,
+						Match: &api.Match{
+							Kind:     api.HeaderMatchKind,
+							Behavior: api.ExactMatchBehavior,
+							From:     api.Metadatum{Key: "x-beta", Value: "true"},
+						},
+					},
+					{Kind: api.CookieRateLimitDescriptor, Key: "user"},
+				},
+				RequestsPerUnit: 1,
+				Unit:            api.MinuteRateLimitUnit,
+			},
+		},
+		RejectStatus: 503,
+	}
+}
+
+func checkN(t *testing.T, rl *RateLimiter, req Request, n int) []RateLimitResult {
+	results := make([]RateLimitResult, n)
+	for i := range results {
+		var err error
+		results[i], err = rl.Check(req)
+		assert.Nil(t, err)
+	}
+	return results
+}
+
+var allowed = RateLimitResult{Allowed: true}
+
+func TestRateLimiterPerClient(t *testing.T) {
+	tbntime.WithCurrentTimeFrozen(func(cs tbntime.ControlledSource) {
+		rl := NewRateLimiter(testRateLimitPolicy(), cs)
+		req := Request{SourceIP: "10.0.0.1"}
+		rejected := RateLimitResult{Limit: "per-client", Status: 503}
+
+		assert.ArrayEqual(t, checkN(t, rl, req, 3), []RateLimitResult{allowed, allowed, rejected})
+
+		// other clients have their own buckets
+		assert.ArrayEqual(t, checkN(t, rl, Request{SourceIP: "10.0.0.2"}, 1), []RateLimitResult{allowed})
+
+		// one token is restored every half second
+		cs.Advance(250 * time.Millisecond)
+		assert.ArrayEqual(t, checkN(t, rl, req, 1), []RateLimitResult{rejected})
+		cs.Advance(250 * time.Millisecond)
+		assert.ArrayEqual(t, checkN(t, rl, req, 2), []RateLimitResult{allowed, rejected})
+
+		// the bucket holds at most RequestsPerUnit tokens
+		cs.Advance(time.Hour)
+		assert.ArrayEqual(t, checkN(t, rl, req, 3), []RateLimitResult{allowed, allowed, rejected})
+	})
+}
+
+func TestRateLimiterDescriptorsMustAllBePresent(t *testing.T) {
+	tbntime.WithCurrentTimeFrozen(func(cs tbntime.ControlledSource) {
+		rl := NewRateLimiter(testRateLimitPolicy(), cs)
+		beta := http.Header{"X-Beta": {"true"}}
+
+		// without a source IP, a beta header and a user cookie, no limit applies
+		assert.ArrayEqual(
+			t,
+			checkN(t, rl, Request{Headers: beta}, 3),
+			[]RateLimitResult{allowed, allowed, allowed},
+		)
+		assert.ArrayEqual(
+			t,
+			checkN(t, rl, Request{Cookies: map[string]string{"user": "a"}}, 3),
+			[]RateLimitResult{allowed, allowed, allowed},
+		)
+		assert.ArrayEqual(
+			t,
+			checkN(t, rl, Request{Headers: http.Header{"X-Beta": {"false"}}, Cookies: map[string]string{"user": "a"}}, 3),
+			[]RateLimitResult{allowed, allowed, allowed},
+		)
+
+		rejected := RateLimitResult{Limit: "beta-per-user", Status: 503}
+		for _, user := range []string{"a", "b"} {
+			req := Request{Headers: beta, Cookies: map[string]string{"user": user}}
+			assert.ArrayEqual(t, checkN(t, rl, req, 2), []RateLimitResult{allowed, rejected})
+		}
+	})
+}
+
+func TestRateLimiterRejectionConsumesNoTokens(t *testing.T) {
+	tbntime.WithCurrentTimeFrozen(func(cs tbntime.ControlledSource) {
+		rl := NewRateLimiter(testRateLimitPolicy(), cs)
+		beta := http.Header{"X-Beta": {"true"}}
+		req := Request{SourceIP: "10.0.0.1", Headers: beta, Cookies: map[string]string{"user": "a"}}
+
+		assert.ArrayEqual(
+			t,
+			checkN(t, rl, req, 3),
+			[]RateLimitResult{
+				allowed,
+				{Limit: "beta-per-user", Status: 503},
+				{Limit: "beta-per-user", Status: 503},
+			},
+		)
+
+		// rejected requests did not consume per-client tokens
+		assert.ArrayEqual(t, checkN(t, rl, Request{SourceIP: "10.0.0.1"}, 2), []RateLimitResult{
+			allowed,
+			{Limit: "per-client", Status: 503},
+		})
+	})
+}
+
+func TestRateLimiterDefaultRejectStatus(t *testing.T) {
+	tbntime.WithCurrentTimeFrozen(func(cs tbntime.ControlledSource) {
+		p := testRateLimitPolicy()
+		p.RejectStatus = 0
+		p.Limits = p.Limits[:1]
+		p.Limits[0].Descriptors = nil
+		rl := NewRateLimiter(p, cs)
+
+		assert.ArrayEqual(t, checkN(t, rl, Request{}, 3), []RateLimitResult{
+			allowed,
+			allowed,
+			{Limit: "per-client", Status: http.StatusTooManyRequests},
+		})
+	})
+}
+
+func TestRateLimiterHeaderDescriptor(t *testing.T) {
+	tbntime.WithCurrentTimeFrozen(func(cs tbntime.ControlledSource) {
+		p := api.RateLimitPolicy{
+			Limits: []api.RateLimit{
+				{
+					Name:            "per-key",
+					Descriptors:     []api.RateLimitDescriptor{{Kind: api.HeaderRateLimitDescriptor, Key: "x-api-key"}},
+					RequestsPerUnit: 1,
+					Unit:            api.DayRateLimitUnit,
+				},
+			},
+		}
+		rl := NewRateLimiter(p, cs)
+		rejected := RateLimitResult{Limit: "per-key", Status: http.StatusTooManyRequests}
+
+		req := Request{Headers: http.Header{"X-Api-Key": {"k1"}}}
+		assert.ArrayEqual(t, checkN(t, rl, req, 2), []RateLimitResult{allowed, rejected})
+
+		cs.Advance(12 * time.Hour)
+		assert.ArrayEqual(t, checkN(t, rl, req, 1), []RateLimitResult{rejected})
+		cs.Advance(12 * time.Hour)
+		assert.ArrayEqual(t, checkN(t, rl, req, 2), []RateLimitResult{allowed, rejected})
+	})
+}
+
+func TestRateLimiterMatchError(t *testing.T) {
+	p := api.RateLimitPolicy{
+		Limits: []api.RateLimit{
+			{
+				Name: "bad",
+				Descriptors: []api.RateLimitDescriptor{
+					{
+						Kind: api.MatchRateLimitDescriptor,
+						Match: &api.Match{
+							Kind:     api.HeaderMatchKind,
+							Behavior: api.RegexMatchBehavior,
+							From:     api.Metadatum{Key: "x-beta", Value: "("},
+						},
+					},
+				},
+				RequestsPerUnit: 1,
+				Unit:            api.SecondRateLimitUnit,
+			},
+		},
+	}
+	rl := NewRateLimiter(p, tbntime.NewSource())
+
+	_, err := rl.Check(Request{Headers: http.Header{"X-Beta": {"true"}}})
+	assert.ErrorContains(t, err, "rate limit bad: ")
+}
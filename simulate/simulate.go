@@ -0,0 +1,462 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package simulate evaluates how the objects of a Zone route a request,
+// without a running proxy. Given a Zone's Domains, Routes, SharedRules and
+// Clusters, Simulate follows a synthetic Request through Domain and alias
+// selection, Redirects, Route selection, Rules and Matches, and
+// ClusterConstraints, reporting the Instances that could serve it:
+//
+// 	result, err := simulate.Simulate(
+// 		zone,
+// 		simulate.Request{
+// 			Host:    "example.com",
+// 			Port:    80,
+// 			Method:  "GET",
+// 			Path:    "/api/users",
+// 			Headers: http.Header{"X-Beta": {"true"}},
+// 		},
+// 	)
+//
+// Simulate is useful for unit-testing routing changes before they are
+// applied. Likewise, a RateLimiter evaluates a sequence of Requests against
+// an api.RateLimitPolicy.
+package simulate
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/turbinelabs/api"
+)
+
+var (
+	// ErrNoDomain is returned when no Domain matches the request's host and
+	// port.
+	ErrNoDomain = errors.New("no domain matches the request")
+
+	// ErrNoRoute is returned when no Route of the matched Domain matches the
+	// request's path.
+	ErrNoRoute = errors.New("no route matches the request")
+)
+
+// Zone holds the objects consulted when routing a request. Objects refer
+// to one another by key.
+type Zone struct {
+	Clusters    api.Clusters
+	Domains     api.Domains
+	Routes      api.Routes
+	SharedRules api.SharedRulesSlice
+}
+
+// Request is a synthetic HTTP request. If Scheme is empty, the scheme is
+// taken from the X-Forwarded-Proto header, or is assumed to be "http".
+type Request struct {
+	Scheme   string
+	Host     string
+	Port     int
+	Method   string
+	Path     string
+	Headers  http.Header
+	Cookies  map[string]string
+	Query    url.Values
+	SourceIP string
+}
+
+// Redirect is a Redirect that applies to a Request.
+type Redirect struct {
+	api.Redirect
+
+	// URL is the location to which the request is redirected.
+	URL string
+}
+
+// Candidate describes the Instances selected by a ClusterConstraint.
+type Candidate struct {
+	Constraint api.ClusterConstraint
+	Cluster    api.Cluster
+
+	// Weight is the ClusterConstraint's weight, and Fraction is its share
+	// of the total weight of the ClusterConstraints with which it competes.
+	Weight   uint32
+	Fraction float64
+
+	// Metadata is the ClusterConstraint's Metadata merged with the
+	// metadata constraints derived from the matching Rule's Matches.
+	Metadata api.Metadata
+
+	// Instances are the Cluster's Instances with the required Metadata.
+	Instances api.Instances
+}
+
+// Result describes how a Request is routed.
+type Result struct {
+	// Domain is the matched Domain. Alias is the DomainAlias by which it
+	// matched, or empty if the request's host equals the Domain's Name.
+	Domain api.Domain
+	Alias  api.DomainAlias
+
+	// Redirect is the redirect applied to the request, if any. If non-nil,
+	// the remaining fields are unset.
+	Redirect *Redirect
+
+	// Route is the Route with the longest Path that prefixes the request's
+	// path, and SharedRules is the SharedRules it refers to.
+	Route       api.Route
+	SharedRules api.SharedRules
+
+	// Rule is the Rule that applies to the request, or nil if the
+	// SharedRules' Default constraints are used.
+	Rule *api.Rule
+
+	// Constraints are the metadata constraints derived from the Rule's
+	// Matches (see api.Match).
+	Constraints api.Metadata
+
+	// Light, Dark and Tap describe the candidate Instances for each of the
+	// applicable ClusterConstraints.
+	Light []Candidate
+	Dark  []Candidate
+	Tap   []Candidate
+}
+
+// Simulate determines how the given Zone routes the Request:
+//
+//   1. The Domain is chosen from those with the request's port, preferring a
+//      Domain whose Name or an alias equals the request's host, then the
+//      longest matching "*.suffix" alias, then the longest matching
+//      "prefix.*" alias.
+//   2. If the Domain has ForceHTTPS set and the X-Forwarded-Proto header is
+//      not "https", the request is redirected to https. Otherwise the first
+//      of the Domain's Redirects whose From pattern matches the request's
+//      path and query and whose HeaderConstraints are met is applied.
+//   3. The Route with the longest Path prefixing the request's path is
+//      chosen.
+//   4. The Route's Rules, followed by its SharedRules' Rules, are checked
+//      in order. A Rule applies if it has no Methods or one equal to the
+//      request's method, and all of its Matches apply. The first applicable
+//      Rule whose light ClusterConstraints select at least one Instance is
+//      used; if there is none, the SharedRules' Default is used.
+//
+// Returns ErrNoDomain or ErrNoRoute if the request matches no Domain or
+// Route, or an error if the Zone contains an invalid reference or pattern.
+func Simulate(z Zone, req Request) (*Result, error) {
+	d, alias, ok := matchDomain(z.Domains, req)
+	if !ok {
+		return nil, ErrNoDomain
+	}
+	result := &Result{Domain: d, Alias: alias}
+
+	redirect, err := matchRedirect(d, req)
+	if err != nil {
+		return nil, err
+	}
+	if redirect != nil {
+		result.Redirect = redirect
+		return result, nil
+	}
+
+	route, ok := matchRoute(z.Routes, d.DomainKey, req.Path)
+	if !ok {
+		return nil, ErrNoRoute
+	}
+	result.Route = route
+
+	sr, ok := findSharedRules(z.SharedRules, route.SharedRulesKey)
+	if !ok {
+		return nil, fmt.Errorf("route %s: unknown shared rules %q", route.RouteKey, route.SharedRulesKey)
+	}
+	result.SharedRules = sr
+
+	clusters := map[api.ClusterKey]api.Cluster{}
+	for _, c := range z.Clusters {
+		clusters[c.ClusterKey] = c
+	}
+
+	rules := append(append(api.Rules{}, route.Rules...), sr.Rules...)
+	for i := range rules {
+		r := rules[i]
+		constraints, ok, err := matchRule(r, req)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %s", r.RuleKey, err.Error())
+		}
+		if !ok {
+			continue
+		}
+
+		light, dark, tap, err := candidates(clusters, r.Constraints, constraints)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %s", r.RuleKey, err.Error())
+		}
+		if !anyInstances(light) {
+			continue
+		}
+
+		result.Rule = &r
+		result.Constraints = constraints
+		result.Light, result.Dark, result.Tap = light, dark, tap
+		return result, nil
+	}
+
+	result.Light, result.Dark, result.Tap, err = candidates(clusters, sr.Default, nil)
+	if err != nil {
+		return nil, fmt.Errorf("shared rules %s: default: %s", sr.SharedRulesKey, err.Error())
+	}
+	return result, nil
+}
+
+// matchDomain returns the Domain serving the request's host and port, and
+// the alias by which it matched.
+func matchDomain(domains api.Domains, req Request) (api.Domain, api.DomainAlias, bool) {
+	host := strings.ToLower(req.Host)
+
+	var (
+		best      api.Domain
+		bestAlias api.DomainAlias
+		bestRank  int
+		bestLen   int
+	)
+
+	consider := func(d api.Domain, alias api.DomainAlias, rank, length int) {
+		if rank > bestRank || (rank == bestRank && length > bestLen) {
+			best, bestAlias, bestRank, bestLen = d, alias, rank, length
+		}
+	}
+
+	for _, d := range domains {
+		if d.Port != req.Port {
+			continue
+		}
+		if strings.ToLower(d.Name) == host {
+			consider(d, "", 4, len(host))
+			continue
+		}
+		for _, alias := range d.Aliases {
+			a := strings.ToLower(string(alias))
+			switch {
+			case a == host:
+				consider(d, alias, 3, len(a))
+			case strings.HasPrefix(a, "*.") && strings.HasSuffix(host, a[1:]):
+				consider(d, alias, 2, len(a))
+			case strings.HasSuffix(a, ".*") && strings.HasPrefix(host, a[:len(a)-1]):
+				consider(d, alias, 1, len(a))
+			}
+		}
+	}
+
+	return best, bestAlias, bestRank > 0
+}
+
+// requestURI returns the request's path and query string.
+func requestURI(req Request) string {
+	if len(req.Query) == 0 {
+		return req.Path
+	}
+	return req.Path + "?" + req.Query.Encode()
+}
+
+// matchRedirect returns the Redirect applied to the request by the Domain,
+// if any.
+func matchRedirect(d api.Domain, req Request) (*Redirect, error) {
+	uri := requestURI(req)
+
+	if d.ForceHTTPS && requestScheme(req) != "https" {
+		return &Redirect{
+			Redirect: api.Redirect{
+				Name:         "force-https",
+				From:         "(.*)",
+				To:           "https://$host$1",
+				RedirectType: api.PermanentRedirect,
+			},
+			URL: "https://" + req.Host + uri,
+		}, nil
+	}
+
+	for _, r := range d.Redirects {
+		re, err := regexp.Compile(r.From)
+		if err != nil {
+			return nil, fmt.Errorf("redirect %s: %s", r.Name, err.Error())
+		}
+
+		m := re.FindStringSubmatchIndex(uri)
+		if m == nil {
+			continue
+		}
+
+		ok, err := headerConstraintsMet(r.HeaderConstraints, req.Headers)
+		if err != nil {
+			return nil, fmt.Errorf("redirect %s: %s", r.Name, err.Error())
+		}
+		if !ok {
+			continue
+		}
+
+		template := strings.Replace(r.To, "$host", req.Host, -1)
+		location := string(re.ExpandString(nil, template, uri, m))
+		return &Redirect{Redirect: r, URL: location}, nil
+	}
+
+	return nil, nil
+}
+
+// headerConstraintsMet returns true if the headers satisfy every
+// HeaderConstraint.
+func headerConstraintsMet(hcs api.HeaderConstraints, headers http.Header) (bool, error) {
+	for _, hc := range hcs {
+		pattern := "^(?:" + hc.Value + ")$"
+		if !hc.CaseSensitive {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, err
+		}
+
+		values, present := headers[http.CanonicalHeaderKey(hc.Name)]
+		matched := false
+		if present {
+			for _, v := range values {
+				if re.MatchString(v) {
+					matched = true
+					break
+				}
+			}
+		}
+
+		if matched == hc.Invert {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// matchRoute returns the Route of the Domain with the longest Path that
+// prefixes the request's path.
+func matchRoute(routes api.Routes, dk api.DomainKey, path string) (api.Route, bool) {
+	var (
+		best  api.Route
+		found bool
+	)
+
+	for _, r := range routes {
+		if r.DomainKey != dk || !strings.HasPrefix(path, r.Path) {
+			continue
+		}
+		if !found || len(r.Path) > len(best.Path) {
+			best, found = r, true
+		}
+	}
+
+	return best, found
+}
+
+func findSharedRules(srs api.SharedRulesSlice, key api.SharedRulesKey) (api.SharedRules, bool) {
+	for _, sr := range srs {
+		if sr.SharedRulesKey == key {
+			return sr, true
+		}
+	}
+	return api.SharedRules{}, false
+}
+
+// candidates returns the Candidates for each of the light, dark and tap
+// ClusterConstraints, given the metadata constraints derived from a Rule.
+func candidates(
+	clusters map[api.ClusterKey]api.Cluster,
+	ac api.AllConstraints,
+	derived api.Metadata,
+) (light, dark, tap []Candidate, err error) {
+	if light, err = constraintCandidates(clusters, ac.Light, derived); err != nil {
+		return
+	}
+	if dark, err = constraintCandidates(clusters, ac.Dark, derived); err != nil {
+		return
+	}
+	tap, err = constraintCandidates(clusters, ac.Tap, derived)
+	return
+}
+
+func constraintCandidates(
+	clusters map[api.ClusterKey]api.Cluster,
+	ccs api.ClusterConstraints,
+	derived api.Metadata,
+) ([]Candidate, error) {
+	var total uint64
+	for _, cc := range ccs {
+		total += uint64(cc.Weight)
+	}
+
+	var result []Candidate
+	for _, cc := range ccs {
+		c, ok := clusters[cc.ClusterKey]
+		if !ok {
+			return nil, fmt.Errorf("constraint %s: unknown cluster %q", cc.ConstraintKey, cc.ClusterKey)
+		}
+
+		md := mergeMetadata(cc.Metadata, derived)
+		instances := api.Instances{}
+		for _, i := range c.Instances {
+			if i.MatchesMetadata(md) {
+				instances = append(instances, i)
+			}
+		}
+
+		candidate := Candidate{
+			Constraint: cc,
+			Cluster:    c,
+			Weight:     cc.Weight,
+			Metadata:   md,
+			Instances:  instances,
+		}
+		if total > 0 {
+			candidate.Fraction = float64(cc.Weight) / float64(total)
+		}
+		result = append(result, candidate)
+	}
+
+	return result, nil
+}
+
+func anyInstances(cs []Candidate) bool {
+	for _, c := range cs {
+		if len(c.Instances) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeMetadata returns the union of base and overrides, sorted by key.
+// Values in overrides take precedence.
+func mergeMetadata(base, overrides api.Metadata) api.Metadata {
+	m := base.Map()
+	for _, md := range overrides {
+		m[md.Key] = md.Value
+	}
+	if len(m) == 0 {
+		return nil
+	}
+
+	result := api.MetadataFromMap(m)
+	sort.Sort(api.MetadataByKey(result))
+	return result
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2018 Turbine Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulate
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/turbinelabs/api"
+	"github.com/turbinelabs/test/assert"
+)
+
+func testZone() Zone {
+	md := func(kvs ...string) api.Metadata {
+		result := api.Metadata{}
+		for i := 0; i < len(kvs); i += 2 {
+			result = append(result, api.Metadatum{Key: kvs[i], Value: kvs[i+1]})
+		}
+		return result
+	}
+
+	return Zone{
+		Clusters: api.Clusters{
+			{
+				ClusterKey: "api",
+				Instances: api.Instances{
+					{Host: "api-1", Port: 8080, Metadata: md("stage", "prod", "version", "1")},
+					{Host: "api-2", Port: 8080, Metadata: md("stage", "prod", "version", "2")},
+					{Host: "api-3", Port: 8080, Metadata: md("stage", "canary", "version", "2")},
+				},
+			},
+			{
+				ClusterKey: "web",
+				Instances:  api.Instances{{Host: "web-1", Port: 80}},
+			},
+			{ClusterKey: "mirror", Instances: api.Instances{{Host: "mirror-1", Port: 80}}},
+		},
+		Domains: api.Domains{
+			{
+				DomainKey: "main",
+				Name:      "example.com",
+				Port:      80,
+				Aliases:   api.DomainAliases{"www.example.com", "*.example.com", "example.*"},
+				Redirects: api.Redirects{
+					{
+						Name:         "old-docs",
+						From:         "/docs/(.*)",
+						To:           "http://docs.example.com/$1",
+						RedirectType: api.PermanentRedirect,
+						HeaderConstraints: api.HeaderConstraints{
+							{Name: "X-Keep-Docs", Value: "yes", Invert: true},
+						},
+					},
+				},
+			},
+			{DomainKey: "api-host", Name: "api.example.com", Port: 80},
+			{DomainKey: "secure", Name: "secure.example.com", Port: 80, ForceHTTPS: true},
+			{DomainKey: "main-tls", Name: "example.com", Port: 443},
+		},
+		Routes: api.Routes{
+			{RouteKey: "root", DomainKey: "main", Path: "/", SharedRulesKey: "web-rules"},
+			{
+				RouteKey:       "api",
+				DomainKey:      "main",
+				Path:           "/api",
+				SharedRulesKey: "api-rules",
+				Rules: api.Rules{
+					{
+						RuleKey: "canary",
+						Methods: []string{"GET"},
+						Matches: api.Matches{
+							{
+								Kind:     api.HeaderMatchKind,
+								Behavior: api.ExactMatchBehavior,
+								From:     api.Metadatum{Key: "X-Canary", Value: "true"},
+								To:       api.Metadatum{Key: "stage", Value: "canary"},
+							},
+						},
+						Constraints: api.AllConstraints{
+							Light: api.ClusterConstraints{{ConstraintKey: "c1", ClusterKey: "api", Weight: 1}},
+							Tap:   api.ClusterConstraints{{ConstraintKey: "t1", ClusterKey: "mirror", Weight: 1}},
+						},
+					},
+				},
+			},
+			{RouteKey: "api-host-root", DomainKey: "api-host", Path: "/", SharedRulesKey: "api-rules"},
+		},
+		SharedRules: api.SharedRulesSlice{
+			{
+				SharedRulesKey: "web-rules",
+				Default: api.AllConstraints{
+					Light: api.ClusterConstraints{{ConstraintKey: "w", ClusterKey: "web", Weight: 1}},
+				},
+			},
+			{
+				SharedRulesKey: "api-rules",
+				Default: api.AllConstraints{
+					Light: api.ClusterConstraints{
+						{ConstraintKey: "p", ClusterKey: "api", Metadata: md("stage", "prod"), Weight: 3},
+						{ConstraintKey: "web", ClusterKey: "web", Weight: 1},
+					},
+					Dark: api.ClusterConstraints{{ConstraintKey: "d", ClusterKey: "mirror", Weight: 1}},
+				},
+				Rules: api.Rules{
+					{
+						RuleKey: "version",
+						Matches: api.Matches{
+							{
+								Kind:     api.CookieMatchKind,
+								Behavior: api.RegexMatchBehavior,
+								From:     api.Metadatum{Key: "v", Value: "v([0-9]+)"},
+								To:       api.Metadatum{Key: "version"},
+							},
+						},
+						Constraints: api.AllConstraints{
+							Light: api.ClusterConstraints{{ConstraintKey: "v", ClusterKey: "api", Weight: 1}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func hosts(c Candidate) []string {
+	result := []string{}
+	for _, i := range c.Instances {
+		result = append(result, i.Host)
+	}
+	return result
+}
+
+func TestSimulateDefault(t *testing.T) {
+	result, err := Simulate(testZone(), Request{Host: "example.com", Port: 80, Method: "GET", Path: "/api/users"})
+	assert.Nil(t, err)
+	assert.Equal(t, result.Domain.DomainKey, api.DomainKey("main"))
+	assert.Equal(t, result.Alias, api.DomainAlias(""))
+	assert.Nil(t, result.Redirect)
+	assert.Equal(t, result.Route.RouteKey, api.RouteKey("api"))
+	assert.Equal(t, result.SharedRules.SharedRulesKey, api.SharedRulesKey("api-rules"))
+	assert.Nil(t, result.Rule)
+	assert.Nil(t, result.Constraints)
+
+	assert.Equal(t, len(result.Light), 2)
+	assert.ArrayEqual(t, hosts(result.Light[0]), []string{"api-1", "api-2"})
+	assert.Equal(t, result.Light[0].Weight, uint32(3))
+	assert.Equal(t, result.Light[0].Fraction, 0.75)
+	assert.ArrayEqual(t, hosts(result.Light[1]), []string{"web-1"})
+	assert.Equal(t, result.Light[1].Fraction, 0.25)
+	assert.Equal(t, len(result.Dark), 1)
+	assert.ArrayEqual(t, hosts(result.Dark[0]), []string{"mirror-1"})
+	assert.Equal(t, len(result.Tap), 0)
+}
+
+func TestSimulateRouteRule(t *testing.T) {
+	req := Request{
+		Host:    "example.com",
+		Port:    80,
+		Method:  "GET",
+		Path:    "/api",
+		Headers: http.Header{"X-Canary": {"true"}},
+	}
+
+	result, err := Simulate(testZone(), req)
+	assert.Nil(t, err)
+	assert.NonNil(t, result.Rule)
+	assert.Equal(t, result.Rule.RuleKey, api.RuleKey("canary"))
+	assert.ArrayEqual(t, result.Constraints, api.Metadata{{Key: "stage", Value: "canary"}})
+	assert.Equal(t, len(result.Light), 1)
+	assert.ArrayEqual(t, hosts(result.Light[0]), []string{"api-3"})
+	assert.ArrayEqual(t, result.Light[0].Metadata, api.Metadata{{Key: "stage", Value: "canary"}})
+	assert.Equal(t, len(result.Tap), 1)
+
+	// method doesn't match
+	req.Method = "POST"
+	result, err = Simulate(testZone(), req)
+	assert.Nil(t, err)
+	assert.Nil(t, result.Rule)
+}
+
+func TestSimulateSharedRulesRuleFallsThrough(t *testing.T) {
+	req := Request{
+		Host:    "example.com",
+		Port:    80,
+		Method:  "GET",
+		Path:    "/api",
+		Cookies: map[string]string{"v": "v2"},
+	}
+
+	result, err := Simulate(testZone(), req)
+	assert.Nil(t, err)
+	assert.NonNil(t, result.Rule)
+	assert.Equal(t, result.Rule.RuleKey, api.RuleKey("version"))
+	assert.ArrayEqual(t, result.Constraints, api.Metadata{{Key: "version", Value: "2"}})
+	assert.ArrayEqual(t, hosts(result.Light[0]), []string{"api-2", "api-3"})
+
+	// no instance has version 3, so the default is used
+	req.Cookies["v"] = "v3"
+	result, err = Simulate(testZone(), req)
+	assert.Nil(t, err)
+	assert.Nil(t, result.Rule)
+	assert.Equal(t, len(result.Light), 2)
+}
+
+func TestSimulateDomainSelection(t *testing.T) {
+	testCases := []struct {
+		host   string
+		port   int
+		domain api.DomainKey
+		alias  api.DomainAlias
+	}{
+		{"EXAMPLE.com", 80, "main", ""},
+		{"www.example.com", 80, "main", "www.example.com"},
+		{"api.example.com", 80, "api-host", ""},
+		{"other.example.com", 80, "main", "*.example.com"},
+		{"example.org", 80, "main", "example.*"},
+	}
+
+	for _, tc := range testCases {
+		assert.Group(
+			tc.host,
+			t,
+			func(g *assert.G) {
+				result, err := Simulate(
+					testZone(),
+					Request{Host: tc.host, Port: tc.port, Method: "GET", Path: "/"},
+				)
+				assert.Nil(g, err)
+				assert.Equal(g, result.Domain.DomainKey, tc.domain)
+				assert.Equal(g, result.Alias, tc.alias)
+			},
+		)
+	}
+
+	_, err := Simulate(testZone(), Request{Host: "example.net", Port: 8080, Path: "/"})
+	assert.Equal(t, err, ErrNoDomain)
+
+	// the domain on port 443 has no routes
+	_, err = Simulate(testZone(), Request{Host: "example.com", Port: 443, Path: "/"})
+	assert.Equal(t, err, ErrNoRoute)
+}
+
+func TestSimulateRedirects(t *testing.T) {
+	result, err := Simulate(
+		testZone(),
+		Request{Host: "example.com", Port: 80, Method: "GET", Path: "/docs/intro"},
+	)
+	assert.Nil(t, err)
+	assert.NonNil(t, result.Redirect)
+	assert.Equal(t, result.Redirect.Name, "old-docs")
+	assert.Equal(t, result.Redirect.URL, "http://docs.example.com/intro")
+	assert.Equal(t, result.Route.RouteKey, api.RouteKey(""))
+
+	// inverted header constraint
+	result, err = Simulate(
+		testZone(),
+		Request{
+			Host:    "example.com",
+			Port:    80,
+			Path:    "/docs/intro",
+			Headers: http.Header{"X-Keep-Docs": {"YES"}},
+		},
+	)
+	assert.Nil(t, err)
+	assert.Nil(t, result.Redirect)
+	assert.Equal(t, result.Route.RouteKey, api.RouteKey("root"))
+
+	result, err = Simulate(
+		testZone(),
+		Request{Host: "secure.example.com", Port: 80, Path: "/x"},
+	)
+	assert.Nil(t, err)
+	assert.NonNil(t, result.Redirect)
+	assert.Equal(t, result.Redirect.URL, "https://secure.example.com/x")
+}